@@ -0,0 +1,82 @@
+// Package codec Redis 中行情数据（值和 Pub/Sub 消息）的编码
+//
+// 写入方按配置选择 JSON 或 MessagePack，读取方使用 Unmarshal 自动识别两种格式，
+// 因此切换编码时无需迁移已有数据：先升级读取方，再修改写入方的配置，旧格式的数据随过期或改写逐步替换。
+// MessagePack 编码与 JSON 使用相同的字段名（json 标签），解码结果与从 JSON 解析一致。
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// 支持的编码
+const (
+	JSON    = "json"
+	MsgPack = "msgpack"
+)
+
+// Codec 值编码
+type Codec interface {
+	Name() string
+	Marshal(v interface{}) ([]byte, error)
+}
+
+// New 按名称创建编码，名称为空时使用 JSON
+func New(name string) (Codec, error) {
+	switch name {
+	case "", JSON:
+		return jsonCodec{}, nil
+	case MsgPack:
+		return msgpackCodec{}, nil
+	default:
+		return nil, fmt.Errorf("unknown codec: %s (available: %s, %s)", name, JSON, MsgPack)
+	}
+}
+
+// Unmarshal 解析 JSON 或 MessagePack 编码的数据
+// 本包写入的 MessagePack 数据顶层总是 map 或数组，首字节不是 ASCII 字符，据此与 JSON 区分
+func Unmarshal(data []byte, v interface{}) error {
+	if !IsMsgPack(data) {
+		return json.Unmarshal(data, v)
+	}
+
+	tree, err := decodeMsgPack(data)
+	if err != nil {
+		return err
+	}
+	// 解析为 interface{} 时直接使用解码结果，无需经过 JSON
+	if p, ok := v.(*interface{}); ok {
+		*p = tree
+		return nil
+	}
+	raw, err := json.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// IsMsgPack 数据是否为 MessagePack 编码
+func IsMsgPack(data []byte) bool {
+	return len(data) > 0 && data[0] >= 0x80
+}
+
+// jsonCodec JSON 编码
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string { return JSON }
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// msgpackCodec MessagePack 编码
+// 先按 JSON 规则序列化（保留 json 标签、嵌入字段和自定义序列化），再将结果编码为 MessagePack
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string { return MsgPack }
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	return encodeMsgPack(v)
+}
@@ -0,0 +1,95 @@
+package codec
+
+import (
+	"encoding/json"
+	"market-system/common/models"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	depth := &models.OrderBook{
+		Symbol:    "BTCUSDT",
+		Bids:      []models.PriceLevel{{Price: 43250.1, Amount: 0.5}, {Price: 43250, Amount: 12}},
+		Asks:      []models.PriceLevel{{Price: 43250.9, Amount: 1e-8}},
+		Timestamp: 1700000000000,
+	}
+	update := &models.KlineUpdate{
+		Kline:    models.Kline{Symbol: "BTCUSDT", Interval: "1m", OpenTime: 1700000000000, Open: 44000, Close: -1.5, Revision: 2},
+		UpdateID: 9007199254740993,
+		Closed:   true,
+		Checksum: 4294967295,
+	}
+	trade := &models.Trade{Symbol: "BTCUSDT", TradeID: strings.Repeat("9", 40), Price: 43250.5, Side: "buy"}
+
+	for _, name := range []string{JSON, MsgPack} {
+		c, err := New(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range []interface{}{depth, update, trade} {
+			data, err := c.Marshal(v)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if IsMsgPack(data) != (name == MsgPack) {
+				t.Errorf("%s: IsMsgPack = %v", name, IsMsgPack(data))
+			}
+
+			got := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+			if err := Unmarshal(data, got); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if !reflect.DeepEqual(got, v) {
+				t.Errorf("%s: got %+v, want %+v", name, got, v)
+			}
+		}
+	}
+}
+
+func TestMsgPackSmallerThanJSON(t *testing.T) {
+	depth := &models.OrderBook{Symbol: "BTCUSDT", Timestamp: 1700000000000}
+	for i := 0; i < 100; i++ {
+		depth.Bids = append(depth.Bids, models.PriceLevel{Price: 43250.12 - float64(i)*0.01, Amount: 0.123456})
+		depth.Asks = append(depth.Asks, models.PriceLevel{Price: 43250.13 + float64(i)*0.01, Amount: 0.123456})
+	}
+
+	jsonData, _ := json.Marshal(depth)
+	msgpackData, err := msgpackCodec{}.Marshal(depth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgpackData) >= len(jsonData) {
+		t.Errorf("msgpack %d bytes, json %d bytes", len(msgpackData), len(jsonData))
+	}
+}
+
+func TestUnmarshalInterface(t *testing.T) {
+	data, err := msgpackCodec{}.Marshal(map[string]interface{}{"symbol": "BTCUSDT", "price": 1.5, "count": 3, "items": []int{1, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v interface{}
+	if err := Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	// 与 JSON 解析结果序列化后相同
+	got, _ := json.Marshal(v)
+	if string(got) != `{"count":3,"items":[1,2],"price":1.5,"symbol":"BTCUSDT"}` {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	var v interface{}
+	for _, data := range [][]byte{{0x82, 0xa1, 'a'}, {0xdc, 0xff, 0xff}, {0x90, 0x00}, {0x81, 0x01, 0x01}} {
+		if err := Unmarshal(data, &v); err == nil {
+			t.Errorf("% x: expected error", data)
+		}
+	}
+	if _, err := New("protobuf"); err == nil {
+		t.Error("expected unknown codec error")
+	}
+}
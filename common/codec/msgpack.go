@@ -0,0 +1,333 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// encodeMsgPack 将值编码为 MessagePack，顶层不是对象或数组的值仍以 JSON 编码（保证 Unmarshal 能区分格式）
+func encodeMsgPack(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var tree interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+	switch tree.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		return raw, nil
+	}
+
+	w := &msgpackWriter{buf: make([]byte, 0, len(raw))}
+	if err := w.write(tree); err != nil {
+		return nil, err
+	}
+	return w.buf, nil
+}
+
+// msgpackWriter MessagePack 编码，输入为 JSON 解析结果（数字为 json.Number）
+type msgpackWriter struct {
+	buf []byte
+}
+
+func (w *msgpackWriter) write(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		w.buf = append(w.buf, 0xc0)
+	case bool:
+		if v {
+			w.buf = append(w.buf, 0xc3)
+		} else {
+			w.buf = append(w.buf, 0xc2)
+		}
+	case string:
+		w.writeString(v)
+	case json.Number:
+		return w.writeNumber(v)
+	case []interface{}:
+		w.writeHeader(len(v), 0x90, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := w.write(item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		// 按键排序，相同的值编码结果相同
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		w.writeHeader(len(v), 0x80, 0xde, 0xdf)
+		for _, key := range keys {
+			w.writeString(key)
+			if err := w.write(v[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %T", v)
+	}
+	return nil
+}
+
+// writeHeader 写入数组或 map 的长度，fix 为 4 位长度的类型前缀
+func (w *msgpackWriter) writeHeader(n int, fix, code16, code32 byte) {
+	switch {
+	case n < 16:
+		w.buf = append(w.buf, fix|byte(n))
+	case n <= math.MaxUint16:
+		w.buf = append(w.buf, code16)
+		w.buf = binary.BigEndian.AppendUint16(w.buf, uint16(n))
+	default:
+		w.buf = append(w.buf, code32)
+		w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(n))
+	}
+}
+
+func (w *msgpackWriter) writeString(s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		w.buf = append(w.buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		w.buf = append(w.buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		w.buf = append(w.buf, 0xda)
+		w.buf = binary.BigEndian.AppendUint16(w.buf, uint16(n))
+	default:
+		w.buf = append(w.buf, 0xdb)
+		w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(n))
+	}
+	w.buf = append(w.buf, s...)
+}
+
+// writeNumber 整数使用最短的整数编码，其他数字编码为 float64
+func (w *msgpackWriter) writeNumber(n json.Number) error {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		w.writeInt(i)
+		return nil
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		w.buf = append(w.buf, 0xcf)
+		w.buf = binary.BigEndian.AppendUint64(w.buf, u)
+		return nil
+	}
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return fmt.Errorf("msgpack: invalid number %s", n)
+	}
+	w.buf = append(w.buf, 0xcb)
+	w.buf = binary.BigEndian.AppendUint64(w.buf, math.Float64bits(f))
+	return nil
+}
+
+func (w *msgpackWriter) writeInt(i int64) {
+	switch {
+	case i >= 0 && i <= 0x7f:
+		w.buf = append(w.buf, byte(i))
+	case i < 0 && i >= -32:
+		w.buf = append(w.buf, byte(i))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		w.buf = append(w.buf, 0xd0, byte(i))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		w.buf = append(w.buf, 0xd1)
+		w.buf = binary.BigEndian.AppendUint16(w.buf, uint16(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		w.buf = append(w.buf, 0xd2)
+		w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(i))
+	default:
+		w.buf = append(w.buf, 0xd3)
+		w.buf = binary.BigEndian.AppendUint64(w.buf, uint64(i))
+	}
+}
+
+// errTruncated 数据不完整
+var errTruncated = errors.New("msgpack: unexpected end of data")
+
+// decodeMsgPack 解码 MessagePack，结果与 JSON 解析为 interface{} 的结构相同（整数为 int64 或 uint64）
+func decodeMsgPack(data []byte) (interface{}, error) {
+	r := &msgpackReader{data: data}
+	v, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	if r.pos != len(data) {
+		return nil, fmt.Errorf("msgpack: %d trailing bytes", len(data)-r.pos)
+	}
+	return v, nil
+}
+
+// msgpackReader MessagePack 解码
+type msgpackReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *msgpackReader) next(n int) ([]byte, error) {
+	if n < 0 || len(r.data)-r.pos < n {
+		return nil, errTruncated
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *msgpackReader) uint(size int) (uint64, error) {
+	b, err := r.next(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+func (r *msgpackReader) read() (interface{}, error) {
+	b, err := r.next(1)
+	if err != nil {
+		return nil, err
+	}
+	code := b[0]
+
+	switch {
+	case code <= 0x7f:
+		return int64(code), nil
+	case code >= 0xe0:
+		return int64(int8(code)), nil
+	case code&0xf0 == 0x80:
+		return r.readMap(int(code & 0x0f))
+	case code&0xf0 == 0x90:
+		return r.readArray(int(code & 0x0f))
+	case code&0xe0 == 0xa0:
+		return r.readString(int(code & 0x1f))
+	}
+
+	switch code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := r.uint(1 << (code - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if u > math.MaxInt64 {
+			return u, nil
+		}
+		return int64(u), nil
+	case 0xd0:
+		u, err := r.uint(1)
+		return int64(int8(u)), err
+	case 0xd1:
+		u, err := r.uint(2)
+		return int64(int16(u)), err
+	case 0xd2:
+		u, err := r.uint(4)
+		return int64(int32(u)), err
+	case 0xd3:
+		u, err := r.uint(8)
+		return int64(u), err
+	case 0xca:
+		u, err := r.uint(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xcb:
+		u, err := r.uint(8)
+		return math.Float64frombits(u), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := r.uint(1 << (code - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return r.readString(int(n))
+	case 0xc4, 0xc5, 0xc6:
+		// bin 类型按字符串处理
+		n, err := r.uint(1 << (code - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		return r.readString(int(n))
+	case 0xdc, 0xdd:
+		n, err := r.uint(2 << (code - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return r.readArray(int(n))
+	case 0xde, 0xdf:
+		n, err := r.uint(2 << (code - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return r.readMap(int(n))
+	}
+	return nil, fmt.Errorf("msgpack: unsupported type 0x%02x", code)
+}
+
+func (r *msgpackReader) readString(n int) (string, error) {
+	b, err := r.next(n)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (r *msgpackReader) readArray(n int) (interface{}, error) {
+	// 每个元素至少 1 字节，长度超过剩余数据时视为不完整
+	if n > len(r.data)-r.pos {
+		return nil, errTruncated
+	}
+	items := make([]interface{}, n)
+	for i := range items {
+		v, err := r.read()
+		if err != nil {
+			return nil, err
+		}
+		items[i] = v
+	}
+	return items, nil
+}
+
+func (r *msgpackReader) readMap(n int) (interface{}, error) {
+	if 2*n > len(r.data)-r.pos {
+		return nil, errTruncated
+	}
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := r.read()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("msgpack: map key of type %T", k)
+		}
+		v, err := r.read()
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
@@ -6,6 +6,45 @@ const (
 	DataTypeDepth  = "depth"
 	DataTypeTrade  = "trade"
 	DataTypeKline  = "kline"
+	DataTypeConfig = "config" // 交易对配置变更（仅 WebSocket 推送）
+	DataTypeTWAP   = "twap"   // 按时间加权的参考价格
+	DataTypeVWAP   = "vwap"   // 滚动的成交量加权平均价格
+	DataTypeBand   = "band"   // 内部市场动态价格带（涨跌停）
+	DataTypeIndex  = "index"  // 多个外部交易所的综合指数价格
+
+	DataTypeIndicator = "indicator" // 按K线计算的技术指标，频道为 indicator:{symbol}:{interval}
+	DataTypeBookStats = "bookstats" // 订单簿统计（价差、不平衡度、累计挂单量）
+
+	DataTypeAggTrade   = "agg_trade"   // 聚合成交
+	DataTypeAlerts     = "alerts"      // 异常检测告警，频道 alerts 推送全部交易对，alerts:{symbol} 推送单个交易对
+	DataTypePriceAlert = "price_alert" // 用户价格提醒触发通知，频道为 price_alert:{id}
+	DataTypeBookTicker = "book_ticker" // 最优买卖价（对应 Binance bookTicker）
+	DataTypeHeikinAshi = "kline_ha"    // 平均K线（Heikin-Ashi），频道为 kline_ha:{symbol}:{interval}
+	DataTypeRenko      = "kline_renko" // 砖形图（Renko），频道为 kline_renko:{symbol}:{interval}
+	DataTypeDepthDiff  = "depthDiff"   // 深度增量（仅 WebSocket 推送），由 API 服务按深度快照计算，频道为 depthDiff:{symbol}
+)
+
+// 异常检测告警类型
+const (
+	AlertTypePriceDeviation = "price_deviation" // 成交价偏离短期 EMA
+	AlertTypeVolumeSpike    = "volume_spike"    // 成交量突增
+)
+
+// 用户价格提醒条件
+const (
+	PriceAlertCrossUp     = "price_cross_up"   // 最新价由下向上穿过 value
+	PriceAlertCrossDown   = "price_cross_down" // 最新价由上向下穿过 value
+	PriceAlertChangeAbove = "change_above"     // 24 小时涨跌幅（%）达到 value 及以上
+	PriceAlertChangeBelow = "change_below"     // 24 小时涨跌幅（%）达到 value 及以下
+)
+
+// 价格带状态（同时作为状态变化事件）
+const (
+	BandStateNormal    = "normal"
+	BandStateLimitUp   = "limit_up"
+	BandStateLimitDown = "limit_down"
+
+	BandEventUpdate = "update" // 参考价格更新
 )
 
 // 交易所常量
@@ -18,6 +57,7 @@ const (
 
 // K线周期常量
 const (
+	Interval1s  = "1s" // 秒级K线，只由成交直接聚合
 	Interval1m  = "1m"
 	Interval5m  = "5m"
 	Interval15m = "15m"
@@ -25,7 +65,8 @@ const (
 	Interval1h  = "1h"
 	Interval4h  = "4h"
 	Interval1d  = "1d"
-	Interval1w  = "1w"
+	Interval1w  = "1w" // 自然周（周一开始，UTC）
+	Interval1M  = "1M" // 自然月（UTC）
 )
 
 // 交易方向
@@ -48,15 +89,77 @@ const (
 	TopicMarketDepth  = "market.depth"
 	TopicMarketTrade  = "market.trade"
 	TopicMarketKline  = "market.kline"
+	TopicMarketDLQ    = "market.dlq" // 死信队列：消费方无法识别消息格式版本的消息
+
+	TopicMarketAggTrade = "market.agg_trade" // Processor 由成交生成的聚合成交
+	TopicMarketAlert    = "market.alert"     // Processor 异常检测告警
 )
 
 // Redis Key 前缀
 const (
 	RedisKeyTicker     = "ticker:"     // ticker:{symbol}
-	RedisKeyDepth      = "depth:"      // depth:{symbol}
-	RedisKeyKline      = "kline:"      // kline:{symbol}:{interval}
+	RedisKeyDepth      = "depth:"      // depth:{symbol}，按价格精度聚合的深度为 depth:{symbol}:{precision}
+	RedisKeyKline      = "kline:"      // ZSet，分数为开盘时间：kline:{symbol}:{interval}，交易所推送的K线: kline:{symbol}:{interval}:{source}
 	RedisKeyTrade      = "trade:"      // trade:{symbol}
-	RedisChannelMarket = "market:"     // market:{symbol}:{type}
+	RedisChannelMarket = "market:"     // market:{type}:{symbol}，按周期推送的为 market:{type}:{symbol}:{interval}
+
+	RedisKeySymbolConfig     = "symbol_config"        // Hash，field 为交易对，value 为 SymbolConfig JSON
+	RedisChannelSymbolConfig = "symbol_config:update" // 交易对配置变更通知，消息内容为交易对
+	RedisChannelBackfill     = "backfill:request"     // 历史K线回补请求，消息内容为 BackfillRequest JSON
+	RedisChannelAdapter      = "adapter:control"      // 交易所适配器控制命令（管理接口发送给 Collector），消息内容为 AdapterCommand JSON
+
+	RedisKeySymbolGroup     = "symbol_group"        // Hash，field 为分组名，value 为 SymbolGroup JSON
+	RedisChannelSymbolGroup = "symbol_group:update" // 交易对分组变更通知，消息内容为分组名
+
+	RedisKeyPriceAlert     = "price_alert"        // Hash，field 为提醒 ID，value 为 PriceAlert JSON
+	RedisChannelPriceAlert = "price_alert:update" // 价格提醒变更通知，消息内容为提醒 ID
+
+	RedisKeyServiceStats = "stats:"      // stats:{service}，服务运行统计 JSON
+	RedisKeyKlineState   = "kline_state" // Hash，field 为 {symbol}:{interval}，value 为停机时未收盘的K线 JSON，启动时恢复
+
+	RedisKeyPriceAlertWebhookStats = "stats:webhook:price_alert" // 价格提醒 Webhook 的推送统计 JSON，由 Processor 上报
+
+	RedisKeyAPIStandby = "api_standby:" // api_standby:{name}，API 热备组中 active 实例的 WebSocket 状态 JSON，standby 实例定期读取
+
+	// kline_state:{partition}，按分区消费时分区被收回时保存的未收盘K线，field 同 kline_state，
+	// offset 字段为保存时的消费位置，分区分配到的实例加载后删除
+	RedisKeyPartitionState = "kline_state:"
+
+	// rolling_state，Hash，field 为交易对，value 为停机时滚动 Ticker 的分钟分桶 JSON，启动时恢复；
+	// rolling_state:{partition}，按分区消费时分区被收回时保存，分区分配到的实例加载后删除
+	RedisKeyRollingState          = "rolling_state"
+	RedisKeyPartitionRollingState = "rolling_state:"
+
+	RedisKeyTWAP  = "twap:"  // twap:{symbol}，参考价格 JSON，推送频道 market:twap:{symbol}
+	RedisKeyVWAP  = "vwap:"  // vwap:{symbol}，成交量加权平均价格 JSON，推送频道 market:vwap:{symbol}
+	RedisKeyBand  = "band:"  // band:{symbol}，价格带 JSON，推送频道 market:band:{symbol}
+	RedisKeyIndex = "index:" // index:{symbol}，指数价格 JSON，推送频道 market:index:{symbol}
+
+	RedisKeyIndicator = "indicator:" // indicator:{symbol}:{interval}，技术指标 JSON，推送频道 market:indicator:{symbol}:{interval}
+
+	RedisKeyHeikinAshi = "kline_ha:"    // ZSet，分数为开盘时间：kline_ha:{symbol}:{interval}，收盘的平均K线
+	RedisKeyRenko      = "kline_renko:" // ZSet，分数为砖块的开始时间：kline_renko:{symbol}:{interval}，interval 为生成砖块的K线周期
+
+	RedisKeyTickerSource = "ticker_source:" // ticker_source:{symbol}，带来源明细的 Ticker（TickerWithSource）
+	RedisKeyDepthSource  = "depth_source:"  // depth_source:{symbol}，混合模式下按档位标注来源的融合深度（OrderBookWithSource）
+	RedisKeyMarketStats  = "stats_24h:"     // stats_24h:{symbol}，由成交计算的 24 小时滚动统计（MarketStats）
+
+	RedisKeyAggTrade = "agg_trade:" // agg_trade:{symbol}，最近的聚合成交 List，推送频道 market:agg_trade:{symbol}
+
+	RedisKeyBookTicker = "book_ticker:" // book_ticker:{symbol}，最优买卖价 JSON，推送频道 market:book_ticker:{symbol}
+
+	RedisKeyBookStats = "bookstats:" // bookstats:{symbol}，订单簿统计历史 List（最新在前），推送频道 market:bookstats:{symbol}
+
+	RedisKeyScreener = "screener:" // ZSet，screener:{sort}，member 为交易对，分数为对应的 24h 统计值，随 Ticker 写入更新
+
+	RedisKeyConsistencyReport = "consistency_report" // 最近一次K线一致性检查报告 JSON
+
+	// daily_totals:market:{date}，行情系统按 UTC 日累计的内部成交，field 为 {symbol}:trade_count、{symbol}:volume、{symbol}:quote_volume
+	RedisKeyMarketTotals = "daily_totals:market:"
+	// daily_totals:engine:{date}，撮合引擎推送的单日成交汇总，field 为交易对，value 为 DailyTradeTotals JSON
+	RedisKeyEngineTotals = "daily_totals:engine:"
+	// Hash，field 为日期，value 为撮合引擎与行情系统的成交对账报告 JSON
+	RedisKeyTradeReconcileReport = "trade_reconcile_report"
 )
 
 // 时间常量（毫秒）
@@ -74,6 +177,19 @@ const (
 	MaxReconnectDelay  = 60 * Second // 最大重连延迟
 )
 
+// 服务统计
+const (
+	ServiceCollector = "collector"
+	ServiceProcessor = "processor"
+	ServiceAPI       = "api"
+	ServiceWebhook   = "webhook"
+	ServiceHistory   = "history"
+
+	ServiceStatsTTL = 2 * Minute // 服务统计过期时间，超过该时间未上报视为服务离线
+
+	DailyTotalsTTL = 30 * Day // 每日成交汇总和对账报告的保留时间
+)
+
 // WebSocket 配置
 const (
 	PingInterval     = 30 * Second // 心跳间隔
@@ -84,10 +200,39 @@ const (
 	MaxMessageSize   = 512 * 1024  // 最大消息大小 512KB
 )
 
+// WebSocket 消息有效期（毫秒），发送队列中超过有效期的消息在写出前丢弃
+const (
+	DepthMessageTTL  = 1 * Second // 深度快照很快失效
+	TickerMessageTTL = 3 * Second // Ticker 快照
+)
+
+// WebSocket 深度推送的默认合并窗口（毫秒），同一交易对在窗口内只推送最新的深度
+const DepthConflationWindow = 100
+
+// WebSocket 深度增量频道推送全量深度的默认间隔（毫秒）
+const DepthDiffSnapshotInterval = 10 * Second
+
+// 实时K线推送最小间隔（毫秒），K线收盘时立即推送
+const KlineLivePushInterval = 1 * Second
+
+// K线收盘等待迟到成交的时间（毫秒），超过收盘时间该时长后没有新成交也收盘
+const KlineCloseDelay = 2 * Second
+
+// K线来源（Kline.Source），交易所推送的K线使用交易所名称
+const KlineSourceLocal = "local" // 本地按成交聚合
+
+// K线类型（K线接口和 WebSocket kline 频道的 type 参数）
+const (
+	KlineTypeCandle     = "candle"      // 普通K线，默认
+	KlineTypeHeikinAshi = "heikin_ashi" // 平均K线，由普通K线的开高低收计算
+	KlineTypeRenko      = "renko"       // 砖形图，收盘价每变动一个砖块大小生成一块砖
+)
+
 // 限流配置
 const (
 	MaxSubscriptionsPerConn = 20  // 每个连接最多订阅数
 	MessageRateLimit        = 100 // 每秒最大消息数
+	MaxRateLimitWarnings    = 10  // 超出消息限流的警告次数，超过后断开连接
 )
 
 // 深度档位
@@ -103,6 +248,7 @@ const (
 	SourceInternal = "internal" // 内部数据源
 	SourceExternal = "external" // 外部数据源
 	SourceMerged   = "merged"   // 合并数据
+	SourceAll      = "all"      // 全部数据源（成交按数据源过滤时使用）
 )
 
 // 交易对模式
@@ -112,11 +258,45 @@ const (
 	ModeHybrid       = "HYBRID"        // 混合模式
 )
 
+// 行情排行的排序字段（screener:{sort}）
+const (
+	ScreenerSortChange     = "change"      // 24h 涨跌幅
+	ScreenerSortVolume     = "volume"      // 24h 成交量
+	ScreenerSortTradeCount = "trade_count" // 24h 成交笔数
+)
+
 // 数据融合策略
 const (
-	MergeStrategyPriority   = "priority"   // 优先级策略（内部优先）
-	MergeStrategySupplement = "supplement" // 补充策略（外部补充）
-	MergeStrategyOverride   = "override"   // 覆盖策略（外部覆盖）
+	MergeStrategyPriority    = "priority"     // 优先级策略（内部优先）
+	MergeStrategySupplement  = "supplement"   // 补充策略（外部补充）
+	MergeStrategyOverride    = "override"     // 覆盖策略（外部覆盖）
+	MergeStrategyVWAP        = "vwap"         // 成交量加权策略（按成交量及权重加权最新价）
+	MergeStrategyMedian      = "median"       // 中位数策略（各数据源最新价取中位数）
+	MergeStrategyTrimmedMean = "trimmed_mean" // 截尾均值策略（剔除两端异常数据源后取平均）
+)
+
+// 指数价格计算方法
+const (
+	IndexMethodWeightedMedian = "weighted_median" // 加权中位数
+	IndexMethodWeightedMean   = "weighted_mean"   // 加权均值
+)
+
+// DefaultTrimPercent trimmed_mean 策略默认两端各剔除的数据源比例（%）
+const DefaultTrimPercent = 20.0
+
+// MinRobustSources median / trimmed_mean 策略需要的最少新鲜数据源数，不足时使用优先级策略的最新价
+// 两个数据源的中位数或截尾均值即两者的平均值，其中一个被操纵仍会带偏发布价格
+const MinRobustSources = 3
+
+// 交易对配置变更事件（推送到 WebSocket config 频道）
+const (
+	SymbolConfigAdded    = "added"    // 新增交易对
+	SymbolConfigUpdated  = "updated"  // 配置更新（模式、精度等）
+	SymbolConfigEnabled  = "enabled"  // 重新启用
+	SymbolConfigDisabled = "disabled" // 停用
+	SymbolConfigRemoved  = "removed"  // 删除配置
+	SymbolConfigDeleted  = "deleted"  // 软删除（对外隐藏，数据保留）
+	SymbolConfigRestored = "restored" // 从软删除恢复
 )
 
 // 内部数据源标识
@@ -127,5 +307,6 @@ const (
 // 数据新鲜度（毫秒）
 const (
 	DataFreshnessThreshold = 5 * Second // 数据新鲜度阈值：5秒
+	TradeDedupWindow       = 1 * Second // 跨数据源成交去重时间窗口：1秒
 	PriceDeviationLimit    = 10.0       // 价格偏离限制：10%
 )
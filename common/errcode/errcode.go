@@ -0,0 +1,61 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error 对外的错误码，REST 接口按 HTTPStatus 返回状态码，响应体为 {"code": Code, "msg": ...}
+// 业务错误通过 Newf 或 fmt.Errorf("%w: ...", errcode.ErrNotFound) 包装，调用方通过 errors.Is 判断
+type Error struct {
+	Code       int
+	Msg        string
+	HTTPStatus int
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+// CodeOK 成功
+const CodeOK = 0
+
+// 错误码目录：前三位为 HTTP 状态码，后两位区分同一状态码下的错误
+var (
+	ErrInvalidParam   = &Error{Code: 40000, Msg: "invalid parameter", HTTPStatus: http.StatusBadRequest}
+	ErrUnauthorized   = &Error{Code: 40100, Msg: "unauthorized", HTTPStatus: http.StatusUnauthorized}
+	ErrForbidden      = &Error{Code: 40300, Msg: "forbidden", HTTPStatus: http.StatusForbidden}
+	ErrNotFound       = &Error{Code: 40400, Msg: "not found", HTTPStatus: http.StatusNotFound}
+	ErrSymbolNotFound = &Error{Code: 40401, Msg: "symbol not found", HTTPStatus: http.StatusNotFound}
+	ErrRateLimited    = &Error{Code: 42900, Msg: "rate limit exceeded", HTTPStatus: http.StatusTooManyRequests}
+	ErrInternal       = &Error{Code: 50000, Msg: "internal error", HTTPStatus: http.StatusInternalServerError}
+	ErrStaleData      = &Error{Code: 50300, Msg: "data is stale", HTTPStatus: http.StatusServiceUnavailable}
+)
+
+// From 获取错误链中的错误码，没有错误码的错误（如 Redis 连接失败）为 ErrInternal
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return ErrInternal
+}
+
+// Newf 创建带错误码的错误，错误信息为格式化后的内容
+func Newf(code *Error, format string, args ...interface{}) error {
+	return &codedError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+type codedError struct {
+	code *Error
+	msg  string
+}
+
+func (e *codedError) Error() string {
+	return e.msg
+}
+
+func (e *codedError) Unwrap() error {
+	return e.code
+}
@@ -0,0 +1,29 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		err  error
+		want *Error
+	}{
+		{Newf(ErrNotFound, "ticker not found for symbol: %s", "BTCUSDT"), ErrNotFound},
+		{fmt.Errorf("wrapped: %w", fmt.Errorf("%w: BTCUSDT", ErrSymbolNotFound)), ErrSymbolNotFound},
+		{fmt.Errorf("failed to get ticker: %w", errors.New("dial tcp: connection refused")), ErrInternal},
+	}
+	for _, tt := range tests {
+		if got := From(tt.err); got != tt.want {
+			t.Errorf("From(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+	if !errors.Is(tests[0].err, ErrNotFound) || errors.Is(tests[0].err, ErrSymbolNotFound) {
+		t.Error("errors.Is mismatch")
+	}
+	if msg := tests[0].err.Error(); msg != "ticker not found for symbol: BTCUSDT" {
+		t.Errorf("message = %q", msg)
+	}
+}
@@ -0,0 +1,186 @@
+// Package v1 行情推送事件的公开数据结构（v1 版本）
+//
+// 外部消费者（WebSocket 客户端、下游服务）应使用本包解析推送数据，而不是依赖内部的 common/models。
+// 本包的 JSON 字段名和类型由 testdata 中的 golden 文件冻结，任何不兼容的改动都需要发布新的版本包。
+package v1
+
+import "encoding/json"
+
+// Version 事件格式版本
+const Version = "v1"
+
+// 数据类型（频道名前缀）
+const (
+	TypeTicker = "ticker"
+	TypeDepth  = "depth"
+	TypeTrade  = "trade"
+	TypeKline  = "kline"
+	TypeConfig = "config"
+
+	TypeAggTrade  = "agg_trade"
+	TypeDepthDiff = "depthDiff"
+)
+
+// Ticker 行情快照
+type Ticker struct {
+	Symbol                string  `json:"symbol"`
+	LastPrice             float64 `json:"last_price"`
+	BidPrice              float64 `json:"bid_price"`
+	AskPrice              float64 `json:"ask_price"`
+	High24h               float64 `json:"high_24h"`
+	Low24h                float64 `json:"low_24h"`
+	Volume24h             float64 `json:"volume_24h"`
+	Open24h               float64 `json:"open_24h"`
+	PriceChange24h        float64 `json:"price_change_24h"`
+	PriceChangePercent24h float64 `json:"price_change_percent_24h"` // 百分比，例如 1.25 表示 +1.25%
+	TradeCount24h         int64   `json:"trade_count_24h"`
+	Timestamp             int64   `json:"timestamp"` // 毫秒
+}
+
+// Trade 成交
+type Trade struct {
+	Symbol    string  `json:"symbol"`
+	TradeID   string  `json:"trade_id"`
+	Price     float64 `json:"price"`
+	Amount    float64 `json:"amount"`
+	Side      string  `json:"side"`               // buy, sell
+	Timestamp int64   `json:"timestamp"`          // 毫秒
+	Source    string  `json:"source,omitempty"`   // internal, external
+	Exchange  string  `json:"exchange,omitempty"` // 数据来源交易所
+}
+
+// AggTrade 聚合成交：同一价格、同一方向的连续成交在聚合窗口内合并为一条
+type AggTrade struct {
+	Symbol       string  `json:"symbol"`
+	AggID        int64   `json:"agg_id"` // 交易对内递增
+	Price        float64 `json:"price"`
+	Amount       float64 `json:"amount"` // 合并的成交数量之和
+	Side         string  `json:"side"`   // buy, sell
+	FirstTradeID string  `json:"first_trade_id"`
+	LastTradeID  string  `json:"last_trade_id"`
+	Count        int64   `json:"count"`      // 合并的成交笔数
+	FirstTime    int64   `json:"first_time"` // 第一笔成交时间（毫秒）
+	Timestamp    int64   `json:"timestamp"`  // 最后一笔成交时间（毫秒）
+	Source       string  `json:"source,omitempty"`
+	Exchange     string  `json:"exchange,omitempty"`
+}
+
+// Kline K线
+type Kline struct {
+	Symbol    string  `json:"symbol"`
+	Interval  string  `json:"interval"`   // 1m, 5m, 15m, 1h, 4h, 1d
+	OpenTime  int64   `json:"open_time"`  // 毫秒
+	CloseTime int64   `json:"close_time"` // 毫秒
+	Open      float64 `json:"open"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Close     float64 `json:"close"`
+	Volume    float64 `json:"volume"`
+	QuoteVol  float64 `json:"quote_vol"`
+	TradeNum  int64   `json:"trade_num"`
+	Source    string  `json:"source,omitempty"` // local 为本地成交聚合，交易所名称表示交易所推送的K线
+	Revision  int64   `json:"revision"`         // 修订号，回补、补齐、重建改写已保存的K线时递增
+	IsFinal   bool    `json:"is_final"`         // 已收盘并保存
+}
+
+// KlineUpdate K线推送（实时K线与收盘K线）
+// 同一根K线按 (open_time, revision, update_id) 去重，revision 更大的修订K线原位替换，
+// update_id 相同时以 closed=true 为准
+type KlineUpdate struct {
+	Kline
+	UpdateID int64  `json:"update_id"`
+	Closed   bool   `json:"closed"`
+	Checksum uint32 `json:"checksum"`
+}
+
+// PriceLevel 价格档位
+type PriceLevel struct {
+	Price  float64 `json:"price"`
+	Amount float64 `json:"amount"`
+}
+
+// OrderBook 深度快照
+type OrderBook struct {
+	Symbol    string       `json:"symbol"`
+	Bids      []PriceLevel `json:"bids"`      // 价格从高到低
+	Asks      []PriceLevel `json:"asks"`      // 价格从低到高
+	Timestamp int64        `json:"timestamp"` // 毫秒
+}
+
+// DepthDiff 深度增量，seq 在交易对内递增，prev_seq 不等于本地最新的 seq 时表示丢失了消息，应等待下一个全量深度
+// snapshot 为 true 时为全量深度（替换本地订单簿），否则只包含变化的档位，amount 为 0 表示删除该价位
+type DepthDiff struct {
+	Symbol    string       `json:"symbol"`
+	Seq       int64        `json:"seq"`
+	PrevSeq   int64        `json:"prev_seq"`
+	Snapshot  bool         `json:"snapshot"`
+	Bids      []PriceLevel `json:"bids"`
+	Asks      []PriceLevel `json:"asks"`
+	Timestamp int64        `json:"timestamp"` // 毫秒
+}
+
+// SymbolConfig 交易对配置
+type SymbolConfig struct {
+	Symbol         string     `json:"symbol"`
+	Mode           string     `json:"mode"`            // INTERNAL_ONLY, EXTERNAL_ONLY, HYBRID
+	PrimarySource  string     `json:"primary_source"`  // internal, external
+	ExternalSource string     `json:"external_source"` // binance, okx 等
+	MergeStrategy  string     `json:"merge_strategy"`  // priority, supplement, override, vwap, median, trimmed_mean
+	Enable         bool       `json:"enable"`
+	Description    string     `json:"description"`
+	TickSize       float64    `json:"tick_size"`
+	VWAP           VWAPConfig `json:"vwap"`
+	FreshnessMs    int64      `json:"freshness_ms"`
+	MaxDepthLevels int        `json:"max_depth_levels"`
+	TrimPercent    float64    `json:"trim_percent"`
+	Deleted        bool       `json:"deleted"`    // 已软删除，行情频道不再推送该交易对
+	DeletedAt      int64      `json:"deleted_at"` // 软删除时间（毫秒），未删除时为 0
+}
+
+// VWAPConfig 成交量加权融合策略配置
+type VWAPConfig struct {
+	InternalWeight    float64 `json:"internal_weight"`
+	ExternalWeight    float64 `json:"external_weight"`
+	MinInternalVolume float64 `json:"min_internal_volume"`
+	MinExternalVolume float64 `json:"min_external_volume"`
+}
+
+// SymbolConfigEvent config 频道推送的交易对配置变更，removed 事件不带 Config
+type SymbolConfigEvent struct {
+	Event     string        `json:"event"` // added, updated, enabled, disabled, removed, deleted, restored
+	Symbol    string        `json:"symbol"`
+	Config    *SymbolConfig `json:"config,omitempty"`
+	Timestamp int64         `json:"timestamp"` // 毫秒
+}
+
+// Request 客户端请求
+type Request struct {
+	Action  string `json:"action"` // subscribe, unsubscribe, ping
+	Channel string `json:"channel,omitempty"`
+	Symbol  string `json:"symbol,omitempty"`
+	// Intervals 仅 kline 频道：一次订阅同一交易对的多个周期，频道为 kline:{symbol}:{interval}
+	Intervals []string `json:"intervals,omitempty"`
+	// Source 仅 trade 频道：按数据源过滤成交（internal / external / all），频道为 trade:{symbol}:{source}
+	Source string `json:"source,omitempty"`
+}
+
+// ChannelMessage 频道推送消息，Data 按频道类型解析为 Ticker / OrderBook / DepthDiff / Trade / KlineUpdate / SymbolConfigEvent
+type ChannelMessage struct {
+	Channel string          `json:"channel"` // 例如 ticker:BTCUSDT、kline:BTCUSDT:1m
+	Data    json.RawMessage `json:"data"`
+}
+
+// Response 请求响应（subscribed / unsubscribed / pong / error）
+type Response struct {
+	Type  string          `json:"type"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// SubscriptionData subscribed / unsubscribed 响应的数据
+type SubscriptionData struct {
+	Channel   string   `json:"channel"`
+	Symbol    string   `json:"symbol"`
+	Intervals []string `json:"intervals,omitempty"`
+	Source    string   `json:"source,omitempty"`
+}
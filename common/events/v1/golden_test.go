@@ -0,0 +1,207 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"market-system/common/models"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// goldenCases 内部模型的 JSON 输出必须与 golden 文件一致，且能无损解析为 v1 结构
+// kline_update 的 golden 文件同时冻结了 checksum 算法（客户端按相同规则复算）
+var goldenCases = []struct {
+	name  string
+	model interface{}
+	event interface{}
+}{
+	{
+		name: "ticker",
+		model: &models.Ticker{
+			Symbol:                "BTCUSDT",
+			LastPrice:             43250.5,
+			BidPrice:              43250.1,
+			AskPrice:              43250.9,
+			High24h:               44000,
+			Low24h:                42100.25,
+			Volume24h:             12345.678,
+			Open24h:               42800,
+			PriceChange24h:        450.5,
+			PriceChangePercent24h: 1.053,
+			TradeCount24h:         987654,
+			Timestamp:             1700000000000,
+		},
+		event: &Ticker{},
+	},
+	{
+		name: "trade",
+		model: &models.Trade{
+			Symbol:    "BTCUSDT",
+			TradeID:   "123456789",
+			Price:     43250.5,
+			Amount:    0.015,
+			Side:      "buy",
+			Timestamp: 1700000000123,
+			Source:    "external",
+			Exchange:  "binance",
+		},
+		event: &Trade{},
+	},
+	{
+		name: "agg_trade",
+		model: &models.AggTrade{
+			Symbol:       "BTCUSDT",
+			AggID:        1700000000123000,
+			Price:        43250.5,
+			Amount:       0.045,
+			Side:         "buy",
+			FirstTradeID: "123456789",
+			LastTradeID:  "123456791",
+			Count:        3,
+			FirstTime:    1700000000123,
+			Timestamp:    1700000000180,
+			Source:       "external",
+			Exchange:     "binance",
+		},
+		event: &AggTrade{},
+	},
+	{
+		name: "kline",
+		model: &models.Kline{
+			Symbol:    "BTCUSDT",
+			Interval:  "1m",
+			OpenTime:  1700000000000,
+			CloseTime: 1700000059999,
+			Open:      43200,
+			High:      43300.5,
+			Low:       43150.25,
+			Close:     43250.5,
+			Volume:    12.5,
+			QuoteVol:  540000.75,
+			TradeNum:  321,
+		},
+		event: &Kline{},
+	},
+	{
+		name: "kline_update",
+		model: models.NewKlineUpdate(&models.Kline{
+			Symbol:    "BTCUSDT",
+			Interval:  "1m",
+			OpenTime:  1700000000000,
+			CloseTime: 1700000059999,
+			Open:      43200,
+			High:      43300.5,
+			Low:       43150.25,
+			Close:     43250.5,
+			Volume:    12.5,
+			QuoteVol:  540000.75,
+			TradeNum:  321,
+			Revision:  1,
+			IsFinal:   true,
+		}, true),
+		event: &KlineUpdate{},
+	},
+	{
+		name: "orderbook",
+		model: &models.OrderBook{
+			Symbol: "BTCUSDT",
+			Bids: []models.PriceLevel{
+				{Price: 43250.1, Amount: 1.5},
+				{Price: 43250, Amount: 0.25},
+			},
+			Asks: []models.PriceLevel{
+				{Price: 43250.9, Amount: 0.8},
+				{Price: 43251.5, Amount: 2},
+			},
+			Timestamp: 1700000000456,
+		},
+		event: &OrderBook{},
+	},
+	{
+		name: "depth_diff",
+		model: &models.DepthDiff{
+			Symbol:   "BTCUSDT",
+			Seq:      42,
+			PrevSeq:  41,
+			Snapshot: false,
+			Bids: []models.PriceLevel{
+				{Price: 43250.1, Amount: 0},
+			},
+			Asks: []models.PriceLevel{
+				{Price: 43250.9, Amount: 1.2},
+			},
+			Timestamp: 1700000000456,
+		},
+		event: &DepthDiff{},
+	},
+	{
+		name: "symbol_config_event",
+		model: &models.SymbolConfigEvent{
+			Event:  "updated",
+			Symbol: "BTCUSDT",
+			Config: &models.SymbolConfig{
+				Symbol:         "BTCUSDT",
+				Mode:           "HYBRID",
+				PrimarySource:  "internal",
+				ExternalSource: "binance",
+				MergeStrategy:  "vwap",
+				Enable:         true,
+				Description:    "BTC hybrid",
+				TickSize:       0.01,
+				VWAP: models.VWAPConfig{
+					InternalWeight: 2,
+					ExternalWeight: 1,
+				},
+				FreshnessMs:    3000,
+				MaxDepthLevels: 50,
+				TrimPercent:    25,
+			},
+			Timestamp: 1700000000789,
+		},
+		event: &SymbolConfigEvent{},
+	},
+}
+
+func TestGoldenModels(t *testing.T) {
+	for _, tc := range goldenCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := marshal(t, tc.model)
+			path := filepath.Join("testdata", tc.name+".golden.json")
+
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read golden file: %v (run go test -update to create)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("JSON wire format changed for %s\ngot:\n%s\nwant:\n%s", tc.name, got, want)
+			}
+
+			// v1 结构必须能无损解析并还原 golden 数据
+			if err := json.Unmarshal(want, tc.event); err != nil {
+				t.Fatalf("unmarshal into v1 struct: %v", err)
+			}
+			if roundTrip := marshal(t, tc.event); !bytes.Equal(roundTrip, want) {
+				t.Errorf("v1 struct out of sync with wire format for %s\ngot:\n%s\nwant:\n%s", tc.name, roundTrip, want)
+			}
+		})
+	}
+}
+
+func marshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(data, '\n')
+}
@@ -0,0 +1,158 @@
+package exchange
+
+import (
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// BinanceREST Binance REST 行情客户端
+type BinanceREST struct {
+	baseURL string
+	budget  *Budget
+}
+
+// NewBinanceREST 创建 Binance REST 客户端
+func NewBinanceREST(baseURL string) *BinanceREST {
+	if baseURL == "" {
+		baseURL = "https://api.binance.com"
+	}
+	return &BinanceREST{baseURL: baseURL, budget: BudgetFor(constants.ExchangeBinance)}
+}
+
+// Name 交易所名称
+func (b *BinanceREST) Name() string {
+	return constants.ExchangeBinance
+}
+
+// GetTicker 获取24小时行情
+func (b *BinanceREST) GetTicker(symbol string) (*models.Ticker, error) {
+	var raw map[string]interface{}
+	if err := getJSON(b.budget, 2, b.url("/api/v3/ticker/24hr", url.Values{"symbol": {symbol}}), &raw); err != nil {
+		return nil, fmt.Errorf("[Binance] get ticker: %w", err)
+	}
+
+	return &models.Ticker{
+		Symbol:                symbol,
+		LastPrice:             toFloat(raw["lastPrice"]),
+		BidPrice:              toFloat(raw["bidPrice"]),
+		AskPrice:              toFloat(raw["askPrice"]),
+		High24h:               toFloat(raw["highPrice"]),
+		Low24h:                toFloat(raw["lowPrice"]),
+		Volume24h:             toFloat(raw["volume"]),
+		Open24h:               toFloat(raw["openPrice"]),
+		PriceChange24h:        toFloat(raw["priceChange"]),
+		PriceChangePercent24h: toFloat(raw["priceChangePercent"]),
+		TradeCount24h:         toInt64(raw["count"]),
+		Timestamp:             toInt64(raw["closeTime"]),
+	}, nil
+}
+
+// GetDepth 获取深度快照
+func (b *BinanceREST) GetDepth(symbol string, limit int) (*models.OrderBook, error) {
+	var raw struct {
+		Bids [][]interface{} `json:"bids"`
+		Asks [][]interface{} `json:"asks"`
+	}
+	params := url.Values{"symbol": {symbol}, "limit": {strconv.Itoa(limit)}}
+	if err := getJSON(b.budget, binanceDepthWeight(limit), b.url("/api/v3/depth", params), &raw); err != nil {
+		return nil, fmt.Errorf("[Binance] get depth: %w", err)
+	}
+
+	return &models.OrderBook{
+		Symbol: symbol,
+		Bids:   toPriceLevels(raw.Bids),
+		Asks:   toPriceLevels(raw.Asks),
+	}, nil
+}
+
+// GetKlines 获取K线
+// 返回格式: [openTime, open, high, low, close, volume, closeTime, quoteVolume, trades, ...]
+func (b *BinanceREST) GetKlines(symbol, interval string, startTime, endTime int64, limit int) ([]*models.Kline, error) {
+	params := url.Values{
+		"symbol":   {symbol},
+		"interval": {interval},
+		"limit":    {strconv.Itoa(limit)},
+	}
+	if startTime > 0 {
+		params.Set("startTime", strconv.FormatInt(startTime, 10))
+	}
+	if endTime > 0 {
+		params.Set("endTime", strconv.FormatInt(endTime, 10))
+	}
+
+	var raw [][]interface{}
+	if err := getJSON(b.budget, 2, b.url("/api/v3/klines", params), &raw); err != nil {
+		return nil, fmt.Errorf("[Binance] get klines: %w", err)
+	}
+
+	klines := make([]*models.Kline, 0, len(raw))
+	for _, item := range raw {
+		if len(item) < 9 {
+			continue
+		}
+		klines = append(klines, &models.Kline{
+			Symbol:    symbol,
+			Interval:  interval,
+			OpenTime:  toInt64(item[0]),
+			Open:      toFloat(item[1]),
+			High:      toFloat(item[2]),
+			Low:       toFloat(item[3]),
+			Close:     toFloat(item[4]),
+			Volume:    toFloat(item[5]),
+			CloseTime: toInt64(item[6]),
+			QuoteVol:  toFloat(item[7]),
+			TradeNum:  toInt64(item[8]),
+		})
+	}
+	return klines, nil
+}
+
+// GetTrades 获取最近成交
+func (b *BinanceREST) GetTrades(symbol string, limit int) ([]*models.Trade, error) {
+	var raw []map[string]interface{}
+	params := url.Values{"symbol": {symbol}, "limit": {strconv.Itoa(limit)}}
+	if err := getJSON(b.budget, 25, b.url("/api/v3/trades", params), &raw); err != nil {
+		return nil, fmt.Errorf("[Binance] get trades: %w", err)
+	}
+
+	trades := make([]*models.Trade, 0, len(raw))
+	for _, item := range raw {
+		// 与 WebSocket 适配器保持一致的方向映射
+		side := constants.SideSell
+		if m, ok := item["isBuyerMaker"].(bool); ok && m {
+			side = constants.SideBuy
+		}
+		trades = append(trades, &models.Trade{
+			Symbol:    symbol,
+			TradeID:   strconv.FormatInt(toInt64(item["id"]), 10),
+			Price:     toFloat(item["price"]),
+			Amount:    toFloat(item["qty"]),
+			Side:      side,
+			Timestamp: toInt64(item["time"]),
+		})
+	}
+	return trades, nil
+}
+
+// binanceDepthWeight 深度接口权重随档位数增加
+func binanceDepthWeight(limit int) int {
+	switch {
+	case limit <= 100:
+		return 5
+	case limit <= 500:
+		return 25
+	case limit <= 1000:
+		return 50
+	default:
+		return 250
+	}
+}
+
+// url 构造请求地址
+func (b *BinanceREST) url(path string, params url.Values) string {
+	return strings.TrimSuffix(b.baseURL, "/") + path + "?" + params.Encode()
+}
@@ -0,0 +1,201 @@
+package exchange
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"market-system/common/constants"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExhausted 权重额度不足且等待时间超过上限
+var ErrBudgetExhausted = errors.New("rest weight budget exhausted")
+
+// BudgetLimit 权重限制配置
+type BudgetLimit struct {
+	Weight  int           // 每个窗口允许的总权重
+	Window  time.Duration // 窗口长度（按窗口长度对齐，与交易所计数方式一致）
+	MaxWait time.Duration // 额度不足时的最长等待时间，超过则返回 ErrBudgetExhausted
+}
+
+// DefaultBudgetLimits 各交易所默认权重限制（留有余量）
+// - Binance: 现货 REST 按 IP 每分钟 6000 权重
+// - OKX: 行情接口按 IP 每 2 秒 20 次（每次请求权重 1）
+var DefaultBudgetLimits = map[string]BudgetLimit{
+	constants.ExchangeBinance: {Weight: 5000, Window: time.Minute, MaxWait: 10 * time.Second},
+	constants.ExchangeOKX:     {Weight: 18, Window: 2 * time.Second, MaxWait: 10 * time.Second},
+}
+
+// Budget 单个交易所的 REST 权重额度
+// 所有 REST 请求发送前按接口权重申请额度；响应头中交易所返回的已用权重（Binance X-MBX-USED-WEIGHT-1M）
+// 优先于本地计数；收到 429/418 时按 Retry-After 暂停该交易所的全部请求。
+type Budget struct {
+	exchange string
+
+	mu           sync.Mutex
+	limit        BudgetLimit
+	windowStart  time.Time
+	used         int
+	blockedUntil time.Time
+
+	stats BudgetStats
+}
+
+// BudgetStats 额度统计
+type BudgetStats struct {
+	Used      int   `json:"used"`      // 当前窗口已用权重
+	Limit     int   `json:"limit"`     // 窗口权重上限
+	Requests  int64 `json:"requests"`  // 请求数
+	Waits     int64 `json:"waits"`     // 因额度不足等待的次数
+	Rejected  int64 `json:"rejected"`  // 等待超时被拒绝的请求数
+	Throttled int64 `json:"throttled"` // 交易所返回限流（429/418）的次数
+}
+
+var (
+	budgetsMu sync.Mutex
+	budgets   = make(map[string]*Budget)
+)
+
+// BudgetFor 获取交易所的权重额度（进程内共享）
+func BudgetFor(exchange string) *Budget {
+	budgetsMu.Lock()
+	defer budgetsMu.Unlock()
+
+	b, ok := budgets[exchange]
+	if !ok {
+		limit, ok := DefaultBudgetLimits[exchange]
+		if !ok {
+			limit = BudgetLimit{Weight: 1200, Window: time.Minute, MaxWait: 10 * time.Second}
+		}
+		b = &Budget{exchange: exchange, limit: limit}
+		budgets[exchange] = b
+	}
+	return b
+}
+
+// ConfigureBudget 设置交易所的权重限制，字段为 0 时保留原值
+func ConfigureBudget(exchange string, limit BudgetLimit) {
+	b := BudgetFor(exchange)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if limit.Weight > 0 {
+		b.limit.Weight = limit.Weight
+	}
+	if limit.Window > 0 {
+		b.limit.Window = limit.Window
+	}
+	if limit.MaxWait > 0 {
+		b.limit.MaxWait = limit.MaxWait
+	}
+}
+
+// BudgetStatsAll 获取所有交易所的额度统计
+func BudgetStatsAll() map[string]BudgetStats {
+	budgetsMu.Lock()
+	list := make([]*Budget, 0, len(budgets))
+	for _, b := range budgets {
+		list = append(list, b)
+	}
+	budgetsMu.Unlock()
+
+	stats := make(map[string]BudgetStats, len(list))
+	for _, b := range list {
+		stats[b.exchange] = b.Stats()
+	}
+	return stats
+}
+
+// Acquire 申请权重额度，额度不足时等待到下一个窗口；等待时间超过 MaxWait 时返回 ErrBudgetExhausted
+func (b *Budget) Acquire(weight int) error {
+	waited := false
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.roll(now)
+
+		var wait time.Duration
+		switch {
+		case now.Before(b.blockedUntil):
+			wait = b.blockedUntil.Sub(now)
+		case b.used+weight <= b.limit.Weight || b.used == 0:
+			// 单次权重超过上限时在空窗口放行，避免永远无法发送
+			b.used += weight
+			b.stats.Requests++
+			b.mu.Unlock()
+			return nil
+		default:
+			wait = b.windowStart.Add(b.limit.Window).Sub(now)
+		}
+
+		if wait > b.limit.MaxWait {
+			b.stats.Rejected++
+			b.mu.Unlock()
+			return fmt.Errorf("%w: %s needs %d, used %d/%d, retry in %v",
+				ErrBudgetExhausted, b.exchange, weight, b.used, b.limit.Weight, wait.Round(time.Millisecond))
+		}
+		if !waited {
+			b.stats.Waits++
+			waited = true
+		}
+		b.mu.Unlock()
+
+		time.Sleep(wait)
+	}
+}
+
+// Observe 根据响应更新额度：同步交易所返回的已用权重，处理限流响应
+func (b *Budget) Observe(resp *http.Response) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.roll(now)
+
+	// Binance 返回当前分钟已用权重，以交易所计数为准（同一 IP 上的其他进程也会占用额度）
+	if v := resp.Header.Get("X-MBX-USED-WEIGHT-1M"); v != "" {
+		if used, err := strconv.Atoi(v); err == nil && used > b.used {
+			b.used = used
+		}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == 418 {
+		b.stats.Throttled++
+
+		retryAfter := b.limit.Window
+		if v := resp.Header.Get("Retry-After"); v != "" {
+			if seconds, err := strconv.Atoi(v); err == nil && seconds > 0 {
+				retryAfter = time.Duration(seconds) * time.Second
+			}
+		}
+		if until := now.Add(retryAfter); until.After(b.blockedUntil) {
+			b.blockedUntil = until
+		}
+		log.Printf("[ALERT] [%s] REST rate limited (status %d), pausing requests for %v\n",
+			b.exchange, resp.StatusCode, retryAfter)
+	}
+}
+
+// Stats 获取额度统计
+func (b *Budget) Stats() BudgetStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.roll(time.Now())
+	stats := b.stats
+	stats.Used = b.used
+	stats.Limit = b.limit.Weight
+	return stats
+}
+
+// roll 进入新窗口时重置已用权重（调用方需持有锁）
+func (b *Budget) roll(now time.Time) {
+	start := now.Truncate(b.limit.Window)
+	if start.After(b.windowStart) {
+		b.windowStart = start
+		b.used = 0
+	}
+}
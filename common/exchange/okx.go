@@ -0,0 +1,218 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OKXREST OKX REST 行情客户端
+type OKXREST struct {
+	baseURL string
+	budget  *Budget
+}
+
+// okxResponse OKX 统一响应格式
+type okxResponse struct {
+	Code string          `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// NewOKXREST 创建 OKX REST 客户端
+func NewOKXREST(baseURL string) *OKXREST {
+	if baseURL == "" {
+		baseURL = "https://www.okx.com"
+	}
+	return &OKXREST{baseURL: baseURL, budget: BudgetFor(constants.ExchangeOKX)}
+}
+
+// Name 交易所名称
+func (o *OKXREST) Name() string {
+	return constants.ExchangeOKX
+}
+
+// GetTicker 获取24小时行情
+func (o *OKXREST) GetTicker(symbol string) (*models.Ticker, error) {
+	var data []map[string]interface{}
+	if err := o.get("/api/v5/market/ticker", url.Values{"instId": {formatOKXSymbol(symbol)}}, &data); err != nil {
+		return nil, fmt.Errorf("[OKX] get ticker: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("[OKX] get ticker: empty data")
+	}
+
+	raw := data[0]
+	return &models.Ticker{
+		Symbol:    symbol,
+		LastPrice: toFloat(raw["last"]),
+		BidPrice:  toFloat(raw["bidPx"]),
+		AskPrice:  toFloat(raw["askPx"]),
+		High24h:   toFloat(raw["high24h"]),
+		Low24h:    toFloat(raw["low24h"]),
+		Volume24h: toFloat(raw["vol24h"]),
+		Open24h:   toFloat(raw["open24h"]),
+		Timestamp: toInt64(raw["ts"]),
+	}, nil
+}
+
+// GetDepth 获取深度快照
+func (o *OKXREST) GetDepth(symbol string, limit int) (*models.OrderBook, error) {
+	var data []struct {
+		Bids [][]interface{} `json:"bids"`
+		Asks [][]interface{} `json:"asks"`
+		Ts   string          `json:"ts"`
+	}
+	params := url.Values{"instId": {formatOKXSymbol(symbol)}, "sz": {strconv.Itoa(limit)}}
+	if err := o.get("/api/v5/market/books", params, &data); err != nil {
+		return nil, fmt.Errorf("[OKX] get depth: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("[OKX] get depth: empty data")
+	}
+
+	return &models.OrderBook{
+		Symbol:    symbol,
+		Bids:      toPriceLevels(data[0].Bids),
+		Asks:      toPriceLevels(data[0].Asks),
+		Timestamp: toInt64(data[0].Ts),
+	}, nil
+}
+
+// GetKlines 获取K线
+// 返回格式: [ts, o, h, l, c, vol, volCcy, volCcyQuote, confirm]，按时间倒序
+func (o *OKXREST) GetKlines(symbol, interval string, startTime, endTime int64, limit int) ([]*models.Kline, error) {
+	bar, ok := okxBars[interval]
+	if !ok {
+		return nil, fmt.Errorf("[OKX] unsupported interval: %s", interval)
+	}
+
+	params := url.Values{
+		"instId": {formatOKXSymbol(symbol)},
+		"bar":    {bar},
+		"limit":  {strconv.Itoa(limit)},
+	}
+	// after: 返回早于该时间的数据；before: 返回晚于该时间的数据
+	if endTime > 0 {
+		params.Set("after", strconv.FormatInt(endTime+1, 10))
+	}
+	if startTime > 0 {
+		params.Set("before", strconv.FormatInt(startTime-1, 10))
+	}
+
+	// 指定时间范围时使用历史K线接口
+	path := "/api/v5/market/candles"
+	if startTime > 0 || endTime > 0 {
+		path = "/api/v5/market/history-candles"
+	}
+
+	var data [][]interface{}
+	if err := o.get(path, params, &data); err != nil {
+		return nil, fmt.Errorf("[OKX] get klines: %w", err)
+	}
+
+	klines := make([]*models.Kline, 0, len(data))
+	for _, item := range data {
+		if len(item) < 8 {
+			continue
+		}
+		openTime := toInt64(item[0])
+		klines = append(klines, &models.Kline{
+			Symbol:   symbol,
+			Interval: interval,
+			OpenTime: openTime,
+			Open:     toFloat(item[1]),
+			High:     toFloat(item[2]),
+			Low:      toFloat(item[3]),
+			Close:    toFloat(item[4]),
+			Volume:   toFloat(item[5]),
+			QuoteVol: toFloat(item[7]),
+		})
+	}
+
+	sort.Slice(klines, func(i, j int) bool {
+		return klines[i].OpenTime < klines[j].OpenTime
+	})
+	// OKX 不返回收盘时间，用下一根K线的开盘时间推算，最后一根沿用前一根的周期长度
+	for i, k := range klines {
+		if i+1 < len(klines) {
+			k.CloseTime = klines[i+1].OpenTime - 1
+		} else if i > 0 {
+			k.CloseTime = k.OpenTime + (k.OpenTime - klines[i-1].OpenTime) - 1
+		}
+	}
+	return klines, nil
+}
+
+// GetTrades 获取最近成交
+func (o *OKXREST) GetTrades(symbol string, limit int) ([]*models.Trade, error) {
+	var data []map[string]interface{}
+	params := url.Values{"instId": {formatOKXSymbol(symbol)}, "limit": {strconv.Itoa(limit)}}
+	if err := o.get("/api/v5/market/trades", params, &data); err != nil {
+		return nil, fmt.Errorf("[OKX] get trades: %w", err)
+	}
+
+	trades := make([]*models.Trade, 0, len(data))
+	for _, item := range data {
+		side := constants.SideSell
+		if s, ok := item["side"].(string); ok && s == "buy" {
+			side = constants.SideBuy
+		}
+		trades = append(trades, &models.Trade{
+			Symbol:    symbol,
+			TradeID:   fmt.Sprintf("%v", item["tradeId"]),
+			Price:     toFloat(item["px"]),
+			Amount:    toFloat(item["sz"]),
+			Side:      side,
+			Timestamp: toInt64(item["ts"]),
+		})
+	}
+
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Timestamp < trades[j].Timestamp
+	})
+	return trades, nil
+}
+
+// get 请求 OKX 接口并解析 data 字段
+func (o *OKXREST) get(path string, params url.Values, data interface{}) error {
+	var resp okxResponse
+	reqURL := strings.TrimSuffix(o.baseURL, "/") + path + "?" + params.Encode()
+	if err := getJSON(o.budget, 1, reqURL, &resp); err != nil {
+		return err
+	}
+	if resp.Code != "0" {
+		return fmt.Errorf("okx error %s: %s", resp.Code, resp.Msg)
+	}
+	return json.Unmarshal(resp.Data, data)
+}
+
+// okxBars K线周期映射（日线及以上使用 UTC 对齐）
+var okxBars = map[string]string{
+	constants.Interval1s:  "1s",
+	constants.Interval1m:  "1m",
+	constants.Interval5m:  "5m",
+	constants.Interval15m: "15m",
+	constants.Interval30m: "30m",
+	constants.Interval1h:  "1H",
+	constants.Interval4h:  "4H",
+	constants.Interval1d:  "1Dutc",
+	constants.Interval1w:  "1Wutc",
+	constants.Interval1M:  "1Mutc",
+}
+
+// formatOKXSymbol 格式化符号 BTCUSDT -> BTC-USDT
+func formatOKXSymbol(symbol string) string {
+	if strings.Contains(symbol, "-") {
+		return symbol
+	}
+	if strings.HasSuffix(symbol, "USDT") {
+		return strings.TrimSuffix(symbol, "USDT") + "-USDT"
+	}
+	return symbol
+}
@@ -0,0 +1,125 @@
+package exchange
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RESTClient 交易所 REST 行情接口
+type RESTClient interface {
+	// Name 交易所名称
+	Name() string
+
+	// GetTicker 获取24小时行情
+	GetTicker(symbol string) (*models.Ticker, error)
+
+	// GetDepth 获取深度快照
+	GetDepth(symbol string, limit int) (*models.OrderBook, error)
+
+	// GetKlines 获取K线，startTime/endTime 为 0 表示不限制，结果按开盘时间升序
+	GetKlines(symbol, interval string, startTime, endTime int64, limit int) ([]*models.Kline, error)
+
+	// GetTrades 获取最近成交，结果按时间升序
+	GetTrades(symbol string, limit int) ([]*models.Trade, error)
+}
+
+// NewRESTClient 根据交易所名称创建 REST 客户端，不支持的交易所返回 nil
+func NewRESTClient(name string) RESTClient {
+	switch name {
+	case constants.ExchangeBinance:
+		return NewBinanceREST("")
+	case constants.ExchangeOKX:
+		return NewOKXREST("")
+	default:
+		return nil
+	}
+}
+
+// httpClient 共享的 HTTP 客户端
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// getJSON 申请权重额度后发送 GET 请求并解析 JSON 响应
+func getJSON(budget *Budget, weight int, url string, v interface{}) error {
+	if err := budget.Acquire(weight); err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	budget.Observe(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	// 数字按 json.Number 解析，成交 ID 等 64 位整数不经过 float64
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// toFloat 将交易所返回的字符串/数字转换为 float64
+func toFloat(v interface{}) float64 {
+	switch val := v.(type) {
+	case float64:
+		return val
+	case string:
+		f, _ := strconv.ParseFloat(val, 64)
+		return f
+	case json.Number:
+		f, _ := val.Float64()
+		return f
+	default:
+		return 0
+	}
+}
+
+// toInt64 将交易所返回的字符串/数字转换为 int64
+func toInt64(v interface{}) int64 {
+	switch val := v.(type) {
+	case float64:
+		return int64(val)
+	case string:
+		i, _ := strconv.ParseInt(val, 10, 64)
+		return i
+	case json.Number:
+		i, _ := val.Int64()
+		return i
+	default:
+		return 0
+	}
+}
+
+// toPriceLevels 解析 [[price, amount, ...], ...] 格式的档位
+func toPriceLevels(raw [][]interface{}) []models.PriceLevel {
+	levels := make([]models.PriceLevel, 0, len(raw))
+	for _, item := range raw {
+		if len(item) < 2 {
+			continue
+		}
+		levels = append(levels, models.PriceLevel{
+			Price:  toFloat(item[0]),
+			Amount: toFloat(item[1]),
+		})
+	}
+	return levels
+}
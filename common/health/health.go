@@ -0,0 +1,230 @@
+// Package health 服务的存活和就绪检查接口（/healthz、/readyz），供 Kubernetes 探针使用
+//
+// /healthz 只执行存活检查（进程内部状态），外部依赖不可用时不应重启进程；
+// /readyz 另外检查外部依赖（Redis、Kafka、交易所连接、消费延迟等），任一检查失败时返回 503。
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"market-system/common/version"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// 探针路径
+const (
+	PathLiveness  = "/healthz"
+	PathReadiness = "/readyz"
+)
+
+// 检查状态
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// DefaultTimeout 单项检查的默认超时时间
+const DefaultTimeout = 2 * time.Second
+
+// Check 检查一项状态或依赖，返回错误表示不可用
+type Check func(ctx context.Context) error
+
+// Report 检查结果
+type Report struct {
+	Status    string                 `json:"status"`
+	Service   string                 `json:"service"`
+	Version   string                 `json:"version"`
+	Checks    map[string]CheckResult `json:"checks"`
+	Timestamp int64                  `json:"timestamp"`
+}
+
+// CheckResult 单项检查结果
+type CheckResult struct {
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker 健康检查，检查项在启动时注册
+type Checker struct {
+	service string
+	timeout time.Duration
+
+	mu        sync.RWMutex
+	liveness  []namedCheck
+	readiness []namedCheck
+}
+
+// New 创建健康检查
+func New(service string) *Checker {
+	return &Checker{service: service, timeout: DefaultTimeout}
+}
+
+// SetTimeout 设置单项检查的超时时间
+func (c *Checker) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		c.timeout = timeout
+	}
+}
+
+// AddLiveness 注册存活检查，/healthz 和 /readyz 都会执行
+func (c *Checker) AddLiveness(name string, check Check) {
+	c.mu.Lock()
+	c.liveness = append(c.liveness, namedCheck{name, check})
+	c.mu.Unlock()
+}
+
+// AddReadiness 注册就绪检查（外部依赖），只在 /readyz 执行
+func (c *Checker) AddReadiness(name string, check Check) {
+	c.mu.Lock()
+	c.readiness = append(c.readiness, namedCheck{name, check})
+	c.mu.Unlock()
+}
+
+// Liveness 执行存活检查
+func (c *Checker) Liveness(ctx context.Context) *Report {
+	c.mu.RLock()
+	checks := append([]namedCheck(nil), c.liveness...)
+	c.mu.RUnlock()
+	return c.run(ctx, checks)
+}
+
+// Readiness 执行存活检查和就绪检查
+func (c *Checker) Readiness(ctx context.Context) *Report {
+	c.mu.RLock()
+	checks := append(append([]namedCheck(nil), c.liveness...), c.readiness...)
+	c.mu.RUnlock()
+	return c.run(ctx, checks)
+}
+
+// run 并发执行检查，每项检查单独计算超时
+func (c *Checker) run(ctx context.Context, checks []namedCheck) *Report {
+	report := &Report{
+		Status:  StatusOK,
+		Service: c.service,
+		Version: version.Version,
+		Checks:  make(map[string]CheckResult, len(checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, nc := range checks {
+		wg.Add(1)
+		go func(nc namedCheck) {
+			defer wg.Done()
+			result := c.runOne(ctx, nc.check)
+			mu.Lock()
+			report.Checks[nc.name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusUnavailable
+			}
+			mu.Unlock()
+		}(nc)
+	}
+	wg.Wait()
+
+	report.Timestamp = time.Now().UnixMilli()
+	return report
+}
+
+func (c *Checker) runOne(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = errors.New("timeout")
+	}
+
+	result := CheckResult{Status: StatusOK, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = StatusUnavailable
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// LivenessHandler /healthz 处理函数
+func (c *Checker) LivenessHandler(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, c.Liveness(r.Context()))
+}
+
+// ReadinessHandler /readyz 处理函数
+func (c *Checker) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, c.Readiness(r.Context()))
+}
+
+// writeReport 输出检查结果，不可用时状态码为 503
+func writeReport(w http.ResponseWriter, report *Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	if report.Status != StatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// Serve 在独立端口上启动探针服务（没有 HTTP 服务的 Collector、Processor 使用），返回的 Server 用于关闭
+func (c *Checker) Serve(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathLiveness, c.LivenessHandler)
+	mux.HandleFunc(PathReadiness, c.ReadinessHandler)
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("[Health] Server stopped: %v\n", err)
+		}
+	}()
+	log.Printf("[Health] Listening on %s\n", addr)
+	return server
+}
+
+// KafkaCheck 检查 Kafka 是否可达：依次连接各 broker 并读取集群元数据，任一 broker 可用即视为可达
+func KafkaCheck(brokers []string) Check {
+	return func(ctx context.Context) error {
+		if len(brokers) == 0 {
+			return errors.New("no kafka brokers configured")
+		}
+
+		var lastErr error
+		for _, broker := range brokers {
+			conn, err := kafka.DialContext(ctx, "tcp", broker)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if deadline, ok := ctx.Deadline(); ok {
+				conn.SetDeadline(deadline)
+			}
+			_, err = conn.Brokers()
+			conn.Close()
+			if err == nil {
+				return nil
+			}
+			lastErr = err
+		}
+		return lastErr
+	}
+}
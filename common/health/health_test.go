@@ -0,0 +1,64 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckerHandlers(t *testing.T) {
+	c := New("processor")
+	c.AddLiveness("pipeline", func(ctx context.Context) error { return nil })
+	c.AddReadiness("redis", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	rec := httptest.NewRecorder()
+	c.LivenessHandler(rec, httptest.NewRequest(http.MethodGet, PathLiveness, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("healthz status = %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	c.ReadinessHandler(rec, httptest.NewRequest(http.MethodGet, PathReadiness, nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("readyz status = %d", rec.Code)
+	}
+	var report Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != StatusUnavailable || report.Service != "processor" {
+		t.Errorf("report = %+v", report)
+	}
+	if got := report.Checks["redis"]; got.Status != StatusUnavailable || got.Error != "connection refused" {
+		t.Errorf("redis check = %+v", got)
+	}
+	if got := report.Checks["pipeline"]; got.Status != StatusOK {
+		t.Errorf("pipeline check = %+v", got)
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	c := New("api")
+	c.SetTimeout(20 * time.Millisecond)
+	block := make(chan struct{})
+	defer close(block)
+	c.AddReadiness("slow", func(ctx context.Context) error {
+		<-block
+		return nil
+	})
+
+	report := c.Readiness(context.Background())
+	if got := report.Checks["slow"]; got.Status != StatusUnavailable || got.Error != "timeout" {
+		t.Errorf("slow check = %+v", got)
+	}
+}
+
+func TestKafkaCheckNoBrokers(t *testing.T) {
+	if err := KafkaCheck(nil)(context.Background()); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"hash/crc32"
+	"strconv"
+	"strings"
+)
+
+// NewKlineUpdate 创建K线推送消息，update_id 取K线内的成交笔数
+func NewKlineUpdate(kline *Kline, closed bool) *KlineUpdate {
+	return &KlineUpdate{
+		Kline:    *kline,
+		UpdateID: kline.TradeNum,
+		Closed:   closed,
+		Checksum: KlineChecksum(kline),
+	}
+}
+
+// KlineChecksum 计算K线内容的 CRC32
+// 按 symbol|interval|open_time|open|high|low|close|volume|quote_vol|trade_num 拼接，
+// 浮点数使用最短表示（与 JSON 序列化一致），客户端可用相同规则复算
+func KlineChecksum(k *Kline) uint32 {
+	fields := []string{
+		k.Symbol,
+		k.Interval,
+		strconv.FormatInt(k.OpenTime, 10),
+		formatFloat(k.Open),
+		formatFloat(k.High),
+		formatFloat(k.Low),
+		formatFloat(k.Close),
+		formatFloat(k.Volume),
+		formatFloat(k.QuoteVol),
+		strconv.FormatInt(k.TradeNum, 10),
+	}
+	return crc32.ChecksumIEEE([]byte(strings.Join(fields, "|")))
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
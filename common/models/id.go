@@ -0,0 +1,37 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// ID 64 位整数 ID
+// JSON 序列化为字符串，避免 JavaScript 等按双精度浮点解析时丢失精度；解析时兼容数字和字符串。
+type ID int64
+
+// String 十进制表示
+func (id ID) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// MarshalJSON 序列化为字符串
+func (id ID) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(id.String())), nil
+}
+
+// UnmarshalJSON 解析数字或字符串，null 和空字符串解析为 0
+func (id *ID) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if len(data) == 0 || string(data) == "null" {
+		*id = 0
+		return nil
+	}
+
+	v, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid id %s: %w", data, err)
+	}
+	*id = ID(v)
+	return nil
+}
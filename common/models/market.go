@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
 
 // MarketData 统一的市场数据格式
 type MarketData struct {
@@ -9,19 +13,54 @@ type MarketData struct {
 	Type      string      `json:"type"` // ticker, depth, trade, kline
 	Source    string      `json:"source"` // internal, external, merged
 	Timestamp int64       `json:"timestamp"`
+	Endpoint  string      `json:"endpoint,omitempty"` // 提供数据的交易所接入点（host:port）
 	Data      interface{} `json:"data"`
 }
 
+// MarketMessage 从 Kafka 读取的市场数据，格式与 MarketData 相同
+// Data 保留原始 JSON，处理方按 Type 直接解析为 Ticker / OrderBook / Trade / Kline，
+// 不经过 map[string]interface{} 逐字段转换，字段类型不匹配时返回错误而不是静默丢失
+type MarketMessage struct {
+	Exchange  string          `json:"exchange"`
+	Symbol    string          `json:"symbol"`
+	Type      string          `json:"type"`
+	Source    string          `json:"source"`
+	Timestamp int64           `json:"timestamp"`
+	Endpoint  string          `json:"endpoint,omitempty"`
+	Data      json.RawMessage `json:"data"`
+	Partition int             `json:"-"` // 消息所在的 Kafka 分区，消费时设置
+	Raw       []byte          `json:"-"` // Kafka 消息原文，消费时设置，处理失败转入死信队列时原样写入
+	Headers   []Header        `json:"-"` // Kafka 消息头（包括版本消息头），消费时设置，转入死信队列时原样保留
+}
+
+// Header Kafka 消息头
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// Decode 将 Data 解析为具体的市场数据，Data 为空时返回错误
+func (m *MarketMessage) Decode(v interface{}) error {
+	if len(m.Data) == 0 || string(m.Data) == "null" {
+		return errors.New("missing data")
+	}
+	return json.Unmarshal(m.Data, v)
+}
+
 // Ticker 行情快照
 type Ticker struct {
-	Symbol    string  `json:"symbol"`
-	LastPrice float64 `json:"last_price"`
-	BidPrice  float64 `json:"bid_price"`
-	AskPrice  float64 `json:"ask_price"`
-	High24h   float64 `json:"high_24h"`
-	Low24h    float64 `json:"low_24h"`
-	Volume24h float64 `json:"volume_24h"`
-	Timestamp int64   `json:"timestamp"`
+	Symbol                string  `json:"symbol"`
+	LastPrice             float64 `json:"last_price"`
+	BidPrice              float64 `json:"bid_price"`
+	AskPrice              float64 `json:"ask_price"`
+	High24h               float64 `json:"high_24h"`
+	Low24h                float64 `json:"low_24h"`
+	Volume24h             float64 `json:"volume_24h"`
+	Open24h               float64 `json:"open_24h"`                 // 24 小时前的价格（窗口内第一笔成交价）
+	PriceChange24h        float64 `json:"price_change_24h"`         // 24 小时涨跌额
+	PriceChangePercent24h float64 `json:"price_change_percent_24h"` // 24 小时涨跌幅（%）
+	TradeCount24h         int64   `json:"trade_count_24h"`          // 24 小时成交笔数，交易所未提供时为 0
+	Timestamp             int64   `json:"timestamp"`
 }
 
 // Trade 成交记录
@@ -32,6 +71,56 @@ type Trade struct {
 	Amount    float64 `json:"amount"`
 	Side      string  `json:"side"` // buy, sell
 	Timestamp int64   `json:"timestamp"`
+	Source    string  `json:"source,omitempty"`   // internal 为内部成交，external 为交易所成交
+	Exchange  string  `json:"exchange,omitempty"` // 数据来源交易所，内部成交为 internal
+}
+
+// AggTrade 聚合成交：聚合窗口内同一价格、同一方向的连续成交合并为一条（对应 Binance aggTrade）
+type AggTrade struct {
+	Symbol       string  `json:"symbol"`
+	AggID        int64   `json:"agg_id"` // 交易对内递增
+	Price        float64 `json:"price"`
+	Amount       float64 `json:"amount"` // 合并的成交数量之和
+	Side         string  `json:"side"`   // buy, sell
+	FirstTradeID string  `json:"first_trade_id"`
+	LastTradeID  string  `json:"last_trade_id"`
+	Count        int64   `json:"count"`      // 合并的成交笔数
+	FirstTime    int64   `json:"first_time"` // 第一笔成交时间（毫秒）
+	Timestamp    int64   `json:"timestamp"`  // 最后一笔成交时间（毫秒）
+	Source       string  `json:"source,omitempty"`
+	Exchange     string  `json:"exchange,omitempty"`
+}
+
+// BookTicker 最优买卖价（盘口第一档），价格或数量变化时推送
+type BookTicker struct {
+	Symbol    string  `json:"symbol"`
+	BidPrice  float64 `json:"bid_price"`
+	BidQty    float64 `json:"bid_qty"`
+	AskPrice  float64 `json:"ask_price"`
+	AskQty    float64 `json:"ask_qty"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+// BookStats 订单簿统计
+type BookStats struct {
+	Symbol    string          `json:"symbol"`
+	BidPrice  float64         `json:"bid_price"`
+	AskPrice  float64         `json:"ask_price"`
+	MidPrice  float64         `json:"mid_price"`
+	Spread    float64         `json:"spread"`     // 卖一价 - 买一价
+	SpreadBps float64         `json:"spread_bps"` // 价差相对中间价（基点）
+	Imbalance float64         `json:"imbalance"`  // 前 N 档 (买量 - 卖量) / (买量 + 卖量)，范围 -1 ~ 1，大于 0 表示买盘更厚
+	Liquidity []LiquidityBand `json:"liquidity"`
+	Timestamp int64           `json:"timestamp"`
+}
+
+// LiquidityBand 距中间价 ±Percent% 范围内的累计挂单量
+type LiquidityBand struct {
+	Percent     float64 `json:"percent"`
+	BidAmount   float64 `json:"bid_amount"`
+	AskAmount   float64 `json:"ask_amount"`
+	BidNotional float64 `json:"bid_notional"` // 挂单金额（价格 × 数量）
+	AskNotional float64 `json:"ask_notional"`
 }
 
 // Kline K线数据
@@ -45,8 +134,23 @@ type Kline struct {
 	Low       float64 `json:"low"`
 	Close     float64 `json:"close"`
 	Volume    float64 `json:"volume"`
-	QuoteVol  float64 `json:"quote_vol"` // 成交额
-	TradeNum  int64   `json:"trade_num"` // 成交笔数
+	QuoteVol  float64 `json:"quote_vol"`        // 成交额
+	TradeNum  int64   `json:"trade_num"`        // 成交笔数
+	Source    string  `json:"source,omitempty"` // local 为本地成交聚合，交易所名称表示交易所推送的K线
+	Revision  int64   `json:"revision"`         // 修订号，首次保存为 0，回补、补齐、重建改写已保存的K线时递增
+	IsFinal   bool    `json:"is_final"`         // 已收盘并保存，实时K线为 false
+}
+
+// KlineUpdate WebSocket 推送的K线更新（实时K线和收盘K线）
+// 同一根K线 (symbol, interval, open_time) 的 update_id 随成交单调递增，
+// 客户端按 (open_time, revision, update_id) 去重并丢弃过期更新，update_id 相同时以 closed=true 为准；
+// 修订后的K线（revision 更大）内容可能与原K线不同，update_id 也可能变小，客户端应原位替换；
+// checksum 为K线内容的 CRC32，用于校验本地K线与服务端是否一致
+type KlineUpdate struct {
+	Kline
+	UpdateID int64  `json:"update_id"`
+	Closed   bool   `json:"closed"`
+	Checksum uint32 `json:"checksum"`
 }
 
 // OrderBook 订单簿
@@ -71,6 +175,19 @@ type DepthUpdate struct {
 	Timestamp int64        `json:"timestamp"`
 }
 
+// DepthDiff WebSocket depthDiff 频道推送的深度增量
+// seq 在交易对内递增，prev_seq 为上一条消息的 seq，客户端据此检查是否丢失消息；
+// snapshot 为 true 时 bids/asks 为全量深度，客户端用其替换本地订单簿，否则只包含变化的档位，数量为 0 表示删除该价位
+type DepthDiff struct {
+	Symbol    string       `json:"symbol"`
+	Seq       int64        `json:"seq"`
+	PrevSeq   int64        `json:"prev_seq"`
+	Snapshot  bool         `json:"snapshot"`
+	Bids      []PriceLevel `json:"bids"`
+	Asks      []PriceLevel `json:"asks"`
+	Timestamp int64        `json:"timestamp"`
+}
+
 // KafkaMessage Kafka 消息格式
 type KafkaMessage struct {
 	Topic     string      `json:"topic"`
@@ -79,6 +196,159 @@ type KafkaMessage struct {
 	Timestamp time.Time   `json:"timestamp"`
 }
 
+// ReferencePrice 参考价格（按时间加权平均价格），key 为窗口名称，例如 5m、30m、1h
+type ReferencePrice struct {
+	Symbol    string             `json:"symbol"`
+	LastPrice float64            `json:"last_price"`
+	TWAP      map[string]float64 `json:"twap"`
+	Coverage  map[string]float64 `json:"coverage"` // 窗口内已有采样覆盖的比例，启动后不足一个窗口时小于 1
+	Timestamp int64              `json:"timestamp"`
+}
+
+// IndexPrice 指数价格：各外部交易所最新价按权重计算的中位数或均值
+type IndexPrice struct {
+	Symbol     string           `json:"symbol"`
+	Price      float64          `json:"price"`
+	Method     string           `json:"method"`     // weighted_median, weighted_mean
+	Components []IndexComponent `json:"components"` // 参与计算的交易所，包括被剔除的异常价格
+	Timestamp  int64            `json:"timestamp"`
+}
+
+// IndexComponent 指数价格的成分
+type IndexComponent struct {
+	Exchange  string  `json:"exchange"`
+	Price     float64 `json:"price"`
+	Weight    float64 `json:"weight"`             // 归一化后的权重，被剔除时为 0
+	Excluded  bool    `json:"excluded,omitempty"` // 偏离中位数超过阈值，不参与计算
+	Timestamp int64   `json:"timestamp"`          // 收到该交易所价格的时间（毫秒）
+}
+
+// Indicators 按K线收盘价计算的技术指标，K线数不足周期的指标不返回
+// SMA、EMA、RSI 的 key 为周期，例如 7、25、99
+type Indicators struct {
+	Symbol    string             `json:"symbol"`
+	Interval  string             `json:"interval"`
+	OpenTime  int64              `json:"open_time"` // 最新一根K线的开盘时间
+	Closed    bool               `json:"closed"`    // 最新一根K线是否已收盘，未收盘时按当前价格计算
+	Close     float64            `json:"close"`
+	SMA       map[string]float64 `json:"sma"`
+	EMA       map[string]float64 `json:"ema"`
+	RSI       map[string]float64 `json:"rsi"`
+	MACD      *MACD              `json:"macd,omitempty"`
+	BOLL      *BOLL              `json:"boll,omitempty"`
+	Timestamp int64              `json:"timestamp"`
+}
+
+// MACD 指数平滑异同移动平均线
+type MACD struct {
+	DIF       float64 `json:"dif"`       // 快线 EMA 与慢线 EMA 之差
+	DEA       float64 `json:"dea"`       // DIF 的 EMA（信号线）
+	Histogram float64 `json:"histogram"` // DIF - DEA
+}
+
+// BOLL 布林带
+type BOLL struct {
+	Upper  float64 `json:"upper"`
+	Middle float64 `json:"middle"`
+	Lower  float64 `json:"lower"`
+}
+
+// VWAPPrice 滚动的成交量加权平均价格，key 为窗口名称，例如 1m、5m、1h，窗口内没有成交时不返回
+type VWAPPrice struct {
+	Symbol    string             `json:"symbol"`
+	VWAP      map[string]float64 `json:"vwap"`
+	Volume    map[string]float64 `json:"volume"` // 窗口内的成交量
+	Timestamp int64              `json:"timestamp"`
+}
+
+// MarketStats 由成交计算的 24 小时滚动行情统计，比 Ticker 多出成交额
+type MarketStats struct {
+	Symbol             string  `json:"symbol"`
+	Open               float64 `json:"open"` // 窗口内第一笔成交价，窗口内没有成交时为最新价
+	High               float64 `json:"high"`
+	Low                float64 `json:"low"`
+	LastPrice          float64 `json:"last_price"`
+	Volume             float64 `json:"volume"`       // 成交量
+	QuoteVolume        float64 `json:"quote_volume"` // 成交额（计价币）
+	PriceChange        float64 `json:"price_change"`
+	PriceChangePercent float64 `json:"price_change_percent"`
+	TradeCount         int64   `json:"trade_count"`
+	Timestamp          int64   `json:"timestamp"`
+}
+
+// Alert 异常检测告警
+type Alert struct {
+	Symbol    string  `json:"symbol"`
+	Type      string  `json:"type"` // price_deviation, volume_spike
+	Exchange  string  `json:"exchange,omitempty"`
+	Source    string  `json:"source,omitempty"`
+	Price     float64 `json:"price"`              // 触发告警的成交价
+	TradeID   string  `json:"trade_id,omitempty"` // 触发告警的成交
+	Value     float64 `json:"value"`              // price_deviation 为偏离 EMA 的百分比（带符号），volume_spike 为当前窗口成交量
+	Reference float64 `json:"reference"`          // price_deviation 为 EMA，volume_spike 为基准窗口的平均成交量
+	Threshold float64 `json:"threshold"`          // price_deviation 为偏离阈值（%），volume_spike 为倍数阈值
+	Message   string  `json:"message"`
+	Timestamp int64   `json:"timestamp"` // 触发告警的成交时间
+}
+
+// PriceBand 内部市场的动态价格带（涨跌停）
+type PriceBand struct {
+	Symbol    string  `json:"symbol"`
+	Event     string  `json:"event"`     // update: 参考价格更新；limit_up / limit_down: 成交价触及上限 / 下限；normal: 回到价格带内
+	State     string  `json:"state"`     // normal, limit_up, limit_down
+	Reference float64 `json:"reference"` // 参考价格
+	Upper     float64 `json:"upper"`     // 价格带上限
+	Lower     float64 `json:"lower"`     // 价格带下限
+	LastPrice float64 `json:"last_price"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+// BackfillRequest 历史K线回补请求（管理接口经 Redis 发送给 Processor），为空表示使用 Processor 的配置
+type BackfillRequest struct {
+	Symbols   []string `json:"symbols,omitempty"`
+	Intervals []string `json:"intervals,omitempty"`
+}
+
+// AdapterCommand 交易所适配器控制命令（管理接口经 Redis 发送给 Collector）
+type AdapterCommand struct {
+	Action   string `json:"action"`   // reconnect
+	Exchange string `json:"exchange"` // 交易所名称
+}
+
+// AdapterActionReconnect 强制重新连接交易所
+const AdapterActionReconnect = "reconnect"
+
+// SymbolGroup 交易对分组（如 majors、defi），对应前端的分类标签
+// 分组内交易对的 ticker、深度、成交同时推送到分组频道，如 ticker:group:majors
+type SymbolGroup struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Symbols     []string `json:"symbols"`
+}
+
+// PriceAlert 用户定义的价格提醒，由 API 创建，Processor 按 Ticker 判断条件
+type PriceAlert struct {
+	ID        string  `json:"id"`
+	Owner     string  `json:"owner,omitempty"` // 创建者的认证身份，未开启认证时为空
+	Symbol    string  `json:"symbol"`
+	Condition string  `json:"condition"`         // price_cross_up, price_cross_down, change_above, change_below
+	Value     float64 `json:"value"`             // 价格，或 24 小时涨跌幅（%）
+	Webhook   string  `json:"webhook,omitempty"` // 触发时 POST PriceAlertEvent 的地址
+	Repeat    bool    `json:"repeat"`            // 触发后继续生效，否则触发后删除
+	CreatedAt int64   `json:"created_at"`
+}
+
+// PriceAlertEvent 价格提醒触发通知
+type PriceAlertEvent struct {
+	AlertID       string  `json:"alert_id"`
+	Symbol        string  `json:"symbol"`
+	Condition     string  `json:"condition"`
+	Value         float64 `json:"value"`
+	Price         float64 `json:"price"`          // 触发时的最新价
+	ChangePercent float64 `json:"change_percent"` // 触发时的 24 小时涨跌幅
+	Timestamp     int64   `json:"timestamp"`
+}
+
 // ========== 混合模式相关模型 ==========
 
 // SymbolConfig 交易对配置
@@ -87,16 +357,41 @@ type SymbolConfig struct {
 	Mode            string `json:"mode"` // INTERNAL_ONLY, EXTERNAL_ONLY, HYBRID
 	PrimarySource   string `json:"primary_source"` // internal, external
 	ExternalSource  string `json:"external_source"` // binance, okx, etc.
-	MergeStrategy   string `json:"merge_strategy"` // priority, supplement, override
+	MergeStrategy   string `json:"merge_strategy"` // priority, supplement, override, vwap, median, trimmed_mean
 	Enable          bool   `json:"enable"`
 	Description     string `json:"description"`
+	TickSize        float64 `json:"tick_size"` // 价格最小变动单位，用于深度合并时对齐价格，0 表示按原价合并
+	VWAP            VWAPConfig `json:"vwap"` // vwap 融合策略配置
+	FreshnessMs     int64   `json:"freshness_ms"`     // 数据新鲜度阈值（毫秒），0 表示使用混合模式默认配置
+	MaxDepthLevels  int     `json:"max_depth_levels"` // 融合深度最大档位数，0 表示使用混合模式默认配置
+	TrimPercent     float64 `json:"trim_percent"`     // trimmed_mean 策略两端各剔除的数据源比例（%），0 表示使用混合模式默认配置
+	Deleted         bool    `json:"deleted"`          // 已软删除：REST/WebSocket 不再返回，Processor 不再处理，已有数据保留，可恢复
+	DeletedAt       int64   `json:"deleted_at"`       // 软删除时间（毫秒），未删除时为 0
+}
+
+// SymbolConfigEvent 交易对配置变更事件，删除时 Config 为空
+type SymbolConfigEvent struct {
+	Event     string        `json:"event"` // added, updated, enabled, disabled, removed, deleted, restored
+	Symbol    string        `json:"symbol"`
+	Config    *SymbolConfig `json:"config,omitempty"`
+	Timestamp int64         `json:"timestamp"`
+}
+
+// VWAPConfig 成交量加权融合策略配置
+type VWAPConfig struct {
+	InternalWeight    float64 `json:"internal_weight"`     // 内部数据源权重，0 表示默认权重 1
+	ExternalWeight    float64 `json:"external_weight"`     // 外部数据源权重，0 表示默认权重 1
+	MinInternalVolume float64 `json:"min_internal_volume"` // 内部24h成交量低于该值时不参与加权
+	MinExternalVolume float64 `json:"min_external_volume"` // 外部24h成交量低于该值时不参与加权
 }
 
 // PriceLevelWithSource 带来源的价格档位
 type PriceLevelWithSource struct {
-	Price  float64 `json:"price"`
-	Amount float64 `json:"amount"`
-	Source string  `json:"source"` // internal, external
+	Price          float64 `json:"price"`
+	Amount         float64 `json:"amount"`
+	Source         string  `json:"source"`                    // internal, external, merged
+	InternalAmount float64 `json:"internal_amount,omitempty"` // 内部数据源在该档位的数量
+	ExternalAmount float64 `json:"external_amount,omitempty"` // 外部数据源在该档位的数量
 }
 
 // OrderBookWithSource 带来源的订单簿
@@ -113,30 +408,34 @@ type OrderBookWithSource struct {
 
 // TickerWithSource 带来源的行情数据
 type TickerWithSource struct {
-	Symbol             string  `json:"symbol"`
-	LastPrice          float64 `json:"last_price"`
-	LastPriceSource    string  `json:"last_price_source"` // internal, external
-	BidPrice           float64 `json:"bid_price"`
-	AskPrice           float64 `json:"ask_price"`
-	High24h            float64 `json:"high_24h"`
-	Low24h             float64 `json:"low_24h"`
-	InternalVolume24h  float64 `json:"internal_volume_24h"`
-	ExternalVolume24h  float64 `json:"external_volume_24h"`
-	TotalVolume24h     float64 `json:"total_volume_24h"`
-	Timestamp          int64   `json:"timestamp"`
+	Symbol                string  `json:"symbol"`
+	LastPrice             float64 `json:"last_price"`
+	LastPriceSource       string  `json:"last_price_source"` // internal, external
+	BidPrice              float64 `json:"bid_price"`
+	AskPrice              float64 `json:"ask_price"`
+	High24h               float64 `json:"high_24h"`
+	Low24h                float64 `json:"low_24h"`
+	Open24h               float64 `json:"open_24h"`
+	PriceChange24h        float64 `json:"price_change_24h"` // 按融合后的最新价计算
+	PriceChangePercent24h float64 `json:"price_change_percent_24h"`
+	TradeCount24h         int64   `json:"trade_count_24h"` // 内部与外部成交笔数之和
+	InternalVolume24h     float64 `json:"internal_volume_24h"`
+	ExternalVolume24h     float64 `json:"external_volume_24h"`
+	TotalVolume24h        float64 `json:"total_volume_24h"`
+	Timestamp             int64   `json:"timestamp"`
 }
 
 // InternalTradeMessage 内部交易引擎推送的消息
 type InternalTradeMessage struct {
 	Symbol      string  `json:"symbol"`
-	TradeID     int64   `json:"trade_id"`
+	TradeID     ID      `json:"trade_id"` // 64 位 ID 序列化为字符串，解析时兼容数字
 	Price       float64 `json:"price"`
 	Amount      float64 `json:"amount"`
 	Side        string  `json:"side"` // buy, sell
-	BuyerID     int64   `json:"buyer_id"`
-	SellerID    int64   `json:"seller_id"`
-	BuyOrderID  int64   `json:"buy_order_id"`
-	SellerOrderID int64 `json:"sell_order_id"`
+	BuyerID     ID      `json:"buyer_id"`
+	SellerID    ID      `json:"seller_id"`
+	BuyOrderID  ID      `json:"buy_order_id"`
+	SellerOrderID ID    `json:"sell_order_id"`
 	Timestamp   int64   `json:"timestamp"`
 	IsMaker     bool    `json:"is_maker"`
 }
@@ -159,3 +458,99 @@ type DataSourceStats struct {
 	ExternalTradeNum  int64   `json:"external_trade_num"` // 外部成交笔数
 	LastUpdateTime    int64   `json:"last_update_time"`
 }
+
+// ServiceStats 服务运行统计，由各服务定期写入 Redis，供 API 汇总展示
+type ServiceStats struct {
+	Service      string             `json:"service"` // collector, processor
+	Timestamp    int64              `json:"timestamp"`
+	MessageRates map[string]float64 `json:"message_rates,omitempty"` // 每秒消息数，key 为数据类型
+	KafkaLag     map[string]int64   `json:"kafka_lag,omitempty"`     // 消费延迟，key 为 topic
+	Exchanges    map[string]bool    `json:"exchanges,omitempty"`     // 交易所连接状态
+	// 交易所适配器各类错误的累计次数，key 为交易所，内层 key 为错误类别
+	AdapterErrors map[string]map[string]int64 `json:"adapter_errors,omitempty"`
+}
+
+// WebhookStats Webhook 推送统计，key 为地址名称
+type WebhookStats struct {
+	Timestamp int64                           `json:"timestamp"`
+	Endpoints map[string]WebhookEndpointStats `json:"endpoints"`
+}
+
+// WebhookEndpointStats 单个 Webhook 地址的推送统计（启动以来累计）
+type WebhookEndpointStats struct {
+	URL            string `json:"url"`
+	Delivered      int64  `json:"delivered"`        // 发送成功的事件数
+	Failed         int64  `json:"failed"`           // 重试后仍失败的事件数
+	Retries        int64  `json:"retries"`          // 重试次数
+	Dropped        int64  `json:"dropped"`          // 队列满时丢弃的事件数
+	Pending        int    `json:"pending"`          // 队列中待发送的事件数
+	LastStatus     int    `json:"last_status"`      // 最近一次请求的 HTTP 状态码，请求失败时为 0
+	LastError      string `json:"last_error"`       // 最近一次失败的原因
+	LastLatencyMs  int64  `json:"last_latency_ms"`  // 最近一次请求的耗时
+	LastDeliveryAt int64  `json:"last_delivery_at"` // 最近一次发送成功的时间
+}
+
+// KlineConsistencyReport 本地聚合的 1m K线与交易所 REST K线的一致性检查报告（最近一次检查）
+type KlineConsistencyReport struct {
+	Interval      string             `json:"interval"`
+	Symbols       int                `json:"symbols"`    // 检查的交易对数
+	Compared      int                `json:"compared"`   // 比较的K线数
+	Mismatched    int                `json:"mismatched"` // 偏差超过容差的K线数
+	Missing       int                `json:"missing"`    // 本地缺失的K线数
+	Repaired      int                `json:"repaired"`   // 已用交易所K线修复的K线数
+	Errors        []string           `json:"errors,omitempty"`
+	Discrepancies []KlineDiscrepancy `json:"discrepancies"` // 最多保留前 200 条
+	StartTime     int64              `json:"start_time"`    // 检查范围（开盘时间，毫秒）
+	EndTime       int64              `json:"end_time"`
+	Timestamp     int64              `json:"timestamp"`
+}
+
+// KlineDiscrepancy 单根K线的偏差，记录偏差最大的字段
+type KlineDiscrepancy struct {
+	Symbol   string  `json:"symbol"`
+	Exchange string  `json:"exchange"`
+	OpenTime int64   `json:"open_time"`
+	Field    string  `json:"field"` // open, high, low, close, volume；本地缺失时为 missing
+	Local    float64 `json:"local"`
+	Remote   float64 `json:"remote"`
+	Diff     float64 `json:"diff"` // 相对偏差（%）
+	Repaired bool    `json:"repaired"`
+}
+
+// DailyTradeTotals 交易对单日（UTC）成交汇总
+type DailyTradeTotals struct {
+	Symbol      string  `json:"symbol"`
+	TradeCount  int64   `json:"trade_count"`
+	Volume      float64 `json:"volume"`       // 成交量
+	QuoteVolume float64 `json:"quote_volume"` // 成交额
+}
+
+// EngineDailyTotals 撮合引擎的单日成交汇总（引擎经内部适配器推送，或由 Processor 从引擎接口拉取）
+type EngineDailyTotals struct {
+	Date    string             `json:"date"` // UTC 日期，如 2025-11-11
+	Symbols []DailyTradeTotals `json:"symbols"`
+}
+
+// TradeReconcileReport 撮合引擎与行情系统单日内部成交汇总的对账报告
+type TradeReconcileReport struct {
+	Date          string                   `json:"date"`
+	Source        string                   `json:"source"`     // 引擎汇总的来源: api, push
+	Symbols       int                      `json:"symbols"`    // 对账的交易对数
+	Matched       int                      `json:"matched"`    // 一致的交易对数
+	Mismatched    int                      `json:"mismatched"` // 偏差超过容差或一方缺失的交易对数
+	Errors        []string                 `json:"errors,omitempty"`
+	Discrepancies []TradeTotalsDiscrepancy `json:"discrepancies"`
+	Timestamp     int64                    `json:"timestamp"`
+}
+
+// TradeTotalsDiscrepancy 单个交易对的汇总偏差
+type TradeTotalsDiscrepancy struct {
+	Symbol            string  `json:"symbol"`
+	Field             string  `json:"field"` // trade_count, volume, quote_volume；一方没有成交时为 engine_missing 或 market_missing
+	EngineCount       int64   `json:"engine_count"`
+	MarketCount       int64   `json:"market_count"`
+	EngineVolume      float64 `json:"engine_volume"`
+	MarketVolume      float64 `json:"market_volume"`
+	EngineQuoteVolume float64 `json:"engine_quote_volume"`
+	MarketQuoteVolume float64 `json:"market_quote_volume"`
+}
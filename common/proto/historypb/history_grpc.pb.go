@@ -0,0 +1,156 @@
+// 历史数据 gRPC 接口，从 PostgreSQL / TimescaleDB 或 InfluxDB 按任意时间范围分页查询成交和K线
+//
+// 生成代码（需要 protoc、protoc-gen-go、protoc-gen-go-grpc）:
+//
+//	make proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: history.proto
+
+package historypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	HistoryData_QueryTrades_FullMethodName = "/history.v1.HistoryData/QueryTrades"
+	HistoryData_QueryKlines_FullMethodName = "/history.v1.HistoryData/QueryKlines"
+)
+
+// HistoryDataClient is the client API for HistoryData service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type HistoryDataClient interface {
+	// 按成交时间范围分页查询成交，下一页以 next_cursor 继续
+	QueryTrades(ctx context.Context, in *QueryTradesRequest, opts ...grpc.CallOption) (*QueryTradesResponse, error)
+	// 按开盘时间范围分页查询K线，分页方式与行情接口的K线查询相同
+	QueryKlines(ctx context.Context, in *QueryKlinesRequest, opts ...grpc.CallOption) (*QueryKlinesResponse, error)
+}
+
+type historyDataClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHistoryDataClient(cc grpc.ClientConnInterface) HistoryDataClient {
+	return &historyDataClient{cc}
+}
+
+func (c *historyDataClient) QueryTrades(ctx context.Context, in *QueryTradesRequest, opts ...grpc.CallOption) (*QueryTradesResponse, error) {
+	out := new(QueryTradesResponse)
+	err := c.cc.Invoke(ctx, HistoryData_QueryTrades_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *historyDataClient) QueryKlines(ctx context.Context, in *QueryKlinesRequest, opts ...grpc.CallOption) (*QueryKlinesResponse, error) {
+	out := new(QueryKlinesResponse)
+	err := c.cc.Invoke(ctx, HistoryData_QueryKlines_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HistoryDataServer is the server API for HistoryData service.
+// All implementations must embed UnimplementedHistoryDataServer
+// for forward compatibility
+type HistoryDataServer interface {
+	// 按成交时间范围分页查询成交，下一页以 next_cursor 继续
+	QueryTrades(context.Context, *QueryTradesRequest) (*QueryTradesResponse, error)
+	// 按开盘时间范围分页查询K线，分页方式与行情接口的K线查询相同
+	QueryKlines(context.Context, *QueryKlinesRequest) (*QueryKlinesResponse, error)
+	mustEmbedUnimplementedHistoryDataServer()
+}
+
+// UnimplementedHistoryDataServer must be embedded to have forward compatible implementations.
+type UnimplementedHistoryDataServer struct {
+}
+
+func (UnimplementedHistoryDataServer) QueryTrades(context.Context, *QueryTradesRequest) (*QueryTradesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryTrades not implemented")
+}
+func (UnimplementedHistoryDataServer) QueryKlines(context.Context, *QueryKlinesRequest) (*QueryKlinesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryKlines not implemented")
+}
+func (UnimplementedHistoryDataServer) mustEmbedUnimplementedHistoryDataServer() {}
+
+// UnsafeHistoryDataServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to HistoryDataServer will
+// result in compilation errors.
+type UnsafeHistoryDataServer interface {
+	mustEmbedUnimplementedHistoryDataServer()
+}
+
+func RegisterHistoryDataServer(s grpc.ServiceRegistrar, srv HistoryDataServer) {
+	s.RegisterService(&HistoryData_ServiceDesc, srv)
+}
+
+func _HistoryData_QueryTrades_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryTradesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HistoryDataServer).QueryTrades(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HistoryData_QueryTrades_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HistoryDataServer).QueryTrades(ctx, req.(*QueryTradesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HistoryData_QueryKlines_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryKlinesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HistoryDataServer).QueryKlines(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HistoryData_QueryKlines_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HistoryDataServer).QueryKlines(ctx, req.(*QueryKlinesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// HistoryData_ServiceDesc is the grpc.ServiceDesc for HistoryData service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var HistoryData_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "history.v1.HistoryData",
+	HandlerType: (*HistoryDataServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "QueryTrades",
+			Handler:    _HistoryData_QueryTrades_Handler,
+		},
+		{
+			MethodName: "QueryKlines",
+			Handler:    _HistoryData_QueryKlines_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "history.proto",
+}
@@ -0,0 +1,301 @@
+// 行情 gRPC 接口，与 REST 接口读取相同的 Redis 数据，供偏好强类型客户端的内部服务使用
+//
+// 生成代码（需要 protoc、protoc-gen-go、protoc-gen-go-grpc）:
+//
+//	make proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: market.proto
+
+package marketpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	MarketData_GetTicker_FullMethodName           = "/market.v1.MarketData/GetTicker"
+	MarketData_GetDepth_FullMethodName            = "/market.v1.MarketData/GetDepth"
+	MarketData_GetKlines_FullMethodName           = "/market.v1.MarketData/GetKlines"
+	MarketData_GetTrades_FullMethodName           = "/market.v1.MarketData/GetTrades"
+	MarketData_SubscribeMarketData_FullMethodName = "/market.v1.MarketData/SubscribeMarketData"
+)
+
+// MarketDataClient is the client API for MarketData service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MarketDataClient interface {
+	// 获取行情快照
+	GetTicker(ctx context.Context, in *GetTickerRequest, opts ...grpc.CallOption) (*Ticker, error)
+	// 获取深度
+	GetDepth(ctx context.Context, in *GetDepthRequest, opts ...grpc.CallOption) (*Depth, error)
+	// 按开盘时间范围获取K线，分页方式与 REST 接口相同
+	GetKlines(ctx context.Context, in *GetKlinesRequest, opts ...grpc.CallOption) (*GetKlinesResponse, error)
+	// 获取最近成交
+	GetTrades(ctx context.Context, in *GetTradesRequest, opts ...grpc.CallOption) (*GetTradesResponse, error)
+	// 订阅实时行情，频道格式与 WebSocket 相同：ticker:BTCUSDT、depth:BTCUSDT、trade:BTCUSDT、kline:BTCUSDT:1m
+	SubscribeMarketData(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (MarketData_SubscribeMarketDataClient, error)
+}
+
+type marketDataClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMarketDataClient(cc grpc.ClientConnInterface) MarketDataClient {
+	return &marketDataClient{cc}
+}
+
+func (c *marketDataClient) GetTicker(ctx context.Context, in *GetTickerRequest, opts ...grpc.CallOption) (*Ticker, error) {
+	out := new(Ticker)
+	err := c.cc.Invoke(ctx, MarketData_GetTicker_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketDataClient) GetDepth(ctx context.Context, in *GetDepthRequest, opts ...grpc.CallOption) (*Depth, error) {
+	out := new(Depth)
+	err := c.cc.Invoke(ctx, MarketData_GetDepth_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketDataClient) GetKlines(ctx context.Context, in *GetKlinesRequest, opts ...grpc.CallOption) (*GetKlinesResponse, error) {
+	out := new(GetKlinesResponse)
+	err := c.cc.Invoke(ctx, MarketData_GetKlines_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketDataClient) GetTrades(ctx context.Context, in *GetTradesRequest, opts ...grpc.CallOption) (*GetTradesResponse, error) {
+	out := new(GetTradesResponse)
+	err := c.cc.Invoke(ctx, MarketData_GetTrades_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketDataClient) SubscribeMarketData(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (MarketData_SubscribeMarketDataClient, error) {
+	stream, err := c.cc.NewStream(ctx, &MarketData_ServiceDesc.Streams[0], MarketData_SubscribeMarketData_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &marketDataSubscribeMarketDataClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MarketData_SubscribeMarketDataClient interface {
+	Recv() (*MarketEvent, error)
+	grpc.ClientStream
+}
+
+type marketDataSubscribeMarketDataClient struct {
+	grpc.ClientStream
+}
+
+func (x *marketDataSubscribeMarketDataClient) Recv() (*MarketEvent, error) {
+	m := new(MarketEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MarketDataServer is the server API for MarketData service.
+// All implementations must embed UnimplementedMarketDataServer
+// for forward compatibility
+type MarketDataServer interface {
+	// 获取行情快照
+	GetTicker(context.Context, *GetTickerRequest) (*Ticker, error)
+	// 获取深度
+	GetDepth(context.Context, *GetDepthRequest) (*Depth, error)
+	// 按开盘时间范围获取K线，分页方式与 REST 接口相同
+	GetKlines(context.Context, *GetKlinesRequest) (*GetKlinesResponse, error)
+	// 获取最近成交
+	GetTrades(context.Context, *GetTradesRequest) (*GetTradesResponse, error)
+	// 订阅实时行情，频道格式与 WebSocket 相同：ticker:BTCUSDT、depth:BTCUSDT、trade:BTCUSDT、kline:BTCUSDT:1m
+	SubscribeMarketData(*SubscribeRequest, MarketData_SubscribeMarketDataServer) error
+	mustEmbedUnimplementedMarketDataServer()
+}
+
+// UnimplementedMarketDataServer must be embedded to have forward compatible implementations.
+type UnimplementedMarketDataServer struct {
+}
+
+func (UnimplementedMarketDataServer) GetTicker(context.Context, *GetTickerRequest) (*Ticker, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTicker not implemented")
+}
+func (UnimplementedMarketDataServer) GetDepth(context.Context, *GetDepthRequest) (*Depth, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDepth not implemented")
+}
+func (UnimplementedMarketDataServer) GetKlines(context.Context, *GetKlinesRequest) (*GetKlinesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetKlines not implemented")
+}
+func (UnimplementedMarketDataServer) GetTrades(context.Context, *GetTradesRequest) (*GetTradesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTrades not implemented")
+}
+func (UnimplementedMarketDataServer) SubscribeMarketData(*SubscribeRequest, MarketData_SubscribeMarketDataServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeMarketData not implemented")
+}
+func (UnimplementedMarketDataServer) mustEmbedUnimplementedMarketDataServer() {}
+
+// UnsafeMarketDataServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MarketDataServer will
+// result in compilation errors.
+type UnsafeMarketDataServer interface {
+	mustEmbedUnimplementedMarketDataServer()
+}
+
+func RegisterMarketDataServer(s grpc.ServiceRegistrar, srv MarketDataServer) {
+	s.RegisterService(&MarketData_ServiceDesc, srv)
+}
+
+func _MarketData_GetTicker_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTickerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketDataServer).GetTicker(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MarketData_GetTicker_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketDataServer).GetTicker(ctx, req.(*GetTickerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MarketData_GetDepth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDepthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketDataServer).GetDepth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MarketData_GetDepth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketDataServer).GetDepth(ctx, req.(*GetDepthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MarketData_GetKlines_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetKlinesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketDataServer).GetKlines(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MarketData_GetKlines_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketDataServer).GetKlines(ctx, req.(*GetKlinesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MarketData_GetTrades_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTradesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketDataServer).GetTrades(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MarketData_GetTrades_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketDataServer).GetTrades(ctx, req.(*GetTradesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MarketData_SubscribeMarketData_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MarketDataServer).SubscribeMarketData(m, &marketDataSubscribeMarketDataServer{stream})
+}
+
+type MarketData_SubscribeMarketDataServer interface {
+	Send(*MarketEvent) error
+	grpc.ServerStream
+}
+
+type marketDataSubscribeMarketDataServer struct {
+	grpc.ServerStream
+}
+
+func (x *marketDataSubscribeMarketDataServer) Send(m *MarketEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// MarketData_ServiceDesc is the grpc.ServiceDesc for MarketData service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MarketData_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "market.v1.MarketData",
+	HandlerType: (*MarketDataServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetTicker",
+			Handler:    _MarketData_GetTicker_Handler,
+		},
+		{
+			MethodName: "GetDepth",
+			Handler:    _MarketData_GetDepth_Handler,
+		},
+		{
+			MethodName: "GetKlines",
+			Handler:    _MarketData_GetKlines_Handler,
+		},
+		{
+			MethodName: "GetTrades",
+			Handler:    _MarketData_GetTrades_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeMarketData",
+			Handler:       _MarketData_SubscribeMarketData_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "market.proto",
+}
@@ -0,0 +1,137 @@
+// Package retention Redis 中各类数据的保留数量和过期时间
+//
+// Processor 写入行情数据和管理接口重建缓存时使用同一套策略，避免两者写入的数据保留数量或过期时间不一致。
+package retention
+
+import (
+	"log"
+	"market-system/common/config"
+	"market-system/common/utils"
+	"time"
+)
+
+// 未配置时的保留数量和过期时间
+const (
+	DefaultKlineListSize = 1000
+	DefaultListSize      = 100
+	DefaultTTL           = 1 * time.Hour
+)
+
+// Policy 解析后的保留策略
+type Policy struct {
+	klineHistory map[string]int64         // 按周期保留的K线数，未配置的周期保留 DefaultKlineListSize
+	klineTTL     map[string]time.Duration // 按周期的K线过期时间，未配置的周期使用 utils.KlineTTL
+	TradeSize    int64
+	TradeTTL     time.Duration
+	AggTradeSize int64
+	AggTradeTTL  time.Duration
+	TickerTTL    time.Duration
+	DepthTTL     time.Duration
+}
+
+// Retention 全局及按交易对的保留策略，交易对的策略已合并全局配置
+type Retention struct {
+	defaults *Policy
+	symbols  map[string]*Policy
+}
+
+// New 解析保留策略，klineHistory 为 kline.history 配置，优先级低于 retention.kline_history
+func New(klineHistory map[string]int, cfg config.RetentionConfig) *Retention {
+	defaults := &Policy{
+		klineHistory: make(map[string]int64),
+		klineTTL:     make(map[string]time.Duration),
+		TradeSize:    DefaultListSize,
+		TradeTTL:     DefaultTTL,
+		AggTradeSize: DefaultListSize,
+		AggTradeTTL:  DefaultTTL,
+		TickerTTL:    DefaultTTL,
+		DepthTTL:     DefaultTTL,
+	}
+	defaults.merge("default", config.RetentionPolicy{KlineHistory: klineHistory})
+	defaults.merge("default", cfg.RetentionPolicy)
+
+	r := &Retention{
+		defaults: defaults,
+		symbols:  make(map[string]*Policy, len(cfg.Symbols)),
+	}
+	for symbol, policy := range cfg.Symbols {
+		p := defaults.clone()
+		p.merge(symbol, policy)
+		r.symbols[symbol] = p
+	}
+	return r
+}
+
+// Policy 交易对的保留策略
+func (r *Retention) Policy(symbol string) *Policy {
+	if p, ok := r.symbols[symbol]; ok {
+		return p
+	}
+	return r.defaults
+}
+
+// KlineListSize 周期保留的K线数
+func (p *Policy) KlineListSize(interval string) int64 {
+	if size, ok := p.klineHistory[interval]; ok {
+		return size
+	}
+	return DefaultKlineListSize
+}
+
+// KlineExpiration 周期的K线过期时间
+func (p *Policy) KlineExpiration(interval string) time.Duration {
+	if ttl, ok := p.klineTTL[interval]; ok {
+		return ttl
+	}
+	return utils.KlineTTL(interval)
+}
+
+// clone 复制策略
+func (p *Policy) clone() *Policy {
+	c := *p
+	c.klineHistory = make(map[string]int64, len(p.klineHistory))
+	for interval, size := range p.klineHistory {
+		c.klineHistory[interval] = size
+	}
+	c.klineTTL = make(map[string]time.Duration, len(p.klineTTL))
+	for interval, ttl := range p.klineTTL {
+		c.klineTTL[interval] = ttl
+	}
+	return &c
+}
+
+// merge 以配置覆盖策略，不支持的周期和不大于 0 的配置忽略，scope 为交易对或 default（用于日志）
+func (p *Policy) merge(scope string, cfg config.RetentionPolicy) {
+	for interval, size := range cfg.KlineHistory {
+		if !utils.ValidateInterval(interval) || size <= 0 {
+			log.Printf("[Retention] Ignored kline history %s %s: %d\n", scope, interval, size)
+			continue
+		}
+		p.klineHistory[interval] = int64(size)
+	}
+	for interval, hours := range cfg.KlineTTLHours {
+		if !utils.ValidateInterval(interval) || hours <= 0 {
+			log.Printf("[Retention] Ignored kline ttl %s %s: %d\n", scope, interval, hours)
+			continue
+		}
+		p.klineTTL[interval] = time.Duration(hours) * time.Hour
+	}
+	if cfg.TradeListSize > 0 {
+		p.TradeSize = int64(cfg.TradeListSize)
+	}
+	if cfg.TradeTTLSec > 0 {
+		p.TradeTTL = time.Duration(cfg.TradeTTLSec) * time.Second
+	}
+	if cfg.AggTradeListSize > 0 {
+		p.AggTradeSize = int64(cfg.AggTradeListSize)
+	}
+	if cfg.AggTradeTTLSec > 0 {
+		p.AggTradeTTL = time.Duration(cfg.AggTradeTTLSec) * time.Second
+	}
+	if cfg.TickerTTLSec > 0 {
+		p.TickerTTL = time.Duration(cfg.TickerTTLSec) * time.Second
+	}
+	if cfg.DepthTTLSec > 0 {
+		p.DepthTTL = time.Duration(cfg.DepthTTLSec) * time.Second
+	}
+}
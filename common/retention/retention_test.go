@@ -0,0 +1,60 @@
+package retention
+
+import (
+	"market-system/common/config"
+	"testing"
+	"time"
+)
+
+func TestRetentionPolicy(t *testing.T) {
+	r := New(map[string]int{"1m": 2000, "1h": 500}, config.RetentionConfig{
+		RetentionPolicy: config.RetentionPolicy{
+			KlineHistory: map[string]int{"1h": 800, "2x": 10},
+			TradeTTLSec:  600,
+		},
+		Symbols: map[string]config.RetentionPolicy{
+			"BTCUSDT": {
+				KlineHistory:  map[string]int{"1m": 10080},
+				KlineTTLHours: map[string]int{"1m": 336},
+				TradeListSize: 500,
+			},
+			"DOGEUSDT": {TradeListSize: 20, DepthTTLSec: -1},
+		},
+	})
+
+	def := r.Policy("ETHUSDT")
+	if got := def.KlineListSize("1m"); got != 2000 {
+		t.Errorf("default 1m history = %d", got)
+	}
+	if got := def.KlineListSize("1h"); got != 800 {
+		t.Errorf("default 1h history = %d, retention should override kline.history", got)
+	}
+	if got := def.KlineListSize("1d"); got != DefaultKlineListSize {
+		t.Errorf("default 1d history = %d", got)
+	}
+	if def.TradeSize != DefaultListSize || def.TradeTTL != 10*time.Minute {
+		t.Errorf("default trade size %d ttl %v", def.TradeSize, def.TradeTTL)
+	}
+
+	btc := r.Policy("BTCUSDT")
+	if got := btc.KlineListSize("1m"); got != 10080 {
+		t.Errorf("BTCUSDT 1m history = %d", got)
+	}
+	if got := btc.KlineListSize("1h"); got != 800 {
+		t.Errorf("BTCUSDT 1h history = %d, should inherit default", got)
+	}
+	if got := btc.KlineExpiration("1m"); got != 336*time.Hour {
+		t.Errorf("BTCUSDT 1m ttl = %v", got)
+	}
+	if btc.TradeSize != 500 || btc.TradeTTL != 10*time.Minute {
+		t.Errorf("BTCUSDT trade size %d ttl %v", btc.TradeSize, btc.TradeTTL)
+	}
+	if got := def.KlineExpiration("1m"); got != 7*24*time.Hour {
+		t.Errorf("symbol override leaked into default: %v", got)
+	}
+
+	doge := r.Policy("DOGEUSDT")
+	if doge.TradeSize != 20 || doge.DepthTTL != DefaultTTL {
+		t.Errorf("DOGEUSDT trade size %d depth ttl %v", doge.TradeSize, doge.DepthTTL)
+	}
+}
@@ -0,0 +1,206 @@
+package sanitize
+
+import (
+	"fmt"
+	"log"
+	"market-system/common/models"
+	"math"
+	"strings"
+	"sync"
+)
+
+// 数据处理阶段
+const (
+	StageIngest    = "ingest"    // 数据接入
+	StageSerialize = "serialize" // 序列化前
+)
+
+// Sanitizer NaN/Inf 数值清洗器
+// 价格类关键字段出现 NaN/Inf 时拒绝整条数据，数量、成交量等非关键字段清零，
+// 深度中非法档位直接剔除。按数据来源统计拒绝/清零次数。
+type Sanitizer struct {
+	stage string
+	mu    sync.Mutex
+	stats map[string]*Stats // key: 数据来源
+}
+
+// Stats 清洗统计
+type Stats struct {
+	Rejected int64 `json:"rejected"` // 拒绝的数据条数
+	Clamped  int64 `json:"clamped"`  // 清零的字段数 / 剔除的档位数
+}
+
+// New 创建清洗器
+func New(stage string) *Sanitizer {
+	return &Sanitizer{
+		stage: stage,
+		stats: make(map[string]*Stats),
+	}
+}
+
+// IsFinite 判断浮点数是否为有限值
+func IsFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// MarketData 清洗统一格式的市场数据，返回 false 表示数据应被丢弃
+func (s *Sanitizer) MarketData(data *models.MarketData) bool {
+	source := data.Exchange
+	if data.Source != "" && data.Source != data.Exchange {
+		source = data.Exchange + "/" + data.Source
+	}
+
+	switch v := data.Data.(type) {
+	case *models.Ticker:
+		return s.Ticker(source, v)
+	case *models.OrderBook:
+		return s.OrderBook(source, v)
+	case *models.Trade:
+		return s.Trade(source, v)
+	case *models.Kline:
+		return s.Kline(source, v)
+	default:
+		return true
+	}
+}
+
+// Ticker 清洗 Ticker，最新价非法时拒绝
+func (s *Sanitizer) Ticker(source string, t *models.Ticker) bool {
+	if !IsFinite(t.LastPrice) {
+		s.reject(source, "ticker", t.Symbol, fmt.Sprintf("last_price=%v", t.LastPrice))
+		return false
+	}
+
+	var clamped []string
+	clamp(&t.BidPrice, "bid_price", &clamped)
+	clamp(&t.AskPrice, "ask_price", &clamped)
+	clamp(&t.High24h, "high_24h", &clamped)
+	clamp(&t.Low24h, "low_24h", &clamped)
+	clamp(&t.Volume24h, "volume_24h", &clamped)
+	clamp(&t.Open24h, "open_24h", &clamped)
+	clamp(&t.PriceChange24h, "price_change_24h", &clamped)
+	clamp(&t.PriceChangePercent24h, "price_change_percent_24h", &clamped)
+	s.clamp(source, "ticker", t.Symbol, clamped)
+	return true
+}
+
+// OrderBook 清洗深度，剔除价格或数量非法的档位
+func (s *Sanitizer) OrderBook(source string, b *models.OrderBook) bool {
+	var clamped []string
+	b.Bids = filterLevels(b.Bids, "bids", &clamped)
+	b.Asks = filterLevels(b.Asks, "asks", &clamped)
+	s.clamp(source, "depth", b.Symbol, clamped)
+	return true
+}
+
+// Trade 清洗成交，价格或数量非法时拒绝
+func (s *Sanitizer) Trade(source string, t *models.Trade) bool {
+	if !IsFinite(t.Price) || !IsFinite(t.Amount) {
+		s.reject(source, "trade", t.Symbol, fmt.Sprintf("price=%v amount=%v", t.Price, t.Amount))
+		return false
+	}
+	return true
+}
+
+// Kline 清洗K线，OHLC 非法时拒绝，成交量/成交额非法时清零
+func (s *Sanitizer) Kline(source string, k *models.Kline) bool {
+	if !IsFinite(k.Open) || !IsFinite(k.High) || !IsFinite(k.Low) || !IsFinite(k.Close) {
+		s.reject(source, "kline", k.Symbol,
+			fmt.Sprintf("open=%v high=%v low=%v close=%v", k.Open, k.High, k.Low, k.Close))
+		return false
+	}
+
+	var clamped []string
+	clamp(&k.Volume, "volume", &clamped)
+	clamp(&k.QuoteVol, "quote_vol", &clamped)
+	s.clamp(source, "kline", k.Symbol, clamped)
+	return true
+}
+
+// Stats 获取按来源统计的清洗信息
+func (s *Sanitizer) Stats() map[string]Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make(map[string]Stats, len(s.stats))
+	for source, stat := range s.stats {
+		stats[source] = *stat
+	}
+	return stats
+}
+
+// reject 记录被拒绝的数据
+func (s *Sanitizer) reject(source, dataType, symbol, detail string) {
+	s.mu.Lock()
+	stat := s.getStats(source)
+	stat.Rejected++
+	count := stat.Rejected
+	s.mu.Unlock()
+
+	if count%100 == 1 {
+		log.Printf("[Sanitize] %s: rejected %s %s from %s: %s (total rejected: %d)\n",
+			s.stage, dataType, symbol, source, detail, count)
+	}
+}
+
+// clamp 记录被清零的字段
+func (s *Sanitizer) clamp(source, dataType, symbol string, fields []string) {
+	if len(fields) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	stat := s.getStats(source)
+	before := stat.Clamped
+	stat.Clamped += int64(len(fields))
+	count := stat.Clamped
+	s.mu.Unlock()
+
+	// 每累计 100 次输出一次日志
+	if before/100 != count/100 || before == 0 {
+		log.Printf("[Sanitize] %s: clamped %s %s from %s: %s (total clamped: %d)\n",
+			s.stage, dataType, symbol, source, strings.Join(fields, ","), count)
+	}
+}
+
+// getStats 获取来源统计（调用方需持有锁）
+func (s *Sanitizer) getStats(source string) *Stats {
+	stat, ok := s.stats[source]
+	if !ok {
+		stat = &Stats{}
+		s.stats[source] = stat
+	}
+	return stat
+}
+
+// clamp 将非法值清零并记录字段名
+func clamp(v *float64, field string, clamped *[]string) {
+	if !IsFinite(*v) {
+		*clamped = append(*clamped, fmt.Sprintf("%s=%v", field, *v))
+		*v = 0
+	}
+}
+
+// filterLevels 剔除价格或数量非法的档位
+func filterLevels(levels []models.PriceLevel, side string, clamped *[]string) []models.PriceLevel {
+	var valid []models.PriceLevel
+	for i, level := range levels {
+		// 全部合法时直接返回原切片，避免复制
+		if valid == nil {
+			if IsFinite(level.Price) && IsFinite(level.Amount) {
+				continue
+			}
+			valid = make([]models.PriceLevel, i, len(levels))
+			copy(valid, levels[:i])
+		}
+		if !IsFinite(level.Price) || !IsFinite(level.Amount) {
+			*clamped = append(*clamped, fmt.Sprintf("%s[%v]=%v", side, level.Price, level.Amount))
+			continue
+		}
+		valid = append(valid, level)
+	}
+	if valid == nil {
+		return levels
+	}
+	return valid
+}
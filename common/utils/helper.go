@@ -3,7 +3,8 @@ package utils
 import (
 	"crypto/md5"
 	"encoding/hex"
-	"fmt"
+	"market-system/common/constants"
+	"market-system/common/errcode"
 	"math"
 )
 
@@ -72,7 +73,20 @@ func RetryBackoff(retryCount int, initialDelay, maxDelay int64) int64 {
 // ValidateSymbol 验证交易对格式
 func ValidateSymbol(symbol string) error {
 	if len(symbol) < 3 {
-		return fmt.Errorf("invalid symbol: %s", symbol)
+		return errcode.Newf(errcode.ErrInvalidParam, "invalid symbol: %s", symbol)
+	}
+	return nil
+}
+
+// ValidateGroupName 验证交易对分组名：1-32 位小写字母、数字、- 或 _
+func ValidateGroupName(name string) error {
+	if len(name) == 0 || len(name) > 32 {
+		return errcode.Newf(errcode.ErrInvalidParam, "invalid group name: %s", name)
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return errcode.Newf(errcode.ErrInvalidParam, "invalid group name: %s", name)
+		}
 	}
 	return nil
 }
@@ -80,8 +94,31 @@ func ValidateSymbol(symbol string) error {
 // ValidateInterval 验证K线周期
 func ValidateInterval(interval string) bool {
 	validIntervals := map[string]bool{
-		"1m": true, "5m": true, "15m": true, "30m": true,
-		"1h": true, "4h": true, "1d": true, "1w": true,
+		"1s": true, "1m": true, "5m": true, "15m": true, "30m": true,
+		"1h": true, "4h": true, "1d": true, "1w": true, "1M": true,
 	}
 	return validIntervals[interval]
 }
+
+// MarketChannel 行情推送的频道名 {type}:{symbol}，Redis 发布时加 market: 前缀，WebSocket、gRPC 订阅时不带前缀
+func MarketChannel(dataType, symbol string) string {
+	return dataType + ":" + symbol
+}
+
+// IntervalChannel 按周期推送的频道名（K线、平均K线、砖形图、技术指标）{type}:{symbol}:{interval}
+func IntervalChannel(dataType, symbol, interval string) string {
+	return dataType + ":" + symbol + ":" + interval
+}
+
+// KlineDataType K线类型对应的数据类型（Redis 键前缀和推送频道的类型），类型为空时为普通K线
+func KlineDataType(klineType string) (string, bool) {
+	switch klineType {
+	case "", constants.KlineTypeCandle:
+		return constants.DataTypeKline, true
+	case constants.KlineTypeHeikinAshi:
+		return constants.DataTypeHeikinAshi, true
+	case constants.KlineTypeRenko:
+		return constants.DataTypeRenko, true
+	}
+	return "", false
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/json"
 )
 
@@ -28,6 +29,14 @@ func FromJSONBytes(data []byte, v interface{}) error {
 	return json.Unmarshal(data, v)
 }
 
+// FromJSONBytesUseNumber 从 JSON 字节数组解析对象，interface{} 中的数字解析为 json.Number
+// 用于按 map 解析的消息，64 位 ID 等大整数不经过 float64，避免丢失精度
+func FromJSONBytesUseNumber(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
 // MustToJSON 将对象转换为 JSON 字符串（panic on error）
 func MustToJSON(v interface{}) string {
 	data, err := json.Marshal(v)
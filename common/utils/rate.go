@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// RateCounter 按类型统计消息速率
+type RateCounter struct {
+	mu     sync.Mutex
+	counts map[string]int64
+	last   time.Time
+}
+
+// NewRateCounter 创建速率统计器
+func NewRateCounter() *RateCounter {
+	return &RateCounter{
+		counts: make(map[string]int64),
+		last:   time.Now(),
+	}
+}
+
+// Inc 计数加一
+func (r *RateCounter) Inc(key string) {
+	r.mu.Lock()
+	r.counts[key]++
+	r.mu.Unlock()
+}
+
+// Rates 获取自上次调用以来各类型的每秒速率，并重置计数
+func (r *RateCounter) Rates() map[string]float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(r.last).Seconds()
+	r.last = now
+
+	rates := make(map[string]float64, len(r.counts))
+	for key, count := range r.counts {
+		if elapsed > 0 {
+			rates[key] = RoundFloat(float64(count)/elapsed, 2)
+		}
+	}
+	r.counts = make(map[string]int64)
+	return rates
+}
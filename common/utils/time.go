@@ -12,29 +12,37 @@ func TimestampToTime(timestamp int64) time.Time {
 	return time.UnixMilli(timestamp)
 }
 
-// GetKlineOpenTime 获取K线开盘时间
+// GetKlineOpenTime 获取K线开盘时间，所有周期统一按 UTC 划分，与交易所K线一致，不受服务器时区影响
 func GetKlineOpenTime(timestamp int64, interval string) int64 {
-	t := time.UnixMilli(timestamp)
+	t := time.UnixMilli(timestamp).UTC()
 
 	switch interval {
+	case "1s":
+		return timestamp - timestamp%1000
 	case "1m":
-		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location()).UnixMilli()
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC).UnixMilli()
 	case "5m":
 		minute := t.Minute() / 5 * 5
-		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), minute, 0, 0, t.Location()).UnixMilli()
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), minute, 0, 0, time.UTC).UnixMilli()
 	case "15m":
 		minute := t.Minute() / 15 * 15
-		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), minute, 0, 0, t.Location()).UnixMilli()
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), minute, 0, 0, time.UTC).UnixMilli()
 	case "30m":
 		minute := t.Minute() / 30 * 30
-		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), minute, 0, 0, t.Location()).UnixMilli()
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), minute, 0, 0, time.UTC).UnixMilli()
 	case "1h":
-		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location()).UnixMilli()
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, time.UTC).UnixMilli()
 	case "4h":
 		hour := t.Hour() / 4 * 4
-		return time.Date(t.Year(), t.Month(), t.Day(), hour, 0, 0, 0, t.Location()).UnixMilli()
+		return time.Date(t.Year(), t.Month(), t.Day(), hour, 0, 0, 0, time.UTC).UnixMilli()
 	case "1d":
-		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).UnixMilli()
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).UnixMilli()
+	case "1w":
+		// ISO 周，周一 00:00 UTC 开始
+		days := (int(t.Weekday()) + 6) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-days, 0, 0, 0, 0, time.UTC).UnixMilli()
+	case "1M":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).UnixMilli()
 	default:
 		return timestamp
 	}
@@ -45,6 +53,8 @@ func GetKlineCloseTime(openTime int64, interval string) int64 {
 	t := time.UnixMilli(openTime)
 
 	switch interval {
+	case "1s":
+		return openTime + 999
 	case "1m":
 		return t.Add(1 * time.Minute).UnixMilli() - 1
 	case "5m":
@@ -59,11 +69,26 @@ func GetKlineCloseTime(openTime int64, interval string) int64 {
 		return t.Add(4 * time.Hour).UnixMilli() - 1
 	case "1d":
 		return t.Add(24 * time.Hour).UnixMilli() - 1
+	case "1w":
+		return t.Add(7 * 24 * time.Hour).UnixMilli() - 1
+	case "1M":
+		// 各月天数不同，按日历加一个月
+		return t.UTC().AddDate(0, 1, 0).UnixMilli() - 1
 	default:
 		return openTime
 	}
 }
 
+// KlineTTL K线缓存的过期时间，周线、月线两次收盘间隔较长，需保留到下一次写入之后
+func KlineTTL(interval string) time.Duration {
+	switch interval {
+	case "1w", "1M":
+		return 90 * 24 * time.Hour
+	default:
+		return 7 * 24 * time.Hour
+	}
+}
+
 // IsNewKline 判断是否需要生成新K线
 func IsNewKline(currentOpenTime, tradeTime int64, interval string) bool {
 	return GetKlineOpenTime(tradeTime, interval) != currentOpenTime
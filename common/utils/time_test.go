@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKlineOpenCloseTime(t *testing.T) {
+	ms := func(value string) int64 {
+		tm, err := time.Parse("2006-01-02 15:04:05.000", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm.UnixMilli()
+	}
+
+	tests := []struct {
+		name      string
+		interval  string
+		timestamp string
+		open      string
+		close     string
+	}{
+		{"4h", "4h", "2024-03-15 13:20:00.000", "2024-03-15 12:00:00.000", "2024-03-15 15:59:59.999"},
+		{"1d", "1d", "2024-03-15 23:59:59.999", "2024-03-15 00:00:00.000", "2024-03-15 23:59:59.999"},
+		// ISO 周从周一 00:00 UTC 开始
+		{"week monday", "1w", "2024-03-11 00:00:00.000", "2024-03-11 00:00:00.000", "2024-03-17 23:59:59.999"},
+		{"week sunday", "1w", "2024-03-17 23:59:59.999", "2024-03-11 00:00:00.000", "2024-03-17 23:59:59.999"},
+		{"week across year", "1w", "2025-01-01 08:00:00.000", "2024-12-30 00:00:00.000", "2025-01-05 23:59:59.999"},
+		{"week across year sunday", "1w", "2021-01-03 12:00:00.000", "2020-12-28 00:00:00.000", "2021-01-03 23:59:59.999"},
+		{"week across february", "1w", "2024-03-01 00:00:00.000", "2024-02-26 00:00:00.000", "2024-03-03 23:59:59.999"},
+		// 月线按日历月，各月天数不同
+		{"month 31 days", "1M", "2024-01-31 23:59:59.999", "2024-01-01 00:00:00.000", "2024-01-31 23:59:59.999"},
+		{"month 30 days", "1M", "2024-04-15 00:00:00.000", "2024-04-01 00:00:00.000", "2024-04-30 23:59:59.999"},
+		{"february leap year", "1M", "2024-02-29 12:00:00.000", "2024-02-01 00:00:00.000", "2024-02-29 23:59:59.999"},
+		{"february", "1M", "2023-02-28 23:59:59.999", "2023-02-01 00:00:00.000", "2023-02-28 23:59:59.999"},
+		{"month across year", "1M", "2023-12-31 23:59:59.999", "2023-12-01 00:00:00.000", "2023-12-31 23:59:59.999"},
+		{"month first", "1M", "2024-01-01 00:00:00.000", "2024-01-01 00:00:00.000", "2024-01-31 23:59:59.999"},
+	}
+
+	// 服务器时区不影响K线划分，所有周期都按 UTC
+	local := time.Local
+	defer func() { time.Local = local }()
+	for _, zone := range []string{"UTC", "Asia/Shanghai", "America/New_York"} {
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			t.Skipf("load location %s: %v", zone, err)
+		}
+		time.Local = loc
+
+		for _, tt := range tests {
+			open := GetKlineOpenTime(ms(tt.timestamp), tt.interval)
+			if want := ms(tt.open); open != want {
+				t.Errorf("%s %s: open = %s, want %s", zone, tt.name, time.UnixMilli(open).UTC(), tt.open)
+			}
+			if closeTime, want := GetKlineCloseTime(open, tt.interval), ms(tt.close); closeTime != want {
+				t.Errorf("%s %s: close = %s, want %s", zone, tt.name, time.UnixMilli(closeTime).UTC(), tt.close)
+			}
+			// 收盘时间仍属于同一根K线，下一毫秒开始新K线
+			if !IsNewKline(open, ms(tt.close)+1, tt.interval) || IsNewKline(open, ms(tt.close), tt.interval) {
+				t.Errorf("%s %s: IsNewKline mismatch at close", zone, tt.name)
+			}
+		}
+	}
+}
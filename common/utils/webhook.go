@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/errcode"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// reservedNetworks 除回环、链路本地和私有地址外不允许作为 Webhook 目标的网段
+var reservedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // 本网络
+	"100.64.0.0/10", // 运营商级 NAT
+	"198.18.0.0/15", // 基准测试
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// PublicIP 地址是否可作为 Webhook 目标：拒绝回环、链路本地（含云厂商元数据地址 169.254.169.254）、私有、组播和未指定地址
+func PublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsPrivate() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// WebhookHostAllowed 主机是否在允许列表中，*.example.com 匹配 example.com 的子域名，列表为空时允许全部主机
+func WebhookHostAllowed(host string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	host = strings.ToLower(host)
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(pattern)
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateWebhookURL 检查 Webhook 地址，防止服务端请求伪造（SSRF）：
+// 只允许 http/https，主机须在允许列表中，解析出的全部地址都须为公网地址
+func ValidateWebhookURL(ctx context.Context, raw string, allowed []string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errcode.Newf(errcode.ErrInvalidParam, "invalid webhook: %s", raw)
+	}
+	host := u.Hostname()
+	if !WebhookHostAllowed(host, allowed) {
+		return errcode.Newf(errcode.ErrInvalidParam, "webhook host not allowed: %s", host)
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return errcode.Newf(errcode.ErrInvalidParam, "failed to resolve webhook host: %s", host)
+	}
+	for _, addr := range addrs {
+		if !PublicIP(addr.IP) {
+			return errcode.Newf(errcode.ErrInvalidParam, "webhook address not allowed: %s", host)
+		}
+	}
+	return nil
+}
+
+// WebhookDialControl 用于 net.Dialer.Control，建立连接前检查实际连接的地址，
+// 避免校验后 DNS 记录被改为内网地址（DNS 重绑定）
+func WebhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !PublicIP(ip) {
+		return fmt.Errorf("webhook address not allowed: %s", host)
+	}
+	return nil
+}
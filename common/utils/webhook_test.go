@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"market-system/common/errcode"
+	"testing"
+)
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		allowed []string
+		ok      bool
+	}{
+		{"https://8.8.8.8/hook", nil, true},
+		{"http://[2001:4860:4860::8888]:8080/hook", nil, true},
+		{"ftp://8.8.8.8/hook", nil, false},
+		{"https:///hook", nil, false},
+		{"http://127.0.0.1:8080/hook", nil, false},
+		{"http://localhost/hook", nil, false},
+		{"http://[::1]/hook", nil, false},
+		{"http://169.254.169.254/latest/meta-data", nil, false},
+		{"http://10.0.0.5/hook", nil, false},
+		{"http://172.16.3.4/hook", nil, false},
+		{"http://192.168.1.10/hook", nil, false},
+		{"http://100.64.0.1/hook", nil, false},
+		{"http://0.0.0.0/hook", nil, false},
+		{"http://[::ffff:127.0.0.1]/hook", nil, false},
+		{"http://[fd00::1]/hook", nil, false},
+		{"https://8.8.8.8/hook", []string{"hooks.example.com"}, false},
+		{"https://8.8.8.8/hook", []string{"8.8.8.8"}, true},
+		// 允许列表中的主机同样不能是内网地址
+		{"http://10.0.0.5/hook", []string{"10.0.0.5"}, false},
+	}
+	for _, tt := range tests {
+		err := ValidateWebhookURL(context.Background(), tt.url, tt.allowed)
+		if (err == nil) != tt.ok {
+			t.Errorf("ValidateWebhookURL(%s, %v) = %v, want ok %v", tt.url, tt.allowed, err, tt.ok)
+		}
+		if err != nil && !errors.Is(err, errcode.ErrInvalidParam) {
+			t.Errorf("ValidateWebhookURL(%s) error %v is not ErrInvalidParam", tt.url, err)
+		}
+	}
+}
+
+func TestWebhookHostAllowed(t *testing.T) {
+	allowed := []string{"hooks.example.com", "*.partner.io"}
+	tests := map[string]bool{
+		"hooks.example.com":  true,
+		"HOOKS.example.com":  true,
+		"evil.example.com":   false,
+		"a.partner.io":       true,
+		"a.b.partner.io":     true,
+		"partner.io":         false,
+		"evilpartner.io":     false,
+		"partner.io.evil.cn": false,
+	}
+	for host, want := range tests {
+		if got := WebhookHostAllowed(host, allowed); got != want {
+			t.Errorf("WebhookHostAllowed(%s) = %v, want %v", host, got, want)
+		}
+	}
+	if !WebhookHostAllowed("any.host", nil) {
+		t.Error("empty allowlist should allow any host")
+	}
+}
+
+func TestWebhookDialControl(t *testing.T) {
+	if err := WebhookDialControl("tcp", "8.8.8.8:443", nil); err != nil {
+		t.Errorf("public address rejected: %v", err)
+	}
+	for _, addr := range []string{"127.0.0.1:80", "169.254.169.254:80", "[::1]:443", "10.1.2.3:8080"} {
+		if err := WebhookDialControl("tcp", addr, nil); err == nil {
+			t.Errorf("%s should be rejected", addr)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Package version 服务版本和 Kafka 消息版本头
+//
+// 生产者在每条 Kafka 消息的消息头中写入服务名、服务版本和消息格式版本，滚动升级期间消费者据此
+// 观察新旧版本混合的情况，并识别无法解析的消息格式。
+package version
+
+import "github.com/segmentio/kafka-go"
+
+// Version 服务版本，构建时注入，例如 go build -ldflags "-X market-system/common/version.Version=1.4.0"
+var Version = "dev"
+
+// SchemaVersion MarketData 消息格式版本，消息结构发生不兼容变更时递增
+const SchemaVersion = "1"
+
+// Kafka 消息头
+const (
+	HeaderProducer       = "x-producer"        // 生产者服务名
+	HeaderServiceVersion = "x-service-version" // 生产者服务版本
+	HeaderSchemaVersion  = "x-schema-version"  // 消息格式版本
+)
+
+// Info 消息的版本信息，没有版本消息头（升级前的生产者）时各字段为空
+type Info struct {
+	Producer       string
+	ServiceVersion string
+	SchemaVersion  string
+}
+
+// Headers 生成生产者的版本消息头
+func Headers(producer string) []kafka.Header {
+	return []kafka.Header{
+		{Key: HeaderProducer, Value: []byte(producer)},
+		{Key: HeaderServiceVersion, Value: []byte(Version)},
+		{Key: HeaderSchemaVersion, Value: []byte(SchemaVersion)},
+	}
+}
+
+// FromHeaders 从消息头读取版本信息
+func FromHeaders(headers []kafka.Header) Info {
+	var info Info
+	for _, h := range headers {
+		switch h.Key {
+		case HeaderProducer:
+			info.Producer = string(h.Value)
+		case HeaderServiceVersion:
+			info.ServiceVersion = string(h.Value)
+		case HeaderSchemaVersion:
+			info.SchemaVersion = string(h.Value)
+		}
+	}
+	return info
+}
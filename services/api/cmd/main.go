@@ -1,17 +1,28 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
 
+	"market-system/common/constants"
+	"market-system/common/health"
 	"market-system/services/api/internal/config"
+	"market-system/services/api/internal/gql"
 	"market-system/services/api/internal/handler"
+	"market-system/services/api/internal/handler/binance"
+	"market-system/services/api/internal/middleware"
+	"market-system/services/api/internal/openapi"
+	"market-system/services/api/internal/rpc"
 	"market-system/services/api/internal/svc"
 	ws "market-system/services/api/internal/websocket"
 
 	"github.com/zeromicro/go-zero/core/conf"
 	"github.com/zeromicro/go-zero/rest"
+	"google.golang.org/grpc"
 )
 
 var configFile = flag.String("f", "etc/market-api.yaml", "the config file")
@@ -22,11 +33,46 @@ func main() {
 	var c config.Config
 	conf.MustLoad(*configFile, &c)
 
-	server := rest.MustNewServer(c.RestConf)
+	var opts []rest.RunOption
+	if c.Cors.Enable {
+		opts = append(opts, rest.WithCustomCors(middleware.CorsHeaders(c.Cors), nil, c.Cors.AllowOrigins...))
+	}
+	server := rest.MustNewServer(c.RestConf, opts...)
 	defer server.Stop()
 
+	// 压缩中间件在最外层，认证和限流的错误响应同样压缩
+	if c.Compress.Enable {
+		server.Use(middleware.NewCompressMiddleware(c.Compress.MinSize).Handle)
+	}
+	// 认证中间件作用于之后注册的全部路由（包括 /ws 和 /graphql），gRPC 服务使用同一套凭证
+	var (
+		auth      *middleware.AuthMiddleware
+		rateLimit *middleware.RateLimitMiddleware
+	)
+	if c.Auth.Enable {
+		auth = middleware.NewAuthMiddleware(c.Auth)
+		server.Use(auth.Handle)
+		log.Println("[Main] API authentication enabled")
+	}
+	// 限流按认证后的调用方区分，在认证中间件之后注册
+	if c.RateLimit.Enable {
+		rateLimit = middleware.NewRateLimitMiddleware(c.RateLimit)
+		server.Use(rateLimit.Handle)
+		log.Println("[Main] API rate limiting enabled")
+	}
+
 	ctx := svc.NewServiceContext(c)
 	handler.RegisterHandlers(server, ctx)
+	// 管理接口依赖认证中间件检查 admin 权限，未开启认证时不注册
+	if c.Auth.Enable {
+		handler.RegisterAdminHandlers(server, ctx)
+	} else {
+		log.Println("[Main] Admin API disabled because authentication is not enabled")
+	}
+	if c.BinanceCompat.Enable {
+		binance.RegisterHandlers(server, ctx)
+		log.Println("[Main] Binance compatible API enabled at /api/v3")
+	}
 
 	// 添加WebSocket路由
 	wsHandler := ws.NewHandler(ctx.WsHub)
@@ -36,6 +82,79 @@ func main() {
 		Handler: wsHandler.ServeHTTP,
 	})
 
+	// 添加 GraphQL 路由
+	graphqlHandler, err := gql.NewHandler(ctx)
+	if err != nil {
+		log.Fatalf("[Main] Failed to build GraphQL schema: %v\n", err)
+	}
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		server.AddRoute(rest.Route{
+			Method:  method,
+			Path:    "/graphql",
+			Handler: graphqlHandler.ServeHTTP,
+		})
+	}
+
+	// 存活/就绪检查（认证和限流中间件跳过探针路由）
+	checker := health.New(constants.ServiceAPI)
+	checker.AddReadiness("redis", func(reqCtx context.Context) error {
+		return ctx.Redis.Ping(reqCtx).Err()
+	})
+	server.AddRoute(rest.Route{
+		Method:  http.MethodGet,
+		Path:    health.PathLiveness,
+		Handler: checker.LivenessHandler,
+	})
+	server.AddRoute(rest.Route{
+		Method:  http.MethodGet,
+		Path:    health.PathReadiness,
+		Handler: checker.ReadinessHandler,
+	})
+
+	// OpenAPI 文档和 Swagger UI
+	if c.OpenAPI.Enable {
+		server.AddRoute(rest.Route{
+			Method:  http.MethodGet,
+			Path:    openapi.PathSpec,
+			Handler: openapi.SpecHandler,
+		})
+		server.AddRoute(rest.Route{
+			Method:  http.MethodGet,
+			Path:    openapi.PathDocs,
+			Handler: openapi.SwaggerUIHandler(c.OpenAPI.SwaggerAssets),
+		})
+		log.Printf("[Main] API docs enabled at %s\n", openapi.PathDocs)
+	}
+
+	// 启动 gRPC 服务
+	if c.Grpc.ListenOn != "" {
+		listener, err := net.Listen("tcp", c.Grpc.ListenOn)
+		if err != nil {
+			log.Fatalf("[Main] Failed to listen on %s: %v\n", c.Grpc.ListenOn, err)
+		}
+		// 认证和限流与 REST 接口一致，拦截器按认证、限流的顺序执行
+		var (
+			unary  []grpc.UnaryServerInterceptor
+			stream []grpc.StreamServerInterceptor
+		)
+		if auth != nil {
+			unary = append(unary, auth.UnaryServerInterceptor())
+			stream = append(stream, auth.StreamServerInterceptor())
+		}
+		if rateLimit != nil {
+			unary = append(unary, rateLimit.UnaryServerInterceptor())
+			stream = append(stream, rateLimit.StreamServerInterceptor())
+		}
+		grpcServer := rpc.NewGRPCServer(ctx, grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...))
+		defer grpcServer.Stop()
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Printf("[Main] gRPC server stopped: %v\n", err)
+			}
+		}()
+		log.Printf("[Main] gRPC server listening on %s\n", c.Grpc.ListenOn)
+	}
+
 	// 启动WebSocket Hub
 	go ctx.WsHub.Run()
 	log.Println("[Main] WebSocket Hub started")
@@ -44,6 +163,11 @@ func main() {
 	go ctx.Broadcaster.Start()
 	log.Println("[Main] Redis Broadcaster started")
 
+	// 启动热备同步
+	if ctx.Standby != nil {
+		go ctx.Standby.Run(context.Background())
+	}
+
 	fmt.Printf("Starting server at %s:%d...\n", c.Host, c.Port)
 	fmt.Printf("WebSocket endpoint: ws://%s:%d/ws\n", c.Host, c.Port)
 	server.Start()
@@ -0,0 +1,37 @@
+// openapi 由 market.api 生成 OpenAPI 3 文档，API 服务内嵌生成的文档
+//
+// 用法（在 services/api 目录下，或执行 go generate ./internal/openapi）:
+//
+//	go run ./cmd/openapi -api market.api -out internal/openapi/openapi.json
+package main
+
+import (
+	"flag"
+	"log"
+	"market-system/services/api/internal/openapi"
+	"os"
+)
+
+func main() {
+	apiFile := flag.String("api", "market.api", ".api 文件路径")
+	out := flag.String("out", "internal/openapi/openapi.json", "输出文件")
+	flag.Parse()
+
+	src, err := os.ReadFile(*apiFile)
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v\n", *apiFile, err)
+	}
+	doc, err := openapi.Generate(src)
+	if err != nil {
+		log.Fatalf("Failed to generate: %v\n", err)
+	}
+
+	data, err := openapi.Marshal(doc)
+	if err != nil {
+		log.Fatalf("Failed to marshal: %v\n", err)
+	}
+	if err := os.WriteFile(*out, data, 0644); err != nil {
+		log.Fatalf("Failed to write %s: %v\n", *out, err)
+	}
+	log.Printf("%d path(s) written to %s\n", len(doc.Paths), *out)
+}
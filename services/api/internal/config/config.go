@@ -4,12 +4,181 @@ import "github.com/zeromicro/go-zero/rest"
 
 type Config struct {
 	rest.RestConf
-	Redis RedisConfig
+	Redis     RedisConfig
+	WebSocket WebSocketConfig `json:",optional"`
+	ReadCache ReadCacheConfig `json:",optional"`
+
+	BinanceCompat BinanceCompatConfig `json:",optional"`
+	Grpc          GrpcConfig          `json:",optional"`
+	Auth          AuthConfig          `json:",optional"`
+	RateLimit     RateLimitConfig     `json:",optional"`
+	Staleness     StalenessConfig     `json:",optional"`
+	Compress      CompressConfig      `json:",optional"`
+	Cors          CorsConfig          `json:",optional"`
+	OpenAPI       OpenAPIConfig       `json:",optional"`
+	Conversion    ConversionConfig    `json:",optional"`
+	PriceAlert    PriceAlertConfig    `json:",optional"`
+	Retention     RetentionConfig     `json:",optional"`
+	Standby       StandbyConfig       `json:",optional"`
 }
 
 type RedisConfig struct {
-	Host     string
-	Port     int
-	Password string
-	DB       int
+	Host     string `json:",optional"`
+	Port     int    `json:",optional"`
+	Password string `json:",optional"`
+	DB       int    `json:",optional"`
+	// 管理接口重建缓存时写入的编码，与 Processor 的 redis.codec 一致：json、msgpack
+	Codec string `json:",default=json,options=json|msgpack"`
+	// 配置 MasterName 后经 Sentinel 连接主节点，忽略 Host/Port
+	Sentinel RedisSentinelConfig `json:",optional"`
+}
+
+// RedisSentinelConfig Redis Sentinel 配置，主节点故障切换后客户端自动连接新的主节点，无需重启服务
+type RedisSentinelConfig struct {
+	MasterName string   `json:",optional"`
+	Addrs      []string `json:",optional"` // Sentinel 地址，如 sentinel-1:26379
+	Password   string   `json:",optional"` // Sentinel 自身的密码
+}
+
+type WebSocketConfig struct {
+	// 各频道类型消息在发送队列中的有效期（毫秒），如 depth: 1000；0 表示不过期
+	MessageTTL map[string]int64 `json:",optional"`
+	// 每个连接最多订阅的频道数（一次订阅多个周期时每个周期计一个），0 使用默认值 20，-1 表示不限制
+	MaxSubscriptionsPerConn int `json:",optional"`
+	// 每个连接每秒允许的入站消息数，0 使用默认值 100，-1 表示不限流；MessageBurst 为突发容量，0 时等于 MessageRate
+	MessageRate  float64 `json:",optional"`
+	MessageBurst int     `json:",optional"`
+	// 超出限流时先返回警告，累计超过该次数后断开连接，0 使用默认值 10
+	RateLimitWarnings int `json:",optional"`
+	// 各频道类型的合并窗口（毫秒），同一频道在窗口内只推送最新的消息，如 depth: 100（默认）；0 表示不合并
+	Conflation map[string]int64 `json:",optional"`
+	// depthDiff 频道推送全量深度的间隔（毫秒），0 使用默认值 10000
+	DepthDiffSnapshotMs int64 `json:",optional"`
+}
+
+// PriceAlertConfig 用户价格提醒
+// Webhook 由 Processor 发送，创建时检查地址：不能解析为回环、链路本地或私有地址，配置 WebhookHosts 后只允许其中的主机
+type PriceAlertConfig struct {
+	WebhookHosts []string `json:",optional"` // 允许的 Webhook 主机，如 hooks.example.com、*.example.com；为空时允许全部公网地址
+}
+
+// RetentionConfig Redis 中各类数据的保留数量和过期时间，管理接口重建缓存时使用，与 Processor 的 retention 配置一致
+// Processor 的 kline.history 配置在 KlineHistory 中设置；未配置的项使用与 Processor 相同的默认值
+type RetentionConfig struct {
+	RetentionPolicy `json:",optional"`
+	Symbols         map[string]RetentionPolicy `json:",optional"` // 按交易对覆盖
+}
+
+// RetentionPolicy 一组保留策略，不大于 0 的项表示未配置
+type RetentionPolicy struct {
+	KlineHistory  map[string]int `json:",optional"` // 按周期保留的K线数，默认 1000
+	KlineTTLHours map[string]int `json:",optional"` // 按周期设置K线的过期时间（小时）
+	TradeListSize int            `json:",optional"` // 保留的最近成交数，默认 100
+	TradeTTLSec   int            `json:",optional"` // 最近成交的过期时间（秒），默认 3600
+	TickerTTLSec  int            `json:",optional"` // Ticker 的过期时间（秒），默认 3600
+	DepthTTLSec   int            `json:",optional"` // 深度的过期时间（秒），默认 3600
+}
+
+// ReadCacheConfig REST 接口的本地读缓存，缓存期内同一个交易对只读取一次 Redis
+// 缓存时间（毫秒）即接口返回数据的最大额外延迟，0 表示不缓存
+type ReadCacheConfig struct {
+	TickerTTLMs int64 `json:",optional"`
+	DepthTTLMs  int64 `json:",optional"`
+}
+
+// BinanceCompatConfig Binance 兼容接口（/api/v3/klines、/api/v3/ticker/24hr、/api/v3/depth），
+// 支持 Binance 格式的图表和行情工具直接接入
+type BinanceCompatConfig struct {
+	Enable bool `json:",optional"`
+}
+
+// GrpcConfig 行情 gRPC 服务，与 REST 服务在同一进程中运行，ListenOn 为空时不启动
+type GrpcConfig struct {
+	ListenOn string `json:",optional"` // 监听地址，如 0.0.0.0:9090
+}
+
+// AuthConfig API 认证，对外开放服务时开启；未开启时不认证（仅在内网部署）
+// 支持 API Key 和 JWT（HS256），管理接口需要 admin 权限，其他接口需要 read 权限
+type AuthConfig struct {
+	Enable        bool           `json:",optional"`
+	Keys          []APIKeyConfig `json:",optional"`
+	JwtSecret     string         `json:",optional"` // JWT 签名密钥，为空时不接受 JWT
+	AnonymousRead bool           `json:",optional"` // 允许不带凭证访问只读接口
+}
+
+// APIKeyConfig API Key 及其权限
+type APIKeyConfig struct {
+	Key        string
+	Name       string `json:",optional"` // 用于日志和按 Key 限流，默认为 Key 的前 8 位
+	Permission string `json:",default=read,options=read|admin"`
+}
+
+// RateLimitConfig REST 和 gRPC 接口限流（令牌桶），按路由分组配置
+// 带 API Key 或 JWT 的请求按调用方限流，其他请求按客户端 IP 限流；超出限制时返回 429
+type RateLimitConfig struct {
+	Enable bool             `json:",optional"`
+	Groups []RateLimitGroup `json:",optional"`
+	// 从 X-Forwarded-For 获取客户端 IP，仅在经可信的反向代理访问时开启
+	TrustForwardedFor bool `json:",optional"`
+}
+
+// RateLimitGroup 路由分组的限流，请求按最长的路由前缀匹配分组，不匹配任何分组的请求不限流
+type RateLimitGroup struct {
+	Prefix   string  // 路由前缀，如 /api/v1/kline；gRPC 为方法名前缀，如 /market.v1.MarketData/GetKlines
+	Rate     float64 // 每个 IP 每秒的请求数
+	Burst    int     `json:",optional"` // 令牌桶容量，默认为 Rate
+	KeyRate  float64 `json:",optional"` // 每个 API Key 每秒的请求数，默认为 Rate
+	KeyBurst int     `json:",optional"` // 默认为 KeyRate
+}
+
+// StalenessConfig 数据过期检查，Processor 停止更新时客户端可通过响应中的 stale 和 age_ms 识别过期数据
+type StalenessConfig struct {
+	ThresholdMs int64 `json:",default=60000"` // Ticker 时间戳距当前时间超过该值时视为过期，0 表示不检查
+	Reject      bool  `json:",optional"`      // 单个交易对的 Ticker 过期时返回 503，批量接口只标记不拒绝
+}
+
+// CompressConfig 响应压缩和条件请求（ETag/If-None-Match），开启后行情接口的数据未更新时返回 304
+type CompressConfig struct {
+	Enable  bool `json:",optional"`
+	MinSize int  `json:",default=1024"` // 响应体小于该字节数时不压缩
+}
+
+// CorsConfig 跨域访问，浏览器页面直接调用 API 时开启；preflight（OPTIONS）请求直接返回 204
+// 不允许的来源不返回 CORS 响应头，由浏览器拦截
+type CorsConfig struct {
+	Enable           bool     `json:",optional"`
+	AllowOrigins     []string `json:",optional"`      // 如 https://app.example.com，为空时允许全部来源
+	AllowMethods     []string `json:",optional"`      // 默认 GET、POST、PUT、DELETE、OPTIONS
+	AllowHeaders     []string `json:",optional"`      // 默认 Content-Type、Authorization、X-API-Key、If-None-Match
+	ExposeHeaders    []string `json:",optional"`      // 默认 ETag、Retry-After
+	MaxAge           int      `json:",default=86400"` // preflight 结果的缓存时间（秒）
+	AllowCredentials bool     `json:",optional"`
+}
+
+// OpenAPIConfig OpenAPI 文档（/api/v1/openapi.json）和 Swagger UI（/api/v1/docs），开启后不需要认证即可访问
+type OpenAPIConfig struct {
+	Enable        bool   `json:",optional"`
+	SwaggerAssets string `json:",optional"` // Swagger UI 静态资源地址，默认 unpkg 上的 swagger-ui-dist，内网部署时配置为内部镜像
+}
+
+// ConversionConfig 计价货币换算，开启后 Ticker 响应附带以 Fiat 计价的最新价和 24 小时成交额
+// 计价货币为稳定币时按 1:1 换算为 USD，其他计价货币经参考交易对换算，如 ETHBTC 的价格乘以 BTCUSDT 的最新价
+type ConversionConfig struct {
+	Enable bool   `json:",optional"`
+	Fiat   string `json:",default=USD"`  // 换算的目标货币
+	Bridge string `json:",default=USDT"` // 参考交易对的计价货币，计价货币 X 的参考交易对默认为 X + Bridge
+	// 识别交易对计价货币的后缀（按最长匹配），默认 USDT、USDC、FDUSD、BUSD、DAI、USD、BTC、ETH、BNB
+	QuoteAssets    []string          `json:",optional"`
+	Stablecoins    []string          `json:",optional"` // 与 USD 按 1:1 换算的货币，默认 USDT、USDC、FDUSD、BUSD、DAI、USD
+	ReferencePairs map[string]string `json:",optional"` // 按计价货币指定参考交易对，如 BTC: BTCUSDC
+	// 1 USD 兑换的 Fiat 数量；Fiat 不是 USD 且未配置时取 Fiat + Bridge 交易对（如 EURUSDT）最新价的倒数
+	FiatRate float64 `json:",optional"`
+}
+
+// StandbyConfig API 实例热备，standby 实例定期同步 active 实例的 WebSocket 状态（depthDiff 频道的订单簿和 seq），
+// 故障切换后重连到 standby 实例的客户端立即获得快照，seq 与切换前连续
+type StandbyConfig struct {
+	Role       string `json:",optional"`        // active 或 standby，为空时不同步
+	Name       string `json:",default=default"` // 热备组名称，同一组的 active 和 standby 实例使用相同的名称
+	IntervalMs int64  `json:",default=1000"`    // 同步间隔（毫秒）
 }
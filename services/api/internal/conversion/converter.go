@@ -0,0 +1,132 @@
+package conversion
+
+import (
+	"market-system/services/api/internal/config"
+	"sort"
+	"strings"
+)
+
+// USD 稳定币换算的基准货币
+const USD = "USD"
+
+var (
+	defaultQuoteAssets = []string{"USDT", "USDC", "FDUSD", "BUSD", "DAI", "USD", "BTC", "ETH", "BNB"}
+	defaultStablecoins = []string{"USDT", "USDC", "FDUSD", "BUSD", "DAI", "USD"}
+)
+
+// PriceFunc 获取交易对的最新价，没有行情时返回 false
+type PriceFunc func(symbol string) (float64, bool)
+
+// Converter 将交易对的计价货币换算为配置的目标货币（Fiat）
+// 换算率由调用方提供的最新价计算，不缓存，参考交易对的价格变化立即生效
+type Converter struct {
+	fiat        string
+	bridge      string
+	quoteAssets []string // 按长度降序，最长匹配
+	stablecoins map[string]bool
+	references  map[string]string
+	fiatRate    float64
+}
+
+// New 创建换算器，未开启时返回 nil
+func New(c config.ConversionConfig) *Converter {
+	if !c.Enable {
+		return nil
+	}
+
+	cv := &Converter{
+		fiat:        strings.ToUpper(c.Fiat),
+		bridge:      strings.ToUpper(c.Bridge),
+		stablecoins: make(map[string]bool),
+		references:  make(map[string]string),
+		fiatRate:    c.FiatRate,
+	}
+	if cv.fiat == "" {
+		cv.fiat = USD
+	}
+	if cv.bridge == "" {
+		cv.bridge = "USDT"
+	}
+
+	quoteAssets := c.QuoteAssets
+	if len(quoteAssets) == 0 {
+		quoteAssets = defaultQuoteAssets
+	}
+	for _, asset := range quoteAssets {
+		cv.quoteAssets = append(cv.quoteAssets, strings.ToUpper(asset))
+	}
+	sort.SliceStable(cv.quoteAssets, func(i, j int) bool { return len(cv.quoteAssets[i]) > len(cv.quoteAssets[j]) })
+
+	stablecoins := c.Stablecoins
+	if len(stablecoins) == 0 {
+		stablecoins = defaultStablecoins
+	}
+	for _, asset := range stablecoins {
+		cv.stablecoins[strings.ToUpper(asset)] = true
+	}
+	for asset, pair := range c.ReferencePairs {
+		cv.references[strings.ToUpper(asset)] = strings.ToUpper(pair)
+	}
+	return cv
+}
+
+// Fiat 换算的目标货币
+func (c *Converter) Fiat() string {
+	return c.fiat
+}
+
+// QuoteAsset 按配置的计价货币后缀识别交易对的计价货币
+func (c *Converter) QuoteAsset(symbol string) (string, bool) {
+	for _, asset := range c.quoteAssets {
+		if len(symbol) > len(asset) && strings.HasSuffix(symbol, asset) {
+			return asset, true
+		}
+	}
+	return "", false
+}
+
+// Rate 1 单位计价货币折合的目标货币数量，参考交易对没有行情时返回 false
+func (c *Converter) Rate(quote string, price PriceFunc) (float64, bool) {
+	if quote == c.fiat {
+		return 1, true
+	}
+
+	usd, ok := c.usdRate(quote, price)
+	if !ok {
+		return 0, false
+	}
+	if c.fiat == USD {
+		return usd, true
+	}
+
+	perUSD := c.fiatRate
+	if perUSD <= 0 {
+		p, ok := price(c.fiat + c.bridge)
+		if !ok || p <= 0 {
+			return 0, false
+		}
+		perUSD = 1 / p
+	}
+	return usd * perUSD, true
+}
+
+// usdRate 1 单位计价货币折合的 USD：稳定币为 1，其他货币取参考交易对（计价货币为稳定币）的最新价
+func (c *Converter) usdRate(quote string, price PriceFunc) (float64, bool) {
+	if c.stablecoins[quote] {
+		return 1, true
+	}
+
+	pair, ok := c.references[quote]
+	if !ok {
+		pair = quote + c.bridge
+	}
+	refQuote, ok := c.QuoteAsset(pair)
+	if !ok || !c.stablecoins[refQuote] {
+		return 0, false
+	}
+	p, ok := price(pair)
+	if !ok || p <= 0 {
+		return 0, false
+	}
+	return p, true
+}
@@ -0,0 +1,54 @@
+package conversion
+
+import (
+	"market-system/services/api/internal/config"
+	"math"
+	"testing"
+)
+
+func prices(m map[string]float64) PriceFunc {
+	return func(symbol string) (float64, bool) {
+		p, ok := m[symbol]
+		return p, ok
+	}
+}
+
+func TestRate(t *testing.T) {
+	price := prices(map[string]float64{"BTCUSDT": 60000, "ETHUSDC": 3000, "EURUSDT": 1.25})
+
+	c := New(config.ConversionConfig{Enable: true, Fiat: "USD", Bridge: "USDT", ReferencePairs: map[string]string{"ETH": "ETHUSDC"}})
+	cases := []struct {
+		symbol string
+		rate   float64
+		ok     bool
+	}{
+		{"BTCUSDT", 1, true},
+		{"ETHBTC", 60000, true},
+		{"SOLETH", 3000, true}, // 按指定的参考交易对
+		{"FOOBNB", 0, false},   // 参考交易对没有行情
+		{"FOOXYZ", 0, false},   // 无法识别计价货币
+		{"ABCUSD", 1, true},    // USDT 的后缀 USD 不影响最长匹配
+	}
+	for _, tc := range cases {
+		rate, ok := 0.0, false
+		if quote, found := c.QuoteAsset(tc.symbol); found {
+			rate, ok = c.Rate(quote, price)
+		}
+		if ok != tc.ok || rate != tc.rate {
+			t.Errorf("%s: rate = %v, %v; want %v, %v", tc.symbol, rate, ok, tc.rate, tc.ok)
+		}
+	}
+
+	eur := New(config.ConversionConfig{Enable: true, Fiat: "EUR", Bridge: "USDT"})
+	if rate, ok := eur.Rate("BTC", price); !ok || math.Abs(rate-48000) > 1e-6 {
+		t.Errorf("BTC in EUR = %v, %v", rate, ok)
+	}
+	fixed := New(config.ConversionConfig{Enable: true, Fiat: "EUR", Bridge: "USDT", FiatRate: 0.9})
+	if rate, ok := fixed.Rate("USDT", prices(nil)); !ok || rate != 0.9 {
+		t.Errorf("USDT in EUR = %v, %v", rate, ok)
+	}
+
+	if New(config.ConversionConfig{}) != nil {
+		t.Error("expected nil converter when disabled")
+	}
+}
@@ -0,0 +1,80 @@
+package gql
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"market-system/services/api/internal/svc"
+
+	"github.com/graphql-go/graphql"
+)
+
+// maxBodyBytes 请求体的最大长度
+const maxBodyBytes = 64 << 10
+
+// Handler GraphQL HTTP 处理器，支持 POST JSON 请求体和 GET 查询参数（query、variables、operationName）
+type Handler struct {
+	schema graphql.Schema
+}
+
+// request GraphQL 请求
+type request struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// NewHandler 创建 GraphQL 处理器
+func NewHandler(svcCtx *svc.ServiceContext) (*Handler, error) {
+	schema, err := newSchema(svcCtx)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{schema: schema}, nil
+}
+
+// ServeHTTP 执行查询，查询执行中的错误（包括部分字段读取失败）在响应的 errors 中返回，HTTP 状态码为 200；
+// 请求格式错误时返回 400
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if v := query.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResult("invalid variables: "+err.Error()))
+				return
+			}
+		}
+	} else if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResult("invalid request body: "+err.Error()))
+		return
+	}
+	if req.Query == "" {
+		writeJSON(w, http.StatusBadRequest, errorResult("missing query"))
+		return
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        r.Context(),
+	})
+	writeJSON(w, http.StatusOK, result)
+}
+
+// errorResult 请求格式错误时的响应，格式与查询错误相同
+func errorResult(message string) map[string]interface{} {
+	return map[string]interface{}{
+		"errors": []map[string]string{{"message": message}},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,71 @@
+package gql
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"market-system/services/api/internal/registry"
+	"market-system/services/api/internal/svc"
+)
+
+// newTestHandler 不连接 Redis，只能查询不读取行情的字段
+func newTestHandler(t *testing.T) *Handler {
+	h, err := NewHandler(&svc.ServiceContext{Symbols: registry.NewRegistry(nil)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func serve(h *Handler, r *http.Request) (int, map[string]interface{}) {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	return w.Code, body
+}
+
+func TestMarketsSymbolOnly(t *testing.T) {
+	h := newTestHandler(t)
+	body := `{"query":"query($s:[String!]!){ markets(symbols:$s){ symbol } }","variables":{"s":["btcusdt","ETHUSDT"]}}`
+	code, resp := serve(h, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+	if code != http.StatusOK || resp["errors"] != nil {
+		t.Fatalf("code %d, resp %v", code, resp)
+	}
+	data, _ := json.Marshal(resp["data"])
+	if want := `{"markets":[{"symbol":"BTCUSDT"},{"symbol":"ETHUSDT"}]}`; string(data) != want {
+		t.Errorf("data = %s, want %s", data, want)
+	}
+}
+
+func TestTooManySymbols(t *testing.T) {
+	h := newTestHandler(t)
+	symbols := make([]string, maxSymbols+1)
+	for i := range symbols {
+		symbols[i] = `"S"`
+	}
+	query := `{ markets(symbols:[` + strings.Join(symbols, ",") + `]){ symbol } }`
+	code, resp := serve(h, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(query), nil))
+	if code != http.StatusOK || resp["errors"] == nil {
+		t.Errorf("code %d, resp %v", code, resp)
+	}
+}
+
+func TestBadRequest(t *testing.T) {
+	h := newTestHandler(t)
+	if code, _ := serve(h, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader("{"))); code != http.StatusBadRequest {
+		t.Errorf("invalid body: code %d", code)
+	}
+	if code, _ := serve(h, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{}`))); code != http.StatusBadRequest {
+		t.Errorf("missing query: code %d", code)
+	}
+	// 未知字段为查询错误
+	code, resp := serve(h, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ nope }"}`)))
+	if code != http.StatusOK || resp["errors"] == nil {
+		t.Errorf("unknown field: code %d, resp %v", code, resp)
+	}
+}
@@ -0,0 +1,200 @@
+package gql
+
+import (
+	"fmt"
+	"strings"
+
+	"market-system/services/api/internal/logic/market"
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// maxSymbols markets 查询最多的交易对数量
+const maxSymbols = 50
+
+// 字段名与 REST 接口的 JSON 字段相同。交易对的 ticker、depth、trades 只在查询中选择时读取，
+// 读取复用 REST 接口的逻辑（读缓存、软删除过滤、NaN/Inf 清洗），单个交易对读取失败时该字段为 null 并在 errors 中返回原因
+
+// marketSource markets 查询返回的交易对，子字段按需读取
+type marketSource struct {
+	Symbol string `json:"symbol"`
+}
+
+// longType 毫秒时间戳等超出 GraphQL Int（32 位）范围的整数
+var longType = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Long",
+	Description: "64 位整数",
+	Serialize: func(value interface{}) interface{} {
+		return value
+	},
+	ParseValue: func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case int:
+			return int64(v)
+		case float64:
+			return int64(v)
+		}
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		if v, ok := valueAST.(*ast.IntValue); ok {
+			var n int64
+			if _, err := fmt.Sscanf(v.Value, "%d", &n); err == nil {
+				return n
+			}
+		}
+		return nil
+	},
+})
+
+var tickerType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Ticker",
+	Fields: graphql.Fields{
+		"symbol":                   &graphql.Field{Type: graphql.String},
+		"last_price":               &graphql.Field{Type: graphql.Float},
+		"bid_price":                &graphql.Field{Type: graphql.Float},
+		"ask_price":                &graphql.Field{Type: graphql.Float},
+		"high_24h":                 &graphql.Field{Type: graphql.Float},
+		"low_24h":                  &graphql.Field{Type: graphql.Float},
+		"volume_24h":               &graphql.Field{Type: graphql.Float},
+		"open_24h":                 &graphql.Field{Type: graphql.Float},
+		"price_change_24h":         &graphql.Field{Type: graphql.Float},
+		"price_change_percent_24h": &graphql.Field{Type: graphql.Float},
+		"trade_count_24h":          &graphql.Field{Type: longType},
+		"timestamp":                &graphql.Field{Type: longType},
+		"age_ms":                   &graphql.Field{Type: longType},
+		"stale":                    &graphql.Field{Type: graphql.Boolean},
+	},
+})
+
+var priceLevelType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PriceLevel",
+	Fields: graphql.Fields{
+		"price":  &graphql.Field{Type: graphql.Float},
+		"amount": &graphql.Field{Type: graphql.Float},
+	},
+})
+
+var depthType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Depth",
+	Fields: graphql.Fields{
+		"symbol":    &graphql.Field{Type: graphql.String},
+		"bids":      &graphql.Field{Type: graphql.NewList(priceLevelType)},
+		"asks":      &graphql.Field{Type: graphql.NewList(priceLevelType)},
+		"timestamp": &graphql.Field{Type: longType},
+	},
+})
+
+var tradeType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Trade",
+	Fields: graphql.Fields{
+		"trade_id":  &graphql.Field{Type: graphql.String},
+		"price":     &graphql.Field{Type: graphql.Float},
+		"amount":    &graphql.Field{Type: graphql.Float},
+		"side":      &graphql.Field{Type: graphql.String},
+		"timestamp": &graphql.Field{Type: longType},
+		"source":    &graphql.Field{Type: graphql.String},
+		"exchange":  &graphql.Field{Type: graphql.String},
+	},
+})
+
+// newSchema 创建查询 schema：
+//
+//	markets(symbols: [String!]!): [Market!]!
+//	market(symbol: String!): Market
+//	Market { symbol, ticker, depth(limit, precision), trades(limit, source) }
+func newSchema(svcCtx *svc.ServiceContext) (graphql.Schema, error) {
+	marketType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Market",
+		Fields: graphql.Fields{
+			"symbol": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"ticker": &graphql.Field{
+				Type: tickerType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					m := p.Source.(*marketSource)
+					return market.NewGetTickerLogic(p.Context, svcCtx).GetTicker(&types.TickerRequest{Symbol: m.Symbol})
+				},
+			},
+			"depth": &graphql.Field{
+				Type: depthType,
+				Args: graphql.FieldConfigArgument{
+					"limit":     &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 20},
+					"precision": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					m := p.Source.(*marketSource)
+					return market.NewGetDepthLogic(p.Context, svcCtx).GetDepth(&types.DepthRequest{
+						Symbol:    m.Symbol,
+						Limit:     int64(p.Args["limit"].(int)),
+						Precision: p.Args["precision"].(string),
+					})
+				},
+			},
+			"trades": &graphql.Field{
+				Type: graphql.NewList(tradeType),
+				Args: graphql.FieldConfigArgument{
+					"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 50},
+					"source": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: "all"},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					m := p.Source.(*marketSource)
+					resp, err := market.NewGetTradesLogic(p.Context, svcCtx).GetTrades(&types.TradesRequest{
+						Symbol: m.Symbol,
+						Limit:  int64(p.Args["limit"].(int)),
+						Source: p.Args["source"].(string),
+					})
+					if err != nil {
+						return nil, err
+					}
+					return resp.Trades, nil
+				},
+			},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"markets": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(marketType))),
+				Description: "多个交易对的行情，已软删除的交易对不返回",
+				Args: graphql.FieldConfigArgument{
+					"symbols": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					raw := p.Args["symbols"].([]interface{})
+					if len(raw) > maxSymbols {
+						return nil, fmt.Errorf("too many symbols: %d (max %d)", len(raw), maxSymbols)
+					}
+					markets := make([]*marketSource, 0, len(raw))
+					for _, v := range raw {
+						symbol := strings.ToUpper(v.(string))
+						if !svcCtx.Symbols.IsDeleted(symbol) {
+							markets = append(markets, &marketSource{Symbol: symbol})
+						}
+					}
+					return markets, nil
+				},
+			},
+			"market": &graphql.Field{
+				Type:        marketType,
+				Description: "单个交易对的行情，已软删除时为 null",
+				Args: graphql.FieldConfigArgument{
+					"symbol": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					symbol := strings.ToUpper(p.Args["symbol"].(string))
+					if svcCtx.Symbols.IsDeleted(symbol) {
+						return nil, nil
+					}
+					return &marketSource{Symbol: symbol}, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
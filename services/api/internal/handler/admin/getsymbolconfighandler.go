@@ -0,0 +1,25 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"market-system/services/api/internal/logic/admin"
+	"market-system/services/api/internal/response"
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+)
+
+func GetSymbolConfigHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.SymbolConfigRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			response.ParamError(r.Context(), w, err)
+			return
+		}
+
+		l := admin.NewGetSymbolConfigLogic(r.Context(), svcCtx)
+		resp, err := l.GetSymbolConfig(&req)
+		response.Write(r.Context(), w, resp, err)
+	}
+}
@@ -0,0 +1,17 @@
+package admin
+
+import (
+	"net/http"
+
+	"market-system/services/api/internal/logic/admin"
+	"market-system/services/api/internal/response"
+	"market-system/services/api/internal/svc"
+)
+
+func ListSymbolConfigsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := admin.NewListSymbolConfigsLogic(r.Context(), svcCtx)
+		resp, err := l.ListSymbolConfigs()
+		response.Write(r.Context(), w, resp, err)
+	}
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"net/http"
+
+	admin "market-system/services/api/internal/handler/admin"
+	system "market-system/services/api/internal/handler/system"
+	"market-system/services/api/internal/svc"
+
+	"github.com/zeromicro/go-zero/rest"
+)
+
+// RegisterAdminHandlers 注册管理接口（/api/v1/admin、/api/v1/system），只在开启认证时调用，
+// 未开启认证时不注册，避免管理接口无需 admin 权限即可访问
+func RegisterAdminHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodPost,
+				Path:    "/cache/:symbol/rebuild",
+				Handler: admin.RebuildCacheHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/backfill",
+				Handler: admin.BackfillHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/symbols",
+				Handler: admin.ListSymbolConfigsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/symbols/:symbol",
+				Handler: admin.GetSymbolConfigHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPut,
+				Path:    "/symbols/:symbol",
+				Handler: admin.SaveSymbolConfigHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/symbols/:symbol",
+				Handler: admin.DeleteSymbolConfigHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/symbols/:symbol/soft-delete",
+				Handler: admin.SoftDeleteSymbolHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/symbols/:symbol/restore",
+				Handler: admin.RestoreSymbolHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/groups",
+				Handler: admin.ListSymbolGroupsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/groups/:name",
+				Handler: admin.GetSymbolGroupHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPut,
+				Path:    "/groups/:name",
+				Handler: admin.SaveSymbolGroupHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/groups/:name",
+				Handler: admin.DeleteSymbolGroupHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/runtime",
+				Handler: admin.GetRuntimeHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/webhooks",
+				Handler: admin.GetWebhookStatsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/adapters/:exchange/reconnect",
+				Handler: admin.ReconnectAdapterHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/symbols/:symbol/flush",
+				Handler: admin.FlushSymbolHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/api/v1/admin"),
+	)
+
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/overview",
+				Handler: system.GetOverviewHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/consistency",
+				Handler: system.GetConsistencyHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/trade_reconcile",
+				Handler: system.GetTradeReconcileHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/api/v1/system"),
+	)
+}
@@ -0,0 +1,40 @@
+package binance
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest"
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"market-system/services/api/internal/logic/binance"
+	"market-system/services/api/internal/svc"
+)
+
+// RegisterHandlers 注册 Binance 兼容接口（/api/v3），开启 BinanceCompat 时调用
+func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/klines",
+				Handler: GetKlinesHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/ticker/24hr",
+				Handler: GetTicker24hrHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/depth",
+				Handler: GetDepthHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/api/v3"),
+	)
+}
+
+// writeError 按 Binance 格式返回错误
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	code, body := binance.ErrorResponse(err)
+	httpx.WriteJsonCtx(r.Context(), w, code, body)
+}
@@ -0,0 +1,27 @@
+package binance
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"market-system/services/api/internal/logic/binance"
+	"market-system/services/api/internal/svc"
+)
+
+func GetDepthHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req binance.DepthRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			writeError(w, r, binance.ParamError(err))
+			return
+		}
+
+		l := binance.NewGetDepthLogic(r.Context(), svcCtx)
+		resp, err := l.GetDepth(&req)
+		if err != nil {
+			writeError(w, r, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
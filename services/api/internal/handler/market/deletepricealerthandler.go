@@ -0,0 +1,25 @@
+package market
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"market-system/services/api/internal/logic/market"
+	"market-system/services/api/internal/response"
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+)
+
+func DeletePriceAlertHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.DeletePriceAlertRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			response.ParamError(r.Context(), w, err)
+			return
+		}
+
+		l := market.NewDeletePriceAlertLogic(r.Context(), svcCtx)
+		resp, err := l.DeletePriceAlert(&req)
+		response.Write(r.Context(), w, resp, err)
+	}
+}
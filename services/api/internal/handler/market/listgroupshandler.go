@@ -0,0 +1,17 @@
+package market
+
+import (
+	"net/http"
+
+	"market-system/services/api/internal/logic/market"
+	"market-system/services/api/internal/response"
+	"market-system/services/api/internal/svc"
+)
+
+func ListGroupsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := market.NewListGroupsLogic(r.Context(), svcCtx)
+		resp, err := l.ListGroups()
+		response.Write(r.Context(), w, resp, err)
+	}
+}
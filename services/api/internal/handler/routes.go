@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	market "market-system/services/api/internal/handler/market"
+	udf "market-system/services/api/internal/handler/udf"
 	"market-system/services/api/internal/svc"
 
 	"github.com/zeromicro/go-zero/rest"
@@ -17,6 +18,41 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/ticker/:symbol",
 				Handler: market.GetTickerHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/ticker/:symbol/sources",
+				Handler: market.GetTickerSourcesHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/tickers",
+				Handler: market.GetTickersHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/screener",
+				Handler: market.GetScreenerHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/stats/24h/:symbol",
+				Handler: market.GetStats24hHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/stats/24h",
+				Handler: market.ListStats24hHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/groups",
+				Handler: market.ListGroupsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/symbols",
+				Handler: market.ListSymbolsHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodGet,
 				Path:    "/kline",
@@ -27,7 +63,103 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/depth/:symbol",
 				Handler: market.GetDepthHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/depth/:symbol/sources",
+				Handler: market.GetDepthSourcesHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/bookTicker/:symbol",
+				Handler: market.GetBookTickerHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/snapshot/:symbol",
+				Handler: market.GetSnapshotHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/trades/:symbol",
+				Handler: market.GetTradesHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/agg_trades/:symbol",
+				Handler: market.GetAggTradesHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/twap/:symbol",
+				Handler: market.GetTWAPHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/vwap/:symbol",
+				Handler: market.GetVWAPHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/index/:symbol",
+				Handler: market.GetIndexHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/indicators",
+				Handler: market.GetIndicatorsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/bookstats/:symbol",
+				Handler: market.GetBookStatsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/slippage/:symbol",
+				Handler: market.GetSlippageHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/price_alerts",
+				Handler: market.CreatePriceAlertHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/price_alerts",
+				Handler: market.ListPriceAlertsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/price_alerts/:id",
+				Handler: market.DeletePriceAlertHandler(serverCtx),
+			},
 		},
 		rest.WithPrefix("/api/v1"),
 	)
+
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/config",
+				Handler: udf.GetUDFConfigHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/symbols",
+				Handler: udf.GetUDFSymbolHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/history",
+				Handler: udf.GetUDFHistoryHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/time",
+				Handler: udf.GetUDFTimeHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/api/v1/udf"),
+	)
 }
@@ -0,0 +1,17 @@
+package system
+
+import (
+	"net/http"
+
+	"market-system/services/api/internal/logic/system"
+	"market-system/services/api/internal/response"
+	"market-system/services/api/internal/svc"
+)
+
+func GetConsistencyHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := system.NewGetConsistencyLogic(r.Context(), svcCtx)
+		resp, err := l.GetConsistency()
+		response.Write(r.Context(), w, resp, err)
+	}
+}
@@ -0,0 +1,17 @@
+package system
+
+import (
+	"net/http"
+
+	"market-system/services/api/internal/logic/system"
+	"market-system/services/api/internal/response"
+	"market-system/services/api/internal/svc"
+)
+
+func GetOverviewHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := system.NewGetOverviewLogic(r.Context(), svcCtx)
+		resp, err := l.GetOverview()
+		response.Write(r.Context(), w, resp, err)
+	}
+}
@@ -0,0 +1,25 @@
+package system
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"market-system/services/api/internal/logic/system"
+	"market-system/services/api/internal/response"
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+)
+
+func GetTradeReconcileHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.TradeReconcileRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			response.ParamError(r.Context(), w, err)
+			return
+		}
+
+		l := system.NewGetTradeReconcileLogic(r.Context(), svcCtx)
+		resp, err := l.GetTradeReconcile(&req)
+		response.Write(r.Context(), w, resp, err)
+	}
+}
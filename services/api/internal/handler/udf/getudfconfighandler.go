@@ -0,0 +1,21 @@
+package udf
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"market-system/services/api/internal/logic/udf"
+	"market-system/services/api/internal/svc"
+)
+
+func GetUDFConfigHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := udf.NewGetUDFConfigLogic(r.Context(), svcCtx)
+		resp, err := l.GetUDFConfig()
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package udf
+
+import (
+	"net/http"
+	"strconv"
+
+	"market-system/services/api/internal/logic/udf"
+	"market-system/services/api/internal/svc"
+)
+
+// GetUDFTimeHandler UDF 要求返回纯文本的秒级时间戳
+func GetUDFTimeHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := udf.NewGetUDFTimeLogic(r.Context(), svcCtx)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(strconv.FormatInt(l.GetUDFTime(), 10)))
+	}
+}
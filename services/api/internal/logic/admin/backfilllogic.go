@@ -0,0 +1,70 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"strings"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type BackfillLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewBackfillLogic(ctx context.Context, svcCtx *svc.ServiceContext) *BackfillLogic {
+	return &BackfillLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Backfill 通知 Processor 回补历史K线，交易对和周期为空时使用 Processor 的配置
+// 回补异步执行，只补齐最新已保存K线之后缺失的K线；需要整体重建时使用缓存重建接口
+func (l *BackfillLogic) Backfill(req *types.BackfillRequest) (resp *types.BackfillResponse, err error) {
+	var request models.BackfillRequest
+	if req.Symbols != "" {
+		request.Symbols = strings.Split(req.Symbols, ",")
+		for _, symbol := range request.Symbols {
+			if err := utils.ValidateSymbol(symbol); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if req.Intervals != "" {
+		request.Intervals = strings.Split(req.Intervals, ",")
+		for _, interval := range request.Intervals {
+			if !utils.ValidateInterval(interval) {
+				return nil, errcode.Newf(errcode.ErrInvalidParam, "invalid interval: %s", interval)
+			}
+		}
+	}
+
+	data, err := utils.ToJSON(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// 返回收到请求的 Processor 数量，为 0 表示没有启用回补的 Processor 在运行
+	receivers, err := l.svcCtx.Redis.Publish(l.ctx, constants.RedisChannelBackfill, data).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish backfill request: %w", err)
+	}
+
+	l.Infof("[Admin] Requested backfill for symbols %v intervals %v, receivers: %d", request.Symbols, request.Intervals, receivers)
+	return &types.BackfillResponse{
+		Symbols:   request.Symbols,
+		Intervals: request.Intervals,
+		Receivers: receivers,
+	}, nil
+}
@@ -0,0 +1,40 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/constants"
+
+	"market-system/services/api/internal/svc"
+)
+
+// purgeSymbolCache 删除交易对的所有缓存 key（ticker/depth/kline/trade 及来源明细），返回删除的 key 数量
+// API 实例的本地读缓存不清除，在缓存期后失效
+func purgeSymbolCache(ctx context.Context, svcCtx *svc.ServiceContext, symbol string) (int64, error) {
+	keys := []string{
+		constants.RedisKeyTicker + symbol,
+		constants.RedisKeyTickerSource + symbol,
+		constants.RedisKeyDepth + symbol,
+		constants.RedisKeyDepthSource + symbol,
+		constants.RedisKeyTrade + symbol,
+		constants.RedisKeyAggTrade + symbol,
+		constants.RedisKeyBookTicker + symbol,
+	}
+
+	// 聚合深度 depth:{symbol}:{precision} 和 K线 kline:{symbol}:{interval}[:{source}] 带后缀，通过 SCAN 查找
+	for _, prefix := range []string{constants.RedisKeyDepth, constants.RedisKeyKline} {
+		iter := svcCtx.Redis.Scan(ctx, 0, prefix+symbol+":*", 100).Iterator()
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return 0, fmt.Errorf("failed to scan %s keys: %w", prefix+symbol, err)
+		}
+	}
+
+	deleted, err := svcCtx.Redis.Del(ctx, keys...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge cache: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,48 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/constants"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteSymbolConfigLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteSymbolConfigLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteSymbolConfigLogic {
+	return &DeleteSymbolConfigLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteSymbolConfig 删除交易对配置，Collector 收到通知后该交易对的数据不再融合，直接透传
+func (l *DeleteSymbolConfigLogic) DeleteSymbolConfig(req *types.SymbolConfigRequest) (resp *types.DeleteSymbolConfigResponse, err error) {
+	deleted, err := l.svcCtx.Redis.HDel(l.ctx, constants.RedisKeySymbolConfig, req.Symbol).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete symbol config: %w", err)
+	}
+	if deleted > 0 {
+		if err := notifySymbolConfig(l.ctx, l.svcCtx, req.Symbol); err != nil {
+			return nil, err
+		}
+		if err := publishSymbolConfigEvent(l.ctx, l.svcCtx, constants.SymbolConfigRemoved, req.Symbol, nil); err != nil {
+			l.Errorf("[Admin] %v", err)
+		}
+		l.Infof("[Admin] Deleted symbol config: %s", req.Symbol)
+	}
+
+	return &types.DeleteSymbolConfigResponse{
+		Symbol:  req.Symbol,
+		Deleted: deleted > 0,
+	}, nil
+}
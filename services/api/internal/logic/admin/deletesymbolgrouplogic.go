@@ -0,0 +1,45 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/constants"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteSymbolGroupLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteSymbolGroupLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteSymbolGroupLogic {
+	return &DeleteSymbolGroupLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteSymbolGroup 删除交易对分组，分组频道不再推送，已订阅的客户端不会收到通知
+func (l *DeleteSymbolGroupLogic) DeleteSymbolGroup(req *types.SymbolGroupRequest) (resp *types.DeleteSymbolGroupResponse, err error) {
+	deleted, err := l.svcCtx.Redis.HDel(l.ctx, constants.RedisKeySymbolGroup, req.Name).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete symbol group: %w", err)
+	}
+	if deleted > 0 {
+		if err := notifySymbolGroup(l.ctx, l.svcCtx, req.Name); err != nil {
+			return nil, err
+		}
+		l.Infof("[Admin] Deleted symbol group: %s", req.Name)
+	}
+
+	return &types.DeleteSymbolGroupResponse{
+		Name:    req.Name,
+		Deleted: deleted > 0,
+	}, nil
+}
@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"context"
+	"market-system/common/utils"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type FlushSymbolLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFlushSymbolLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FlushSymbolLogic {
+	return &FlushSymbolLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// FlushSymbol 清除交易对的缓存数据，不从交易所重新拉取，Processor 收到新的行情后重新写入
+// 需要立即恢复数据时使用缓存重建接口
+func (l *FlushSymbolLogic) FlushSymbol(req *types.FlushSymbolRequest) (resp *types.FlushSymbolResponse, err error) {
+	if err := utils.ValidateSymbol(req.Symbol); err != nil {
+		return nil, err
+	}
+
+	deleted, err := purgeSymbolCache(l.ctx, l.svcCtx, req.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Infof("[Admin] Flushed %d keys for %s", deleted, req.Symbol)
+	return &types.FlushSymbolResponse{
+		Symbol:      req.Symbol,
+		DeletedKeys: deleted,
+	}, nil
+}
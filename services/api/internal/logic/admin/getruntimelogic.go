@@ -0,0 +1,93 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetRuntimeLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetRuntimeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetRuntimeLogic {
+	return &GetRuntimeLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetRuntime 获取运行时状态
+// WebSocket 连接数和频道订阅数为处理请求的 API 实例的数据；Kafka 消费延迟、交易所适配器状态
+// 来自 Collector/Processor 定期写入 Redis 的统计，服务未上报时对应数据为空
+func (l *GetRuntimeLogic) GetRuntime() (resp *types.RuntimeResponse, err error) {
+	throttle := l.svcCtx.WsHub.ThrottleStats()
+	resp = &types.RuntimeResponse{
+		WsClients: l.svcCtx.WsHub.ClientCount(),
+		Channels:  l.svcCtx.WsHub.ChannelSubscribers(),
+		WsThrottle: types.WsThrottleStats{
+			ThrottledMessages: throttle.ThrottledMessages,
+			ThrottledClients:  throttle.ThrottledClients,
+			Disconnected:      throttle.Disconnected,
+		},
+		KafkaLag:  make(map[string]int64),
+		Adapters:  make(map[string]types.AdapterStatus),
+		Services:  make(map[string]types.ServiceStatus),
+		Timestamp: utils.GetCurrentTimestamp(),
+	}
+
+	// Collector：交易所连接状态及错误统计
+	if stats := l.loadServiceStats(constants.ServiceCollector); stats != nil {
+		for exchange, connected := range stats.Exchanges {
+			resp.Adapters[exchange] = types.AdapterStatus{
+				Connected: connected,
+				Errors:    stats.AdapterErrors[exchange],
+			}
+		}
+		resp.Services[constants.ServiceCollector] = types.ServiceStatus{Online: true, Timestamp: stats.Timestamp}
+	} else {
+		resp.Services[constants.ServiceCollector] = types.ServiceStatus{}
+	}
+
+	// Processor：Kafka 消费延迟
+	if stats := l.loadServiceStats(constants.ServiceProcessor); stats != nil {
+		for topic, lag := range stats.KafkaLag {
+			resp.KafkaLag[topic] = lag
+			resp.TotalKafkaLag += lag
+		}
+		resp.Services[constants.ServiceProcessor] = types.ServiceStatus{Online: true, Timestamp: stats.Timestamp}
+	} else {
+		resp.Services[constants.ServiceProcessor] = types.ServiceStatus{}
+	}
+
+	return resp, nil
+}
+
+// loadServiceStats 读取服务上报的统计，不存在或解析失败时返回 nil
+func (l *GetRuntimeLogic) loadServiceStats(service string) *models.ServiceStats {
+	data, err := l.svcCtx.Redis.Get(l.ctx, constants.RedisKeyServiceStats+service).Result()
+	if err != nil {
+		if err != redis.Nil {
+			l.Errorf("[Admin] Failed to get %s stats: %v", service, err)
+		}
+		return nil
+	}
+
+	var stats models.ServiceStats
+	if err := json.Unmarshal([]byte(data), &stats); err != nil {
+		l.Errorf("[Admin] Invalid %s stats: %v", service, err)
+		return nil
+	}
+	return &stats
+}
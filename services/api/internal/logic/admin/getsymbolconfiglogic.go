@@ -0,0 +1,38 @@
+package admin
+
+import (
+	"context"
+	"market-system/common/errcode"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetSymbolConfigLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetSymbolConfigLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetSymbolConfigLogic {
+	return &GetSymbolConfigLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetSymbolConfigLogic) GetSymbolConfig(req *types.SymbolConfigRequest) (resp *types.SymbolConfigResponse, err error) {
+	cfg, err := loadSymbolConfig(l.ctx, l.svcCtx, req.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, errcode.Newf(errcode.ErrNotFound, "symbol config not found: %s", req.Symbol)
+	}
+
+	result := toSymbolConfigResponse(cfg)
+	return &result, nil
+}
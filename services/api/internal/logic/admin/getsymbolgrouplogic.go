@@ -0,0 +1,39 @@
+package admin
+
+import (
+	"context"
+	"market-system/common/errcode"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetSymbolGroupLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetSymbolGroupLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetSymbolGroupLogic {
+	return &GetSymbolGroupLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetSymbolGroup 获取交易对分组
+func (l *GetSymbolGroupLogic) GetSymbolGroup(req *types.SymbolGroupRequest) (resp *types.SymbolGroupResponse, err error) {
+	group, err := loadSymbolGroup(l.ctx, l.svcCtx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, errcode.Newf(errcode.ErrNotFound, "symbol group not found: %s", req.Name)
+	}
+
+	result := toSymbolGroupResponse(group)
+	return &result, nil
+}
@@ -0,0 +1,73 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"sort"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetWebhookStatsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetWebhookStatsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetWebhookStatsLogic {
+	return &GetWebhookStatsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetWebhookStats 获取 webhook 服务和 Processor（价格提醒）上报的各地址推送统计，都未运行或统计过期时 online 为 false
+func (l *GetWebhookStatsLogic) GetWebhookStats() (resp *types.WebhookStatsResponse, err error) {
+	resp = &types.WebhookStatsResponse{Endpoints: make([]types.WebhookEndpointStatus, 0)}
+
+	keys := []string{constants.RedisKeyServiceStats + constants.ServiceWebhook, constants.RedisKeyPriceAlertWebhookStats}
+	values, err := l.svcCtx.Redis.MGet(l.ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook stats: %w", err)
+	}
+
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue // 未上报或已过期
+		}
+		var stats models.WebhookStats
+		if err := json.Unmarshal([]byte(data), &stats); err != nil {
+			return nil, fmt.Errorf("failed to parse webhook stats %s: %w", keys[i], err)
+		}
+
+		resp.Online = true
+		if stats.Timestamp > resp.Timestamp {
+			resp.Timestamp = stats.Timestamp
+		}
+		for name, s := range stats.Endpoints {
+			resp.Endpoints = append(resp.Endpoints, types.WebhookEndpointStatus{
+				Name:           name,
+				Url:            s.URL,
+				Delivered:      s.Delivered,
+				Failed:         s.Failed,
+				Retries:        s.Retries,
+				Dropped:        s.Dropped,
+				Pending:        s.Pending,
+				LastStatus:     s.LastStatus,
+				LastError:      s.LastError,
+				LastLatencyMs:  s.LastLatencyMs,
+				LastDeliveryAt: s.LastDeliveryAt,
+			})
+		}
+	}
+	sort.Slice(resp.Endpoints, func(i, j int) bool { return resp.Endpoints[i].Name < resp.Endpoints[j].Name })
+	return resp, nil
+}
@@ -0,0 +1,54 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"sort"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListSymbolConfigsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListSymbolConfigsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListSymbolConfigsLogic {
+	return &ListSymbolConfigsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ListSymbolConfigs 获取通过管理接口维护的全部交易对配置（不包含 Collector 配置文件中的配置）
+func (l *ListSymbolConfigsLogic) ListSymbolConfigs() (resp *types.SymbolConfigListResponse, err error) {
+	data, err := l.svcCtx.Redis.HGetAll(l.ctx, constants.RedisKeySymbolConfig).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list symbol configs: %w", err)
+	}
+
+	resp = &types.SymbolConfigListResponse{
+		Configs: make([]types.SymbolConfigResponse, 0, len(data)),
+	}
+	for symbol, raw := range data {
+		var cfg models.SymbolConfig
+		if err := json.Unmarshal([]byte(raw), &cfg); err != nil {
+			l.Errorf("[Admin] Invalid symbol config for %s: %v", symbol, err)
+			continue
+		}
+		resp.Configs = append(resp.Configs, toSymbolConfigResponse(&cfg))
+	}
+
+	sort.Slice(resp.Configs, func(i, j int) bool {
+		return resp.Configs[i].Symbol < resp.Configs[j].Symbol
+	})
+	return resp, nil
+}
@@ -0,0 +1,54 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"sort"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListSymbolGroupsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListSymbolGroupsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListSymbolGroupsLogic {
+	return &ListSymbolGroupsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ListSymbolGroups 获取全部交易对分组
+func (l *ListSymbolGroupsLogic) ListSymbolGroups() (resp *types.SymbolGroupListResponse, err error) {
+	data, err := l.svcCtx.Redis.HGetAll(l.ctx, constants.RedisKeySymbolGroup).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list symbol groups: %w", err)
+	}
+
+	resp = &types.SymbolGroupListResponse{
+		Groups: make([]types.SymbolGroupResponse, 0, len(data)),
+	}
+	for name, raw := range data {
+		var group models.SymbolGroup
+		if err := json.Unmarshal([]byte(raw), &group); err != nil {
+			l.Errorf("[Admin] Invalid symbol group %s: %v", name, err)
+			continue
+		}
+		resp.Groups = append(resp.Groups, toSymbolGroupResponse(&group))
+	}
+
+	sort.Slice(resp.Groups, func(i, j int) bool {
+		return resp.Groups[i].Name < resp.Groups[j].Name
+	})
+	return resp, nil
+}
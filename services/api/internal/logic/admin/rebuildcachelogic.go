@@ -0,0 +1,277 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/exchange"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"strings"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 重建时默认拉取的K线周期，与 Processor 聚合的周期一致
+var defaultRebuildIntervals = []string{
+	constants.Interval1m,
+	constants.Interval5m,
+	constants.Interval15m,
+	constants.Interval1h,
+	constants.Interval4h,
+	constants.Interval1d,
+	constants.Interval1w,
+	constants.Interval1M,
+}
+
+type RebuildCacheLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRebuildCacheLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RebuildCacheLogic {
+	return &RebuildCacheLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// RebuildCache 清除交易对的所有缓存数据（ticker/depth/kline/trade），并从交易所 REST 接口重新拉取
+// 目前仅支持从交易所 REST 重建，单项失败不影响其他数据的重建，错误汇总在响应中返回
+func (l *RebuildCacheLogic) RebuildCache(req *types.RebuildCacheRequest) (resp *types.RebuildCacheResponse, err error) {
+	if err := utils.ValidateSymbol(req.Symbol); err != nil {
+		return nil, err
+	}
+
+	client := exchange.NewRESTClient(req.Source)
+	if client == nil {
+		return nil, errcode.Newf(errcode.ErrInvalidParam, "unsupported source: %s", req.Source)
+	}
+
+	intervals := defaultRebuildIntervals
+	if req.Intervals != "" {
+		intervals = strings.Split(req.Intervals, ",")
+		for _, interval := range intervals {
+			if !utils.ValidateInterval(interval) {
+				return nil, errcode.Newf(errcode.ErrInvalidParam, "invalid interval: %s", interval)
+			}
+		}
+	}
+
+	limit := req.KlineLimit
+	if limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
+
+	resp = &types.RebuildCacheResponse{
+		Symbol: req.Symbol,
+		Source: req.Source,
+		Klines: make(map[string]int),
+	}
+
+	// 1. 清除缓存，清除前记录已有K线的修订号，重建的K线在此基础上递增
+	revisions := l.klineRevisions(req.Symbol, intervals)
+	deleted, err := purgeSymbolCache(l.ctx, l.svcCtx, req.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	resp.DeletedKeys = deleted
+	l.Infof("[Admin] Purged %d keys for %s", deleted, req.Symbol)
+
+	// 2. 重建 Ticker
+	if ticker, err := client.GetTicker(req.Symbol); err != nil {
+		resp.Errors = append(resp.Errors, err.Error())
+	} else if err := l.saveTicker(client.Name(), ticker); err != nil {
+		resp.Errors = append(resp.Errors, err.Error())
+	} else {
+		resp.Ticker = true
+	}
+
+	// 3. 重建深度
+	if depth, err := client.GetDepth(req.Symbol, constants.MaxDepthLevel); err != nil {
+		resp.Errors = append(resp.Errors, err.Error())
+	} else if err := l.saveDepth(client.Name(), depth); err != nil {
+		resp.Errors = append(resp.Errors, err.Error())
+	} else {
+		resp.DepthLevels = len(depth.Bids) + len(depth.Asks)
+	}
+
+	// 4. 重建K线
+	for _, interval := range intervals {
+		klines, err := client.GetKlines(req.Symbol, interval, 0, 0, limit)
+		if err != nil {
+			resp.Errors = append(resp.Errors, err.Error())
+			continue
+		}
+		klines, err = l.saveKlines(client.Name(), req.Symbol, interval, klines, revisions[interval])
+		if err != nil {
+			resp.Errors = append(resp.Errors, err.Error())
+			continue
+		}
+		resp.Klines[interval] = len(klines)
+	}
+
+	// 5. 重建成交
+	if trades, err := client.GetTrades(req.Symbol, 100); err != nil {
+		resp.Errors = append(resp.Errors, err.Error())
+	} else if saved, err := l.saveTrades(client.Name(), req.Symbol, trades); err != nil {
+		resp.Errors = append(resp.Errors, err.Error())
+	} else {
+		resp.Trades = saved
+	}
+
+	l.Infof("[Admin] Rebuilt cache for %s from %s, errors: %d", req.Symbol, req.Source, len(resp.Errors))
+	return resp, nil
+}
+
+// saveTicker 写入 Ticker，格式、保留策略和编码与 Processor 保持一致
+func (l *RebuildCacheLogic) saveTicker(source string, ticker *models.Ticker) error {
+	if !l.svcCtx.Sanitizer.Ticker(source, ticker) {
+		return fmt.Errorf("invalid ticker data from %s", source)
+	}
+
+	key := constants.RedisKeyTicker + ticker.Symbol
+	if ticker.Timestamp == 0 {
+		ticker.Timestamp = utils.GetCurrentTimestamp()
+	}
+
+	pipe := l.svcCtx.Redis.Pipeline()
+	pipe.HSet(l.ctx, key, map[string]interface{}{
+		"last_price":               ticker.LastPrice,
+		"bid_price":                ticker.BidPrice,
+		"ask_price":                ticker.AskPrice,
+		"high_24h":                 ticker.High24h,
+		"low_24h":                  ticker.Low24h,
+		"volume_24h":               ticker.Volume24h,
+		"open_24h":                 ticker.Open24h,
+		"price_change_24h":         ticker.PriceChange24h,
+		"price_change_percent_24h": ticker.PriceChangePercent24h,
+		"trade_count_24h":          ticker.TradeCount24h,
+		"timestamp":                ticker.Timestamp,
+	})
+	pipe.Expire(l.ctx, key, l.svcCtx.Retention.Policy(ticker.Symbol).TickerTTL)
+	if _, err := pipe.Exec(l.ctx); err != nil {
+		return fmt.Errorf("failed to save ticker: %w", err)
+	}
+	return nil
+}
+
+// saveDepth 写入深度快照
+func (l *RebuildCacheLogic) saveDepth(source string, depth *models.OrderBook) error {
+	l.svcCtx.Sanitizer.OrderBook(source, depth)
+
+	if depth.Timestamp == 0 {
+		depth.Timestamp = utils.GetCurrentTimestamp()
+	}
+
+	data, err := l.svcCtx.Codec.Marshal(depth)
+	if err != nil {
+		return err
+	}
+
+	key := constants.RedisKeyDepth + depth.Symbol
+	if err := l.svcCtx.Redis.Set(l.ctx, key, data, l.svcCtx.Retention.Policy(depth.Symbol).DepthTTL).Err(); err != nil {
+		return fmt.Errorf("failed to save depth: %w", err)
+	}
+	return nil
+}
+
+// klineRevisions 读取交易对各周期已有K线的修订号，key: 周期 -> 开盘时间
+func (l *RebuildCacheLogic) klineRevisions(symbol string, intervals []string) map[string]map[int64]int64 {
+	revisions := make(map[string]map[int64]int64, len(intervals))
+	for _, interval := range intervals {
+		key := fmt.Sprintf("%s%s:%s", constants.RedisKeyKline, symbol, interval)
+		items, err := l.svcCtx.Redis.ZRange(l.ctx, key, 0, -1).Result()
+		if err != nil {
+			l.Errorf("[Admin] Failed to read %s klines for %s: %v", interval, symbol, err)
+			continue
+		}
+
+		revisions[interval] = make(map[int64]int64, len(items))
+		for _, item := range items {
+			var kline models.Kline
+			if err := codec.Unmarshal([]byte(item), &kline); err != nil {
+				continue
+			}
+			revisions[interval][kline.OpenTime] = kline.Revision
+		}
+	}
+	return revisions
+}
+
+// saveKlines 写入K线有序集合（分数为开盘时间，与 Processor 保持一致），返回实际写入的K线
+// 重建前已存在的K线视为修订，修订号在原修订号上加 1；尚未收盘的最新K线 is_final 为 false
+func (l *RebuildCacheLogic) saveKlines(source, symbol, interval string, klines []*models.Kline, revisions map[int64]int64) ([]*models.Kline, error) {
+	now := utils.GetCurrentTimestamp()
+	valid := make([]*models.Kline, 0, len(klines))
+	for _, kline := range klines {
+		if !l.svcCtx.Sanitizer.Kline(source, kline) {
+			continue
+		}
+		if revision, ok := revisions[kline.OpenTime]; ok {
+			kline.Revision = revision + 1
+		}
+		kline.IsFinal = kline.CloseTime < now
+		valid = append(valid, kline)
+	}
+	if len(valid) == 0 {
+		return valid, nil
+	}
+
+	key := fmt.Sprintf("%s%s:%s", constants.RedisKeyKline, symbol, interval)
+	members := make([]redis.Z, 0, len(valid))
+	for _, kline := range valid {
+		data, err := l.svcCtx.Codec.Marshal(kline)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, redis.Z{Score: float64(kline.OpenTime), Member: data})
+	}
+	policy := l.svcCtx.Retention.Policy(symbol)
+	pipe := l.svcCtx.Redis.Pipeline()
+	pipe.ZAdd(l.ctx, key, members...)
+	pipe.ZRemRangeByRank(l.ctx, key, 0, -policy.KlineListSize(interval)-1)
+	pipe.Expire(l.ctx, key, policy.KlineExpiration(interval))
+
+	if _, err := pipe.Exec(l.ctx); err != nil {
+		return nil, fmt.Errorf("failed to save %s klines: %w", interval, err)
+	}
+	return valid, nil
+}
+
+// saveTrades 写入成交列表（按时间升序 LPUSH，使最新的成交位于列表头部），返回实际写入的条数
+func (l *RebuildCacheLogic) saveTrades(source, symbol string, trades []*models.Trade) (int, error) {
+	key := constants.RedisKeyTrade + symbol
+	pipe := l.svcCtx.Redis.Pipeline()
+	saved := 0
+	for _, trade := range trades {
+		if !l.svcCtx.Sanitizer.Trade(source, trade) {
+			continue
+		}
+		data, err := l.svcCtx.Codec.Marshal(trade)
+		if err != nil {
+			return 0, err
+		}
+		pipe.LPush(l.ctx, key, data)
+		saved++
+	}
+	if saved == 0 {
+		return 0, nil
+	}
+	policy := l.svcCtx.Retention.Policy(symbol)
+	pipe.LTrim(l.ctx, key, 0, policy.TradeSize-1)
+	pipe.Expire(l.ctx, key, policy.TradeTTL)
+
+	if _, err := pipe.Exec(l.ctx); err != nil {
+		return 0, fmt.Errorf("failed to save trades: %w", err)
+	}
+	return saved, nil
+}
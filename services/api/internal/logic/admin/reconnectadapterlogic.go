@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+	"market-system/common/utils"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 支持强制重连的交易所（Collector 中实现了 Reconnector 的适配器）
+var reconnectableExchanges = map[string]bool{
+	constants.ExchangeBinance: true,
+	constants.ExchangeOKX:     true,
+}
+
+type ReconnectAdapterLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewReconnectAdapterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ReconnectAdapterLogic {
+	return &ReconnectAdapterLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ReconnectAdapter 通知 Collector 强制交易所适配器重连，重连后恢复原有订阅
+// 命令异步执行，重连结果通过运行时状态接口的适配器连接状态查看
+func (l *ReconnectAdapterLogic) ReconnectAdapter(req *types.ReconnectAdapterRequest) (resp *types.ReconnectAdapterResponse, err error) {
+	if !reconnectableExchanges[req.Exchange] {
+		return nil, errcode.Newf(errcode.ErrInvalidParam, "unsupported exchange: %s", req.Exchange)
+	}
+
+	data, err := utils.ToJSON(models.AdapterCommand{
+		Action:   models.AdapterActionReconnect,
+		Exchange: req.Exchange,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 返回收到命令的 Collector 数量，为 0 表示没有配置 Redis 的 Collector 在运行
+	receivers, err := l.svcCtx.Redis.Publish(l.ctx, constants.RedisChannelAdapter, data).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish adapter command: %w", err)
+	}
+
+	l.Infof("[Admin] Requested %s reconnect, receivers: %d", req.Exchange, receivers)
+	return &types.ReconnectAdapterResponse{
+		Exchange:  req.Exchange,
+		Receivers: receivers,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package admin
+
+import (
+	"context"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RestoreSymbolLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRestoreSymbolLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RestoreSymbolLogic {
+	return &RestoreSymbolLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// RestoreSymbol 恢复软删除的交易对，已保留的数据立即重新可见，Processor 收到新数据后恢复处理
+func (l *RestoreSymbolLogic) RestoreSymbol(req *types.SymbolConfigRequest) (resp *types.SymbolConfigResponse, err error) {
+	cfg, changed, err := setSymbolDeleted(l.ctx, l.svcCtx, req.Symbol, false)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		l.Infof("[Admin] Restored symbol: %s", req.Symbol)
+	}
+
+	result := toSymbolConfigResponse(cfg)
+	return &result, nil
+}
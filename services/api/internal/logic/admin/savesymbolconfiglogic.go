@@ -0,0 +1,88 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SaveSymbolConfigLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSaveSymbolConfigLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SaveSymbolConfigLogic {
+	return &SaveSymbolConfigLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SaveSymbolConfig 创建或更新交易对配置，并通知 Collector 实时生效
+func (l *SaveSymbolConfigLogic) SaveSymbolConfig(req *types.SaveSymbolConfigRequest) (resp *types.SymbolConfigResponse, err error) {
+	if err := utils.ValidateSymbol(req.Symbol); err != nil {
+		return nil, err
+	}
+
+	cfg := &models.SymbolConfig{
+		Symbol:         req.Symbol,
+		Mode:           req.Mode,
+		PrimarySource:  req.PrimarySource,
+		ExternalSource: req.ExternalSource,
+		MergeStrategy:  req.MergeStrategy,
+		Enable:         req.Enable,
+		Description:    req.Description,
+		TickSize:       req.TickSize,
+		VWAP: models.VWAPConfig{
+			InternalWeight:    req.Vwap.InternalWeight,
+			ExternalWeight:    req.Vwap.ExternalWeight,
+			MinInternalVolume: req.Vwap.MinInternalVolume,
+			MinExternalVolume: req.Vwap.MinExternalVolume,
+		},
+		FreshnessMs:    req.FreshnessMs,
+		MaxDepthLevels: req.MaxDepthLevels,
+		TrimPercent:    req.TrimPercent,
+	}
+	if err := validateSymbolConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	previous, err := loadSymbolConfig(l.ctx, l.svcCtx, cfg.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	// 软删除状态只能通过删除/恢复接口修改
+	if previous != nil {
+		cfg.Deleted = previous.Deleted
+		cfg.DeletedAt = previous.DeletedAt
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.svcCtx.Redis.HSet(l.ctx, constants.RedisKeySymbolConfig, cfg.Symbol, data).Err(); err != nil {
+		return nil, fmt.Errorf("failed to save symbol config: %w", err)
+	}
+	if err := notifySymbolConfig(l.ctx, l.svcCtx, cfg.Symbol); err != nil {
+		return nil, err
+	}
+	// 配置已生效，推送失败只记录日志
+	if err := publishSymbolConfigEvent(l.ctx, l.svcCtx, symbolConfigEvent(previous, cfg), cfg.Symbol, cfg); err != nil {
+		l.Errorf("[Admin] %v", err)
+	}
+
+	l.Infof("[Admin] Saved symbol config: %s, mode: %s, strategy: %s", cfg.Symbol, cfg.Mode, cfg.MergeStrategy)
+	result := toSymbolConfigResponse(cfg)
+	return &result, nil
+}
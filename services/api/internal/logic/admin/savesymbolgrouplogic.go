@@ -0,0 +1,61 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SaveSymbolGroupLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSaveSymbolGroupLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SaveSymbolGroupLogic {
+	return &SaveSymbolGroupLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SaveSymbolGroup 创建或更新交易对分组（整体替换分组内的交易对），并通知各 API 实例实时生效
+func (l *SaveSymbolGroupLogic) SaveSymbolGroup(req *types.SaveSymbolGroupRequest) (resp *types.SymbolGroupResponse, err error) {
+	if err := utils.ValidateGroupName(req.Name); err != nil {
+		return nil, err
+	}
+	symbols, err := normalizeGroupSymbols(req.Symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &models.SymbolGroup{
+		Name:        req.Name,
+		Description: req.Description,
+		Symbols:     symbols,
+	}
+
+	data, err := json.Marshal(group)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.svcCtx.Redis.HSet(l.ctx, constants.RedisKeySymbolGroup, group.Name, data).Err(); err != nil {
+		return nil, fmt.Errorf("failed to save symbol group: %w", err)
+	}
+	if err := notifySymbolGroup(l.ctx, l.svcCtx, group.Name); err != nil {
+		return nil, err
+	}
+
+	l.Infof("[Admin] Saved symbol group: %s, symbols: %d", group.Name, len(group.Symbols))
+	result := toSymbolGroupResponse(group)
+	return &result, nil
+}
@@ -0,0 +1,38 @@
+package admin
+
+import (
+	"context"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SoftDeleteSymbolLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSoftDeleteSymbolLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SoftDeleteSymbolLogic {
+	return &SoftDeleteSymbolLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SoftDeleteSymbol 软删除交易对：REST/WebSocket 不再返回该交易对，Processor 停止处理，已有数据保留，可通过恢复接口恢复
+func (l *SoftDeleteSymbolLogic) SoftDeleteSymbol(req *types.SymbolConfigRequest) (resp *types.SymbolConfigResponse, err error) {
+	cfg, changed, err := setSymbolDeleted(l.ctx, l.svcCtx, req.Symbol, true)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		l.Infof("[Admin] Soft deleted symbol: %s", req.Symbol)
+	}
+
+	result := toSymbolConfigResponse(cfg)
+	return &result, nil
+}
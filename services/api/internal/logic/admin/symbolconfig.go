@@ -0,0 +1,183 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+	"market-system/common/utils"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 交易对配置存储在 Redis Hash symbol_config 中，变更后通过 symbol_config:update 频道通知 Collector，
+// Collector 的融合器收到通知后重新加载对应交易对的配置，无需重启。
+// 软删除状态同样记录在配置中，API 和 Processor 监听同一频道，据此隐藏和停止处理已软删除的交易对。
+
+var validModes = map[string]bool{
+	constants.ModeInternalOnly: true,
+	constants.ModeExternalOnly: true,
+	constants.ModeHybrid:       true,
+}
+
+var validSources = map[string]bool{
+	constants.SourceInternal: true,
+	constants.SourceExternal: true,
+}
+
+var validMergeStrategies = map[string]bool{
+	constants.MergeStrategyPriority:    true,
+	constants.MergeStrategySupplement:  true,
+	constants.MergeStrategyOverride:    true,
+	constants.MergeStrategyVWAP:        true,
+	constants.MergeStrategyMedian:      true,
+	constants.MergeStrategyTrimmedMean: true,
+}
+
+// validateSymbolConfig 校验交易对配置
+func validateSymbolConfig(cfg *models.SymbolConfig) error {
+	if !validModes[cfg.Mode] {
+		return errcode.Newf(errcode.ErrInvalidParam, "invalid mode: %s", cfg.Mode)
+	}
+	if cfg.PrimarySource != "" && !validSources[cfg.PrimarySource] {
+		return errcode.Newf(errcode.ErrInvalidParam, "invalid primary source: %s", cfg.PrimarySource)
+	}
+	if cfg.Mode == constants.ModeHybrid && !validMergeStrategies[cfg.MergeStrategy] {
+		return errcode.Newf(errcode.ErrInvalidParam, "invalid merge strategy: %s", cfg.MergeStrategy)
+	}
+	if cfg.TickSize < 0 || cfg.FreshnessMs < 0 || cfg.MaxDepthLevels < 0 {
+		return errcode.Newf(errcode.ErrInvalidParam, "tick_size, freshness_ms and max_depth_levels must not be negative")
+	}
+	if cfg.TrimPercent < 0 || cfg.TrimPercent >= 50 {
+		return errcode.Newf(errcode.ErrInvalidParam, "trim_percent must be in [0, 50)")
+	}
+	return nil
+}
+
+// loadSymbolConfig 从 Redis 读取交易对配置，不存在时返回 nil
+func loadSymbolConfig(ctx context.Context, svcCtx *svc.ServiceContext, symbol string) (*models.SymbolConfig, error) {
+	raw, err := svcCtx.Redis.HGet(ctx, constants.RedisKeySymbolConfig, symbol).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbol config: %w", err)
+	}
+
+	var cfg models.SymbolConfig
+	if err := json.Unmarshal([]byte(raw), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid symbol config for %s: %w", symbol, err)
+	}
+	return &cfg, nil
+}
+
+// setSymbolDeleted 设置交易对的软删除状态并通知各服务，状态未变化时不通知
+// 软删除只修改注册表中的状态，不清除缓存和历史数据，恢复后立即重新可见
+func setSymbolDeleted(ctx context.Context, svcCtx *svc.ServiceContext, symbol string, deleted bool) (*models.SymbolConfig, bool, error) {
+	cfg, err := loadSymbolConfig(ctx, svcCtx, symbol)
+	if err != nil {
+		return nil, false, err
+	}
+	if cfg == nil {
+		return nil, false, errcode.Newf(errcode.ErrNotFound, "symbol config not found: %s", symbol)
+	}
+	if cfg.Deleted == deleted {
+		return cfg, false, nil
+	}
+
+	cfg.Deleted = deleted
+	cfg.DeletedAt = 0
+	event := constants.SymbolConfigRestored
+	if deleted {
+		cfg.DeletedAt = utils.GetCurrentTimestamp()
+		event = constants.SymbolConfigDeleted
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := svcCtx.Redis.HSet(ctx, constants.RedisKeySymbolConfig, symbol, data).Err(); err != nil {
+		return nil, false, fmt.Errorf("failed to save symbol config: %w", err)
+	}
+	if err := notifySymbolConfig(ctx, svcCtx, symbol); err != nil {
+		return nil, false, err
+	}
+	// 状态已生效，推送失败只记录日志
+	if err := publishSymbolConfigEvent(ctx, svcCtx, event, symbol, cfg); err != nil {
+		logx.WithContext(ctx).Errorf("[Admin] %v", err)
+	}
+	return cfg, true, nil
+}
+
+// notifySymbolConfig 通知 Collector 重新加载交易对配置
+func notifySymbolConfig(ctx context.Context, svcCtx *svc.ServiceContext, symbol string) error {
+	if err := svcCtx.Redis.Publish(ctx, constants.RedisChannelSymbolConfig, symbol).Err(); err != nil {
+		return fmt.Errorf("failed to notify symbol config change: %w", err)
+	}
+	return nil
+}
+
+// publishSymbolConfigEvent 推送交易对配置变更事件到 WebSocket config 频道（经 Redis 广播到所有 API 实例）
+func publishSymbolConfigEvent(ctx context.Context, svcCtx *svc.ServiceContext, event, symbol string, cfg *models.SymbolConfig) error {
+	data, err := json.Marshal(&models.SymbolConfigEvent{
+		Event:     event,
+		Symbol:    symbol,
+		Config:    cfg,
+		Timestamp: utils.GetCurrentTimestamp(),
+	})
+	if err != nil {
+		return err
+	}
+
+	channel := constants.RedisChannelMarket + constants.DataTypeConfig
+	if err := svcCtx.Redis.Publish(ctx, channel, data).Err(); err != nil {
+		return fmt.Errorf("failed to publish symbol config event: %w", err)
+	}
+	return nil
+}
+
+// symbolConfigEvent 根据变更前后的配置确定事件类型
+func symbolConfigEvent(previous, current *models.SymbolConfig) string {
+	switch {
+	case previous == nil:
+		return constants.SymbolConfigAdded
+	case previous.Enable && !current.Enable:
+		return constants.SymbolConfigDisabled
+	case !previous.Enable && current.Enable:
+		return constants.SymbolConfigEnabled
+	default:
+		return constants.SymbolConfigUpdated
+	}
+}
+
+// toSymbolConfigResponse 转换为响应结构
+func toSymbolConfigResponse(cfg *models.SymbolConfig) types.SymbolConfigResponse {
+	return types.SymbolConfigResponse{
+		Symbol:         cfg.Symbol,
+		Mode:           cfg.Mode,
+		PrimarySource:  cfg.PrimarySource,
+		ExternalSource: cfg.ExternalSource,
+		MergeStrategy:  cfg.MergeStrategy,
+		Enable:         cfg.Enable,
+		Description:    cfg.Description,
+		TickSize:       cfg.TickSize,
+		Vwap: types.SymbolVWAPConfig{
+			InternalWeight:    cfg.VWAP.InternalWeight,
+			ExternalWeight:    cfg.VWAP.ExternalWeight,
+			MinInternalVolume: cfg.VWAP.MinInternalVolume,
+			MinExternalVolume: cfg.VWAP.MinExternalVolume,
+		},
+		FreshnessMs:    cfg.FreshnessMs,
+		MaxDepthLevels: cfg.MaxDepthLevels,
+		TrimPercent:    cfg.TrimPercent,
+		Deleted:        cfg.Deleted,
+		DeletedAt:      cfg.DeletedAt,
+	}
+}
@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+	"market-system/common/utils"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 交易对分组存储在 Redis Hash symbol_group 中，变更后通过 symbol_group:update 频道通知各 API 实例，
+// API 据此将分组内交易对的行情推送到分组频道（如 ticker:group:majors），并按分组过滤 REST 接口。
+
+// maxGroupSymbols 单个分组的最大交易对数
+const maxGroupSymbols = 500
+
+// normalizeGroupSymbols 校验分组内的交易对并去重，保持原有顺序
+func normalizeGroupSymbols(symbols []string) ([]string, error) {
+	if len(symbols) > maxGroupSymbols {
+		return nil, errcode.Newf(errcode.ErrInvalidParam, "too many symbols: %d (max %d)", len(symbols), maxGroupSymbols)
+	}
+
+	result := make([]string, 0, len(symbols))
+	seen := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		if err := utils.ValidateSymbol(symbol); err != nil {
+			return nil, err
+		}
+		if !seen[symbol] {
+			seen[symbol] = true
+			result = append(result, symbol)
+		}
+	}
+	return result, nil
+}
+
+// loadSymbolGroup 从 Redis 读取交易对分组，不存在时返回 nil
+func loadSymbolGroup(ctx context.Context, svcCtx *svc.ServiceContext, name string) (*models.SymbolGroup, error) {
+	raw, err := svcCtx.Redis.HGet(ctx, constants.RedisKeySymbolGroup, name).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbol group: %w", err)
+	}
+
+	var group models.SymbolGroup
+	if err := json.Unmarshal([]byte(raw), &group); err != nil {
+		return nil, fmt.Errorf("invalid symbol group %s: %w", name, err)
+	}
+	return &group, nil
+}
+
+// notifySymbolGroup 通知各 API 实例重新加载交易对分组
+func notifySymbolGroup(ctx context.Context, svcCtx *svc.ServiceContext, name string) error {
+	if err := svcCtx.Redis.Publish(ctx, constants.RedisChannelSymbolGroup, name).Err(); err != nil {
+		return fmt.Errorf("failed to notify symbol group change: %w", err)
+	}
+	return nil
+}
+
+// toSymbolGroupResponse 转换为响应结构
+func toSymbolGroupResponse(group *models.SymbolGroup) types.SymbolGroupResponse {
+	symbols := group.Symbols
+	if symbols == nil {
+		symbols = []string{}
+	}
+	return types.SymbolGroupResponse{
+		Name:        group.Name,
+		Description: group.Description,
+		Symbols:     symbols,
+	}
+}
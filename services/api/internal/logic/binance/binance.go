@@ -0,0 +1,147 @@
+package binance
+
+import (
+	"errors"
+	"market-system/common/errcode"
+	"market-system/common/models"
+	"market-system/services/api/internal/registry"
+	"net/http"
+	"strconv"
+)
+
+// Binance 现货 REST 接口（/api/v3）的兼容层：按 Binance 的路径、参数和响应格式返回本系统的行情，
+// 只支持 Binance 图表和行情工具常用的K线、24 小时 Ticker 和深度接口。
+// 响应中的价格和数量为字符串，K线为数组，与 Binance 一致；这些接口不在 market.api 中定义，
+// 开启 BinanceCompat 后才注册路由
+
+// Binance 错误码
+const (
+	codeUnknown        = -1000 // 服务内部错误
+	codeMandatoryParam = -1102 // 缺少必填参数或参数格式错误
+	codeInvalidParam   = -1100 // 参数值不合法
+	codeBadInterval    = -1120 // 不支持的K线周期
+	codeBadSymbol      = -1121 // 交易对不存在
+)
+
+// Error Binance 格式的错误响应: {"code": -1121, "msg": "Invalid symbol."}
+type Error struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+// errInvalidSymbol 交易对不存在或已软删除
+var errInvalidSymbol = &Error{Code: codeBadSymbol, Msg: "Invalid symbol."}
+
+// ParamError 请求参数解析失败
+func ParamError(err error) error {
+	return &Error{Code: codeMandatoryParam, Msg: err.Error()}
+}
+
+// ErrorResponse 转换为 HTTP 状态码和 Binance 格式的错误，请求错误为 400，其他为 500
+func ErrorResponse(err error) (int, *Error) {
+	var e *Error
+	if errors.As(err, &e) {
+		return http.StatusBadRequest, e
+	}
+	if errors.Is(err, registry.ErrSymbolNotFound) {
+		return http.StatusBadRequest, errInvalidSymbol
+	}
+	if errors.Is(err, errcode.ErrInvalidParam) {
+		return http.StatusBadRequest, &Error{Code: codeInvalidParam, Msg: err.Error()}
+	}
+	return http.StatusInternalServerError, &Error{Code: codeUnknown, Msg: err.Error()}
+}
+
+// formatFloat 价格和数量按最短的十进制字符串返回
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// klineRow 转换为 Binance K线数组：
+// [开盘时间, 开盘价, 最高价, 最低价, 收盘价, 成交量, 收盘时间, 成交额, 成交笔数, 主动买入成交量, 主动买入成交额, 忽略]
+// 未统计主动买入成交量，固定为 "0"
+func klineRow(k *models.Kline) []interface{} {
+	return []interface{}{
+		k.OpenTime,
+		formatFloat(k.Open),
+		formatFloat(k.High),
+		formatFloat(k.Low),
+		formatFloat(k.Close),
+		formatFloat(k.Volume),
+		k.CloseTime,
+		formatFloat(k.QuoteVol),
+		k.TradeNum,
+		"0",
+		"0",
+		"0",
+	}
+}
+
+// depthLevels 转换为 Binance 深度档位 [["价格", "数量"], ...]，最多 limit 档
+func depthLevels(levels []models.PriceLevel, limit int) [][2]string {
+	if limit > len(levels) {
+		limit = len(levels)
+	}
+	result := make([][2]string, limit)
+	for i := 0; i < limit; i++ {
+		result[i] = [2]string{formatFloat(levels[i].Price), formatFloat(levels[i].Amount)}
+	}
+	return result
+}
+
+// KlinesRequest GET /api/v3/klines
+type KlinesRequest struct {
+	Symbol    string `form:"symbol"`
+	Interval  string `form:"interval"`
+	StartTime int64  `form:"startTime,optional"`
+	EndTime   int64  `form:"endTime,optional"`
+	Limit     int64  `form:"limit,default=500"`
+}
+
+// Ticker24hrRequest GET /api/v3/ticker/24hr，symbol 和 symbols 都不指定时返回全部交易对
+type Ticker24hrRequest struct {
+	Symbol  string `form:"symbol,optional"`
+	Symbols string `form:"symbols,optional"` // JSON 数组，如 ["BTCUSDT","ETHUSDT"]
+}
+
+// Ticker24hr Binance 24 小时 Ticker
+type Ticker24hr struct {
+	Symbol             string `json:"symbol"`
+	PriceChange        string `json:"priceChange"`
+	PriceChangePercent string `json:"priceChangePercent"`
+	WeightedAvgPrice   string `json:"weightedAvgPrice"`
+	PrevClosePrice     string `json:"prevClosePrice"`
+	LastPrice          string `json:"lastPrice"`
+	LastQty            string `json:"lastQty"`
+	BidPrice           string `json:"bidPrice"`
+	BidQty             string `json:"bidQty"`
+	AskPrice           string `json:"askPrice"`
+	AskQty             string `json:"askQty"`
+	OpenPrice          string `json:"openPrice"`
+	HighPrice          string `json:"highPrice"`
+	LowPrice           string `json:"lowPrice"`
+	Volume             string `json:"volume"`
+	QuoteVolume        string `json:"quoteVolume"`
+	OpenTime           int64  `json:"openTime"`
+	CloseTime          int64  `json:"closeTime"`
+	FirstID            int64  `json:"firstId"`
+	LastID             int64  `json:"lastId"`
+	Count              int64  `json:"count"`
+}
+
+// DepthRequest GET /api/v3/depth
+type DepthRequest struct {
+	Symbol string `form:"symbol"`
+	Limit  int    `form:"limit,default=100"`
+}
+
+// DepthResponse Binance 深度，没有更新序号，lastUpdateId 为深度的更新时间（毫秒）
+type DepthResponse struct {
+	LastUpdateID int64       `json:"lastUpdateId"`
+	Bids         [][2]string `json:"bids"`
+	Asks         [][2]string `json:"asks"`
+}
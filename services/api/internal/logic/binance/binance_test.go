@@ -0,0 +1,75 @@
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"market-system/common/models"
+	"market-system/services/api/internal/registry"
+	"net/http"
+	"testing"
+)
+
+func TestKlineRow(t *testing.T) {
+	row := klineRow(&models.Kline{
+		OpenTime: 1700000000000, CloseTime: 1700000059999,
+		Open: 100, High: 101.5, Low: 99.25, Close: 100.1,
+		Volume: 12.5, QuoteVol: 1251.25, TradeNum: 42,
+	})
+	data, err := json.Marshal(row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[1700000000000,"100","101.5","99.25","100.1","12.5",1700000059999,"1251.25",42,"0","0","0"]`
+	if string(data) != want {
+		t.Errorf("row = %s, want %s", data, want)
+	}
+}
+
+func TestDepthLevels(t *testing.T) {
+	levels := []models.PriceLevel{{Price: 100, Amount: 1.5}, {Price: 99.9, Amount: 2}}
+	if got := depthLevels(levels, 1); len(got) != 1 || got[0] != [2]string{"100", "1.5"} {
+		t.Errorf("levels = %v", got)
+	}
+	if got := depthLevels(levels, 10); len(got) != 2 {
+		t.Errorf("levels = %v", got)
+	}
+}
+
+func TestToTicker24hr(t *testing.T) {
+	ticker := &models.Ticker{Symbol: "BTCUSDT", LastPrice: 105, BidPrice: 104, AskPrice: 106,
+		Open24h: 100, Volume24h: 10, PriceChange24h: 5, PriceChangePercent24h: 5, Timestamp: 1700086400000}
+
+	resp := toTicker24hr(ticker, nil, nil)
+	if resp.BidQty != "0" || resp.QuoteVolume != "0" || resp.OpenTime != 1700000000000 {
+		t.Errorf("without book and stats: %+v", resp)
+	}
+
+	resp = toTicker24hr(ticker,
+		&models.BookTicker{BidPrice: 104.5, BidQty: 2, AskPrice: 105.5, AskQty: 3},
+		&models.MarketStats{Volume: 10, QuoteVolume: 1025})
+	if resp.BidPrice != "104.5" || resp.BidQty != "2" || resp.AskQty != "3" {
+		t.Errorf("book = %+v", resp)
+	}
+	if resp.QuoteVolume != "1025" || resp.WeightedAvgPrice != "102.5" {
+		t.Errorf("stats = %+v", resp)
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   int
+	}{
+		{&Error{Code: codeBadInterval, Msg: "Invalid interval."}, http.StatusBadRequest, codeBadInterval},
+		{fmt.Errorf("%w: BTCUSDT", registry.ErrSymbolNotFound), http.StatusBadRequest, codeBadSymbol},
+		{errors.New("redis down"), http.StatusInternalServerError, codeUnknown},
+	}
+	for _, tc := range tests {
+		status, body := ErrorResponse(tc.err)
+		if status != tc.status || body.Code != tc.code {
+			t.Errorf("%v: got %d %+v", tc.err, status, body)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"strings"
+
+	"market-system/services/api/internal/svc"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// maxDepthLimit 单次最多返回的档位数量，与 Binance 相同
+const maxDepthLimit = 5000
+
+type GetDepthLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetDepthLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetDepthLogic {
+	return &GetDepthLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetDepth 获取深度，买卖盘各返回最多 limit 档
+func (l *GetDepthLogic) GetDepth(req *DepthRequest) (*DepthResponse, error) {
+	symbol := strings.ToUpper(req.Symbol)
+	if err := l.svcCtx.Symbols.Check(symbol); err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > maxDepthLimit {
+		limit = maxDepthLimit
+	}
+
+	data, err := l.svcCtx.Redis.Get(l.ctx, constants.RedisKeyDepth+symbol).Bytes()
+	if err == redis.Nil {
+		return nil, errInvalidSymbol
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get depth: %w", err)
+	}
+
+	var depth models.OrderBook
+	if err := codec.Unmarshal(data, &depth); err != nil {
+		return nil, fmt.Errorf("failed to parse depth data: %w", err)
+	}
+	l.svcCtx.Sanitizer.OrderBook("redis", &depth)
+
+	return &DepthResponse{
+		LastUpdateID: depth.Timestamp,
+		Bids:         depthLevels(depth.Bids, limit),
+		Asks:         depthLevels(depth.Asks, limit),
+	}, nil
+}
@@ -0,0 +1,92 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"strconv"
+	"strings"
+
+	"market-system/services/api/internal/svc"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// maxKlinesLimit 单次最多返回的K线数量，与 Binance 相同
+const maxKlinesLimit = 1000
+
+type GetKlinesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetKlinesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetKlinesLogic {
+	return &GetKlinesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetKlines 按开盘时间正序返回K线数组
+// 指定 startTime 时返回从 startTime 开始的 limit 根，否则返回 endTime（不指定时为当前）之前最近的 limit 根
+func (l *GetKlinesLogic) GetKlines(req *KlinesRequest) ([][]interface{}, error) {
+	symbol := strings.ToUpper(req.Symbol)
+	if err := l.svcCtx.Symbols.Check(symbol); err != nil {
+		return nil, err
+	}
+	if !utils.ValidateInterval(req.Interval) {
+		return nil, &Error{Code: codeBadInterval, Msg: "Invalid interval."}
+	}
+	if req.StartTime > 0 && req.EndTime > 0 && req.StartTime > req.EndTime {
+		return nil, &Error{Code: codeInvalidParam, Msg: "startTime must not be later than endTime."}
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > maxKlinesLimit {
+		limit = maxKlinesLimit
+	}
+
+	key := fmt.Sprintf("%s%s:%s", constants.RedisKeyKline, symbol, req.Interval)
+	opt := &redis.ZRangeBy{Min: "-inf", Max: "+inf", Count: limit}
+	if req.StartTime > 0 {
+		opt.Min = strconv.FormatInt(req.StartTime, 10)
+	}
+	if req.EndTime > 0 {
+		opt.Max = strconv.FormatInt(req.EndTime, 10)
+	}
+
+	var results []string
+	var err error
+	if req.StartTime > 0 {
+		results, err = l.svcCtx.Redis.ZRangeByScore(l.ctx, key, opt).Result()
+	} else {
+		results, err = l.svcCtx.Redis.ZRevRangeByScore(l.ctx, key, opt).Result()
+		// 倒序读取最近的 limit 根，转为正序
+		for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+			results[i], results[j] = results[j], results[i]
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get klines: %w", err)
+	}
+
+	rows := make([][]interface{}, 0, len(results))
+	for _, data := range results {
+		var kline models.Kline
+		if err := codec.Unmarshal([]byte(data), &kline); err != nil {
+			continue
+		}
+		if !l.svcCtx.Sanitizer.Kline(symbol, &kline) {
+			continue
+		}
+		rows = append(rows, klineRow(&kline))
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,224 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"sort"
+	"strings"
+
+	"market-system/services/api/internal/svc"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// maxTicker24hrSymbols symbols 参数最多列出的交易对数量
+const maxTicker24hrSymbols = 200
+
+type GetTicker24hrLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetTicker24hrLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetTicker24hrLogic {
+	return &GetTicker24hrLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetTicker24hr 获取 24 小时 Ticker，指定 symbol 时返回单个对象，否则返回数组（按交易对排序或按 symbols 的顺序）
+// 买卖量取自最优买卖价，成交额取自 24 小时滚动统计，没有对应数据时为 "0"
+func (l *GetTicker24hrLogic) GetTicker24hr(req *Ticker24hrRequest) (interface{}, error) {
+	if req.Symbol != "" && req.Symbols != "" {
+		return nil, &Error{Code: codeInvalidParam, Msg: "symbol and symbols cannot be sent together."}
+	}
+
+	if req.Symbol != "" {
+		symbol := strings.ToUpper(req.Symbol)
+		if err := l.svcCtx.Symbols.Check(symbol); err != nil {
+			return nil, err
+		}
+		tickers, err := l.tickers([]string{symbol})
+		if err != nil {
+			return nil, err
+		}
+		if len(tickers) == 0 {
+			return nil, errInvalidSymbol
+		}
+		return tickers[0], nil
+	}
+
+	var symbols []string
+	if req.Symbols != "" {
+		if err := json.Unmarshal([]byte(req.Symbols), &symbols); err != nil {
+			return nil, &Error{Code: codeInvalidParam, Msg: "Invalid symbols."}
+		}
+		if len(symbols) > maxTicker24hrSymbols {
+			return nil, &Error{Code: codeInvalidParam, Msg: fmt.Sprintf("Too many symbols (max %d).", maxTicker24hrSymbols)}
+		}
+		for i, symbol := range symbols {
+			symbols[i] = strings.ToUpper(symbol)
+		}
+	} else {
+		var err error
+		if symbols, err = l.scanTickerSymbols(); err != nil {
+			return nil, err
+		}
+	}
+
+	visible := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		if !l.svcCtx.Symbols.IsDeleted(symbol) {
+			visible = append(visible, symbol)
+		}
+	}
+	return l.tickers(visible)
+}
+
+// tickers 在同一个管道中读取 Ticker、最优买卖价和 24 小时统计，没有 Ticker 数据的交易对不返回
+func (l *GetTicker24hrLogic) tickers(symbols []string) ([]*Ticker24hr, error) {
+	result := make([]*Ticker24hr, 0, len(symbols))
+	if len(symbols) == 0 {
+		return result, nil
+	}
+
+	pipe := l.svcCtx.Redis.Pipeline()
+	tickerCmds := make([]*redis.MapStringStringCmd, len(symbols))
+	bookCmds := make([]*redis.StringCmd, len(symbols))
+	statsCmds := make([]*redis.StringCmd, len(symbols))
+	for i, symbol := range symbols {
+		tickerCmds[i] = pipe.HGetAll(l.ctx, constants.RedisKeyTicker+symbol)
+		bookCmds[i] = pipe.Get(l.ctx, constants.RedisKeyBookTicker+symbol)
+		statsCmds[i] = pipe.Get(l.ctx, constants.RedisKeyMarketStats+symbol)
+	}
+	// 最优买卖价和统计不存在时返回 redis.Nil，逐条检查其他错误
+	pipe.Exec(l.ctx)
+
+	for i, symbol := range symbols {
+		data, err := tickerCmds[i].Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ticker: %w", err)
+		}
+		if len(data) == 0 {
+			continue
+		}
+		ticker := parseTicker(symbol, data)
+		// 清洗 NaN/Inf，避免序列化失败
+		if !l.svcCtx.Sanitizer.Ticker("redis", ticker) {
+			continue
+		}
+
+		var book *models.BookTicker
+		if raw, err := bookCmds[i].Bytes(); err == nil {
+			book = &models.BookTicker{}
+			if codec.Unmarshal(raw, book) != nil {
+				book = nil
+			}
+		} else if err != redis.Nil {
+			return nil, fmt.Errorf("failed to get book ticker: %w", err)
+		}
+
+		var stats *models.MarketStats
+		if raw, err := statsCmds[i].Bytes(); err == nil {
+			stats = &models.MarketStats{}
+			if codec.Unmarshal(raw, stats) != nil {
+				stats = nil
+			}
+		} else if err != redis.Nil {
+			return nil, fmt.Errorf("failed to get 24h stats: %w", err)
+		}
+
+		result = append(result, toTicker24hr(ticker, book, stats))
+	}
+	return result, nil
+}
+
+// scanTickerSymbols 查找全部有 Ticker 数据的交易对，按交易对排序
+func (l *GetTicker24hrLogic) scanTickerSymbols() ([]string, error) {
+	var symbols []string
+	iter := l.svcCtx.Redis.Scan(l.ctx, 0, constants.RedisKeyTicker+"*", 500).Iterator()
+	for iter.Next(l.ctx) {
+		symbols = append(symbols, strings.TrimPrefix(iter.Val(), constants.RedisKeyTicker))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan tickers: %w", err)
+	}
+	sort.Strings(symbols)
+	return symbols, nil
+}
+
+// toTicker24hr 转换为 Binance 24 小时 Ticker，book 和 stats 可以为 nil
+// 没有成交编号，firstId 和 lastId 固定为 -1；统计窗口为截至 Ticker 更新时间的 24 小时
+func toTicker24hr(t *models.Ticker, book *models.BookTicker, stats *models.MarketStats) *Ticker24hr {
+	resp := &Ticker24hr{
+		Symbol:             t.Symbol,
+		PriceChange:        formatFloat(t.PriceChange24h),
+		PriceChangePercent: formatFloat(t.PriceChangePercent24h),
+		WeightedAvgPrice:   "0",
+		PrevClosePrice:     formatFloat(t.Open24h),
+		LastPrice:          formatFloat(t.LastPrice),
+		LastQty:            "0",
+		BidPrice:           formatFloat(t.BidPrice),
+		BidQty:             "0",
+		AskPrice:           formatFloat(t.AskPrice),
+		AskQty:             "0",
+		OpenPrice:          formatFloat(t.Open24h),
+		HighPrice:          formatFloat(t.High24h),
+		LowPrice:           formatFloat(t.Low24h),
+		Volume:             formatFloat(t.Volume24h),
+		QuoteVolume:        "0",
+		OpenTime:           t.Timestamp - 24*60*60*1000,
+		CloseTime:          t.Timestamp,
+		FirstID:            -1,
+		LastID:             -1,
+		Count:              t.TradeCount24h,
+	}
+	if book != nil {
+		resp.BidPrice, resp.BidQty = formatFloat(book.BidPrice), formatFloat(book.BidQty)
+		resp.AskPrice, resp.AskQty = formatFloat(book.AskPrice), formatFloat(book.AskQty)
+	}
+	if stats != nil {
+		resp.QuoteVolume = formatFloat(stats.QuoteVolume)
+		if stats.Volume > 0 {
+			resp.WeightedAvgPrice = formatFloat(stats.QuoteVolume / stats.Volume)
+		}
+	}
+	return resp
+}
+
+// parseTicker 从 ticker Hash 解析 Ticker
+func parseTicker(symbol string, data map[string]string) *models.Ticker {
+	ticker := &models.Ticker{Symbol: symbol}
+
+	floats := map[string]*float64{
+		"last_price":               &ticker.LastPrice,
+		"bid_price":                &ticker.BidPrice,
+		"ask_price":                &ticker.AskPrice,
+		"high_24h":                 &ticker.High24h,
+		"low_24h":                  &ticker.Low24h,
+		"volume_24h":               &ticker.Volume24h,
+		"open_24h":                 &ticker.Open24h,
+		"price_change_24h":         &ticker.PriceChange24h,
+		"price_change_percent_24h": &ticker.PriceChangePercent24h,
+	}
+	for field, dst := range floats {
+		if val, ok := data[field]; ok {
+			fmt.Sscanf(val, "%f", dst)
+		}
+	}
+	if val, ok := data["trade_count_24h"]; ok {
+		fmt.Sscanf(val, "%d", &ticker.TradeCount24h)
+	}
+	if val, ok := data["timestamp"]; ok {
+		fmt.Sscanf(val, "%d", &ticker.Timestamp)
+	}
+
+	return ticker
+}
@@ -0,0 +1,60 @@
+package market
+
+import (
+	"context"
+	"strconv"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+)
+
+// tickerConverter 为 Ticker 响应附加换算后的最新价和成交额
+// 参考交易对的最新价经 Ticker 读缓存读取，同一请求中只读取一次
+type tickerConverter struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	prices map[string]float64 // 已读取的最新价，没有行情时为 0
+}
+
+// newTickerConverter 未开启换算时返回 nil
+func newTickerConverter(ctx context.Context, svcCtx *svc.ServiceContext) *tickerConverter {
+	if svcCtx.Converter == nil {
+		return nil
+	}
+	return &tickerConverter{ctx: ctx, svcCtx: svcCtx, prices: make(map[string]float64)}
+}
+
+// apply 换算 resp，无法识别计价货币或参考交易对没有行情时不附加
+func (c *tickerConverter) apply(resp *types.TickerResponse) {
+	if c == nil {
+		return
+	}
+	converter := c.svcCtx.Converter
+	quote, ok := converter.QuoteAsset(resp.Symbol)
+	if !ok {
+		return
+	}
+	c.prices[resp.Symbol] = resp.LastPrice
+	rate, ok := converter.Rate(quote, c.price)
+	if !ok {
+		return
+	}
+
+	resp.ConvertCurrency = converter.Fiat()
+	resp.ConvertedLastPrice = resp.LastPrice * rate
+	resp.ConvertedVolume24h = resp.Volume24h * resp.ConvertedLastPrice
+}
+
+func (c *tickerConverter) price(symbol string) (float64, bool) {
+	if p, ok := c.prices[symbol]; ok {
+		return p, p > 0
+	}
+
+	var p float64
+	data, err := getTickerHash(c.ctx, c.svcCtx, symbol)
+	if err == nil && !c.svcCtx.Symbols.IsDeleted(symbol) {
+		p, _ = strconv.ParseFloat(data["last_price"], 64)
+	}
+	c.prices[symbol] = p
+	return p, p > 0
+}
@@ -0,0 +1,58 @@
+package market
+
+import (
+	"context"
+	"market-system/common/errcode"
+	"market-system/common/models"
+	"strings"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreatePriceAlertLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreatePriceAlertLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreatePriceAlertLogic {
+	return &CreatePriceAlertLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// CreatePriceAlert 创建价格提醒，Processor 收到通知后开始判断，触发时 POST 到 webhook
+// REST 创建的提醒没有接收推送的连接，必须指定 webhook
+func (l *CreatePriceAlertLogic) CreatePriceAlert(req *types.CreatePriceAlertRequest) (resp *types.PriceAlert, err error) {
+	owner, err := alertOwner(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Webhook == "" {
+		return nil, errcode.Newf(errcode.ErrInvalidParam, "webhook is required")
+	}
+
+	alert := &models.PriceAlert{
+		Owner:     owner,
+		Symbol:    strings.ToUpper(req.Symbol),
+		Condition: req.Condition,
+		Value:     req.Value,
+		Webhook:   req.Webhook,
+		Repeat:    req.Repeat,
+	}
+	if err := l.svcCtx.Symbols.Check(alert.Symbol); err != nil {
+		return nil, err
+	}
+	if err := l.svcCtx.PriceAlerts.Create(l.ctx, alert); err != nil {
+		return nil, err
+	}
+
+	l.Infof("[PriceAlert] Created alert %s: %s %s %v", alert.ID, alert.Symbol, alert.Condition, alert.Value)
+	result := toPriceAlert(alert)
+	return &result, nil
+}
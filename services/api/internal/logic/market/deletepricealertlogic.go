@@ -0,0 +1,43 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"market-system/common/errcode"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeletePriceAlertLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeletePriceAlertLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeletePriceAlertLogic {
+	return &DeletePriceAlertLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeletePriceAlert 删除当前认证身份创建的价格提醒，不存在或已触发删除时 deleted 为 false
+func (l *DeletePriceAlertLogic) DeletePriceAlert(req *types.DeletePriceAlertRequest) (resp *types.DeletePriceAlertResponse, err error) {
+	owner, err := alertOwner(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = l.svcCtx.PriceAlerts.Delete(l.ctx, owner, req.Id)
+	if err != nil && !errors.Is(err, errcode.ErrNotFound) {
+		return nil, err
+	}
+
+	return &types.DeletePriceAlertResponse{
+		Id:      req.Id,
+		Deleted: err == nil,
+	}, nil
+}
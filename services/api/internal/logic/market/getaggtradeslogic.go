@@ -0,0 +1,75 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/models"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// aggTradeHistorySize Processor 为每个交易对保留的最近聚合成交条数
+const aggTradeHistorySize = 100
+
+type GetAggTradesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetAggTradesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetAggTradesLogic {
+	return &GetAggTradesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetAggTrades 获取交易对最近的聚合成交（按时间倒序）
+func (l *GetAggTradesLogic) GetAggTrades(req *types.AggTradesRequest) (resp *types.AggTradesResponse, err error) {
+	if err := l.svcCtx.Symbols.Check(req.Symbol); err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > aggTradeHistorySize {
+		limit = aggTradeHistorySize
+	}
+
+	key := constants.RedisKeyAggTrade + req.Symbol
+	items, err := l.svcCtx.Redis.LRange(l.ctx, key, 0, limit-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agg trades: %w", err)
+	}
+
+	resp = &types.AggTradesResponse{
+		Symbol:    req.Symbol,
+		AggTrades: make([]types.AggTrade, 0, len(items)),
+	}
+	for _, item := range items {
+		var agg models.AggTrade
+		if err := codec.Unmarshal([]byte(item), &agg); err != nil {
+			continue
+		}
+		resp.AggTrades = append(resp.AggTrades, types.AggTrade{
+			AggID:        agg.AggID,
+			Price:        agg.Price,
+			Amount:       agg.Amount,
+			Side:         agg.Side,
+			FirstTradeID: agg.FirstTradeID,
+			LastTradeID:  agg.LastTradeID,
+			Count:        agg.Count,
+			FirstTime:    agg.FirstTime,
+			Timestamp:    agg.Timestamp,
+			Source:       agg.Source,
+			Exchange:     agg.Exchange,
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,90 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// maxBookStatsLimit 订单簿统计每次最多返回的历史条数
+const maxBookStatsLimit = 1000
+
+type GetBookStatsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetBookStatsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetBookStatsLogic {
+	return &GetBookStatsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetBookStats 获取交易对最近的订单簿统计（按时间倒序，第一条为最新）
+func (l *GetBookStatsLogic) GetBookStats(req *types.BookStatsRequest) (resp *types.BookStatsResponse, err error) {
+	if err := l.svcCtx.Symbols.Check(req.Symbol); err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 1
+	}
+	if limit > maxBookStatsLimit {
+		limit = maxBookStatsLimit
+	}
+
+	key := constants.RedisKeyBookStats + req.Symbol
+	items, err := l.svcCtx.Redis.LRange(l.ctx, key, 0, limit-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book stats: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, errcode.Newf(errcode.ErrNotFound, "book stats not found for symbol: %s", req.Symbol)
+	}
+
+	resp = &types.BookStatsResponse{
+		Symbol: req.Symbol,
+		Stats:  make([]types.BookStats, 0, len(items)),
+	}
+	for _, item := range items {
+		var stats models.BookStats
+		if err := codec.Unmarshal([]byte(item), &stats); err != nil {
+			continue
+		}
+
+		liquidity := make([]types.LiquidityBand, 0, len(stats.Liquidity))
+		for _, band := range stats.Liquidity {
+			liquidity = append(liquidity, types.LiquidityBand{
+				Percent:     band.Percent,
+				BidAmount:   band.BidAmount,
+				AskAmount:   band.AskAmount,
+				BidNotional: band.BidNotional,
+				AskNotional: band.AskNotional,
+			})
+		}
+		resp.Stats = append(resp.Stats, types.BookStats{
+			BidPrice:  stats.BidPrice,
+			AskPrice:  stats.AskPrice,
+			MidPrice:  stats.MidPrice,
+			Spread:    stats.Spread,
+			SpreadBps: stats.SpreadBps,
+			Imbalance: stats.Imbalance,
+			Liquidity: liquidity,
+			Timestamp: stats.Timestamp,
+		})
+	}
+
+	return resp, nil
+}
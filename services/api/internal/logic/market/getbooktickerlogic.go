@@ -0,0 +1,61 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetBookTickerLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetBookTickerLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetBookTickerLogic {
+	return &GetBookTickerLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetBookTicker 获取最优买卖价（processor 在盘口第一档变化时写入），某一侧没有挂单时价格和数量为 0
+func (l *GetBookTickerLogic) GetBookTicker(req *types.TickerRequest) (resp *types.BookTickerResponse, err error) {
+	if err := l.svcCtx.Symbols.Check(req.Symbol); err != nil {
+		return nil, err
+	}
+
+	key := constants.RedisKeyBookTicker + req.Symbol
+
+	data, err := l.svcCtx.Redis.Get(l.ctx, key).Result()
+	if err == redis.Nil {
+		return nil, errcode.Newf(errcode.ErrNotFound, "book ticker not found for symbol: %s", req.Symbol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book ticker: %w", err)
+	}
+
+	var ticker models.BookTicker
+	if err := codec.Unmarshal([]byte(data), &ticker); err != nil {
+		return nil, fmt.Errorf("failed to parse book ticker: %w", err)
+	}
+
+	return &types.BookTickerResponse{
+		Symbol:    req.Symbol,
+		BidPrice:  ticker.BidPrice,
+		BidQty:    ticker.BidQty,
+		AskPrice:  ticker.AskPrice,
+		AskQty:    ticker.AskQty,
+		Timestamp: ticker.Timestamp,
+	}, nil
+}
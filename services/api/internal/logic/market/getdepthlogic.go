@@ -2,14 +2,16 @@ package market
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"market-system/common/codec"
 	"market-system/common/constants"
+	"market-system/common/errcode"
 	"market-system/common/models"
 
 	"market-system/services/api/internal/svc"
 	"market-system/services/api/internal/types"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
@@ -28,50 +30,36 @@ func NewGetDepthLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetDepth
 }
 
 func (l *GetDepthLogic) GetDepth(req *types.DepthRequest) (resp *types.DepthResponse, err error) {
-	// 从 Redis 获取深度数据
+	if err := l.svcCtx.Symbols.Check(req.Symbol); err != nil {
+		return nil, err
+	}
+
+	// 从 Redis 获取深度数据，指定精度时读取 processor 按该精度聚合的深度
 	key := constants.RedisKeyDepth + req.Symbol
+	if req.Precision != "" {
+		key += ":" + req.Precision
+	}
 
-	data, err := l.svcCtx.Redis.Get(l.ctx, key).Result()
+	data, err := getDepthData(l.ctx, l.svcCtx, key)
+	if err == redis.Nil && req.Precision != "" {
+		return nil, errcode.Newf(errcode.ErrNotFound, "depth precision %s is not available for %s", req.Precision, req.Symbol)
+	}
+	if err == redis.Nil {
+		return nil, errcode.Newf(errcode.ErrNotFound, "depth not found for symbol: %s", req.Symbol)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get depth: %w", err)
 	}
 
 	var depth models.OrderBook
-	if err := json.Unmarshal([]byte(data), &depth); err != nil {
+	if err := codec.Unmarshal([]byte(data), &depth); err != nil {
 		return nil, fmt.Errorf("failed to parse depth data: %w", err)
 	}
 
-	// 转换为响应格式
-	bids := make([]types.PriceLevel, 0)
-	asks := make([]types.PriceLevel, 0)
-
-	// 限制档位数量
-	limit := int(req.Limit)
-	if limit > len(depth.Bids) {
-		limit = len(depth.Bids)
-	}
-	for i := 0; i < limit; i++ {
-		bids = append(bids, types.PriceLevel{
-			Price:  depth.Bids[i].Price,
-			Amount: depth.Bids[i].Amount,
-		})
-	}
-
-	limit = int(req.Limit)
-	if limit > len(depth.Asks) {
-		limit = len(depth.Asks)
-	}
-	for i := 0; i < limit; i++ {
-		asks = append(asks, types.PriceLevel{
-			Price:  depth.Asks[i].Price,
-			Amount: depth.Asks[i].Amount,
-		})
-	}
-
 	resp = &types.DepthResponse{
 		Symbol:    req.Symbol,
-		Bids:      bids,
-		Asks:      asks,
+		Bids:      priceLevels(depth.Bids, int(req.Limit)),
+		Asks:      priceLevels(depth.Asks, int(req.Limit)),
 		Timestamp: depth.Timestamp,
 	}
 
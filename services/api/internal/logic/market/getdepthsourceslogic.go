@@ -0,0 +1,150 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetDepthSourcesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetDepthSourcesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetDepthSourcesLogic {
+	return &GetDepthSourcesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetDepthSources 获取按档位标注来源的深度，供交易界面区分内部流动性与外部流动性
+// 混合模式下读取 processor 保存的融合深度；其他交易对的深度只有一个来源，
+// 按交易对模式标注（INTERNAL_ONLY 为 internal，其他为 external）
+func (l *GetDepthSourcesLogic) GetDepthSources(req *types.DepthSourcesRequest) (resp *types.DepthSourcesResponse, err error) {
+	if err := l.svcCtx.Symbols.Check(req.Symbol); err != nil {
+		return nil, err
+	}
+
+	data, err := l.svcCtx.Redis.Get(l.ctx, constants.RedisKeyDepthSource+req.Symbol).Result()
+	if err == redis.Nil {
+		return l.singleSource(req)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get depth sources: %w", err)
+	}
+
+	var depth models.OrderBookWithSource
+	if err := codec.Unmarshal([]byte(data), &depth); err != nil {
+		return nil, fmt.Errorf("failed to parse depth sources: %w", err)
+	}
+
+	resp = &types.DepthSourcesResponse{
+		Symbol:            req.Symbol,
+		Bids:              sourcedLevels(depth.Bids, int(req.Limit)),
+		Asks:              sourcedLevels(depth.Asks, int(req.Limit)),
+		InternalBidsCount: depth.InternalBidsCount,
+		ExternalBidsCount: depth.ExternalBidsCount,
+		InternalAsksCount: depth.InternalAsksCount,
+		ExternalAsksCount: depth.ExternalAsksCount,
+		Timestamp:         depth.Timestamp,
+	}
+	return resp, nil
+}
+
+// singleSource 读取单一来源的深度，全部档位标注为交易对的数据来源
+func (l *GetDepthSourcesLogic) singleSource(req *types.DepthSourcesRequest) (*types.DepthSourcesResponse, error) {
+	data, err := getDepthData(l.ctx, l.svcCtx, constants.RedisKeyDepth+req.Symbol)
+	if err == redis.Nil {
+		return nil, errcode.Newf(errcode.ErrNotFound, "depth not found for symbol: %s", req.Symbol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get depth: %w", err)
+	}
+
+	var depth models.OrderBook
+	if err := codec.Unmarshal([]byte(data), &depth); err != nil {
+		return nil, fmt.Errorf("failed to parse depth data: %w", err)
+	}
+
+	source, err := l.symbolSource(req.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := func(levels []models.PriceLevel) []models.PriceLevelWithSource {
+		result := make([]models.PriceLevelWithSource, len(levels))
+		for i, level := range levels {
+			result[i] = models.PriceLevelWithSource{Price: level.Price, Amount: level.Amount, Source: source}
+			if source == constants.SourceInternal {
+				result[i].InternalAmount = level.Amount
+			} else {
+				result[i].ExternalAmount = level.Amount
+			}
+		}
+		return result
+	}
+
+	resp := &types.DepthSourcesResponse{
+		Symbol:    req.Symbol,
+		Bids:      sourcedLevels(tag(depth.Bids), int(req.Limit)),
+		Asks:      sourcedLevels(tag(depth.Asks), int(req.Limit)),
+		Timestamp: depth.Timestamp,
+	}
+	if source == constants.SourceInternal {
+		resp.InternalBidsCount, resp.InternalAsksCount = len(depth.Bids), len(depth.Asks)
+	} else {
+		resp.ExternalBidsCount, resp.ExternalAsksCount = len(depth.Bids), len(depth.Asks)
+	}
+	return resp, nil
+}
+
+// symbolSource 按交易对模式确定单一来源深度的来源，没有交易对配置时视为外部数据源
+func (l *GetDepthSourcesLogic) symbolSource(symbol string) (string, error) {
+	raw, err := l.svcCtx.Redis.HGet(l.ctx, constants.RedisKeySymbolConfig, symbol).Result()
+	if err == redis.Nil {
+		return constants.SourceExternal, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get symbol config: %w", err)
+	}
+
+	var cfg models.SymbolConfig
+	if err := json.Unmarshal([]byte(raw), &cfg); err != nil {
+		return "", fmt.Errorf("invalid symbol config for %s: %w", symbol, err)
+	}
+	if cfg.Mode == constants.ModeInternalOnly {
+		return constants.SourceInternal, nil
+	}
+	return constants.SourceExternal, nil
+}
+
+// sourcedLevels 截取前 limit 档并转换为响应结构
+func sourcedLevels(levels []models.PriceLevelWithSource, limit int) []types.SourcedPriceLevel {
+	if limit > len(levels) {
+		limit = len(levels)
+	}
+	result := make([]types.SourcedPriceLevel, 0, limit)
+	for i := 0; i < limit; i++ {
+		result = append(result, types.SourcedPriceLevel{
+			Price:          levels[i].Price,
+			Amount:         levels[i].Amount,
+			Source:         levels[i].Source,
+			InternalAmount: levels[i].InternalAmount,
+			ExternalAmount: levels[i].ExternalAmount,
+		})
+	}
+	return result
+}
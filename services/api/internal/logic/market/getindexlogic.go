@@ -0,0 +1,73 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetIndexLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetIndexLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetIndexLogic {
+	return &GetIndexLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetIndex 获取交易对的综合指数价格（由 processor 按各外部交易所最新价定期计算写入）
+func (l *GetIndexLogic) GetIndex(req *types.IndexRequest) (resp *types.IndexResponse, err error) {
+	if err := l.svcCtx.Symbols.Check(req.Symbol); err != nil {
+		return nil, err
+	}
+
+	key := constants.RedisKeyIndex + req.Symbol
+
+	data, err := l.svcCtx.Redis.Get(l.ctx, key).Result()
+	if err == redis.Nil {
+		return nil, errcode.Newf(errcode.ErrNotFound, "index price not found for symbol: %s", req.Symbol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index price: %w", err)
+	}
+
+	var price models.IndexPrice
+	if err := codec.Unmarshal([]byte(data), &price); err != nil {
+		return nil, fmt.Errorf("failed to parse index price data: %w", err)
+	}
+
+	components := make([]types.IndexComponent, 0, len(price.Components))
+	for _, comp := range price.Components {
+		components = append(components, types.IndexComponent{
+			Exchange:  comp.Exchange,
+			Price:     comp.Price,
+			Weight:    comp.Weight,
+			Excluded:  comp.Excluded,
+			Timestamp: comp.Timestamp,
+		})
+	}
+
+	resp = &types.IndexResponse{
+		Symbol:     req.Symbol,
+		Price:      price.Price,
+		Method:     price.Method,
+		Components: components,
+		Timestamp:  price.Timestamp,
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,76 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+	"market-system/common/utils"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetIndicatorsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetIndicatorsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetIndicatorsLogic {
+	return &GetIndicatorsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetIndicators 获取交易对某个周期的技术指标（由 processor 随K线更新计算写入）
+func (l *GetIndicatorsLogic) GetIndicators(req *types.IndicatorsRequest) (resp *types.IndicatorsResponse, err error) {
+	if err := l.svcCtx.Symbols.Check(req.Symbol); err != nil {
+		return nil, err
+	}
+	if !utils.ValidateInterval(req.Interval) {
+		return nil, errcode.Newf(errcode.ErrInvalidParam, "invalid interval: %s", req.Interval)
+	}
+
+	key := fmt.Sprintf("%s%s:%s", constants.RedisKeyIndicator, req.Symbol, req.Interval)
+
+	data, err := l.svcCtx.Redis.Get(l.ctx, key).Result()
+	if err == redis.Nil {
+		return nil, errcode.Newf(errcode.ErrNotFound, "indicators not found for symbol: %s, interval: %s", req.Symbol, req.Interval)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indicators: %w", err)
+	}
+
+	var ind models.Indicators
+	if err := codec.Unmarshal([]byte(data), &ind); err != nil {
+		return nil, fmt.Errorf("failed to parse indicators data: %w", err)
+	}
+
+	resp = &types.IndicatorsResponse{
+		Symbol:    req.Symbol,
+		Interval:  req.Interval,
+		OpenTime:  ind.OpenTime,
+		Closed:    ind.Closed,
+		Close:     ind.Close,
+		Sma:       ind.SMA,
+		Ema:       ind.EMA,
+		Rsi:       ind.RSI,
+		Timestamp: ind.Timestamp,
+	}
+	if ind.MACD != nil {
+		resp.Macd = &types.MACD{Dif: ind.MACD.DIF, Dea: ind.MACD.DEA, Histogram: ind.MACD.Histogram}
+	}
+	if ind.BOLL != nil {
+		resp.Boll = &types.BOLL{Upper: ind.BOLL.Upper, Middle: ind.BOLL.Middle, Lower: ind.BOLL.Lower}
+	}
+
+	return resp, nil
+}
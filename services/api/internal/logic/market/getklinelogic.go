@@ -2,17 +2,26 @@ package market
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"market-system/common/codec"
 	"market-system/common/constants"
+	"market-system/common/errcode"
 	"market-system/common/models"
+	"market-system/common/utils"
+	"strconv"
 
 	"market-system/services/api/internal/svc"
 	"market-system/services/api/internal/types"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
+const (
+	maxKlineLimit    = 1000  // 单次最多返回的K线数量
+	maxKlineSpanBars = 10000 // start_time 与 end_time 的最大跨度（按K线根数计）
+)
+
 type GetKlineLogic struct {
 	logx.Logger
 	ctx    context.Context
@@ -27,21 +36,86 @@ func NewGetKlineLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetKline
 	}
 }
 
+// GetKline 按开盘时间范围获取K线，默认返回区间内最近的 limit 根（按开盘时间倒序），
+// order=asc 时返回区间内最早的 limit 根（按开盘时间正序）。
+// 区间内还有更多K线时 has_more 为 true，下一页以 next_end_time（倒序）或 next_start_time（正序）继续查询，
+// 图表向左滚动时即可按页加载更早的历史。
+// type 为 heikin_ashi、renko 时返回 Processor 计算的平均K线、砖块（需在 kline 配置中开启）
 func (l *GetKlineLogic) GetKline(req *types.KlineRequest) (resp *types.KlineResponse, err error) {
-	// 从 Redis 获取 K线数据
-	key := fmt.Sprintf("%s%s:%s", constants.RedisKeyKline, req.Symbol, req.Interval)
+	if err := l.svcCtx.Symbols.Check(req.Symbol); err != nil {
+		return nil, err
+	}
+	if !utils.ValidateInterval(req.Interval) {
+		return nil, errcode.Newf(errcode.ErrInvalidParam, "invalid interval: %s", req.Interval)
+	}
+	dataType, ok := utils.KlineDataType(req.Type)
+	if !ok {
+		return nil, errcode.Newf(errcode.ErrInvalidParam, "invalid type: %s", req.Type)
+	}
+
+	if req.StartTime > 0 && req.EndTime > 0 {
+		if req.StartTime > req.EndTime {
+			return nil, errcode.Newf(errcode.ErrInvalidParam, "start_time must not be later than end_time")
+		}
+		// 一根K线的时长（月线按起始月份计算）
+		barMs := utils.GetKlineCloseTime(req.StartTime, req.Interval) + 1 - req.StartTime
+		if (req.EndTime-req.StartTime)/barMs > maxKlineSpanBars {
+			return nil, errcode.Newf(errcode.ErrInvalidParam, "time range too large: at most %d %s klines", maxKlineSpanBars, req.Interval)
+		}
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > maxKlineLimit {
+		limit = maxKlineLimit
+	}
+
+	// 从 Redis 获取 K线数据，有序集合以开盘时间为分数，多取一根判断区间内是否还有更多K线
+	key := fmt.Sprintf("%s:%s:%s", dataType, req.Symbol, req.Interval)
+	opt := &redis.ZRangeBy{Min: "-inf", Max: "+inf", Count: limit + 1}
+	if req.StartTime > 0 {
+		opt.Min = strconv.FormatInt(req.StartTime, 10)
+	}
+	if req.EndTime > 0 {
+		opt.Max = strconv.FormatInt(req.EndTime, 10)
+	}
 
-	// 获取数据
-	results, err := l.svcCtx.Redis.LRange(l.ctx, key, 0, req.Limit-1).Result()
+	var results []redis.Z
+	if req.Order == "asc" {
+		results, err = l.svcCtx.Redis.ZRangeByScoreWithScores(l.ctx, key, opt).Result()
+	} else {
+		results, err = l.svcCtx.Redis.ZRevRangeByScoreWithScores(l.ctx, key, opt).Result()
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get klines: %w", err)
 	}
 
+	resp = &types.KlineResponse{
+		Symbol:   req.Symbol,
+		Interval: req.Interval,
+		Type:     req.Type,
+	}
+	if resp.Type == "" {
+		resp.Type = constants.KlineTypeCandle
+	}
+	if int64(len(results)) > limit {
+		results = results[:limit]
+		resp.HasMore = true
+		// 下一页从本页最后一根K线之后开始（分数即开盘时间）
+		last := int64(results[len(results)-1].Score)
+		if req.Order == "asc" {
+			resp.NextStartTime = last + 1
+		} else {
+			resp.NextEndTime = last - 1
+		}
+	}
+
 	// 解析数据
+	now := utils.GetCurrentTimestamp()
 	klines := make([]types.Kline, 0, len(results))
-	for _, data := range results {
+	for _, z := range results {
+		data, _ := z.Member.(string)
 		var kline models.Kline
-		if err := json.Unmarshal([]byte(data), &kline); err != nil {
+		if err := codec.Unmarshal([]byte(data), &kline); err != nil {
 			continue
 		}
 
@@ -55,14 +129,13 @@ func (l *GetKlineLogic) GetKline(req *types.KlineRequest) (resp *types.KlineResp
 			Volume:    kline.Volume,
 			QuoteVol:  kline.QuoteVol,
 			TradeNum:  kline.TradeNum,
+			Revision:  kline.Revision,
+			// 升级前保存的K线没有 is_final，已过收盘时间的视为已收盘
+			IsFinal: kline.IsFinal || kline.CloseTime < now,
 		})
 	}
 
-	resp = &types.KlineResponse{
-		Symbol:   req.Symbol,
-		Interval: req.Interval,
-		Data:     klines,
-	}
+	resp.Data = klines
 
 	return resp, nil
 }
@@ -0,0 +1,128 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// maxScreenerLimit 行情排行每页最多返回的交易对数量
+const maxScreenerLimit = 200
+
+// screenerSorts 行情排行支持的排序字段
+var screenerSorts = map[string]bool{
+	constants.ScreenerSortChange:     true,
+	constants.ScreenerSortVolume:     true,
+	constants.ScreenerSortTradeCount: true,
+}
+
+type GetScreenerLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetScreenerLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetScreenerLogic {
+	return &GetScreenerLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetScreener 行情排行
+// processor 写入 Ticker 时同时更新各排序字段的有序集合，按排名分页读取后再取对应的 Ticker。
+// 已软删除的交易对不返回；Ticker 已过期的交易对从排行中移除，因此一页可能少于 limit 个
+func (l *GetScreenerLogic) GetScreener(req *types.ScreenerRequest) (resp *types.ScreenerResponse, err error) {
+	if !screenerSorts[req.Sort] {
+		return nil, errcode.Newf(errcode.ErrInvalidParam, "invalid sort: %s", req.Sort)
+	}
+	if req.Offset < 0 {
+		return nil, errcode.Newf(errcode.ErrInvalidParam, "offset must not be negative")
+	}
+	limit := req.Limit
+	if limit <= 0 || limit > maxScreenerLimit {
+		limit = maxScreenerLimit
+	}
+
+	key := constants.RedisKeyScreener + req.Sort
+	pipe := l.svcCtx.Redis.Pipeline()
+	total := pipe.ZCard(l.ctx, key)
+	var ranked *redis.StringSliceCmd
+	if req.Order == "asc" {
+		ranked = pipe.ZRange(l.ctx, key, req.Offset, req.Offset+limit-1)
+	} else {
+		ranked = pipe.ZRevRange(l.ctx, key, req.Offset, req.Offset+limit-1)
+	}
+	if _, err := pipe.Exec(l.ctx); err != nil {
+		return nil, fmt.Errorf("failed to get screener: %w", err)
+	}
+
+	symbols := make([]string, 0, len(ranked.Val()))
+	for _, symbol := range ranked.Val() {
+		if !l.svcCtx.Symbols.IsDeleted(symbol) {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	pipe = l.svcCtx.Redis.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(symbols))
+	for i, symbol := range symbols {
+		cmds[i] = pipe.HGetAll(l.ctx, constants.RedisKeyTicker+symbol)
+	}
+	if len(symbols) > 0 {
+		if _, err := pipe.Exec(l.ctx); err != nil {
+			return nil, fmt.Errorf("failed to get tickers: %w", err)
+		}
+	}
+
+	resp = &types.ScreenerResponse{
+		Sort:    req.Sort,
+		Order:   req.Order,
+		Total:   total.Val(),
+		Tickers: make([]types.TickerResponse, 0, len(symbols)),
+	}
+	var expired []interface{}
+	converter := newTickerConverter(l.ctx, l.svcCtx)
+	for i, symbol := range symbols {
+		data := cmds[i].Val()
+		if len(data) == 0 {
+			expired = append(expired, symbol)
+			continue
+		}
+
+		ticker := parseTickerHash(symbol, data)
+		if !l.svcCtx.Sanitizer.Ticker("redis", ticker) {
+			continue
+		}
+		result := toTickerResponse(ticker)
+		setTickerAge(l.svcCtx.Config.Staleness, &result)
+		converter.apply(&result)
+		resp.Tickers = append(resp.Tickers, result)
+	}
+
+	if len(expired) > 0 {
+		l.removeExpired(expired)
+		resp.Total -= int64(len(expired))
+	}
+
+	return resp, nil
+}
+
+// removeExpired 从全部排行中移除 Ticker 已过期的交易对
+func (l *GetScreenerLogic) removeExpired(symbols []interface{}) {
+	pipe := l.svcCtx.Redis.Pipeline()
+	for sort := range screenerSorts {
+		pipe.ZRem(l.ctx, constants.RedisKeyScreener+sort, symbols...)
+	}
+	if _, err := pipe.Exec(l.ctx); err != nil {
+		l.Errorf("failed to remove expired symbols from screener: %v", err)
+	}
+}
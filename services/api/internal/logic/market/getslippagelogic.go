@@ -0,0 +1,112 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetSlippageLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetSlippageLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetSlippageLogic {
+	return &GetSlippageLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetSlippage 按当前深度估算成交指定金额（计价币）的成交均价和滑点
+// 买入依次吃卖盘，卖出依次吃买盘；滑点相对中间价计算（包含半个价差），单位为基点。
+// 深度不足以成交全部金额时按可成交部分计算，Complete 为 false
+func (l *GetSlippageLogic) GetSlippage(req *types.SlippageRequest) (resp *types.SlippageResponse, err error) {
+	if err := l.svcCtx.Symbols.Check(req.Symbol); err != nil {
+		return nil, err
+	}
+
+	if req.Notional <= 0 {
+		return nil, errcode.Newf(errcode.ErrInvalidParam, "notional must be positive")
+	}
+
+	key := constants.RedisKeyDepth + req.Symbol
+
+	data, err := getDepthData(l.ctx, l.svcCtx, key)
+	if err == redis.Nil {
+		return nil, errcode.Newf(errcode.ErrNotFound, "order book is empty for symbol: %s", req.Symbol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get depth: %w", err)
+	}
+
+	var depth models.OrderBook
+	if err := codec.Unmarshal([]byte(data), &depth); err != nil {
+		return nil, fmt.Errorf("failed to parse depth data: %w", err)
+	}
+
+	if len(depth.Bids) == 0 || len(depth.Asks) == 0 {
+		return nil, errcode.Newf(errcode.ErrNotFound, "order book is empty for symbol: %s", req.Symbol)
+	}
+
+	levels := depth.Asks
+	if req.Side == constants.SideSell {
+		levels = depth.Bids
+	}
+
+	resp = &types.SlippageResponse{
+		Symbol:    req.Symbol,
+		Side:      req.Side,
+		Notional:  req.Notional,
+		BestPrice: levels[0].Price,
+		MidPrice:  (depth.Bids[0].Price + depth.Asks[0].Price) / 2,
+		Timestamp: depth.Timestamp,
+	}
+
+	// 逐档成交，最后一档只成交剩余金额
+	remaining := req.Notional
+	for _, level := range levels {
+		if remaining <= 0 {
+			break
+		}
+		if level.Price <= 0 || level.Amount <= 0 {
+			continue
+		}
+
+		amount := level.Amount
+		if notional := level.Price * amount; notional > remaining {
+			amount = remaining / level.Price
+		}
+
+		resp.FilledAmount += amount
+		resp.FilledNotional += level.Price * amount
+		resp.Levels++
+		remaining -= level.Price * amount
+	}
+
+	if resp.FilledAmount == 0 {
+		return resp, nil
+	}
+
+	resp.AvgPrice = resp.FilledNotional / resp.FilledAmount
+	resp.Complete = remaining <= req.Notional*1e-9
+
+	// 买入均价高于中间价、卖出均价低于中间价时滑点为正
+	resp.SlippageBps = (resp.AvgPrice - resp.MidPrice) / resp.MidPrice * 10000
+	if req.Side == constants.SideSell {
+		resp.SlippageBps = -resp.SlippageBps
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,75 @@
+package market
+
+import (
+	"context"
+	"market-system/common/errcode"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetSnapshotLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetSnapshotLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetSnapshotLogic {
+	return &GetSnapshotLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetSnapshot 获取交易对的 ticker、深度和最近成交，三者来自同一时刻
+func (l *GetSnapshotLogic) GetSnapshot(req *types.SnapshotRequest) (resp *types.SnapshotResponse, err error) {
+	if err := l.svcCtx.Symbols.Check(req.Symbol); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := readSnapshot(l.ctx, l.svcCtx.Redis, req.Symbol, req.Trades)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshot.Ticker == nil && snapshot.Depth == nil && len(snapshot.Trades) == 0 {
+		return nil, errcode.Newf(errcode.ErrNotFound, "market data not found for symbol: %s", req.Symbol)
+	}
+
+	resp = &types.SnapshotResponse{
+		Symbol:    req.Symbol,
+		Trades:    make([]types.Trade, 0, len(snapshot.Trades)),
+		Timestamp: snapshot.Timestamp,
+	}
+
+	// 清洗 NaN/Inf，避免序列化失败
+	if ticker := snapshot.Ticker; ticker != nil && l.svcCtx.Sanitizer.Ticker("redis", ticker) {
+		result := toTickerResponse(ticker)
+		setTickerAge(l.svcCtx.Config.Staleness, &result)
+		newTickerConverter(l.ctx, l.svcCtx).apply(&result)
+		resp.Ticker = &result
+	}
+
+	if depth := snapshot.Depth; depth != nil {
+		l.svcCtx.Sanitizer.OrderBook("redis", depth)
+		resp.Depth = &types.DepthResponse{
+			Symbol:    req.Symbol,
+			Bids:      priceLevels(depth.Bids, int(req.Depth)),
+			Asks:      priceLevels(depth.Asks, int(req.Depth)),
+			Timestamp: depth.Timestamp,
+		}
+	}
+
+	for i := range snapshot.Trades {
+		trade := &snapshot.Trades[i]
+		if !l.svcCtx.Sanitizer.Trade("redis", trade) {
+			continue
+		}
+		resp.Trades = append(resp.Trades, tradeResponse(trade))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,70 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetStats24hLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetStats24hLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetStats24hLogic {
+	return &GetStats24hLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetStats24h 获取交易对的 24 小时滚动统计（由 processor 按成交计算，需开启 rolling_ticker）
+func (l *GetStats24hLogic) GetStats24h(req *types.Stats24hRequest) (resp *types.Stats24hResponse, err error) {
+	if err := l.svcCtx.Symbols.Check(req.Symbol); err != nil {
+		return nil, err
+	}
+
+	data, err := l.svcCtx.Redis.Get(l.ctx, constants.RedisKeyMarketStats+req.Symbol).Result()
+	if err == redis.Nil {
+		return nil, errcode.Newf(errcode.ErrNotFound, "24h stats not found for symbol: %s", req.Symbol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get 24h stats: %w", err)
+	}
+
+	var stats models.MarketStats
+	if err := codec.Unmarshal([]byte(data), &stats); err != nil {
+		return nil, fmt.Errorf("failed to parse 24h stats: %w", err)
+	}
+
+	result := toStats24hResponse(&stats)
+	return &result, nil
+}
+
+// toStats24hResponse 转换为响应结构
+func toStats24hResponse(stats *models.MarketStats) types.Stats24hResponse {
+	return types.Stats24hResponse{
+		Symbol:             stats.Symbol,
+		Open:               stats.Open,
+		High:               stats.High,
+		Low:                stats.Low,
+		LastPrice:          stats.LastPrice,
+		Volume:             stats.Volume,
+		QuoteVolume:        stats.QuoteVolume,
+		PriceChange:        stats.PriceChange,
+		PriceChangePercent: stats.PriceChangePercent,
+		TradeCount:         stats.TradeCount,
+		Timestamp:          stats.Timestamp,
+	}
+}
@@ -3,7 +3,7 @@ package market
 import (
 	"context"
 	"fmt"
-	"market-system/common/constants"
+	"market-system/common/errcode"
 
 	"market-system/services/api/internal/svc"
 	"market-system/services/api/internal/types"
@@ -26,44 +26,33 @@ func NewGetTickerLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetTick
 }
 
 func (l *GetTickerLogic) GetTicker(req *types.TickerRequest) (resp *types.TickerResponse, err error) {
-	// 从 Redis 获取 Ticker 数据
-	key := constants.RedisKeyTicker + req.Symbol
+	if err := l.svcCtx.Symbols.Check(req.Symbol); err != nil {
+		return nil, err
+	}
 
-	data, err := l.svcCtx.Redis.HGetAll(l.ctx, key).Result()
+	// 从 Redis 获取 Ticker 数据
+	data, err := getTickerHash(l.ctx, l.svcCtx, req.Symbol)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ticker: %w", err)
 	}
 
 	if len(data) == 0 {
-		return nil, fmt.Errorf("ticker not found for symbol: %s", req.Symbol)
+		return nil, errcode.Newf(errcode.ErrNotFound, "ticker not found for symbol: %s", req.Symbol)
 	}
 
-	resp = &types.TickerResponse{
-		Symbol: req.Symbol,
-	}
+	ticker := parseTickerHash(req.Symbol, data)
 
-	// 解析数据
-	if val, ok := data["last_price"]; ok {
-		fmt.Sscanf(val, "%f", &resp.LastPrice)
-	}
-	if val, ok := data["bid_price"]; ok {
-		fmt.Sscanf(val, "%f", &resp.BidPrice)
+	// 清洗 NaN/Inf，避免序列化失败
+	if !l.svcCtx.Sanitizer.Ticker("redis", ticker) {
+		return nil, fmt.Errorf("invalid ticker data for symbol: %s", req.Symbol)
 	}
-	if val, ok := data["ask_price"]; ok {
-		fmt.Sscanf(val, "%f", &resp.AskPrice)
-	}
-	if val, ok := data["high_24h"]; ok {
-		fmt.Sscanf(val, "%f", &resp.High24h)
-	}
-	if val, ok := data["low_24h"]; ok {
-		fmt.Sscanf(val, "%f", &resp.Low24h)
-	}
-	if val, ok := data["volume_24h"]; ok {
-		fmt.Sscanf(val, "%f", &resp.Volume24h)
-	}
-	if val, ok := data["timestamp"]; ok {
-		fmt.Sscanf(val, "%d", &resp.Timestamp)
+
+	result := toTickerResponse(ticker)
+	setTickerAge(l.svcCtx.Config.Staleness, &result)
+	newTickerConverter(l.ctx, l.svcCtx).apply(&result)
+	if result.Stale && l.svcCtx.Config.Staleness.Reject {
+		return nil, errcode.Newf(errcode.ErrStaleData, "ticker for %s is stale: last update %d ms ago", req.Symbol, result.AgeMs)
 	}
 
-	return resp, nil
+	return &result, nil
 }
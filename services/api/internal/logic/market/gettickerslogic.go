@@ -0,0 +1,159 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+	"sort"
+	"strings"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// maxTickerSymbols symbols 参数最多列出的交易对数量
+const maxTickerSymbols = 200
+
+type GetTickersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetTickersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetTickersLogic {
+	return &GetTickersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetTickers 批量获取 Ticker
+// 指定分组时返回分组内的交易对（按分组内的顺序），指定 symbols 时只返回列出的交易对（按列出的顺序，
+// 同时指定分组时取交集）；都不指定时返回全部有 Ticker 数据的交易对（按交易对排序）。
+// 已软删除、没有行情数据或数据无效的交易对不返回
+func (l *GetTickersLogic) GetTickers(req *types.TickersRequest) (resp *types.TickersResponse, err error) {
+	candidates, err := l.candidates(req)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]string, 0, len(candidates))
+	for _, symbol := range candidates {
+		if !l.svcCtx.Symbols.IsDeleted(symbol) {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	pipe := l.svcCtx.Redis.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(symbols))
+	for i, symbol := range symbols {
+		cmds[i] = pipe.HGetAll(l.ctx, constants.RedisKeyTicker+symbol)
+	}
+	if len(symbols) > 0 {
+		if _, err := pipe.Exec(l.ctx); err != nil {
+			return nil, fmt.Errorf("failed to get tickers: %w", err)
+		}
+	}
+
+	resp = &types.TickersResponse{
+		Group:   req.Group,
+		Tickers: make([]types.TickerResponse, 0, len(symbols)),
+	}
+	converter := newTickerConverter(l.ctx, l.svcCtx)
+	for i, symbol := range symbols {
+		data := cmds[i].Val()
+		if len(data) == 0 {
+			continue
+		}
+
+		ticker := parseTickerHash(symbol, data)
+		// 清洗 NaN/Inf，避免序列化失败
+		if !l.svcCtx.Sanitizer.Ticker("redis", ticker) {
+			continue
+		}
+		result := toTickerResponse(ticker)
+		setTickerAge(l.svcCtx.Config.Staleness, &result)
+		converter.apply(&result)
+		resp.Tickers = append(resp.Tickers, result)
+	}
+
+	return resp, nil
+}
+
+// candidates 按请求确定要获取的交易对
+func (l *GetTickersLogic) candidates(req *types.TickersRequest) ([]string, error) {
+	var listed []string
+	for _, symbol := range strings.Split(req.Symbols, ",") {
+		if symbol = strings.ToUpper(strings.TrimSpace(symbol)); symbol != "" {
+			listed = append(listed, symbol)
+		}
+	}
+	if len(listed) > maxTickerSymbols {
+		return nil, errcode.Newf(errcode.ErrInvalidParam, "too many symbols: %d (max %d)", len(listed), maxTickerSymbols)
+	}
+
+	if req.Group == "" {
+		if len(listed) > 0 {
+			return listed, nil
+		}
+		return l.scanTickerSymbols()
+	}
+
+	group := l.svcCtx.Groups.Get(req.Group)
+	if group == nil {
+		return nil, errcode.Newf(errcode.ErrNotFound, "symbol group not found: %s", req.Group)
+	}
+	if len(listed) == 0 {
+		return group.Symbols, nil
+	}
+
+	selected := make(map[string]bool, len(listed))
+	for _, symbol := range listed {
+		selected[symbol] = true
+	}
+	symbols := make([]string, 0, len(listed))
+	for _, symbol := range group.Symbols {
+		if selected[symbol] {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols, nil
+}
+
+// scanTickerSymbols 查找全部有 Ticker 数据的交易对，按交易对排序
+func (l *GetTickersLogic) scanTickerSymbols() ([]string, error) {
+	var symbols []string
+	iter := l.svcCtx.Redis.Scan(l.ctx, 0, constants.RedisKeyTicker+"*", 500).Iterator()
+	for iter.Next(l.ctx) {
+		symbols = append(symbols, strings.TrimPrefix(iter.Val(), constants.RedisKeyTicker))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan tickers: %w", err)
+	}
+	sort.Strings(symbols)
+	return symbols, nil
+}
+
+// toTickerResponse 转换为响应结构
+func toTickerResponse(ticker *models.Ticker) types.TickerResponse {
+	return types.TickerResponse{
+		Symbol:                ticker.Symbol,
+		LastPrice:             ticker.LastPrice,
+		BidPrice:              ticker.BidPrice,
+		AskPrice:              ticker.AskPrice,
+		High24h:               ticker.High24h,
+		Low24h:                ticker.Low24h,
+		Volume24h:             ticker.Volume24h,
+		Open24h:               ticker.Open24h,
+		PriceChange24h:        ticker.PriceChange24h,
+		PriceChangePercent24h: ticker.PriceChangePercent24h,
+		TradeCount24h:         ticker.TradeCount24h,
+		Timestamp:             ticker.Timestamp,
+	}
+}
@@ -0,0 +1,76 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetTickerSourcesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetTickerSourcesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetTickerSourcesLogic {
+	return &GetTickerSourcesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetTickerSources 获取带来源明细的 Ticker（由 processor 随 Ticker 写入）
+// 混合模式下为融合后的 Ticker，可据此区分内部成交量与外部镜像的成交量；单一来源的交易对成交量全部计入该来源
+func (l *GetTickerSourcesLogic) GetTickerSources(req *types.TickerRequest) (resp *types.TickerSourcesResponse, err error) {
+	if err := l.svcCtx.Symbols.Check(req.Symbol); err != nil {
+		return nil, err
+	}
+
+	key := constants.RedisKeyTickerSource + req.Symbol
+
+	data, err := l.svcCtx.Redis.Get(l.ctx, key).Result()
+	if err == redis.Nil {
+		return nil, errcode.Newf(errcode.ErrNotFound, "ticker sources not found for symbol: %s", req.Symbol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticker sources: %w", err)
+	}
+
+	var ticker models.TickerWithSource
+	if err := codec.Unmarshal([]byte(data), &ticker); err != nil {
+		return nil, fmt.Errorf("failed to parse ticker sources: %w", err)
+	}
+
+	resp = &types.TickerSourcesResponse{
+		Symbol:                req.Symbol,
+		LastPrice:             ticker.LastPrice,
+		LastPriceSource:       ticker.LastPriceSource,
+		BidPrice:              ticker.BidPrice,
+		AskPrice:              ticker.AskPrice,
+		High24h:               ticker.High24h,
+		Low24h:                ticker.Low24h,
+		Open24h:               ticker.Open24h,
+		PriceChange24h:        ticker.PriceChange24h,
+		PriceChangePercent24h: ticker.PriceChangePercent24h,
+		TradeCount24h:         ticker.TradeCount24h,
+		InternalVolume24h:     ticker.InternalVolume24h,
+		ExternalVolume24h:     ticker.ExternalVolume24h,
+		TotalVolume24h:        ticker.TotalVolume24h,
+		Timestamp:             ticker.Timestamp,
+	}
+	if ticker.TotalVolume24h > 0 {
+		resp.InternalVolumeRatio = ticker.InternalVolume24h / ticker.TotalVolume24h
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,84 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/models"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// tradeHistorySize Processor 为每个交易对保留的最近成交条数
+const tradeHistorySize = 100
+
+type GetTradesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetTradesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetTradesLogic {
+	return &GetTradesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetTrades 获取交易对的最近成交（按时间倒序），source 为 internal / external 时只返回对应数据源的成交
+// 过滤在保留的最近成交中进行，返回条数可能少于 limit
+func (l *GetTradesLogic) GetTrades(req *types.TradesRequest) (resp *types.TradesResponse, err error) {
+	if err := l.svcCtx.Symbols.Check(req.Symbol); err != nil {
+		return nil, err
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 || limit > tradeHistorySize {
+		limit = tradeHistorySize
+	}
+
+	key := constants.RedisKeyTrade + req.Symbol
+	items, err := l.svcCtx.Redis.LRange(l.ctx, key, 0, tradeHistorySize-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trades: %w", err)
+	}
+
+	resp = &types.TradesResponse{
+		Symbol: req.Symbol,
+		Trades: make([]types.Trade, 0, limit),
+	}
+	for _, item := range items {
+		if len(resp.Trades) >= limit {
+			break
+		}
+
+		var trade models.Trade
+		if err := codec.Unmarshal([]byte(item), &trade); err != nil {
+			continue
+		}
+		if !matchTradeSource(&trade, req.Source) || !l.svcCtx.Sanitizer.Trade("redis", &trade) {
+			continue
+		}
+		resp.Trades = append(resp.Trades, tradeResponse(&trade))
+	}
+
+	return resp, nil
+}
+
+// matchTradeSource 成交是否属于指定数据源，source 为空或 all 时不过滤
+// 没有记录数据源的成交视为外部成交
+func matchTradeSource(trade *models.Trade, source string) bool {
+	if source == "" || source == constants.SourceAll {
+		return true
+	}
+	tradeSource := trade.Source
+	if tradeSource == "" {
+		tradeSource = constants.SourceExternal
+	}
+	return tradeSource == source
+}
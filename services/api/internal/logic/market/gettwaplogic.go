@@ -0,0 +1,62 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetTWAPLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetTWAPLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetTWAPLogic {
+	return &GetTWAPLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetTWAP 获取交易对的按时间加权参考价格（由 processor 定期计算写入）
+func (l *GetTWAPLogic) GetTWAP(req *types.TWAPRequest) (resp *types.TWAPResponse, err error) {
+	if err := l.svcCtx.Symbols.Check(req.Symbol); err != nil {
+		return nil, err
+	}
+
+	key := constants.RedisKeyTWAP + req.Symbol
+
+	data, err := l.svcCtx.Redis.Get(l.ctx, key).Result()
+	if err == redis.Nil {
+		return nil, errcode.Newf(errcode.ErrNotFound, "twap not found for symbol: %s", req.Symbol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get twap: %w", err)
+	}
+
+	var price models.ReferencePrice
+	if err := codec.Unmarshal([]byte(data), &price); err != nil {
+		return nil, fmt.Errorf("failed to parse twap data: %w", err)
+	}
+
+	resp = &types.TWAPResponse{
+		Symbol:    req.Symbol,
+		LastPrice: price.LastPrice,
+		Twap:      price.TWAP,
+		Coverage:  price.Coverage,
+		Timestamp: price.Timestamp,
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,61 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetVWAPLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetVWAPLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetVWAPLogic {
+	return &GetVWAPLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetVWAP 获取交易对各滚动窗口的成交量加权平均价格（由 processor 定期计算写入）
+func (l *GetVWAPLogic) GetVWAP(req *types.VWAPRequest) (resp *types.VWAPResponse, err error) {
+	if err := l.svcCtx.Symbols.Check(req.Symbol); err != nil {
+		return nil, err
+	}
+
+	key := constants.RedisKeyVWAP + req.Symbol
+
+	data, err := l.svcCtx.Redis.Get(l.ctx, key).Result()
+	if err == redis.Nil {
+		return nil, errcode.Newf(errcode.ErrNotFound, "vwap not found for symbol: %s", req.Symbol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vwap: %w", err)
+	}
+
+	var price models.VWAPPrice
+	if err := codec.Unmarshal([]byte(data), &price); err != nil {
+		return nil, fmt.Errorf("failed to parse vwap data: %w", err)
+	}
+
+	resp = &types.VWAPResponse{
+		Symbol:    req.Symbol,
+		Vwap:      price.VWAP,
+		Volume:    price.Volume,
+		Timestamp: price.Timestamp,
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,47 @@
+package market
+
+import (
+	"context"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListGroupsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListGroupsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListGroupsLogic {
+	return &ListGroupsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ListGroups 获取全部交易对分组，供前端生成分类标签；已软删除的交易对不返回
+func (l *ListGroupsLogic) ListGroups() (resp *types.SymbolGroupListResponse, err error) {
+	groups := l.svcCtx.Groups.List()
+
+	resp = &types.SymbolGroupListResponse{
+		Groups: make([]types.SymbolGroupResponse, 0, len(groups)),
+	}
+	for _, group := range groups {
+		symbols := make([]string, 0, len(group.Symbols))
+		for _, symbol := range group.Symbols {
+			if !l.svcCtx.Symbols.IsDeleted(symbol) {
+				symbols = append(symbols, symbol)
+			}
+		}
+		resp.Groups = append(resp.Groups, types.SymbolGroupResponse{
+			Name:        group.Name,
+			Description: group.Description,
+			Symbols:     symbols,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,43 @@
+package market
+
+import (
+	"context"
+	"strings"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListPriceAlertsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListPriceAlertsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListPriceAlertsLogic {
+	return &ListPriceAlertsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ListPriceAlerts 获取当前认证身份创建的价格提醒（包括 WebSocket 创建的），按创建时间排序
+func (l *ListPriceAlertsLogic) ListPriceAlerts(req *types.PriceAlertsRequest) (resp *types.PriceAlertsResponse, err error) {
+	owner, err := alertOwner(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+	alerts, err := l.svcCtx.PriceAlerts.List(l.ctx, owner, strings.ToUpper(req.Symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &types.PriceAlertsResponse{Alerts: make([]types.PriceAlert, 0, len(alerts))}
+	for _, alert := range alerts {
+		resp.Alerts = append(resp.Alerts, toPriceAlert(alert))
+	}
+	return resp, nil
+}
@@ -0,0 +1,91 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+	"sort"
+	"strings"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListStats24hLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListStats24hLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListStats24hLogic {
+	return &ListStats24hLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ListStats24h 批量获取 24 小时滚动统计，指定 symbols 时只返回列出的交易对（按列出的顺序），
+// 否则返回全部有统计的交易对（按交易对排序）；已软删除或没有统计的交易对不返回
+func (l *ListStats24hLogic) ListStats24h(req *types.Stats24hListRequest) (resp *types.Stats24hListResponse, err error) {
+	var symbols []string
+	for _, symbol := range strings.Split(req.Symbols, ",") {
+		if symbol = strings.ToUpper(strings.TrimSpace(symbol)); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+	if len(symbols) > maxTickerSymbols {
+		return nil, errcode.Newf(errcode.ErrInvalidParam, "too many symbols: %d (max %d)", len(symbols), maxTickerSymbols)
+	}
+	if len(symbols) == 0 {
+		iter := l.svcCtx.Redis.Scan(l.ctx, 0, constants.RedisKeyMarketStats+"*", 500).Iterator()
+		for iter.Next(l.ctx) {
+			symbols = append(symbols, strings.TrimPrefix(iter.Val(), constants.RedisKeyMarketStats))
+		}
+		if err := iter.Err(); err != nil {
+			return nil, fmt.Errorf("failed to scan 24h stats: %w", err)
+		}
+		sort.Strings(symbols)
+	}
+
+	visible := symbols[:0]
+	for _, symbol := range symbols {
+		if !l.svcCtx.Symbols.IsDeleted(symbol) {
+			visible = append(visible, symbol)
+		}
+	}
+
+	pipe := l.svcCtx.Redis.Pipeline()
+	cmds := make([]*redis.StringCmd, len(visible))
+	for i, symbol := range visible {
+		cmds[i] = pipe.Get(l.ctx, constants.RedisKeyMarketStats+symbol)
+	}
+	if len(visible) > 0 {
+		if _, err := pipe.Exec(l.ctx); err != nil && err != redis.Nil {
+			return nil, fmt.Errorf("failed to get 24h stats: %w", err)
+		}
+	}
+
+	resp = &types.Stats24hListResponse{
+		Stats: make([]types.Stats24hResponse, 0, len(visible)),
+	}
+	for i, symbol := range visible {
+		data, err := cmds[i].Result()
+		if err != nil {
+			continue
+		}
+		var stats models.MarketStats
+		if err := codec.Unmarshal([]byte(data), &stats); err != nil {
+			l.Errorf("[Market] Invalid 24h stats for %s: %v", symbol, err)
+			continue
+		}
+		resp.Stats = append(resp.Stats, toStats24hResponse(&stats))
+	}
+	return resp, nil
+}
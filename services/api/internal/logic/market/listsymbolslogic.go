@@ -0,0 +1,121 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"sort"
+	"strconv"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListSymbolsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListSymbolsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListSymbolsLogic {
+	return &ListSymbolsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// channelKeys 各频道对应的行情 key 前缀，交易对有该 key 时视为频道可用
+// K线按 1m 周期判断（其他周期由 1m 聚合）
+var channelKeys = []struct {
+	channel string
+	key     func(symbol string) string
+}{
+	{constants.DataTypeTicker, func(s string) string { return constants.RedisKeyTicker + s }},
+	{constants.DataTypeDepth, func(s string) string { return constants.RedisKeyDepth + s }},
+	{constants.DataTypeTrade, func(s string) string { return constants.RedisKeyTrade + s }},
+	{constants.DataTypeAggTrade, func(s string) string { return constants.RedisKeyAggTrade + s }},
+	{constants.DataTypeKline, func(s string) string { return constants.RedisKeyKline + s + ":1m" }},
+}
+
+// ListSymbols 获取交易对注册表中的全部交易对（已软删除的不返回），供前端生成行情列表
+// 可用频道和数据新鲜度以 Redis 中的行情数据为准：最近一次 Ticker 更新超过新鲜度阈值视为不新鲜
+func (l *ListSymbolsLogic) ListSymbols() (resp *types.SymbolListResponse, err error) {
+	data, err := l.svcCtx.Redis.HGetAll(l.ctx, constants.RedisKeySymbolConfig).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list symbols: %w", err)
+	}
+
+	configs := make([]*models.SymbolConfig, 0, len(data))
+	for symbol, raw := range data {
+		var cfg models.SymbolConfig
+		if err := json.Unmarshal([]byte(raw), &cfg); err != nil {
+			l.Errorf("[Market] Invalid symbol config for %s: %v", symbol, err)
+			continue
+		}
+		if cfg.Deleted || l.svcCtx.Symbols.IsDeleted(symbol) {
+			continue
+		}
+		cfg.Symbol = symbol
+		configs = append(configs, &cfg)
+	}
+	sort.Slice(configs, func(i, j int) bool {
+		return configs[i].Symbol < configs[j].Symbol
+	})
+
+	// 在同一个管道中读取各交易对的行情 key 是否存在和 Ticker 更新时间
+	pipe := l.svcCtx.Redis.Pipeline()
+	exists := make([][]*redis.IntCmd, len(configs))
+	timestamps := make([]*redis.SliceCmd, len(configs))
+	for i, cfg := range configs {
+		exists[i] = make([]*redis.IntCmd, len(channelKeys))
+		for j, c := range channelKeys {
+			exists[i][j] = pipe.Exists(l.ctx, c.key(cfg.Symbol))
+		}
+		timestamps[i] = pipe.HMGet(l.ctx, constants.RedisKeyTicker+cfg.Symbol, "timestamp")
+	}
+	if len(configs) > 0 {
+		if _, err := pipe.Exec(l.ctx); err != nil {
+			return nil, fmt.Errorf("failed to get symbol status: %w", err)
+		}
+	}
+
+	now := utils.GetCurrentTimestamp()
+	resp = &types.SymbolListResponse{
+		Symbols:   make([]types.SymbolInfo, 0, len(configs)),
+		Timestamp: now,
+	}
+	for i, cfg := range configs {
+		info := types.SymbolInfo{
+			Symbol:      cfg.Symbol,
+			Mode:        cfg.Mode,
+			Enable:      cfg.Enable,
+			Description: cfg.Description,
+			TickSize:    cfg.TickSize,
+			Channels:    make([]string, 0, len(channelKeys)),
+		}
+		for j, c := range channelKeys {
+			if exists[i][j].Val() > 0 {
+				info.Channels = append(info.Channels, c.channel)
+			}
+		}
+
+		threshold := cfg.FreshnessMs
+		if threshold <= 0 {
+			threshold = constants.DataFreshnessThreshold
+		}
+		if ts, ok := timestamps[i].Val()[0].(string); ok {
+			info.LastUpdate, _ = strconv.ParseInt(ts, 10, 64)
+		}
+		info.Fresh = info.LastUpdate > 0 && now-info.LastUpdate <= threshold
+
+		resp.Symbols = append(resp.Symbols, info)
+	}
+	return resp, nil
+}
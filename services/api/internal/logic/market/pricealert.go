@@ -0,0 +1,32 @@
+package market
+
+import (
+	"context"
+	"market-system/common/errcode"
+	"market-system/common/models"
+
+	"market-system/services/api/internal/middleware"
+	"market-system/services/api/internal/types"
+)
+
+// alertOwner 价格提醒的创建者为请求的认证身份
+// 提醒按创建者隔离，未开启认证或匿名访问时没有可区分的身份，返回 ErrUnauthorized
+func alertOwner(ctx context.Context) (string, error) {
+	if id, ok := middleware.IdentityFrom(ctx); ok && id.Name != "" {
+		return id.Name, nil
+	}
+	return "", errcode.Newf(errcode.ErrUnauthorized, "price alerts require authentication")
+}
+
+// toPriceAlert 转换为接口响应
+func toPriceAlert(alert *models.PriceAlert) types.PriceAlert {
+	return types.PriceAlert{
+		Id:        alert.ID,
+		Symbol:    alert.Symbol,
+		Condition: alert.Condition,
+		Value:     alert.Value,
+		Webhook:   alert.Webhook,
+		Repeat:    alert.Repeat,
+		CreatedAt: alert.CreatedAt,
+	}
+}
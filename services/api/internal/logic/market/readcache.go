@@ -0,0 +1,40 @@
+package market
+
+import (
+	"context"
+	"market-system/common/constants"
+
+	"market-system/services/api/internal/svc"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// getTickerHash 经 Ticker 读缓存读取 ticker Hash，不存在时返回空 map
+// 读取结果由合并的并发请求共享，不随发起读取的请求取消
+func getTickerHash(ctx context.Context, svcCtx *svc.ServiceContext, symbol string) (map[string]string, error) {
+	v, err := svcCtx.TickerCache.Get(symbol, func() (interface{}, error) {
+		return svcCtx.Redis.HGetAll(context.WithoutCancel(ctx), constants.RedisKeyTicker+symbol).Result()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(map[string]string), nil
+}
+
+// getDepthData 经深度读缓存读取深度 key，不存在时返回 redis.Nil（不存在的结果同样缓存）
+func getDepthData(ctx context.Context, svcCtx *svc.ServiceContext, key string) (string, error) {
+	v, err := svcCtx.DepthCache.Get(key, func() (interface{}, error) {
+		data, err := svcCtx.Redis.Get(context.WithoutCancel(ctx), key).Result()
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return data, err
+	})
+	if err != nil {
+		return "", err
+	}
+	if v == nil {
+		return "", redis.Nil
+	}
+	return v.(string), nil
+}
@@ -0,0 +1,171 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/models"
+
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 同一交易对的 ticker、深度、成交分别存储在不同的 key 中，Processor 依次写入。
+// 组合接口分别读取多个 key 时可能读到不同时刻的数据（例如新深度 + 旧 ticker），
+// 因此通过 Lua 脚本在一次原子执行中读取所有 key，Redis 执行脚本期间不会处理其他写入。
+// 注意：Redis Cluster 下这些 key 需要在同一个 slot 中（使用 hash tag），当前部署为单实例。
+
+// snapshotScript 原子读取 ticker（Hash）、深度（String）、最近成交（List）及 Redis 服务器时间
+// KEYS[1] ticker key, KEYS[2] depth key, KEYS[3] trade key; ARGV[1] 成交条数
+var snapshotScript = redis.NewScript(`
+local ticker = redis.call('HGETALL', KEYS[1])
+local depth = redis.call('GET', KEYS[2])
+local trades = redis.call('LRANGE', KEYS[3], 0, tonumber(ARGV[1]) - 1)
+local now = redis.call('TIME')
+return {ticker, depth, trades, now}
+`)
+
+// marketSnapshot 同一时刻的交易对行情
+type marketSnapshot struct {
+	Ticker    *models.Ticker    // 为 nil 表示没有 ticker 数据
+	Depth     *models.OrderBook // 为 nil 表示没有深度数据
+	Trades    []models.Trade    // 最近成交，按时间倒序
+	Timestamp int64             // 读取时的 Redis 服务器时间（毫秒）
+}
+
+// readSnapshot 一致性读取交易对的 ticker、深度和最近成交
+func readSnapshot(ctx context.Context, rdb *redis.Client, symbol string, tradeLimit int64) (*marketSnapshot, error) {
+	if tradeLimit < 0 {
+		tradeLimit = 0
+	}
+	keys := []string{
+		constants.RedisKeyTicker + symbol,
+		constants.RedisKeyDepth + symbol,
+		constants.RedisKeyTrade + symbol,
+	}
+
+	result, err := snapshotScript.Run(ctx, rdb, keys, tradeLimit).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if len(result) != 4 {
+		return nil, fmt.Errorf("unexpected snapshot reply length: %d", len(result))
+	}
+
+	snapshot := &marketSnapshot{}
+
+	// HGETALL 在脚本中返回 field/value 交替的数组
+	if fields, ok := result[0].([]interface{}); ok && len(fields) > 0 {
+		data := make(map[string]string, len(fields)/2)
+		for i := 0; i+1 < len(fields); i += 2 {
+			field, _ := fields[i].(string)
+			value, _ := fields[i+1].(string)
+			data[field] = value
+		}
+		snapshot.Ticker = parseTickerHash(symbol, data)
+	}
+
+	// key 不存在时 GET 返回 false，转换为 nil
+	if data, ok := result[1].(string); ok {
+		var depth models.OrderBook
+		if err := codec.Unmarshal([]byte(data), &depth); err != nil {
+			return nil, fmt.Errorf("failed to parse depth data: %w", err)
+		}
+		snapshot.Depth = &depth
+	}
+
+	if items, ok := result[2].([]interface{}); ok {
+		snapshot.Trades = make([]models.Trade, 0, len(items))
+		for _, item := range items {
+			data, _ := item.(string)
+			var trade models.Trade
+			if err := codec.Unmarshal([]byte(data), &trade); err != nil {
+				continue
+			}
+			snapshot.Trades = append(snapshot.Trades, trade)
+		}
+	}
+
+	// TIME 返回 [秒, 微秒]
+	if now, ok := result[3].([]interface{}); ok && len(now) == 2 {
+		var sec, usec int64
+		fmt.Sscanf(fmt.Sprint(now[0]), "%d", &sec)
+		fmt.Sscanf(fmt.Sprint(now[1]), "%d", &usec)
+		snapshot.Timestamp = sec*1000 + usec/1000
+	}
+
+	return snapshot, nil
+}
+
+// parseTickerHash 从 ticker Hash 解析 Ticker
+func parseTickerHash(symbol string, data map[string]string) *models.Ticker {
+	ticker := &models.Ticker{
+		Symbol: symbol,
+	}
+
+	if val, ok := data["last_price"]; ok {
+		fmt.Sscanf(val, "%f", &ticker.LastPrice)
+	}
+	if val, ok := data["bid_price"]; ok {
+		fmt.Sscanf(val, "%f", &ticker.BidPrice)
+	}
+	if val, ok := data["ask_price"]; ok {
+		fmt.Sscanf(val, "%f", &ticker.AskPrice)
+	}
+	if val, ok := data["high_24h"]; ok {
+		fmt.Sscanf(val, "%f", &ticker.High24h)
+	}
+	if val, ok := data["low_24h"]; ok {
+		fmt.Sscanf(val, "%f", &ticker.Low24h)
+	}
+	if val, ok := data["volume_24h"]; ok {
+		fmt.Sscanf(val, "%f", &ticker.Volume24h)
+	}
+	if val, ok := data["open_24h"]; ok {
+		fmt.Sscanf(val, "%f", &ticker.Open24h)
+	}
+	if val, ok := data["price_change_24h"]; ok {
+		fmt.Sscanf(val, "%f", &ticker.PriceChange24h)
+	}
+	if val, ok := data["price_change_percent_24h"]; ok {
+		fmt.Sscanf(val, "%f", &ticker.PriceChangePercent24h)
+	}
+	if val, ok := data["trade_count_24h"]; ok {
+		fmt.Sscanf(val, "%d", &ticker.TradeCount24h)
+	}
+	if val, ok := data["timestamp"]; ok {
+		fmt.Sscanf(val, "%d", &ticker.Timestamp)
+	}
+
+	return ticker
+}
+
+// priceLevels 转换深度档位，最多 limit 档
+func priceLevels(levels []models.PriceLevel, limit int) []types.PriceLevel {
+	if limit > len(levels) {
+		limit = len(levels)
+	}
+	result := make([]types.PriceLevel, 0, limit)
+	for i := 0; i < limit; i++ {
+		result = append(result, types.PriceLevel{
+			Price:  levels[i].Price,
+			Amount: levels[i].Amount,
+		})
+	}
+	return result
+}
+
+// tradeResponse 转换为接口返回的成交
+func tradeResponse(trade *models.Trade) types.Trade {
+	return types.Trade{
+		TradeID:   trade.TradeID,
+		Price:     trade.Price,
+		Amount:    trade.Amount,
+		Side:      trade.Side,
+		Timestamp: trade.Timestamp,
+		Source:    trade.Source,
+		Exchange:  trade.Exchange,
+	}
+}
@@ -0,0 +1,18 @@
+package market
+
+import (
+	"market-system/services/api/internal/config"
+	"market-system/services/api/internal/types"
+	"time"
+)
+
+// setTickerAge 填写 Ticker 的数据延迟和过期标记
+func setTickerAge(c config.StalenessConfig, resp *types.TickerResponse) {
+	resp.AgeMs, resp.Stale = dataAge(c, resp.Timestamp, time.Now().UnixMilli())
+}
+
+// dataAge 计算数据延迟（毫秒），超过阈值时视为过期；时钟偏差导致的负值按 0 计算
+func dataAge(c config.StalenessConfig, timestamp, now int64) (int64, bool) {
+	age := max(now-timestamp, 0)
+	return age, c.ThresholdMs > 0 && age > c.ThresholdMs
+}
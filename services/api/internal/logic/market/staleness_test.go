@@ -0,0 +1,28 @@
+package market
+
+import (
+	"testing"
+
+	"market-system/services/api/internal/config"
+)
+
+func TestDataAge(t *testing.T) {
+	const now = 1700000100000
+	tests := []struct {
+		c         config.StalenessConfig
+		timestamp int64
+		age       int64
+		stale     bool
+	}{
+		{config.StalenessConfig{ThresholdMs: 60000}, now - 1000, 1000, false},
+		{config.StalenessConfig{ThresholdMs: 60000}, now - 60001, 60001, true},
+		{config.StalenessConfig{ThresholdMs: 0}, now - 3600000, 3600000, false},
+		{config.StalenessConfig{ThresholdMs: 60000}, now + 500, 0, false},
+	}
+	for _, tt := range tests {
+		age, stale := dataAge(tt.c, tt.timestamp, now)
+		if age != tt.age || stale != tt.stale {
+			t.Errorf("dataAge(%+v, %d) = %d, %v, want %d, %v", tt.c, tt.timestamp, age, stale, tt.age, tt.stale)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/models"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetConsistencyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetConsistencyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetConsistencyLogic {
+	return &GetConsistencyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetConsistency 获取 Processor 最近一次K线一致性检查的报告，未启用检查或报告已过期时 available 为 false
+func (l *GetConsistencyLogic) GetConsistency() (resp *types.ConsistencyResponse, err error) {
+	data, err := l.svcCtx.Redis.Get(l.ctx, constants.RedisKeyConsistencyReport).Result()
+	if err == redis.Nil {
+		return &types.ConsistencyResponse{Discrepancies: []types.KlineDiscrepancy{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consistency report: %w", err)
+	}
+
+	var report models.KlineConsistencyReport
+	if err := json.Unmarshal([]byte(data), &report); err != nil {
+		return nil, fmt.Errorf("invalid consistency report: %w", err)
+	}
+
+	resp = &types.ConsistencyResponse{
+		Available:     true,
+		Interval:      report.Interval,
+		Symbols:       report.Symbols,
+		Compared:      report.Compared,
+		Mismatched:    report.Mismatched,
+		Missing:       report.Missing,
+		Repaired:      report.Repaired,
+		Errors:        report.Errors,
+		Discrepancies: make([]types.KlineDiscrepancy, 0, len(report.Discrepancies)),
+		StartTime:     report.StartTime,
+		EndTime:       report.EndTime,
+		Timestamp:     report.Timestamp,
+	}
+	for _, d := range report.Discrepancies {
+		resp.Discrepancies = append(resp.Discrepancies, types.KlineDiscrepancy{
+			Symbol:   d.Symbol,
+			Exchange: d.Exchange,
+			OpenTime: d.OpenTime,
+			Field:    d.Field,
+			Local:    d.Local,
+			Remote:   d.Remote,
+			Diff:     d.Diff,
+			Repaired: d.Repaired,
+		})
+	}
+	return resp, nil
+}
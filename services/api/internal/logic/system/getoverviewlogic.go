@@ -0,0 +1,154 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"strconv"
+	"strings"
+
+	"market-system/services/api/internal/readcache"
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetOverviewLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetOverviewLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetOverviewLogic {
+	return &GetOverviewLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetOverview 汇总系统运行概览
+// 消息速率、Kafka 消费延迟、交易所连接状态来自 Collector/Processor 定期写入 Redis 的统计，
+// 服务超过 ServiceStatsTTL 未上报时统计缺失，对应服务标记为离线
+func (l *GetOverviewLogic) GetOverview() (resp *types.OverviewResponse, err error) {
+	resp = &types.OverviewResponse{
+		MessageRates:   make(map[string]float64),
+		ProcessedRates: make(map[string]float64),
+		WsClients:      l.svcCtx.WsHub.ClientCount(),
+		KafkaLag:       make(map[string]int64),
+		Exchanges:      make(map[string]bool),
+		Services:       make(map[string]types.ServiceStatus),
+		ReadCache:      make(map[string]types.CacheStats),
+		Timestamp:      utils.GetCurrentTimestamp(),
+	}
+
+	// 跟踪的交易对数量（以 Ticker 缓存为准）
+	symbols, err := l.countKeys(constants.RedisKeyTicker + "*")
+	if err != nil {
+		return nil, err
+	}
+	resp.SymbolsTracked = symbols
+
+	// Redis 内存
+	if err := l.fillRedisMemory(resp); err != nil {
+		l.Errorf("[System] Failed to get redis memory: %v", err)
+	}
+
+	// 读缓存命中统计
+	l.fillReadCache(resp)
+
+	// Collector 统计
+	if stats := l.loadServiceStats(constants.ServiceCollector); stats != nil {
+		resp.MessageRates = stats.MessageRates
+		resp.Exchanges = stats.Exchanges
+		resp.Services[constants.ServiceCollector] = types.ServiceStatus{Online: true, Timestamp: stats.Timestamp}
+	} else {
+		resp.Services[constants.ServiceCollector] = types.ServiceStatus{}
+	}
+
+	// Processor 统计
+	if stats := l.loadServiceStats(constants.ServiceProcessor); stats != nil {
+		resp.ProcessedRates = stats.MessageRates
+		resp.KafkaLag = stats.KafkaLag
+		for _, lag := range stats.KafkaLag {
+			resp.TotalKafkaLag += lag
+		}
+		resp.Services[constants.ServiceProcessor] = types.ServiceStatus{Online: true, Timestamp: stats.Timestamp}
+	} else {
+		resp.Services[constants.ServiceProcessor] = types.ServiceStatus{}
+	}
+
+	return resp, nil
+}
+
+// countKeys 统计匹配的 key 数量
+func (l *GetOverviewLogic) countKeys(pattern string) (int, error) {
+	count := 0
+	iter := l.svcCtx.Redis.Scan(l.ctx, 0, pattern, 100).Iterator()
+	for iter.Next(l.ctx) {
+		count++
+	}
+	if err := iter.Err(); err != nil {
+		return 0, fmt.Errorf("failed to scan keys: %w", err)
+	}
+	return count, nil
+}
+
+// fillRedisMemory 解析 INFO memory 中的内存占用
+func (l *GetOverviewLogic) fillRedisMemory(resp *types.OverviewResponse) error {
+	info, err := l.svcCtx.Redis.Info(l.ctx, "memory").Result()
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if value, ok := strings.CutPrefix(line, "used_memory:"); ok {
+			resp.RedisUsedMemory, _ = strconv.ParseInt(value, 10, 64)
+		} else if value, ok := strings.CutPrefix(line, "used_memory_human:"); ok {
+			resp.RedisMemoryHuman = value
+		}
+	}
+	return nil
+}
+
+// fillReadCache 填充本实例各读缓存的命中统计
+func (l *GetOverviewLogic) fillReadCache(resp *types.OverviewResponse) {
+	for name, cache := range map[string]*readcache.Cache{
+		"ticker": l.svcCtx.TickerCache,
+		"depth":  l.svcCtx.DepthCache,
+	} {
+		if cache == nil {
+			continue
+		}
+		stats := cache.Stats()
+		item := types.CacheStats{Hits: stats.Hits, Misses: stats.Misses}
+		if total := stats.Hits + stats.Misses; total > 0 {
+			item.HitRate = float64(stats.Hits) / float64(total)
+		}
+		resp.ReadCache[name] = item
+	}
+}
+
+// loadServiceStats 读取服务上报的统计，不存在或解析失败时返回 nil
+func (l *GetOverviewLogic) loadServiceStats(service string) *models.ServiceStats {
+	data, err := l.svcCtx.Redis.Get(l.ctx, constants.RedisKeyServiceStats+service).Result()
+	if err != nil {
+		if err != redis.Nil {
+			l.Errorf("[System] Failed to get %s stats: %v", service, err)
+		}
+		return nil
+	}
+
+	var stats models.ServiceStats
+	if err := json.Unmarshal([]byte(data), &stats); err != nil {
+		l.Errorf("[System] Invalid %s stats: %v", service, err)
+		return nil
+	}
+	return &stats
+}
@@ -0,0 +1,86 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"sort"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetTradeReconcileLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetTradeReconcileLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetTradeReconcileLogic {
+	return &GetTradeReconcileLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetTradeReconcile 获取指定日期（默认最近一次）的撮合引擎成交对账报告，没有报告时 available 为 false
+func (l *GetTradeReconcileLogic) GetTradeReconcile(req *types.TradeReconcileRequest) (resp *types.TradeReconcileResponse, err error) {
+	empty := &types.TradeReconcileResponse{Date: req.Date, Discrepancies: []types.TradeTotalsDiscrepancy{}}
+
+	date := req.Date
+	if date == "" {
+		dates, err := l.svcCtx.Redis.HKeys(l.ctx, constants.RedisKeyTradeReconcileReport).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get trade reconcile reports: %w", err)
+		}
+		if len(dates) == 0 {
+			return empty, nil
+		}
+		sort.Strings(dates)
+		date = dates[len(dates)-1]
+	}
+
+	data, err := l.svcCtx.Redis.HGet(l.ctx, constants.RedisKeyTradeReconcileReport, date).Result()
+	if err == redis.Nil {
+		return empty, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade reconcile report: %w", err)
+	}
+
+	var report models.TradeReconcileReport
+	if err := json.Unmarshal([]byte(data), &report); err != nil {
+		return nil, fmt.Errorf("invalid trade reconcile report: %w", err)
+	}
+
+	resp = &types.TradeReconcileResponse{
+		Available:     true,
+		Date:          report.Date,
+		Source:        report.Source,
+		Symbols:       report.Symbols,
+		Matched:       report.Matched,
+		Mismatched:    report.Mismatched,
+		Errors:        report.Errors,
+		Discrepancies: make([]types.TradeTotalsDiscrepancy, 0, len(report.Discrepancies)),
+		Timestamp:     report.Timestamp,
+	}
+	for _, d := range report.Discrepancies {
+		resp.Discrepancies = append(resp.Discrepancies, types.TradeTotalsDiscrepancy{
+			Symbol:            d.Symbol,
+			Field:             d.Field,
+			EngineCount:       d.EngineCount,
+			MarketCount:       d.MarketCount,
+			EngineVolume:      d.EngineVolume,
+			MarketVolume:      d.MarketVolume,
+			EngineQuoteVolume: d.EngineQuoteVolume,
+			MarketQuoteVolume: d.MarketQuoteVolume,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,32 @@
+package udf
+
+import (
+	"context"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetUDFConfigLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetUDFConfigLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetUDFConfigLogic {
+	return &GetUDFConfigLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetUDFConfig 图表初始化时获取数据源能力，不支持搜索时图表按 /symbols 逐个解析交易对
+func (l *GetUDFConfigLogic) GetUDFConfig() (resp *types.UDFConfigResponse, err error) {
+	return &types.UDFConfigResponse{
+		SupportedResolutions: supportedResolutions,
+		SupportsTime:         true,
+	}, nil
+}
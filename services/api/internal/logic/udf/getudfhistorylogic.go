@@ -0,0 +1,109 @@
+package udf
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"strconv"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetUDFHistoryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetUDFHistoryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetUDFHistoryLogic {
+	return &GetUDFHistoryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetUDFHistory 获取 [from, to) 内的收盘K线，countback 大于 0 时改为返回 to 之前的最近 countback 根
+// 区间内没有数据时返回 no_data，并通过 nextTime 告知图表更早的K线位置
+func (l *GetUDFHistoryLogic) GetUDFHistory(req *types.UDFHistoryRequest) (resp *types.UDFHistoryResponse, err error) {
+	interval, ok := resolutions[req.Resolution]
+	if !ok {
+		return &types.UDFHistoryResponse{S: "error", Errmsg: "unsupported resolution: " + req.Resolution}, nil
+	}
+
+	symbol := symbolName(req.Symbol)
+	if l.svcCtx.Symbols.IsDeleted(symbol) {
+		return &types.UDFHistoryResponse{S: "error", Errmsg: "unknown_symbol: " + symbol}, nil
+	}
+
+	// 有序集合以开盘时间为分数，直接按区间读取
+	key := fmt.Sprintf("%s%s:%s", constants.RedisKeyKline, symbol, interval)
+	from, to := req.From*1000, req.To*1000
+	var results []string
+	if req.Countback > 0 {
+		results, err = l.svcCtx.Redis.ZRevRangeByScore(l.ctx, key, &redis.ZRangeBy{
+			Min:   "-inf",
+			Max:   "(" + strconv.FormatInt(to, 10),
+			Count: req.Countback,
+		}).Result()
+		// 倒序读取最近的 countback 根，转为升序
+		for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+			results[i], results[j] = results[j], results[i]
+		}
+	} else {
+		results, err = l.svcCtx.Redis.ZRangeByScore(l.ctx, key, &redis.ZRangeBy{
+			Min: strconv.FormatInt(from, 10),
+			Max: "(" + strconv.FormatInt(to, 10),
+		}).Result()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get klines: %w", err)
+	}
+
+	klines := make([]*models.Kline, 0, len(results))
+	for _, data := range results {
+		var kline models.Kline
+		if err := codec.Unmarshal([]byte(data), &kline); err != nil {
+			continue
+		}
+		klines = append(klines, &kline)
+	}
+
+	resp = &types.UDFHistoryResponse{S: "ok"}
+	if len(klines) == 0 {
+		resp.S = "no_data"
+		// 区间之前最近的一根K线
+		prev, err := l.svcCtx.Redis.ZRevRangeByScoreWithScores(l.ctx, key, &redis.ZRangeBy{
+			Min:   "-inf",
+			Max:   "(" + strconv.FormatInt(from, 10),
+			Count: 1,
+		}).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get klines: %w", err)
+		}
+		if len(prev) > 0 {
+			resp.NextTime = int64(prev[0].Score) / 1000
+		}
+		return resp, nil
+	}
+
+	for _, k := range klines {
+		if !l.svcCtx.Sanitizer.Kline(k.Symbol, k) {
+			continue
+		}
+		resp.T = append(resp.T, k.OpenTime/1000)
+		resp.O = append(resp.O, k.Open)
+		resp.H = append(resp.H, k.High)
+		resp.L = append(resp.L, k.Low)
+		resp.C = append(resp.C, k.Close)
+		resp.V = append(resp.V, k.Volume)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,93 @@
+package udf
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/constants"
+	"math"
+
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetUDFSymbolLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetUDFSymbolLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetUDFSymbolLogic {
+	return &GetUDFSymbolLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetUDFSymbol 获取图表需要的交易对信息，价格精度取自交易对配置的 tick_size
+func (l *GetUDFSymbolLogic) GetUDFSymbol(req *types.UDFSymbolRequest) (resp *types.UDFSymbolResponse, err error) {
+	symbol := symbolName(req.Symbol)
+
+	cfg, ok, err := lookupSymbol(l.ctx, l.svcCtx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown_symbol: %s", symbol)
+	}
+
+	resp = &types.UDFSymbolResponse{
+		Name:                 symbol,
+		Ticker:               symbol,
+		Description:          symbol,
+		Type:                 "crypto",
+		Session:              "24x7",
+		Timezone:             "Etc/UTC",
+		Exchange:             constants.ExchangeInternal,
+		ListedExchange:       constants.ExchangeInternal,
+		Minmov:               1,
+		Pricescale:           l.pricescale(symbol, 0),
+		HasIntraday:          true,
+		HasWeeklyAndMonthly:  true, // 周线、月线直接取聚合结果，不由图表从日线合成
+		SupportedResolutions: supportedResolutions,
+		VolumePrecision:      8,
+		DataStatus:           "streaming",
+	}
+
+	if cfg != nil {
+		if cfg.Description != "" {
+			resp.Description = cfg.Description
+		}
+		if cfg.Mode == constants.ModeExternalOnly && cfg.ExternalSource != "" {
+			resp.Exchange = cfg.ExternalSource
+			resp.ListedExchange = cfg.ExternalSource
+		}
+		resp.Pricescale = l.pricescale(symbol, cfg.TickSize)
+	}
+
+	return resp, nil
+}
+
+// pricescale 价格精度（10 的整数次幂，图表显示的小数位数）
+// 优先按 tick_size 计算；未配置时按最新价估算，价格越低小数位越多
+func (l *GetUDFSymbolLogic) pricescale(symbol string, tickSize float64) int64 {
+	if tickSize > 0 {
+		return int64(math.Pow10(int(math.Ceil(-math.Log10(tickSize) - 1e-9))))
+	}
+
+	var lastPrice float64
+	if val, err := l.svcCtx.Redis.HGet(l.ctx, constants.RedisKeyTicker+symbol, "last_price").Result(); err == nil {
+		fmt.Sscanf(val, "%f", &lastPrice)
+	}
+
+	switch {
+	case lastPrice >= 1000:
+		return 100
+	case lastPrice >= 1:
+		return 10000
+	default:
+		return 100000000
+	}
+}
@@ -0,0 +1,29 @@
+package udf
+
+import (
+	"context"
+	"time"
+
+	"market-system/services/api/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetUDFTimeLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetUDFTimeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetUDFTimeLogic {
+	return &GetUDFTimeLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetUDFTime 服务器时间（秒），图表用于校准本地时钟
+func (l *GetUDFTimeLogic) GetUDFTime() int64 {
+	return time.Now().Unix()
+}
@@ -0,0 +1,65 @@
+package udf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"strings"
+
+	"market-system/services/api/internal/svc"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TradingView UDF（Universal Data Feed）协议：图表组件通过 HTTP 拉取配置、交易对信息和历史K线，
+// 实时K线通过 WebSocket kline 频道推送。UDF 中的时间单位为秒。
+
+// resolutions UDF 周期与K线周期的对应关系
+var resolutions = map[string]string{
+	"1":   constants.Interval1m,
+	"5":   constants.Interval5m,
+	"15":  constants.Interval15m,
+	"60":  constants.Interval1h,
+	"240": constants.Interval4h,
+	"D":   constants.Interval1d,
+	"1D":  constants.Interval1d,
+	"W":   constants.Interval1w,
+	"1W":  constants.Interval1w,
+	"M":   constants.Interval1M,
+	"1M":  constants.Interval1M,
+}
+
+// supportedResolutions 图表可选的周期
+var supportedResolutions = []string{"1", "5", "15", "60", "240", "1D", "1W", "1M"}
+
+// symbolName 去掉图表传入的交易所前缀，例如 BINANCE:BTCUSDT
+func symbolName(symbol string) string {
+	if i := strings.LastIndex(symbol, ":"); i >= 0 {
+		symbol = symbol[i+1:]
+	}
+	return strings.ToUpper(symbol)
+}
+
+// lookupSymbol 从交易对注册表查找交易对，未注册但已有行情的交易对（仅外部数据源）返回 nil 配置
+// 停用和已软删除的交易对视为不存在
+func lookupSymbol(ctx context.Context, svcCtx *svc.ServiceContext, symbol string) (*models.SymbolConfig, bool, error) {
+	raw, err := svcCtx.Redis.HGet(ctx, constants.RedisKeySymbolConfig, symbol).Result()
+	if err == nil {
+		var cfg models.SymbolConfig
+		if err := json.Unmarshal([]byte(raw), &cfg); err != nil {
+			return nil, false, fmt.Errorf("invalid symbol config for %s: %w", symbol, err)
+		}
+		return &cfg, cfg.Enable && !cfg.Deleted, nil
+	}
+	if err != redis.Nil {
+		return nil, false, fmt.Errorf("failed to get symbol config: %w", err)
+	}
+
+	n, err := svcCtx.Redis.Exists(ctx, constants.RedisKeyTicker+symbol).Result()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to check ticker: %w", err)
+	}
+	return nil, n > 0, nil
+}
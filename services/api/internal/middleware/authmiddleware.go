@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"market-system/common/errcode"
+	"market-system/common/health"
+	"market-system/services/api/internal/config"
+	"market-system/services/api/internal/openapi"
+	"market-system/services/api/internal/response"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// 权限，admin 包含 read
+const (
+	PermissionRead  = "read"
+	PermissionAdmin = "admin"
+)
+
+// adminPrefixes 需要 admin 权限的路由
+var adminPrefixes = []string{"/api/v1/admin", "/api/v1/system"}
+
+// isPublic 存活/就绪探针和 API 文档路由，不需要认证，也不限流
+func isPublic(path string) bool {
+	switch path {
+	case health.PathLiveness, health.PathReadiness, openapi.PathSpec, openapi.PathDocs:
+		return true
+	}
+	return false
+}
+
+// Identity 请求的认证身份
+type Identity struct {
+	Name       string // API Key 名称或 JWT 的 sub，匿名访问时为空
+	Permission string
+}
+
+type identityKey struct{}
+
+// IdentityFrom 获取请求的认证身份，未开启认证时返回 false
+func IdentityFrom(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// AuthMiddleware API 认证，作用于全部路由（包括 WebSocket 升级请求）
+// 凭证为 X-API-Key 请求头、Authorization: Bearer <JWT>，或 api_key 查询参数（浏览器建立 WebSocket 连接时无法设置请求头）。
+// 凭证无效时返回 401，权限不足时返回 403，响应为统一格式
+type AuthMiddleware struct {
+	keys          map[string]Identity
+	jwtSecret     []byte
+	anonymousRead bool
+}
+
+// NewAuthMiddleware 创建认证中间件
+func NewAuthMiddleware(c config.AuthConfig) *AuthMiddleware {
+	m := &AuthMiddleware{
+		keys:          make(map[string]Identity, len(c.Keys)),
+		anonymousRead: c.AnonymousRead,
+	}
+	if c.JwtSecret != "" {
+		m.jwtSecret = []byte(c.JwtSecret)
+	}
+	for _, k := range c.Keys {
+		if k.Key == "" {
+			continue
+		}
+		name := k.Name
+		if name == "" {
+			name = k.Key[:min(8, len(k.Key))]
+		}
+		m.keys[k.Key] = Identity{Name: name, Permission: k.Permission}
+	}
+	return m
+}
+
+// Handle 校验凭证和权限，通过后将认证身份写入请求的 context
+func (m *AuthMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isPublic(r.URL.Path) {
+			next(w, r)
+			return
+		}
+		required := requiredPermission(r.URL.Path)
+
+		id, err := m.authenticate(r)
+		if err == errNoCredentials && m.anonymousRead && required == PermissionRead {
+			id, err = Identity{Permission: PermissionRead}, nil
+		}
+		if err != nil {
+			response.Error(r.Context(), w, fmt.Errorf("%w: %v", errcode.ErrUnauthorized, err))
+			return
+		}
+		if required == PermissionAdmin && id.Permission != PermissionAdmin {
+			log.Printf("[Auth] %s denied admin access to %s\n", id.Name, r.URL.Path)
+			response.Error(r.Context(), w, errcode.Newf(errcode.ErrForbidden, "admin permission required"))
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, id)))
+	}
+}
+
+var errNoCredentials = errors.New("missing credentials")
+
+// authenticate 按 API Key、JWT 的顺序校验凭证
+func (m *AuthMiddleware) authenticate(r *http.Request) (Identity, error) {
+	key := r.Header.Get("X-API-Key")
+	if key == "" {
+		key = r.URL.Query().Get("api_key")
+	}
+	return m.authenticateCredentials(key, r.Header.Get("Authorization"))
+}
+
+// authenticateCredentials 校验 API Key 或 Authorization 的值（Bearer <JWT>），REST 和 gRPC 共用
+func (m *AuthMiddleware) authenticateCredentials(key, authorization string) (Identity, error) {
+	if key != "" {
+		id, ok := m.keys[key]
+		if !ok {
+			return Identity{}, errors.New("invalid api key")
+		}
+		return id, nil
+	}
+
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || token == "" {
+		return Identity{}, errNoCredentials
+	}
+	if m.jwtSecret == nil {
+		return Identity{}, errors.New("jwt not accepted")
+	}
+	return m.parseJWT(token)
+}
+
+// parseJWT 校验 HS256 签名和过期时间，permission 声明为权限（默认 read），sub 为名称
+func (m *AuthMiddleware) parseJWT(token string) (Identity, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return m.jwtSecret, nil
+	})
+	if err != nil {
+		return Identity{}, fmt.Errorf("invalid token: %w", err)
+	}
+
+	id := Identity{Permission: PermissionRead}
+	id.Name, _ = claims["sub"].(string)
+	if permission, _ := claims["permission"].(string); permission == PermissionAdmin {
+		id.Permission = PermissionAdmin
+	}
+	return id, nil
+}
+
+// requiredPermission 路由需要的权限
+func requiredPermission(path string) string {
+	for _, prefix := range adminPrefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return PermissionAdmin
+		}
+	}
+	return PermissionRead
+}
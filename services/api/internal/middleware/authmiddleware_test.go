@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"market-system/services/api/internal/config"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	m := NewAuthMiddleware(config.AuthConfig{
+		Enable: true,
+		Keys: []config.APIKeyConfig{
+			{Key: "read-key", Name: "reader", Permission: PermissionRead},
+			{Key: "admin-key", Name: "ops", Permission: PermissionAdmin},
+		},
+		JwtSecret: "secret",
+	})
+
+	var got Identity
+	h := m.Handle(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = IdentityFrom(r.Context())
+	})
+
+	sign := func(secret string, claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + token
+	}
+	expired := jwt.MapClaims{"sub": "bot", "exp": time.Now().Add(-time.Minute).Unix()}
+
+	tests := []struct {
+		name   string
+		path   string
+		header map[string]string
+		code   int
+		who    string
+	}{
+		{"no credentials", "/api/v1/ticker/BTCUSDT", nil, http.StatusUnauthorized, ""},
+		{"readiness probe", "/readyz", nil, http.StatusOK, ""},
+		{"unknown key", "/api/v1/ticker/BTCUSDT", map[string]string{"X-API-Key": "bad"}, http.StatusUnauthorized, ""},
+		{"read key", "/api/v1/ticker/BTCUSDT", map[string]string{"X-API-Key": "read-key"}, http.StatusOK, "reader"},
+		{"query key", "/ws?api_key=read-key", nil, http.StatusOK, "reader"},
+		{"read key on admin", "/api/v1/admin/symbols", map[string]string{"X-API-Key": "read-key"}, http.StatusForbidden, ""},
+		{"admin key on admin", "/api/v1/system/health", map[string]string{"X-API-Key": "admin-key"}, http.StatusOK, "ops"},
+		{"admin prefix only", "/api/v1/administrator", map[string]string{"X-API-Key": "read-key"}, http.StatusOK, "reader"},
+		{"jwt read", "/graphql", map[string]string{"Authorization": sign("secret", jwt.MapClaims{"sub": "bot"})}, http.StatusOK, "bot"},
+		{"jwt admin", "/api/v1/admin/symbols", map[string]string{"Authorization": sign("secret", jwt.MapClaims{"sub": "bot", "permission": "admin"})}, http.StatusOK, "bot"},
+		{"jwt read on admin", "/api/v1/admin/symbols", map[string]string{"Authorization": sign("secret", jwt.MapClaims{"sub": "bot"})}, http.StatusForbidden, ""},
+		{"jwt bad signature", "/graphql", map[string]string{"Authorization": sign("other", jwt.MapClaims{"sub": "bot"})}, http.StatusUnauthorized, ""},
+		{"jwt expired", "/graphql", map[string]string{"Authorization": sign("secret", expired)}, http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		got = Identity{}
+		r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		for k, v := range tt.header {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		h(w, r)
+		if w.Code != tt.code {
+			t.Errorf("%s: code = %d, want %d", tt.name, w.Code, tt.code)
+		}
+		if got.Name != tt.who {
+			t.Errorf("%s: identity = %q, want %q", tt.name, got.Name, tt.who)
+		}
+	}
+}
+
+func TestAuthMiddlewareAnonymousRead(t *testing.T) {
+	m := NewAuthMiddleware(config.AuthConfig{Enable: true, AnonymousRead: true})
+	h := m.Handle(func(w http.ResponseWriter, r *http.Request) {})
+
+	for path, code := range map[string]int{
+		"/api/v1/ticker/BTCUSDT": http.StatusOK,
+		"/api/v1/admin/symbols":  http.StatusUnauthorized,
+	} {
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != code {
+			t.Errorf("%s: code = %d, want %d", path, w.Code, code)
+		}
+	}
+
+	// 带无效凭证时不按匿名处理
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/ticker/BTCUSDT", nil)
+	r.Header.Set("X-API-Key", "bad")
+	w := httptest.NewRecorder()
+	h(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("invalid key: code = %d", w.Code)
+	}
+}
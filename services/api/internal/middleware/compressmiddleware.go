@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// CompressMiddleware 压缩响应并处理条件请求
+// 响应带 ETag 且与请求的 If-None-Match 相同时返回 304（不带响应体）；
+// 客户端支持 gzip 且响应体不小于 minSize 时按 gzip 压缩。响应在处理完成后整体写出，WebSocket 升级请求不经过该中间件
+type CompressMiddleware struct {
+	minSize int
+}
+
+// NewCompressMiddleware 创建压缩中间件，响应体小于 minSize 字节时不压缩
+func NewCompressMiddleware(minSize int) *CompressMiddleware {
+	return &CompressMiddleware{minSize: minSize}
+}
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(nil)
+	},
+}
+
+// Handle 缓存处理结果，按请求头决定返回 304、压缩或原样写出
+func (m *CompressMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "" {
+			next(w, r)
+			return
+		}
+
+		bw := &bufferedWriter{ResponseWriter: w}
+		next(bw, r)
+		if bw.status == 0 {
+			bw.status = http.StatusOK
+		}
+
+		header := w.Header()
+		if bw.status == http.StatusOK && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+			if etag := header.Get("ETag"); etag != "" && etagMatch(r.Header.Get("If-None-Match"), etag) {
+				header.Del("Content-Type")
+				header.Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+
+		header.Add("Vary", "Accept-Encoding")
+		if bw.buf.Len() < m.minSize || header.Get("Content-Encoding") != "" || !acceptsGzip(r) {
+			w.WriteHeader(bw.status)
+			w.Write(bw.buf.Bytes())
+			return
+		}
+
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.WriteHeader(bw.status)
+		gz := gzipWriters.Get().(*gzip.Writer)
+		gz.Reset(w)
+		gz.Write(bw.buf.Bytes())
+		gz.Close()
+		gzipWriters.Put(gz)
+	}
+}
+
+// bufferedWriter 缓存处理结果的状态码和响应体，响应头直接写入原 ResponseWriter
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	buf    bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *bufferedWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.buf.Write(p)
+}
+
+// acceptsGzip 请求头 Accept-Encoding 是否包含 gzip（q=0 表示不接受）
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.TrimSpace(coding) != "gzip" {
+			continue
+		}
+		return strings.ReplaceAll(params, " ", "") != "q=0"
+	}
+	return false
+}
+
+// etagMatch If-None-Match 是否包含 etag，按弱比较（忽略 W/ 前缀）
+func etagMatch(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompressMiddleware(t *testing.T) {
+	body := strings.Repeat(`{"price":42000.5,"amount":1.25},`, 100)
+	h := NewCompressMiddleware(1024).Handle(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `W/"1700000000000"`)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, body)
+	})
+
+	do := func(header map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/depth/BTCUSDT", nil)
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		h(w, r)
+		return w
+	}
+
+	w := do(map[string]string{"Accept-Encoding": "gzip, deflate"})
+	if w.Code != http.StatusOK || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("gzip: code = %d, encoding = %q", w.Code, w.Header().Get("Content-Encoding"))
+	}
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := io.ReadAll(gz); string(got) != body {
+		t.Errorf("gzip body mismatch")
+	}
+
+	if w := do(nil); w.Header().Get("Content-Encoding") != "" || w.Body.String() != body {
+		t.Errorf("identity: encoding = %q", w.Header().Get("Content-Encoding"))
+	}
+	if w := do(map[string]string{"Accept-Encoding": "gzip;q=0"}); w.Header().Get("Content-Encoding") != "" {
+		t.Errorf("q=0: encoding = %q", w.Header().Get("Content-Encoding"))
+	}
+
+	w = do(map[string]string{"If-None-Match": `"1699999999999", W/"1700000000000"`, "Accept-Encoding": "gzip"})
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("not modified: code = %d, body = %d bytes", w.Code, w.Body.Len())
+	}
+	if w := do(map[string]string{"If-None-Match": `W/"1699999999999"`}); w.Code != http.StatusOK {
+		t.Errorf("modified: code = %d", w.Code)
+	}
+}
+
+func TestCompressMiddlewareSmallAndErrors(t *testing.T) {
+	h := NewCompressMiddleware(1024).Handle(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `W/"1"`)
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"code":40400}`)
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/ticker/BTCUSDT", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	r.Header.Set("If-None-Match", `W/"1"`)
+	w := httptest.NewRecorder()
+	h(w, r)
+	// 只有 200 响应按 ETag 返回 304，小于 minSize 的响应不压缩
+	if w.Code != http.StatusNotFound || w.Header().Get("Content-Encoding") != "" || w.Body.String() != `{"code":40400}` {
+		t.Errorf("code = %d, encoding = %q, body = %q", w.Code, w.Header().Get("Content-Encoding"), w.Body.String())
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"market-system/services/api/internal/config"
+)
+
+// CORS 默认值，AllowHeaders 包含认证和条件请求使用的请求头，ExposeHeaders 包含 ETag 和限流的 Retry-After
+var (
+	defaultCorsMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions}
+	defaultCorsHeaders = []string{"Content-Type", "Authorization", "X-API-Key", "If-None-Match"}
+	defaultCorsExpose  = []string{"ETag", "Retry-After"}
+)
+
+// CorsHeaders 按配置改写 CORS 响应头，用于 rest.WithCustomCors
+// 来源的匹配和 preflight 请求由 go-zero 处理，来源不允许时不设置响应头
+func CorsHeaders(c config.CorsConfig) func(header http.Header) {
+	methods := strings.Join(withDefault(c.AllowMethods, defaultCorsMethods), ", ")
+	headers := strings.Join(withDefault(c.AllowHeaders, defaultCorsHeaders), ", ")
+	expose := strings.Join(withDefault(c.ExposeHeaders, defaultCorsExpose), ", ")
+	maxAge := strconv.Itoa(c.MaxAge)
+
+	return func(header http.Header) {
+		if header.Get("Access-Control-Allow-Origin") == "" {
+			return
+		}
+		header.Set("Access-Control-Allow-Methods", methods)
+		header.Set("Access-Control-Allow-Headers", headers)
+		header.Set("Access-Control-Expose-Headers", expose)
+		header.Set("Access-Control-Max-Age", maxAge)
+		if c.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		} else {
+			header.Del("Access-Control-Allow-Credentials")
+		}
+	}
+}
+
+func withDefault(values, defaults []string) []string {
+	if len(values) == 0 {
+		return defaults
+	}
+	return values
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"market-system/services/api/internal/config"
+)
+
+func TestCorsHeaders(t *testing.T) {
+	// go-zero 为允许的来源设置的响应头
+	allowed := func() http.Header {
+		return http.Header{
+			"Access-Control-Allow-Origin":      {"https://app.example.com"},
+			"Access-Control-Allow-Methods":     {"GET, HEAD, POST, PATCH, PUT, DELETE"},
+			"Access-Control-Allow-Credentials": {"true"},
+			"Access-Control-Max-Age":           {"86400"},
+		}
+	}
+
+	header := allowed()
+	CorsHeaders(config.CorsConfig{MaxAge: 600})(header)
+	want := map[string]string{
+		"Access-Control-Allow-Methods":     "GET, POST, PUT, DELETE, OPTIONS",
+		"Access-Control-Allow-Headers":     "Content-Type, Authorization, X-API-Key, If-None-Match",
+		"Access-Control-Expose-Headers":    "ETag, Retry-After",
+		"Access-Control-Max-Age":           "600",
+		"Access-Control-Allow-Credentials": "",
+	}
+	for k, v := range want {
+		if got := header.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+
+	header = allowed()
+	CorsHeaders(config.CorsConfig{AllowMethods: []string{"GET"}, AllowCredentials: true, MaxAge: 60})(header)
+	if header.Get("Access-Control-Allow-Methods") != "GET" || header.Get("Access-Control-Allow-Credentials") != "true" {
+		t.Errorf("custom: %v", header)
+	}
+
+	// 来源不允许时不设置
+	header = http.Header{}
+	CorsHeaders(config.CorsConfig{MaxAge: 600})(header)
+	if len(header) != 0 {
+		t.Errorf("not allowed: %v", header)
+	}
+}
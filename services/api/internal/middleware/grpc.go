@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"math"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// gRPC 调用的凭证元数据，与 REST 的 X-API-Key、Authorization 请求头相同
+const (
+	grpcKeyAPIKey        = "x-api-key"
+	grpcKeyAuthorization = "authorization"
+	grpcKeyForwardedFor  = "x-forwarded-for"
+)
+
+// UnaryServerInterceptor gRPC 查询的认证，gRPC 服务只提供行情数据，所有方法需要 read 权限
+func (m *AuthMiddleware) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := m.authenticateGRPC(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor gRPC 订阅的认证，在建立订阅时校验一次
+func (m *AuthMiddleware) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := m.authenticateGRPC(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticateGRPC 校验元数据中的凭证，通过后将认证身份写入 context
+func (m *AuthMiddleware) authenticateGRPC(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	id, err := m.authenticateCredentials(firstValue(md, grpcKeyAPIKey), firstValue(md, grpcKeyAuthorization))
+	if err == errNoCredentials && m.anonymousRead {
+		id, err = Identity{Permission: PermissionRead}, nil
+	}
+	if err != nil {
+		log.Printf("[Auth] gRPC %s rejected: %v\n", method, err)
+		return nil, status.Errorf(codes.Unauthenticated, "unauthorized: %v", err)
+	}
+	return context.WithValue(ctx, identityKey{}, id), nil
+}
+
+// UnaryServerInterceptor gRPC 查询的限流，分组按完整方法名（如 /market.v1.MarketData/GetKlines）的最长前缀匹配
+// 超出限制时返回 ResourceExhausted，需要在认证拦截器之后注册
+func (m *RateLimitMiddleware) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := m.allowGRPC(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor gRPC 订阅的限流，按建立订阅的次数计算
+func (m *RateLimitMiddleware) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := m.allowGRPC(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// allowGRPC 认证后的调用按调用方限流，匿名调用按客户端 IP 限流
+func (m *RateLimitMiddleware) allowGRPC(ctx context.Context, method string) error {
+	group := m.match(method)
+	if group == nil {
+		return nil
+	}
+
+	l, client := group.ip, "ip:"+m.grpcClientIP(ctx)
+	if id, ok := IdentityFrom(ctx); ok && id.Name != "" {
+		l, client = group.key, "key:"+id.Name
+	}
+	if wait := l.take(client, time.Now()); wait > 0 {
+		log.Printf("[RateLimit] %s exceeded limit of %s\n", client, group.prefix)
+		return status.Errorf(codes.ResourceExhausted, "rate limited, retry after %ds", int(math.Ceil(wait.Seconds())))
+	}
+	return nil
+}
+
+// grpcClientIP 客户端 IP，开启 TrustForwardedFor 时取 x-forwarded-for 元数据的第一个地址
+func (m *RateLimitMiddleware) grpcClientIP(ctx context.Context) string {
+	if m.trustForwardedFor {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if forwarded := firstValue(md, grpcKeyForwardedFor); forwarded != "" {
+			ip, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(ip)
+		}
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// firstValue 元数据中 key 的第一个值
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// serverStream 替换 context 的 gRPC 流，订阅处理中可以获取认证身份
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
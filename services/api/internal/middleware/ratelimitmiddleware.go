@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"market-system/common/errcode"
+	"market-system/services/api/internal/config"
+	"market-system/services/api/internal/response"
+)
+
+// RateLimitMiddleware REST 接口限流（令牌桶）
+// 认证后的请求按调用方限流，匿名请求按客户端 IP 限流，需要在认证中间件之后注册
+type RateLimitMiddleware struct {
+	groups            []rateLimitGroup // 按前缀长度降序
+	trustForwardedFor bool
+}
+
+type rateLimitGroup struct {
+	prefix string
+	ip     *limiter
+	key    *limiter
+}
+
+// NewRateLimitMiddleware 创建限流中间件，Rate <= 0 的分组不限流
+func NewRateLimitMiddleware(c config.RateLimitConfig) *RateLimitMiddleware {
+	m := &RateLimitMiddleware{trustForwardedFor: c.TrustForwardedFor}
+	for _, g := range c.Groups {
+		if g.Rate <= 0 {
+			continue
+		}
+		keyRate, keyBurst := g.KeyRate, g.KeyBurst
+		if keyRate <= 0 {
+			keyRate, keyBurst = g.Rate, g.Burst
+		}
+		m.groups = append(m.groups, rateLimitGroup{
+			prefix: g.Prefix,
+			ip:     newLimiter(g.Rate, g.Burst),
+			key:    newLimiter(keyRate, keyBurst),
+		})
+	}
+	sort.SliceStable(m.groups, func(i, j int) bool {
+		return len(m.groups[i].prefix) > len(m.groups[j].prefix)
+	})
+	return m
+}
+
+// Handle 超出限制时返回 429，Retry-After 为下一个令牌可用的秒数
+func (m *RateLimitMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		group := m.match(r.URL.Path)
+		if group == nil || isPublic(r.URL.Path) {
+			next(w, r)
+			return
+		}
+
+		l, client := group.ip, "ip:"+m.clientIP(r)
+		if id, ok := IdentityFrom(r.Context()); ok && id.Name != "" {
+			l, client = group.key, "key:"+id.Name
+		}
+		if wait := l.take(client, time.Now()); wait > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			response.Error(r.Context(), w, errcode.ErrRateLimited)
+			log.Printf("[RateLimit] %s exceeded limit of %s\n", client, group.prefix)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// match 按最长前缀匹配分组
+func (m *RateLimitMiddleware) match(path string) *rateLimitGroup {
+	for i := range m.groups {
+		if strings.HasPrefix(path, m.groups[i].prefix) {
+			return &m.groups[i]
+		}
+	}
+	return nil
+}
+
+// clientIP 客户端 IP，开启 TrustForwardedFor 时取 X-Forwarded-For 的第一个地址
+func (m *RateLimitMiddleware) clientIP(r *http.Request) string {
+	if m.trustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			ip, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(ip)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// limiter 按客户端划分的令牌桶
+type limiter struct {
+	rate    float64 // 每秒补充的令牌数
+	burst   float64
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	sweepAt int // 令牌桶数量达到该值时清理已满的桶
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(rate float64, burst int) *limiter {
+	b := float64(burst)
+	if b < 1 {
+		b = math.Max(rate, 1)
+	}
+	return &limiter{
+		rate:    rate,
+		burst:   b,
+		buckets: make(map[string]*bucket),
+		sweepAt: minSweep,
+	}
+}
+
+// minSweep 令牌桶少于该数量时不清理
+const minSweep = 1024
+
+// take 取一个令牌，成功时返回 0，否则返回下一个令牌可用前的等待时间
+func (l *limiter) take(client string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[client]
+	if !ok {
+		if len(l.buckets) >= l.sweepAt {
+			l.sweep(now)
+		}
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return 0
+}
+
+// sweep 清理已补满的令牌桶（与新建的桶等价），调用方持有锁
+// 令牌桶数量达到上次清理后的两倍时才遍历，平均开销为常数
+func (l *limiter) sweep(now time.Time) {
+	for client, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+	l.sweepAt = 2 * len(l.buckets)
+	if l.sweepAt < minSweep {
+		l.sweepAt = minSweep
+	}
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"market-system/services/api/internal/config"
+)
+
+func TestLimiterTake(t *testing.T) {
+	l := newLimiter(2, 2)
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if wait := l.take("a", now); wait != 0 {
+			t.Fatalf("take %d: wait = %v", i, wait)
+		}
+	}
+	if wait := l.take("a", now); wait != 500*time.Millisecond {
+		t.Errorf("exhausted: wait = %v", wait)
+	}
+	if wait := l.take("b", now); wait != 0 {
+		t.Errorf("other client: wait = %v", wait)
+	}
+	if wait := l.take("a", now.Add(500*time.Millisecond)); wait != 0 {
+		t.Errorf("refilled: wait = %v", wait)
+	}
+}
+
+func TestLimiterSweep(t *testing.T) {
+	l := newLimiter(1, 1)
+	now := time.Now()
+	for i := 0; i < minSweep; i++ {
+		l.take(string(rune(i)), now)
+	}
+	l.take("new", now.Add(time.Second))
+	if len(l.buckets) != 1 {
+		t.Errorf("buckets = %d", len(l.buckets))
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	m := NewRateLimitMiddleware(config.RateLimitConfig{
+		Groups: []config.RateLimitGroup{
+			{Prefix: "/api", Rate: 1, KeyRate: 0.5, KeyBurst: 3},
+			{Prefix: "/api/v1/kline", Rate: 1, Burst: 2},
+		},
+	})
+	h := m.Handle(func(w http.ResponseWriter, r *http.Request) {})
+
+	do := func(path, ip string, id *Identity) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.RemoteAddr = ip + ":12345"
+		if id != nil {
+			r = r.WithContext(context.WithValue(r.Context(), identityKey{}, *id))
+		}
+		w := httptest.NewRecorder()
+		h(w, r)
+		return w
+	}
+
+	// /api/v1/kline 匹配更长的前缀，容量为 2
+	for i, code := range []int{200, 200, 429} {
+		if w := do("/api/v1/kline/BTCUSDT", "10.0.0.1", nil); w.Code != code {
+			t.Errorf("kline %d: code = %d, want %d", i, w.Code, code)
+		}
+	}
+	do("/api/v1/ticker/BTCUSDT", "10.0.0.1", nil)
+	w := do("/api/v1/ticker/BTCUSDT", "10.0.0.1", nil)
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("ticker: code = %d, Retry-After = %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	// 带凭证的请求按 API Key 限流，与 IP 无关
+	key := &Identity{Name: "mm", Permission: PermissionRead}
+	for i, code := range []int{200, 200, 200, 429} {
+		if w := do("/api/v1/ticker/BTCUSDT", "10.0.0."+string(rune('2'+i)), key); w.Code != code {
+			t.Errorf("key %d: code = %d, want %d", i, w.Code, code)
+		}
+	}
+
+	// 不匹配任何分组时不限流
+	for i := 0; i < 5; i++ {
+		if w := do("/ws", "10.0.0.1", nil); w.Code != http.StatusOK {
+			t.Fatalf("ws: code = %d", w.Code)
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/ticker/BTCUSDT", nil)
+	r.RemoteAddr = "10.0.0.1:12345"
+	r.Header.Set("X-Forwarded-For", "1.2.3.4, 10.0.0.1")
+
+	if ip := (&RateLimitMiddleware{}).clientIP(r); ip != "10.0.0.1" {
+		t.Errorf("untrusted = %s", ip)
+	}
+	if ip := (&RateLimitMiddleware{trustForwardedFor: true}).clientIP(r); ip != "1.2.3.4" {
+		t.Errorf("trusted = %s", ip)
+	}
+}
@@ -0,0 +1,406 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Document OpenAPI 3 文档
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Tags       []Tag                           `json:"tags"`
+	Paths      map[string]map[string]Operation `json:"paths"` // 路径 -> 方法 -> 操作
+	Components Components                      `json:"components"`
+	Security   []map[string][]string           `json:"security"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type Operation struct {
+	Tags        []string            `json:"tags"`
+	Summary     string              `json:"summary,omitempty"`
+	OperationID string              `json:"operationId"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// 分组说明，同时决定文档中分组的顺序
+var groupTags = []Tag{
+	{Name: "market", Description: "行情查询"},
+	{Name: "udf", Description: "TradingView UDF 数据源，响应为 UDF 格式，不使用统一响应格式"},
+	{Name: "admin", Description: "管理接口，需要 admin 权限"},
+	{Name: "system", Description: "系统运行状态，需要 admin 权限"},
+}
+
+// rawGroups 直接返回响应体、不使用统一响应格式（code/msg/data）的分组
+var rawGroups = map[string]bool{"udf": true}
+
+// baseResponse 统一响应格式的类型名
+const baseResponse = "BaseResponse"
+
+var pathParamRe = regexp.MustCompile(`:(\w+)`)
+
+// Generate 由 .api 文件生成 OpenAPI 3 文档
+func Generate(src []byte) (*Document, error) {
+	file, err := parseAPI(src)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{file: file}
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       file.info["title"],
+			Description: file.info["desc"],
+			Version:     file.info["version"],
+		},
+		Tags:  groupTags,
+		Paths: make(map[string]map[string]Operation),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+			SecuritySchemes: map[string]SecurityScheme{
+				"apiKey": {Type: "apiKey", In: "header", Name: "X-API-Key", Description: "API Key，也可以通过 api_key 查询参数传递"},
+				"bearer": {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "HS256 签名的 JWT，permission 声明为 read 或 admin"},
+			},
+		},
+		// 未开启认证时不需要凭证
+		Security: []map[string][]string{{"apiKey": {}}, {"bearer": {}}, {}},
+	}
+
+	for _, route := range file.routes {
+		op, err := g.operation(route)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", route.method, route.path, err)
+		}
+		path := pathParamRe.ReplaceAllString(route.prefix+route.path, "{$1}")
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]Operation)
+		}
+		doc.Paths[path][route.method] = op
+	}
+
+	// 只输出响应和请求体引用到的类型（包括间接引用）
+	for {
+		var pending []string
+		for name := range g.refs {
+			if _, done := doc.Components.Schemas[name]; !done {
+				pending = append(pending, name)
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+		sort.Strings(pending)
+		for _, name := range pending {
+			schema, err := g.object(file.types[name])
+			if err != nil {
+				return nil, fmt.Errorf("type %s: %w", name, err)
+			}
+			doc.Components.Schemas[name] = schema
+		}
+	}
+	return doc, nil
+}
+
+type generator struct {
+	file *apiFile
+	refs map[string]bool // 引用到的类型
+}
+
+// operation 生成单个路由的操作：path/form 字段为路径/查询参数，json 字段为请求体
+func (g *generator) operation(route apiRoute) (Operation, error) {
+	op := Operation{
+		Tags:        []string{route.group},
+		Summary:     route.doc,
+		OperationID: route.handler,
+		Responses:   make(map[string]Response),
+	}
+
+	if route.request != "" {
+		req, ok := g.file.types[route.request]
+		if !ok {
+			return op, fmt.Errorf("unknown type %s", route.request)
+		}
+		hasBody := false
+		for _, field := range req.fields {
+			if name, _, ok := tagValue(field.tag, "path"); ok {
+				op.Parameters = append(op.Parameters, Parameter{
+					Name: name, In: "path", Description: field.doc, Required: true,
+					Schema: basicSchema(field.typ),
+				})
+			} else if name, opts, ok := tagValue(field.tag, "form"); ok {
+				schema, required := g.paramSchema(field.typ, opts)
+				op.Parameters = append(op.Parameters, Parameter{
+					Name: name, In: "query", Description: field.doc, Required: required,
+					Schema: schema,
+				})
+			} else if _, _, ok := tagValue(field.tag, "json"); ok {
+				hasBody = true
+			}
+		}
+		if hasBody {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  jsonContent(g.ref(route.request)),
+			}
+		}
+	}
+
+	success := Response{Description: "成功"}
+	var data *Schema
+	if route.response != "" {
+		if _, ok := g.file.types[route.response]; !ok {
+			return op, fmt.Errorf("unknown type %s", route.response)
+		}
+		data = g.ref(route.response)
+	}
+	if rawGroups[route.group] {
+		if data != nil {
+			success.Content = jsonContent(data)
+		}
+		op.Responses[strconv.Itoa(http.StatusOK)] = success
+		return op, nil
+	}
+
+	envelope := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code": {Type: "integer", Description: "错误码，0 表示成功"},
+			"msg":  {Type: "string"},
+		},
+		Required: []string{"code", "msg"},
+	}
+	if data != nil {
+		envelope.Properties["data"] = data
+	}
+	success.Content = jsonContent(envelope)
+	op.Responses[strconv.Itoa(http.StatusOK)] = success
+	op.Responses["default"] = Response{
+		Description: "错误，HTTP 状态码与错误码对应（400 参数错误、401 未认证、403 无权限、404 不存在、429 限流、500 内部错误、503 数据过期）",
+		Content:     jsonContent(g.ref(baseResponse)),
+	}
+	return op, nil
+}
+
+// paramSchema 查询参数的类型、默认值和可选值，没有 optional 和默认值时为必填
+func (g *generator) paramSchema(typ string, opts []string) (*Schema, bool) {
+	schema := basicSchema(typ)
+	_, optional := tagOption(opts, "optional")
+	if def, ok := tagOption(opts, "default"); ok {
+		schema.Default = parseValue(schema.Type, def)
+		optional = true
+	}
+	if options, ok := tagOption(opts, "options"); ok {
+		for _, option := range strings.Split(options, "|") {
+			schema.Enum = append(schema.Enum, parseValue(schema.Type, option))
+		}
+	}
+	return schema, !optional
+}
+
+// object 生成类型的对象结构，只包含 json 字段
+func (g *generator) object(t *apiType) (*Schema, error) {
+	schema := &Schema{
+		Type:        "object",
+		Description: t.doc,
+		Properties:  make(map[string]*Schema),
+	}
+	for _, field := range t.fields {
+		name, opts, ok := tagValue(field.tag, "json")
+		if !ok || name == "-" {
+			continue
+		}
+		prop, err := g.schema(field.typ)
+		if err != nil {
+			return nil, err
+		}
+		// $ref 的同级属性会被忽略，引用类型的字段不输出说明
+		if field.doc != "" && prop.Ref == "" {
+			prop.Description = field.doc
+		}
+		if def, ok := tagOption(opts, "default"); ok {
+			prop.Default = parseValue(prop.Type, def)
+		}
+		if options, ok := tagOption(opts, "options"); ok {
+			for _, option := range strings.Split(options, "|") {
+				prop.Enum = append(prop.Enum, parseValue(prop.Type, option))
+			}
+		}
+		schema.Properties[name] = prop
+
+		_, optional := tagOption(opts, "optional")
+		_, omitempty := tagOption(opts, "omitempty")
+		_, hasDefault := tagOption(opts, "default")
+		if !optional && !omitempty && !hasDefault {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema, nil
+}
+
+// schema 字段类型对应的结构，支持指针、切片、map[string]T、基本类型和 .api 中定义的类型
+func (g *generator) schema(typ string) (*Schema, error) {
+	switch {
+	case strings.HasPrefix(typ, "*"):
+		s, err := g.schema(typ[1:])
+		if err != nil {
+			return nil, err
+		}
+		if s.Ref == "" {
+			s.Nullable = true
+		}
+		return s, nil
+	case strings.HasPrefix(typ, "[]"):
+		items, err := g.schema(typ[2:])
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case strings.HasPrefix(typ, "map[string]"):
+		values, err := g.schema(strings.TrimPrefix(typ, "map[string]"))
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case typ == "interface{}":
+		return &Schema{}, nil
+	}
+	if s := basicSchema(typ); s.Type != "" {
+		return s, nil
+	}
+	if _, ok := g.file.types[typ]; !ok {
+		return nil, fmt.Errorf("unknown type %s", typ)
+	}
+	return g.ref(typ), nil
+}
+
+// ref 引用 components 中的类型
+func (g *generator) ref(name string) *Schema {
+	if g.refs == nil {
+		g.refs = make(map[string]bool)
+	}
+	g.refs[name] = true
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// basicSchema 基本类型对应的结构，其他类型返回空结构
+func basicSchema(typ string) *Schema {
+	switch typ {
+	case "string":
+		return &Schema{Type: "string"}
+	case "bool":
+		return &Schema{Type: "boolean"}
+	case "int", "int32":
+		return &Schema{Type: "integer", Format: "int32"}
+	case "int64":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "float32":
+		return &Schema{Type: "number", Format: "float"}
+	case "float64":
+		return &Schema{Type: "number", Format: "double"}
+	}
+	return &Schema{}
+}
+
+// parseValue 按类型解析标签中的默认值和可选值
+func parseValue(typ, value string) interface{} {
+	switch typ {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
+
+// Marshal 输出格式化的 JSON 文档（以换行结尾）
+func Marshal(doc *Document) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
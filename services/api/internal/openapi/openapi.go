@@ -0,0 +1,47 @@
+// Package openapi 由 market.api 中的路由和类型定义生成 OpenAPI 3 文档，并提供文档和 Swagger UI 接口
+//
+// 修改 market.api 后执行 go generate ./internal/openapi 重新生成 openapi.json
+package openapi
+
+//go:generate go run ../../cmd/openapi -api ../../market.api -out openapi.json
+
+import (
+	_ "embed"
+	"html/template"
+	"net/http"
+)
+
+// 文档路由
+const (
+	PathSpec = "/api/v1/openapi.json"
+	PathDocs = "/api/v1/docs"
+)
+
+// DefaultSwaggerAssets Swagger UI 静态资源的默认地址，内网部署时可配置为内部镜像
+const DefaultSwaggerAssets = "https://unpkg.com/swagger-ui-dist@5"
+
+//go:embed openapi.json
+var spec []byte
+
+//go:embed swagger.html
+var swaggerHTML string
+
+var swaggerTemplate = template.Must(template.New("swagger").Parse(swaggerHTML))
+
+// SpecHandler 返回 OpenAPI 文档
+func SpecHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(spec)
+}
+
+// SwaggerUIHandler 返回加载 OpenAPI 文档的 Swagger UI 页面，assets 为空时使用 DefaultSwaggerAssets
+func SwaggerUIHandler(assets string) http.HandlerFunc {
+	if assets == "" {
+		assets = DefaultSwaggerAssets
+	}
+	data := struct{ Assets, Spec string }{assets, PathSpec}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		swaggerTemplate.Execute(w, data)
+	}
+}
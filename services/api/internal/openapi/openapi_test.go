@@ -0,0 +1,105 @@
+package openapi
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// TestSpecUpToDate 内嵌的 openapi.json 与 market.api 一致，修改 market.api 后需要重新生成
+func TestSpecUpToDate(t *testing.T) {
+	src, err := os.ReadFile("../../market.api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Generate(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, spec) {
+		t.Fatal("openapi.json is out of date, run go generate ./internal/openapi")
+	}
+
+	op := doc.Paths["/api/v1/ticker/{symbol}"]["get"]
+	if op.OperationID != "GetTicker" || len(op.Parameters) != 1 || op.Parameters[0].In != "path" {
+		t.Errorf("GetTicker = %+v", op)
+	}
+	data200 := op.Responses["200"].Content["application/json"].Schema.Properties["data"]
+	if data200 == nil || data200.Ref != "#/components/schemas/TickerResponse" {
+		t.Errorf("GetTicker data = %+v", data200)
+	}
+	if _, ok := doc.Components.Schemas["TickerResponse"]; !ok {
+		t.Error("TickerResponse schema missing")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	src := []byte(`syntax = "v1"
+
+info(
+	title: "test"
+	version: "v1"
+)
+
+type (
+	// 查询
+	ListRequest {
+		Symbol string ` + "`path:\"symbol\"`" + `
+		Limit  int    ` + "`form:\"limit,default=20\"`" + ` // 最多 100
+		Side   string ` + "`form:\"side,optional,options=buy|sell\"`" + `
+	}
+
+	ListResponse {
+		Items []string          ` + "`json:\"items\"`" + `
+		Extra map[string]*Item  ` + "`json:\"extra,omitempty\"`" + `
+	}
+
+	Item {
+		Price float64 ` + "`json:\"price\"`" + `
+	}
+)
+
+@server(
+	prefix: /api/v1/udf
+	group: udf
+)
+service market-api {
+	@doc "列表"
+	@handler List
+	get /list/:symbol (ListRequest) returns (ListResponse)
+}
+`)
+	doc, err := Generate(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	op := doc.Paths["/api/v1/udf/list/{symbol}"]["get"]
+	if len(op.Parameters) != 3 {
+		t.Fatalf("parameters = %+v", op.Parameters)
+	}
+	if limit := op.Parameters[1]; limit.Required || limit.Schema.Default != int64(20) || limit.Description != "最多 100" {
+		t.Errorf("limit = %+v", limit)
+	}
+	if side := op.Parameters[2]; side.Required || len(side.Schema.Enum) != 2 {
+		t.Errorf("side = %+v", side)
+	}
+	if ref := op.Responses["200"].Content["application/json"].Schema.Ref; ref != "#/components/schemas/ListResponse" {
+		t.Errorf("udf response should not be wrapped, got %q", ref)
+	}
+	list := doc.Components.Schemas["ListResponse"]
+	if len(list.Required) != 1 || list.Required[0] != "items" {
+		t.Errorf("required = %v", list.Required)
+	}
+	if _, ok := doc.Components.Schemas["Item"]; !ok {
+		t.Error("indirectly referenced Item schema missing")
+	}
+
+	if _, err := Generate([]byte("type (\n\tBad {\n\t\tX unknown\n\t}\n)\n")); err == nil {
+		t.Error("expected parse error")
+	}
+}
@@ -0,0 +1,189 @@
+package openapi
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// apiFile market.api 中的接口信息、类型和路由
+type apiFile struct {
+	info   map[string]string
+	types  map[string]*apiType
+	routes []apiRoute
+}
+
+type apiType struct {
+	name   string
+	doc    string
+	fields []apiField
+}
+
+type apiField struct {
+	name string
+	typ  string
+	tag  reflect.StructTag
+	doc  string
+}
+
+type apiRoute struct {
+	group    string
+	prefix   string
+	doc      string
+	handler  string
+	method   string
+	path     string
+	request  string
+	response string
+}
+
+var (
+	keyValueRe = regexp.MustCompile(`^(\w+):\s*"?(.*?)"?$`)
+	typeRe     = regexp.MustCompile(`^(\w+)\s*\{$`)
+	fieldRe    = regexp.MustCompile("^(\\w+)\\s+(\\S+)\\s+`([^`]*)`\\s*(?://\\s*(.*))?$")
+	docRe      = regexp.MustCompile(`^@doc\s+"(.*)"$`)
+	handlerRe  = regexp.MustCompile(`^@handler\s+(\w+)$`)
+	routeRe    = regexp.MustCompile(`^(get|post|put|delete|patch|head)\s+(\S+)(?:\s+\((\w+)\))?(?:\s+returns\s+\((\w+)\))?$`)
+)
+
+// parseAPI 解析 goctl 的 .api 文件，只支持本仓库用到的语法：info、type 组、@server 和 service 块
+func parseAPI(src []byte) (*apiFile, error) {
+	file := &apiFile{
+		info:  make(map[string]string),
+		types: make(map[string]*apiType),
+	}
+
+	const (
+		top = iota
+		inInfo
+		inTypes
+		inType
+		inServer
+		inService
+	)
+	state := top
+	var (
+		doc     []string // 类型前的注释
+		current *apiType
+		server  map[string]string
+		route   apiRoute
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		switch state {
+		case top:
+			switch {
+			case strings.HasPrefix(line, "info("):
+				state = inInfo
+			case line == "type (":
+				state = inTypes
+			case strings.HasPrefix(line, "@server("):
+				server = make(map[string]string)
+				state = inServer
+			case strings.HasPrefix(line, "service ") && strings.HasSuffix(line, "{"):
+				route = apiRoute{group: server["group"], prefix: server["prefix"]}
+				state = inService
+			}
+
+		case inInfo:
+			if line == ")" {
+				state = top
+			} else if m := keyValueRe.FindStringSubmatch(line); m != nil {
+				file.info[m[1]] = m[2]
+			}
+
+		case inTypes:
+			switch {
+			case line == ")":
+				state = top
+			case strings.HasPrefix(line, "//"):
+				doc = append(doc, strings.TrimSpace(strings.TrimPrefix(line, "//")))
+			default:
+				m := typeRe.FindStringSubmatch(line)
+				if m == nil {
+					return nil, fmt.Errorf("line %d: unexpected %q", lineNo, line)
+				}
+				current = &apiType{name: m[1], doc: strings.Join(doc, "\n")}
+				doc = nil
+				state = inType
+			}
+
+		case inType:
+			switch {
+			case line == "}":
+				file.types[current.name] = current
+				state = inTypes
+			case strings.HasPrefix(line, "//"):
+			default:
+				m := fieldRe.FindStringSubmatch(line)
+				if m == nil {
+					return nil, fmt.Errorf("line %d: unsupported field %q", lineNo, line)
+				}
+				current.fields = append(current.fields, apiField{name: m[1], typ: m[2], tag: reflect.StructTag(m[3]), doc: m[4]})
+			}
+
+		case inServer:
+			if line == ")" {
+				state = top
+			} else if m := keyValueRe.FindStringSubmatch(line); m != nil {
+				server[m[1]] = m[2]
+			}
+
+		case inService:
+			if line == "}" {
+				state = top
+				continue
+			}
+			if m := docRe.FindStringSubmatch(line); m != nil {
+				route.doc = m[1]
+			} else if m := handlerRe.FindStringSubmatch(line); m != nil {
+				route.handler = m[1]
+			} else if m := routeRe.FindStringSubmatch(line); m != nil {
+				route.method, route.path, route.request, route.response = m[1], m[2], m[3], m[4]
+				file.routes = append(file.routes, route)
+				route = apiRoute{group: route.group, prefix: route.prefix}
+			} else {
+				return nil, fmt.Errorf("line %d: unexpected %q", lineNo, line)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if state != top {
+		return nil, fmt.Errorf("unexpected end of file")
+	}
+	return file, nil
+}
+
+// tagValue 解析字段标签，返回名称和选项（如 optional、default=1m、options=asc|desc）
+func tagValue(tag reflect.StructTag, key string) (name string, opts []string, ok bool) {
+	value, ok := tag.Lookup(key)
+	if !ok {
+		return "", nil, false
+	}
+	parts := strings.Split(value, ",")
+	return parts[0], parts[1:], true
+}
+
+// tagOption 查找标签选项，key=value 形式的选项返回 value
+func tagOption(opts []string, key string) (string, bool) {
+	for _, opt := range opts {
+		if opt == key {
+			return "", true
+		}
+		if strings.HasPrefix(opt, key+"=") {
+			return strings.TrimPrefix(opt, key+"="), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,136 @@
+package pricealert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// MaxPerOwner 每个认证身份最多创建的提醒数
+const MaxPerOwner = 100
+
+// Store 用户价格提醒
+// 提醒保存在 price_alert Hash，变更后通过 price_alert:update 频道通知 Processor 重新加载。
+// REST 接口和 WebSocket alert.subscribe 共用，提醒只对创建者可见。
+type Store struct {
+	client       *redis.Client
+	webhookHosts []string // 允许的 Webhook 主机，为空时允许全部公网地址
+}
+
+// NewStore 创建价格提醒存储
+func NewStore(client *redis.Client, webhookHosts []string) *Store {
+	return &Store{client: client, webhookHosts: webhookHosts}
+}
+
+// Validate 检查提醒的交易对、条件和 Webhook 地址
+// Webhook 由 Processor 发送，主机须在 webhookHosts 中，且不能解析为回环、链路本地或私有地址
+func Validate(ctx context.Context, alert *models.PriceAlert, webhookHosts []string) error {
+	if err := utils.ValidateSymbol(alert.Symbol); err != nil {
+		return err
+	}
+	switch alert.Condition {
+	case constants.PriceAlertCrossUp, constants.PriceAlertCrossDown:
+		if alert.Value <= 0 {
+			return errcode.Newf(errcode.ErrInvalidParam, "price must be positive: %v", alert.Value)
+		}
+	case constants.PriceAlertChangeAbove, constants.PriceAlertChangeBelow:
+	default:
+		return errcode.Newf(errcode.ErrInvalidParam, "invalid condition: %s", alert.Condition)
+	}
+	if alert.Webhook != "" {
+		return utils.ValidateWebhookURL(ctx, alert.Webhook, webhookHosts)
+	}
+	return nil
+}
+
+// Create 检查并保存提醒，生成 ID 和创建时间
+func (s *Store) Create(ctx context.Context, alert *models.PriceAlert) error {
+	alert.Symbol = strings.ToUpper(alert.Symbol)
+	if err := Validate(ctx, alert, s.webhookHosts); err != nil {
+		return err
+	}
+
+	owned, err := s.List(ctx, alert.Owner, "")
+	if err != nil {
+		return err
+	}
+	if len(owned) >= MaxPerOwner {
+		return errcode.Newf(errcode.ErrInvalidParam, "too many price alerts, max %d", MaxPerOwner)
+	}
+
+	alert.ID = uuid.New().String()
+	alert.CreatedAt = utils.GetCurrentTimestamp()
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	if err := s.client.HSet(ctx, constants.RedisKeyPriceAlert, alert.ID, data).Err(); err != nil {
+		return fmt.Errorf("failed to save price alert: %w", err)
+	}
+	return s.notify(ctx, alert.ID)
+}
+
+// List 获取创建者的提醒，symbol 为空时返回全部交易对，按创建时间排序
+func (s *Store) List(ctx context.Context, owner, symbol string) ([]*models.PriceAlert, error) {
+	data, err := s.client.HGetAll(ctx, constants.RedisKeyPriceAlert).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price alerts: %w", err)
+	}
+
+	alerts := make([]*models.PriceAlert, 0)
+	for id, raw := range data {
+		var alert models.PriceAlert
+		if err := json.Unmarshal([]byte(raw), &alert); err != nil {
+			continue
+		}
+		if alert.Owner != owner || (symbol != "" && alert.Symbol != symbol) {
+			continue
+		}
+		alert.ID = id
+		alerts = append(alerts, &alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].CreatedAt != alerts[j].CreatedAt {
+			return alerts[i].CreatedAt < alerts[j].CreatedAt
+		}
+		return alerts[i].ID < alerts[j].ID
+	})
+	return alerts, nil
+}
+
+// Delete 删除创建者的提醒，不存在或不属于创建者时返回 ErrNotFound
+func (s *Store) Delete(ctx context.Context, owner, id string) error {
+	raw, err := s.client.HGet(ctx, constants.RedisKeyPriceAlert, id).Result()
+	if err == redis.Nil {
+		return errcode.Newf(errcode.ErrNotFound, "price alert not found: %s", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get price alert: %w", err)
+	}
+	var alert models.PriceAlert
+	if err := json.Unmarshal([]byte(raw), &alert); err == nil && alert.Owner != owner {
+		return errcode.Newf(errcode.ErrNotFound, "price alert not found: %s", id)
+	}
+
+	if err := s.client.HDel(ctx, constants.RedisKeyPriceAlert, id).Err(); err != nil {
+		return fmt.Errorf("failed to delete price alert: %w", err)
+	}
+	return s.notify(ctx, id)
+}
+
+// notify 通知 Processor 重新加载提醒
+func (s *Store) notify(ctx context.Context, id string) error {
+	if err := s.client.Publish(ctx, constants.RedisChannelPriceAlert, id).Err(); err != nil {
+		return fmt.Errorf("failed to notify price alert update: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package readcache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrLoadPanic 读取时 panic，等待同一次读取的请求返回该错误
+var ErrLoadPanic = errors.New("readcache: load panicked")
+
+// Cache 短时本地读缓存，流量高峰时减少对 Redis 的重复读取
+// 缓存期内同一个 key 只读取一次，并发的未命中合并为一次读取；读取失败的结果不缓存。
+// 缓存的值在多个请求间共享，调用方不能修改。nil 表示不缓存，Get 直接读取
+type Cache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]*entry
+	sweepAt int // 缓存项数量达到该值时清理过期项
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// entry 缓存项，ready 关闭前正在读取
+type entry struct {
+	ready    chan struct{}
+	value    interface{}
+	err      error
+	expireAt time.Time
+}
+
+// Stats 缓存命中统计（累计）
+type Stats struct {
+	Hits   int64
+	Misses int64
+}
+
+// New 创建读缓存，ttl <= 0 时返回 nil（不缓存）
+func New(ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &Cache{
+		ttl:     ttl,
+		entries: make(map[string]*entry),
+		sweepAt: minSweep,
+	}
+}
+
+// Get 获取 key 的缓存值，未命中或已过期时调用 load 读取
+// 等待其他请求正在进行的读取计为命中
+func (c *Cache) Get(key string, load func() (interface{}, error)) (interface{}, error) {
+	if c == nil {
+		return load()
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		select {
+		case <-e.ready:
+			if now.Before(e.expireAt) {
+				c.mu.Unlock()
+				c.hits.Add(1)
+				return e.value, nil
+			}
+		default:
+			c.mu.Unlock()
+			c.hits.Add(1)
+			<-e.ready
+			return e.value, e.err
+		}
+	}
+	e := &entry{ready: make(chan struct{})}
+	c.entries[key] = e
+	c.mu.Unlock()
+	c.misses.Add(1)
+
+	// load panic 时同样结束读取，等待中的请求返回错误，不会永久阻塞；panic 继续向上抛出
+	defer func() {
+		if r := recover(); r != nil {
+			e.value, e.err = nil, fmt.Errorf("%w: %s: %v", ErrLoadPanic, key, r)
+			c.finish(key, e, now)
+			panic(r)
+		}
+	}()
+	e.value, e.err = load()
+	e.expireAt = time.Now().Add(c.ttl)
+	c.finish(key, e, now)
+	return e.value, e.err
+}
+
+// finish 结束读取并通知等待的请求，读取失败的结果不缓存
+func (c *Cache) finish(key string, e *entry, now time.Time) {
+	c.mu.Lock()
+	if e.err != nil {
+		delete(c.entries, key)
+	} else if len(c.entries) >= c.sweepAt {
+		c.sweep(now)
+	}
+	close(e.ready)
+	c.mu.Unlock()
+}
+
+// minSweep 缓存项少于该数量时不清理
+const minSweep = 64
+
+// sweep 清理已过期的缓存项（交易对下线后对应的 key 不再读取），调用方持有锁
+// 缓存项数量达到上次清理后的两倍时才遍历，平均开销为常数
+func (c *Cache) sweep(now time.Time) {
+	for key, e := range c.entries {
+		select {
+		case <-e.ready:
+			if !now.Before(e.expireAt) {
+				delete(c.entries, key)
+			}
+		default:
+		}
+	}
+	c.sweepAt = 2 * len(c.entries)
+	if c.sweepAt < minSweep {
+		c.sweepAt = minSweep
+	}
+}
+
+// Stats 获取命中统计，未开启缓存时返回零值
+func (c *Cache) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
@@ -0,0 +1,123 @@
+package readcache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheHitAndExpire(t *testing.T) {
+	c := New(50 * time.Millisecond)
+	loads := 0
+	load := func() (interface{}, error) {
+		loads++
+		return loads, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if v, _ := c.Get("ticker:BTCUSDT", load); v != 1 {
+			t.Fatalf("get %d = %v", i, v)
+		}
+	}
+	time.Sleep(60 * time.Millisecond)
+	if v, _ := c.Get("ticker:BTCUSDT", load); v != 2 {
+		t.Errorf("after expire = %v", v)
+	}
+	if stats := c.Stats(); stats.Hits != 2 || stats.Misses != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestCacheErrorNotCached(t *testing.T) {
+	c := New(time.Minute)
+	if _, err := c.Get("k", func() (interface{}, error) { return nil, errors.New("down") }); err == nil {
+		t.Fatal("expected error")
+	}
+	if v, err := c.Get("k", func() (interface{}, error) { return "ok", nil }); err != nil || v != "ok" {
+		t.Errorf("got %v, %v", v, err)
+	}
+}
+
+func TestCacheCoalesce(t *testing.T) {
+	c := New(time.Minute)
+	var loads atomic.Int64
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, _ := c.Get("depth:BTCUSDT", func() (interface{}, error) {
+				loads.Add(1)
+				<-release
+				return "depth", nil
+			})
+			if v != "depth" {
+				t.Errorf("got %v", v)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if loads.Load() != 1 {
+		t.Errorf("loads = %d", loads.Load())
+	}
+}
+
+func TestCacheLoadPanic(t *testing.T) {
+	c := New(time.Minute)
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	panicked := make(chan interface{}, 1)
+	go func() {
+		defer func() { panicked <- recover() }()
+		c.Get("ticker:BTCUSDT", func() (interface{}, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+	<-started
+
+	// 等待中的请求在读取 panic 后返回错误，不会永久阻塞
+	waited := make(chan error, 1)
+	go func() {
+		_, err := c.Get("ticker:BTCUSDT", func() (interface{}, error) { return "unexpected", nil })
+		waited <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	if r := <-panicked; r != "boom" {
+		t.Errorf("recovered = %v, want boom", r)
+	}
+	select {
+	case err := <-waited:
+		if !errors.Is(err, ErrLoadPanic) {
+			t.Errorf("waiter err = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter blocked after load panic")
+	}
+
+	// panic 的结果不缓存，之后重新读取
+	if v, err := c.Get("ticker:BTCUSDT", func() (interface{}, error) { return "ok", nil }); err != nil || v != "ok" {
+		t.Errorf("got %v, %v", v, err)
+	}
+}
+
+func TestNilCache(t *testing.T) {
+	c := New(0)
+	if v, _ := c.Get("k", func() (interface{}, error) { return 1, nil }); v != 1 {
+		t.Errorf("got %v", v)
+	}
+	if c.Stats() != (Stats{}) {
+		t.Errorf("stats = %+v", c.Stats())
+	}
+}
@@ -0,0 +1,167 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"sort"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Groups 交易对分组（本地缓存）
+// 分组由管理接口写入 symbol_group Hash，并通过 symbol_group:update 频道通知各 API 实例。
+// WebSocket 据此将交易对的行情同时推送到所属分组的频道，REST 接口按分组过滤交易对。
+type Groups struct {
+	client *redis.Client
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.RWMutex
+	groups   map[string]*models.SymbolGroup
+	bySymbol map[string][]string // 交易对所属的分组，按名称排序
+}
+
+// NewGroups 创建交易对分组缓存
+func NewGroups(client *redis.Client) *Groups {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Groups{
+		client:   client,
+		ctx:      ctx,
+		cancel:   cancel,
+		groups:   make(map[string]*models.SymbolGroup),
+		bySymbol: make(map[string][]string),
+	}
+}
+
+// Start 加载全部分组，并开始监听变更
+func (g *Groups) Start() error {
+	// 先订阅再加载，避免加载期间的变更丢失
+	pubsub := g.client.Subscribe(g.ctx, constants.RedisChannelSymbolGroup)
+	if _, err := pubsub.Receive(g.ctx); err != nil {
+		pubsub.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	data, err := g.client.HGetAll(g.ctx, constants.RedisKeySymbolGroup).Result()
+	if err != nil {
+		pubsub.Close()
+		return fmt.Errorf("failed to load symbol groups: %w", err)
+	}
+
+	g.mu.Lock()
+	for name, raw := range data {
+		var group models.SymbolGroup
+		if err := json.Unmarshal([]byte(raw), &group); err != nil {
+			log.Printf("[Groups] Invalid group %s: %v\n", name, err)
+			continue
+		}
+		g.groups[name] = &group
+	}
+	g.rebuild()
+	count := len(g.groups)
+	g.mu.Unlock()
+
+	go g.watch(pubsub)
+
+	log.Printf("[Groups] Loaded %d symbol groups\n", count)
+	return nil
+}
+
+// Stop 停止监听
+func (g *Groups) Stop() {
+	g.cancel()
+}
+
+// GroupsOf 交易对所属的分组
+func (g *Groups) GroupsOf(symbol string) []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.bySymbol[symbol]
+}
+
+// Get 获取分组，不存在时返回 nil
+func (g *Groups) Get(name string) *models.SymbolGroup {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.groups[name]
+}
+
+// List 获取全部分组，按名称排序
+func (g *Groups) List() []*models.SymbolGroup {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	groups := make([]*models.SymbolGroup, 0, len(g.groups))
+	for _, group := range g.groups {
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// watch 处理分组变更通知
+func (g *Groups) watch(pubsub *redis.PubSub) {
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-g.ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				log.Println("[Groups] Channel closed")
+				return
+			}
+			g.reload(msg.Payload)
+		}
+	}
+}
+
+// reload 重新加载单个分组，分组不存在时删除
+func (g *Groups) reload(name string) {
+	var group *models.SymbolGroup
+	raw, err := g.client.HGet(g.ctx, constants.RedisKeySymbolGroup, name).Result()
+	switch {
+	case err == redis.Nil:
+	case err != nil:
+		log.Printf("[Groups] Failed to reload %s: %v\n", name, err)
+		return
+	default:
+		group = &models.SymbolGroup{}
+		if err := json.Unmarshal([]byte(raw), group); err != nil {
+			log.Printf("[Groups] Invalid group %s: %v\n", name, err)
+			return
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if group != nil {
+		g.groups[name] = group
+	} else {
+		delete(g.groups, name)
+	}
+	g.rebuild()
+}
+
+// rebuild 重建交易对到分组的索引，调用方持有写锁
+func (g *Groups) rebuild() {
+	bySymbol := make(map[string][]string)
+	for name, group := range g.groups {
+		for _, symbol := range group.Symbols {
+			bySymbol[symbol] = append(bySymbol[symbol], name)
+		}
+	}
+	for _, names := range bySymbol {
+		sort.Strings(names)
+	}
+	g.bySymbol = bySymbol
+}
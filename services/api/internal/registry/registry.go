@@ -0,0 +1,137 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrSymbolNotFound 交易对已软删除，对外视为不存在
+var ErrSymbolNotFound = errcode.ErrSymbolNotFound
+
+// Registry 交易对注册表中已软删除的交易对（本地缓存）
+// 软删除的交易对在 REST 接口中视为不存在，WebSocket 不允许订阅也不再推送其行情，已有数据保留。
+// 注册表由管理接口写入 symbol_config Hash，并通过 symbol_config:update 频道通知各 API 实例。
+type Registry struct {
+	client *redis.Client
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.RWMutex
+	deleted map[string]bool
+}
+
+// NewRegistry 创建交易对注册表缓存
+func NewRegistry(client *redis.Client) *Registry {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Registry{
+		client:  client,
+		ctx:     ctx,
+		cancel:  cancel,
+		deleted: make(map[string]bool),
+	}
+}
+
+// Start 加载已软删除的交易对，并开始监听变更
+func (r *Registry) Start() error {
+	// 先订阅再加载，避免加载期间的变更丢失
+	pubsub := r.client.Subscribe(r.ctx, constants.RedisChannelSymbolConfig)
+	if _, err := pubsub.Receive(r.ctx); err != nil {
+		pubsub.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	data, err := r.client.HGetAll(r.ctx, constants.RedisKeySymbolConfig).Result()
+	if err != nil {
+		pubsub.Close()
+		return fmt.Errorf("failed to load symbol configs: %w", err)
+	}
+
+	r.mu.Lock()
+	for symbol, raw := range data {
+		var cfg models.SymbolConfig
+		if err := json.Unmarshal([]byte(raw), &cfg); err == nil && cfg.Deleted {
+			r.deleted[symbol] = true
+		}
+	}
+	count := len(r.deleted)
+	r.mu.Unlock()
+
+	go r.watch(pubsub)
+
+	log.Printf("[Registry] Loaded %d deleted symbols\n", count)
+	return nil
+}
+
+// Stop 停止监听
+func (r *Registry) Stop() {
+	r.cancel()
+}
+
+// IsDeleted 交易对是否已软删除
+func (r *Registry) IsDeleted(symbol string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.deleted[symbol]
+}
+
+// Check 交易对已软删除时返回 ErrSymbolNotFound
+func (r *Registry) Check(symbol string) error {
+	if r.IsDeleted(symbol) {
+		return fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+	return nil
+}
+
+// watch 处理配置变更通知
+func (r *Registry) watch(pubsub *redis.PubSub) {
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				log.Println("[Registry] Channel closed")
+				return
+			}
+			r.reload(msg.Payload)
+		}
+	}
+}
+
+// reload 重新加载单个交易对的软删除状态，配置不存在时视为未删除
+func (r *Registry) reload(symbol string) {
+	deleted := false
+	raw, err := r.client.HGet(r.ctx, constants.RedisKeySymbolConfig, symbol).Result()
+	switch {
+	case err == redis.Nil:
+	case err != nil:
+		log.Printf("[Registry] Failed to reload %s: %v\n", symbol, err)
+		return
+	default:
+		var cfg models.SymbolConfig
+		if err := json.Unmarshal([]byte(raw), &cfg); err != nil {
+			log.Printf("[Registry] Invalid config for %s: %v\n", symbol, err)
+			return
+		}
+		deleted = cfg.Deleted
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if deleted {
+		r.deleted[symbol] = true
+	} else {
+		delete(r.deleted, symbol)
+	}
+}
@@ -0,0 +1,54 @@
+package response
+
+import (
+	"context"
+	"net/http"
+
+	"market-system/common/errcode"
+	"market-system/services/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// REST 接口（/api/v1）的统一响应：{"code": 0, "msg": "success", "data": ...}
+// 出错时按错误码返回对应的 HTTP 状态码，code 为 common/errcode 中的错误码；
+// UDF、Binance 兼容接口和 GraphQL 按各自协议的格式返回，不使用统一响应
+
+// versioned 带数据版本的响应（行情数据），见 types.DataVersion
+type versioned interface {
+	DataVersion() string
+}
+
+// Write 写入处理结果，err 不为 nil 时写入错误
+// 行情数据按数据版本设置 ETag，客户端带 If-None-Match 请求且数据未更新时由 CompressMiddleware 返回 304
+func Write(ctx context.Context, w http.ResponseWriter, resp interface{}, err error) {
+	if err != nil {
+		Error(ctx, w, err)
+		return
+	}
+	if v, ok := resp.(versioned); ok {
+		w.Header().Set("ETag", `W/"`+v.DataVersion()+`"`)
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	httpx.OkJsonCtx(ctx, w, &types.BaseResponse{Code: errcode.CodeOK, Msg: "success", Data: resp})
+}
+
+// Error 写入错误，内部错误只返回通用信息，详细原因写入日志
+func Error(ctx context.Context, w http.ResponseWriter, err error) {
+	e := errcode.From(err)
+	msg := err.Error()
+	if e == errcode.ErrInternal {
+		logx.WithContext(ctx).Errorf("internal error: %v", err)
+		msg = e.Msg
+	}
+	httpx.WriteJsonCtx(ctx, w, e.HTTPStatus, &types.BaseResponse{Code: e.Code, Msg: msg})
+}
+
+// ParamError 写入请求参数解析错误
+func ParamError(ctx context.Context, w http.ResponseWriter, err error) {
+	httpx.WriteJsonCtx(ctx, w, http.StatusBadRequest, &types.BaseResponse{
+		Code: errcode.ErrInvalidParam.Code,
+		Msg:  err.Error(),
+	})
+}
@@ -0,0 +1,48 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"market-system/common/errcode"
+	"market-system/services/api/internal/types"
+)
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name   string
+		resp   interface{}
+		err    error
+		status int
+		code   int
+		msg    string
+	}{
+		{"ok", map[string]string{"symbol": "BTCUSDT"}, nil, http.StatusOK, errcode.CodeOK, "success"},
+		{"symbol not found", nil, fmt.Errorf("%w: BTCUSDT", errcode.ErrSymbolNotFound), http.StatusNotFound, 40401, "symbol not found: BTCUSDT"},
+		{"data not found", nil, errcode.Newf(errcode.ErrNotFound, "ticker not found for symbol: %s", "BTCUSDT"), http.StatusNotFound, 40400, "ticker not found for symbol: BTCUSDT"},
+		{"invalid param", nil, errcode.Newf(errcode.ErrInvalidParam, "invalid interval: %s", "7m"), http.StatusBadRequest, 40000, "invalid interval: 7m"},
+		{"redis down", nil, fmt.Errorf("failed to get ticker: %w", errors.New("dial tcp: connection refused")), http.StatusInternalServerError, 50000, "internal error"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		Write(context.Background(), w, tt.resp, tt.err)
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.status)
+		}
+		var body types.BaseResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if body.Code != tt.code || body.Msg != tt.msg {
+			t.Errorf("%s: body = %+v", tt.name, body)
+		}
+		if (body.Data != nil) != (tt.err == nil) {
+			t.Errorf("%s: data = %v", tt.name, body.Data)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/proto/marketpb"
+	"market-system/services/api/internal/logic/market"
+	"market-system/services/api/internal/svc"
+	"market-system/services/api/internal/types"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server 行情 gRPC 服务，查询复用 REST 接口的逻辑（读缓存、软删除过滤、NaN/Inf 清洗），
+// 只转换请求和响应的格式，未指定的参数取 REST 接口的默认值
+type Server struct {
+	marketpb.UnimplementedMarketDataServer
+	svcCtx *svc.ServiceContext
+}
+
+// NewServer 创建行情 gRPC 服务
+func NewServer(svcCtx *svc.ServiceContext) *Server {
+	return &Server{svcCtx: svcCtx}
+}
+
+// NewGRPCServer 创建注册了行情服务的 gRPC 服务器，opts 为认证、限流等拦截器
+func NewGRPCServer(svcCtx *svc.ServiceContext, opts ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(opts...)
+	marketpb.RegisterMarketDataServer(server, NewServer(svcCtx))
+	return server
+}
+
+// GetTicker 获取行情快照
+func (s *Server) GetTicker(ctx context.Context, req *marketpb.GetTickerRequest) (*marketpb.Ticker, error) {
+	resp, err := market.NewGetTickerLogic(ctx, s.svcCtx).GetTicker(&types.TickerRequest{Symbol: req.Symbol})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &marketpb.Ticker{
+		Symbol:                 resp.Symbol,
+		LastPrice:              resp.LastPrice,
+		BidPrice:               resp.BidPrice,
+		AskPrice:               resp.AskPrice,
+		High_24H:               resp.High24h,
+		Low_24H:                resp.Low24h,
+		Volume_24H:             resp.Volume24h,
+		Open_24H:               resp.Open24h,
+		PriceChange_24H:        resp.PriceChange24h,
+		PriceChangePercent_24H: resp.PriceChangePercent24h,
+		TradeCount_24H:         resp.TradeCount24h,
+		Timestamp:              resp.Timestamp,
+	}, nil
+}
+
+// GetDepth 获取深度
+func (s *Server) GetDepth(ctx context.Context, req *marketpb.GetDepthRequest) (*marketpb.Depth, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	resp, err := market.NewGetDepthLogic(ctx, s.svcCtx).GetDepth(&types.DepthRequest{
+		Symbol:    req.Symbol,
+		Limit:     limit,
+		Precision: req.Precision,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &marketpb.Depth{
+		Symbol:    resp.Symbol,
+		Bids:      toPriceLevels(resp.Bids),
+		Asks:      toPriceLevels(resp.Asks),
+		Timestamp: resp.Timestamp,
+	}, nil
+}
+
+// GetKlines 按开盘时间范围获取K线
+func (s *Server) GetKlines(ctx context.Context, req *marketpb.GetKlinesRequest) (*marketpb.GetKlinesResponse, error) {
+	klineReq := &types.KlineRequest{
+		Symbol:    req.Symbol,
+		Interval:  req.Interval,
+		Limit:     req.Limit,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Order:     req.Order,
+	}
+	if klineReq.Interval == "" {
+		klineReq.Interval = "1m"
+	}
+	if klineReq.Limit <= 0 {
+		klineReq.Limit = 100
+	}
+	switch klineReq.Order {
+	case "":
+		klineReq.Order = "desc"
+	case "asc", "desc":
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid order: %s", req.Order)
+	}
+
+	resp, err := market.NewGetKlineLogic(ctx, s.svcCtx).GetKline(klineReq)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	klines := make([]*marketpb.Kline, 0, len(resp.Data))
+	for _, k := range resp.Data {
+		klines = append(klines, &marketpb.Kline{
+			Symbol:    resp.Symbol,
+			Interval:  resp.Interval,
+			OpenTime:  k.OpenTime,
+			CloseTime: k.CloseTime,
+			Open:      k.Open,
+			High:      k.High,
+			Low:       k.Low,
+			Close:     k.Close,
+			Volume:    k.Volume,
+			QuoteVol:  k.QuoteVol,
+			TradeNum:  k.TradeNum,
+			Revision:  k.Revision,
+			IsFinal:   k.IsFinal,
+		})
+	}
+	return &marketpb.GetKlinesResponse{
+		Symbol:        resp.Symbol,
+		Interval:      resp.Interval,
+		Klines:        klines,
+		HasMore:       resp.HasMore,
+		NextStartTime: resp.NextStartTime,
+		NextEndTime:   resp.NextEndTime,
+	}, nil
+}
+
+// GetTrades 获取最近成交
+func (s *Server) GetTrades(ctx context.Context, req *marketpb.GetTradesRequest) (*marketpb.GetTradesResponse, error) {
+	tradesReq := &types.TradesRequest{
+		Symbol: req.Symbol,
+		Limit:  req.Limit,
+		Source: req.Source,
+	}
+	if tradesReq.Limit <= 0 {
+		tradesReq.Limit = 50
+	}
+	switch tradesReq.Source {
+	case "", constants.SourceAll, constants.SourceInternal, constants.SourceExternal:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid source: %s", req.Source)
+	}
+
+	resp, err := market.NewGetTradesLogic(ctx, s.svcCtx).GetTrades(tradesReq)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	trades := make([]*marketpb.Trade, 0, len(resp.Trades))
+	for _, t := range resp.Trades {
+		trades = append(trades, &marketpb.Trade{
+			Symbol:    resp.Symbol,
+			TradeId:   t.TradeID,
+			Price:     t.Price,
+			Amount:    t.Amount,
+			Side:      t.Side,
+			Timestamp: t.Timestamp,
+			Source:    t.Source,
+			Exchange:  t.Exchange,
+		})
+	}
+	return &marketpb.GetTradesResponse{Symbol: resp.Symbol, Trades: trades}, nil
+}
+
+// toPriceLevels 转换深度档位
+func toPriceLevels(levels []types.PriceLevel) []*marketpb.PriceLevel {
+	result := make([]*marketpb.PriceLevel, len(levels))
+	for i, level := range levels {
+		result[i] = &marketpb.PriceLevel{Price: level.Price, Amount: level.Amount}
+	}
+	return result
+}
+
+// toStatus 按错误码转换为 gRPC 状态，交易对或数据不存在为 NotFound，参数错误为 InvalidArgument，
+// 数据过期为 Unavailable，其他错误为 Unknown
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, errcode.ErrSymbolNotFound), errors.Is(err, errcode.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, errcode.ErrInvalidParam):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, errcode.ErrStaleData):
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Unknown, err.Error())
+}
@@ -0,0 +1,172 @@
+package rpc
+
+import (
+	"fmt"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"strings"
+
+	"market-system/common/proto/marketpb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxSubscribeChannels 单个订阅最多的频道数量
+const maxSubscribeChannels = 100
+
+// SubscribeMarketData 订阅实时行情，每个订阅使用独立的 Redis 订阅，客户端断开时取消
+// 频道格式与 WebSocket 相同，已软删除的交易对不能订阅，订阅期间被软删除后停止推送
+func (s *Server) SubscribeMarketData(req *marketpb.SubscribeRequest, stream marketpb.MarketData_SubscribeMarketDataServer) error {
+	if len(req.Channels) == 0 {
+		return status.Error(codes.InvalidArgument, "no channels")
+	}
+	if len(req.Channels) > maxSubscribeChannels {
+		return status.Errorf(codes.InvalidArgument, "too many channels: %d (max %d)", len(req.Channels), maxSubscribeChannels)
+	}
+
+	redisChannels := make([]string, len(req.Channels))
+	for i, channel := range req.Channels {
+		_, symbol, err := parseChannel(channel)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if err := s.svcCtx.Symbols.Check(symbol); err != nil {
+			return toStatus(err)
+		}
+		redisChannels[i] = constants.RedisChannelMarket + channel
+	}
+
+	ctx := stream.Context()
+	pubsub := s.svcCtx.Redis.Subscribe(ctx, redisChannels...)
+	defer pubsub.Close()
+	// 等待订阅确认，避免返回前丢失消息
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return status.Errorf(codes.Unavailable, "failed to subscribe: %v", err)
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return status.Error(codes.Unavailable, "subscription closed")
+			}
+			channel := strings.TrimPrefix(msg.Channel, constants.RedisChannelMarket)
+			_, symbol, _ := parseChannel(channel)
+			if s.svcCtx.Symbols.IsDeleted(symbol) {
+				continue
+			}
+
+			event, err := toMarketEvent(channel, []byte(msg.Payload))
+			if err != nil {
+				continue
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// parseChannel 解析订阅频道：ticker:BTCUSDT、depth:BTCUSDT、trade:BTCUSDT、kline:BTCUSDT:1m
+func parseChannel(channel string) (channelType, symbol string, err error) {
+	parts := strings.Split(channel, ":")
+	switch {
+	case len(parts) == 2 && (parts[0] == constants.DataTypeTicker || parts[0] == constants.DataTypeDepth || parts[0] == constants.DataTypeTrade):
+	case len(parts) == 3 && parts[0] == constants.DataTypeKline:
+		if !utils.ValidateInterval(parts[2]) {
+			return "", "", fmt.Errorf("invalid interval in channel: %s", channel)
+		}
+	default:
+		return "", "", fmt.Errorf("invalid channel: %s", channel)
+	}
+	if parts[1] == "" {
+		return "", "", fmt.Errorf("missing symbol in channel: %s", channel)
+	}
+	return parts[0], parts[1], nil
+}
+
+// toMarketEvent 解码频道消息（JSON 或 MessagePack）并转换为推送事件
+func toMarketEvent(channel string, payload []byte) (*marketpb.MarketEvent, error) {
+	channelType, _, err := parseChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &marketpb.MarketEvent{Channel: channel}
+	switch channelType {
+	case constants.DataTypeTicker:
+		var t models.Ticker
+		if err := codec.Unmarshal(payload, &t); err != nil {
+			return nil, err
+		}
+		event.Data = &marketpb.MarketEvent_Ticker{Ticker: &marketpb.Ticker{
+			Symbol:                 t.Symbol,
+			LastPrice:              t.LastPrice,
+			BidPrice:               t.BidPrice,
+			AskPrice:               t.AskPrice,
+			High_24H:               t.High24h,
+			Low_24H:                t.Low24h,
+			Volume_24H:             t.Volume24h,
+			Open_24H:               t.Open24h,
+			PriceChange_24H:        t.PriceChange24h,
+			PriceChangePercent_24H: t.PriceChangePercent24h,
+			TradeCount_24H:         t.TradeCount24h,
+			Timestamp:              t.Timestamp,
+		}}
+	case constants.DataTypeDepth:
+		var book models.OrderBook
+		if err := codec.Unmarshal(payload, &book); err != nil {
+			return nil, err
+		}
+		depth := &marketpb.Depth{Symbol: book.Symbol, Timestamp: book.Timestamp}
+		for _, level := range book.Bids {
+			depth.Bids = append(depth.Bids, &marketpb.PriceLevel{Price: level.Price, Amount: level.Amount})
+		}
+		for _, level := range book.Asks {
+			depth.Asks = append(depth.Asks, &marketpb.PriceLevel{Price: level.Price, Amount: level.Amount})
+		}
+		event.Data = &marketpb.MarketEvent_Depth{Depth: depth}
+	case constants.DataTypeTrade:
+		var t models.Trade
+		if err := codec.Unmarshal(payload, &t); err != nil {
+			return nil, err
+		}
+		event.Data = &marketpb.MarketEvent_Trade{Trade: &marketpb.Trade{
+			Symbol:    t.Symbol,
+			TradeId:   t.TradeID,
+			Price:     t.Price,
+			Amount:    t.Amount,
+			Side:      t.Side,
+			Timestamp: t.Timestamp,
+			Source:    t.Source,
+			Exchange:  t.Exchange,
+		}}
+	case constants.DataTypeKline:
+		var k models.KlineUpdate
+		if err := codec.Unmarshal(payload, &k); err != nil {
+			return nil, err
+		}
+		event.Data = &marketpb.MarketEvent_Kline{Kline: &marketpb.Kline{
+			Symbol:    k.Symbol,
+			Interval:  k.Interval,
+			OpenTime:  k.OpenTime,
+			CloseTime: k.CloseTime,
+			Open:      k.Open,
+			High:      k.High,
+			Low:       k.Low,
+			Close:     k.Close,
+			Volume:    k.Volume,
+			QuoteVol:  k.QuoteVol,
+			TradeNum:  k.TradeNum,
+			Revision:  k.Revision,
+			IsFinal:   k.Closed || k.IsFinal,
+		}}
+	}
+	return event, nil
+}
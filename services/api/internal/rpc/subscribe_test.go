@@ -0,0 +1,57 @@
+package rpc
+
+import (
+	"testing"
+
+	"market-system/common/proto/marketpb"
+)
+
+func TestParseChannel(t *testing.T) {
+	tests := []struct {
+		channel     string
+		channelType string
+		symbol      string
+		ok          bool
+	}{
+		{"ticker:BTCUSDT", "ticker", "BTCUSDT", true},
+		{"depth:ETHUSDT", "depth", "ETHUSDT", true},
+		{"kline:BTCUSDT:1m", "kline", "BTCUSDT", true},
+		{"kline:BTCUSDT:7m", "", "", false},
+		{"kline:BTCUSDT", "", "", false},
+		{"ticker:", "", "", false},
+		{"band:BTCUSDT", "", "", false},
+	}
+	for _, tc := range tests {
+		channelType, symbol, err := parseChannel(tc.channel)
+		if (err == nil) != tc.ok || channelType != tc.channelType || symbol != tc.symbol {
+			t.Errorf("%s: got %q %q %v", tc.channel, channelType, symbol, err)
+		}
+	}
+}
+
+func TestToMarketEvent(t *testing.T) {
+	event, err := toMarketEvent("kline:BTCUSDT:1m",
+		[]byte(`{"symbol":"BTCUSDT","interval":"1m","open_time":1700000000000,"close":101.5,"closed":true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kline := event.GetKline()
+	if kline == nil || kline.Close != 101.5 || !kline.IsFinal || event.Channel != "kline:BTCUSDT:1m" {
+		t.Errorf("event = %v", event)
+	}
+
+	event, err = toMarketEvent("depth:BTCUSDT", []byte(`{"symbol":"BTCUSDT","bids":[{"price":100,"amount":2}],"asks":[]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if depth := event.GetDepth(); depth == nil || len(depth.Bids) != 1 || depth.Bids[0].Amount != 2 {
+		t.Errorf("event = %v", event)
+	}
+
+	if _, ok := event.Data.(*marketpb.MarketEvent_Depth); !ok {
+		t.Errorf("data = %T", event.Data)
+	}
+	if _, err := toMarketEvent("trade:BTCUSDT", []byte("not json")); err == nil {
+		t.Error("expected decode error")
+	}
+}
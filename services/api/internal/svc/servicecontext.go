@@ -3,7 +3,16 @@ package svc
 import (
 	"context"
 	"fmt"
+	"market-system/common/codec"
+	commonconfig "market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/retention"
+	"market-system/common/sanitize"
 	"market-system/services/api/internal/config"
+	"market-system/services/api/internal/conversion"
+	"market-system/services/api/internal/pricealert"
+	"market-system/services/api/internal/readcache"
+	"market-system/services/api/internal/registry"
 	ws "market-system/services/api/internal/websocket"
 	"time"
 
@@ -15,20 +24,26 @@ type ServiceContext struct {
 	Redis       *redis.Client
 	WsHub       *ws.Hub
 	Broadcaster *ws.Broadcaster
+	Sanitizer   *sanitize.Sanitizer   // 输出前的 NaN/Inf 清洗
+	Symbols     *registry.Registry    // 已软删除的交易对，REST/WebSocket 对外隐藏
+	Groups      *registry.Groups      // 交易对分组，WebSocket 分组频道和 REST 按分组过滤
+	TickerCache *readcache.Cache      // Ticker 读缓存，为 nil 表示不缓存
+	DepthCache  *readcache.Cache      // 深度读缓存，为 nil 表示不缓存
+	PriceAlerts *pricealert.Store     // 用户价格提醒，REST 接口和 WebSocket alert.subscribe 共用
+	Converter   *conversion.Converter // 计价货币换算，为 nil 表示不换算
+	Retention   *retention.Retention  // Redis 中各类数据的保留策略，管理接口重建缓存时使用
+	Codec       codec.Codec           // 管理接口重建缓存时写入的编码
+	Standby     *ws.Standby           // 热备同步，为 nil 表示不同步
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
+	valueCodec, err := codec.New(c.Redis.Codec)
+	if err != nil {
+		panic(err)
+	}
+
 	// 初始化 Redis 客户端
-	rdb := redis.NewClient(&redis.Options{
-		Addr:         fmt.Sprintf("%s:%d", c.Redis.Host, c.Redis.Port),
-		Password:     c.Redis.Password,
-		DB:           c.Redis.DB,
-		DialTimeout:  5 * time.Second,
-		ReadTimeout:  3 * time.Second,
-		WriteTimeout: 3 * time.Second,
-		PoolSize:     100,
-		MinIdleConns: 10,
-	})
+	rdb := newRedisClient(c.Redis)
 
 	// 测试连接
 	ctx := context.Background()
@@ -36,16 +51,129 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		panic(fmt.Sprintf("Failed to connect to Redis: %v", err))
 	}
 
+	// 加载交易对注册表（软删除状态）
+	symbols := registry.NewRegistry(rdb)
+	if err := symbols.Start(); err != nil {
+		panic(fmt.Sprintf("Failed to load symbol registry: %v", err))
+	}
+
+	// 加载交易对分组
+	groups := registry.NewGroups(rdb)
+	if err := groups.Start(); err != nil {
+		panic(fmt.Sprintf("Failed to load symbol groups: %v", err))
+	}
+
+	priceAlerts := pricealert.NewStore(rdb, c.PriceAlert.WebhookHosts)
+
 	// 初始化 WebSocket Hub
 	hub := ws.NewHub()
+	hub.SetSymbolFilter(symbols.IsDeleted)
+	hub.SetAlertStore(priceAlerts)
+	hub.SetSnapshotSource(ws.NewRedisSnapshots(rdb))
+	if c.WebSocket.MaxSubscriptionsPerConn != 0 {
+		hub.SetMaxSubscriptions(c.WebSocket.MaxSubscriptionsPerConn)
+	}
+	if ws := c.WebSocket; ws.MessageRate != 0 || ws.MessageBurst != 0 || ws.RateLimitWarnings != 0 {
+		rate, warnings := ws.MessageRate, ws.RateLimitWarnings
+		if rate == 0 {
+			rate = constants.MessageRateLimit
+		}
+		if warnings == 0 {
+			warnings = constants.MaxRateLimitWarnings
+		}
+		hub.SetMessageRateLimit(rate, ws.MessageBurst, warnings)
+	}
+	if c.WebSocket.DepthDiffSnapshotMs > 0 {
+		hub.SetDepthDiffSnapshotInterval(time.Duration(c.WebSocket.DepthDiffSnapshotMs) * time.Millisecond)
+	}
+	for channelType, window := range c.WebSocket.Conflation {
+		hub.SetConflation(channelType, time.Duration(window)*time.Millisecond)
+	}
+	for channelType, ttl := range c.WebSocket.MessageTTL {
+		hub.SetMessageTTL(channelType, time.Duration(ttl)*time.Millisecond)
+	}
 
 	// 初始化 Broadcaster
 	broadcaster := ws.NewBroadcaster(hub, rdb)
+	broadcaster.SetGroupLookup(groups.GroupsOf)
+
+	// 热备同步
+	var standby *ws.Standby
+	switch c.Standby.Role {
+	case "":
+	case ws.StandbyRoleActive, ws.StandbyRoleStandby:
+		standby = ws.NewStandby(hub, rdb, c.Standby.Role, c.Standby.Name, time.Duration(c.Standby.IntervalMs)*time.Millisecond)
+	default:
+		panic(fmt.Sprintf("Unknown standby role: %s", c.Standby.Role))
+	}
 
 	return &ServiceContext{
 		Config:      c,
 		Redis:       rdb,
 		WsHub:       hub,
 		Broadcaster: broadcaster,
+		Sanitizer:   sanitize.New(sanitize.StageSerialize),
+		Symbols:     symbols,
+		Groups:      groups,
+		TickerCache: readcache.New(time.Duration(c.ReadCache.TickerTTLMs) * time.Millisecond),
+		DepthCache:  readcache.New(time.Duration(c.ReadCache.DepthTTLMs) * time.Millisecond),
+		PriceAlerts: priceAlerts,
+		Converter:   conversion.New(c.Conversion),
+		Retention:   newRetention(c.Retention),
+		Codec:       valueCodec,
+		Standby:     standby,
+	}
+}
+
+// newRetention 按与 Processor 相同的规则解析保留策略
+func newRetention(c config.RetentionConfig) *retention.Retention {
+	policy := func(p config.RetentionPolicy) commonconfig.RetentionPolicy {
+		return commonconfig.RetentionPolicy{
+			KlineHistory:  p.KlineHistory,
+			KlineTTLHours: p.KlineTTLHours,
+			TradeListSize: p.TradeListSize,
+			TradeTTLSec:   p.TradeTTLSec,
+			TickerTTLSec:  p.TickerTTLSec,
+			DepthTTLSec:   p.DepthTTLSec,
+		}
+	}
+
+	cfg := commonconfig.RetentionConfig{
+		RetentionPolicy: policy(c.RetentionPolicy),
+		Symbols:         make(map[string]commonconfig.RetentionPolicy, len(c.Symbols)),
+	}
+	for symbol, p := range c.Symbols {
+		cfg.Symbols[symbol] = policy(p)
+	}
+	return retention.New(nil, cfg)
+}
+
+// newRedisClient 创建 Redis 客户端，配置了 Sentinel 时经 Sentinel 获取主节点地址，
+// 主节点故障切换后连接（包括注册表、广播的订阅）自动重连到新的主节点
+func newRedisClient(c config.RedisConfig) *redis.Client {
+	if c.Sentinel.MasterName != "" {
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       c.Sentinel.MasterName,
+			SentinelAddrs:    c.Sentinel.Addrs,
+			SentinelPassword: c.Sentinel.Password,
+			Password:         c.Password,
+			DB:               c.DB,
+			DialTimeout:      5 * time.Second,
+			ReadTimeout:      3 * time.Second,
+			WriteTimeout:     3 * time.Second,
+			PoolSize:         100,
+			MinIdleConns:     10,
+		})
 	}
+
+	return redis.NewClient(&redis.Options{
+		Addr:         fmt.Sprintf("%s:%d", c.Host, c.Port),
+		Password:     c.Password,
+		DB:           c.DB,
+		DialTimeout:  5 * time.Second,
+		ReadTimeout:  3 * time.Second,
+		WriteTimeout: 3 * time.Second,
+		PoolSize:     100,
+		MinIdleConns: 10,
+	})
 }
@@ -5,20 +5,127 @@ type TickerRequest struct {
 }
 
 type TickerResponse struct {
+	Symbol                string  `json:"symbol"`
+	LastPrice             float64 `json:"last_price"`
+	BidPrice              float64 `json:"bid_price"`
+	AskPrice              float64 `json:"ask_price"`
+	High24h               float64 `json:"high_24h"`
+	Low24h                float64 `json:"low_24h"`
+	Volume24h             float64 `json:"volume_24h"`
+	Open24h               float64 `json:"open_24h"`
+	PriceChange24h        float64 `json:"price_change_24h"`
+	PriceChangePercent24h float64 `json:"price_change_percent_24h"`
+	TradeCount24h         int64   `json:"trade_count_24h"`
+	Timestamp             int64   `json:"timestamp"`
+	AgeMs                 int64   `json:"age_ms"`
+	Stale                 bool    `json:"stale"`
+	ConvertCurrency       string  `json:"convert_currency,omitempty"`
+	ConvertedLastPrice    float64 `json:"converted_last_price,omitempty"`
+	ConvertedVolume24h    float64 `json:"converted_volume_24h,omitempty"`
+}
+
+type TickerSourcesResponse struct {
+	Symbol                string  `json:"symbol"`
+	LastPrice             float64 `json:"last_price"`
+	LastPriceSource       string  `json:"last_price_source"`
+	BidPrice              float64 `json:"bid_price"`
+	AskPrice              float64 `json:"ask_price"`
+	High24h               float64 `json:"high_24h"`
+	Low24h                float64 `json:"low_24h"`
+	Open24h               float64 `json:"open_24h"`
+	PriceChange24h        float64 `json:"price_change_24h"`
+	PriceChangePercent24h float64 `json:"price_change_percent_24h"`
+	TradeCount24h         int64   `json:"trade_count_24h"`
+	InternalVolume24h     float64 `json:"internal_volume_24h"`
+	ExternalVolume24h     float64 `json:"external_volume_24h"`
+	TotalVolume24h        float64 `json:"total_volume_24h"`
+	InternalVolumeRatio   float64 `json:"internal_volume_ratio"`
+	Timestamp             int64   `json:"timestamp"`
+}
+
+type BookTickerResponse struct {
 	Symbol    string  `json:"symbol"`
-	LastPrice float64 `json:"last_price"`
 	BidPrice  float64 `json:"bid_price"`
+	BidQty    float64 `json:"bid_qty"`
 	AskPrice  float64 `json:"ask_price"`
-	High24h   float64 `json:"high_24h"`
-	Low24h    float64 `json:"low_24h"`
-	Volume24h float64 `json:"volume_24h"`
+	AskQty    float64 `json:"ask_qty"`
 	Timestamp int64   `json:"timestamp"`
 }
 
+type Stats24hRequest struct {
+	Symbol string `path:"symbol"`
+}
+
+type Stats24hResponse struct {
+	Symbol             string  `json:"symbol"`
+	Open               float64 `json:"open"`
+	High               float64 `json:"high"`
+	Low                float64 `json:"low"`
+	LastPrice          float64 `json:"last_price"`
+	Volume             float64 `json:"volume"`
+	QuoteVolume        float64 `json:"quote_volume"`
+	PriceChange        float64 `json:"price_change"`
+	PriceChangePercent float64 `json:"price_change_percent"`
+	TradeCount         int64   `json:"trade_count"`
+	Timestamp          int64   `json:"timestamp"`
+}
+
+type Stats24hListRequest struct {
+	Symbols string `form:"symbols,optional"`
+}
+
+type Stats24hListResponse struct {
+	Stats []Stats24hResponse `json:"stats"`
+}
+
+type TickersRequest struct {
+	Group   string `form:"group,optional"`
+	Symbols string `form:"symbols,optional"`
+}
+
+type TickersResponse struct {
+	Group   string           `json:"group"`
+	Tickers []TickerResponse `json:"tickers"`
+}
+
+type ScreenerRequest struct {
+	Sort   string `form:"sort,default=change,options=change|volume|trade_count"`
+	Order  string `form:"order,default=desc,options=asc|desc"`
+	Limit  int64  `form:"limit,default=20"`
+	Offset int64  `form:"offset,default=0"`
+}
+
+type ScreenerResponse struct {
+	Sort    string           `json:"sort"`
+	Order   string           `json:"order"`
+	Total   int64            `json:"total"`
+	Tickers []TickerResponse `json:"tickers"`
+}
+
+type SymbolInfo struct {
+	Symbol      string   `json:"symbol"`
+	Mode        string   `json:"mode"`
+	Enable      bool     `json:"enable"`
+	Description string   `json:"description"`
+	TickSize    float64  `json:"tick_size"`
+	Channels    []string `json:"channels"`
+	LastUpdate  int64    `json:"last_update"`
+	Fresh       bool     `json:"fresh"`
+}
+
+type SymbolListResponse struct {
+	Symbols   []SymbolInfo `json:"symbols"`
+	Timestamp int64        `json:"timestamp"`
+}
+
 type KlineRequest struct {
-	Symbol   string `form:"symbol"`
-	Interval string `form:"interval,default=1m"`
-	Limit    int64  `form:"limit,default=100"`
+	Symbol    string `form:"symbol"`
+	Interval  string `form:"interval,default=1m"`
+	Limit     int64  `form:"limit,default=100"`
+	StartTime int64  `form:"start_time,optional"`
+	EndTime   int64  `form:"end_time,optional"`
+	Order     string `form:"order,default=desc,options=asc|desc"`
+	Type      string `form:"type,default=candle,options=candle|heikin_ashi|renko"`
 }
 
 type Kline struct {
@@ -31,17 +138,24 @@ type Kline struct {
 	Volume    float64 `json:"volume"`
 	QuoteVol  float64 `json:"quote_vol"`
 	TradeNum  int64   `json:"trade_num"`
+	Revision  int64   `json:"revision"`
+	IsFinal   bool    `json:"is_final"`
 }
 
 type KlineResponse struct {
-	Symbol   string  `json:"symbol"`
-	Interval string  `json:"interval"`
-	Data     []Kline `json:"data"`
+	Symbol        string  `json:"symbol"`
+	Interval      string  `json:"interval"`
+	Type          string  `json:"type"`
+	Data          []Kline `json:"data"`
+	HasMore       bool    `json:"has_more"`
+	NextStartTime int64   `json:"next_start_time,omitempty"`
+	NextEndTime   int64   `json:"next_end_time,omitempty"`
 }
 
 type DepthRequest struct {
-	Symbol string `path:"symbol"`
-	Limit  int64  `form:"limit,default=20"`
+	Symbol    string `path:"symbol"`
+	Limit     int64  `form:"limit,default=20"`
+	Precision string `form:"precision,optional"`
 }
 
 type PriceLevel struct {
@@ -56,8 +170,546 @@ type DepthResponse struct {
 	Timestamp int64        `json:"timestamp"`
 }
 
+type DepthSourcesRequest struct {
+	Symbol string `path:"symbol"`
+	Limit  int64  `form:"limit,default=20"`
+}
+
+type SourcedPriceLevel struct {
+	Price          float64 `json:"price"`
+	Amount         float64 `json:"amount"`
+	Source         string  `json:"source"`
+	InternalAmount float64 `json:"internal_amount"`
+	ExternalAmount float64 `json:"external_amount"`
+}
+
+type DepthSourcesResponse struct {
+	Symbol            string              `json:"symbol"`
+	Bids              []SourcedPriceLevel `json:"bids"`
+	Asks              []SourcedPriceLevel `json:"asks"`
+	InternalBidsCount int                 `json:"internal_bids_count"`
+	ExternalBidsCount int                 `json:"external_bids_count"`
+	InternalAsksCount int                 `json:"internal_asks_count"`
+	ExternalAsksCount int                 `json:"external_asks_count"`
+	Timestamp         int64               `json:"timestamp"`
+}
+
+type SnapshotRequest struct {
+	Symbol string `path:"symbol"`
+	Depth  int64  `form:"depth,default=20"`
+	Trades int64  `form:"trades,default=50"`
+}
+
+type Trade struct {
+	TradeID   string  `json:"trade_id"`
+	Price     float64 `json:"price"`
+	Amount    float64 `json:"amount"`
+	Side      string  `json:"side"`
+	Timestamp int64   `json:"timestamp"`
+	Source    string  `json:"source,omitempty"`
+	Exchange  string  `json:"exchange,omitempty"`
+}
+
+type SnapshotResponse struct {
+	Symbol    string          `json:"symbol"`
+	Ticker    *TickerResponse `json:"ticker"`
+	Depth     *DepthResponse  `json:"depth"`
+	Trades    []Trade         `json:"trades"`
+	Timestamp int64           `json:"timestamp"`
+}
+
+type TradesRequest struct {
+	Symbol string `path:"symbol"`
+	Limit  int64  `form:"limit,default=50"`
+	Source string `form:"source,default=all,options=internal|external|all"`
+}
+
+type TradesResponse struct {
+	Symbol string  `json:"symbol"`
+	Trades []Trade `json:"trades"`
+}
+
+type AggTrade struct {
+	AggID        int64   `json:"agg_id"`
+	Price        float64 `json:"price"`
+	Amount       float64 `json:"amount"`
+	Side         string  `json:"side"`
+	FirstTradeID string  `json:"first_trade_id"`
+	LastTradeID  string  `json:"last_trade_id"`
+	Count        int64   `json:"count"`
+	FirstTime    int64   `json:"first_time"`
+	Timestamp    int64   `json:"timestamp"`
+	Source       string  `json:"source,omitempty"`
+	Exchange     string  `json:"exchange,omitempty"`
+}
+
+type AggTradesRequest struct {
+	Symbol string `path:"symbol"`
+	Limit  int64  `form:"limit,default=50"`
+}
+
+type AggTradesResponse struct {
+	Symbol    string     `json:"symbol"`
+	AggTrades []AggTrade `json:"agg_trades"`
+}
+
+type TWAPRequest struct {
+	Symbol string `path:"symbol"`
+}
+
+type TWAPResponse struct {
+	Symbol    string             `json:"symbol"`
+	LastPrice float64            `json:"last_price"`
+	Twap      map[string]float64 `json:"twap"`
+	Coverage  map[string]float64 `json:"coverage"`
+	Timestamp int64              `json:"timestamp"`
+}
+
+type VWAPRequest struct {
+	Symbol string `path:"symbol"`
+}
+
+type VWAPResponse struct {
+	Symbol    string             `json:"symbol"`
+	Vwap      map[string]float64 `json:"vwap"`
+	Volume    map[string]float64 `json:"volume"`
+	Timestamp int64              `json:"timestamp"`
+}
+
+type IndexRequest struct {
+	Symbol string `path:"symbol"`
+}
+
+type IndexComponent struct {
+	Exchange  string  `json:"exchange"`
+	Price     float64 `json:"price"`
+	Weight    float64 `json:"weight"`
+	Excluded  bool    `json:"excluded"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+type IndexResponse struct {
+	Symbol     string           `json:"symbol"`
+	Price      float64          `json:"price"`
+	Method     string           `json:"method"`
+	Components []IndexComponent `json:"components"`
+	Timestamp  int64            `json:"timestamp"`
+}
+
+type IndicatorsRequest struct {
+	Symbol   string `form:"symbol"`
+	Interval string `form:"interval,default=1m"`
+}
+
+type MACD struct {
+	Dif       float64 `json:"dif"`
+	Dea       float64 `json:"dea"`
+	Histogram float64 `json:"histogram"`
+}
+
+type BOLL struct {
+	Upper  float64 `json:"upper"`
+	Middle float64 `json:"middle"`
+	Lower  float64 `json:"lower"`
+}
+
+type IndicatorsResponse struct {
+	Symbol    string             `json:"symbol"`
+	Interval  string             `json:"interval"`
+	OpenTime  int64              `json:"open_time"`
+	Closed    bool               `json:"closed"`
+	Close     float64            `json:"close"`
+	Sma       map[string]float64 `json:"sma"`
+	Ema       map[string]float64 `json:"ema"`
+	Rsi       map[string]float64 `json:"rsi"`
+	Macd      *MACD              `json:"macd"`
+	Boll      *BOLL              `json:"boll"`
+	Timestamp int64              `json:"timestamp"`
+}
+
+type BookStatsRequest struct {
+	Symbol string `path:"symbol"`
+	Limit  int64  `form:"limit,default=1"`
+}
+
+type LiquidityBand struct {
+	Percent     float64 `json:"percent"`
+	BidAmount   float64 `json:"bid_amount"`
+	AskAmount   float64 `json:"ask_amount"`
+	BidNotional float64 `json:"bid_notional"`
+	AskNotional float64 `json:"ask_notional"`
+}
+
+type BookStats struct {
+	BidPrice  float64         `json:"bid_price"`
+	AskPrice  float64         `json:"ask_price"`
+	MidPrice  float64         `json:"mid_price"`
+	Spread    float64         `json:"spread"`
+	SpreadBps float64         `json:"spread_bps"`
+	Imbalance float64         `json:"imbalance"`
+	Liquidity []LiquidityBand `json:"liquidity"`
+	Timestamp int64           `json:"timestamp"`
+}
+
+type BookStatsResponse struct {
+	Symbol string      `json:"symbol"`
+	Stats  []BookStats `json:"stats"`
+}
+
+type SlippageRequest struct {
+	Symbol   string  `path:"symbol"`
+	Notional float64 `form:"notional"`
+	Side     string  `form:"side,default=buy,options=buy|sell"`
+}
+
+type SlippageResponse struct {
+	Symbol         string  `json:"symbol"`
+	Side           string  `json:"side"`
+	Notional       float64 `json:"notional"`
+	FilledNotional float64 `json:"filled_notional"`
+	FilledAmount   float64 `json:"filled_amount"`
+	AvgPrice       float64 `json:"avg_price"`
+	BestPrice      float64 `json:"best_price"`
+	MidPrice       float64 `json:"mid_price"`
+	SlippageBps    float64 `json:"slippage_bps"`
+	Levels         int     `json:"levels"`
+	Complete       bool    `json:"complete"`
+	Timestamp      int64   `json:"timestamp"`
+}
+
+type CreatePriceAlertRequest struct {
+	Symbol    string  `json:"symbol"`
+	Condition string  `json:"condition"`
+	Value     float64 `json:"value"`
+	Webhook   string  `json:"webhook"`
+	Repeat    bool    `json:"repeat,optional"`
+}
+
+type PriceAlert struct {
+	Id        string  `json:"id"`
+	Symbol    string  `json:"symbol"`
+	Condition string  `json:"condition"`
+	Value     float64 `json:"value"`
+	Webhook   string  `json:"webhook,omitempty"`
+	Repeat    bool    `json:"repeat"`
+	CreatedAt int64   `json:"created_at"`
+}
+
+type PriceAlertsRequest struct {
+	Symbol string `form:"symbol,optional"`
+}
+
+type PriceAlertsResponse struct {
+	Alerts []PriceAlert `json:"alerts"`
+}
+
+type DeletePriceAlertRequest struct {
+	Id string `path:"id"`
+}
+
+type DeletePriceAlertResponse struct {
+	Id      string `json:"id"`
+	Deleted bool   `json:"deleted"`
+}
+
+type UDFConfigResponse struct {
+	SupportedResolutions   []string `json:"supported_resolutions"`
+	SupportsGroupRequest   bool     `json:"supports_group_request"`
+	SupportsMarks          bool     `json:"supports_marks"`
+	SupportsSearch         bool     `json:"supports_search"`
+	SupportsTimescaleMarks bool     `json:"supports_timescale_marks"`
+	SupportsTime           bool     `json:"supports_time"`
+}
+
+type UDFSymbolRequest struct {
+	Symbol string `form:"symbol"`
+}
+
+type UDFSymbolResponse struct {
+	Name                 string   `json:"name"`
+	Ticker               string   `json:"ticker"`
+	Description          string   `json:"description"`
+	Type                 string   `json:"type"`
+	Session              string   `json:"session"`
+	Timezone             string   `json:"timezone"`
+	Exchange             string   `json:"exchange"`
+	ListedExchange       string   `json:"listed_exchange"`
+	Minmov               int64    `json:"minmov"`
+	Pricescale           int64    `json:"pricescale"`
+	HasIntraday          bool     `json:"has_intraday"`
+	HasWeeklyAndMonthly  bool     `json:"has_weekly_and_monthly"`
+	SupportedResolutions []string `json:"supported_resolutions"`
+	VolumePrecision      int      `json:"volume_precision"`
+	DataStatus           string   `json:"data_status"`
+}
+
+type UDFHistoryRequest struct {
+	Symbol     string `form:"symbol"`
+	Resolution string `form:"resolution"`
+	From       int64  `form:"from"`
+	To         int64  `form:"to"`
+	Countback  int64  `form:"countback,optional"`
+}
+
+type UDFHistoryResponse struct {
+	S        string    `json:"s"`
+	Errmsg   string    `json:"errmsg,omitempty"`
+	T        []int64   `json:"t,omitempty"`
+	O        []float64 `json:"o,omitempty"`
+	H        []float64 `json:"h,omitempty"`
+	L        []float64 `json:"l,omitempty"`
+	C        []float64 `json:"c,omitempty"`
+	V        []float64 `json:"v,omitempty"`
+	NextTime int64     `json:"nextTime,omitempty"`
+}
+
+type TradeTotalsDiscrepancy struct {
+	Symbol            string  `json:"symbol"`
+	Field             string  `json:"field"`
+	EngineCount       int64   `json:"engine_count"`
+	MarketCount       int64   `json:"market_count"`
+	EngineVolume      float64 `json:"engine_volume"`
+	MarketVolume      float64 `json:"market_volume"`
+	EngineQuoteVolume float64 `json:"engine_quote_volume"`
+	MarketQuoteVolume float64 `json:"market_quote_volume"`
+}
+
+type TradeReconcileRequest struct {
+	Date string `form:"date,optional"`
+}
+
+type TradeReconcileResponse struct {
+	Available     bool                     `json:"available"`
+	Date          string                   `json:"date"`
+	Source        string                   `json:"source"`
+	Symbols       int                      `json:"symbols"`
+	Matched       int                      `json:"matched"`
+	Mismatched    int                      `json:"mismatched"`
+	Errors        []string                 `json:"errors,omitempty"`
+	Discrepancies []TradeTotalsDiscrepancy `json:"discrepancies"`
+	Timestamp     int64                    `json:"timestamp"`
+}
+
 type BaseResponse struct {
 	Code int         `json:"code"`
 	Msg  string      `json:"msg"`
 	Data interface{} `json:"data,omitempty"`
 }
+
+type RebuildCacheRequest struct {
+	Symbol     string `path:"symbol"`
+	Source     string `form:"source,default=binance"`
+	Intervals  string `form:"intervals,optional"`
+	KlineLimit int    `form:"kline_limit,default=500"`
+}
+
+type RebuildCacheResponse struct {
+	Symbol      string         `json:"symbol"`
+	Source      string         `json:"source"`
+	DeletedKeys int64          `json:"deleted_keys"`
+	Ticker      bool           `json:"ticker"`
+	DepthLevels int            `json:"depth_levels"`
+	Klines      map[string]int `json:"klines"`
+	Trades      int            `json:"trades"`
+	Errors      []string       `json:"errors,omitempty"`
+}
+
+type BackfillRequest struct {
+	Symbols   string `form:"symbols,optional"`
+	Intervals string `form:"intervals,optional"`
+}
+
+type BackfillResponse struct {
+	Symbols   []string `json:"symbols,omitempty"`
+	Intervals []string `json:"intervals,omitempty"`
+	Receivers int64    `json:"receivers"`
+}
+
+type SymbolVWAPConfig struct {
+	InternalWeight    float64 `json:"internal_weight,optional"`
+	ExternalWeight    float64 `json:"external_weight,optional"`
+	MinInternalVolume float64 `json:"min_internal_volume,optional"`
+	MinExternalVolume float64 `json:"min_external_volume,optional"`
+}
+
+type SymbolConfigRequest struct {
+	Symbol string `path:"symbol"`
+}
+
+type SaveSymbolConfigRequest struct {
+	Symbol         string           `path:"symbol"`
+	Mode           string           `json:"mode"`
+	PrimarySource  string           `json:"primary_source,optional"`
+	ExternalSource string           `json:"external_source,optional"`
+	MergeStrategy  string           `json:"merge_strategy,optional"`
+	Enable         bool             `json:"enable,default=true"`
+	Description    string           `json:"description,optional"`
+	TickSize       float64          `json:"tick_size,optional"`
+	Vwap           SymbolVWAPConfig `json:"vwap,optional"`
+	FreshnessMs    int64            `json:"freshness_ms,optional"`
+	MaxDepthLevels int              `json:"max_depth_levels,optional"`
+	TrimPercent    float64          `json:"trim_percent,optional"`
+}
+
+type SymbolConfigResponse struct {
+	Symbol         string           `json:"symbol"`
+	Mode           string           `json:"mode"`
+	PrimarySource  string           `json:"primary_source"`
+	ExternalSource string           `json:"external_source"`
+	MergeStrategy  string           `json:"merge_strategy"`
+	Enable         bool             `json:"enable"`
+	Description    string           `json:"description"`
+	TickSize       float64          `json:"tick_size"`
+	Vwap           SymbolVWAPConfig `json:"vwap"`
+	FreshnessMs    int64            `json:"freshness_ms"`
+	MaxDepthLevels int              `json:"max_depth_levels"`
+	TrimPercent    float64          `json:"trim_percent"`
+	Deleted        bool             `json:"deleted"`
+	DeletedAt      int64            `json:"deleted_at"`
+}
+
+type SymbolConfigListResponse struct {
+	Configs []SymbolConfigResponse `json:"configs"`
+}
+
+type DeleteSymbolConfigResponse struct {
+	Symbol  string `json:"symbol"`
+	Deleted bool   `json:"deleted"`
+}
+
+type SymbolGroupRequest struct {
+	Name string `path:"name"`
+}
+
+type SaveSymbolGroupRequest struct {
+	Name        string   `path:"name"`
+	Description string   `json:"description,optional"`
+	Symbols     []string `json:"symbols"`
+}
+
+type SymbolGroupResponse struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Symbols     []string `json:"symbols"`
+}
+
+type SymbolGroupListResponse struct {
+	Groups []SymbolGroupResponse `json:"groups"`
+}
+
+type DeleteSymbolGroupResponse struct {
+	Name    string `json:"name"`
+	Deleted bool   `json:"deleted"`
+}
+
+type AdapterStatus struct {
+	Connected bool             `json:"connected"`
+	Errors    map[string]int64 `json:"errors"`
+}
+
+type WsThrottleStats struct {
+	ThrottledMessages int64 `json:"throttled_messages"`
+	ThrottledClients  int64 `json:"throttled_clients"`
+	Disconnected      int64 `json:"disconnected"`
+}
+
+type RuntimeResponse struct {
+	WsClients     int                      `json:"ws_clients"`
+	Channels      map[string]int           `json:"channels"`
+	WsThrottle    WsThrottleStats          `json:"ws_throttle"`
+	KafkaLag      map[string]int64         `json:"kafka_lag"`
+	TotalKafkaLag int64                    `json:"total_kafka_lag"`
+	Adapters      map[string]AdapterStatus `json:"adapters"`
+	Services      map[string]ServiceStatus `json:"services"`
+	Timestamp     int64                    `json:"timestamp"`
+}
+
+type WebhookEndpointStatus struct {
+	Name           string `json:"name"`
+	Url            string `json:"url"`
+	Delivered      int64  `json:"delivered"`
+	Failed         int64  `json:"failed"`
+	Retries        int64  `json:"retries"`
+	Dropped        int64  `json:"dropped"`
+	Pending        int    `json:"pending"`
+	LastStatus     int    `json:"last_status"`
+	LastError      string `json:"last_error"`
+	LastLatencyMs  int64  `json:"last_latency_ms"`
+	LastDeliveryAt int64  `json:"last_delivery_at"`
+}
+
+type WebhookStatsResponse struct {
+	Online    bool                    `json:"online"`
+	Endpoints []WebhookEndpointStatus `json:"endpoints"`
+	Timestamp int64                   `json:"timestamp"`
+}
+
+type ReconnectAdapterRequest struct {
+	Exchange string `path:"exchange"`
+}
+
+type ReconnectAdapterResponse struct {
+	Exchange  string `json:"exchange"`
+	Receivers int64  `json:"receivers"`
+}
+
+type FlushSymbolRequest struct {
+	Symbol string `path:"symbol"`
+}
+
+type FlushSymbolResponse struct {
+	Symbol      string `json:"symbol"`
+	DeletedKeys int64  `json:"deleted_keys"`
+}
+
+type ServiceStatus struct {
+	Online    bool  `json:"online"`
+	Timestamp int64 `json:"timestamp"`
+}
+
+type CacheStats struct {
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+type OverviewResponse struct {
+	SymbolsTracked   int                      `json:"symbols_tracked"`
+	MessageRates     map[string]float64       `json:"message_rates"`
+	ProcessedRates   map[string]float64       `json:"processed_rates"`
+	WsClients        int                      `json:"ws_clients"`
+	KafkaLag         map[string]int64         `json:"kafka_lag"`
+	TotalKafkaLag    int64                    `json:"total_kafka_lag"`
+	RedisUsedMemory  int64                    `json:"redis_used_memory"`
+	RedisMemoryHuman string                   `json:"redis_memory_human"`
+	Exchanges        map[string]bool          `json:"exchanges"`
+	Services         map[string]ServiceStatus `json:"services"`
+	ReadCache        map[string]CacheStats    `json:"read_cache"`
+	Timestamp        int64                    `json:"timestamp"`
+}
+
+type KlineDiscrepancy struct {
+	Symbol   string  `json:"symbol"`
+	Exchange string  `json:"exchange"`
+	OpenTime int64   `json:"open_time"`
+	Field    string  `json:"field"`
+	Local    float64 `json:"local"`
+	Remote   float64 `json:"remote"`
+	Diff     float64 `json:"diff"`
+	Repaired bool    `json:"repaired"`
+}
+
+type ConsistencyResponse struct {
+	Available     bool               `json:"available"`
+	Interval      string             `json:"interval"`
+	Symbols       int                `json:"symbols"`
+	Compared      int                `json:"compared"`
+	Mismatched    int                `json:"mismatched"`
+	Missing       int                `json:"missing"`
+	Repaired      int                `json:"repaired"`
+	Errors        []string           `json:"errors,omitempty"`
+	Discrepancies []KlineDiscrepancy `json:"discrepancies"`
+	StartTime     int64              `json:"start_time"`
+	EndTime       int64              `json:"end_time"`
+	Timestamp     int64              `json:"timestamp"`
+}
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+)
+
+// DataVersion 数据版本，版本相同时数据相同，REST 接口据此生成 ETag（客户端轮询时数据未更新返回 304）
+// 带时间戳的数据按时间戳生成；同一个 URL 的参数相同，版本只需区分数据的更新
+
+func (r *TickerResponse) DataVersion() string {
+	if r.Stale {
+		// 过期标记随时间变化，需要与未过期时区分
+		return strconv.FormatInt(r.Timestamp, 10) + "-stale"
+	}
+	return strconv.FormatInt(r.Timestamp, 10)
+}
+
+func (r *BookTickerResponse) DataVersion() string {
+	return strconv.FormatInt(r.Timestamp, 10)
+}
+
+func (r *DepthResponse) DataVersion() string {
+	return strconv.FormatInt(r.Timestamp, 10)
+}
+
+func (r *DepthSourcesResponse) DataVersion() string {
+	return strconv.FormatInt(r.Timestamp, 10)
+}
+
+func (r *Stats24hResponse) DataVersion() string {
+	return strconv.FormatInt(r.Timestamp, 10)
+}
+
+// DataVersion K线没有更新时间戳（未收盘的K线更新时修订号不变），按K线内容的哈希生成
+func (r *KlineResponse) DataVersion() string {
+	h := fnv.New64a()
+	for _, k := range r.Data {
+		fmt.Fprintf(h, "%d,%d,%g,%g,%g,%g,%g,%g,%d,%d,%t;",
+			k.OpenTime, k.CloseTime, k.Open, k.High, k.Low, k.Close, k.Volume, k.QuoteVol, k.TradeNum, k.Revision, k.IsFinal)
+	}
+	fmt.Fprintf(h, "%t,%d,%d", r.HasMore, r.NextStartTime, r.NextEndTime)
+	return strconv.FormatUint(h.Sum64(), 16)
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/utils"
 	"strings"
 
 	"github.com/redis/go-redis/v9"
@@ -15,6 +18,9 @@ type Broadcaster struct {
 	redisClient *redis.Client
 	ctx         context.Context
 	cancel      context.CancelFunc
+
+	// 获取交易对所属的分组，为 nil 表示不推送分组频道
+	groups func(symbol string) []string
 }
 
 // NewBroadcaster 创建新的广播器
@@ -28,12 +34,17 @@ func NewBroadcaster(hub *Hub, redisClient *redis.Client) *Broadcaster {
 	}
 }
 
+// SetGroupLookup 设置交易对分组查询，需在 Start 之前调用
+func (b *Broadcaster) SetGroupLookup(groups func(symbol string) []string) {
+	b.groups = groups
+}
+
 // Start 启动Redis订阅
 func (b *Broadcaster) Start() {
 	log.Println("[WebSocket Broadcaster] Starting Redis subscription...")
 
 	// 订阅所有市场数据频道
-	// 频道格式: market:ticker:BTCUSDT, market:depth:BTCUSDT, market:trade:BTCUSDT, market:kline:BTCUSDT:1m
+	// 频道格式: market:ticker:BTCUSDT, market:depth:BTCUSDT, market:trade:BTCUSDT, market:kline:BTCUSDT:1m, market:config
 	pubsub := b.redisClient.PSubscribe(b.ctx, "market:*")
 
 	// 确保pubsub关闭
@@ -78,15 +89,46 @@ func (b *Broadcaster) handleRedisMessage(msg *redis.Message) {
 	// 格式: market:kline:BTCUSDT:1m -> kline:BTCUSDT:1m
 	channel := strings.TrimPrefix(msg.Channel, "market:")
 
-	// 解析消息数据
+	// 解析消息数据（JSON 或 MessagePack，推送给客户端时统一为 JSON）
 	var data interface{}
-	if err := json.Unmarshal([]byte(msg.Payload), &data); err != nil {
+	if err := codec.Unmarshal([]byte(msg.Payload), &data); err != nil {
 		log.Printf("[WebSocket Broadcaster] Failed to parse message: %v\n", err)
 		return
 	}
 
 	// 广播到订阅了该频道的客户端
 	b.hub.Broadcast(channel, data)
+
+	// 交易对配置变更、异常检测告警同时推送到按交易对订阅的频道: config -> config:BTCUSDT
+	if channel == constants.DataTypeConfig || channel == constants.DataTypeAlerts {
+		if event, ok := data.(map[string]interface{}); ok {
+			if symbol, _ := event["symbol"].(string); symbol != "" {
+				b.hub.Broadcast(channel+":"+symbol, data)
+			}
+		}
+	}
+
+	// 深度同时计算增量推送到 depthDiff 频道: depth:BTCUSDT -> depthDiff:BTCUSDT
+	if channelType(channel) == constants.DataTypeDepth && strings.Count(channel, ":") == 1 {
+		b.hub.broadcastDepthDiff(channelSymbol(channel), []byte(msg.Payload))
+	}
+
+	// 成交同时推送到按数据源订阅的频道: trade:BTCUSDT -> trade:BTCUSDT:internal
+	if channelType(channel) == constants.DataTypeTrade && strings.Count(channel, ":") == 1 {
+		if trade, ok := data.(map[string]interface{}); ok {
+			if source, _ := trade["source"].(string); source != "" {
+				b.hub.Broadcast(channel+":"+source, data)
+			}
+		}
+	}
+
+	// 分组内交易对的行情同时推送到分组频道: ticker:BTCUSDT -> ticker:group:majors
+	// 已软删除的交易对不推送
+	if b.groups != nil && groupChannelTypes[channelType(channel)] && strings.Count(channel, ":") == 1 && !b.hub.channelHidden(channel) {
+		for _, group := range b.groups(channelSymbol(channel)) {
+			b.hub.Broadcast(channelType(channel)+":"+groupPrefix+group, data)
+		}
+	}
 }
 
 // Stop 停止广播器
@@ -96,7 +138,7 @@ func (b *Broadcaster) Stop() {
 
 // BroadcastTicker 广播Ticker消息（供Processor服务调用）
 func (b *Broadcaster) BroadcastTicker(symbol string, ticker interface{}) error {
-	channel := "ticker:" + symbol
+	channel := utils.MarketChannel(constants.DataTypeTicker, symbol)
 	data, err := json.Marshal(ticker)
 	if err != nil {
 		return err
@@ -108,7 +150,7 @@ func (b *Broadcaster) BroadcastTicker(symbol string, ticker interface{}) error {
 
 // BroadcastDepth 广播深度消息
 func (b *Broadcaster) BroadcastDepth(symbol string, depth interface{}) error {
-	channel := "depth:" + symbol
+	channel := utils.MarketChannel(constants.DataTypeDepth, symbol)
 	data, err := json.Marshal(depth)
 	if err != nil {
 		return err
@@ -119,7 +161,7 @@ func (b *Broadcaster) BroadcastDepth(symbol string, depth interface{}) error {
 
 // BroadcastTrade 广播成交消息
 func (b *Broadcaster) BroadcastTrade(symbol string, trade interface{}) error {
-	channel := "trade:" + symbol
+	channel := utils.MarketChannel(constants.DataTypeTrade, symbol)
 	data, err := json.Marshal(trade)
 	if err != nil {
 		return err
@@ -130,7 +172,7 @@ func (b *Broadcaster) BroadcastTrade(symbol string, trade interface{}) error {
 
 // BroadcastKline 广播K线消息
 func (b *Broadcaster) BroadcastKline(symbol, interval string, kline interface{}) error {
-	channel := "kline:" + symbol + ":" + interval
+	channel := utils.IntervalChannel(constants.DataTypeKline, symbol, interval)
 	data, err := json.Marshal(kline)
 	if err != nil {
 		return err
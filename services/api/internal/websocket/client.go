@@ -2,7 +2,14 @@ package websocket
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -40,6 +47,26 @@ type Client struct {
 
 	// 客户端ID（可选，用于日志）
 	id string
+
+	// 因过期被丢弃的消息数
+	staleDropped int64
+
+	// 连接的认证身份，作为 alert.subscribe 创建的提醒的创建者
+	owner string
+
+	// 经 alert.subscribe 创建且没有 Webhook 的价格提醒 ID，连接断开时删除，只在读协程中访问
+	alerts map[string]bool
+
+	// 入站消息限流（首条消息时创建）、令牌桶补满前累计被限流的消息数及是否被限流过，只在读协程中访问
+	limiter       *messageLimiter
+	throttled     int
+	everThrottled bool
+
+	// 已订阅、快照尚未发出的频道
+	pending pendingSnapshots
+
+	// depthDiff 频道已写出快照的 seq，只在写协程中访问
+	diffSeqs map[string]int64
 }
 
 // NewClient 创建新的客户端实例
@@ -55,6 +82,7 @@ func NewClient(hub *Hub, conn *websocket.Conn, id string) *Client {
 // readPump 从WebSocket连接读取消息
 func (c *Client) readPump() {
 	defer func() {
+		c.removeAlerts()
 		c.hub.Unregister(c)
 		c.conn.Close()
 	}()
@@ -76,24 +104,30 @@ func (c *Client) readPump() {
 			break
 		}
 
-		// 处理客户端消息
-		c.handleMessage(message)
+		// 处理客户端消息，持续超出限流时断开连接
+		if !c.handleMessage(message) {
+			break
+		}
 	}
 }
 
-// handleMessage 处理客户端发送的消息
-func (c *Client) handleMessage(message []byte) {
+// handleMessage 处理客户端发送的消息，返回 false 表示需要断开连接
+func (c *Client) handleMessage(message []byte) bool {
+	if allowed, keep := c.allowMessage(); !allowed {
+		return keep
+	}
+
 	var msg map[string]interface{}
 	if err := json.Unmarshal(message, &msg); err != nil {
 		log.Printf("[WebSocket Client %s] Invalid JSON: %v\n", c.id, err)
 		c.sendError("Invalid JSON format")
-		return
+		return true
 	}
 
 	action, ok := msg["action"].(string)
 	if !ok {
 		c.sendError("Missing 'action' field")
-		return
+		return true
 	}
 
 	switch action {
@@ -103,9 +137,14 @@ func (c *Client) handleMessage(message []byte) {
 		c.handleUnsubscribe(msg)
 	case "ping":
 		c.handlePing()
+	case "alert.subscribe":
+		c.handleAlertSubscribe(msg)
+	case "alert.unsubscribe":
+		c.handleAlertUnsubscribe(msg)
 	default:
 		c.sendError("Unknown action: " + action)
 	}
+	return true
 }
 
 // handleSubscribe 处理订阅请求
@@ -117,17 +156,63 @@ func (c *Client) handleSubscribe(msg map[string]interface{}) {
 	}
 
 	symbol, _ := msg["symbol"].(string) // symbol可选
+	symbol = strings.ToUpper(symbol)    // 与推送频道中的交易对一致
+
+	intervals, err := parseIntervals(msg, channel, symbol)
+	if err != nil {
+		c.sendError(err.Error())
+		return
+	}
+	source, err := parseSource(msg, channel, symbol)
+	if err != nil {
+		c.sendError(err.Error())
+		return
+	}
+	group, err := parseGroup(msg, channel, symbol)
+	if err != nil {
+		c.sendError(err.Error())
+		return
+	}
+	klineType, dataChannel, err := parseKlineType(msg, channel)
+	if err != nil {
+		c.sendError(err.Error())
+		return
+	}
 
 	// 构建完整的频道名
-	fullChannel := c.buildChannelName(channel, symbol)
+	channels := c.buildChannelNames(dataChannel, symbol, intervals, source, group)
 
-	c.hub.Subscribe(c, fullChannel)
+	for _, fullChannel := range channels {
+		if c.hub.channelHidden(fullChannel) {
+			c.sendError("Symbol not found: " + symbol)
+			return
+		}
+	}
 
-	// 发送订阅成功响应
-	c.sendResponse("subscribed", map[string]interface{}{
-		"channel": channel,
-		"symbol":  symbol,
-	})
+	// 新订阅的频道在快照发出前不推送
+	snapshots := c.snapshotTargets(channels)
+	for _, fullChannel := range snapshots {
+		c.pending.add(fullChannel)
+	}
+	if err := c.hub.Subscribe(c, channels...); err != nil {
+		for _, fullChannel := range snapshots {
+			c.pending.remove(fullChannel)
+		}
+		c.sendError(subscribeErrorMessage(c.hub, err))
+		return
+	}
+
+	// 发送订阅成功响应，之后推送当前数据的快照
+	c.sendResponse("subscribed", subscriptionData(channel, symbol, intervals, source, group, klineType))
+	c.sendSnapshots(snapshots)
+}
+
+// subscribeErrorMessage 订阅失败时返回给客户端的错误信息
+func subscribeErrorMessage(h *Hub, err error) string {
+	if errors.Is(err, ErrSubscriptionLimit) {
+		return fmt.Sprintf("Subscription limit exceeded: max %d channels per connection", h.MaxSubscriptions())
+	}
+	return err.Error()
 }
 
 // handleUnsubscribe 处理取消订阅请求
@@ -139,17 +224,36 @@ func (c *Client) handleUnsubscribe(msg map[string]interface{}) {
 	}
 
 	symbol, _ := msg["symbol"].(string) // symbol可选
+	symbol = strings.ToUpper(symbol)    // 与推送频道中的交易对一致
 
-	// 构建完整的频道名
-	fullChannel := c.buildChannelName(channel, symbol)
+	intervals, err := parseIntervals(msg, channel, symbol)
+	if err != nil {
+		c.sendError(err.Error())
+		return
+	}
+	source, err := parseSource(msg, channel, symbol)
+	if err != nil {
+		c.sendError(err.Error())
+		return
+	}
+	group, err := parseGroup(msg, channel, symbol)
+	if err != nil {
+		c.sendError(err.Error())
+		return
+	}
+	klineType, dataChannel, err := parseKlineType(msg, channel)
+	if err != nil {
+		c.sendError(err.Error())
+		return
+	}
 
-	c.hub.Unsubscribe(c, fullChannel)
+	// 构建完整的频道名
+	for _, fullChannel := range c.buildChannelNames(dataChannel, symbol, intervals, source, group) {
+		c.hub.Unsubscribe(c, fullChannel)
+	}
 
 	// 发送取消订阅成功响应
-	c.sendResponse("unsubscribed", map[string]interface{}{
-		"channel": channel,
-		"symbol":  symbol,
-	})
+	c.sendResponse("unsubscribed", subscriptionData(channel, symbol, intervals, source, group, klineType))
 }
 
 // handlePing 处理ping请求
@@ -161,11 +265,175 @@ func (c *Client) handlePing() {
 // 格式: channel:symbol 或 channel (如果symbol为空)
 func (c *Client) buildChannelName(channel, symbol string) string {
 	if symbol != "" {
-		return channel + ":" + symbol
+		return utils.MarketChannel(channel, symbol)
 	}
 	return channel
 }
 
+// buildChannelNames 构建请求涉及的全部频道名称
+// 指定 intervals 时每个周期一个频道: kline:symbol:interval
+// 指定 source 时为按数据源过滤的成交频道: trade:symbol:source
+// 指定 group 时为交易对分组频道: ticker:group:majors
+func (c *Client) buildChannelNames(channel, symbol string, intervals []string, source, group string) []string {
+	if group != "" {
+		return []string{channel + ":" + groupPrefix + group}
+	}
+	if source != "" {
+		return []string{c.buildChannelName(channel, symbol) + ":" + source}
+	}
+	if len(intervals) == 0 {
+		return []string{c.buildChannelName(channel, symbol)}
+	}
+
+	channels := make([]string, 0, len(intervals))
+	for _, interval := range intervals {
+		channels = append(channels, utils.IntervalChannel(channel, symbol, interval))
+	}
+	return channels
+}
+
+// parseIntervals 解析 kline、indicator 频道的周期：interval 订阅一个周期，intervals 一次订阅同一交易对的多个周期，
+// 两者可同时指定；这两类频道按周期推送（{type}:{symbol}:{interval}），必须指定交易对和至少一个周期
+func parseIntervals(msg map[string]interface{}, channel, symbol string) ([]string, error) {
+	var list []interface{}
+	if raw, ok := msg["interval"]; ok {
+		if _, ok := raw.(string); !ok {
+			return nil, errors.New("Invalid 'interval' field")
+		}
+		list = append(list, raw)
+	}
+	if raw, ok := msg["intervals"]; ok {
+		items, ok := raw.([]interface{})
+		if !ok {
+			return nil, errors.New("Invalid 'intervals' field")
+		}
+		if len(items) == 0 {
+			return nil, errors.New("Empty 'intervals' field")
+		}
+		list = append(list, items...)
+	}
+
+	if !intervalChannelTypes[channel] {
+		if list != nil {
+			return nil, errors.New("'interval' requires kline or indicator channel and symbol")
+		}
+		return nil, nil
+	}
+	if symbol == "" {
+		return nil, fmt.Errorf("'%s' channel requires symbol and interval", channel)
+	}
+	if len(list) == 0 {
+		return nil, errors.New("Missing 'interval' field")
+	}
+
+	intervals := make([]string, 0, len(list))
+	seen := make(map[string]bool, len(list))
+	for _, item := range list {
+		interval, ok := item.(string)
+		if !ok || !utils.ValidateInterval(interval) {
+			return nil, fmt.Errorf("Invalid interval: %v", item)
+		}
+		if !seen[interval] {
+			seen[interval] = true
+			intervals = append(intervals, interval)
+		}
+	}
+	return intervals, nil
+}
+
+// parseSource 解析可选的 source 字段（仅 trade 频道），按数据源过滤成交: internal / external / all
+// all 等同于不过滤，返回空
+func parseSource(msg map[string]interface{}, channel, symbol string) (string, error) {
+	raw, ok := msg["source"]
+	if !ok {
+		return "", nil
+	}
+
+	source, ok := raw.(string)
+	if !ok {
+		return "", errors.New("Invalid 'source' field")
+	}
+	if channel != constants.DataTypeTrade || symbol == "" {
+		return "", errors.New("'source' requires trade channel and symbol")
+	}
+
+	switch source {
+	case constants.SourceInternal, constants.SourceExternal:
+		return source, nil
+	case "", constants.SourceAll:
+		return "", nil
+	default:
+		return "", fmt.Errorf("Invalid source: %s", source)
+	}
+}
+
+// parseGroup 解析可选的 group 字段，订阅交易对分组内全部交易对的行情，不能同时指定 symbol
+// 分组不存在时订阅仍然成功，分组创建后开始推送
+func parseGroup(msg map[string]interface{}, channel, symbol string) (string, error) {
+	raw, ok := msg["group"]
+	if !ok {
+		return "", nil
+	}
+
+	group, ok := raw.(string)
+	if !ok {
+		return "", errors.New("Invalid 'group' field")
+	}
+	if !groupChannelTypes[channel] || symbol != "" {
+		return "", errors.New("'group' requires ticker, depth, trade or agg_trade channel without symbol")
+	}
+	if err := utils.ValidateGroupName(group); err != nil {
+		return "", fmt.Errorf("Invalid group: %s", group)
+	}
+	return group, nil
+}
+
+// parseKlineType 解析可选的 type 字段（仅 kline 频道），返回K线类型和实际订阅的频道类型：
+// heikin_ashi 订阅 kline_ha:{symbol}:{interval}，renko 订阅 kline_renko:{symbol}:{interval}，candle 等同于不指定
+func parseKlineType(msg map[string]interface{}, channel string) (string, string, error) {
+	raw, ok := msg["type"]
+	if !ok {
+		return "", channel, nil
+	}
+
+	klineType, ok := raw.(string)
+	if !ok {
+		return "", "", errors.New("Invalid 'type' field")
+	}
+	if channel != constants.DataTypeKline {
+		return "", "", errors.New("'type' requires kline channel")
+	}
+	dataType, ok := utils.KlineDataType(klineType)
+	if !ok {
+		return "", "", fmt.Errorf("Invalid type: %s", klineType)
+	}
+	if dataType == constants.DataTypeKline {
+		return "", channel, nil
+	}
+	return klineType, dataType, nil
+}
+
+// subscriptionData 订阅/取消订阅响应的数据
+func subscriptionData(channel, symbol string, intervals []string, source, group, klineType string) map[string]interface{} {
+	data := map[string]interface{}{
+		"channel": channel,
+		"symbol":  symbol,
+	}
+	if len(intervals) > 0 {
+		data["intervals"] = intervals
+	}
+	if source != "" {
+		data["source"] = source
+	}
+	if group != "" {
+		data["group"] = group
+	}
+	if klineType != "" {
+		data["type"] = klineType
+	}
+	return data
+}
+
 // writePump 向WebSocket连接写入消息
 func (c *Client) writePump() {
 	ticker := time.NewTicker(pingPeriod)
@@ -184,10 +452,9 @@ func (c *Client) writePump() {
 				return
 			}
 
-			// 将消息编码为JSON
-			jsonData, err := json.Marshal(message)
-			if err != nil {
-				log.Printf("[WebSocket Client %s] JSON marshal error: %v\n", c.id, err)
+			// 将消息编码为JSON（过期消息直接丢弃）
+			jsonData, ok := c.encodeMessage(message)
+			if !ok {
 				continue
 			}
 
@@ -200,13 +467,12 @@ func (c *Client) writePump() {
 			// 批量发送队列中的其他消息
 			n := len(c.send)
 			for i := 0; i < n; i++ {
-				w.Write(newline)
 				additionalMsg := <-c.send
-				additionalJSON, err := json.Marshal(additionalMsg)
-				if err != nil {
-					log.Printf("[WebSocket Client %s] JSON marshal error: %v\n", c.id, err)
+				additionalJSON, ok := c.encodeMessage(additionalMsg)
+				if !ok {
 					continue
 				}
+				w.Write(newline)
 				w.Write(additionalJSON)
 			}
 
@@ -223,6 +489,45 @@ func (c *Client) writePump() {
 	}
 }
 
+// encodeMessage 编码待发送消息，频道消息超过有效期时丢弃并计数，快照发出前的频道消息直接丢弃
+func (c *Client) encodeMessage(message interface{}) ([]byte, bool) {
+	if sm, ok := message.(*snapshotMessage); ok {
+		c.pending.remove(sm.Channel)
+		if diff, ok := sm.Data.(*models.DepthDiff); ok {
+			if c.diffSeqs == nil {
+				c.diffSeqs = make(map[string]int64)
+			}
+			c.diffSeqs[sm.Channel] = diff.Seq
+		}
+	}
+	if qm, ok := message.(*queuedMessage); ok {
+		if c.pending.has(qm.Channel) || c.staleDepthDiff(qm) {
+			return nil, false
+		}
+		if ttl := c.hub.messageTTL(qm.Channel); ttl > 0 && time.Since(qm.EnqueuedAt) > ttl {
+			dropped := atomic.AddInt64(&c.staleDropped, 1)
+			c.hub.recordStaleDrop(qm.Channel)
+			if dropped%100 == 1 {
+				log.Printf("[WebSocket Client %s] Dropping stale message on channel '%s', total dropped: %d\n",
+					c.id, qm.Channel, dropped)
+			}
+			return nil, false
+		}
+	}
+
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("[WebSocket Client %s] JSON marshal error: %v\n", c.id, err)
+		return nil, false
+	}
+	return jsonData, true
+}
+
+// StaleDropped 获取因过期被丢弃的消息数
+func (c *Client) StaleDropped() int64 {
+	return atomic.LoadInt64(&c.staleDropped)
+}
+
 // sendError 发送错误响应
 func (c *Client) sendError(errMsg string) {
 	response := map[string]interface{}{
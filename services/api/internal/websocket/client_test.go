@@ -0,0 +1,438 @@
+package websocket
+
+import (
+	"context"
+	"market-system/common/errcode"
+	"market-system/common/models"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubscribeKlineIntervals(t *testing.T) {
+	hub := NewHub()
+	client := &Client{hub: hub, send: make(chan interface{}, 8)}
+
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"kline","symbol":"BTCUSDT","intervals":["1m","1h","1m"]}`))
+
+	resp := (<-client.send).(map[string]interface{})
+	if resp["type"] != "subscribed" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if got := resp["data"].(map[string]interface{})["intervals"]; !reflect.DeepEqual(got, []string{"1m", "1h"}) {
+		t.Errorf("response intervals = %v", got)
+	}
+
+	subs := hub.GetSubscriptions(client)
+	sort.Strings(subs)
+	if want := []string{"kline:BTCUSDT:1h", "kline:BTCUSDT:1m"}; !reflect.DeepEqual(subs, want) {
+		t.Fatalf("subscriptions = %v, want %v", subs, want)
+	}
+
+	client.handleMessage([]byte(`{"action":"unsubscribe","channel":"kline","symbol":"BTCUSDT","intervals":["1h"]}`))
+	<-client.send
+	if subs := hub.GetSubscriptions(client); !reflect.DeepEqual(subs, []string{"kline:BTCUSDT:1m"}) {
+		t.Errorf("subscriptions after unsubscribe = %v", subs)
+	}
+}
+
+func TestSubscribeKlineInterval(t *testing.T) {
+	hub := NewHub()
+	client := &Client{hub: hub, send: make(chan interface{}, 8)}
+
+	// interval 与 intervals 合并去重，交易对统一为大写，与推送频道 kline:{symbol}:{interval} 一致
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"kline","symbol":"btcusdt","interval":"1m","intervals":["1m","5m"]}`))
+	if resp := (<-client.send).(map[string]interface{}); resp["type"] != "subscribed" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	subs := hub.GetSubscriptions(client)
+	sort.Strings(subs)
+	if want := []string{"kline:BTCUSDT:1m", "kline:BTCUSDT:5m"}; !reflect.DeepEqual(subs, want) {
+		t.Fatalf("subscriptions = %v, want %v", subs, want)
+	}
+
+	client.handleMessage([]byte(`{"action":"unsubscribe","channel":"kline","symbol":"BTCUSDT","interval":"5m"}`))
+	<-client.send
+	if subs := hub.GetSubscriptions(client); !reflect.DeepEqual(subs, []string{"kline:BTCUSDT:1m"}) {
+		t.Errorf("subscriptions after unsubscribe = %v", subs)
+	}
+}
+
+func TestSubscribeIndicatorIntervals(t *testing.T) {
+	hub := NewHub()
+	client := &Client{hub: hub, send: make(chan interface{}, 8)}
+
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"indicator","symbol":"BTCUSDT","intervals":["1h","4h"]}`))
+	if resp := (<-client.send).(map[string]interface{}); resp["type"] != "subscribed" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	subs := hub.GetSubscriptions(client)
+	sort.Strings(subs)
+	if want := []string{"indicator:BTCUSDT:1h", "indicator:BTCUSDT:4h"}; !reflect.DeepEqual(subs, want) {
+		t.Errorf("subscriptions = %v, want %v", subs, want)
+	}
+}
+
+func TestSubscribeKlineType(t *testing.T) {
+	hub := NewHub()
+	client := &Client{hub: hub, send: make(chan interface{}, 8)}
+
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"kline","symbol":"BTCUSDT","intervals":["1m"],"type":"heikin_ashi"}`))
+	resp := (<-client.send).(map[string]interface{})
+	if resp["type"] != "subscribed" || resp["data"].(map[string]interface{})["type"] != "heikin_ashi" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"kline","symbol":"BTCUSDT","intervals":["1m"],"type":"renko"}`))
+	<-client.send
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"kline","symbol":"BTCUSDT","intervals":["5m"],"type":"candle"}`))
+	<-client.send
+
+	subs := hub.GetSubscriptions(client)
+	sort.Strings(subs)
+	if want := []string{"kline:BTCUSDT:5m", "kline_ha:BTCUSDT:1m", "kline_renko:BTCUSDT:1m"}; !reflect.DeepEqual(subs, want) {
+		t.Fatalf("subscriptions = %v, want %v", subs, want)
+	}
+
+	for _, msg := range []string{
+		`{"action":"subscribe","channel":"kline","symbol":"BTCUSDT","intervals":["1m"],"type":"point_figure"}`,
+		`{"action":"subscribe","channel":"ticker","symbol":"BTCUSDT","type":"renko"}`,
+	} {
+		client.handleMessage([]byte(msg))
+		if resp := (<-client.send).(map[string]interface{}); resp["type"] != "error" {
+			t.Errorf("%s: expected error, got %+v", msg, resp)
+		}
+	}
+}
+
+func TestSubscribeInvalidIntervals(t *testing.T) {
+	cases := []string{
+		`{"action":"subscribe","channel":"kline","symbol":"BTCUSDT","intervals":["2m"]}`,
+		`{"action":"subscribe","channel":"kline","symbol":"BTCUSDT","intervals":[]}`,
+		`{"action":"subscribe","channel":"kline","intervals":["1m"]}`,
+		`{"action":"subscribe","channel":"ticker","symbol":"BTCUSDT","intervals":["1m"]}`,
+		`{"action":"subscribe","channel":"kline","symbol":"BTCUSDT"}`,
+		`{"action":"subscribe","channel":"kline","symbol":"BTCUSDT","interval":"2m"}`,
+		`{"action":"subscribe","channel":"kline","symbol":"BTCUSDT","interval":1}`,
+		`{"action":"subscribe","channel":"ticker","symbol":"BTCUSDT","interval":"1m"}`,
+	}
+	for _, msg := range cases {
+		hub := NewHub()
+		client := &Client{hub: hub, send: make(chan interface{}, 8)}
+
+		client.handleMessage([]byte(msg))
+		if resp := (<-client.send).(map[string]interface{}); resp["type"] != "error" {
+			t.Errorf("%s: expected error, got %+v", msg, resp)
+		}
+		if subs := hub.GetSubscriptions(client); len(subs) != 0 {
+			t.Errorf("%s: unexpected subscriptions %v", msg, subs)
+		}
+	}
+}
+
+func TestSubscribeTradeSource(t *testing.T) {
+	hub := NewHub()
+	client := &Client{hub: hub, send: make(chan interface{}, 8)}
+
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"trade","symbol":"BTCUSDT","source":"internal"}`))
+	resp := (<-client.send).(map[string]interface{})
+	if resp["type"] != "subscribed" || resp["data"].(map[string]interface{})["source"] != "internal" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"trade","symbol":"ETHUSDT","source":"all"}`))
+	<-client.send
+
+	subs := hub.GetSubscriptions(client)
+	sort.Strings(subs)
+	if want := []string{"trade:BTCUSDT:internal", "trade:ETHUSDT"}; !reflect.DeepEqual(subs, want) {
+		t.Fatalf("subscriptions = %v, want %v", subs, want)
+	}
+
+	for _, msg := range []string{
+		`{"action":"subscribe","channel":"trade","symbol":"BTCUSDT","source":"merged"}`,
+		`{"action":"subscribe","channel":"ticker","symbol":"BTCUSDT","source":"internal"}`,
+		`{"action":"subscribe","channel":"trade","source":"internal"}`,
+	} {
+		client.handleMessage([]byte(msg))
+		if resp := (<-client.send).(map[string]interface{}); resp["type"] != "error" {
+			t.Errorf("%s: expected error, got %+v", msg, resp)
+		}
+	}
+}
+
+func TestSubscribeGroup(t *testing.T) {
+	hub := NewHub()
+	client := &Client{hub: hub, send: make(chan interface{}, 8)}
+
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"ticker","group":"majors"}`))
+	resp := (<-client.send).(map[string]interface{})
+	if resp["type"] != "subscribed" || resp["data"].(map[string]interface{})["group"] != "majors" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if subs := hub.GetSubscriptions(client); !reflect.DeepEqual(subs, []string{"ticker:group:majors"}) {
+		t.Fatalf("subscriptions = %v", subs)
+	}
+
+	for _, msg := range []string{
+		`{"action":"subscribe","channel":"ticker","symbol":"BTCUSDT","group":"majors"}`,
+		`{"action":"subscribe","channel":"kline","group":"majors"}`,
+		`{"action":"subscribe","channel":"ticker","group":"Majors!"}`,
+		`{"action":"subscribe","channel":"trade","group":"majors","source":"internal"}`,
+	} {
+		client.handleMessage([]byte(msg))
+		if resp := (<-client.send).(map[string]interface{}); resp["type"] != "error" {
+			t.Errorf("%s: expected error, got %+v", msg, resp)
+		}
+	}
+
+	client.handleMessage([]byte(`{"action":"unsubscribe","channel":"ticker","group":"majors"}`))
+	<-client.send
+	if subs := hub.GetSubscriptions(client); len(subs) != 0 {
+		t.Errorf("subscriptions after unsubscribe = %v", subs)
+	}
+}
+
+type memoryAlertStore struct {
+	alerts map[string]*models.PriceAlert
+}
+
+func (s *memoryAlertStore) Create(ctx context.Context, alert *models.PriceAlert) error {
+	if alert.Condition == "" {
+		return errcode.Newf(errcode.ErrInvalidParam, "invalid condition: %s", alert.Condition)
+	}
+	alert.ID = "alert-" + alert.Symbol
+	s.alerts[alert.ID] = alert
+	return nil
+}
+
+func (s *memoryAlertStore) Delete(ctx context.Context, owner, id string) error {
+	if alert, ok := s.alerts[id]; !ok || alert.Owner != owner {
+		return errcode.Newf(errcode.ErrNotFound, "price alert not found: %s", id)
+	}
+	delete(s.alerts, id)
+	return nil
+}
+
+func TestAlertSubscribe(t *testing.T) {
+	store := &memoryAlertStore{alerts: make(map[string]*models.PriceAlert)}
+	hub := NewHub()
+	hub.SetAlertStore(store)
+	client := &Client{hub: hub, send: make(chan interface{}, 8), owner: "alice"}
+
+	client.handleMessage([]byte(`{"action":"alert.subscribe","symbol":"BTCUSDT","condition":"price_cross_up","value":70000}`))
+	resp := (<-client.send).(map[string]interface{})
+	if resp["type"] != "alert.subscribed" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	alert := store.alerts["alert-BTCUSDT"]
+	if alert == nil || alert.Owner != "alice" || alert.Value != 70000 {
+		t.Fatalf("alert = %+v", alert)
+	}
+	if subs := hub.GetSubscriptions(client); !reflect.DeepEqual(subs, []string{"price_alert:alert-BTCUSDT"}) {
+		t.Fatalf("subscriptions = %v", subs)
+	}
+
+	client.handleMessage([]byte(`{"action":"alert.subscribe","symbol":"ETHUSDT"}`))
+	if resp := (<-client.send).(map[string]interface{}); resp["type"] != "error" {
+		t.Errorf("expected error, got %+v", resp)
+	}
+
+	// 未认证的连接不能创建提醒
+	anonymous := &Client{hub: hub, send: make(chan interface{}, 8)}
+	anonymous.handleMessage([]byte(`{"action":"alert.subscribe","symbol":"ETHUSDT","condition":"price_cross_up","value":3000}`))
+	if resp := (<-anonymous.send).(map[string]interface{}); resp["error"] != "Price alerts require authentication" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	// 连接断开时删除没有 Webhook 的提醒
+	client.handleMessage([]byte(`{"action":"alert.subscribe","symbol":"SOLUSDT","condition":"change_above","value":5}`))
+	<-client.send
+	client.handleMessage([]byte(`{"action":"alert.unsubscribe","id":"alert-BTCUSDT"}`))
+	if resp := (<-client.send).(map[string]interface{}); resp["type"] != "alert.unsubscribed" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	client.removeAlerts()
+	if len(store.alerts) != 0 {
+		t.Errorf("alerts after disconnect = %v", store.alerts)
+	}
+}
+
+func TestSubscriptionLimit(t *testing.T) {
+	hub := NewHub()
+	hub.SetMaxSubscriptions(3)
+	client := &Client{hub: hub, send: make(chan interface{}, 8)}
+
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"kline","symbol":"BTCUSDT","intervals":["1m","5m"]}`))
+	if resp := (<-client.send).(map[string]interface{}); resp["type"] != "subscribed" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	// 超过上限时全部不订阅
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"kline","symbol":"BTCUSDT","intervals":["1m","1h","4h"]}`))
+	resp := (<-client.send).(map[string]interface{})
+	if resp["type"] != "error" || resp["error"] != "Subscription limit exceeded: max 3 channels per connection" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if subs := hub.GetSubscriptions(client); len(subs) != 2 {
+		t.Fatalf("subscriptions = %v", subs)
+	}
+
+	// 已订阅的频道不重复计数
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"kline","symbol":"BTCUSDT","intervals":["1m","1h"]}`))
+	if resp := (<-client.send).(map[string]interface{}); resp["type"] != "subscribed" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if subs := hub.GetSubscriptions(client); len(subs) != 3 {
+		t.Errorf("subscriptions = %v", subs)
+	}
+}
+
+func TestMessageRateLimit(t *testing.T) {
+	hub := NewHub()
+	hub.SetMessageRateLimit(1, 2, 2)
+	client := &Client{hub: hub, send: make(chan interface{}, 16)}
+
+	// 突发容量内的消息正常处理
+	for i := 0; i < 2; i++ {
+		if !client.handleMessage([]byte(`{"action":"ping"}`)) {
+			t.Fatal("unexpected disconnect")
+		}
+		if resp := (<-client.send).(map[string]interface{}); resp["type"] != "pong" {
+			t.Fatalf("unexpected response: %+v", resp)
+		}
+	}
+
+	// 超限的消息丢弃并警告，超过警告次数后断开
+	for i := 1; i <= 3; i++ {
+		keep := client.handleMessage([]byte(`{"action":"ping"}`))
+		resp := (<-client.send).(map[string]interface{})
+		if resp["type"] != "error" || keep != (i <= 2) {
+			t.Fatalf("message %d: keep = %v, response = %+v", i, keep, resp)
+		}
+	}
+
+	if stats := hub.ThrottleStats(); stats != (ThrottleStats{ThrottledMessages: 3, ThrottledClients: 1, Disconnected: 1}) {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestMessageRateLimitReset(t *testing.T) {
+	hub := NewHub()
+	hub.SetMessageRateLimit(1, 2, 2)
+	client := &Client{hub: hub, send: make(chan interface{}, 16)}
+
+	// send 发送一条消息，返回是否被限流及限流警告
+	send := func() (throttled bool, warning string) {
+		if !client.handleMessage([]byte(`{"action":"ping"}`)) {
+			t.Fatal("unexpected disconnect")
+		}
+		resp := (<-client.send).(map[string]interface{})
+		if resp["type"] != "error" {
+			return false, ""
+		}
+		return true, resp["error"].(string)
+	}
+	// elapse 模拟经过的时间
+	elapse := func(d time.Duration) {
+		client.limiter.last = client.limiter.last.Add(-d)
+	}
+
+	send()
+	send()
+	if throttled, warning := send(); !throttled || !strings.HasSuffix(warning, "warning 1/2") {
+		t.Fatalf("warning = %q", warning)
+	}
+
+	// 令牌桶补满后警告次数清零
+	elapse(2 * time.Second)
+	for i := 0; i < 2; i++ {
+		if throttled, _ := send(); throttled {
+			t.Fatalf("message %d throttled after refill", i)
+		}
+	}
+	if throttled, warning := send(); !throttled || !strings.HasSuffix(warning, "warning 1/2") {
+		t.Fatalf("warning after refill = %q", warning)
+	}
+
+	// 未补满时继续累计
+	elapse(time.Second)
+	if throttled, _ := send(); throttled {
+		t.Fatal("message throttled after partial refill")
+	}
+	if throttled, warning := send(); !throttled || !strings.HasSuffix(warning, "warning 2/2") {
+		t.Fatalf("warning after partial refill = %q", warning)
+	}
+	if client.handleMessage([]byte(`{"action":"ping"}`)) {
+		t.Error("client not disconnected")
+	}
+	<-client.send
+
+	// 同一连接只计一次被限流的连接
+	if stats := hub.ThrottleStats(); stats != (ThrottleStats{ThrottledMessages: 4, ThrottledClients: 1, Disconnected: 1}) {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+type memorySnapshots map[string]interface{}
+
+func (s memorySnapshots) Snapshot(ctx context.Context, channel string) (interface{}, error) {
+	return s[channel], nil
+}
+
+func TestSubscribeSnapshot(t *testing.T) {
+	hub := NewHub()
+	hub.SetSnapshotSource(memorySnapshots{
+		"ticker:BTCUSDT":      map[string]interface{}{"last_price": 43250.5},
+		"kline:BTCUSDT:1m":    map[string]interface{}{"close": 43250.5},
+		"depth:ETHUSDT":       map[string]interface{}{"bids": []interface{}{}},
+		"ticker:group:majors": map[string]interface{}{"last_price": 1},
+	})
+	client := &Client{hub: hub, send: make(chan interface{}, 8)}
+
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"ticker","symbol":"BTCUSDT"}`))
+	if resp := (<-client.send).(map[string]interface{}); resp["type"] != "subscribed" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if snapshot, ok := (<-client.send).(*snapshotMessage); !ok || snapshot.Channel != "ticker:BTCUSDT" {
+		t.Fatalf("expected ticker snapshot, got %+v", snapshot)
+	}
+
+	// 没有数据的周期不推送快照
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"kline","symbol":"BTCUSDT","intervals":["1m","1h"]}`))
+	<-client.send
+	if snapshot, ok := (<-client.send).(*snapshotMessage); !ok || snapshot.Channel != "kline:BTCUSDT:1m" {
+		t.Fatalf("expected kline snapshot, got %+v", snapshot)
+	}
+
+	// 已订阅的频道、分组频道不推送快照
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"ticker","symbol":"BTCUSDT"}`))
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"ticker","group":"majors"}`))
+	<-client.send
+	<-client.send
+	if len(client.send) != 0 {
+		t.Fatalf("unexpected message: %+v", <-client.send)
+	}
+}
+
+func TestSnapshotPrecedesUpdates(t *testing.T) {
+	hub := NewHub()
+	hub.SetSnapshotSource(memorySnapshots{"depth:ETHUSDT": map[string]interface{}{"timestamp": 2}})
+	client := &Client{hub: hub, send: make(chan interface{}, 8)}
+
+	// 快照入队前到达的推送在写出时丢弃
+	client.pending.add("depth:ETHUSDT")
+	if _, ok := client.encodeMessage(newQueuedMessage("depth:ETHUSDT", map[string]interface{}{"timestamp": 1})); ok {
+		t.Error("update before snapshot should be dropped")
+	}
+	client.sendSnapshots([]string{"depth:ETHUSDT"})
+	if _, ok := client.encodeMessage(<-client.send); !ok {
+		t.Fatal("snapshot not encoded")
+	}
+	if _, ok := client.encodeMessage(newQueuedMessage("depth:ETHUSDT", map[string]interface{}{"timestamp": 3})); !ok {
+		t.Error("update after snapshot should be sent")
+	}
+}
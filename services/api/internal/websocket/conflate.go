@@ -0,0 +1,104 @@
+package websocket
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// conflator 按频道合并推送：同一频道在合并窗口内最多推送一次，窗口内后到的消息只保留最新一条，窗口结束时推送
+// 窗口开始时的第一条消息立即推送，不增加延迟；分组频道包含多个交易对的数据，不合并
+type conflator struct {
+	mu       sync.Mutex
+	windows  map[string]time.Duration // 各数据类型的合并窗口
+	channels map[string]*conflatedChannel
+	merged   map[string]int64 // 各数据类型被合并（未推送）的消息数
+}
+
+type conflatedChannel struct {
+	last      time.Time   // 上一次推送的时间
+	pending   interface{} // 等待窗口结束推送的最新消息
+	scheduled bool
+}
+
+func newConflator() *conflator {
+	return &conflator{
+		windows:  make(map[string]time.Duration),
+		channels: make(map[string]*conflatedChannel),
+		merged:   make(map[string]int64),
+	}
+}
+
+// setWindow 设置数据类型的合并窗口，window <= 0 表示不合并
+func (c *conflator) setWindow(dataType string, window time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if window <= 0 {
+		delete(c.windows, dataType)
+		return
+	}
+	c.windows[dataType] = window
+}
+
+// hold 判断消息是否需要等待合并窗口结束，返回 false 时由调用方立即推送
+// 需要等待时保存为频道的最新消息，窗口结束时调用 flush 推送
+func (c *conflator) hold(channel string, data interface{}, now time.Time, flush func(channel string, delay time.Duration)) bool {
+	dataType := channelType(channel)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	window := c.windows[dataType]
+	if window <= 0 || strings.Contains(channel, ":"+groupPrefix) {
+		return false
+	}
+
+	ch, ok := c.channels[channel]
+	if !ok {
+		ch = &conflatedChannel{}
+		c.channels[channel] = ch
+	}
+	if !ch.scheduled && now.Sub(ch.last) >= window {
+		ch.last = now
+		return false
+	}
+
+	if ch.pending != nil {
+		c.merged[dataType]++
+	}
+	ch.pending = data
+	if !ch.scheduled {
+		ch.scheduled = true
+		flush(channel, ch.last.Add(window).Sub(now))
+	}
+	return true
+}
+
+// take 取出窗口结束时需要推送的最新消息
+func (c *conflator) take(channel string, now time.Time) interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch, ok := c.channels[channel]
+	if !ok {
+		return nil
+	}
+	data := ch.pending
+	ch.pending = nil
+	ch.scheduled = false
+	ch.last = now
+	return data
+}
+
+// stats 各数据类型被合并的消息数
+func (c *conflator) stats() map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := make(map[string]int64, len(c.merged))
+	for k, v := range c.merged {
+		stats[k] = v
+	}
+	return stats
+}
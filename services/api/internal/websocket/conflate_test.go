@@ -0,0 +1,51 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConflateDepth(t *testing.T) {
+	hub := NewHub()
+	hub.SetConflation("depth", 50*time.Millisecond)
+
+	receive := func() *BroadcastMessage {
+		select {
+		case msg := <-hub.broadcast:
+			return msg
+		case <-time.After(time.Second):
+			t.Fatal("no message")
+			return nil
+		}
+	}
+
+	// 窗口开始时的第一条立即推送，窗口内只在结束时推送最新的一条
+	hub.Broadcast("depth:BTCUSDT", 1)
+	hub.Broadcast("depth:BTCUSDT", 2)
+	hub.Broadcast("depth:BTCUSDT", 3)
+	if msg := receive(); msg.Data != 1 {
+		t.Fatalf("first message = %+v", msg)
+	}
+	if len(hub.broadcast) != 0 {
+		t.Fatal("conflated messages sent before window ends")
+	}
+
+	// 其他频道、分组频道和未设置窗口的数据类型不受影响
+	hub.Broadcast("depth:ETHUSDT", 10)
+	hub.Broadcast("depth:group:majors", 20)
+	hub.Broadcast("depth:group:majors", 21)
+	hub.Broadcast("ticker:BTCUSDT", 30)
+	hub.Broadcast("ticker:BTCUSDT", 31)
+	for _, want := range []interface{}{10, 20, 21, 30, 31} {
+		if msg := receive(); msg.Data != want {
+			t.Fatalf("message = %+v, want %v", msg, want)
+		}
+	}
+
+	if msg := receive(); msg.Channel != "depth:BTCUSDT" || msg.Data != 3 {
+		t.Fatalf("conflated message = %+v", msg)
+	}
+	if stats := hub.ConflationStats(); stats["depth"] != 1 {
+		t.Errorf("stats = %v", stats)
+	}
+}
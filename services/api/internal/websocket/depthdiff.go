@@ -0,0 +1,257 @@
+package websocket
+
+import (
+	"log"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"sort"
+	"sync"
+	"time"
+)
+
+// depthDiffer 按深度快照计算 depthDiff 频道的增量，seq 为本实例内交易对的序号
+// 只为有订阅者的交易对保存订单簿，没有订阅者时清除，之后的第一条消息为全量深度
+type depthDiffer struct {
+	mu               sync.Mutex
+	books            map[string]*diffBook
+	snapshotInterval time.Duration // 推送全量深度的间隔
+}
+
+type diffBook struct {
+	bids, asks   map[float64]float64
+	seq          int64
+	timestamp    int64
+	lastSnapshot time.Time
+	mirrored     bool // 从 active 实例同步、本实例还没有订阅者的订单簿，没有订阅者时继续更新而不清除
+}
+
+// DepthDiffState depthDiff 频道单个交易对的订单簿和 seq，热备时由 active 实例同步到 standby 实例
+type DepthDiffState struct {
+	Symbol    string              `json:"symbol"`
+	Seq       int64               `json:"seq"`
+	Timestamp int64               `json:"timestamp"`
+	Bids      []models.PriceLevel `json:"bids"`
+	Asks      []models.PriceLevel `json:"asks"`
+}
+
+func newDepthDiffer(snapshotInterval time.Duration) *depthDiffer {
+	return &depthDiffer{
+		books:            make(map[string]*diffBook),
+		snapshotInterval: snapshotInterval,
+	}
+}
+
+// update 以新的深度快照更新订单簿，返回需要推送的增量或全量深度，没有变化时返回 nil
+func (d *depthDiffer) update(book *models.OrderBook, now time.Time) *models.DepthDiff {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if b, ok := d.books[book.Symbol]; ok {
+		b.mirrored = false
+	}
+	return d.updateLocked(book, now)
+}
+
+// follow 没有订阅者时更新从 active 实例同步的订单簿，seq 与 active 实例按相同的深度快照推进，不推送
+// 不是同步的订单簿时清除（与 reset 相同），返回是否更新
+func (d *depthDiffer) follow(book *models.OrderBook, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	b, ok := d.books[book.Symbol]
+	if !ok || !b.mirrored {
+		delete(d.books, book.Symbol)
+		return false
+	}
+	d.updateLocked(book, now)
+	return true
+}
+
+// mirrored 交易对的订单簿是否为从 active 实例同步、本实例还没有订阅者的订单簿
+func (d *depthDiffer) mirrored(symbol string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	b, ok := d.books[symbol]
+	return ok && b.mirrored
+}
+
+// updateLocked 以新的深度快照更新订单簿，调用方持有锁
+func (d *depthDiffer) updateLocked(book *models.OrderBook, now time.Time) *models.DepthDiff {
+	bids, asks := levelMap(book.Bids), levelMap(book.Asks)
+	b, ok := d.books[book.Symbol]
+	if !ok || now.Sub(b.lastSnapshot) >= d.snapshotInterval {
+		if !ok {
+			b = &diffBook{}
+			d.books[book.Symbol] = b
+		}
+		b.bids, b.asks, b.timestamp, b.lastSnapshot = bids, asks, book.Timestamp, now
+		b.seq++
+		return b.full(book.Symbol, b.seq-1)
+	}
+
+	diff := &models.DepthDiff{
+		Symbol:    book.Symbol,
+		Bids:      changedLevels(b.bids, bids, true),
+		Asks:      changedLevels(b.asks, asks, false),
+		Timestamp: book.Timestamp,
+	}
+	if len(diff.Bids) == 0 && len(diff.Asks) == 0 {
+		return nil
+	}
+	b.bids, b.asks, b.timestamp = bids, asks, book.Timestamp
+	diff.PrevSeq = b.seq
+	b.seq++
+	diff.Seq = b.seq
+	return diff
+}
+
+// snapshot 交易对当前的全量深度（订阅时推送），在持有锁时交给 send 加入发送队列，没有保存订单簿时返回 false
+// 入队前不会产生新的增量，之后的增量排在快照之后，seq 与快照连续
+func (d *depthDiffer) snapshot(symbol string, send func(snapshot *models.DepthDiff)) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	b, ok := d.books[symbol]
+	if !ok {
+		return false
+	}
+	b.mirrored = false
+	send(b.full(symbol, b.seq-1))
+	return true
+}
+
+// export 所有订单簿的状态，active 实例同步到 standby 实例
+func (d *depthDiffer) export() []DepthDiffState {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	states := make([]DepthDiffState, 0, len(d.books))
+	for symbol, b := range d.books {
+		states = append(states, DepthDiffState{
+			Symbol:    symbol,
+			Seq:       b.seq,
+			Timestamp: b.timestamp,
+			Bids:      sortedLevels(b.bids, true),
+			Asks:      sortedLevels(b.asks, false),
+		})
+	}
+	return states
+}
+
+// mirror 以 active 实例的状态替换同步的订单簿，返回同步的交易对数
+// 本实例已有订阅者的订单簿不替换，避免已推送的 seq 回退；active 实例不再保存的交易对清除
+func (d *depthDiffer) mirror(states []DepthDiffState, now time.Time) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	active := make(map[string]bool, len(states))
+	mirrored := 0
+	for _, state := range states {
+		active[state.Symbol] = true
+		if b, ok := d.books[state.Symbol]; ok && !b.mirrored {
+			continue
+		}
+		d.books[state.Symbol] = &diffBook{
+			bids:         levelMap(state.Bids),
+			asks:         levelMap(state.Asks),
+			seq:          state.Seq,
+			timestamp:    state.Timestamp,
+			lastSnapshot: now,
+			mirrored:     true,
+		}
+		mirrored++
+	}
+	for symbol, b := range d.books {
+		if b.mirrored && !active[symbol] {
+			delete(d.books, symbol)
+		}
+	}
+	return mirrored
+}
+
+// reset 清除交易对的订单簿（没有订阅者）
+func (d *depthDiffer) reset(symbol string) {
+	d.mu.Lock()
+	delete(d.books, symbol)
+	d.mu.Unlock()
+}
+
+func (b *diffBook) full(symbol string, prevSeq int64) *models.DepthDiff {
+	return &models.DepthDiff{
+		Symbol:    symbol,
+		Seq:       b.seq,
+		PrevSeq:   prevSeq,
+		Snapshot:  true,
+		Bids:      sortedLevels(b.bids, true),
+		Asks:      sortedLevels(b.asks, false),
+		Timestamp: b.timestamp,
+	}
+}
+
+func levelMap(levels []models.PriceLevel) map[float64]float64 {
+	m := make(map[float64]float64, len(levels))
+	for _, level := range levels {
+		if level.Amount > 0 {
+			m[level.Price] = level.Amount
+		}
+	}
+	return m
+}
+
+// changedLevels 新增、数量变化和删除（数量为 0）的档位，买盘按价格从高到低，卖盘从低到高
+func changedLevels(old, cur map[float64]float64, desc bool) []models.PriceLevel {
+	changed := make(map[float64]float64)
+	for price, amount := range cur {
+		if old[price] != amount {
+			changed[price] = amount
+		}
+	}
+	for price := range old {
+		if _, ok := cur[price]; !ok {
+			changed[price] = 0
+		}
+	}
+	return sortedLevels(changed, desc)
+}
+
+func sortedLevels(m map[float64]float64, desc bool) []models.PriceLevel {
+	levels := make([]models.PriceLevel, 0, len(m))
+	for price, amount := range m {
+		levels = append(levels, models.PriceLevel{Price: price, Amount: amount})
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		if desc {
+			return levels[i].Price > levels[j].Price
+		}
+		return levels[i].Price < levels[j].Price
+	})
+	return levels
+}
+
+// broadcastDepthDiff 按 depth:{symbol} 频道的深度快照推送 depthDiff:{symbol} 频道，没有订阅者时不解析也不计算
+// 没有订阅者但订单簿从 active 实例同步时继续更新，故障切换后订阅的客户端 seq 与切换前连续
+func (h *Hub) broadcastDepthDiff(symbol string, payload []byte) {
+	channel := utils.MarketChannel(constants.DataTypeDepthDiff, symbol)
+	subscribed := h.subscriptionManager.HasSubscribers(channel)
+	if !subscribed && !h.depthDiffs.mirrored(symbol) {
+		h.depthDiffs.reset(symbol)
+		return
+	}
+
+	var book models.OrderBook
+	if err := codec.Unmarshal(payload, &book); err != nil {
+		log.Printf("[WebSocket Hub] Failed to parse depth for %s: %v\n", symbol, err)
+		return
+	}
+	book.Symbol = symbol
+	if !subscribed {
+		h.depthDiffs.follow(&book, time.Now())
+		return
+	}
+	if diff := h.depthDiffs.update(&book, time.Now()); diff != nil {
+		h.Broadcast(channel, diff)
+	}
+}
@@ -0,0 +1,180 @@
+package websocket
+
+import (
+	"market-system/common/models"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func book(bids, asks []models.PriceLevel) *models.OrderBook {
+	return &models.OrderBook{Symbol: "BTCUSDT", Bids: bids, Asks: asks}
+}
+
+func TestDepthDiffer(t *testing.T) {
+	d := newDepthDiffer(10 * time.Second)
+	now := time.Unix(0, 0)
+
+	first := d.update(book(
+		[]models.PriceLevel{{Price: 100, Amount: 1}, {Price: 99, Amount: 2}},
+		[]models.PriceLevel{{Price: 101, Amount: 1}},
+	), now)
+	if !first.Snapshot || first.Seq != 1 || len(first.Bids) != 2 {
+		t.Fatalf("first = %+v", first)
+	}
+
+	// 只推送变化的档位，删除的档位数量为 0
+	diff := d.update(book(
+		[]models.PriceLevel{{Price: 100, Amount: 1.5}},
+		[]models.PriceLevel{{Price: 101, Amount: 1}, {Price: 102, Amount: 3}},
+	), now.Add(time.Second))
+	if diff.Snapshot || diff.Seq != 2 || diff.PrevSeq != 1 {
+		t.Fatalf("diff = %+v", diff)
+	}
+	if want := []models.PriceLevel{{Price: 100, Amount: 1.5}, {Price: 99, Amount: 0}}; !reflect.DeepEqual(diff.Bids, want) {
+		t.Errorf("bids = %v, want %v", diff.Bids, want)
+	}
+	if want := []models.PriceLevel{{Price: 102, Amount: 3}}; !reflect.DeepEqual(diff.Asks, want) {
+		t.Errorf("asks = %v, want %v", diff.Asks, want)
+	}
+
+	// 没有变化时不推送
+	if diff := d.update(book(
+		[]models.PriceLevel{{Price: 100, Amount: 1.5}},
+		[]models.PriceLevel{{Price: 101, Amount: 1}, {Price: 102, Amount: 3}},
+	), now.Add(2*time.Second)); diff != nil {
+		t.Errorf("unexpected diff %+v", diff)
+	}
+
+	// 订阅时的全量深度与最新的 seq 一致
+	var snapshot *models.DepthDiff
+	d.snapshot("BTCUSDT", func(s *models.DepthDiff) { snapshot = s })
+	if snapshot == nil || !snapshot.Snapshot || snapshot.Seq != 2 || len(snapshot.Asks) != 2 {
+		t.Errorf("snapshot = %+v", snapshot)
+	}
+
+	// 到达间隔后推送全量深度，seq 连续
+	full := d.update(book([]models.PriceLevel{{Price: 100, Amount: 1.5}}, nil), now.Add(11*time.Second))
+	if !full.Snapshot || full.Seq != 3 || full.PrevSeq != 2 || len(full.Asks) != 0 {
+		t.Errorf("periodic snapshot = %+v", full)
+	}
+
+	d.reset("BTCUSDT")
+	if d.snapshot("BTCUSDT", func(*models.DepthDiff) { t.Error("unexpected snapshot after reset") }) {
+		t.Error("expected no snapshot after reset")
+	}
+}
+
+func TestBroadcastDepthDiff(t *testing.T) {
+	hub := NewHub()
+	payload := []byte(`{"symbol":"BTCUSDT","bids":[{"price":100,"amount":1}],"asks":[],"timestamp":1}`)
+
+	// 没有订阅者时不推送
+	hub.broadcastDepthDiff("BTCUSDT", payload)
+	if len(hub.broadcast) != 0 {
+		t.Fatal("unexpected broadcast without subscribers")
+	}
+
+	client := &Client{hub: hub, send: make(chan interface{}, 8)}
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"depthDiff","symbol":"BTCUSDT"}`))
+	if resp := (<-client.send).(map[string]interface{}); resp["type"] != "subscribed" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	hub.broadcastDepthDiff("BTCUSDT", payload)
+	msg := <-hub.broadcast
+	if diff := msg.Data.(*models.DepthDiff); msg.Channel != "depthDiff:BTCUSDT" || !diff.Snapshot || diff.Seq != 1 {
+		t.Fatalf("message = %+v", msg)
+	}
+}
+
+func TestDepthDiffSnapshotSequence(t *testing.T) {
+	hub := NewHub()
+	first := &Client{hub: hub, send: make(chan interface{}, 8)}
+	first.handleMessage([]byte(`{"action":"subscribe","channel":"depthDiff","symbol":"BTCUSDT"}`))
+	<-first.send
+
+	// seq 1 已产生但尚未分发时新的连接订阅，快照的 seq 为 1
+	hub.broadcastDepthDiff("BTCUSDT", []byte(`{"bids":[{"price":100,"amount":1}],"asks":[],"timestamp":1}`))
+	client := &Client{hub: hub, send: make(chan interface{}, 8)}
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"depthDiff","symbol":"BTCUSDT"}`))
+	if resp := (<-client.send).(map[string]interface{}); resp["type"] != "subscribed" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	hub.broadcastDepthDiff("BTCUSDT", []byte(`{"bids":[{"price":100,"amount":2}],"asks":[],"timestamp":2}`))
+	hub.broadcastToChannel(<-hub.broadcast)
+	hub.broadcastToChannel(<-hub.broadcast)
+
+	// 快照之后依次为 seq 1（已包含在快照中，丢弃）和 seq 2
+	var seqs []int64
+	for len(client.send) > 0 {
+		message := <-client.send
+		if _, ok := client.encodeMessage(message); !ok {
+			continue
+		}
+		switch m := message.(type) {
+		case *snapshotMessage:
+			seqs = append(seqs, m.Data.(*models.DepthDiff).Seq)
+		case *queuedMessage:
+			seqs = append(seqs, m.Data.(*models.DepthDiff).Seq)
+		}
+	}
+	if want := []int64{1, 2}; !reflect.DeepEqual(seqs, want) {
+		t.Errorf("seqs = %v, want %v", seqs, want)
+	}
+
+	// 增量不能设置有效期
+	hub.SetMessageTTL("depthDiff", time.Second)
+	if ttl := hub.messageTTL("depthDiff:BTCUSDT"); ttl != 0 {
+		t.Errorf("depthDiff ttl = %v, want 0", ttl)
+	}
+}
+
+func TestDepthDiffStandby(t *testing.T) {
+	active, standby := NewHub(), NewHub()
+	depth := func(amount string, ts int) []byte {
+		return []byte(`{"bids":[{"price":100,"amount":` + amount + `}],"asks":[{"price":101,"amount":1}],"timestamp":` + strconv.Itoa(ts) + `}`)
+	}
+
+	client := &Client{hub: active, send: make(chan interface{}, 8)}
+	client.handleMessage([]byte(`{"action":"subscribe","channel":"depthDiff","symbol":"BTCUSDT"}`))
+	<-client.send
+	active.broadcastDepthDiff("BTCUSDT", depth("1", 1))
+	active.broadcastDepthDiff("BTCUSDT", depth("2", 2))
+
+	// standby 实例没有订阅者，同步后按相同的深度继续更新，seq 与 active 实例一致
+	if n := standby.MirrorReplicaState(active.ReplicaState()); n != 1 {
+		t.Fatalf("mirrored %d books", n)
+	}
+	active.broadcastDepthDiff("BTCUSDT", depth("3", 3))
+	standby.broadcastDepthDiff("BTCUSDT", depth("3", 3))
+	if len(standby.broadcast) != 0 {
+		t.Fatal("unexpected broadcast without subscribers")
+	}
+
+	// 故障切换后订阅 standby 实例，快照的 seq 与切换前连续
+	var want, got *models.DepthDiff
+	active.depthDiffs.snapshot("BTCUSDT", func(s *models.DepthDiff) { want = s })
+	standby.depthDiffs.snapshot("BTCUSDT", func(s *models.DepthDiff) { got = s })
+	if got == nil || got.Seq != 3 || !reflect.DeepEqual(got, want) {
+		t.Fatalf("standby snapshot = %+v, want %+v", got, want)
+	}
+
+	// 本实例有订阅者后不再被同步覆盖，避免已推送的 seq 回退
+	stale := &ReplicaState{DepthDiffs: []DepthDiffState{{Symbol: "BTCUSDT", Seq: 1}}}
+	if n := standby.MirrorReplicaState(stale); n != 0 {
+		t.Errorf("mirrored %d books over subscribed book", n)
+	}
+
+	// active 实例不再保存的交易对清除
+	standby.MirrorReplicaState(&ReplicaState{DepthDiffs: []DepthDiffState{{Symbol: "ETHUSDT", Seq: 5}}})
+	standby.MirrorReplicaState(&ReplicaState{})
+	if standby.depthDiffs.mirrored("ETHUSDT") {
+		t.Error("book not removed after active dropped it")
+	}
+	standby.broadcastDepthDiff("ETHUSDT", depth("1", 4))
+	if len(standby.depthDiffs.export()) != 1 {
+		t.Errorf("books = %+v", standby.depthDiffs.export())
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"time"
 
+	"market-system/services/api/internal/middleware"
+
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
@@ -44,16 +46,20 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// 创建客户端实例
 	client := NewClient(h.hub, conn, clientID)
+	if id, ok := middleware.IdentityFrom(r.Context()); ok {
+		client.owner = id.Name
+	}
 
 	// 注册客户端到Hub
 	h.hub.Register(client)
 
 	// 发送欢迎消息
 	welcomeMsg := map[string]interface{}{
-		"type":      "welcome",
-		"client_id": clientID,
-		"timestamp": time.Now().Unix(),
-		"message":   "Connected to Market WebSocket Server",
+		"type":              "welcome",
+		"client_id":         clientID,
+		"timestamp":         time.Now().Unix(),
+		"message":           "Connected to Market WebSocket Server",
+		"max_subscriptions": h.hub.MaxSubscriptions(), // 0 表示不限制
 	}
 	select {
 	case client.send <- welcomeMsg:
@@ -1,8 +1,11 @@
 package websocket
 
 import (
+	"errors"
 	"log"
+	"market-system/common/constants"
 	"sync"
+	"time"
 )
 
 // Hub 管理所有WebSocket客户端连接
@@ -25,10 +28,50 @@ type Hub struct {
 	// 读写锁保护clients map
 	mu sync.RWMutex
 
+	// 各数据类型的消息有效期，key: ticker/depth/trade/kline，0 表示不过期
+	messageTTLs map[string]time.Duration
+
+	// 各数据类型因过期被丢弃的消息数
+	staleDrops map[string]int64
+
+	// 保护 messageTTLs 与 staleDrops
+	ttlMu sync.RWMutex
+
+	// 判断交易对是否被隐藏（已软删除），为 nil 表示不隐藏
+	hidden func(symbol string) bool
+
+	// 用户价格提醒存储，为 nil 表示不支持 alert.subscribe
+	alerts AlertStore
+
+	// 每个连接最多订阅的频道数，<= 0 表示不限制
+	maxSubscriptions int
+
+	// 每个连接每秒允许的入站消息数及突发容量，messageRate <= 0 表示不限流
+	messageRate  float64
+	messageBurst int
+
+	// 超出限流的警告次数，超过后断开连接
+	rateLimitWarnings int
+
+	// 入站消息限流统计
+	throttle throttleCounters
+
+	// 订阅时读取频道快照，为 nil 表示不推送快照
+	snapshots SnapshotSource
+
+	// 按频道合并推送
+	conflator *conflator
+
+	// depthDiff 频道的增量计算
+	depthDiffs *depthDiffer
+
 	// 停止信号
 	stopChan chan struct{}
 }
 
+// ErrSubscriptionLimit 订阅后连接的频道数超过上限
+var ErrSubscriptionLimit = errors.New("subscription limit exceeded")
+
 // BroadcastMessage 广播消息结构
 type BroadcastMessage struct {
 	Channel string      // 频道名称，如 "ticker:BTCUSDT"
@@ -37,14 +80,27 @@ type BroadcastMessage struct {
 
 // NewHub 创建新的Hub实例
 func NewHub() *Hub {
-	return &Hub{
+	h := &Hub{
 		clients:             make(map[*Client]bool),
 		register:            make(chan *Client, 256),
 		unregister:          make(chan *Client, 256),
 		broadcast:           make(chan *BroadcastMessage, 1024),
 		subscriptionManager: NewSubscriptionManager(),
 		stopChan:            make(chan struct{}),
+		messageTTLs: map[string]time.Duration{
+			constants.DataTypeDepth:      constants.DepthMessageTTL * time.Millisecond,
+			constants.DataTypeTicker:     constants.TickerMessageTTL * time.Millisecond,
+			constants.DataTypeBookTicker: constants.DepthMessageTTL * time.Millisecond,
+		},
+		staleDrops:        make(map[string]int64),
+		maxSubscriptions:  constants.MaxSubscriptionsPerConn,
+		messageRate:       constants.MessageRateLimit,
+		rateLimitWarnings: constants.MaxRateLimitWarnings,
+		conflator:         newConflator(),
+		depthDiffs:        newDepthDiffer(constants.DepthDiffSnapshotInterval * time.Millisecond),
 	}
+	h.conflator.setWindow(constants.DataTypeDepth, constants.DepthConflationWindow*time.Millisecond)
+	return h
 }
 
 // Run 启动Hub，处理注册/注销/广播
@@ -88,11 +144,8 @@ func (h *Hub) broadcastToChannel(message *BroadcastMessage) {
 		return
 	}
 
-	// 构造JSON消息
-	jsonMessage := map[string]interface{}{
-		"channel": message.Channel,
-		"data":    message.Data,
-	}
+	// 构造队列消息（记录入队时间，写出时检查是否过期）
+	jsonMessage := newQueuedMessage(message.Channel, message.Data)
 
 	successCount := 0
 	failCount := 0
@@ -114,14 +167,55 @@ func (h *Hub) broadcastToChannel(message *BroadcastMessage) {
 	}
 }
 
-// Broadcast 广播消息到指定频道
+// Broadcast 广播消息到指定频道，被隐藏交易对的消息直接丢弃
+// 设置了合并窗口的数据类型，同一频道在窗口内只推送最新的消息
 func (h *Hub) Broadcast(channel string, data interface{}) {
+	if h.channelHidden(channel) {
+		return
+	}
+	if h.conflator.hold(channel, data, time.Now(), h.scheduleFlush) {
+		return
+	}
 	h.broadcast <- &BroadcastMessage{
 		Channel: channel,
 		Data:    data,
 	}
 }
 
+// scheduleFlush 合并窗口结束时推送频道的最新消息
+func (h *Hub) scheduleFlush(channel string, delay time.Duration) {
+	time.AfterFunc(delay, func() {
+		data := h.conflator.take(channel, time.Now())
+		if data == nil || h.channelHidden(channel) {
+			return
+		}
+		select {
+		case h.broadcast <- &BroadcastMessage{Channel: channel, Data: data}:
+		case <-h.stopChan:
+		}
+	})
+}
+
+// SetConflation 设置某类频道的合并窗口（启动前调用），同一频道在窗口内最多推送一次最新的消息，window <= 0 表示不合并
+// depthDiff 频道的增量不能合并，设置无效
+func (h *Hub) SetConflation(channelType string, window time.Duration) {
+	if channelType == constants.DataTypeDepthDiff {
+		log.Printf("[WebSocket Hub] Conflation is not supported for channel type: %s\n", channelType)
+		return
+	}
+	h.conflator.setWindow(channelType, window)
+}
+
+// SetDepthDiffSnapshotInterval 设置 depthDiff 频道推送全量深度的间隔（启动前调用）
+func (h *Hub) SetDepthDiffSnapshotInterval(interval time.Duration) {
+	h.depthDiffs.snapshotInterval = interval
+}
+
+// ConflationStats 获取各数据类型因合并未推送的消息数
+func (h *Hub) ConflationStats() map[string]int64 {
+	return h.conflator.stats()
+}
+
 // Register 注册客户端
 func (h *Hub) Register(client *Client) {
 	h.register <- client
@@ -132,10 +226,16 @@ func (h *Hub) Unregister(client *Client) {
 	h.unregister <- client
 }
 
-// Subscribe 订阅频道
-func (h *Hub) Subscribe(client *Client, channel string) {
-	h.subscriptionManager.Subscribe(client, channel)
-	log.Printf("[WebSocket Hub] Client subscribed to channel: %s\n", channel)
+// Subscribe 订阅频道，多个频道全部订阅或全部不订阅，超过连接的订阅上限时返回 ErrSubscriptionLimit
+func (h *Hub) Subscribe(client *Client, channels ...string) error {
+	if !h.subscriptionManager.SubscribeLimited(client, channels, h.maxSubscriptions) {
+		log.Printf("[WebSocket Hub] Client %s exceeded subscription limit %d\n", client.id, h.maxSubscriptions)
+		return ErrSubscriptionLimit
+	}
+	for _, channel := range channels {
+		log.Printf("[WebSocket Hub] Client subscribed to channel: %s\n", channel)
+	}
+	return nil
 }
 
 // Unsubscribe 取消订阅频道
@@ -144,6 +244,99 @@ func (h *Hub) Unsubscribe(client *Client, channel string) {
 	log.Printf("[WebSocket Hub] Client unsubscribed from channel: %s\n", channel)
 }
 
+// SetSymbolFilter 设置判断交易对是否被隐藏的函数（启动前调用），被隐藏交易对的行情频道不允许订阅也不再推送
+func (h *Hub) SetSymbolFilter(hidden func(symbol string) bool) {
+	h.hidden = hidden
+}
+
+// SetMaxSubscriptions 设置每个连接最多订阅的频道数（启动前调用），n <= 0 表示不限制
+func (h *Hub) SetMaxSubscriptions(n int) {
+	h.maxSubscriptions = n
+}
+
+// MaxSubscriptions 每个连接最多订阅的频道数，0 表示不限制
+func (h *Hub) MaxSubscriptions() int {
+	if h.maxSubscriptions < 0 {
+		return 0
+	}
+	return h.maxSubscriptions
+}
+
+// SetMessageRateLimit 设置每个连接的入站消息限流（启动前调用）
+// rate 为每秒消息数（<= 0 表示不限流），burst 为突发容量（< 1 时等于 rate），warnings 为断开前的警告次数
+func (h *Hub) SetMessageRateLimit(rate float64, burst, warnings int) {
+	h.messageRate = rate
+	h.messageBurst = burst
+	h.rateLimitWarnings = warnings
+}
+
+// ThrottleStats 获取入站消息限流统计
+func (h *Hub) ThrottleStats() ThrottleStats {
+	return h.throttle.snapshot()
+}
+
+// SetSnapshotSource 设置订阅时读取频道快照的数据源（启动前调用）
+func (h *Hub) SetSnapshotSource(source SnapshotSource) {
+	h.snapshots = source
+}
+
+// SetAlertStore 设置用户价格提醒存储（启动前调用）
+func (h *Hub) SetAlertStore(store AlertStore) {
+	h.alerts = store
+}
+
+// channelHidden 频道是否属于被隐藏的交易对，config 频道不隐藏（软删除事件仍需推送给订阅者）
+func (h *Hub) channelHidden(channel string) bool {
+	if h.hidden == nil || channelType(channel) == constants.DataTypeConfig {
+		return false
+	}
+	symbol := channelSymbol(channel)
+	return symbol != "" && h.hidden(symbol)
+}
+
+// SetMessageTTL 设置某类频道消息在发送队列中的有效期，ttl <= 0 表示不过期
+// depthDiff 频道丢弃任何一条增量都会使 seq 不连续，设置无效
+func (h *Hub) SetMessageTTL(channelType string, ttl time.Duration) {
+	if channelType == constants.DataTypeDepthDiff {
+		log.Printf("[WebSocket Hub] Message TTL is not supported for channel type: %s\n", channelType)
+		return
+	}
+	h.ttlMu.Lock()
+	defer h.ttlMu.Unlock()
+
+	if ttl <= 0 {
+		delete(h.messageTTLs, channelType)
+		return
+	}
+	h.messageTTLs[channelType] = ttl
+}
+
+// messageTTL 获取频道消息的有效期
+func (h *Hub) messageTTL(channel string) time.Duration {
+	h.ttlMu.RLock()
+	defer h.ttlMu.RUnlock()
+	return h.messageTTLs[channelType(channel)]
+}
+
+// recordStaleDrop 记录过期丢弃的消息
+func (h *Hub) recordStaleDrop(channel string) {
+	h.ttlMu.Lock()
+	h.staleDrops[channelType(channel)]++
+	h.ttlMu.Unlock()
+}
+
+// StaleDropStats 获取各数据类型因过期被丢弃的消息数
+func (h *Hub) StaleDropStats() map[string]int64 {
+	h.ttlMu.RLock()
+	defer h.ttlMu.RUnlock()
+
+	stats := make(map[string]int64, len(h.staleDrops))
+	for k, v := range h.staleDrops {
+		stats[k] = v
+	}
+	return stats
+}
+
 // ClientCount 返回当前连接的客户端数量
 func (h *Hub) ClientCount() int {
 	h.mu.RLock()
@@ -151,6 +344,11 @@ func (h *Hub) ClientCount() int {
 	return len(h.clients)
 }
 
+// ChannelSubscribers 返回各频道当前的订阅者数量
+func (h *Hub) ChannelSubscribers() map[string]int {
+	return h.subscriptionManager.GetSubscriberCounts()
+}
+
 // GetSubscriptions 获取客户端的所有订阅
 func (h *Hub) GetSubscriptions(client *Client) []string {
 	return h.subscriptionManager.GetClientSubscriptions(client)
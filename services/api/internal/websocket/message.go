@@ -0,0 +1,70 @@
+package websocket
+
+import (
+	"encoding/json"
+	"market-system/common/constants"
+	"strings"
+	"time"
+)
+
+// groupPrefix 分组频道中分组名的前缀，如 ticker:group:majors
+const groupPrefix = "group:"
+
+// groupChannelTypes 支持按交易对分组订阅的数据类型
+var groupChannelTypes = map[string]bool{
+	constants.DataTypeTicker:   true,
+	constants.DataTypeDepth:    true,
+	constants.DataTypeTrade:    true,
+	constants.DataTypeAggTrade: true,
+}
+
+// intervalChannelTypes 支持 intervals 字段一次订阅多个周期的数据类型，频道为 {type}:{symbol}:{interval}
+var intervalChannelTypes = map[string]bool{
+	constants.DataTypeKline:     true,
+	constants.DataTypeIndicator: true,
+}
+
+// queuedMessage 进入客户端发送队列的频道消息，记录入队时间用于过期判断
+type queuedMessage struct {
+	Channel    string
+	Data       interface{}
+	EnqueuedAt time.Time
+}
+
+// newQueuedMessage 创建队列消息
+func newQueuedMessage(channel string, data interface{}) *queuedMessage {
+	return &queuedMessage{
+		Channel:    channel,
+		Data:       data,
+		EnqueuedAt: time.Now(),
+	}
+}
+
+// MarshalJSON 保持推送给客户端的格式不变: {"channel": ..., "data": ...}
+func (m *queuedMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"channel": m.Channel,
+		"data":    m.Data,
+	})
+}
+
+// channelType 获取频道的数据类型，如 depth:BTCUSDT -> depth
+func channelType(channel string) string {
+	if idx := strings.Index(channel, ":"); idx >= 0 {
+		return channel[:idx]
+	}
+	return channel
+}
+
+// channelSymbol 从频道名称中取交易对，例如 kline:BTCUSDT:1m -> BTCUSDT，没有交易对时返回空
+func channelSymbol(channel string) string {
+	idx := strings.Index(channel, ":")
+	if idx < 0 {
+		return ""
+	}
+	symbol := channel[idx+1:]
+	if end := strings.Index(symbol, ":"); end >= 0 {
+		symbol = symbol[:end]
+	}
+	return symbol
+}
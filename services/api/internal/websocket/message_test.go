@@ -0,0 +1,74 @@
+package websocket
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// TestGoldenEnvelopes 冻结推送给客户端的消息外层格式
+func TestGoldenEnvelopes(t *testing.T) {
+	client := &Client{send: make(chan interface{}, 8)}
+
+	client.sendResponse("subscribed", map[string]interface{}{
+		"channel": "ticker",
+		"symbol":  "BTCUSDT",
+	})
+	client.sendResponse("pong", nil)
+	client.sendError("Missing 'channel' field")
+
+	cases := []struct {
+		name    string
+		message interface{}
+	}{
+		{
+			name: "channel_message",
+			message: newQueuedMessage("ticker:BTCUSDT", map[string]interface{}{
+				"symbol":     "BTCUSDT",
+				"last_price": 43250.5,
+				"timestamp":  1700000000000,
+			}),
+		},
+		{
+			name: "snapshot",
+			message: &snapshotMessage{Channel: "ticker:BTCUSDT", Data: map[string]interface{}{
+				"symbol":     "BTCUSDT",
+				"last_price": 43250.5,
+				"timestamp":  1700000000000,
+			}},
+		},
+		{name: "subscribed", message: <-client.send},
+		{name: "pong", message: <-client.send},
+		{name: "error", message: <-client.send},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.MarshalIndent(tc.message, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := append(data, '\n')
+			path := filepath.Join("testdata", tc.name+".golden.json")
+
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read golden file: %v (run go test -update to create)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("envelope format changed for %s\ngot:\n%s\nwant:\n%s", tc.name, got, want)
+			}
+		})
+	}
+}
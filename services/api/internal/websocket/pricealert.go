@@ -0,0 +1,106 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"log"
+	"market-system/common/constants"
+	"market-system/common/errcode"
+	"market-system/common/models"
+	"strings"
+)
+
+// AlertStore 用户价格提醒存储
+type AlertStore interface {
+	Create(ctx context.Context, alert *models.PriceAlert) error
+	Delete(ctx context.Context, owner, id string) error
+}
+
+// handleAlertSubscribe 创建价格提醒并订阅其触发通知频道 price_alert:{id}
+// 请求: {"action":"alert.subscribe","symbol":"BTCUSDT","condition":"price_cross_up","value":70000,"webhook":"...","repeat":false}
+// 连接断开时删除在该连接上创建、且没有 Webhook 的提醒；提醒按创建者隔离，未认证或匿名的连接不能创建
+func (c *Client) handleAlertSubscribe(msg map[string]interface{}) {
+	if c.hub.alerts == nil {
+		c.sendError("Price alerts are not enabled")
+		return
+	}
+	if c.owner == "" {
+		c.sendError("Price alerts require authentication")
+		return
+	}
+
+	alert := &models.PriceAlert{Owner: c.owner}
+	alert.Symbol, _ = msg["symbol"].(string)
+	alert.Condition, _ = msg["condition"].(string)
+	alert.Value, _ = msg["value"].(float64)
+	alert.Webhook, _ = msg["webhook"].(string)
+	alert.Repeat, _ = msg["repeat"].(bool)
+
+	if c.hub.hidden != nil && c.hub.hidden(strings.ToUpper(alert.Symbol)) {
+		c.sendError("Symbol not found: " + alert.Symbol)
+		return
+	}
+	if err := c.hub.alerts.Create(context.Background(), alert); err != nil {
+		c.sendError(alertErrorMessage(err))
+		return
+	}
+	if err := c.hub.Subscribe(c, constants.DataTypePriceAlert+":"+alert.ID); err != nil {
+		// 无法推送触发通知，撤销刚创建的提醒
+		c.hub.alerts.Delete(context.Background(), c.owner, alert.ID)
+		c.sendError(subscribeErrorMessage(c.hub, err))
+		return
+	}
+
+	if alert.Webhook == "" {
+		if c.alerts == nil {
+			c.alerts = make(map[string]bool)
+		}
+		c.alerts[alert.ID] = true
+	}
+	c.sendResponse("alert.subscribed", alert)
+}
+
+// handleAlertUnsubscribe 删除价格提醒并取消订阅，只能删除同一认证身份创建的提醒
+// 请求: {"action":"alert.unsubscribe","id":"..."}
+func (c *Client) handleAlertUnsubscribe(msg map[string]interface{}) {
+	if c.hub.alerts == nil {
+		c.sendError("Price alerts are not enabled")
+		return
+	}
+	if c.owner == "" {
+		c.sendError("Price alerts require authentication")
+		return
+	}
+
+	id, _ := msg["id"].(string)
+	if id == "" {
+		c.sendError("Missing 'id' field")
+		return
+	}
+	if err := c.hub.alerts.Delete(context.Background(), c.owner, id); err != nil {
+		c.sendError(alertErrorMessage(err))
+		return
+	}
+
+	delete(c.alerts, id)
+	c.hub.Unsubscribe(c, constants.DataTypePriceAlert+":"+id)
+	c.sendResponse("alert.unsubscribed", map[string]interface{}{"id": id})
+}
+
+// removeAlerts 连接断开时删除只能推送到该连接的提醒，已触发删除的提醒忽略
+func (c *Client) removeAlerts() {
+	for id := range c.alerts {
+		if err := c.hub.alerts.Delete(context.Background(), c.owner, id); err != nil && !errors.Is(err, errcode.ErrNotFound) {
+			log.Printf("[WebSocket Client %s] Failed to remove price alert %s: %v\n", c.id, id, err)
+		}
+	}
+}
+
+// alertErrorMessage 参数错误、不存在时返回具体原因，其余错误（如 Redis 连接失败）不暴露细节
+func alertErrorMessage(err error) string {
+	if code := errcode.From(err); code == errcode.ErrInvalidParam || code == errcode.ErrNotFound {
+		return err.Error()
+	}
+	log.Printf("[WebSocket] Price alert request failed: %v\n", err)
+	return "Internal error"
+}
@@ -0,0 +1,101 @@
+package websocket
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// messageLimiter 单个连接的入站消息令牌桶，只在读协程中访问
+type messageLimiter struct {
+	rate   float64 // 每秒补充的令牌数
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newMessageLimiter(rate float64, burst int, now time.Time) *messageLimiter {
+	b := float64(burst)
+	if b < 1 {
+		b = math.Max(rate, 1)
+	}
+	return &messageLimiter{rate: rate, burst: b, tokens: b, last: now}
+}
+
+// refill 按经过的时间补充令牌，返回令牌桶是否已补满
+func (l *messageLimiter) refill(now time.Time) bool {
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	return l.tokens >= l.burst
+}
+
+// allow 取一个令牌，令牌不足时返回 false
+func (l *messageLimiter) allow(now time.Time) bool {
+	l.refill(now)
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// ThrottleStats 入站消息限流统计
+type ThrottleStats struct {
+	ThrottledMessages int64 // 被限流丢弃的消息数
+	ThrottledClients  int64 // 被限流过的连接数
+	Disconnected      int64 // 因持续超限被断开的连接数
+}
+
+// throttleCounters Hub 的限流统计计数
+type throttleCounters struct {
+	messages     int64
+	clients      int64
+	disconnected int64
+}
+
+func (t *throttleCounters) snapshot() ThrottleStats {
+	return ThrottleStats{
+		ThrottledMessages: atomic.LoadInt64(&t.messages),
+		ThrottledClients:  atomic.LoadInt64(&t.clients),
+		Disconnected:      atomic.LoadInt64(&t.disconnected),
+	}
+}
+
+// allowMessage 检查入站消息是否超出连接的限流，allowed 为 false 时丢弃消息
+// 超限时返回警告，累计超过警告次数后 keep 为 false，由读协程断开连接；
+// 令牌桶补满（连接已有一段时间未超限）后警告次数清零，偶发的突发不会累积到断开
+func (c *Client) allowMessage() (allowed, keep bool) {
+	h := c.hub
+	if h.messageRate <= 0 {
+		return true, true
+	}
+
+	now := time.Now()
+	if c.limiter == nil {
+		c.limiter = newMessageLimiter(h.messageRate, h.messageBurst, now)
+	}
+	if c.limiter.refill(now) {
+		c.throttled = 0
+	}
+	if c.limiter.allow(now) {
+		return true, true
+	}
+
+	c.throttled++
+	atomic.AddInt64(&h.throttle.messages, 1)
+	if !c.everThrottled {
+		c.everThrottled = true
+		atomic.AddInt64(&h.throttle.clients, 1)
+	}
+	if c.throttled > h.rateLimitWarnings {
+		atomic.AddInt64(&h.throttle.disconnected, 1)
+		log.Printf("[WebSocket Client %s] Message rate limit exceeded %d times, disconnecting\n", c.id, c.throttled)
+		c.sendError("Message rate limit exceeded, closing connection")
+		return false, false
+	}
+	c.sendError(fmt.Sprintf("Message rate limit exceeded: max %g messages per second, warning %d/%d",
+		h.messageRate, c.throttled, h.rateLimitWarnings))
+	return false, true
+}
@@ -0,0 +1,262 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"market-system/common/codec"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// snapshotTimeout 订阅时读取快照的超时时间
+const snapshotTimeout = 2 * time.Second
+
+// snapshotChannelTypes 订阅时推送快照的数据类型
+var snapshotChannelTypes = map[string]bool{
+	constants.DataTypeTicker: true,
+	constants.DataTypeDepth:  true,
+	constants.DataTypeKline:  true,
+
+	constants.DataTypeDepthDiff: true, // 快照由 API 服务保存的订单簿生成，seq 与之后的增量连续
+}
+
+// SnapshotSource 读取频道的当前数据，没有数据时返回 nil
+type SnapshotSource interface {
+	Snapshot(ctx context.Context, channel string) (interface{}, error)
+}
+
+// snapshotMessage 订阅后推送的频道快照，格式与频道消息相同并带 type: snapshot
+type snapshotMessage struct {
+	Channel string
+	Data    interface{}
+}
+
+// MarshalJSON 推送给客户端的格式: {"type": "snapshot", "channel": ..., "data": ...}
+func (m *snapshotMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"type":    "snapshot",
+		"channel": m.Channel,
+		"data":    m.Data,
+	})
+}
+
+// snapshotChannel 频道是否在订阅时推送快照，如 ticker:BTCUSDT、depth:BTCUSDT、kline:BTCUSDT:1m
+// 分组频道（ticker:group:majors）不推送
+func snapshotChannel(channel string) bool {
+	parts := strings.Split(channel, ":")
+	switch {
+	case !snapshotChannelTypes[parts[0]]:
+		return false
+	case parts[0] == constants.DataTypeKline:
+		return len(parts) == 3
+	default:
+		return len(parts) == 2
+	}
+}
+
+// pendingSnapshots 已订阅、快照尚未发出的频道，快照发出前该频道的推送直接丢弃，保证快照是频道的第一条消息
+type pendingSnapshots struct {
+	count    int32 // 待发快照的频道数，为 0 时写协程不加锁
+	mu       sync.Mutex
+	channels map[string]bool
+}
+
+func (p *pendingSnapshots) add(channel string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.channels == nil {
+		p.channels = make(map[string]bool)
+	}
+	if !p.channels[channel] {
+		p.channels[channel] = true
+		atomic.AddInt32(&p.count, 1)
+	}
+}
+
+func (p *pendingSnapshots) remove(channel string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.channels[channel] {
+		delete(p.channels, channel)
+		atomic.AddInt32(&p.count, -1)
+	}
+}
+
+func (p *pendingSnapshots) has(channel string) bool {
+	if atomic.LoadInt32(&p.count) == 0 {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.channels[channel]
+}
+
+// sendSnapshots 订阅成功后推送新订阅频道的快照
+// 调用前频道已加入 pending，快照读取失败或没有数据时直接移除，之后正常推送
+func (c *Client) sendSnapshots(channels []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+
+	for _, channel := range channels {
+		if channelType(channel) == constants.DataTypeDepthDiff {
+			queued := c.hub.depthDiffs.snapshot(channelSymbol(channel), func(snapshot *models.DepthDiff) {
+				c.queueSnapshot(channel, snapshot)
+			})
+			if !queued {
+				c.pending.remove(channel)
+			}
+			continue
+		}
+
+		var data interface{}
+		var err error
+		if c.hub.snapshots != nil {
+			data, err = c.hub.snapshots.Snapshot(ctx, channel)
+		}
+		if err != nil {
+			log.Printf("[WebSocket Client %s] Failed to load snapshot for channel '%s': %v\n", c.id, channel, err)
+		}
+		if data == nil {
+			c.pending.remove(channel)
+			continue
+		}
+		c.queueSnapshot(channel, data)
+	}
+}
+
+// queueSnapshot 快照加入发送队列，队列已满时丢弃快照，频道之后正常推送
+func (c *Client) queueSnapshot(channel string, data interface{}) {
+	select {
+	case c.send <- &snapshotMessage{Channel: channel, Data: data}:
+	default:
+		c.pending.remove(channel)
+		log.Printf("[WebSocket Client %s] Send buffer full, dropping snapshot\n", c.id)
+	}
+}
+
+// staleDepthDiff depthDiff 频道快照之前产生、快照发出后才写出的增量（seq 不大于快照的 seq），已包含在快照中
+// diffSeqs 只在写协程中访问，快照之后的第一条增量写出后清除
+func (c *Client) staleDepthDiff(qm *queuedMessage) bool {
+	diff, ok := qm.Data.(*models.DepthDiff)
+	if !ok {
+		return false
+	}
+	seq, ok := c.diffSeqs[qm.Channel]
+	if !ok {
+		return false
+	}
+	if diff.Seq <= seq {
+		return true
+	}
+	delete(c.diffSeqs, qm.Channel)
+	return false
+}
+
+// snapshotTargets 需要推送快照的新订阅频道（已订阅的频道不重复推送）
+func (c *Client) snapshotTargets(channels []string) []string {
+	subscribed := make(map[string]bool)
+	for _, channel := range c.hub.GetSubscriptions(c) {
+		subscribed[channel] = true
+	}
+
+	var targets []string
+	for _, channel := range channels {
+		if !snapshotChannel(channel) || subscribed[channel] {
+			continue
+		}
+		if c.hub.snapshots != nil || channelType(channel) == constants.DataTypeDepthDiff {
+			targets = append(targets, channel)
+		}
+	}
+	return targets
+}
+
+// RedisSnapshots 从 Redis 读取 Ticker、深度和最新一根K线作为快照，格式与推送的消息相同
+type RedisSnapshots struct {
+	rdb *redis.Client
+}
+
+// NewRedisSnapshots 创建 Redis 快照读取
+func NewRedisSnapshots(rdb *redis.Client) *RedisSnapshots {
+	return &RedisSnapshots{rdb: rdb}
+}
+
+// Snapshot 读取频道的当前数据
+func (s *RedisSnapshots) Snapshot(ctx context.Context, channel string) (interface{}, error) {
+	switch channelType(channel) {
+	case constants.DataTypeTicker:
+		return s.ticker(ctx, channelSymbol(channel))
+	case constants.DataTypeDepth:
+		return s.depth(ctx, channelSymbol(channel))
+	case constants.DataTypeKline:
+		return s.kline(ctx, strings.TrimPrefix(channel, constants.DataTypeKline+":"))
+	}
+	return nil, nil
+}
+
+// ticker Ticker 以 Hash 存储，转换为推送的 Ticker 格式
+func (s *RedisSnapshots) ticker(ctx context.Context, symbol string) (interface{}, error) {
+	data, err := s.rdb.HGetAll(ctx, constants.RedisKeyTicker+symbol).Result()
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+
+	float := func(field string) float64 {
+		v, _ := strconv.ParseFloat(data[field], 64)
+		return v
+	}
+	ticker := &models.Ticker{
+		Symbol:                symbol,
+		LastPrice:             float("last_price"),
+		BidPrice:              float("bid_price"),
+		AskPrice:              float("ask_price"),
+		High24h:               float("high_24h"),
+		Low24h:                float("low_24h"),
+		Volume24h:             float("volume_24h"),
+		Open24h:               float("open_24h"),
+		PriceChange24h:        float("price_change_24h"),
+		PriceChangePercent24h: float("price_change_percent_24h"),
+	}
+	ticker.TradeCount24h, _ = strconv.ParseInt(data["trade_count_24h"], 10, 64)
+	ticker.Timestamp, _ = strconv.ParseInt(data["timestamp"], 10, 64)
+	return ticker, nil
+}
+
+func (s *RedisSnapshots) depth(ctx context.Context, symbol string) (interface{}, error) {
+	data, err := s.rdb.Get(ctx, constants.RedisKeyDepth+symbol).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var depth interface{}
+	if err := codec.Unmarshal(data, &depth); err != nil {
+		return nil, fmt.Errorf("invalid depth: %w", err)
+	}
+	return depth, nil
+}
+
+// kline 最新一根K线，以 KlineUpdate 格式返回，key 为 {symbol}:{interval}
+func (s *RedisSnapshots) kline(ctx context.Context, key string) (interface{}, error) {
+	items, err := s.rdb.ZRevRange(ctx, constants.RedisKeyKline+key, 0, 0).Result()
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+
+	var kline models.Kline
+	if err := codec.Unmarshal([]byte(items[0]), &kline); err != nil {
+		return nil, fmt.Errorf("invalid kline: %w", err)
+	}
+	return models.NewKlineUpdate(&kline, kline.IsFinal), nil
+}
@@ -0,0 +1,128 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"log"
+	"market-system/common/constants"
+	"market-system/common/utils"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 热备角色
+const (
+	StandbyRoleActive  = "active"  // 定期将 WebSocket 状态写入 Redis
+	StandbyRoleStandby = "standby" // 定期从 Redis 读取 active 实例的状态
+)
+
+// ReplicaState 热备同步的 WebSocket 状态
+// 目前为 depthDiff 频道的订单簿和 seq；其他频道的订阅快照从 Redis 读取，各实例一致，不需要同步
+type ReplicaState struct {
+	UpdatedAt  int64            `json:"updated_at"`
+	DepthDiffs []DepthDiffState `json:"depth_diffs"`
+}
+
+// ReplicaState 当前的热备状态
+func (h *Hub) ReplicaState() *ReplicaState {
+	return &ReplicaState{
+		UpdatedAt:  utils.GetCurrentTimestamp(),
+		DepthDiffs: h.depthDiffs.export(),
+	}
+}
+
+// MirrorReplicaState 以 active 实例的状态替换同步的订单簿，返回同步的交易对数
+func (h *Hub) MirrorReplicaState(state *ReplicaState) int {
+	return h.depthDiffs.mirror(state.DepthDiffs, time.Now())
+}
+
+// Standby API 实例间的热备同步
+// active 实例定期将状态写入 api_standby:{name}，过期时间为同步间隔的 3 倍；standby 实例定期读取并同步，
+// 故障切换到 standby 实例后重连的客户端立即获得 depthDiff 快照，seq 与切换前连续。
+// active 实例停止后状态过期，standby 实例保留最后同步的订单簿，之后按收到的深度继续更新
+type Standby struct {
+	hub      *Hub
+	rdb      *redis.Client
+	role     string
+	key      string
+	interval time.Duration
+}
+
+// NewStandby 创建热备同步，name 为热备组名称，同一组的 active 和 standby 实例使用相同的名称
+func NewStandby(hub *Hub, rdb *redis.Client, role, name string, interval time.Duration) *Standby {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return &Standby{
+		hub:      hub,
+		rdb:      rdb,
+		role:     role,
+		key:      constants.RedisKeyAPIStandby + name,
+		interval: interval,
+	}
+}
+
+// Run 按同步间隔写入或读取状态，直到 ctx 取消
+func (s *Standby) Run(ctx context.Context) {
+	log.Printf("[Standby] Running as %s (key: %s, interval: %v)\n", s.role, s.key, s.interval)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	following := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			switch s.role {
+			case StandbyRoleActive:
+				if err := s.publish(ctx); err != nil {
+					log.Printf("[Standby] Failed to publish state: %v\n", err)
+				}
+			case StandbyRoleStandby:
+				ok, err := s.follow(ctx)
+				if err != nil {
+					log.Printf("[Standby] Failed to sync state: %v\n", err)
+					continue
+				}
+				if ok != following {
+					following = ok
+					if ok {
+						log.Printf("[Standby] Following active replica state\n")
+					} else {
+						log.Printf("[Standby] Active replica state expired, keeping last synced books\n")
+					}
+				}
+			}
+		}
+	}
+}
+
+// publish 写入本实例的状态
+func (s *Standby) publish(ctx context.Context) error {
+	data, err := utils.ToJSONBytes(s.hub.ReplicaState())
+	if err != nil {
+		return err
+	}
+	return s.rdb.Set(ctx, s.key, data, 3*s.interval).Err()
+}
+
+// follow 读取并同步 active 实例的状态，状态不存在（active 实例已停止）时返回 false
+func (s *Standby) follow(ctx context.Context) (bool, error) {
+	data, err := s.rdb.Get(ctx, s.key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var state ReplicaState
+	if err := utils.FromJSONBytes(data, &state); err != nil {
+		return false, err
+	}
+	s.hub.MirrorReplicaState(&state)
+	return true, nil
+}
@@ -42,6 +42,40 @@ func (sm *SubscriptionManager) Subscribe(client *Client, channel string) {
 	sm.clientSubscriptions[client][channel] = true
 }
 
+// SubscribeLimited 订阅多个频道，订阅后客户端的频道数超过 max 时全部不订阅并返回 false
+// 已订阅的频道不重复计数，max <= 0 表示不限制
+func (sm *SubscriptionManager) SubscribeLimited(client *Client, channels []string, max int) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if max > 0 {
+		existing := sm.clientSubscriptions[client]
+		total := len(existing)
+		added := make(map[string]bool, len(channels))
+		for _, channel := range channels {
+			if !existing[channel] && !added[channel] {
+				added[channel] = true
+				total++
+			}
+		}
+		if total > max {
+			return false
+		}
+	}
+
+	if _, exists := sm.clientSubscriptions[client]; !exists {
+		sm.clientSubscriptions[client] = make(map[string]bool)
+	}
+	for _, channel := range channels {
+		if _, exists := sm.channelSubscribers[channel]; !exists {
+			sm.channelSubscribers[channel] = make(map[*Client]bool)
+		}
+		sm.channelSubscribers[channel][client] = true
+		sm.clientSubscriptions[client][channel] = true
+	}
+	return true
+}
+
 // Unsubscribe 取消订阅频道
 func (sm *SubscriptionManager) Unsubscribe(client *Client, channel string) {
 	sm.mu.Lock()
@@ -110,6 +144,13 @@ func (sm *SubscriptionManager) GetSubscribers(channel string) map[*Client]bool {
 	return result
 }
 
+// HasSubscribers 频道是否有订阅者
+func (sm *SubscriptionManager) HasSubscribers(channel string) bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return len(sm.channelSubscribers[channel]) > 0
+}
+
 // GetClientSubscriptions 获取客户端订阅的所有频道
 func (sm *SubscriptionManager) GetClientSubscriptions(client *Client) []string {
 	sm.mu.RLock()
@@ -157,3 +198,15 @@ func (sm *SubscriptionManager) IsSubscribed(client *Client, channel string) bool
 	}
 	return channels[channel]
 }
+
+// GetSubscriberCounts 获取各频道的订阅者数量
+func (sm *SubscriptionManager) GetSubscriberCounts() map[string]int {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	counts := make(map[string]int, len(sm.channelSubscribers))
+	for channel, subscribers := range sm.channelSubscribers {
+		counts[channel] = len(subscribers)
+	}
+	return counts
+}
@@ -0,0 +1,23 @@
+package websocket
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSubscriberCounts(t *testing.T) {
+	sm := NewSubscriptionManager()
+	a, b := &Client{}, &Client{}
+
+	sm.Subscribe(a, "ticker:BTCUSDT")
+	sm.Subscribe(b, "ticker:BTCUSDT")
+	sm.Subscribe(a, "depth:BTCUSDT")
+	if got, want := sm.GetSubscriberCounts(), map[string]int{"ticker:BTCUSDT": 2, "depth:BTCUSDT": 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("counts = %v, want %v", got, want)
+	}
+
+	sm.UnsubscribeAll(a)
+	if got, want := sm.GetSubscriberCounts(), map[string]int{"ticker:BTCUSDT": 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("counts after unsubscribe = %v, want %v", got, want)
+	}
+}
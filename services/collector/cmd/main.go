@@ -1,18 +1,31 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
+	"fmt"
 	"log"
 	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/exchange"
+	"market-system/common/health"
 	"market-system/common/models"
+	"market-system/common/sanitize"
+	"market-system/common/utils"
+	"market-system/common/version"
 	"market-system/services/collector/internal/adapters"
+	"market-system/services/collector/internal/merger"
 	"market-system/services/collector/internal/publisher"
+	"market-system/services/collector/internal/symbolconfig"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
 	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
 var (
@@ -24,6 +37,12 @@ type Collector struct {
 	factory   *adapters.AdapterFactory
 	adapters  []adapters.ExchangeAdapter
 	publisher *publisher.KafkaPublisher
+	sanitizer *sanitize.Sanitizer
+	merger    *merger.DataMerger    // 混合模式下的数据融合器
+	watcher   *symbolconfig.Watcher // 交易对配置热更新
+	redis     *redis.Client         // 配置热更新及统计上报，未配置 Redis 时为 nil
+	rates     *utils.RateCounter    // 按数据类型统计消息速率
+	health    *http.Server          // 存活/就绪检查，未配置端口时为 nil
 	wg        sync.WaitGroup
 }
 
@@ -44,8 +63,8 @@ func main() {
 		log.Fatalf("Failed to start collector: %v\n", err)
 	}
 
-	// 等待退出信号
-	waitForSignal()
+	// 等待退出信号（SIGHUP 重新加载订阅配置）
+	waitForSignal(collector.Reload)
 
 	// 停止服务
 	collector.Stop()
@@ -53,14 +72,16 @@ func main() {
 
 func NewCollector(cfg *config.CollectorConfig) *Collector {
 	return &Collector{
-		config:   cfg,
-		factory:  adapters.NewAdapterFactory(),
-		adapters: make([]adapters.ExchangeAdapter, 0),
+		config:    cfg,
+		factory:   adapters.NewAdapterFactory(),
+		adapters:  make([]adapters.ExchangeAdapter, 0),
+		sanitizer: sanitize.New(sanitize.StageIngest),
+		rates:     utils.NewRateCounter(),
 	}
 }
 
 func (c *Collector) Start() error {
-	log.Println("Starting Market Data Collector...")
+	log.Printf("Starting Market Data Collector (version %s)...\n", version.Version)
 
 	// 初始化 Kafka Publisher
 	c.publisher = publisher.NewKafkaPublisher(c.config.Kafka.Brokers)
@@ -68,31 +89,80 @@ func (c *Collector) Start() error {
 		return err
 	}
 
+	// 初始化 Redis 客户端（可选）
+	if c.config.Redis.Host != "" {
+		c.redis = redis.NewClient(&redis.Options{
+			Addr:        fmt.Sprintf("%s:%d", c.config.Redis.Host, c.config.Redis.Port),
+			Password:    c.config.Redis.Password,
+			DB:          c.config.Redis.DB,
+			DialTimeout: 5 * time.Second,
+		})
+	}
+
+	// 初始化混合模式数据融合器
+	if c.config.HybridMode.Enable {
+		configs := make([]*models.SymbolConfig, 0, len(c.config.SymbolConfigs))
+		for i := range c.config.SymbolConfigs {
+			configs = append(configs, &c.config.SymbolConfigs[i])
+		}
+		c.merger = merger.NewDataMerger(configs, c.config.HybridMode)
+
+		// 监听 Redis 中的交易对配置变更
+		if c.redis != nil {
+			watcher := symbolconfig.NewWatcher(c.redis, c.merger)
+			if err := watcher.Start(); err != nil {
+				log.Printf("[SymbolConfig] Failed to start watcher: %v\n", err)
+				watcher.Close()
+			} else {
+				c.watcher = watcher
+			}
+		}
+	}
+
 	// 初始化交易所适配器
 	for _, exchangeCfg := range c.config.Exchanges {
+		// REST 权重限制（回补、快照等 REST 请求共享）
+		exchange.ConfigureBudget(exchangeCfg.Name, exchange.BudgetLimit{
+			Weight:  exchangeCfg.RESTLimit.Weight,
+			Window:  time.Duration(exchangeCfg.RESTLimit.WindowMs) * time.Millisecond,
+			MaxWait: time.Duration(exchangeCfg.RESTLimit.MaxWaitMs) * time.Millisecond,
+		})
+
 		if !exchangeCfg.Enable {
 			log.Printf("[%s] Disabled, skipping...\n", exchangeCfg.Name)
 			continue
 		}
 
-		adapter := c.factory.Create(exchangeCfg.Name, exchangeCfg.WSUrl)
+		// 选择接入点（按区域或连接延迟）
+		endpoint, err := adapters.ResolveEndpoint(exchangeCfg.Name, exchangeCfg.Region, exchangeCfg.WSUrl)
+		if err != nil {
+			log.Printf("[%s] Failed to resolve endpoint: %v, skipping...\n", exchangeCfg.Name, err)
+			continue
+		}
+
+		adapter := c.factory.Create(exchangeCfg.Name, endpoint.URL)
 		if adapter == nil {
 			log.Printf("[%s] Adapter not found, skipping...\n", exchangeCfg.Name)
 			continue
 		}
 
-		// 设置消息处理器
-		adapter.OnMessage(c.handleMarketData)
+		// 设置消息处理器，记录提供数据的接入点
+		adapter.OnMessage(c.endpointHandler(endpoint))
+
+		// 撮合引擎推送的单日成交汇总保存到 Redis，供 Processor 对账
+		if internal, ok := adapter.(*adapters.InternalAdapter); ok && c.redis != nil {
+			internal.OnDailyTotals(c.saveEngineTotals)
+		}
 
 		// 连接
 		if err := adapter.Connect(); err != nil {
-			log.Printf("[%s] Failed to connect: %v\n", exchangeCfg.Name, err)
+			log.Printf("[%s] Failed to connect (%s): %v\n", exchangeCfg.Name, adapters.ClassifyError(err), err)
 			continue
 		}
 
 		// 订阅
 		if err := adapter.Subscribe(exchangeCfg.Symbols, exchangeCfg.Channels); err != nil {
-			log.Printf("[%s] Failed to subscribe: %v\n", exchangeCfg.Name, err)
+			log.Printf("[%s] Failed to subscribe (%s): %v\n", exchangeCfg.Name, adapters.ClassifyError(err), err)
 			continue
 		}
 
@@ -100,6 +170,14 @@ func (c *Collector) Start() error {
 		log.Printf("[%s] Started successfully\n", exchangeCfg.Name)
 	}
 
+	// 启动存活/就绪检查
+	c.startHealth()
+
+	// 监听管理接口下发的适配器控制命令
+	if c.redis != nil {
+		go c.watchControl()
+	}
+
 	// 启动统计输出
 	go c.printStats()
 
@@ -117,6 +195,18 @@ func (c *Collector) Stop() {
 		}
 	}
 
+	if c.health != nil {
+		c.health.Close()
+	}
+
+	// 停止配置监听
+	if c.watcher != nil {
+		c.watcher.Close()
+	}
+	if c.redis != nil {
+		c.redis.Close()
+	}
+
 	// 关闭 Kafka Publisher
 	if c.publisher != nil {
 		c.publisher.Close()
@@ -126,8 +216,74 @@ func (c *Collector) Stop() {
 	log.Println("Collector stopped")
 }
 
+// Reload 重新加载配置文件，按新的交易对/频道配置更新各适配器的订阅（只订阅/取消变化的部分）
+// 新启用或停用交易所、切换接入点需要重启服务
+func (c *Collector) Reload() {
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Printf("[Reload] Failed to load config: %v\n", err)
+		return
+	}
+
+	running := make(map[string]adapters.ExchangeAdapter, len(c.adapters))
+	for _, adapter := range c.adapters {
+		running[adapter.GetName()] = adapter
+	}
+
+	for _, exchangeCfg := range cfg.Exchanges {
+		adapter, ok := running[exchangeCfg.Name]
+		if !ok {
+			if exchangeCfg.Enable {
+				log.Printf("[Reload] [%s] Not running, restart required to enable\n", exchangeCfg.Name)
+			}
+			continue
+		}
+		if !exchangeCfg.Enable {
+			log.Printf("[Reload] [%s] Disabled in config, restart required to stop\n", exchangeCfg.Name)
+			continue
+		}
+
+		if err := adapter.UpdateSubscriptions(exchangeCfg.Symbols, exchangeCfg.Channels); err != nil {
+			log.Printf("[Reload] [%s] Failed to update subscriptions: %v\n", exchangeCfg.Name, err)
+			continue
+		}
+		log.Printf("[Reload] [%s] Subscriptions updated: %d symbols\n", exchangeCfg.Name, len(exchangeCfg.Symbols))
+	}
+
+	c.config.Exchanges = cfg.Exchanges
+}
+
+// endpointHandler 在行情数据中记录提供数据的接入点后交给 handleMarketData 处理
+func (c *Collector) endpointHandler(endpoint adapters.Endpoint) adapters.MessageHandler {
+	host := endpoint.Host()
+	return func(data *models.MarketData) {
+		if data.Endpoint == "" {
+			data.Endpoint = host
+		}
+		c.handleMarketData(data)
+	}
+}
+
 // handleMarketData 处理市场数据
 func (c *Collector) handleMarketData(data *models.MarketData) {
+	// 清洗 NaN/Inf 数值，避免非法数据进入下游
+	if !c.sanitizer.MarketData(data) {
+		return
+	}
+
+	c.rates.Inc(data.Type)
+
+	// 混合模式下按交易对配置过滤/融合
+	if c.merger != nil {
+		if data.Source == "" {
+			data.Source = constants.SourceExternal
+		}
+		data = c.merger.ProcessData(data)
+		if data == nil {
+			return
+		}
+	}
+
 	// 发布到 Kafka
 	if err := c.publisher.Publish(data); err != nil {
 		log.Printf("[ERROR] Failed to publish data: %v\n", err)
@@ -146,15 +302,164 @@ func (c *Collector) printStats() {
 	defer ticker.Stop()
 
 	for range ticker.C {
+		c.reportStats()
+
 		stats := c.publisher.GetStats()
 		log.Println("=== Kafka Stats ===")
 		for topic, stat := range stats {
 			log.Printf("[%s] Messages: %d, Bytes: %d, Errors: %d\n",
 				topic, stat.Messages, stat.Bytes, stat.Errors)
 		}
+
+		for _, adapter := range c.adapters {
+			if reporter, ok := adapter.(adapters.ErrorReporter); ok {
+				for class, count := range reporter.ErrorStats() {
+					log.Printf("[%s] Errors (%s): %d\n", adapter.GetName(), class, count)
+				}
+			}
+		}
+
+		for name, stat := range exchange.BudgetStatsAll() {
+			log.Printf("[%s] REST weight: %d/%d, Requests: %d, Waits: %d, Rejected: %d, Throttled: %d\n",
+				name, stat.Used, stat.Limit, stat.Requests, stat.Waits, stat.Rejected, stat.Throttled)
+		}
+
+		if c.merger != nil {
+			for symbol, count := range c.merger.GetDedupStats() {
+				log.Printf("[Merger][%s] Duplicate trades dropped: %d\n", symbol, count)
+			}
+		}
+
+		for source, stat := range c.sanitizer.Stats() {
+			log.Printf("[Sanitize][%s] Rejected: %d, Clamped: %d\n", source, stat.Rejected, stat.Clamped)
+		}
+	}
+}
+
+// reportStats 将运行统计写入 Redis，供 API 汇总展示
+func (c *Collector) reportStats() {
+	if c.redis == nil {
+		return
+	}
+
+	exchanges := make(map[string]bool, len(c.adapters))
+	adapterErrors := make(map[string]map[string]int64, len(c.adapters))
+	for _, adapter := range c.adapters {
+		exchanges[adapter.GetName()] = adapter.IsConnected()
+		if reporter, ok := adapter.(adapters.ErrorReporter); ok {
+			counts := make(map[string]int64)
+			for class, count := range reporter.ErrorStats() {
+				counts[string(class)] = count
+			}
+			adapterErrors[adapter.GetName()] = counts
+		}
+	}
+
+	stats := &models.ServiceStats{
+		Service:       constants.ServiceCollector,
+		Timestamp:     utils.GetCurrentTimestamp(),
+		MessageRates:  c.rates.Rates(),
+		Exchanges:     exchanges,
+		AdapterErrors: adapterErrors,
+	}
+
+	data, err := utils.ToJSON(stats)
+	if err != nil {
+		return
+	}
+	key := constants.RedisKeyServiceStats + constants.ServiceCollector
+	if err := c.redis.Set(context.Background(), key, data, constants.ServiceStatsTTL*time.Millisecond).Err(); err != nil {
+		log.Printf("[Stats] Failed to report stats: %v\n", err)
 	}
 }
 
+// startHealth 启动探针服务：Kafka、Redis（已配置时）可达且各交易所适配器已连接时就绪
+func (c *Collector) startHealth() {
+	if c.config.Server.Port == 0 {
+		return
+	}
+
+	checker := health.New(constants.ServiceCollector)
+	checker.SetTimeout(time.Duration(c.config.Health.TimeoutMs) * time.Millisecond)
+	checker.AddReadiness("kafka", health.KafkaCheck(c.config.Kafka.Brokers))
+	if c.redis != nil {
+		checker.AddReadiness("redis", func(ctx context.Context) error {
+			return c.redis.Ping(ctx).Err()
+		})
+	}
+	for _, adapter := range c.adapters {
+		adapter := adapter
+		checker.AddReadiness("adapter:"+adapter.GetName(), func(ctx context.Context) error {
+			if !adapter.IsConnected() {
+				return fmt.Errorf("%s disconnected", adapter.GetName())
+			}
+			return nil
+		})
+	}
+
+	c.health = checker.Serve(fmt.Sprintf("%s:%d", c.config.Server.Host, c.config.Server.Port))
+}
+
+// watchControl 监听适配器控制命令，Redis 客户端关闭后退出
+func (c *Collector) watchControl() {
+	pubsub := c.redis.Subscribe(context.Background(), constants.RedisChannelAdapter)
+	defer pubsub.Close()
+
+	for msg := range pubsub.Channel() {
+		var cmd models.AdapterCommand
+		if err := json.Unmarshal([]byte(msg.Payload), &cmd); err != nil {
+			log.Printf("[Control] Invalid command: %v\n", err)
+			continue
+		}
+		c.handleCommand(&cmd)
+	}
+}
+
+// handleCommand 执行适配器控制命令，未运行的交易所忽略
+func (c *Collector) handleCommand(cmd *models.AdapterCommand) {
+	for _, adapter := range c.adapters {
+		if adapter.GetName() != cmd.Exchange {
+			continue
+		}
+
+		switch cmd.Action {
+		case models.AdapterActionReconnect:
+			reconnector, ok := adapter.(adapters.Reconnector)
+			if !ok {
+				log.Printf("[Control] [%s] Reconnect not supported\n", cmd.Exchange)
+				return
+			}
+			if err := reconnector.Reconnect(); err != nil {
+				log.Printf("[Control] [%s] Failed to reconnect: %v\n", cmd.Exchange, err)
+				return
+			}
+			log.Printf("[Control] [%s] Reconnect triggered\n", cmd.Exchange)
+		default:
+			log.Printf("[Control] [%s] Unknown action: %s\n", cmd.Exchange, cmd.Action)
+		}
+		return
+	}
+	log.Printf("[Control] [%s] Not running, ignoring %s\n", cmd.Exchange, cmd.Action)
+}
+
+// saveEngineTotals 保存撮合引擎推送的单日成交汇总（按交易对覆盖）
+func (c *Collector) saveEngineTotals(totals *models.EngineDailyTotals) error {
+	ctx := context.Background()
+	key := constants.RedisKeyEngineTotals + totals.Date
+
+	pipe := c.redis.Pipeline()
+	for i := range totals.Symbols {
+		data, err := utils.ToJSON(&totals.Symbols[i])
+		if err != nil {
+			return err
+		}
+		pipe.HSet(ctx, key, totals.Symbols[i].Symbol, data)
+	}
+	pipe.Expire(ctx, key, constants.DailyTotalsTTL*time.Millisecond)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
 // loadConfig 加载配置文件
 func loadConfig(path string) (*config.CollectorConfig, error) {
 	data, err := os.ReadFile(path)
@@ -170,10 +475,17 @@ func loadConfig(path string) (*config.CollectorConfig, error) {
 	return &cfg, nil
 }
 
-// waitForSignal 等待退出信号
-func waitForSignal() {
+// waitForSignal 等待退出信号，收到 SIGHUP 时调用 reload
+func waitForSignal(reload func()) {
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	<-sigChan
-	log.Println("Received shutdown signal")
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	for sig := range sigChan {
+		if sig == syscall.SIGHUP {
+			log.Println("Received reload signal")
+			reload()
+			continue
+		}
+		log.Println("Received shutdown signal")
+		return
+	}
 }
@@ -7,8 +7,10 @@ import (
 	"market-system/common/constants"
 	"market-system/common/models"
 	"market-system/common/utils"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -23,9 +25,13 @@ type BinanceAdapter struct {
 	handler       MessageHandler
 	closeChan     chan struct{}
 	reconnect     bool
-	subscriptions []string    // 保存订阅列表
+	subscriptions subscriptionSet    // 订阅集合（期望订阅 / 当前连接已生效订阅）
+	syncMu        sync.Mutex         // 串行化订阅同步
 	lastPong      time.Time   // 最后一次PONG时间
-	reconnectConf ReconnectConfig
+	subRequests   map[int64][]string // 订阅请求ID -> 订阅的 stream，用于定位出错的订阅
+	nextReqID     int64
+	errTracker    errorTracker
+	reconnecting  atomic.Int32 // 正在进行的重连次数
 }
 
 // NewBinanceAdapter 创建 Binance 适配器
@@ -34,16 +40,12 @@ func NewBinanceAdapter(wsURL string) ExchangeAdapter {
 		wsURL = "wss://stream.binance.com:9443/ws"
 	}
 	return &BinanceAdapter{
-		wsURL:     wsURL,
-		closeChan: make(chan struct{}),
-		reconnect: true,
-		lastPong:  time.Now(),
-		reconnectConf: ReconnectConfig{
-			MaxRetries:   10,
-			InitialDelay: 1 * time.Second,
-			MaxDelay:     60 * time.Second,
-			Multiplier:   2.0,
-		},
+		wsURL:         wsURL,
+		closeChan:     make(chan struct{}),
+		reconnect:     true,
+		lastPong:      time.Now(),
+		subRequests:   make(map[int64][]string),
+		subscriptions: newSubscriptionSet(),
 	}
 }
 
@@ -55,9 +57,9 @@ func (b *BinanceAdapter) Connect() error {
 	dialer := websocket.DefaultDialer
 	dialer.HandshakeTimeout = 10 * time.Second
 
-	conn, _, err := dialer.Dial(b.wsURL, nil)
+	conn, resp, err := dialer.Dial(b.wsURL, nil)
 	if err != nil {
-		return fmt.Errorf("failed to connect to binance: %w", err)
+		return fmt.Errorf("failed to connect to binance: %w", classifyHandshake(constants.ExchangeBinance, resp, err))
 	}
 
 	// 设置读取限制
@@ -82,49 +84,138 @@ func (b *BinanceAdapter) Connect() error {
 	return nil
 }
 
-// Subscribe 订阅数据
+// Subscribe 订阅数据（在现有订阅基础上追加）
 func (b *BinanceAdapter) Subscribe(symbols []string, channels []string) error {
 	if !b.IsConnected() {
 		return fmt.Errorf("not connected")
 	}
 
-	// 构建订阅消息
-	streams := make([]string, 0)
+	b.mu.Lock()
 	for _, symbol := range symbols {
-		symbolLower := strings.ToLower(symbol)
-		for _, channel := range channels {
-			switch channel {
-			case constants.DataTypeTicker:
-				streams = append(streams, fmt.Sprintf("%s@ticker", symbolLower))
-			case constants.DataTypeDepth:
-				streams = append(streams, fmt.Sprintf("%s@depth20@100ms", symbolLower))
-			case constants.DataTypeTrade:
-				streams = append(streams, fmt.Sprintf("%s@trade", symbolLower))
-			case constants.DataTypeKline:
-				streams = append(streams, fmt.Sprintf("%s@kline_1m", symbolLower))
-			}
+		b.subscriptions.add(b.buildStreams(symbol, channels))
+	}
+	b.mu.Unlock()
+
+	if err := b.syncSubscriptions(); err != nil {
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+	return nil
+}
+
+// UpdateSubscriptions 将订阅替换为指定的交易对和频道，只对变化的部分订阅/取消订阅
+func (b *BinanceAdapter) UpdateSubscriptions(symbols []string, channels []string) error {
+	streams := make([]string, 0, len(symbols)*len(channels))
+	for _, symbol := range symbols {
+		streams = append(streams, b.buildStreams(symbol, channels)...)
+	}
+
+	b.mu.Lock()
+	b.subscriptions.replace(streams)
+	b.mu.Unlock()
+
+	// 未连接时等待重连后同步
+	if !b.IsConnected() {
+		return nil
+	}
+	return b.syncSubscriptions()
+}
+
+// syncSubscriptions 计算期望订阅与已生效订阅的差集，只发送增量的订阅/取消订阅请求
+func (b *BinanceAdapter) syncSubscriptions() error {
+	b.syncMu.Lock()
+	defer b.syncMu.Unlock()
+
+	b.mu.RLock()
+	toAdd, toRemove := b.subscriptions.diff()
+	b.mu.RUnlock()
+
+	if len(toRemove) > 0 {
+		if err := b.sendRequest("UNSUBSCRIBE", toRemove); err != nil {
+			return err
 		}
+		b.mu.Lock()
+		b.subscriptions.markInactive(toRemove)
+		b.mu.Unlock()
 	}
 
-	subMsg := map[string]interface{}{
-		"method": "SUBSCRIBE",
-		"params": streams,
-		"id":     1,
+	// 按交易对分别发送订阅请求，交易所返回错误时可定位到具体交易对
+	for i, streams := range groupStreamsBySymbol(toAdd) {
+		// Binance 限制每秒最多 5 条订阅消息
+		if i > 0 || len(toRemove) > 0 {
+			time.Sleep(250 * time.Millisecond)
+		}
+		if err := b.sendRequest("SUBSCRIBE", streams); err != nil {
+			return err
+		}
+		b.mu.Lock()
+		b.subscriptions.markActive(streams)
+		b.mu.Unlock()
+	}
+
+	if len(toAdd) > 0 || len(toRemove) > 0 {
+		log.Printf("[Binance] Subscriptions synced: +%d -%d streams\n", len(toAdd), len(toRemove))
+	}
+	return nil
+}
+
+// groupStreamsBySymbol 按交易对分组 stream（stream 格式: {symbol}@{channel}）
+func groupStreamsBySymbol(streams []string) [][]string {
+	groups := make([][]string, 0)
+	index := make(map[string]int)
+	for _, stream := range streams {
+		symbol := stream
+		if i := strings.Index(stream, "@"); i >= 0 {
+			symbol = stream[:i]
+		}
+		if idx, ok := index[symbol]; ok {
+			groups[idx] = append(groups[idx], stream)
+			continue
+		}
+		index[symbol] = len(groups)
+		groups = append(groups, []string{stream})
 	}
+	return groups
+}
 
+// buildStreams 构建交易对的订阅 stream 列表
+func (b *BinanceAdapter) buildStreams(symbol string, channels []string) []string {
+	streams := make([]string, 0, len(channels))
+	symbolLower := strings.ToLower(symbol)
+	for _, channel := range channels {
+		switch channel {
+		case constants.DataTypeTicker:
+			streams = append(streams, fmt.Sprintf("%s@ticker", symbolLower))
+		case constants.DataTypeDepth:
+			streams = append(streams, fmt.Sprintf("%s@depth20@100ms", symbolLower))
+		case constants.DataTypeTrade:
+			streams = append(streams, fmt.Sprintf("%s@trade", symbolLower))
+		case constants.DataTypeKline:
+			streams = append(streams, fmt.Sprintf("%s@kline_1m", symbolLower))
+		}
+	}
+	return streams
+}
+
+// sendRequest 发送订阅/取消订阅请求
+func (b *BinanceAdapter) sendRequest(method string, streams []string) error {
 	b.mu.Lock()
-	err := b.conn.WriteJSON(subMsg)
-	if err == nil {
-		// 保存订阅列表（用于重连后重新订阅）
-		b.subscriptions = append(b.subscriptions, streams...)
+	defer b.mu.Unlock()
+
+	b.nextReqID++
+	id := b.nextReqID
+	msg := map[string]interface{}{
+		"method": method,
+		"params": streams,
+		"id":     id,
 	}
-	b.mu.Unlock()
 
-	if err != nil {
-		return fmt.Errorf("failed to subscribe: %w", err)
+	if err := b.conn.WriteJSON(msg); err != nil {
+		return err
 	}
 
-	log.Printf("[Binance] Subscribed to %d streams\n", len(streams))
+	if method == "SUBSCRIBE" {
+		b.subRequests[id] = streams
+	}
 	return nil
 }
 
@@ -155,6 +246,25 @@ func (b *BinanceAdapter) IsConnected() bool {
 	return b.connected
 }
 
+// Reconnect 强制重连：关闭当前连接，由读取循环重连并恢复订阅；未连接且不在重连中时直接发起重连
+func (b *BinanceAdapter) Reconnect() error {
+	b.mu.RLock()
+	conn, connected, enabled := b.conn, b.connected, b.reconnect
+	b.mu.RUnlock()
+
+	if !enabled {
+		return fmt.Errorf("binance adapter closed")
+	}
+	log.Printf("[Binance] Manual reconnect requested\n")
+	if connected && conn != nil {
+		return conn.Close()
+	}
+	if b.reconnecting.Load() == 0 {
+		go b.handleReconnect(errManualReconnect)
+	}
+	return nil
+}
+
 // GetName 获取交易所名称
 func (b *BinanceAdapter) GetName() string {
 	return constants.ExchangeBinance
@@ -177,7 +287,7 @@ func (b *BinanceAdapter) readMessages() {
 			if err != nil {
 				log.Printf("[Binance] Read error: %v\n", err)
 				if b.reconnect {
-					b.handleReconnect()
+					b.handleReconnect(err)
 				}
 				return
 			}
@@ -194,14 +304,26 @@ func (b *BinanceAdapter) handleMessage(message []byte) {
 		return
 	}
 
+	// 数字按 json.Number 解析，成交 ID 等 64 位整数不丢失精度
 	var rawMsg map[string]interface{}
-	if err := json.Unmarshal(message, &rawMsg); err != nil {
+	if err := utils.FromJSONBytesUseNumber(message, &rawMsg); err != nil {
 		log.Printf("[Binance] Failed to parse message: %v\n", err)
 		return
 	}
 
+	// 检查是否是订阅错误
+	if errMsg, ok := rawMsg["error"].(map[string]interface{}); ok {
+		b.handleError(errMsg, rawMsg["id"])
+		return
+	}
+
 	// 检查是否是订阅响应
 	if _, ok := rawMsg["result"]; ok {
+		if id := parseInt64(rawMsg["id"]); id != 0 {
+			b.mu.Lock()
+			delete(b.subRequests, id)
+			b.mu.Unlock()
+		}
 		return
 	}
 
@@ -235,14 +357,18 @@ func (b *BinanceAdapter) handleMessage(message []byte) {
 // parseTicker 解析 Ticker 数据
 func (b *BinanceAdapter) parseTicker(raw map[string]interface{}, symbol string, timestamp int64) *models.MarketData {
 	ticker := &models.Ticker{
-		Symbol:    symbol,
-		LastPrice: parseFloat(raw["c"]),
-		BidPrice:  parseFloat(raw["b"]),
-		AskPrice:  parseFloat(raw["a"]),
-		High24h:   parseFloat(raw["h"]),
-		Low24h:    parseFloat(raw["l"]),
-		Volume24h: parseFloat(raw["v"]),
-		Timestamp: timestamp,
+		Symbol:                symbol,
+		LastPrice:             parseFloat(raw["c"]),
+		BidPrice:              parseFloat(raw["b"]),
+		AskPrice:              parseFloat(raw["a"]),
+		High24h:               parseFloat(raw["h"]),
+		Low24h:                parseFloat(raw["l"]),
+		Volume24h:             parseFloat(raw["v"]),
+		Open24h:               parseFloat(raw["o"]),
+		PriceChange24h:        parseFloat(raw["p"]),
+		PriceChangePercent24h: parseFloat(raw["P"]),
+		TradeCount24h:         parseInt64(raw["n"]),
+		Timestamp:             timestamp,
 	}
 
 	return &models.MarketData{
@@ -284,13 +410,13 @@ func (b *BinanceAdapter) parseTrade(raw map[string]interface{}, symbol string, t
 
 	// 安全获取时间戳
 	ts := timestamp
-	if T, ok := raw["T"].(float64); ok {
-		ts = int64(T)
+	if T := parseInt64(raw["T"]); T != 0 {
+		ts = T
 	}
 
 	trade := &models.Trade{
 		Symbol:    symbol,
-		TradeID:   fmt.Sprintf("%v", raw["t"]),
+		TradeID:   parseID(raw["t"]),
 		Price:     parseFloat(raw["p"]),
 		Amount:    parseFloat(raw["q"]),
 		Side:      side,
@@ -313,15 +439,15 @@ func (b *BinanceAdapter) parseKline(raw map[string]interface{}, symbol string, t
 	kline := &models.Kline{
 		Symbol:    symbol,
 		Interval:  k["i"].(string),
-		OpenTime:  int64(k["t"].(float64)),
-		CloseTime: int64(k["T"].(float64)),
+		OpenTime:  parseInt64(k["t"]),
+		CloseTime: parseInt64(k["T"]),
 		Open:      parseFloat(k["o"]),
 		High:      parseFloat(k["h"]),
 		Low:       parseFloat(k["l"]),
 		Close:     parseFloat(k["c"]),
 		Volume:    parseFloat(k["v"]),
 		QuoteVol:  parseFloat(k["q"]),
-		TradeNum:  int64(k["n"].(float64)),
+		TradeNum:  parseInt64(k["n"]),
 	}
 
 	return &models.MarketData{
@@ -347,7 +473,7 @@ func (b *BinanceAdapter) keepAlive() {
 				// 检查心跳超时
 				if time.Since(b.lastPong) > 60*time.Second {
 					log.Println("[Binance] Pong timeout, reconnecting...")
-					b.handleReconnect()
+					b.handleReconnect(fmt.Errorf("pong timeout"))
 					return
 				}
 
@@ -363,55 +489,85 @@ func (b *BinanceAdapter) keepAlive() {
 	}
 }
 
-// handleReconnect 处理重连（指数退避）
-func (b *BinanceAdapter) handleReconnect() {
-	retries := 0
-	delay := b.reconnectConf.InitialDelay
-
-	for retries < b.reconnectConf.MaxRetries {
-		log.Printf("[Binance] Attempting to reconnect (attempt %d/%d)...\n", retries+1, b.reconnectConf.MaxRetries)
-		time.Sleep(delay)
+// handleReconnect 处理重连（按错误类别的重试策略退避）
+func (b *BinanceAdapter) handleReconnect(cause error) {
+	b.reconnecting.Add(1)
+	defer b.reconnecting.Add(-1)
 
-		if err := b.Connect(); err == nil {
-			log.Println("[Binance] Reconnected successfully")
-			// 重新订阅
-			b.resubscribe()
-			return
+	reconnectWithPolicy("Binance", cause, &b.errTracker, func() error {
+		if err := b.Connect(); err != nil {
+			return err
 		}
+		// 重新订阅
+		b.resubscribe()
+		return nil
+	})
+}
 
-		retries++
-		// 指数退避
-		delay = time.Duration(float64(delay) * b.reconnectConf.Multiplier)
-		if delay > b.reconnectConf.MaxDelay {
-			delay = b.reconnectConf.MaxDelay
-		}
+// handleError 处理交易所返回的错误消息并分类
+// 错误码参考: https://binance-docs.github.io/apidocs/spot/en/#error-messages
+func (b *BinanceAdapter) handleError(errMsg map[string]interface{}, rawID interface{}) {
+	code := fmt.Sprintf("%v", errMsg["code"])
+	msg, _ := errMsg["msg"].(string)
+
+	adapterErr := &AdapterError{
+		Exchange: constants.ExchangeBinance,
+		Class:    ErrorClassTransient,
+		Code:     code,
+		Err:      fmt.Errorf("%s", msg),
 	}
 
-	log.Printf("[Binance] Max retries (%d) reached, giving up\n", b.reconnectConf.MaxRetries)
-}
+	lowerMsg := strings.ToLower(msg)
+	switch {
+	case code == "-1003" || strings.Contains(lowerMsg, "too many"):
+		adapterErr.Class = ErrorClassRateLimited
+	case code == "-2014" || code == "-2015" || strings.Contains(lowerMsg, "api-key"):
+		adapterErr.Class = ErrorClassAuth
+	case code == "-1121" || strings.Contains(lowerMsg, "symbol") || strings.Contains(lowerMsg, "invalid request"):
+		adapterErr.Class = ErrorClassInvalidSymbol
+	}
 
-// resubscribe 重新订阅
-func (b *BinanceAdapter) resubscribe() error {
-	if len(b.subscriptions) == 0 {
-		return nil
+	// 定位出错的订阅请求
+	var streams []string
+	if id := parseInt64(rawID); id != 0 {
+		b.mu.Lock()
+		streams = b.subRequests[id]
+		delete(b.subRequests, id)
+		b.mu.Unlock()
 	}
 
-	subMsg := map[string]interface{}{
-		"method": "SUBSCRIBE",
-		"params": b.subscriptions,
-		"id":     time.Now().Unix(),
+	// 无效订阅从订阅列表中移除，避免重连后重复订阅
+	if adapterErr.Class == ErrorClassInvalidSymbol && len(streams) > 0 {
+		adapterErr.Symbols = streams
+		b.removeSubscriptions(streams)
 	}
 
+	b.errTracker.record(adapterErr)
+}
+
+// removeSubscriptions 从订阅集合中移除指定的 stream
+func (b *BinanceAdapter) removeSubscriptions(streams []string) {
 	b.mu.Lock()
-	err := b.conn.WriteJSON(subMsg)
+	b.subscriptions.remove(streams)
 	b.mu.Unlock()
+	log.Printf("[Binance] Removed %d invalid streams: %v\n", len(streams), streams)
+}
 
-	if err != nil {
+// ErrorStats 获取各类错误次数
+func (b *BinanceAdapter) ErrorStats() map[ErrorClass]int64 {
+	return b.errTracker.stats()
+}
+
+// resubscribe 重连后重新订阅（新连接上没有已生效的订阅，同步全部期望订阅）
+func (b *BinanceAdapter) resubscribe() error {
+	b.mu.Lock()
+	b.subscriptions.reset()
+	b.mu.Unlock()
+
+	if err := b.syncSubscriptions(); err != nil {
 		log.Printf("[Binance] Resubscribe failed: %v\n", err)
 		return err
 	}
-
-	log.Printf("[Binance] Resubscribed to %d streams\n", len(b.subscriptions))
 	return nil
 }
 
@@ -423,6 +579,9 @@ func parseFloat(v interface{}) float64 {
 	switch val := v.(type) {
 	case float64:
 		return val
+	case json.Number:
+		f, _ := val.Float64()
+		return f
 	case string:
 		var f float64
 		fmt.Sscanf(val, "%f", &f)
@@ -432,6 +591,40 @@ func parseFloat(v interface{}) float64 {
 	}
 }
 
+// parseInt64 解析整数，json.Number 和字符串按整数解析不丢失精度
+func parseInt64(v interface{}) int64 {
+	switch val := v.(type) {
+	case json.Number:
+		i, err := val.Int64()
+		if err != nil {
+			f, _ := val.Float64()
+			return int64(f)
+		}
+		return i
+	case float64:
+		return int64(val)
+	case string:
+		i, _ := strconv.ParseInt(val, 10, 64)
+		return i
+	default:
+		return 0
+	}
+}
+
+// parseID 将数字或字符串 ID 转换为字符串，数字 ID 保留原始的十进制表示
+func parseID(v interface{}) string {
+	switch val := v.(type) {
+	case json.Number:
+		return val.String()
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case string:
+		return val
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
 func parsePriceLevels(v interface{}) []models.PriceLevel {
 	if v == nil {
 		return []models.PriceLevel{}
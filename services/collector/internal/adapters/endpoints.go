@@ -0,0 +1,145 @@
+package adapters
+
+import (
+	"fmt"
+	"log"
+	"market-system/common/constants"
+	"net"
+	"net/url"
+	"time"
+)
+
+// RegionAuto 按连接延迟自动选择接入点
+const RegionAuto = "auto"
+
+// latencyProbeTimeout 测量接入点延迟的连接超时
+const latencyProbeTimeout = 3 * time.Second
+
+// Endpoint 交易所 WebSocket 接入点
+type Endpoint struct {
+	Region string // 区域名称，配置中通过 region 选择
+	URL    string
+	Auto   bool // 是否参与按延迟自动选择
+}
+
+// Endpoints 各交易所的区域接入点，第一个为默认接入点
+// - Binance data-stream.binance.vision 为只提供行情数据的镜像；binance.us 是独立站点，交易对与主站不同，只能显式选择
+// - OKX wsaws.okx.com 为部署在 AWS 上的接入点，适合部署在 AWS 的服务
+var Endpoints = map[string][]Endpoint{
+	constants.ExchangeBinance: {
+		{Region: "global", URL: "wss://stream.binance.com:9443/ws", Auto: true},
+		{Region: "global-443", URL: "wss://stream.binance.com:443/ws", Auto: true},
+		{Region: "data", URL: "wss://data-stream.binance.vision/ws", Auto: true},
+		{Region: "us", URL: "wss://stream.binance.us:9443/ws"},
+	},
+	constants.ExchangeOKX: {
+		{Region: "global", URL: "wss://ws.okx.com:8443/ws/v5/public", Auto: true},
+		{Region: "aws", URL: "wss://wsaws.okx.com:8443/ws/v5/public", Auto: true},
+	},
+}
+
+// Host 接入点地址（host:port），记录在行情数据中
+func (e Endpoint) Host() string {
+	u, err := url.Parse(e.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// ResolveEndpoint 按配置确定交易所的接入点
+// - region 为空时使用 wsURL，wsURL 也为空时使用默认接入点
+// - region 为 auto 时测量参与自动选择的接入点的连接延迟，选择最快的；全部不可达时使用默认接入点
+// - 其余按区域名称选择
+func ResolveEndpoint(exchange, region, wsURL string) (Endpoint, error) {
+	endpoints := Endpoints[exchange]
+	if region == "" {
+		if wsURL == "" && len(endpoints) > 0 {
+			return endpoints[0], nil
+		}
+		return Endpoint{URL: wsURL}, nil
+	}
+
+	if len(endpoints) == 0 {
+		return Endpoint{}, fmt.Errorf("no regional endpoints for exchange %s", exchange)
+	}
+
+	if region == RegionAuto {
+		return fastestEndpoint(exchange, endpoints), nil
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint.Region == region {
+			return endpoint, nil
+		}
+	}
+	return Endpoint{}, fmt.Errorf("unknown region %q for exchange %s", region, exchange)
+}
+
+// fastestEndpoint 并发测量各接入点的 TCP 连接延迟，返回最快的接入点
+func fastestEndpoint(exchange string, endpoints []Endpoint) Endpoint {
+	type probe struct {
+		endpoint Endpoint
+		latency  time.Duration
+		err      error
+	}
+
+	results := make(chan probe)
+	candidates := 0
+	for _, endpoint := range endpoints {
+		if !endpoint.Auto {
+			continue
+		}
+		candidates++
+		go func(endpoint Endpoint) {
+			latency, err := measureLatency(endpoint)
+			results <- probe{endpoint: endpoint, latency: latency, err: err}
+		}(endpoint)
+	}
+
+	best := probe{endpoint: endpoints[0], err: fmt.Errorf("no endpoint reachable")}
+	for i := 0; i < candidates; i++ {
+		p := <-results
+		if p.err != nil {
+			log.Printf("[%s] Endpoint %s (%s) unreachable: %v\n", exchange, p.endpoint.Region, p.endpoint.Host(), p.err)
+			continue
+		}
+		log.Printf("[%s] Endpoint %s (%s) latency: %v\n", exchange, p.endpoint.Region, p.endpoint.Host(), p.latency)
+		if best.err != nil || p.latency < best.latency {
+			best = p
+		}
+	}
+
+	if best.err != nil {
+		log.Printf("[%s] No endpoint reachable, using default %s\n", exchange, best.endpoint.Region)
+	} else {
+		log.Printf("[%s] Selected endpoint %s (%s)\n", exchange, best.endpoint.Region, best.endpoint.Host())
+	}
+	return best.endpoint
+}
+
+// measureLatency 测量建立 TCP 连接的耗时
+func measureLatency(endpoint Endpoint) (time.Duration, error) {
+	u, err := url.Parse(endpoint.URL)
+	if err != nil {
+		return 0, err
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "wss" || u.Scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", host, latencyProbeTimeout)
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	conn.Close()
+	return latency, nil
+}
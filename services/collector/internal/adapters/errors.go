@@ -0,0 +1,234 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrorClass 交易所错误分类
+type ErrorClass string
+
+const (
+	ErrorClassTransient     ErrorClass = "transient"      // 网络抖动、连接断开等临时错误
+	ErrorClassRateLimited   ErrorClass = "rate_limited"   // 被交易所限流
+	ErrorClassAuth          ErrorClass = "auth"           // 认证失败、IP 被封禁
+	ErrorClassInvalidSymbol ErrorClass = "invalid_symbol" // 交易对或频道不存在
+)
+
+// AdapterError 带分类的适配器错误
+type AdapterError struct {
+	Exchange string
+	Class    ErrorClass
+	Code     string   // 交易所错误码
+	Symbols  []string // 出错的交易对/订阅（仅 invalid_symbol）
+	Err      error
+}
+
+func (e *AdapterError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("[%s] %s error (code %s): %v", e.Exchange, e.Class, e.Code, e.Err)
+	}
+	return fmt.Sprintf("[%s] %s error: %v", e.Exchange, e.Class, e.Err)
+}
+
+func (e *AdapterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryPolicy 重试策略
+type RetryPolicy struct {
+	Retry        bool          // 是否重连
+	MaxRetries   int           // 最大重试次数
+	InitialDelay time.Duration // 初始等待时间
+	MaxDelay     time.Duration // 最大等待时间
+	Multiplier   float64       // 退避倍数
+	Alert        bool          // 是否输出告警
+}
+
+// Delay 计算第 attempt 次重试前的等待时间（指数退避）
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.InitialDelay
+	for i := 0; i < attempt; i++ {
+		delay = time.Duration(float64(delay) * p.Multiplier)
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return delay
+}
+
+// DefaultRetryPolicies 各类错误的默认重试策略
+// - transient: 快速指数退避重连
+// - rate_limited: 较长的退避时间，避免加重限流或被封禁
+// - auth: 重连无法恢复，停止重连并告警
+// - invalid_symbol: 从订阅列表中移除出错的交易对后正常重连，避免重复订阅导致的重连循环
+var DefaultRetryPolicies = map[ErrorClass]RetryPolicy{
+	ErrorClassTransient: {
+		Retry:        true,
+		MaxRetries:   10,
+		InitialDelay: 1 * time.Second,
+		MaxDelay:     60 * time.Second,
+		Multiplier:   2.0,
+	},
+	ErrorClassRateLimited: {
+		Retry:        true,
+		MaxRetries:   10,
+		InitialDelay: 30 * time.Second,
+		MaxDelay:     5 * time.Minute,
+		Multiplier:   2.0,
+		Alert:        true,
+	},
+	ErrorClassAuth: {
+		Retry: false,
+		Alert: true,
+	},
+	ErrorClassInvalidSymbol: {
+		Retry:        true,
+		MaxRetries:   10,
+		InitialDelay: 1 * time.Second,
+		MaxDelay:     60 * time.Second,
+		Multiplier:   2.0,
+		Alert:        true,
+	},
+}
+
+// ClassifyError 对错误进行分类，无法识别的错误视为临时错误
+func ClassifyError(err error) ErrorClass {
+	var adapterErr *AdapterError
+	if errors.As(err, &adapterErr) {
+		return adapterErr.Class
+	}
+
+	var closeErr *websocket.CloseError
+	if errors.As(err, &closeErr) {
+		switch closeErr.Code {
+		case websocket.ClosePolicyViolation, websocket.CloseTryAgainLater:
+			return ErrorClassRateLimited
+		}
+		return ErrorClassTransient
+	}
+
+	return ErrorClassTransient
+}
+
+// classifyHandshake 根据 WebSocket 握手的 HTTP 状态码分类连接错误
+func classifyHandshake(exchange string, resp *http.Response, err error) error {
+	if resp == nil {
+		return err
+	}
+
+	var class ErrorClass
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, 418: // Binance 使用 418 表示 IP 因频繁请求被封禁
+		class = ErrorClassRateLimited
+	case http.StatusUnauthorized, http.StatusForbidden:
+		class = ErrorClassAuth
+	default:
+		return err
+	}
+
+	return &AdapterError{
+		Exchange: exchange,
+		Class:    class,
+		Code:     fmt.Sprintf("http %d", resp.StatusCode),
+		Err:      err,
+	}
+}
+
+// errorTracker 记录适配器的错误统计，以及最近一次由交易所消息上报的错误
+type errorTracker struct {
+	mu     sync.Mutex
+	counts map[ErrorClass]int64
+	last   *AdapterError
+}
+
+// record 记录错误，需要告警的错误类别输出告警日志
+func (t *errorTracker) record(err *AdapterError) {
+	t.mu.Lock()
+	if t.counts == nil {
+		t.counts = make(map[ErrorClass]int64)
+	}
+	t.counts[err.Class]++
+	t.last = err
+	t.mu.Unlock()
+
+	if DefaultRetryPolicies[err.Class].Alert {
+		log.Printf("[ALERT] %v\n", err)
+	} else {
+		log.Printf("[%s] %v\n", err.Exchange, err)
+	}
+}
+
+// takeLast 取出最近一次交易所上报的错误
+func (t *errorTracker) takeLast() *AdapterError {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	last := t.last
+	t.last = nil
+	return last
+}
+
+// stats 获取各类错误次数
+func (t *errorTracker) stats() map[ErrorClass]int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := make(map[ErrorClass]int64, len(t.counts))
+	for class, count := range t.counts {
+		stats[class] = count
+	}
+	return stats
+}
+
+// ErrorReporter 可上报错误统计的适配器
+type ErrorReporter interface {
+	ErrorStats() map[ErrorClass]int64
+}
+
+// errManualReconnect 管理接口触发的重连，按临时错误的策略重连
+var errManualReconnect = errors.New("manual reconnect")
+
+// reconnectWithPolicy 按错误类别的重试策略重连，重连过程中错误类别变化时切换到对应策略
+// connect 为建立连接并重新订阅的函数
+func reconnectWithPolicy(exchange string, cause error, tracker *errorTracker, connect func() error) {
+	// 交易所在断开前上报的错误优先于读取错误（例如限流后被服务端断开）
+	class := ClassifyError(cause)
+	if last := tracker.takeLast(); last != nil && last.Class != ErrorClassInvalidSymbol {
+		class = last.Class
+	}
+
+	policy := DefaultRetryPolicies[class]
+	for attempt := 0; ; attempt++ {
+		if !policy.Retry {
+			log.Printf("[ALERT] [%s] %s error, stop reconnecting: %v\n", exchange, class, cause)
+			return
+		}
+		if attempt >= policy.MaxRetries {
+			log.Printf("[ALERT] [%s] Max retries (%d) reached for %s error, giving up\n", exchange, policy.MaxRetries, class)
+			return
+		}
+
+		delay := policy.Delay(attempt)
+		log.Printf("[%s] Attempting to reconnect in %v (attempt %d/%d, cause: %s)...\n",
+			exchange, delay, attempt+1, policy.MaxRetries, class)
+		time.Sleep(delay)
+
+		err := connect()
+		if err == nil {
+			log.Printf("[%s] Reconnected successfully\n", exchange)
+			return
+		}
+
+		cause = err
+		if newClass := ClassifyError(err); newClass != class {
+			log.Printf("[%s] Reconnect failed with %s error, switching retry policy\n", exchange, newClass)
+			class = newClass
+			policy = DefaultRetryPolicies[class]
+		}
+	}
+}
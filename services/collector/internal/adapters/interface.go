@@ -10,6 +10,9 @@ type ExchangeAdapter interface {
 	// Subscribe 订阅数据
 	Subscribe(symbols []string, channels []string) error
 
+	// UpdateSubscriptions 替换订阅的交易对和频道（配置重载时调用），只对变化部分订阅/取消订阅
+	UpdateSubscriptions(symbols []string, channels []string) error
+
 	// OnMessage 设置消息处理器
 	OnMessage(handler MessageHandler)
 
@@ -23,6 +26,11 @@ type ExchangeAdapter interface {
 	GetName() string
 }
 
+// Reconnector 支持强制重连的适配器（管理接口触发）
+type Reconnector interface {
+	Reconnect() error
+}
+
 // MessageHandler 消息处理器
 type MessageHandler func(data *models.MarketData)
 
@@ -9,6 +9,7 @@ import (
 	"market-system/common/utils"
 	"net/http"
 	"sync"
+	"time"
 )
 
 // InternalAdapter 内部数据源适配器
@@ -19,6 +20,8 @@ type InternalAdapter struct {
 	mu         sync.RWMutex
 	connected  bool
 	port       int
+
+	totalsHandler func(totals *models.EngineDailyTotals) error // 撮合引擎单日成交汇总处理，为 nil 表示不接收
 }
 
 // NewInternalAdapter 创建内部适配器
@@ -42,6 +45,7 @@ func (a *InternalAdapter) Connect() error {
 	mux.HandleFunc("/api/market/trade", a.handleTrade)
 	mux.HandleFunc("/api/market/depth", a.handleDepth)
 	mux.HandleFunc("/api/market/ticker", a.handleTicker)
+	mux.HandleFunc("/api/market/daily_totals", a.handleDailyTotals)
 	mux.HandleFunc("/health", a.handleHealth)
 
 	// 创建 HTTP 服务器
@@ -69,11 +73,22 @@ func (a *InternalAdapter) Subscribe(symbols []string, channels []string) error {
 	return nil
 }
 
+// UpdateSubscriptions 内部适配器接收所有推送的数据，不需要更新订阅
+func (a *InternalAdapter) UpdateSubscriptions(symbols []string, channels []string) error {
+	log.Printf("[Internal] Subscriptions updated: symbols: %v, channels: %v\n", symbols, channels)
+	return nil
+}
+
 // OnMessage 设置消息处理器
 func (a *InternalAdapter) OnMessage(handler MessageHandler) {
 	a.handler = handler
 }
 
+// OnDailyTotals 设置撮合引擎单日成交汇总的处理器（保存后供 Processor 对账）
+func (a *InternalAdapter) OnDailyTotals(handler func(totals *models.EngineDailyTotals) error) {
+	a.totalsHandler = handler
+}
+
 // Close 关闭服务器
 func (a *InternalAdapter) Close() error {
 	a.mu.Lock()
@@ -119,7 +134,7 @@ func (a *InternalAdapter) handleTrade(w http.ResponseWriter, r *http.Request) {
 	// 转换为标准 Trade 格式
 	standardTrade := &models.Trade{
 		Symbol:    trade.Symbol,
-		TradeID:   fmt.Sprintf("%d", trade.TradeID),
+		TradeID:   trade.TradeID.String(),
 		Price:     trade.Price,
 		Amount:    trade.Amount,
 		Side:      trade.Side,
@@ -242,6 +257,49 @@ func (a *InternalAdapter) handleTicker(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[Internal] Ticker received: %s @ %.2f\n", ticker.Symbol, ticker.LastPrice)
 }
 
+// handleDailyTotals 处理撮合引擎推送的单日成交汇总
+func (a *InternalAdapter) handleDailyTotals(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if a.totalsHandler == nil {
+		http.Error(w, "Daily totals not supported", http.StatusServiceUnavailable)
+		return
+	}
+
+	var totals models.EngineDailyTotals
+	if err := json.NewDecoder(r.Body).Decode(&totals); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if _, err := time.Parse("2006-01-02", totals.Date); err != nil {
+		http.Error(w, "Invalid date", http.StatusBadRequest)
+		return
+	}
+	for _, t := range totals.Symbols {
+		if err := utils.ValidateSymbol(t.Symbol); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := a.totalsHandler(&totals); err != nil {
+		log.Printf("[Internal] Failed to save daily totals of %s: %v\n", totals.Date, err)
+		http.Error(w, "Failed to save daily totals", http.StatusInternalServerError)
+		return
+	}
+
+	// 响应成功
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code": 0,
+		"msg":  "success",
+	})
+
+	log.Printf("[Internal] Daily totals received: %s, symbols: %d\n", totals.Date, len(totals.Symbols))
+}
+
 // handleHealth 健康检查
 func (a *InternalAdapter) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -9,6 +9,7 @@ import (
 	"market-system/common/utils"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -23,17 +24,11 @@ type OKXAdapter struct {
 	handler       MessageHandler
 	closeChan     chan struct{}
 	reconnect     bool
-	subscriptions []string      // 保存订阅列表
+	subscriptions subscriptionSet // 订阅集合（期望订阅 / 当前连接已生效订阅），key 格式: {channel}:{instId}
+	syncMu        sync.Mutex      // 串行化订阅同步
 	lastPong      time.Time     // 最后一次PONG时间
-	reconnectConf ReconnectConfig
-}
-
-// ReconnectConfig 重连配置
-type ReconnectConfig struct {
-	MaxRetries   int
-	InitialDelay time.Duration
-	MaxDelay     time.Duration
-	Multiplier   float64
+	errTracker    errorTracker
+	reconnecting  atomic.Int32 // 正在进行的重连次数
 }
 
 // NewOKXAdapter 创建 OKX 适配器
@@ -42,16 +37,11 @@ func NewOKXAdapter(wsURL string) ExchangeAdapter {
 		wsURL = "wss://ws.okx.com:8443/ws/v5/public"
 	}
 	return &OKXAdapter{
-		wsURL:     wsURL,
-		closeChan: make(chan struct{}),
-		reconnect: true,
-		lastPong:  time.Now(),
-		reconnectConf: ReconnectConfig{
-			MaxRetries:   10,
-			InitialDelay: 1 * time.Second,
-			MaxDelay:     60 * time.Second,
-			Multiplier:   2.0,
-		},
+		wsURL:         wsURL,
+		closeChan:     make(chan struct{}),
+		reconnect:     true,
+		lastPong:      time.Now(),
+		subscriptions: newSubscriptionSet(),
 	}
 }
 
@@ -63,9 +53,9 @@ func (o *OKXAdapter) Connect() error {
 	dialer := websocket.DefaultDialer
 	dialer.HandshakeTimeout = 10 * time.Second
 
-	conn, _, err := dialer.Dial(o.wsURL, nil)
+	conn, resp, err := dialer.Dial(o.wsURL, nil)
 	if err != nil {
-		return fmt.Errorf("failed to connect to OKX: %w", err)
+		return fmt.Errorf("failed to connect to OKX: %w", classifyHandshake(constants.ExchangeOKX, resp, err))
 	}
 
 	// 设置读取限制
@@ -90,16 +80,38 @@ func (o *OKXAdapter) Connect() error {
 	return nil
 }
 
-// Subscribe 订阅数据
+// Subscribe 订阅数据（在现有订阅基础上追加）
 func (o *OKXAdapter) Subscribe(symbols []string, channels []string) error {
 	if !o.IsConnected() {
 		return fmt.Errorf("not connected")
 	}
 
-	// 构建订阅参数
-	args := make([]map[string]string, 0)
-	subscriptions := make([]string, 0)
+	o.mu.Lock()
+	o.subscriptions.add(o.buildSubscriptions(symbols, channels))
+	o.mu.Unlock()
+
+	if err := o.syncSubscriptions(); err != nil {
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+	return nil
+}
 
+// UpdateSubscriptions 将订阅替换为指定的交易对和频道，只对变化的部分订阅/取消订阅
+func (o *OKXAdapter) UpdateSubscriptions(symbols []string, channels []string) error {
+	o.mu.Lock()
+	o.subscriptions.replace(o.buildSubscriptions(symbols, channels))
+	o.mu.Unlock()
+
+	// 未连接时等待重连后同步
+	if !o.IsConnected() {
+		return nil
+	}
+	return o.syncSubscriptions()
+}
+
+// buildSubscriptions 构建订阅 key 列表
+func (o *OKXAdapter) buildSubscriptions(symbols []string, channels []string) []string {
+	subscriptions := make([]string, 0, len(symbols)*len(channels))
 	for _, symbol := range symbols {
 		// OKX使用 BTC-USDT 格式
 		instId := o.formatSymbol(symbol)
@@ -119,38 +131,70 @@ func (o *OKXAdapter) Subscribe(symbols []string, channels []string) error {
 				continue
 			}
 
-			args = append(args, map[string]string{
-				"channel": okxChannel,
-				"instId":  instId,
-			})
-
-			// 保存订阅信息
 			subscriptions = append(subscriptions, fmt.Sprintf("%s:%s", okxChannel, instId))
 		}
 	}
+	return subscriptions
+}
 
-	// OKX订阅消息格式
-	subMsg := map[string]interface{}{
-		"op":   "subscribe",
-		"args": args,
-	}
+// syncSubscriptions 计算期望订阅与已生效订阅的差集，只发送增量的订阅/取消订阅请求
+func (o *OKXAdapter) syncSubscriptions() error {
+	o.syncMu.Lock()
+	defer o.syncMu.Unlock()
 
-	o.mu.Lock()
-	err := o.conn.WriteJSON(subMsg)
-	if err == nil {
-		// 保存订阅列表（用于重连后重新订阅）
-		o.subscriptions = append(o.subscriptions, subscriptions...)
+	o.mu.RLock()
+	toAdd, toRemove := o.subscriptions.diff()
+	o.mu.RUnlock()
+
+	if len(toRemove) > 0 {
+		if err := o.sendRequest("unsubscribe", toRemove); err != nil {
+			return err
+		}
+		o.mu.Lock()
+		o.subscriptions.markInactive(toRemove)
+		o.mu.Unlock()
 	}
-	o.mu.Unlock()
 
-	if err != nil {
-		return fmt.Errorf("failed to subscribe: %w", err)
+	if len(toAdd) > 0 {
+		if err := o.sendRequest("subscribe", toAdd); err != nil {
+			return err
+		}
+		o.mu.Lock()
+		o.subscriptions.markActive(toAdd)
+		o.mu.Unlock()
 	}
 
-	log.Printf("[OKX] Subscribed to %d channels\n", len(args))
+	if len(toAdd) > 0 || len(toRemove) > 0 {
+		log.Printf("[OKX] Subscriptions synced: +%d -%d channels\n", len(toAdd), len(toRemove))
+	}
 	return nil
 }
 
+// sendRequest 发送订阅/取消订阅请求
+func (o *OKXAdapter) sendRequest(op string, subscriptions []string) error {
+	// 构建订阅参数
+	args := make([]map[string]string, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		parts := strings.SplitN(sub, ":", 2)
+		if len(parts) == 2 {
+			args = append(args, map[string]string{
+				"channel": parts[0],
+				"instId":  parts[1],
+			})
+		}
+	}
+
+	// OKX订阅消息格式
+	msg := map[string]interface{}{
+		"op":   op,
+		"args": args,
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.conn.WriteJSON(msg)
+}
+
 // OnMessage 设置消息处理器
 func (o *OKXAdapter) OnMessage(handler MessageHandler) {
 	o.handler = handler
@@ -178,6 +222,25 @@ func (o *OKXAdapter) IsConnected() bool {
 	return o.connected
 }
 
+// Reconnect 强制重连：关闭当前连接，由读取循环重连并恢复订阅；未连接且不在重连中时直接发起重连
+func (o *OKXAdapter) Reconnect() error {
+	o.mu.RLock()
+	conn, connected, enabled := o.conn, o.connected, o.reconnect
+	o.mu.RUnlock()
+
+	if !enabled {
+		return fmt.Errorf("okx adapter closed")
+	}
+	log.Printf("[OKX] Manual reconnect requested\n")
+	if connected && conn != nil {
+		return conn.Close()
+	}
+	if o.reconnecting.Load() == 0 {
+		go o.handleReconnect(errManualReconnect)
+	}
+	return nil
+}
+
 // GetName 获取交易所名称
 func (o *OKXAdapter) GetName() string {
 	return constants.ExchangeOKX
@@ -200,7 +263,7 @@ func (o *OKXAdapter) readMessages() {
 			if err != nil {
 				log.Printf("[OKX] Read error: %v\n", err)
 				if o.reconnect {
-					o.handleReconnect()
+					o.handleReconnect(err)
 				}
 				return
 			}
@@ -229,7 +292,7 @@ func (o *OKXAdapter) handleMessage(message []byte) {
 			log.Printf("[OKX] Subscription confirmed: %v\n", rawMsg["arg"])
 			return
 		} else if event == "error" {
-			log.Printf("[OKX] Error: %v\n", rawMsg["msg"])
+			o.handleError(rawMsg)
 			return
 		}
 	}
@@ -293,6 +356,7 @@ func (o *OKXAdapter) parseTicker(raw map[string]interface{}, symbol string, time
 		High24h:   parseFloat(raw["high24h"]),
 		Low24h:    parseFloat(raw["low24h"]),
 		Volume24h: parseFloat(raw["vol24h"]),
+		Open24h:   parseFloat(raw["open24h"]), // 不提供涨跌额和成交笔数，涨跌由 processor 按开盘价计算
 		Timestamp: timestamp,
 	}
 
@@ -406,7 +470,7 @@ func (o *OKXAdapter) keepAlive() {
 				// 检查心跳超时
 				if time.Since(o.lastPong) > 60*time.Second {
 					log.Println("[OKX] Pong timeout, reconnecting...")
-					o.handleReconnect()
+					o.handleReconnect(fmt.Errorf("pong timeout"))
 					return
 				}
 
@@ -422,66 +486,104 @@ func (o *OKXAdapter) keepAlive() {
 	}
 }
 
-// handleReconnect 处理重连（指数退避）
-func (o *OKXAdapter) handleReconnect() {
-	retries := 0
-	delay := o.reconnectConf.InitialDelay
-
-	for retries < o.reconnectConf.MaxRetries {
-		log.Printf("[OKX] Attempting to reconnect (attempt %d/%d)...\n", retries+1, o.reconnectConf.MaxRetries)
-		time.Sleep(delay)
+// handleReconnect 处理重连（按错误类别的重试策略退避）
+func (o *OKXAdapter) handleReconnect(cause error) {
+	o.reconnecting.Add(1)
+	defer o.reconnecting.Add(-1)
 
-		if err := o.Connect(); err == nil {
-			log.Println("[OKX] Reconnected successfully")
-			// 重新订阅
-			o.resubscribe()
-			return
+	reconnectWithPolicy("OKX", cause, &o.errTracker, func() error {
+		if err := o.Connect(); err != nil {
+			return err
 		}
+		// 重新订阅
+		o.resubscribe()
+		return nil
+	})
+}
 
-		retries++
-		// 指数退避
-		delay = time.Duration(float64(delay) * o.reconnectConf.Multiplier)
-		if delay > o.reconnectConf.MaxDelay {
-			delay = o.reconnectConf.MaxDelay
+// handleError 处理交易所返回的错误消息并分类
+// 错误码参考: https://www.okx.com/docs-v5/en/#error-code-websocket-public
+func (o *OKXAdapter) handleError(rawMsg map[string]interface{}) {
+	code, _ := rawMsg["code"].(string)
+	msg, _ := rawMsg["msg"].(string)
+
+	adapterErr := &AdapterError{
+		Exchange: constants.ExchangeOKX,
+		Class:    ErrorClassTransient,
+		Code:     code,
+		Err:      fmt.Errorf("%s", msg),
+	}
+
+	switch code {
+	case "60014", "60026": // 请求过于频繁
+		adapterErr.Class = ErrorClassRateLimited
+	case "60004", "60005", "60006", "60007", "60009", "60024": // 登录/签名失败
+		adapterErr.Class = ErrorClassAuth
+	case "60018": // 频道或交易对不存在
+		adapterErr.Class = ErrorClassInvalidSymbol
+	}
+
+	// 无效订阅从订阅列表中移除，避免重连后重复订阅
+	if adapterErr.Class == ErrorClassInvalidSymbol {
+		if instId := parseErrorInstID(rawMsg, msg); instId != "" {
+			adapterErr.Symbols = []string{instId}
+			o.removeSubscriptions(instId)
 		}
 	}
 
-	log.Printf("[OKX] Max retries (%d) reached, giving up\n", o.reconnectConf.MaxRetries)
+	o.errTracker.record(adapterErr)
 }
 
-// resubscribe 重新订阅
-func (o *OKXAdapter) resubscribe() error {
-	if len(o.subscriptions) == 0 {
-		return nil
+// parseErrorInstID 从错误消息中解析出错的 instId
+// 格式: Wrong URL or channel:tickers,instId:BTC-XXX doesn't exist.
+func parseErrorInstID(rawMsg map[string]interface{}, msg string) string {
+	if arg, ok := rawMsg["arg"].(map[string]interface{}); ok {
+		if instId, ok := arg["instId"].(string); ok {
+			return instId
+		}
 	}
 
-	// 构建订阅参数
-	args := make([]map[string]string, 0, len(o.subscriptions))
-	for _, sub := range o.subscriptions {
-		parts := strings.Split(sub, ":")
-		if len(parts) == 2 {
-			args = append(args, map[string]string{
-				"channel": parts[0],
-				"instId":  parts[1],
-			})
-		}
+	idx := strings.Index(msg, "instId:")
+	if idx < 0 {
+		return ""
 	}
+	instId := msg[idx+len("instId:"):]
+	if end := strings.IndexAny(instId, " ,"); end >= 0 {
+		instId = instId[:end]
+	}
+	return strings.TrimSuffix(instId, ".")
+}
 
-	subMsg := map[string]interface{}{
-		"op":   "subscribe",
-		"args": args,
+// removeSubscriptions 从订阅集合中移除交易对的全部订阅
+func (o *OKXAdapter) removeSubscriptions(instId string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	removed := make([]string, 0)
+	for sub := range o.subscriptions.desired {
+		if strings.HasSuffix(sub, ":"+instId) {
+			removed = append(removed, sub)
+		}
 	}
+	o.subscriptions.remove(removed)
+	log.Printf("[OKX] Removed %d invalid subscriptions for %s\n", len(removed), instId)
+}
 
+// ErrorStats 获取各类错误次数
+func (o *OKXAdapter) ErrorStats() map[ErrorClass]int64 {
+	return o.errTracker.stats()
+}
+
+// resubscribe 重连后重新订阅（新连接上没有已生效的订阅，同步全部期望订阅）
+func (o *OKXAdapter) resubscribe() error {
 	o.mu.Lock()
-	err := o.conn.WriteJSON(subMsg)
+	o.subscriptions.reset()
 	o.mu.Unlock()
 
-	if err != nil {
+	if err := o.syncSubscriptions(); err != nil {
 		log.Printf("[OKX] Resubscribe failed: %v\n", err)
 		return err
 	}
-
-	log.Printf("[OKX] Resubscribed to %d channels\n", len(args))
 	return nil
 }
 
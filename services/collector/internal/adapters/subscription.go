@@ -0,0 +1,77 @@
+package adapters
+
+import "sort"
+
+// subscriptionSet 订阅集合
+// desired 为期望订阅的规范集合（由配置决定），active 为当前连接上已生效的订阅。
+// 订阅变更和重连时只对两者的差集发送订阅/取消订阅请求，避免重复订阅。
+// 非并发安全，由适配器在持有锁时调用。
+type subscriptionSet struct {
+	desired map[string]bool
+	active  map[string]bool
+}
+
+// newSubscriptionSet 创建订阅集合
+func newSubscriptionSet() subscriptionSet {
+	return subscriptionSet{
+		desired: make(map[string]bool),
+		active:  make(map[string]bool),
+	}
+}
+
+// add 添加期望订阅
+func (s *subscriptionSet) add(keys []string) {
+	for _, key := range keys {
+		s.desired[key] = true
+	}
+}
+
+// replace 替换期望订阅集合
+func (s *subscriptionSet) replace(keys []string) {
+	s.desired = make(map[string]bool, len(keys))
+	s.add(keys)
+}
+
+// remove 移除订阅（期望集合和已生效集合均移除，不发送取消订阅请求）
+func (s *subscriptionSet) remove(keys []string) {
+	for _, key := range keys {
+		delete(s.desired, key)
+		delete(s.active, key)
+	}
+}
+
+// diff 计算需要新增和取消的订阅，结果按字典序排列
+func (s *subscriptionSet) diff() (toAdd, toRemove []string) {
+	for key := range s.desired {
+		if !s.active[key] {
+			toAdd = append(toAdd, key)
+		}
+	}
+	for key := range s.active {
+		if !s.desired[key] {
+			toRemove = append(toRemove, key)
+		}
+	}
+	sort.Strings(toAdd)
+	sort.Strings(toRemove)
+	return toAdd, toRemove
+}
+
+// markActive 标记订阅已生效
+func (s *subscriptionSet) markActive(keys []string) {
+	for _, key := range keys {
+		s.active[key] = true
+	}
+}
+
+// markInactive 标记订阅已取消
+func (s *subscriptionSet) markInactive(keys []string) {
+	for _, key := range keys {
+		delete(s.active, key)
+	}
+}
+
+// reset 连接重建后清空已生效集合
+func (s *subscriptionSet) reset() {
+	s.active = make(map[string]bool)
+}
@@ -0,0 +1,117 @@
+package merger
+
+import (
+	"market-system/common/models"
+	"strconv"
+	"strings"
+)
+
+// tradeDeduplicator 跨数据源成交去重
+// 混合模式下同一笔成交可能同时来自内部撮合引擎和外部镜像，按 (交易对, 价格, 数量) 分组，
+// 时间窗口内来自不同数据源的相同成交视为重复，只保留先到达的一笔，避免成交量重复统计。
+// 同一数据源内的相同成交视为不同的成交，不去重。
+// 非并发安全，由 DataMerger 在持有锁时调用。
+type tradeDeduplicator struct {
+	window      int64                  // 去重时间窗口（毫秒）
+	seen        map[string][]seenTrade // key: symbol|price|amount
+	lastCleanup int64                  // 上次清理时间（成交时间）
+	duplicates  map[string]int64       // 按交易对统计的重复成交数
+}
+
+// seenTrade 已处理的成交
+type seenTrade struct {
+	source    string
+	timestamp int64
+}
+
+// newTradeDeduplicator 创建成交去重器
+func newTradeDeduplicator(window int64) *tradeDeduplicator {
+	return &tradeDeduplicator{
+		window:     window,
+		seen:       make(map[string][]seenTrade),
+		duplicates: make(map[string]int64),
+	}
+}
+
+// isDuplicate 判断成交是否已由其他数据源上报过，未重复时记录该成交
+func (d *tradeDeduplicator) isDuplicate(trade *models.Trade, source string) bool {
+	key := tradeKey(trade)
+	entries := d.seen[key]
+
+	// 查找窗口内来自其他数据源的相同成交，匹配后移除，保证一笔成交只抵消一次
+	kept := entries[:0]
+	duplicate := false
+	for _, entry := range entries {
+		diff := trade.Timestamp - entry.timestamp
+		if diff > d.window {
+			continue // 已过期
+		}
+		if !duplicate && entry.source != source && abs64(diff) <= d.window {
+			duplicate = true
+			continue
+		}
+		kept = append(kept, entry)
+	}
+
+	if duplicate {
+		d.duplicates[trade.Symbol]++
+	} else {
+		kept = append(kept, seenTrade{source: source, timestamp: trade.Timestamp})
+	}
+
+	if len(kept) == 0 {
+		delete(d.seen, key)
+	} else {
+		d.seen[key] = kept
+	}
+
+	d.cleanup(trade.Timestamp)
+	return duplicate
+}
+
+// cleanup 定期清理过期的成交记录
+func (d *tradeDeduplicator) cleanup(now int64) {
+	if now-d.lastCleanup < 10*d.window {
+		return
+	}
+	d.lastCleanup = now
+
+	for key, entries := range d.seen {
+		kept := entries[:0]
+		for _, entry := range entries {
+			if now-entry.timestamp <= d.window {
+				kept = append(kept, entry)
+			}
+		}
+		if len(kept) == 0 {
+			delete(d.seen, key)
+		} else {
+			d.seen[key] = kept
+		}
+	}
+}
+
+// remove 删除交易对的去重记录
+func (d *tradeDeduplicator) remove(symbol string) {
+	prefix := symbol + "|"
+	for key := range d.seen {
+		if strings.HasPrefix(key, prefix) {
+			delete(d.seen, key)
+		}
+	}
+	delete(d.duplicates, symbol)
+}
+
+// tradeKey 成交去重 key，价格和数量按字符串精确匹配
+func tradeKey(trade *models.Trade) string {
+	return trade.Symbol + "|" +
+		strconv.FormatFloat(trade.Price, 'f', -1, 64) + "|" +
+		strconv.FormatFloat(trade.Amount, 'f', -1, 64)
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,155 @@
+package merger
+
+import (
+	"market-system/common/constants"
+	"market-system/common/models"
+	"strconv"
+	"testing"
+)
+
+func TestTradeDeduplicator(t *testing.T) {
+	type step struct {
+		symbol    string
+		price     float64
+		amount    float64
+		timestamp int64
+		source    string
+		duplicate bool
+	}
+	internal, external := constants.SourceInternal, constants.SourceExternal
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "other source within window",
+			steps: []step{
+				{"BTCUSDT", 100, 1, 1000, internal, false},
+				{"BTCUSDT", 100, 1, 1100, external, true},
+			},
+		},
+		{
+			// 先到达的成交时间可能晚于后到达的成交
+			name: "earlier timestamp within window",
+			steps: []step{
+				{"BTCUSDT", 100, 1, 1000, external, false},
+				{"BTCUSDT", 100, 1, 900, internal, true},
+			},
+		},
+		{
+			name: "other source outside window",
+			steps: []step{
+				{"BTCUSDT", 100, 1, 1000, internal, false},
+				{"BTCUSDT", 100, 1, 1101, external, false},
+			},
+		},
+		{
+			name: "same source",
+			steps: []step{
+				{"BTCUSDT", 100, 1, 1000, internal, false},
+				{"BTCUSDT", 100, 1, 1000, internal, false},
+			},
+		},
+		{
+			// symbol|price|amount 任一不同都不是同一笔成交
+			name: "different key",
+			steps: []step{
+				{"BTCUSDT", 100, 1, 1000, internal, false},
+				{"ETHUSDT", 100, 1, 1000, external, false},
+				{"BTCUSDT", 100.5, 1, 1000, external, false},
+				{"BTCUSDT", 100, 1.5, 1000, external, false},
+			},
+		},
+		{
+			// 一笔成交只抵消另一数据源的一笔
+			name: "matched once",
+			steps: []step{
+				{"BTCUSDT", 100, 1, 1000, internal, false},
+				{"BTCUSDT", 100, 1, 1010, external, true},
+				{"BTCUSDT", 100, 1, 1020, external, false},
+				{"BTCUSDT", 100, 1, 1030, internal, true},
+			},
+		},
+		{
+			// 价格和数量精确匹配，不做容差比较
+			name: "exact price",
+			steps: []step{
+				{"BTCUSDT", 0.3, 2, 1000, internal, false},
+				{"BTCUSDT", 0.30000000000000004, 2, 1000, external, false},
+				{"BTCUSDT", 0.3, 2, 1000, external, true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTradeDeduplicator(100)
+			var duplicates int64
+			for i, s := range tt.steps {
+				trade := &models.Trade{Symbol: s.symbol, Price: s.price, Amount: s.amount, Timestamp: s.timestamp}
+				if got := d.isDuplicate(trade, s.source); got != s.duplicate {
+					t.Errorf("step %d: duplicate = %v, want %v", i, got, s.duplicate)
+				}
+				if s.duplicate {
+					duplicates++
+				}
+			}
+			var counted int64
+			for _, n := range d.duplicates {
+				counted += n
+			}
+			if counted != duplicates {
+				t.Errorf("duplicates = %d, want %d", counted, duplicates)
+			}
+		})
+	}
+}
+
+func TestTradeDeduplicatorFormatting(t *testing.T) {
+	parse := func(s string) float64 {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	// 各数据源上报的价格和数量格式不同（末尾补零、科学计数法），解析后的数值相同即为同一笔成交
+	tests := []struct {
+		internalPrice, internalAmount string
+		externalPrice, externalAmount string
+		duplicate                     bool
+	}{
+		{"0.10", "1", "0.1", "1.000", true},
+		{"43250.50", "0.0100", "43250.5", "0.01", true},
+		{"100", "2", "100.00000000", "2.0", true},
+		{"1e-5", "3", "0.00001", "3", true},
+		{"0.10", "1", "0.11", "1", false},
+		{"0.10", "1", "0.1", "1.01", false},
+	}
+	for _, tt := range tests {
+		d := newTradeDeduplicator(100)
+		d.isDuplicate(&models.Trade{Symbol: "BTCUSDT", Price: parse(tt.internalPrice), Amount: parse(tt.internalAmount), Timestamp: 1000}, constants.SourceInternal)
+		got := d.isDuplicate(&models.Trade{Symbol: "BTCUSDT", Price: parse(tt.externalPrice), Amount: parse(tt.externalAmount), Timestamp: 1010}, constants.SourceExternal)
+		if got != tt.duplicate {
+			t.Errorf("%s@%s vs %s@%s: duplicate = %v, want %v",
+				tt.internalAmount, tt.internalPrice, tt.externalAmount, tt.externalPrice, got, tt.duplicate)
+		}
+	}
+}
+
+func TestTradeDeduplicatorRemove(t *testing.T) {
+	d := newTradeDeduplicator(100)
+	d.isDuplicate(&models.Trade{Symbol: "BTCUSDT", Price: 100, Amount: 1, Timestamp: 1000}, constants.SourceInternal)
+	d.isDuplicate(&models.Trade{Symbol: "BTCUSDTX", Price: 100, Amount: 1, Timestamp: 1000}, constants.SourceInternal)
+
+	// 删除交易对后不再与之前的成交匹配，前缀相同的交易对不受影响
+	d.remove("BTCUSDT")
+	if d.isDuplicate(&models.Trade{Symbol: "BTCUSDT", Price: 100, Amount: 1, Timestamp: 1010}, constants.SourceExternal) {
+		t.Error("removed symbol still deduplicated")
+	}
+	if !d.isDuplicate(&models.Trade{Symbol: "BTCUSDTX", Price: 100, Amount: 1, Timestamp: 1010}, constants.SourceExternal) {
+		t.Error("other symbol not deduplicated")
+	}
+}
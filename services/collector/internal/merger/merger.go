@@ -2,8 +2,11 @@ package merger
 
 import (
 	"log"
+	"market-system/common/config"
 	"market-system/common/constants"
 	"market-system/common/models"
+	"market-system/common/utils"
+	"math"
 	"sort"
 	"sync"
 	"time"
@@ -12,8 +15,12 @@ import (
 // DataMerger 数据融合器
 type DataMerger struct {
 	symbolConfigs map[string]*models.SymbolConfig // 交易对配置
+	fileConfigs   map[string]*models.SymbolConfig // 配置文件中的交易对配置，运行时配置删除后恢复
 	internalData  map[string]*CachedData           // 内部数据缓存
 	externalData  map[string]*CachedData           // 外部数据缓存
+	sourcePrices  map[string]map[string]*sourcePrice // 各数据源最新价，key: 交易对 -> 交易所
+	defaults      config.HybridModeConfig         // 全局默认配置（交易对未单独配置时使用）
+	tradeDedup    *tradeDeduplicator              // 跨数据源成交去重
 	mu            sync.RWMutex
 }
 
@@ -26,16 +33,26 @@ type CachedData struct {
 }
 
 // NewDataMerger 创建数据融合器
-func NewDataMerger(configs []*models.SymbolConfig) *DataMerger {
+func NewDataMerger(configs []*models.SymbolConfig, defaults config.HybridModeConfig) *DataMerger {
 	merger := &DataMerger{
 		symbolConfigs: make(map[string]*models.SymbolConfig),
+		fileConfigs:   make(map[string]*models.SymbolConfig),
 		internalData:  make(map[string]*CachedData),
 		externalData:  make(map[string]*CachedData),
+		sourcePrices:  make(map[string]map[string]*sourcePrice),
+		defaults:      defaults,
 	}
 
+	dedupWindow := defaults.TradeDedupWindowMs
+	if dedupWindow <= 0 {
+		dedupWindow = constants.TradeDedupWindow
+	}
+	merger.tradeDedup = newTradeDeduplicator(dedupWindow)
+
 	// 加载配置
-	for _, config := range configs {
-		merger.symbolConfigs[config.Symbol] = config
+	for _, cfg := range configs {
+		merger.symbolConfigs[cfg.Symbol] = cfg
+		merger.fileConfigs[cfg.Symbol] = cfg
 	}
 
 	log.Printf("[Merger] Initialized with %d symbol configs\n", len(configs))
@@ -91,7 +108,10 @@ func (m *DataMerger) mergeData(data *models.MarketData, config *models.SymbolCon
 	case constants.DataTypeDepth:
 		return m.mergeDepth(data.Symbol, config)
 	case constants.DataTypeTrade:
-		// Trade 数据不融合，直接返回
+		// Trade 数据不融合，跨数据源重复的成交丢弃
+		if trade, ok := data.Data.(*models.Trade); ok && m.tradeDedup.isDuplicate(trade, data.Source) {
+			return nil
+		}
 		return data
 	default:
 		return data
@@ -122,6 +142,7 @@ func (m *DataMerger) cacheData(data *models.MarketData) {
 	case constants.DataTypeTicker:
 		if ticker, ok := data.Data.(*models.Ticker); ok {
 			cache.Ticker = ticker
+			m.cacheSourcePrice(data, ticker)
 		}
 	case constants.DataTypeDepth:
 		if depth, ok := data.Data.(*models.OrderBook); ok {
@@ -149,21 +170,35 @@ func (m *DataMerger) mergeTicker(symbol string, config *models.SymbolConfig) *mo
 	switch config.MergeStrategy {
 	case constants.MergeStrategyPriority:
 		// 优先级策略：内部数据优先
-		mergedTicker = m.mergeTickerPriority(internalCache, externalCache, symbol)
+		mergedTicker = m.mergeTickerPriority(internalCache, externalCache, config)
 
 	case constants.MergeStrategySupplement:
 		// 补充策略：内部数据为主，外部数据补充
-		mergedTicker = m.mergeTickerSupplement(internalCache, externalCache, symbol)
+		mergedTicker = m.mergeTickerSupplement(internalCache, externalCache, config)
+
+	case constants.MergeStrategyVWAP:
+		// 成交量加权策略：按各数据源成交量及权重加权最新价
+		mergedTicker = m.mergeTickerVWAP(internalCache, externalCache, config)
+
+	case constants.MergeStrategyMedian, constants.MergeStrategyTrimmedMean:
+		// 稳健策略：各数据源最新价取中位数或截尾均值
+		mergedTicker = m.mergeTickerRobust(symbol, internalCache, externalCache, config)
 
 	default:
 		// 默认使用优先级策略
-		mergedTicker = m.mergeTickerPriority(internalCache, externalCache, symbol)
+		mergedTicker = m.mergeTickerPriority(internalCache, externalCache, config)
 	}
 
 	if mergedTicker == nil {
 		return nil
 	}
 
+	// 涨跌额和涨跌幅按融合后的最新价计算
+	if mergedTicker.Open24h > 0 {
+		mergedTicker.PriceChange24h = mergedTicker.LastPrice - mergedTicker.Open24h
+		mergedTicker.PriceChangePercent24h = utils.CalculateChange24h(mergedTicker.LastPrice, mergedTicker.Open24h)
+	}
+
 	return &models.MarketData{
 		Exchange:  "merged",
 		Symbol:    symbol,
@@ -175,22 +210,23 @@ func (m *DataMerger) mergeTicker(symbol string, config *models.SymbolConfig) *mo
 }
 
 // mergeTickerPriority 优先级融合 Ticker
-func (m *DataMerger) mergeTickerPriority(internal, external *CachedData, symbol string) *models.TickerWithSource {
+func (m *DataMerger) mergeTickerPriority(internal, external *CachedData, config *models.SymbolConfig) *models.TickerWithSource {
 	ticker := &models.TickerWithSource{
-		Symbol: symbol,
+		Symbol: config.Symbol,
 	}
 
 	// 优先使用内部数据
-	if internal != nil && internal.Ticker != nil && m.isDataFresh(internal.Timestamp) {
+	if internal != nil && internal.Ticker != nil && m.isDataFresh(internal.Timestamp, config) {
 		ticker.LastPrice = internal.Ticker.LastPrice
 		ticker.LastPriceSource = constants.SourceInternal
 		ticker.BidPrice = internal.Ticker.BidPrice
 		ticker.AskPrice = internal.Ticker.AskPrice
 		ticker.High24h = internal.Ticker.High24h
 		ticker.Low24h = internal.Ticker.Low24h
+		ticker.Open24h = internal.Ticker.Open24h
 		ticker.InternalVolume24h = internal.Ticker.Volume24h
 		ticker.Timestamp = internal.Ticker.Timestamp
-	} else if external != nil && external.Ticker != nil && m.isDataFresh(external.Timestamp) {
+	} else if external != nil && external.Ticker != nil && m.isDataFresh(external.Timestamp, config) {
 		// 没有内部数据或内部数据过期，使用外部数据
 		ticker.LastPrice = external.Ticker.LastPrice
 		ticker.LastPriceSource = constants.SourceExternal
@@ -198,18 +234,21 @@ func (m *DataMerger) mergeTickerPriority(internal, external *CachedData, symbol
 		ticker.AskPrice = external.Ticker.AskPrice
 		ticker.High24h = external.Ticker.High24h
 		ticker.Low24h = external.Ticker.Low24h
+		ticker.Open24h = external.Ticker.Open24h
 		ticker.ExternalVolume24h = external.Ticker.Volume24h
 		ticker.Timestamp = external.Ticker.Timestamp
 	} else {
 		return nil
 	}
 
-	// 合并成交量
+	// 合并成交量和成交笔数
 	if internal != nil && internal.Ticker != nil {
 		ticker.InternalVolume24h = internal.Ticker.Volume24h
+		ticker.TradeCount24h += internal.Ticker.TradeCount24h
 	}
 	if external != nil && external.Ticker != nil {
 		ticker.ExternalVolume24h = external.Ticker.Volume24h
+		ticker.TradeCount24h += external.Ticker.TradeCount24h
 	}
 	ticker.TotalVolume24h = ticker.InternalVolume24h + ticker.ExternalVolume24h
 
@@ -217,9 +256,63 @@ func (m *DataMerger) mergeTickerPriority(internal, external *CachedData, symbol
 }
 
 // mergeTickerSupplement 补充融合 Ticker
-func (m *DataMerger) mergeTickerSupplement(internal, external *CachedData, symbol string) *models.TickerWithSource {
+func (m *DataMerger) mergeTickerSupplement(internal, external *CachedData, config *models.SymbolConfig) *models.TickerWithSource {
 	// 与优先级策略类似，但更强调外部数据作为补充
-	return m.mergeTickerPriority(internal, external, symbol)
+	return m.mergeTickerPriority(internal, external, config)
+}
+
+// mergeTickerVWAP 成交量加权融合 Ticker
+// 最新价 = Σ(价格 × 成交量 × 权重) / Σ(成交量 × 权重)，成交量低于阈值的数据源不参与加权；
+// 没有数据源满足条件时退化为优先级策略
+func (m *DataMerger) mergeTickerVWAP(internal, external *CachedData, config *models.SymbolConfig) *models.TickerWithSource {
+	ticker := m.mergeTickerPriority(internal, external, config)
+	if ticker == nil {
+		return nil
+	}
+
+	vwap := config.VWAP
+	var weightedPrice, totalWeight float64
+	sources := make([]string, 0, 2)
+
+	addSource := func(cache *CachedData, source string, weight, minVolume float64) {
+		if cache == nil || cache.Ticker == nil || !m.isDataFresh(cache.Timestamp, config) {
+			return
+		}
+		if weight == 0 {
+			weight = 1
+		}
+		t := cache.Ticker
+		if weight < 0 || t.LastPrice <= 0 || t.Volume24h <= 0 || t.Volume24h < minVolume {
+			return
+		}
+
+		w := t.Volume24h * weight
+		weightedPrice += t.LastPrice * w
+		totalWeight += w
+		sources = append(sources, source)
+
+		// 最高/最低价取所有参与数据源的极值
+		ticker.High24h = utils.MaxFloat(ticker.High24h, t.High24h)
+		if t.Low24h > 0 && (ticker.Low24h == 0 || t.Low24h < ticker.Low24h) {
+			ticker.Low24h = t.Low24h
+		}
+	}
+
+	addSource(internal, constants.SourceInternal, vwap.InternalWeight, vwap.MinInternalVolume)
+	addSource(external, constants.SourceExternal, vwap.ExternalWeight, vwap.MinExternalVolume)
+
+	if totalWeight == 0 {
+		return ticker
+	}
+
+	ticker.LastPrice = weightedPrice / totalWeight
+	if len(sources) == 1 {
+		ticker.LastPriceSource = sources[0]
+	} else {
+		ticker.LastPriceSource = constants.SourceMerged
+	}
+
+	return ticker
 }
 
 // mergeDepth 融合深度数据
@@ -231,13 +324,13 @@ func (m *DataMerger) mergeDepth(symbol string, config *models.SymbolConfig) *mod
 
 	switch config.MergeStrategy {
 	case constants.MergeStrategyPriority:
-		mergedDepth = m.mergeDepthPriority(internalCache, externalCache, symbol)
+		mergedDepth = m.mergeDepthPriority(internalCache, externalCache, config)
 
 	case constants.MergeStrategySupplement:
-		mergedDepth = m.mergeDepthSupplement(internalCache, externalCache, symbol)
+		mergedDepth = m.mergeDepthSupplement(internalCache, externalCache, config)
 
 	default:
-		mergedDepth = m.mergeDepthPriority(internalCache, externalCache, symbol)
+		mergedDepth = m.mergeDepthPriority(internalCache, externalCache, config)
 	}
 
 	if mergedDepth == nil {
@@ -255,15 +348,15 @@ func (m *DataMerger) mergeDepth(symbol string, config *models.SymbolConfig) *mod
 }
 
 // mergeDepthPriority 优先级融合深度
-func (m *DataMerger) mergeDepthPriority(internal, external *CachedData, symbol string) *models.OrderBookWithSource {
+func (m *DataMerger) mergeDepthPriority(internal, external *CachedData, config *models.SymbolConfig) *models.OrderBookWithSource {
 	depth := &models.OrderBookWithSource{
-		Symbol: symbol,
+		Symbol: config.Symbol,
 		Bids:   make([]models.PriceLevelWithSource, 0),
 		Asks:   make([]models.PriceLevelWithSource, 0),
 	}
 
 	// 添加内部深度
-	if internal != nil && internal.Depth != nil && m.isDataFresh(internal.Timestamp) {
+	if internal != nil && internal.Depth != nil && m.isDataFresh(internal.Timestamp, config) {
 		for _, bid := range internal.Depth.Bids {
 			depth.Bids = append(depth.Bids, models.PriceLevelWithSource{
 				Price:  bid.Price,
@@ -283,7 +376,7 @@ func (m *DataMerger) mergeDepthPriority(internal, external *CachedData, symbol s
 	}
 
 	// 添加外部深度
-	if external != nil && external.Depth != nil && m.isDataFresh(external.Timestamp) {
+	if external != nil && external.Depth != nil && m.isDataFresh(external.Timestamp, config) {
 		for _, bid := range external.Depth.Bids {
 			depth.Bids = append(depth.Bids, models.PriceLevelWithSource{
 				Price:  bid.Price,
@@ -302,37 +395,28 @@ func (m *DataMerger) mergeDepthPriority(internal, external *CachedData, symbol s
 		depth.ExternalAsksCount = len(external.Depth.Asks)
 	}
 
-	// 排序合并
-	sort.Slice(depth.Bids, func(i, j int) bool {
-		return depth.Bids[i].Price > depth.Bids[j].Price // 买盘价格从高到低
-	})
-	sort.Slice(depth.Asks, func(i, j int) bool {
-		return depth.Asks[i].Price < depth.Asks[j].Price // 卖盘价格从低到高
-	})
+	// 同价位合并后排序
+	depth.Bids = aggregatePriceLevels(depth.Bids, config.TickSize, true)
+	depth.Asks = aggregatePriceLevels(depth.Asks, config.TickSize, false)
 
 	// 限制档位数量
-	if len(depth.Bids) > 100 {
-		depth.Bids = depth.Bids[:100]
-	}
-	if len(depth.Asks) > 100 {
-		depth.Asks = depth.Asks[:100]
-	}
+	m.capDepth(depth, config)
 
 	depth.Timestamp = time.Now().UnixMilli()
 	return depth
 }
 
 // mergeDepthSupplement 补充融合深度
-func (m *DataMerger) mergeDepthSupplement(internal, external *CachedData, symbol string) *models.OrderBookWithSource {
+func (m *DataMerger) mergeDepthSupplement(internal, external *CachedData, config *models.SymbolConfig) *models.OrderBookWithSource {
 	// 如果内部深度档位不足，用外部深度补充
 	depth := &models.OrderBookWithSource{
-		Symbol: symbol,
+		Symbol: config.Symbol,
 		Bids:   make([]models.PriceLevelWithSource, 0),
 		Asks:   make([]models.PriceLevelWithSource, 0),
 	}
 
 	// 优先添加内部深度
-	if internal != nil && internal.Depth != nil && m.isDataFresh(internal.Timestamp) {
+	if internal != nil && internal.Depth != nil && m.isDataFresh(internal.Timestamp, config) {
 		for _, bid := range internal.Depth.Bids {
 			depth.Bids = append(depth.Bids, models.PriceLevelWithSource{
 				Price:  bid.Price,
@@ -352,7 +436,7 @@ func (m *DataMerger) mergeDepthSupplement(internal, external *CachedData, symbol
 	}
 
 	// 如果档位不足 20 档，用外部数据补充
-	if len(depth.Bids) < 20 && external != nil && external.Depth != nil && m.isDataFresh(external.Timestamp) {
+	if len(depth.Bids) < 20 && external != nil && external.Depth != nil && m.isDataFresh(external.Timestamp, config) {
 		for _, bid := range external.Depth.Bids {
 			depth.Bids = append(depth.Bids, models.PriceLevelWithSource{
 				Price:  bid.Price,
@@ -363,7 +447,7 @@ func (m *DataMerger) mergeDepthSupplement(internal, external *CachedData, symbol
 		depth.ExternalBidsCount = len(external.Depth.Bids)
 	}
 
-	if len(depth.Asks) < 20 && external != nil && external.Depth != nil && m.isDataFresh(external.Timestamp) {
+	if len(depth.Asks) < 20 && external != nil && external.Depth != nil && m.isDataFresh(external.Timestamp, config) {
 		for _, ask := range external.Depth.Asks {
 			depth.Asks = append(depth.Asks, models.PriceLevelWithSource{
 				Price:  ask.Price,
@@ -374,22 +458,126 @@ func (m *DataMerger) mergeDepthSupplement(internal, external *CachedData, symbol
 		depth.ExternalAsksCount = len(external.Depth.Asks)
 	}
 
-	// 排序
-	sort.Slice(depth.Bids, func(i, j int) bool {
-		return depth.Bids[i].Price > depth.Bids[j].Price
-	})
-	sort.Slice(depth.Asks, func(i, j int) bool {
-		return depth.Asks[i].Price < depth.Asks[j].Price
-	})
+	// 同价位合并后排序
+	depth.Bids = aggregatePriceLevels(depth.Bids, config.TickSize, true)
+	depth.Asks = aggregatePriceLevels(depth.Asks, config.TickSize, false)
+
+	// 限制档位数量
+	m.capDepth(depth, config)
 
 	depth.Timestamp = time.Now().UnixMilli()
 	return depth
 }
 
+// aggregatePriceLevels 合并相同价位（或按 tickSize 对齐后相同价位）的档位
+// 买盘向下对齐、卖盘向上对齐，避免对齐后买卖盘交叉；结果按盘口方向排序
+func aggregatePriceLevels(levels []models.PriceLevelWithSource, tickSize float64, isBid bool) []models.PriceLevelWithSource {
+	merged := make(map[float64]*models.PriceLevelWithSource, len(levels))
+	for _, level := range levels {
+		price := alignPrice(level.Price, tickSize, isBid)
+
+		agg, ok := merged[price]
+		if !ok {
+			agg = &models.PriceLevelWithSource{Price: price}
+			merged[price] = agg
+		}
+
+		agg.Amount += level.Amount
+		switch level.Source {
+		case constants.SourceInternal:
+			agg.InternalAmount += level.Amount
+		case constants.SourceExternal:
+			agg.ExternalAmount += level.Amount
+		}
+	}
+
+	result := make([]models.PriceLevelWithSource, 0, len(merged))
+	for _, agg := range merged {
+		switch {
+		case agg.InternalAmount > 0 && agg.ExternalAmount > 0:
+			agg.Source = constants.SourceMerged
+		case agg.InternalAmount > 0:
+			agg.Source = constants.SourceInternal
+		default:
+			agg.Source = constants.SourceExternal
+		}
+		result = append(result, *agg)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if isBid {
+			return result[i].Price > result[j].Price // 买盘价格从高到低
+		}
+		return result[i].Price < result[j].Price // 卖盘价格从低到高
+	})
+
+	return result
+}
+
+// alignPrice 将价格对齐到 tickSize
+func alignPrice(price, tickSize float64, isBid bool) float64 {
+	if tickSize <= 0 {
+		return price
+	}
+
+	// 先按 tick 数量取整，再消除浮点误差，避免 0.1+0.2 之类的价格落入不同档位
+	ticks := utils.RoundFloat(price/tickSize, 8)
+	if isBid {
+		ticks = math.Floor(ticks)
+	} else {
+		ticks = math.Ceil(ticks)
+	}
+	return utils.RoundFloat(ticks*tickSize, 10)
+}
+
+// capDepth 按配置限制深度档位数量
+func (m *DataMerger) capDepth(depth *models.OrderBookWithSource, config *models.SymbolConfig) {
+	maxLevels := m.maxDepthLevels(config)
+	if len(depth.Bids) > maxLevels {
+		depth.Bids = depth.Bids[:maxLevels]
+	}
+	if len(depth.Asks) > maxLevels {
+		depth.Asks = depth.Asks[:maxLevels]
+	}
+}
+
 // isDataFresh 检查数据是否新鲜
-func (m *DataMerger) isDataFresh(timestamp int64) bool {
+func (m *DataMerger) isDataFresh(timestamp int64, config *models.SymbolConfig) bool {
 	now := time.Now().UnixMilli()
-	return (now - timestamp) < constants.DataFreshnessThreshold
+	return (now - timestamp) < m.freshnessThreshold(config)
+}
+
+// freshnessThreshold 获取数据新鲜度阈值（毫秒）：交易对配置 > 混合模式默认配置 > 常量
+func (m *DataMerger) freshnessThreshold(config *models.SymbolConfig) int64 {
+	if config != nil && config.FreshnessMs > 0 {
+		return config.FreshnessMs
+	}
+	if m.defaults.DataFreshnessThreshold > 0 {
+		return m.defaults.DataFreshnessThreshold
+	}
+	return constants.DataFreshnessThreshold
+}
+
+// maxDepthLevels 获取融合深度的最大档位数：交易对配置 > 混合模式默认配置 > 常量
+func (m *DataMerger) maxDepthLevels(config *models.SymbolConfig) int {
+	if config != nil && config.MaxDepthLevels > 0 {
+		return config.MaxDepthLevels
+	}
+	if m.defaults.MaxDepthLevels > 0 {
+		return m.defaults.MaxDepthLevels
+	}
+	return constants.MaxDepthLevel
+}
+
+// trimPercent 获取截尾均值两端剔除比例（%）：交易对配置 > 混合模式默认配置 > 常量
+func (m *DataMerger) trimPercent(config *models.SymbolConfig) float64 {
+	if config != nil && config.TrimPercent > 0 {
+		return config.TrimPercent
+	}
+	if m.defaults.TrimPercent > 0 {
+		return m.defaults.TrimPercent
+	}
+	return constants.DefaultTrimPercent
 }
 
 // GetSymbolConfig 获取交易对配置
@@ -406,3 +594,33 @@ func (m *DataMerger) UpdateSymbolConfig(config *models.SymbolConfig) {
 	m.symbolConfigs[config.Symbol] = config
 	log.Printf("[Merger] Updated config for symbol: %s, mode: %s\n", config.Symbol, config.Mode)
 }
+
+// RemoveSymbolConfig 删除交易对的运行时配置，与重启后的行为一致：
+// 配置文件中有该交易对时恢复为配置文件中的配置，否则删除，之后该交易对的数据不再融合，直接透传
+func (m *DataMerger) RemoveSymbolConfig(symbol string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cfg, ok := m.fileConfigs[symbol]; ok {
+		m.symbolConfigs[symbol] = cfg
+		log.Printf("[Merger] Reverted config for symbol: %s to file config, mode: %s\n", symbol, cfg.Mode)
+		return
+	}
+	delete(m.symbolConfigs, symbol)
+	delete(m.internalData, symbol)
+	delete(m.externalData, symbol)
+	delete(m.sourcePrices, symbol)
+	m.tradeDedup.remove(symbol)
+	log.Printf("[Merger] Removed config for symbol: %s\n", symbol)
+}
+
+// GetDedupStats 获取按交易对统计的跨数据源重复成交数
+func (m *DataMerger) GetDedupStats() map[string]int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := make(map[string]int64, len(m.tradeDedup.duplicates))
+	for symbol, count := range m.tradeDedup.duplicates {
+		stats[symbol] = count
+	}
+	return stats
+}
@@ -0,0 +1,91 @@
+package merger
+
+import (
+	"market-system/common/constants"
+	"market-system/common/models"
+	"math"
+	"testing"
+)
+
+func TestAlignPrice(t *testing.T) {
+	tests := []struct {
+		name     string
+		price    float64
+		tickSize float64
+		isBid    bool
+		want     float64
+	}{
+		// 买盘向下、卖盘向上对齐，不会把挂单价格改得更优
+		{"bid rounds down", 100.07, 0.1, true, 100.0},
+		{"ask rounds up", 100.07, 0.1, false, 100.1},
+		{"bid large tick", 12345, 10, true, 12340},
+		{"ask large tick", 12345, 10, false, 12350},
+		{"on tick", 100.1, 0.1, true, 100.1},
+		// 价格恰好在档位上，浮点除法的误差不能落入相邻档位
+		{"bid below boundary by float error", 0.3, 0.1, true, 0.3},        // 0.3/0.1 = 2.9999999999999996
+		{"ask above boundary by float error", 0.1 + 0.2, 0.1, false, 0.3}, // 0.30000000000000004
+		{"ask small tick", 0.07, 0.01, false, 0.07},                       // 7.000000000000001
+		{"bid odd tick", 1.15, 0.05, true, 1.15},                          // 22.999999999999996
+		{"no tick", 100.07, 0, true, 100.07},
+	}
+	for _, tt := range tests {
+		if got := alignPrice(tt.price, tt.tickSize, tt.isBid); got != tt.want {
+			t.Errorf("%s: alignPrice(%v, %v, %v) = %v, want %v", tt.name, tt.price, tt.tickSize, tt.isBid, got, tt.want)
+		}
+	}
+}
+
+func TestAggregatePriceLevels(t *testing.T) {
+	levels := []models.PriceLevelWithSource{
+		{Price: 100.07, Amount: 1, Source: constants.SourceInternal},
+		{Price: 100.02, Amount: 2, Source: constants.SourceExternal},
+		{Price: 100.15, Amount: 0.5, Source: constants.SourceExternal},
+		{Price: 99.95, Amount: 3, Source: constants.SourceInternal},
+		{Price: 0.1 + 99.9, Amount: 0.2, Source: constants.SourceInternal}, // 浮点误差不影响所在档位
+	}
+
+	tests := []struct {
+		name  string
+		isBid bool
+		want  []models.PriceLevelWithSource
+	}{
+		{
+			// 买盘价格从高到低，100.07 和 100.02 向下对齐到 100.0 后合并
+			name:  "bids",
+			isBid: true,
+			want: []models.PriceLevelWithSource{
+				{Price: 100.1, Amount: 0.5, Source: constants.SourceExternal, ExternalAmount: 0.5},
+				{Price: 100.0, Amount: 3.2, Source: constants.SourceMerged, InternalAmount: 1.2, ExternalAmount: 2},
+				{Price: 99.9, Amount: 3, Source: constants.SourceInternal, InternalAmount: 3},
+			},
+		},
+		{
+			// 卖盘价格从低到高，100.07 和 100.02 向上对齐到 100.1 后合并
+			name:  "asks",
+			isBid: false,
+			want: []models.PriceLevelWithSource{
+				{Price: 100.0, Amount: 3.2, Source: constants.SourceInternal, InternalAmount: 3.2},
+				{Price: 100.1, Amount: 3, Source: constants.SourceMerged, InternalAmount: 1, ExternalAmount: 2},
+				{Price: 100.2, Amount: 0.5, Source: constants.SourceExternal, ExternalAmount: 0.5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := aggregatePriceLevels(levels, 0.1, tt.isBid)
+			if len(got) != len(tt.want) {
+				t.Fatalf("levels = %+v", got)
+			}
+			for i, want := range tt.want {
+				level := got[i]
+				if level.Price != want.Price || level.Source != want.Source ||
+					math.Abs(level.Amount-want.Amount) > 1e-9 ||
+					math.Abs(level.InternalAmount-want.InternalAmount) > 1e-9 ||
+					math.Abs(level.ExternalAmount-want.ExternalAmount) > 1e-9 {
+					t.Errorf("level %d = %+v, want %+v", i, level, want)
+				}
+			}
+		})
+	}
+}
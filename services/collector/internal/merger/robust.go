@@ -0,0 +1,88 @@
+package merger
+
+import (
+	"market-system/common/constants"
+	"market-system/common/models"
+	"math"
+	"sort"
+	"time"
+)
+
+// sourcePrice 单个数据源（内部撮合或某个外部交易所）的最新价
+type sourcePrice struct {
+	source    string // internal, external
+	price     float64
+	timestamp int64 // 接收时间（毫秒），用于判断新鲜度
+}
+
+// cacheSourcePrice 按交易所缓存最新价（调用方需持有锁）
+// 外部数据缓存只保留最后收到的一条，多个外部交易所时需要单独记录每个交易所的价格
+func (m *DataMerger) cacheSourcePrice(data *models.MarketData, ticker *models.Ticker) {
+	prices, ok := m.sourcePrices[data.Symbol]
+	if !ok {
+		prices = make(map[string]*sourcePrice)
+		m.sourcePrices[data.Symbol] = prices
+	}
+	prices[data.Exchange] = &sourcePrice{
+		source:    data.Source,
+		price:     ticker.LastPrice,
+		timestamp: time.Now().UnixMilli(),
+	}
+}
+
+// mergeTickerRobust 按中位数或截尾均值融合最新价
+// 每个数据源各取一个最新价，单个数据源被操纵或异常时不会带偏发布价格（至少需要 3 个新鲜的数据源）：
+// - median: 取中位数，偶数个数据源时取中间两个的平均值
+// - trimmed_mean: 按价格排序后两端各剔除 trim_percent% 的数据源（向上取整，至少各剔除一个），剩余取平均
+// 买卖价、24h 最高/最低价和成交量沿用优先级策略；新鲜的数据源不足 3 个时最新价使用优先级策略的主数据源
+func (m *DataMerger) mergeTickerRobust(symbol string, internal, external *CachedData, config *models.SymbolConfig) *models.TickerWithSource {
+	ticker := m.mergeTickerPriority(internal, external, config)
+	if ticker == nil {
+		return nil
+	}
+
+	prices := make([]float64, 0, len(m.sourcePrices[symbol]))
+	for _, p := range m.sourcePrices[symbol] {
+		if p.price <= 0 || !m.isDataFresh(p.timestamp, config) {
+			continue
+		}
+		prices = append(prices, p.price)
+	}
+	if len(prices) < constants.MinRobustSources {
+		return ticker
+	}
+
+	sort.Float64s(prices)
+	if config.MergeStrategy == constants.MergeStrategyMedian {
+		ticker.LastPrice = median(prices)
+	} else {
+		ticker.LastPrice = trimmedMean(prices, m.trimPercent(config))
+	}
+	ticker.LastPriceSource = constants.SourceMerged
+
+	return ticker
+}
+
+// median 中位数（prices 已升序）
+func median(prices []float64) float64 {
+	n := len(prices)
+	if n%2 == 1 {
+		return prices[n/2]
+	}
+	return (prices[n/2-1] + prices[n/2]) / 2
+}
+
+// trimmedMean 截尾均值（prices 已升序），两端各剔除 percent% 的数据（向上取整），至少保留一个
+func trimmedMean(prices []float64, percent float64) float64 {
+	n := len(prices)
+	trim := int(math.Ceil(float64(n)*percent/100 - 1e-9)) // 避免 0.1 等比例的浮点误差多剔除一个
+	if 2*trim >= n {
+		trim = (n - 1) / 2
+	}
+
+	var sum float64
+	for _, p := range prices[trim : n-trim] {
+		sum += p
+	}
+	return sum / float64(n-2*trim)
+}
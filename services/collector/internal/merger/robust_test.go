@@ -0,0 +1,156 @@
+package merger
+
+import (
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"math"
+	"testing"
+)
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		prices []float64
+		want   float64
+	}{
+		{[]float64{100}, 100},
+		{[]float64{100, 102}, 101},
+		{[]float64{99, 100, 500}, 100},
+		{[]float64{1, 99, 100, 101}, 99.5},
+		{[]float64{98, 99, 100, 101, 10000}, 100},
+	}
+	for _, tt := range tests {
+		if got := median(tt.prices); got != tt.want {
+			t.Errorf("median(%v) = %v, want %v", tt.prices, got, tt.want)
+		}
+	}
+}
+
+func TestTrimmedMean(t *testing.T) {
+	tests := []struct {
+		prices  []float64
+		percent float64
+		want    float64
+	}{
+		// 3 个数据源按 20% 向上取整各剔除一个，只剩中间值
+		{[]float64{99, 100, 500}, 20, 100},
+		{[]float64{1, 99, 101, 500}, 20, 100},
+		{[]float64{1, 99, 100, 101, 500}, 20, 100},
+		// 10 个数据源按 20% 各剔除两个
+		{[]float64{1, 2, 100, 100, 100, 100, 100, 100, 900, 1000}, 20, 100},
+		// 10 个数据源按 10% 恰好各剔除一个（不因浮点误差多剔除）
+		{[]float64{1, 98, 99, 100, 100, 100, 100, 101, 102, 1000}, 10, 100},
+		// 剔除比例过大时至少保留一个
+		{[]float64{99, 100, 500}, 50, 100},
+		{[]float64{99, 101}, 20, 100},
+		{[]float64{100}, 20, 100},
+	}
+	for _, tt := range tests {
+		if got := trimmedMean(tt.prices, tt.percent); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("trimmedMean(%v, %v) = %v, want %v", tt.prices, tt.percent, got, tt.want)
+		}
+	}
+}
+
+// tickerData 某个交易所的 Ticker
+func tickerData(exchange, source string, price float64) *models.MarketData {
+	return &models.MarketData{
+		Exchange: exchange,
+		Symbol:   "BTCUSDT",
+		Type:     constants.DataTypeTicker,
+		Source:   source,
+		Data:     &models.Ticker{Symbol: "BTCUSDT", LastPrice: price, Open24h: 100},
+	}
+}
+
+func TestMergeTickerRobust(t *testing.T) {
+	tests := []struct {
+		name       string
+		strategy   string
+		sources    []*models.MarketData
+		wantPrice  float64
+		wantSource string
+	}{
+		{
+			// 两个数据源不足最少数量，使用内部数据源的最新价，外部被操纵的价格不影响发布价格
+			name:     "median two sources",
+			strategy: constants.MergeStrategyMedian,
+			sources: []*models.MarketData{
+				tickerData("internal", constants.SourceInternal, 100),
+				tickerData("binance", constants.SourceExternal, 200),
+			},
+			wantPrice:  100,
+			wantSource: constants.SourceInternal,
+		},
+		{
+			name:     "trimmed mean two sources",
+			strategy: constants.MergeStrategyTrimmedMean,
+			sources: []*models.MarketData{
+				tickerData("internal", constants.SourceInternal, 100),
+				tickerData("binance", constants.SourceExternal, 200),
+			},
+			wantPrice:  100,
+			wantSource: constants.SourceInternal,
+		},
+		{
+			name:     "median three sources",
+			strategy: constants.MergeStrategyMedian,
+			sources: []*models.MarketData{
+				tickerData("internal", constants.SourceInternal, 500),
+				tickerData("binance", constants.SourceExternal, 100),
+				tickerData("okx", constants.SourceExternal, 102),
+			},
+			wantPrice:  102,
+			wantSource: constants.SourceMerged,
+		},
+		{
+			name:     "trimmed mean three sources",
+			strategy: constants.MergeStrategyTrimmedMean,
+			sources: []*models.MarketData{
+				tickerData("internal", constants.SourceInternal, 101),
+				tickerData("binance", constants.SourceExternal, 1),
+				tickerData("okx", constants.SourceExternal, 100),
+			},
+			wantPrice:  100,
+			wantSource: constants.SourceMerged,
+		},
+		{
+			name:     "trimmed mean five sources",
+			strategy: constants.MergeStrategyTrimmedMean,
+			sources: []*models.MarketData{
+				tickerData("internal", constants.SourceInternal, 100),
+				tickerData("binance", constants.SourceExternal, 99),
+				tickerData("okx", constants.SourceExternal, 101),
+				tickerData("bybit", constants.SourceExternal, 0.5),
+				tickerData("kraken", constants.SourceExternal, 900),
+			},
+			wantPrice:  100,
+			wantSource: constants.SourceMerged,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewDataMerger([]*models.SymbolConfig{{
+				Symbol:        "BTCUSDT",
+				Mode:          constants.ModeHybrid,
+				MergeStrategy: tt.strategy,
+			}}, config.HybridModeConfig{})
+
+			var result *models.MarketData
+			for _, data := range tt.sources {
+				result = m.ProcessData(data)
+			}
+			ticker, ok := result.Data.(*models.TickerWithSource)
+			if !ok {
+				t.Fatalf("result = %+v", result)
+			}
+			if ticker.LastPrice != tt.wantPrice || ticker.LastPriceSource != tt.wantSource {
+				t.Errorf("last price = %v (%s), want %v (%s)", ticker.LastPrice, ticker.LastPriceSource, tt.wantPrice, tt.wantSource)
+			}
+			if want := (tt.wantPrice - 100) / 100 * 100; math.Abs(ticker.PriceChangePercent24h-want) > 1e-9 {
+				t.Errorf("change percent = %v, want %v", ticker.PriceChangePercent24h, want)
+			}
+		})
+	}
+}
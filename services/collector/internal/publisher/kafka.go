@@ -7,6 +7,7 @@ import (
 	"market-system/common/constants"
 	"market-system/common/models"
 	"market-system/common/utils"
+	"market-system/common/version"
 
 	"github.com/segmentio/kafka-go"
 )
@@ -73,8 +74,9 @@ func (p *KafkaPublisher) Publish(data *models.MarketData) error {
 	key := []byte(data.Symbol)
 
 	msg := kafka.Message{
-		Key:   key,
-		Value: value,
+		Key:     key,
+		Value:   value,
+		Headers: version.Headers(constants.ServiceCollector),
 	}
 
 	// 异步写入
@@ -0,0 +1,143 @@
+package symbolconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"market-system/common/constants"
+	"market-system/common/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Updater 交易对配置更新接口（由 DataMerger 实现）
+type Updater interface {
+	UpdateSymbolConfig(config *models.SymbolConfig)
+	RemoveSymbolConfig(symbol string) // 删除运行时配置，配置文件中有该交易对时恢复为配置文件中的配置
+}
+
+// Watcher 监听 Redis 中的交易对配置，变更后实时更新到融合器
+// 配置由 API 服务的管理接口写入 symbol_config Hash，并通过 symbol_config:update 频道通知
+type Watcher struct {
+	client  *redis.Client
+	updater Updater
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// NewWatcher 创建配置监听器
+func NewWatcher(client *redis.Client, updater Updater) *Watcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Watcher{
+		client:  client,
+		updater: updater,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Start 加载 Redis 中已有的配置（覆盖配置文件中的同名交易对），并开始监听变更
+func (w *Watcher) Start() error {
+	if err := w.client.Ping(w.ctx).Err(); err != nil {
+		return fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	// 先订阅再加载，避免加载期间的变更丢失
+	pubsub := w.client.Subscribe(w.ctx, constants.RedisChannelSymbolConfig)
+	if _, err := pubsub.Receive(w.ctx); err != nil {
+		pubsub.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	if err := w.loadAll(); err != nil {
+		pubsub.Close()
+		return err
+	}
+
+	go w.watch(pubsub)
+
+	log.Printf("[SymbolConfig] Watching %s\n", constants.RedisChannelSymbolConfig)
+	return nil
+}
+
+// Close 停止监听（Redis 客户端由调用方关闭）
+func (w *Watcher) Close() {
+	w.cancel()
+}
+
+// loadAll 加载全部配置
+func (w *Watcher) loadAll() error {
+	data, err := w.client.HGetAll(w.ctx, constants.RedisKeySymbolConfig).Result()
+	if err != nil {
+		return fmt.Errorf("failed to load symbol configs: %w", err)
+	}
+
+	for symbol, raw := range data {
+		cfg, err := decode(raw)
+		if err != nil {
+			log.Printf("[SymbolConfig] Invalid config for %s: %v\n", symbol, err)
+			continue
+		}
+		w.updater.UpdateSymbolConfig(cfg)
+	}
+
+	log.Printf("[SymbolConfig] Loaded %d configs from redis\n", len(data))
+	return nil
+}
+
+// watch 处理配置变更通知
+func (w *Watcher) watch(pubsub *redis.PubSub) {
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				log.Println("[SymbolConfig] Channel closed")
+				return
+			}
+			w.reload(msg.Payload)
+		}
+	}
+}
+
+// reload 重新加载单个交易对的配置
+func (w *Watcher) reload(symbol string) {
+	raw, err := w.client.HGet(w.ctx, constants.RedisKeySymbolConfig, symbol).Result()
+	w.apply(symbol, raw, err)
+}
+
+// apply 应用读取到的配置，配置不存在时视为删除（配置文件中有该交易对时由融合器恢复为配置文件中的配置）
+func (w *Watcher) apply(symbol, raw string, err error) {
+	if err == redis.Nil {
+		w.updater.RemoveSymbolConfig(symbol)
+		return
+	}
+	if err != nil {
+		log.Printf("[SymbolConfig] Failed to reload %s: %v\n", symbol, err)
+		return
+	}
+
+	cfg, err := decode(raw)
+	if err != nil {
+		log.Printf("[SymbolConfig] Invalid config for %s: %v\n", symbol, err)
+		return
+	}
+	w.updater.UpdateSymbolConfig(cfg)
+}
+
+// decode 解析配置
+func decode(raw string) (*models.SymbolConfig, error) {
+	var cfg models.SymbolConfig
+	if err := json.Unmarshal([]byte(raw), &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Symbol == "" {
+		return nil, fmt.Errorf("missing symbol")
+	}
+	return &cfg, nil
+}
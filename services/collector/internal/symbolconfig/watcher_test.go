@@ -0,0 +1,40 @@
+package symbolconfig
+
+import (
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/services/collector/internal/merger"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestWatcherApplyDelete(t *testing.T) {
+	fileConfig := &models.SymbolConfig{Symbol: "BTCUSDT", Mode: constants.ModeHybrid, Enable: true}
+	m := merger.NewDataMerger([]*models.SymbolConfig{fileConfig}, config.HybridModeConfig{})
+	w := &Watcher{updater: m}
+
+	// 管理接口覆盖配置文件中的交易对，以及只在 Redis 中配置的交易对
+	w.apply("BTCUSDT", `{"symbol":"BTCUSDT","mode":"INTERNAL_ONLY","enable":true}`, nil)
+	w.apply("ETHUSDT", `{"symbol":"ETHUSDT","mode":"HYBRID","enable":true}`, nil)
+	if cfg := m.GetSymbolConfig("BTCUSDT"); cfg == nil || cfg.Mode != constants.ModeInternalOnly {
+		t.Fatalf("BTCUSDT config = %+v", cfg)
+	}
+
+	// 删除后与重启后的行为一致：恢复为配置文件中的配置，只在 Redis 中配置的交易对删除
+	w.apply("BTCUSDT", "", redis.Nil)
+	if cfg := m.GetSymbolConfig("BTCUSDT"); cfg != fileConfig {
+		t.Errorf("BTCUSDT config after delete = %+v, want file config", cfg)
+	}
+	w.apply("ETHUSDT", "", redis.Nil)
+	if cfg := m.GetSymbolConfig("ETHUSDT"); cfg != nil {
+		t.Errorf("ETHUSDT config after delete = %+v, want nil", cfg)
+	}
+
+	// 无效的配置不影响当前配置
+	w.apply("BTCUSDT", `{"mode":"EXTERNAL_ONLY"}`, nil)
+	if cfg := m.GetSymbolConfig("BTCUSDT"); cfg != fileConfig {
+		t.Errorf("BTCUSDT config after invalid update = %+v", cfg)
+	}
+}
@@ -0,0 +1,113 @@
+// history 历史数据查询服务
+//
+// 从 Processor 写入的 PostgreSQL / TimescaleDB 或 InfluxDB 按任意时间范围分页查询成交和K线，
+// 提供 REST（/api/v1/history/*）和 gRPC（history.v1.HistoryData）接口；
+// Redis 中只保留最近的数据，更早的数据通过本服务查询。
+//
+// 用法:
+//
+//	history -config configs/history.json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/health"
+	"market-system/services/history/internal/query"
+	"market-system/services/history/internal/server"
+	"market-system/services/history/internal/store"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	configPath := flag.String("config", "configs/history.json", "配置文件路径")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v\n", err)
+	}
+
+	st, err := store.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create store: %v\n", err)
+	}
+	defer st.Close()
+
+	svc := query.NewService(st, cfg)
+	checker := health.New(constants.ServiceHistory)
+	checker.AddReadiness("store", st.Ping)
+
+	// 启动 gRPC 服务
+	if cfg.GrpcListenOn != "" {
+		listener, err := net.Listen("tcp", cfg.GrpcListenOn)
+		if err != nil {
+			log.Fatalf("[History] Failed to listen on %s: %v\n", cfg.GrpcListenOn, err)
+		}
+		grpcServer := server.NewGRPCServer(svc)
+		defer grpcServer.GracefulStop()
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Printf("[History] gRPC server stopped: %v\n", err)
+			}
+		}()
+		log.Printf("[History] gRPC server listening on %s\n", cfg.GrpcListenOn)
+	}
+
+	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           server.NewHandler(svc, checker),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("[History] HTTP server stopped: %v\n", err)
+		}
+	}()
+	log.Printf("[History] Serving %s backend on %s\n", backendName(cfg.Backend), addr)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	<-sigChan
+	log.Println("Received shutdown signal")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	httpServer.Shutdown(ctx)
+}
+
+func backendName(backend string) string {
+	if backend == "" {
+		return store.BackendPostgres
+	}
+	return backend
+}
+
+func loadConfig(path string) (*config.HistoryConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg config.HistoryConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.Server.Port == 0 {
+		cfg.Server.Port = 8083
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,242 @@
+package query
+
+import (
+	"context"
+	"encoding/base64"
+	"market-system/common/config"
+	"market-system/common/errcode"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"market-system/services/history/internal/store"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 默认值
+const (
+	DefaultLimit    = 500
+	DefaultInterval = "1m"
+
+	defaultMaxLimit       = 5000
+	defaultQueryTimeoutMs = 10000
+)
+
+// 排序方式
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// TradesRequest 成交查询参数
+type TradesRequest struct {
+	Symbol    string
+	StartTime int64
+	EndTime   int64
+	Limit     int
+	Order     string
+	Cursor    string // 上一页返回的 NextCursor
+}
+
+// TradesPage 一页成交
+type TradesPage struct {
+	Symbol     string          `json:"symbol"`
+	Trades     []*models.Trade `json:"trades"`
+	HasMore    bool            `json:"has_more"`
+	NextCursor string          `json:"next_cursor,omitempty"` // HasMore 时用于查询下一页
+}
+
+// KlinesRequest K线查询参数
+type KlinesRequest struct {
+	Symbol    string
+	Interval  string
+	StartTime int64
+	EndTime   int64
+	Limit     int
+	Order     string
+}
+
+// KlinesPage 一页K线
+// 与行情接口的K线查询相同，下一页通过时间范围继续：升序时以 NextStartTime 作为 start_time，降序时以 NextEndTime 作为 end_time
+type KlinesPage struct {
+	Symbol        string          `json:"symbol"`
+	Interval      string          `json:"interval"`
+	Klines        []*models.Kline `json:"klines"`
+	HasMore       bool            `json:"has_more"`
+	NextStartTime int64           `json:"next_start_time,omitempty"`
+	NextEndTime   int64           `json:"next_end_time,omitempty"`
+}
+
+// Service 历史数据查询，REST 和 gRPC 接口共用
+// 每页多查一条记录判断是否还有下一页
+type Service struct {
+	store    store.Store
+	maxLimit int
+	timeout  time.Duration
+}
+
+// NewService 创建查询服务
+func NewService(s store.Store, cfg *config.HistoryConfig) *Service {
+	maxLimit := cfg.MaxLimit
+	if maxLimit <= 0 {
+		maxLimit = defaultMaxLimit
+	}
+	timeoutMs := cfg.QueryTimeoutMs
+	if timeoutMs <= 0 {
+		timeoutMs = defaultQueryTimeoutMs
+	}
+	return &Service{
+		store:    s,
+		maxLimit: maxLimit,
+		timeout:  time.Duration(timeoutMs) * time.Millisecond,
+	}
+}
+
+// QueryTrades 分页查询成交
+func (s *Service) QueryTrades(ctx context.Context, req *TradesRequest) (*TradesPage, error) {
+	symbol := utils.FormatSymbol(req.Symbol)
+	if err := validateRange(symbol, req.StartTime, req.EndTime); err != nil {
+		return nil, err
+	}
+	limit, err := s.limit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := parseOrder(req.Order)
+	if err != nil {
+		return nil, err
+	}
+	q := &store.TradeQuery{
+		Symbol:    symbol,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Desc:      desc,
+		Limit:     limit + 1,
+	}
+	if req.Cursor != "" {
+		if q.After, err = DecodeCursor(req.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	trades, err := s.store.QueryTrades(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &TradesPage{Symbol: symbol, Trades: trades}
+	if len(trades) > limit {
+		page.Trades = trades[:limit]
+		page.HasMore = true
+		last := page.Trades[limit-1]
+		page.NextCursor = EncodeCursor(&store.TradeCursor{Timestamp: last.Timestamp, TradeID: last.TradeID})
+	}
+	return page, nil
+}
+
+// QueryKlines 分页查询K线
+func (s *Service) QueryKlines(ctx context.Context, req *KlinesRequest) (*KlinesPage, error) {
+	symbol := utils.FormatSymbol(req.Symbol)
+	if err := validateRange(symbol, req.StartTime, req.EndTime); err != nil {
+		return nil, err
+	}
+	interval := req.Interval
+	if interval == "" {
+		interval = DefaultInterval
+	}
+	if !utils.ValidateInterval(interval) {
+		return nil, errcode.Newf(errcode.ErrInvalidParam, "invalid interval: %s", interval)
+	}
+	limit, err := s.limit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := parseOrder(req.Order)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	klines, err := s.store.QueryKlines(ctx, &store.KlineQuery{
+		Symbol:    symbol,
+		Interval:  interval,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Desc:      desc,
+		Limit:     limit + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page := &KlinesPage{Symbol: symbol, Interval: interval, Klines: klines}
+	if len(klines) > limit {
+		page.Klines = klines[:limit]
+		page.HasMore = true
+		if desc {
+			page.NextEndTime = page.Klines[limit-1].OpenTime - 1
+		} else {
+			page.NextStartTime = page.Klines[limit-1].OpenTime + 1
+		}
+	}
+	return page, nil
+}
+
+// limit 校验每页数量，0 为默认值
+func (s *Service) limit(limit int) (int, error) {
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	if limit < 0 || limit > s.maxLimit {
+		return 0, errcode.Newf(errcode.ErrInvalidParam, "limit must be between 1 and %d", s.maxLimit)
+	}
+	return limit, nil
+}
+
+func validateRange(symbol string, startTime, endTime int64) error {
+	if err := utils.ValidateSymbol(symbol); err != nil {
+		return err
+	}
+	if startTime < 0 || endTime < 0 {
+		return errcode.Newf(errcode.ErrInvalidParam, "start_time and end_time must not be negative")
+	}
+	if endTime > 0 && startTime > endTime {
+		return errcode.Newf(errcode.ErrInvalidParam, "start_time must not be after end_time")
+	}
+	return nil
+}
+
+func parseOrder(order string) (bool, error) {
+	switch order {
+	case "", OrderAsc:
+		return false, nil
+	case OrderDesc:
+		return true, nil
+	}
+	return false, errcode.Newf(errcode.ErrInvalidParam, "invalid order: %s", order)
+}
+
+// EncodeCursor 成交分页游标，内容为 "时间戳:成交ID" 的 base64（URL 安全）
+func EncodeCursor(c *store.TradeCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.Timestamp, 10) + ":" + c.TradeID))
+}
+
+// DecodeCursor 解析成交分页游标
+func DecodeCursor(cursor string) (*store.TradeCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errcode.Newf(errcode.ErrInvalidParam, "invalid cursor")
+	}
+	ts, tradeID, ok := strings.Cut(string(data), ":")
+	if !ok || tradeID == "" {
+		return nil, errcode.Newf(errcode.ErrInvalidParam, "invalid cursor")
+	}
+	timestamp, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return nil, errcode.Newf(errcode.ErrInvalidParam, "invalid cursor")
+	}
+	return &store.TradeCursor{Timestamp: timestamp, TradeID: tradeID}, nil
+}
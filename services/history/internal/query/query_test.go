@@ -0,0 +1,169 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"market-system/common/config"
+	"market-system/common/errcode"
+	"market-system/common/models"
+	"market-system/services/history/internal/store"
+	"sort"
+	"testing"
+)
+
+// memoryStore 按 Store 的语义在内存中过滤、排序
+type memoryStore struct {
+	trades []*models.Trade
+	klines []*models.Kline
+}
+
+func (s *memoryStore) QueryTrades(ctx context.Context, q *store.TradeQuery) ([]*models.Trade, error) {
+	less := func(a, b *models.Trade) bool {
+		if a.Timestamp != b.Timestamp {
+			return a.Timestamp < b.Timestamp
+		}
+		return a.TradeID < b.TradeID
+	}
+	var result []*models.Trade
+	for _, t := range s.trades {
+		if t.Symbol != q.Symbol || q.StartTime > 0 && t.Timestamp < q.StartTime || q.EndTime > 0 && t.Timestamp > q.EndTime {
+			continue
+		}
+		if c := q.After; c != nil {
+			cursor := &models.Trade{Timestamp: c.Timestamp, TradeID: c.TradeID}
+			if q.Desc && !less(t, cursor) || !q.Desc && !less(cursor, t) {
+				continue
+			}
+		}
+		result = append(result, t)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if q.Desc {
+			return less(result[j], result[i])
+		}
+		return less(result[i], result[j])
+	})
+	if len(result) > q.Limit {
+		result = result[:q.Limit]
+	}
+	return result, nil
+}
+
+func (s *memoryStore) QueryKlines(ctx context.Context, q *store.KlineQuery) ([]*models.Kline, error) {
+	var result []*models.Kline
+	for _, k := range s.klines {
+		if k.Symbol == q.Symbol && k.Interval == q.Interval &&
+			(q.StartTime == 0 || k.OpenTime >= q.StartTime) && (q.EndTime == 0 || k.OpenTime <= q.EndTime) {
+			result = append(result, k)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].OpenTime < result[j].OpenTime != q.Desc
+	})
+	if len(result) > q.Limit {
+		result = result[:q.Limit]
+	}
+	return result, nil
+}
+
+func (s *memoryStore) Ping(ctx context.Context) error { return nil }
+func (s *memoryStore) Close() error                   { return nil }
+
+func TestQueryTradesCursor(t *testing.T) {
+	st := &memoryStore{}
+	// 同一毫秒内有多笔成交，跨越分页边界
+	for i, ts := range []int64{1000, 1000, 1000, 2000, 2000, 3000, 4000} {
+		st.trades = append(st.trades, &models.Trade{Symbol: "BTCUSDT", TradeID: string(rune('a' + i)), Timestamp: ts})
+	}
+	svc := NewService(st, &config.HistoryConfig{})
+
+	for _, order := range []string{OrderAsc, OrderDesc} {
+		var ids string
+		req := &TradesRequest{Symbol: "BTCUSDT", Limit: 2, Order: order}
+		for pages := 0; ; pages++ {
+			if pages > 10 {
+				t.Fatalf("%s: too many pages", order)
+			}
+			page, err := svc.QueryTrades(context.Background(), req)
+			if err != nil {
+				t.Fatalf("%s: %v", order, err)
+			}
+			for _, trade := range page.Trades {
+				ids += trade.TradeID
+			}
+			if !page.HasMore {
+				break
+			}
+			req.Cursor = page.NextCursor
+		}
+		want := "abcdefg"
+		if order == OrderDesc {
+			want = "gfedcba"
+		}
+		if ids != want {
+			t.Errorf("%s: trades = %s, want %s", order, ids, want)
+		}
+	}
+
+	page, err := svc.QueryTrades(context.Background(), &TradesRequest{Symbol: "BTCUSDT", StartTime: 2000, EndTime: 3000})
+	if err != nil || len(page.Trades) != 3 || page.HasMore || page.NextCursor != "" {
+		t.Errorf("range page = %+v, err = %v", page, err)
+	}
+}
+
+func TestQueryKlinesPagination(t *testing.T) {
+	st := &memoryStore{}
+	for i := int64(0); i < 5; i++ {
+		st.klines = append(st.klines, &models.Kline{Symbol: "BTCUSDT", Interval: "1m", OpenTime: i * 60000})
+	}
+	svc := NewService(st, &config.HistoryConfig{})
+
+	page, err := svc.QueryKlines(context.Background(), &KlinesRequest{Symbol: "BTCUSDT", Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !page.HasMore || page.NextStartTime != 60001 || page.Interval != DefaultInterval {
+		t.Errorf("asc page = %+v", page)
+	}
+
+	page, err = svc.QueryKlines(context.Background(), &KlinesRequest{Symbol: "BTCUSDT", Limit: 3, Order: OrderDesc, EndTime: 180000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !page.HasMore || page.NextEndTime != 59999 || page.Klines[0].OpenTime != 180000 {
+		t.Errorf("desc page = %+v", page)
+	}
+}
+
+func TestQueryValidation(t *testing.T) {
+	svc := NewService(&memoryStore{}, &config.HistoryConfig{MaxLimit: 100})
+	ctx := context.Background()
+
+	errs := []error{}
+	for _, req := range []*TradesRequest{
+		{Symbol: "X"},
+		{Symbol: "BTCUSDT", StartTime: 2000, EndTime: 1000},
+		{Symbol: "BTCUSDT", Limit: 101},
+		{Symbol: "BTCUSDT", Order: "random"},
+		{Symbol: "BTCUSDT", Cursor: "not-a-cursor"},
+	} {
+		_, err := svc.QueryTrades(ctx, req)
+		errs = append(errs, err)
+	}
+	_, err := svc.QueryKlines(ctx, &KlinesRequest{Symbol: "BTCUSDT", Interval: "2m"})
+	errs = append(errs, err)
+
+	for i, err := range errs {
+		if !errors.Is(err, errcode.ErrInvalidParam) {
+			t.Errorf("case %d: err = %v, want invalid parameter", i, err)
+		}
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := &store.TradeCursor{Timestamp: 1700000000123, TradeID: "abc:123"}
+	got, err := DecodeCursor(EncodeCursor(c))
+	if err != nil || *got != *c {
+		t.Errorf("cursor = %+v, err = %v", got, err)
+	}
+}
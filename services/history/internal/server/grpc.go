@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"market-system/common/errcode"
+	"market-system/common/proto/historypb"
+	"market-system/services/history/internal/query"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCServer 历史数据 gRPC 服务，与 REST 接口共用查询逻辑
+type GRPCServer struct {
+	historypb.UnimplementedHistoryDataServer
+	svc *query.Service
+}
+
+// NewGRPCServer 创建注册了历史数据服务的 gRPC 服务器
+func NewGRPCServer(svc *query.Service) *grpc.Server {
+	server := grpc.NewServer()
+	historypb.RegisterHistoryDataServer(server, &GRPCServer{svc: svc})
+	return server
+}
+
+// QueryTrades 分页查询成交
+func (s *GRPCServer) QueryTrades(ctx context.Context, req *historypb.QueryTradesRequest) (*historypb.QueryTradesResponse, error) {
+	page, err := s.svc.QueryTrades(ctx, &query.TradesRequest{
+		Symbol:    req.Symbol,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Limit:     int(req.Limit),
+		Order:     req.Order,
+		Cursor:    req.Cursor,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	trades := make([]*historypb.Trade, 0, len(page.Trades))
+	for _, t := range page.Trades {
+		trades = append(trades, &historypb.Trade{
+			Symbol:    t.Symbol,
+			TradeId:   t.TradeID,
+			Price:     t.Price,
+			Amount:    t.Amount,
+			Side:      t.Side,
+			Timestamp: t.Timestamp,
+		})
+	}
+	return &historypb.QueryTradesResponse{
+		Symbol:     page.Symbol,
+		Trades:     trades,
+		HasMore:    page.HasMore,
+		NextCursor: page.NextCursor,
+	}, nil
+}
+
+// QueryKlines 分页查询K线
+func (s *GRPCServer) QueryKlines(ctx context.Context, req *historypb.QueryKlinesRequest) (*historypb.QueryKlinesResponse, error) {
+	page, err := s.svc.QueryKlines(ctx, &query.KlinesRequest{
+		Symbol:    req.Symbol,
+		Interval:  req.Interval,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Limit:     int(req.Limit),
+		Order:     req.Order,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	klines := make([]*historypb.Kline, 0, len(page.Klines))
+	for _, k := range page.Klines {
+		klines = append(klines, &historypb.Kline{
+			Symbol:    k.Symbol,
+			Interval:  k.Interval,
+			OpenTime:  k.OpenTime,
+			CloseTime: k.CloseTime,
+			Open:      k.Open,
+			High:      k.High,
+			Low:       k.Low,
+			Close:     k.Close,
+			Volume:    k.Volume,
+			QuoteVol:  k.QuoteVol,
+			TradeNum:  k.TradeNum,
+			Revision:  k.Revision,
+			IsFinal:   k.IsFinal,
+		})
+	}
+	return &historypb.QueryKlinesResponse{
+		Symbol:        page.Symbol,
+		Interval:      page.Interval,
+		Klines:        klines,
+		HasMore:       page.HasMore,
+		NextStartTime: page.NextStartTime,
+		NextEndTime:   page.NextEndTime,
+	}, nil
+}
+
+// toStatus 业务错误转换为 gRPC 状态码
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, errcode.ErrInvalidParam):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	log.Printf("[History] Query failed: %v\n", err)
+	return status.Error(codes.Internal, errcode.ErrInternal.Msg)
+}
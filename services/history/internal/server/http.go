@@ -0,0 +1,124 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"market-system/common/errcode"
+	"market-system/common/health"
+	"market-system/services/history/internal/query"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// REST 接口路径
+const (
+	PathTrades = "/api/v1/history/trades"
+	PathKlines = "/api/v1/history/klines"
+)
+
+// response 与 API 服务相同的统一响应：{"code": 0, "msg": "success", "data": ...}
+type response struct {
+	Code int         `json:"code"`
+	Msg  string      `json:"msg"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// NewHandler 创建 REST 接口及存活/就绪检查的路由
+func NewHandler(svc *query.Service, checker *health.Checker) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathTrades, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		params := r.URL.Query()
+		req := &query.TradesRequest{
+			Symbol: params.Get("symbol"),
+			Order:  params.Get("order"),
+			Cursor: params.Get("cursor"),
+		}
+		if err := parseInts(params, map[string]*int64{"start_time": &req.StartTime, "end_time": &req.EndTime}); err != nil {
+			writeResponse(w, nil, err)
+			return
+		}
+		limit, err := parseLimit(params)
+		if err != nil {
+			writeResponse(w, nil, err)
+			return
+		}
+		req.Limit = limit
+
+		page, err := svc.QueryTrades(r.Context(), req)
+		writeResponse(w, page, err)
+	})
+	mux.HandleFunc(PathKlines, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		params := r.URL.Query()
+		req := &query.KlinesRequest{
+			Symbol:   params.Get("symbol"),
+			Interval: params.Get("interval"),
+			Order:    params.Get("order"),
+		}
+		if err := parseInts(params, map[string]*int64{"start_time": &req.StartTime, "end_time": &req.EndTime}); err != nil {
+			writeResponse(w, nil, err)
+			return
+		}
+		limit, err := parseLimit(params)
+		if err != nil {
+			writeResponse(w, nil, err)
+			return
+		}
+		req.Limit = limit
+
+		page, err := svc.QueryKlines(r.Context(), req)
+		writeResponse(w, page, err)
+	})
+	mux.HandleFunc(health.PathLiveness, checker.LivenessHandler)
+	mux.HandleFunc(health.PathReadiness, checker.ReadinessHandler)
+	return mux
+}
+
+// parseInts 解析整数参数，未传时保持为 0
+func parseInts(params url.Values, fields map[string]*int64) error {
+	for name, dst := range fields {
+		v := params.Get(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return errcode.Newf(errcode.ErrInvalidParam, "invalid %s: %s", name, v)
+		}
+		*dst = n
+	}
+	return nil
+}
+
+func parseLimit(params url.Values) (int, error) {
+	var limit int64
+	if err := parseInts(params, map[string]*int64{"limit": &limit}); err != nil {
+		return 0, err
+	}
+	return int(limit), nil
+}
+
+// writeResponse 写入结果，出错时按错误码返回对应的 HTTP 状态码，内部错误只返回通用信息
+func writeResponse(w http.ResponseWriter, data interface{}, err error) {
+	status, resp := http.StatusOK, &response{Code: errcode.CodeOK, Msg: "success", Data: data}
+	if err != nil {
+		e := errcode.From(err)
+		status, resp = e.HTTPStatus, &response{Code: e.Code, Msg: err.Error()}
+		if e == errcode.ErrInternal {
+			log.Printf("[History] Query failed: %v\n", err)
+			resp.Msg = e.Msg
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
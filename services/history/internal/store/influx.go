@@ -0,0 +1,252 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"market-system/common/config"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InfluxDB measurement，与 Processor 写入时一致
+const (
+	measurementKline = "kline"
+	measurementTrade = "trade"
+)
+
+// Influx 通过 InfluxDB 2.x 的 Flux 查询接口读取 kline、trade measurement
+// 字段经 pivot 合并为一行，结果以 CSV 返回。
+// InfluxDB 中不保存K线的收盘时间和是否收盘，按周期计算。
+type Influx struct {
+	cfg    config.InfluxDBConfig
+	client *http.Client
+}
+
+// NewInflux 创建 InfluxDB 查询客户端
+func NewInflux(cfg config.InfluxDBConfig) (*Influx, error) {
+	if cfg.URL == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("influxdb url and bucket are required")
+	}
+	return &Influx{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// QueryTrades 查询成交
+func (s *Influx) QueryTrades(ctx context.Context, q *TradeQuery) ([]*models.Trade, error) {
+	var b strings.Builder
+	s.writeSource(&b, measurementTrade, q.Symbol, q.StartTime, q.EndTime)
+	b.WriteString(` |> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value") |> group()`)
+	if q.After != nil {
+		cmp := ">"
+		if q.Desc {
+			cmp = "<"
+		}
+		cursorTime := fluxTime(q.After.Timestamp)
+		fmt.Fprintf(&b, ` |> filter(fn: (r) => r._time %s %s or (r._time == %s and r.trade_id %s %s))`,
+			cmp, cursorTime, cursorTime, cmp, fluxString(q.After.TradeID))
+	}
+	fmt.Fprintf(&b, ` |> sort(columns: ["_time", "trade_id"], desc: %t) |> limit(n: %d)`, q.Desc, q.Limit)
+
+	rows, err := s.query(ctx, b.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trades: %w", err)
+	}
+
+	trades := make([]*models.Trade, 0, len(rows))
+	for _, row := range rows {
+		ts, err := time.Parse(time.RFC3339Nano, row["_time"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid trade time %q: %w", row["_time"], err)
+		}
+		trades = append(trades, &models.Trade{
+			Symbol:    q.Symbol,
+			TradeID:   row["trade_id"],
+			Price:     parseFloat(row["price"]),
+			Amount:    parseFloat(row["amount"]),
+			Side:      row["side"],
+			Timestamp: ts.UnixMilli(),
+		})
+	}
+	return trades, nil
+}
+
+// QueryKlines 查询K线
+func (s *Influx) QueryKlines(ctx context.Context, q *KlineQuery) ([]*models.Kline, error) {
+	var b strings.Builder
+	s.writeSource(&b, measurementKline, q.Symbol, q.StartTime, q.EndTime)
+	fmt.Fprintf(&b, ` |> filter(fn: (r) => r.interval == %s)`, fluxString(q.Interval))
+	b.WriteString(` |> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value") |> group()`)
+	fmt.Fprintf(&b, ` |> sort(columns: ["_time"], desc: %t) |> limit(n: %d)`, q.Desc, q.Limit)
+
+	rows, err := s.query(ctx, b.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query klines: %w", err)
+	}
+
+	now := utils.GetCurrentTimestamp()
+	klines := make([]*models.Kline, 0, len(rows))
+	for _, row := range rows {
+		ts, err := time.Parse(time.RFC3339Nano, row["_time"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid kline time %q: %w", row["_time"], err)
+		}
+		openTime := ts.UnixMilli()
+		closeTime := utils.GetKlineCloseTime(openTime, q.Interval)
+		klines = append(klines, &models.Kline{
+			Symbol:    q.Symbol,
+			Interval:  q.Interval,
+			OpenTime:  openTime,
+			CloseTime: closeTime,
+			Open:      parseFloat(row["open"]),
+			High:      parseFloat(row["high"]),
+			Low:       parseFloat(row["low"]),
+			Close:     parseFloat(row["close"]),
+			Volume:    parseFloat(row["volume"]),
+			QuoteVol:  parseFloat(row["quote_vol"]),
+			TradeNum:  parseInt(row["trade_num"]),
+			Revision:  parseInt(row["revision"]),
+			IsFinal:   closeTime < now,
+		})
+	}
+	return klines, nil
+}
+
+// Ping 检查 InfluxDB 是否可用
+func (s *Influx) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(s.cfg.URL, "/")+"/ping", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("influxdb ping returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close 无需释放资源
+func (s *Influx) Close() error {
+	return nil
+}
+
+// writeSource 写入 from/range/filter 部分，结束时间包含在内
+func (s *Influx) writeSource(b *strings.Builder, measurement, symbol string, startTime, endTime int64) {
+	stop := "now()"
+	if endTime > 0 {
+		stop = fluxTime(endTime + 1)
+	}
+	fmt.Fprintf(b, `from(bucket: %s) |> range(start: %s, stop: %s)`, fluxString(s.cfg.Bucket), fluxTime(startTime), stop)
+	fmt.Fprintf(b, ` |> filter(fn: (r) => r._measurement == %s and r.symbol == %s)`, fluxString(measurement), fluxString(symbol))
+}
+
+// query 执行 Flux 查询，返回按列名索引的行
+func (s *Influx) query(ctx context.Context, flux string) ([]map[string]string, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"query":   flux,
+		"type":    "flux",
+		"dialect": map[string]interface{}{"header": true, "annotations": []string{}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("org", s.cfg.Org)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(s.cfg.URL, "/")+"/api/v2/query?"+query.Encode(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Token "+s.cfg.Token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/csv")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("influxdb query returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return parseCSV(resp.Body)
+}
+
+// parseCSV 解析不带注释行的 Flux CSV 结果
+// 每个表以表头开始，表之间以空行分隔（csv.Reader 会跳过空行），因此遇到表头时重新建立列索引。
+func parseCSV(r io.Reader) ([]map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	var header []string
+	var rows []map[string]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse influxdb response: %w", err)
+		}
+		if header == nil || isHeader(record) {
+			header = record
+			continue
+		}
+
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(record) && name != "" {
+				row[name] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func isHeader(record []string) bool {
+	for _, v := range record {
+		if v == "_time" {
+			return true
+		}
+	}
+	return false
+}
+
+// fluxTime 毫秒时间戳转为 Flux 时间字面量
+func fluxTime(ms int64) string {
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339Nano)
+}
+
+// fluxString 转为 Flux 字符串字面量（转义引号、反斜杠和插值符号）
+func fluxString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func parseFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+func parseInt(s string) int64 {
+	v, _ := strconv.ParseInt(s, 10, 64)
+	return v
+}
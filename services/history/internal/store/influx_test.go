@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"market-system/common/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInfluxQueryTrades(t *testing.T) {
+	var flux string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/query" || r.URL.Query().Get("org") != "market" || r.Header.Get("Authorization") != "Token secret" {
+			t.Errorf("unexpected request %s %s", r.URL, r.Header.Get("Authorization"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req struct {
+			Query string `json:"query"`
+		}
+		json.Unmarshal(body, &req)
+		flux = req.Query
+
+		// 多个表时每个表带有表头，表之间为空行
+		io.WriteString(w, ",result,table,_start,_stop,_time,_measurement,side,symbol,amount,price,trade_id\r\n"+
+			",_result,0,2024-01-01T00:00:00Z,2024-01-02T00:00:00Z,2024-01-01T00:00:01.5Z,trade,buy,BTCUSDT,0.5,42000.1,t1\r\n"+
+			"\r\n"+
+			",result,table,_start,_stop,_time,_measurement,side,symbol,amount,price,trade_id\r\n"+
+			",_result,1,2024-01-01T00:00:00Z,2024-01-02T00:00:00Z,2024-01-01T00:00:02Z,trade,sell,BTCUSDT,1.25,42000,t2\r\n")
+	}))
+	defer server.Close()
+
+	s, err := NewInflux(config.InfluxDBConfig{URL: server.URL, Token: "secret", Org: "market", Bucket: "market-data"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trades, err := s.QueryTrades(context.Background(), &TradeQuery{
+		Symbol:  "BTCUSDT",
+		EndTime: 1704153600000,
+		Desc:    true,
+		After:   &TradeCursor{Timestamp: 1704067205000, TradeID: `t"9`},
+		Limit:   2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, part := range []string{
+		`range(start: 1970-01-01T00:00:00Z, stop: 2024-01-02T00:00:00.001Z)`,
+		`r.symbol == "BTCUSDT"`,
+		`r._time < 2024-01-01T00:00:05Z or (r._time == 2024-01-01T00:00:05Z and r.trade_id < "t\"9")`,
+		`desc: true) |> limit(n: 2)`,
+	} {
+		if !strings.Contains(flux, part) {
+			t.Errorf("query missing %q: %s", part, flux)
+		}
+	}
+
+	if len(trades) != 2 {
+		t.Fatalf("trades = %d", len(trades))
+	}
+	if tr := trades[0]; tr.TradeID != "t1" || tr.Side != "buy" || tr.Price != 42000.1 || tr.Amount != 0.5 || tr.Timestamp != 1704067201500 {
+		t.Errorf("first = %+v", tr)
+	}
+	if tr := trades[1]; tr.TradeID != "t2" || tr.Side != "sell" || tr.Timestamp != 1704067202000 {
+		t.Errorf("second = %+v", tr)
+	}
+}
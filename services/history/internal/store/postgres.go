@@ -0,0 +1,136 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"market-system/common/config"
+	"market-system/common/models"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// Postgres 读取 PostgreSQL / TimescaleDB 中的 trades、klines 表（由 Processor 写入，结构见 processor/internal/storage/migrations）
+type Postgres struct {
+	db *sql.DB
+}
+
+// NewPostgres 连接 PostgreSQL
+func NewPostgres(cfg config.PostgresConfig) (*Postgres, error) {
+	if cfg.MaxConns <= 0 {
+		cfg.MaxConns = 10
+	}
+
+	db, err := sql.Open("postgres", cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open postgres: %w", err)
+	}
+	db.SetMaxOpenConns(cfg.MaxConns)
+	db.SetMaxIdleConns(cfg.MaxConns)
+	db.SetConnMaxLifetime(30 * time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+
+	log.Println("[Postgres] Connected successfully")
+	return &Postgres{db: db}, nil
+}
+
+// QueryTrades 查询成交
+func (p *Postgres) QueryTrades(ctx context.Context, q *TradeQuery) ([]*models.Trade, error) {
+	where, args := []string{"symbol = $1"}, []interface{}{q.Symbol}
+	if q.StartTime > 0 {
+		args = append(args, time.UnixMilli(q.StartTime).UTC())
+		where = append(where, fmt.Sprintf("time >= $%d", len(args)))
+	}
+	if q.EndTime > 0 {
+		args = append(args, time.UnixMilli(q.EndTime).UTC())
+		where = append(where, fmt.Sprintf("time <= $%d", len(args)))
+	}
+	order, cmp := "ASC", ">"
+	if q.Desc {
+		order, cmp = "DESC", "<"
+	}
+	if q.After != nil {
+		args = append(args, time.UnixMilli(q.After.Timestamp).UTC(), q.After.TradeID)
+		where = append(where, fmt.Sprintf("(time, trade_id) %s ($%d, $%d)", cmp, len(args)-1, len(args)))
+	}
+	args = append(args, q.Limit)
+
+	query := fmt.Sprintf(`SELECT time, trade_id, price, amount, side FROM trades WHERE %s ORDER BY time %s, trade_id %s LIMIT $%d`,
+		strings.Join(where, " AND "), order, order, len(args))
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trades: %w", err)
+	}
+	defer rows.Close()
+
+	trades := make([]*models.Trade, 0, q.Limit)
+	for rows.Next() {
+		var t time.Time
+		trade := &models.Trade{Symbol: q.Symbol}
+		if err := rows.Scan(&t, &trade.TradeID, &trade.Price, &trade.Amount, &trade.Side); err != nil {
+			return nil, fmt.Errorf("failed to scan trade: %w", err)
+		}
+		trade.Timestamp = t.UnixMilli()
+		trades = append(trades, trade)
+	}
+	return trades, rows.Err()
+}
+
+// QueryKlines 查询K线
+func (p *Postgres) QueryKlines(ctx context.Context, q *KlineQuery) ([]*models.Kline, error) {
+	where, args := []string{"symbol = $1", "interval = $2"}, []interface{}{q.Symbol, q.Interval}
+	if q.StartTime > 0 {
+		args = append(args, time.UnixMilli(q.StartTime).UTC())
+		where = append(where, fmt.Sprintf("open_time >= $%d", len(args)))
+	}
+	if q.EndTime > 0 {
+		args = append(args, time.UnixMilli(q.EndTime).UTC())
+		where = append(where, fmt.Sprintf("open_time <= $%d", len(args)))
+	}
+	order := "ASC"
+	if q.Desc {
+		order = "DESC"
+	}
+	args = append(args, q.Limit)
+
+	query := fmt.Sprintf(`SELECT open_time, close_time, open, high, low, close, volume, quote_vol, trade_num, revision, is_final
+		FROM klines WHERE %s ORDER BY open_time %s LIMIT $%d`, strings.Join(where, " AND "), order, len(args))
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query klines: %w", err)
+	}
+	defer rows.Close()
+
+	klines := make([]*models.Kline, 0, q.Limit)
+	for rows.Next() {
+		var openTime, closeTime time.Time
+		k := &models.Kline{Symbol: q.Symbol, Interval: q.Interval}
+		if err := rows.Scan(&openTime, &closeTime, &k.Open, &k.High, &k.Low, &k.Close,
+			&k.Volume, &k.QuoteVol, &k.TradeNum, &k.Revision, &k.IsFinal); err != nil {
+			return nil, fmt.Errorf("failed to scan kline: %w", err)
+		}
+		k.OpenTime = openTime.UnixMilli()
+		k.CloseTime = closeTime.UnixMilli()
+		klines = append(klines, k)
+	}
+	return klines, rows.Err()
+}
+
+// Ping 检查连接
+func (p *Postgres) Ping(ctx context.Context) error {
+	return p.db.PingContext(ctx)
+}
+
+// Close 关闭连接
+func (p *Postgres) Close() error {
+	return p.db.Close()
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/config"
+	"market-system/common/models"
+)
+
+// 存储后端名称，与 Processor 的 storage.backends 对应
+const (
+	BackendPostgres = "postgres"
+	BackendInfluxDB = "influxdb"
+)
+
+// Store 历史数据存储（只读）
+type Store interface {
+	// QueryTrades 按成交时间查询成交，同一时间的成交按成交 ID 排序
+	QueryTrades(ctx context.Context, q *TradeQuery) ([]*models.Trade, error)
+	// QueryKlines 按开盘时间查询K线
+	QueryKlines(ctx context.Context, q *KlineQuery) ([]*models.Kline, error)
+	// Ping 检查存储是否可用
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// TradeQuery 成交查询条件
+type TradeQuery struct {
+	Symbol    string
+	StartTime int64 // 成交时间下限（毫秒，含），为 0 时不限制
+	EndTime   int64 // 成交时间上限（毫秒，含），为 0 时不限制
+	Desc      bool  // 按时间倒序
+	After     *TradeCursor
+	Limit     int
+}
+
+// TradeCursor 成交的位置，查询从该成交之后（按查询的顺序，不含该成交）开始
+type TradeCursor struct {
+	Timestamp int64
+	TradeID   string
+}
+
+// KlineQuery K线查询条件
+type KlineQuery struct {
+	Symbol    string
+	Interval  string
+	StartTime int64 // 开盘时间下限（毫秒，含），为 0 时不限制
+	EndTime   int64 // 开盘时间上限（毫秒，含），为 0 时不限制
+	Desc      bool  // 按开盘时间倒序
+	Limit     int
+}
+
+// New 按配置创建存储
+func New(cfg *config.HistoryConfig) (Store, error) {
+	switch cfg.Backend {
+	case "", BackendPostgres:
+		return NewPostgres(cfg.Postgres)
+	case BackendInfluxDB:
+		return NewInflux(cfg.InfluxDB)
+	}
+	return nil, fmt.Errorf("unknown history backend: %s", cfg.Backend)
+}
@@ -0,0 +1,254 @@
+// dlq 查看死信队列中的消息，并将修复后的消息重新投递到原 topic
+//
+// 用法:
+//
+//	dlq -config configs/processor.json list
+//	dlq -config configs/processor.json requeue -offsets 0:12,0:15
+//	dlq -config configs/processor.json requeue -all
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/services/processor/internal/consumer"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// idleTimeout 读取死信队列时，超过该时间没有新消息视为已读到末尾
+const idleTimeout = 3 * time.Second
+
+// entry 死信消息
+type entry struct {
+	msg         kafka.Message
+	sourceTopic string
+	err         string
+	attempts    string
+	failedAt    int64
+}
+
+func main() {
+	configPath := flag.String("config", "configs/processor.json", "配置文件路径")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v\n", err)
+	}
+	topic := cfg.Kafka.Consumer.DLQTopic
+	if topic == "" {
+		topic = constants.TopicMarketDLQ
+	}
+
+	switch flag.Arg(0) {
+	case "list":
+		err = list(cfg.Kafka.Brokers, topic, flag.Args()[1:])
+	case "requeue":
+		err = requeue(cfg.Kafka.Brokers, topic, flag.Args()[1:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("%s: %v\n", flag.Arg(0), err)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: dlq [-config path] <command> [options]\n\n")
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  list     列出死信队列中的消息（-values 同时输出消息内容）\n")
+	fmt.Fprintf(os.Stderr, "  requeue  将消息重新投递到原 topic（-offsets partition:offset,... 或 -all）\n")
+}
+
+// list 列出死信队列中的消息
+func list(brokers []string, topic string, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	values := fs.Bool("values", false, "输出消息内容")
+	fs.Parse(args)
+
+	entries, err := readAll(brokers, topic)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		fmt.Printf("%d:%d\t%s\tattempts=%s\tfailed_at=%s\tkey=%s\terror=%s\n",
+			e.msg.Partition, e.msg.Offset, e.sourceTopic, e.attempts,
+			time.UnixMilli(e.failedAt).Format(time.RFC3339), e.msg.Key, e.err)
+		if *values {
+			fmt.Printf("\t%s\n", e.msg.Value)
+		}
+	}
+	fmt.Printf("%d message(s) in %s\n", len(entries), topic)
+	return nil
+}
+
+// requeue 将指定的消息重新投递到原 topic，去除死信消息头
+// 死信队列中的消息不会删除，重复投递前请确认处理方已修复
+func requeue(brokers []string, topic string, args []string) error {
+	fs := flag.NewFlagSet("requeue", flag.ExitOnError)
+	offsets := fs.String("offsets", "", "要重新投递的消息，格式 partition:offset，多个用逗号分隔")
+	all := fs.Bool("all", false, "重新投递全部消息")
+	fs.Parse(args)
+
+	selected, err := parseOffsets(*offsets)
+	if err != nil {
+		return err
+	}
+	if !*all && len(selected) == 0 {
+		return errors.New("specify -offsets or -all")
+	}
+
+	entries, err := readAll(brokers, topic)
+	if err != nil {
+		return err
+	}
+
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+	}
+	defer writer.Close()
+
+	requeued := 0
+	for _, e := range entries {
+		if !*all && !selected[offsetKey(e.msg.Partition, e.msg.Offset)] {
+			continue
+		}
+		if e.sourceTopic == "" {
+			log.Printf("Skipped %d:%d: missing %s header\n", e.msg.Partition, e.msg.Offset, consumer.HeaderSourceTopic)
+			continue
+		}
+
+		headers := make([]kafka.Header, 0, len(e.msg.Headers))
+		for _, h := range e.msg.Headers {
+			if !consumer.IsDeadLetterHeader(h.Key) {
+				headers = append(headers, h)
+			}
+		}
+		msg := kafka.Message{Topic: e.sourceTopic, Key: e.msg.Key, Value: e.msg.Value, Headers: headers}
+		if err := writer.WriteMessages(context.Background(), msg); err != nil {
+			return fmt.Errorf("failed to requeue %d:%d: %w", e.msg.Partition, e.msg.Offset, err)
+		}
+		requeued++
+		fmt.Printf("Requeued %d:%d to %s\n", e.msg.Partition, e.msg.Offset, e.sourceTopic)
+	}
+	fmt.Printf("%d message(s) requeued\n", requeued)
+	return nil
+}
+
+// readAll 读取死信队列各分区的全部消息
+func readAll(brokers []string, topic string) ([]entry, error) {
+	if len(brokers) == 0 {
+		return nil, errors.New("no kafka brokers configured")
+	}
+
+	conn, err := kafka.Dial("tcp", brokers[0])
+	if err != nil {
+		return nil, err
+	}
+	partitions, err := conn.ReadPartitions(topic)
+	conn.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []entry
+	for _, partition := range partitions {
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:   brokers,
+			Topic:     topic,
+			Partition: partition.ID,
+			MaxWait:   time.Second,
+		})
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), idleTimeout)
+			msg, err := reader.ReadMessage(ctx)
+			cancel()
+			if err != nil {
+				break
+			}
+			entries = append(entries, parseEntry(msg))
+		}
+		reader.Close()
+	}
+	return entries, nil
+}
+
+// parseEntry 读取死信消息头
+func parseEntry(msg kafka.Message) entry {
+	e := entry{msg: msg}
+	for _, h := range msg.Headers {
+		switch h.Key {
+		case consumer.HeaderSourceTopic:
+			e.sourceTopic = string(h.Value)
+		case consumer.HeaderError:
+			e.err = string(h.Value)
+		case consumer.HeaderAttempts:
+			e.attempts = string(h.Value)
+		case consumer.HeaderFailedAt:
+			e.failedAt, _ = strconv.ParseInt(string(h.Value), 10, 64)
+		}
+	}
+	return e
+}
+
+// parseOffsets 解析 partition:offset 列表
+func parseOffsets(s string) (map[string]bool, error) {
+	selected := make(map[string]bool)
+	if s == "" {
+		return selected, nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		parts := strings.SplitN(strings.TrimSpace(item), ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid offset %q, expected partition:offset", item)
+		}
+		partition, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid partition in %q", item)
+		}
+		offset, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid offset in %q", item)
+		}
+		selected[offsetKey(partition, offset)] = true
+	}
+	return selected, nil
+}
+
+func offsetKey(partition int, offset int64) string {
+	return strconv.Itoa(partition) + ":" + strconv.FormatInt(offset, 10)
+}
+
+// loadConfig 加载配置文件
+func loadConfig(path string) (*config.ProcessorConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg config.ProcessorConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"market-system/common/constants"
+	"market-system/common/health"
+	"time"
+)
+
+// startHealth 启动探针服务：Kafka、Redis 可达且消费延迟未超过阈值时就绪
+func (p *Processor) startHealth() {
+	if p.config.Server.Port == 0 {
+		return
+	}
+
+	checker := health.New(constants.ServiceProcessor)
+	checker.SetTimeout(time.Duration(p.config.Health.TimeoutMs) * time.Millisecond)
+	checker.AddReadiness("kafka", health.KafkaCheck(p.config.Kafka.Brokers))
+	checker.AddReadiness("redis", p.storage.Ping)
+	if p.config.Health.MaxKafkaLag > 0 {
+		checker.AddReadiness("kafka_lag", p.checkKafkaLag(p.config.Health.MaxKafkaLag))
+	}
+
+	p.health = checker.Serve(fmt.Sprintf("%s:%d", p.config.Server.Host, p.config.Server.Port))
+}
+
+// checkKafkaLag 各 Topic 的消费延迟（消息数）不超过 maxLag
+// 按分区消费的 Topic 由消费组管理，不在检查范围内
+func (p *Processor) checkKafkaLag(maxLag int64) health.Check {
+	return func(ctx context.Context) error {
+		for topic, stat := range p.consumer.GetStats() {
+			if stat.Lag > maxLag {
+				return fmt.Errorf("%s lag %d exceeds %d", topic, stat.Lag, maxLag)
+			}
+		}
+		return nil
+	}
+}
@@ -4,16 +4,40 @@ import (
 	"context"
 	"encoding/json"
 	"flag"
+	"fmt"
 	"log"
 	"market-system/common/config"
 	"market-system/common/constants"
 	"market-system/common/models"
+	"market-system/common/sanitize"
+	"market-system/common/utils"
+	"market-system/common/version"
+	"market-system/services/processor/internal/aggtrade"
+	"market-system/services/processor/internal/anomaly"
+	"market-system/services/processor/internal/archive"
+	"market-system/services/processor/internal/backfill"
+	"market-system/services/processor/internal/bookstats"
+	"market-system/services/processor/internal/candle"
+	"market-system/services/processor/internal/consistency"
 	"market-system/services/processor/internal/consumer"
+	"market-system/services/processor/internal/dailytotals"
 	"market-system/services/processor/internal/handler"
+	"market-system/services/processor/internal/index"
+	"market-system/services/processor/internal/indicator"
+	"market-system/services/processor/internal/pipeline"
+	"market-system/services/processor/internal/pricealert"
+	"market-system/services/processor/internal/priceband"
+	"market-system/services/processor/internal/publisher"
+	"market-system/services/processor/internal/reference"
+	"market-system/services/processor/internal/registry"
+	"market-system/services/processor/internal/rolling"
 	"market-system/services/processor/internal/storage"
+	"market-system/services/processor/internal/tiering"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 var (
@@ -23,9 +47,38 @@ var (
 type Processor struct {
 	config        *config.ProcessorConfig
 	consumer      *consumer.KafkaConsumer
-	storage       *storage.RedisStorage
+	storage       *storage.RedisStorage        // 服务统计、K线推送等依赖 Redis 的功能
+	influx        *storage.InfluxStorage       // 为 nil 表示未启用 InfluxDB
+	postgres      *storage.PostgresStorage     // 为 nil 表示未启用 PostgreSQL
+	archiver      *archive.Archiver            // 为 nil 表示未启用归档
+	sink          *storage.FanoutStorage       // 行情数据写入（按配置启用的所有存储后端）
+	klineKafka    *publisher.KlinePublisher    // 为 nil 表示不发布K线到 Kafka
+	aggTradeKafka *publisher.AggTradePublisher // 为 nil 表示不发布聚合成交到 Kafka
+	alertKafka    *publisher.AlertPublisher    // 为 nil 表示不发布告警到 Kafka
 	klineHandler  *handler.KlineHandler
 	depthHandler  *handler.DepthHandler
+	pipeline      *pipeline.Dispatcher
+	tiering       *tiering.Manager        // 为 nil 表示不分级降频
+	twap          *reference.TWAP         // 为 nil 表示不计算参考价格
+	vwap          *reference.VWAP         // 为 nil 表示不计算成交量加权平均价格
+	index         *index.Calculator       // 为 nil 表示不计算指数价格
+	indicators    *indicator.Engine       // 为 nil 表示不计算技术指标
+	heikinAshi    *candle.HeikinAshi      // 为 nil 表示不计算平均K线
+	renko         *candle.Renko           // 为 nil 表示不生成砖形图
+	bookStats     *bookstats.Analyzer     // 为 nil 表示不计算订单簿统计
+	rolling       *rolling.Stats          // 为 nil 表示不由成交计算 24 小时滚动 Ticker
+	priceBands    *priceband.Bands        // 为 nil 表示不计算内部市场价格带
+	aggTrades     *aggtrade.Aggregator    // 为 nil 表示不生成聚合成交
+	anomalies     *anomaly.Detector       // 为 nil 表示不检测成交异常
+	priceAlerts   *pricealert.Engine      // 为 nil 表示不处理用户价格提醒
+	backfill      *backfill.Backfiller    // 为 nil 表示不回补历史K线
+	consistency   *consistency.Checker    // 为 nil 表示不检查K线一致性
+	tradeTotals   *dailytotals.Reconciler // 为 nil 表示不与撮合引擎对账
+	symbols       *registry.Registry      // 已软删除的交易对不再处理
+	partitions    *partitionSymbols       // 为 nil 表示不按分区消费
+	sanitizer     *sanitize.Sanitizer
+	rates         *utils.RateCounter // 按数据类型统计消息速率
+	health        *http.Server       // 存活/就绪检查，未配置端口时为 nil
 	ctx           context.Context
 	cancel        context.CancelFunc
 }
@@ -60,196 +113,846 @@ func main() {
 func NewProcessor(cfg *config.ProcessorConfig) (*Processor, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	// 初始化 Redis 存储
-	redisStorage, err := storage.NewRedisStorage(
-		cfg.Redis.Host,
-		cfg.Redis.Port,
-		cfg.Redis.Password,
-		cfg.Redis.DB,
-	)
+	// 按配置初始化存储后端（第一个为主存储，其余后端的写入错误相互隔离）
+	sink, err := storage.Open(cfg)
 	if err != nil {
 		cancel()
 		return nil, err
 	}
+	log.Printf("[Storage] Backends: %v\n", sink.Names())
 
-	// 初始化处理器
-	klineHandler := handler.NewKlineHandler(redisStorage)
-	depthHandler := handler.NewDepthHandler(redisStorage)
+	redisStorage, ok := sink.Backend(storage.BackendRedis).(*storage.RedisStorage)
+	if !ok {
+		cancel()
+		sink.Close()
+		return nil, fmt.Errorf("storage backend %s is required", storage.BackendRedis)
+	}
+	influxStorage, _ := sink.Backend(storage.BackendInfluxDB).(*storage.InfluxStorage)
+	postgresStorage, _ := sink.Backend(storage.BackendPostgres).(*storage.PostgresStorage)
+	archiver, _ := sink.Backend(storage.BackendArchive).(*archive.Archiver)
+
+	// 初始化处理器（按分区消费时未收盘的K线随分区保存和恢复，不在启停时整体保存）
+	var klineState handler.KlineStateStore = redisStorage
+	if cfg.Kafka.Consumer.PartitionAware {
+		klineState = nil
+	}
+	klineHandler := handler.NewKlineHandler(sink, klineState, cfg.Kline)
+	klinePublishers := handler.KlinePublishers{redisStorage}
+	var klineKafka *publisher.KlinePublisher
+	if cfg.Kafka.Producer.LiveKlines {
+		klineKafka = publisher.NewKlinePublisher(cfg.Kafka.Brokers)
+		klinePublishers = append(klinePublishers, klineKafka)
+	}
+	var indicators *indicator.Engine
+	if cfg.Indicator.Enable {
+		indicators = indicator.NewEngine(cfg.Indicator, redisStorage, redisStorage)
+		klinePublishers = append(klinePublishers, indicators)
+	}
+	var heikinAshi *candle.HeikinAshi
+	if cfg.Kline.HeikinAshi.Enable {
+		heikinAshi = candle.NewHeikinAshi(cfg.Kline.HeikinAshi, redisStorage)
+		klinePublishers = append(klinePublishers, heikinAshi)
+	}
+	var renko *candle.Renko
+	if cfg.Kline.Renko.Enable {
+		renko = candle.NewRenko(cfg.Kline.Renko, redisStorage)
+		klinePublishers = append(klinePublishers, renko)
+	}
+	klineHandler.SetPublisher(klinePublishers)
+	klineHandler.SetSourceStore(redisStorage)
+	klineHandler.SetHistory(redisStorage)
+	depthHandler := handler.NewDepthHandler(sink)
+	depthHandler.SetAggregation(cfg.DepthAggregation, redisStorage)
+	depthHandler.SetBookTicker(redisStorage)
+	var bookStats *bookstats.Analyzer
+	if cfg.BookStats.Enable {
+		bookStats = bookstats.NewAnalyzer(cfg.BookStats, redisStorage)
+		depthHandler.SetBookStats(bookStats)
+	}
 
 	// 初始化 Kafka 消费者
 	kafkaConsumer := consumer.NewKafkaConsumer(cfg.Kafka.Brokers, cfg.Kafka.Consumer.Group)
+	dlqTopic := cfg.Kafka.Consumer.DLQTopic
+	if dlqTopic == "" {
+		dlqTopic = constants.TopicMarketDLQ
+	}
+	kafkaConsumer.EnableDLQ(dlqTopic, cfg.Kafka.Consumer.UnknownSchemaToDLQ, cfg.Kafka.Consumer.PoisonToDLQ)
+	maxRetries := cfg.Kafka.Consumer.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+	kafkaConsumer.SetMaxRetries(maxRetries)
 
-	return &Processor{
-		config:       cfg,
-		consumer:     kafkaConsumer,
-		storage:      redisStorage,
-		klineHandler: klineHandler,
-		depthHandler: depthHandler,
-		ctx:          ctx,
-		cancel:       cancel,
-	}, nil
-}
+	// 初始化按交易对隔离的处理队列
+	dispatcher := pipeline.NewDispatcher(cfg.Pipeline.QueueSize, cfg.Pipeline.Shards)
 
-func (p *Processor) Start() error {
-	log.Println("Starting Market Data Processor...")
+	// 初始化活跃度分级
+	var tieringManager *tiering.Manager
+	if cfg.Tiering.Enable {
+		tieringManager = tiering.NewManager(cfg.Tiering)
+	}
 
-	// 订阅 Ticker Topic
-	p.consumer.Subscribe(constants.TopicMarketTicker, func(data *models.MarketData) error {
-		ticker, ok := data.Data.(map[string]interface{})
-		if !ok {
-			return nil
+	// 初始化参考价格计算
+	var twap *reference.TWAP
+	if cfg.TWAP.Enable {
+		twap = reference.NewTWAP(cfg.TWAP, redisStorage)
+	}
+	var vwap *reference.VWAP
+	if cfg.VWAP.Enable {
+		vwap = reference.NewVWAP(cfg.VWAP, redisStorage)
+	}
+
+	// 初始化指数价格计算
+	var indexCalculator *index.Calculator
+	if cfg.Index.Enable {
+		indexCalculator = index.NewCalculator(cfg.Index, redisStorage)
+	}
+
+	// 初始化 24 小时滚动 Ticker 计算
+	var rollingStats *rolling.Stats
+	if cfg.RollingTicker.Enable {
+		rollingStats = rolling.NewStats(cfg.RollingTicker, redisStorage, depthHandler, sink)
+		rollingStats.SetStatsSink(redisStorage)
+		rollingStats.SetStateStore(redisStorage)
+	}
+
+	// 初始化内部市场价格带计算
+	var priceBands *priceband.Bands
+	if cfg.PriceBand.Enable {
+		priceBands = priceband.NewBands(cfg.PriceBand, redisStorage, redisStorage)
+	}
+
+	// 初始化聚合成交生成
+	var aggTrades *aggtrade.Aggregator
+	var aggTradeKafka *publisher.AggTradePublisher
+	if cfg.AggTrade.Enable {
+		aggTradePublishers := aggtrade.Publishers{redisStorage}
+		if cfg.AggTrade.Kafka {
+			aggTradeKafka = publisher.NewAggTradePublisher(cfg.Kafka.Brokers)
+			aggTradePublishers = append(aggTradePublishers, aggTradeKafka)
 		}
+		aggTrades = aggtrade.NewAggregator(cfg.AggTrade, aggTradePublishers)
+	}
 
-		// 转换为 Ticker 对象
-		t := parseTickerFromMap(ticker, data.Symbol)
-		return p.storage.SaveTicker(t)
-	})
+	// 初始化异常检测
+	var anomalies *anomaly.Detector
+	var alertKafka *publisher.AlertPublisher
+	if cfg.Anomaly.Enable {
+		alertPublishers := anomaly.Publishers{redisStorage}
+		if cfg.Anomaly.Kafka {
+			alertKafka = publisher.NewAlertPublisher(cfg.Kafka.Brokers)
+			alertPublishers = append(alertPublishers, alertKafka)
+		}
+		anomalies = anomaly.NewDetector(cfg.Anomaly, alertPublishers)
+	}
 
-	// 订阅 Depth Topic
-	p.consumer.Subscribe(constants.TopicMarketDepth, func(data *models.MarketData) error {
-		depthMap, ok := data.Data.(map[string]interface{})
-		if !ok {
-			return nil
+	// 初始化用户价格提醒
+	var priceAlerts *pricealert.Engine
+	if cfg.PriceAlert.Enable {
+		priceAlerts, err = pricealert.NewEngine(cfg.PriceAlert, redisStorage, redisStorage)
+		if err != nil {
+			cancel()
+			sink.Close()
+			return nil, err
 		}
+	}
 
-		depth := parseDepthFromMap(depthMap, data.Symbol, data.Timestamp)
-		return p.depthHandler.HandleDepth(depth)
-	})
+	// 初始化历史K线回补
+	var backfiller *backfill.Backfiller
+	if cfg.Backfill.Enable {
+		backfiller, err = backfill.NewBackfiller(cfg.Backfill, sink, redisStorage, klineHandler, redisStorage.EnabledSymbols)
+		if err != nil {
+			cancel()
+			sink.Close()
+			return nil, err
+		}
+	}
 
-	// 订阅 Trade Topic
-	p.consumer.Subscribe(constants.TopicMarketTrade, func(data *models.MarketData) error {
-		tradeMap, ok := data.Data.(map[string]interface{})
-		if !ok {
-			return nil
+	// 初始化K线一致性检查
+	var checker *consistency.Checker
+	if cfg.Consistency.Enable {
+		checker = consistency.NewChecker(cfg.Consistency, redisStorage, redisStorage, klineHandler, sink, klinePublishers, redisStorage)
+	}
+
+	// 初始化撮合引擎成交对账
+	var tradeTotals *dailytotals.Reconciler
+	if cfg.TradeReconcile.Enable {
+		var engine dailytotals.Engine
+		if cfg.TradeReconcile.EngineURL != "" {
+			engine = dailytotals.NewHTTPEngine(cfg.TradeReconcile.EngineURL, time.Duration(cfg.TradeReconcile.TimeoutSec)*time.Second)
+		}
+		tradeTotals = dailytotals.NewReconciler(cfg.TradeReconcile, redisStorage, engine)
+	}
+
+	p := &Processor{
+		config:        cfg,
+		consumer:      kafkaConsumer,
+		storage:       redisStorage,
+		influx:        influxStorage,
+		postgres:      postgresStorage,
+		archiver:      archiver,
+		sink:          sink,
+		klineKafka:    klineKafka,
+		aggTradeKafka: aggTradeKafka,
+		alertKafka:    alertKafka,
+		klineHandler:  klineHandler,
+		depthHandler:  depthHandler,
+		pipeline:      dispatcher,
+		tiering:       tieringManager,
+		twap:          twap,
+		vwap:          vwap,
+		index:         indexCalculator,
+		indicators:    indicators,
+		heikinAshi:    heikinAshi,
+		renko:         renko,
+		bookStats:     bookStats,
+		rolling:       rollingStats,
+		priceBands:    priceBands,
+		aggTrades:     aggTrades,
+		anomalies:     anomalies,
+		priceAlerts:   priceAlerts,
+		backfill:      backfiller,
+		consistency:   checker,
+		tradeTotals:   tradeTotals,
+		sanitizer:     sanitize.New(sanitize.StageIngest),
+		rates:         utils.NewRateCounter(),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+	if cfg.Kafka.Consumer.PartitionAware {
+		p.partitions = newPartitionSymbols()
+	}
+	p.symbols = registry.New(redisStorage, p.removeSymbol)
+	return p, nil
+}
+
+func (p *Processor) Start() error {
+	log.Printf("Starting Market Data Processor (version %s)...\n", version.Version)
+
+	// 加载已软删除的交易对（先订阅再加载，避免加载期间的变更丢失）
+	updates := p.storage.Subscribe(p.ctx, constants.RedisChannelSymbolConfig)
+	if err := p.symbols.Load(); err != nil {
+		return err
+	}
+	go p.symbols.Run(updates)
+
+	// 加载需要计算滚动 Ticker 的交易对，之后随注册表变更重新加载
+	if p.rolling != nil {
+		rollingUpdates := p.storage.Subscribe(p.ctx, constants.RedisChannelSymbolConfig)
+		if err := p.rolling.Load(); err != nil {
+			return err
 		}
+		go p.rolling.Run(rollingUpdates, p.ctx.Done())
+	}
 
-		trade := parseTradeFromMap(tradeMap, data.Symbol)
+	// 加载需要计算价格带的交易对，之后随注册表变更重新加载
+	if p.priceBands != nil {
+		bandUpdates := p.storage.Subscribe(p.ctx, constants.RedisChannelSymbolConfig)
+		if err := p.priceBands.Load(); err != nil {
+			return err
+		}
+		go p.priceBands.Run(bandUpdates, p.ctx.Done())
+	}
 
-		// 保存交易数据
-		if err := p.storage.SaveTrade(trade); err != nil {
-			log.Printf("[Trade] Failed to save: %v\n", err)
+	// 加载用户价格提醒，之后随提醒变更重新加载
+	if p.priceAlerts != nil {
+		alertUpdates := p.storage.Subscribe(p.ctx, constants.RedisChannelPriceAlert)
+		if err := p.priceAlerts.Load(); err != nil {
+			return err
 		}
+		go p.priceAlerts.Run(alertUpdates, p.ctx.Done())
+	}
 
-		// 生成K线
-		return p.klineHandler.HandleTrade(trade)
-	})
+	// 回补历史K线（在开始消费前完成，避免与本地聚合的K线交错写入），之后响应管理接口触发的回补
+	if p.backfill != nil {
+		p.backfill.Run(&models.BackfillRequest{}, p.ctx.Done())
+		go p.backfill.Listen(p.storage.Subscribe(p.ctx, constants.RedisChannelBackfill), p.ctx.Done())
+	}
+
+	// 订阅 Ticker Topic
+	p.consumer.Subscribe(constants.TopicMarketTicker, p.dispatch(constants.TopicMarketTicker, p.handleTicker))
+
+	// 订阅 Depth Topic
+	p.consumer.Subscribe(constants.TopicMarketDepth, p.dispatch(constants.TopicMarketDepth, p.handleDepth))
+
+	if p.partitions != nil {
+		// 按分区订阅 Trade 和 Kline Topic，同一交易对的成交和K线由同一实例处理，分区转移时保存并恢复未收盘的K线
+		p.consumer.SubscribePartitioned(map[string]consumer.MessageHandler{
+			constants.TopicMarketTrade: p.track(p.dispatchEach(constants.TopicMarketTrade, p.newTradeHandler)),
+			constants.TopicMarketKline: p.dispatch(constants.TopicMarketKline, p.handleKline),
+		}, p)
+	} else {
+		// 订阅 Trade Topic
+		p.consumer.Subscribe(constants.TopicMarketTrade, p.dispatchEach(constants.TopicMarketTrade, p.newTradeHandler))
+
+		// 订阅 Kline Topic（交易所推送的K线，与本地聚合K线核对）
+		p.consumer.Subscribe(constants.TopicMarketKline, p.dispatch(constants.TopicMarketKline, p.handleKline))
+	}
 
 	// 启动消费
 	if err := p.consumer.Start(p.ctx); err != nil {
 		return err
 	}
 
+	// 启动按周期边界收盘K线（不活跃的交易对也能按时收盘）
+	go p.klineHandler.Run(p.ctx.Done())
+
+	// 启动活跃度分级和降频数据补发
+	if p.tiering != nil {
+		go p.tiering.Run(p.ctx.Done(), func(symbol string, task tiering.Task) {
+			if err := p.pipeline.Dispatch(p.ctx, symbol, pipeline.Task(task)); err != nil {
+				log.Printf("[Tiering] Failed to dispatch pending task for %s: %v\n", symbol, err)
+			}
+		})
+	}
+
+	// 启动参考价格采样和发布
+	if p.twap != nil {
+		go p.twap.Run(p.ctx.Done())
+	}
+	if p.vwap != nil {
+		go p.vwap.Run(p.ctx.Done())
+	}
+
+	// 启动指数价格计算和发布
+	if p.index != nil {
+		go p.index.Run(p.ctx.Done())
+	}
+
+	// 启动聚合成交按窗口发布
+	if p.aggTrades != nil {
+		go p.aggTrades.Run(p.ctx.Done())
+	}
+
+	// 启动K线一致性检查
+	if p.consistency != nil {
+		go p.consistency.Run(p.ctx.Done())
+	}
+
+	// 启动成交汇总写入和每日对账
+	if p.tradeTotals != nil {
+		go p.tradeTotals.Run(p.ctx.Done())
+	}
+
+	// 启动队列统计输出
+	go p.printStats()
+
+	// 启动存活/就绪检查
+	p.startHealth()
+
 	log.Println("Processor started successfully!")
 	return nil
 }
 
-func (p *Processor) Stop() {
-	log.Println("Stopping processor...")
-
-	// 取消上下文
-	p.cancel()
+// dispatch 将消息投递到交易对所属的处理队列，实际处理在队列 worker 中异步执行
+// 队列已满时阻塞消费直到入队；处理失败时在队列中重试，重试后仍失败的消息转入死信队列
+func (p *Processor) dispatch(topic string, handle consumer.MessageHandler) consumer.MessageHandler {
+	return p.dispatchEach(topic, func() consumer.MessageHandler { return handle })
+}
 
-	// 关闭消费者
-	if p.consumer != nil {
-		p.consumer.Close()
+// dispatchEach 与 dispatch 相同，每条消息使用 newHandler 创建的处理函数，重试时复用，用于在重试之间保留处理进度
+func (p *Processor) dispatchEach(topic string, newHandler func() consumer.MessageHandler) consumer.MessageHandler {
+	return func(data *models.MarketMessage) error {
+		p.rates.Inc(data.Type)
+		if p.symbols.IsDeleted(data.Symbol) {
+			return nil
+		}
+		handle := newHandler()
+		return p.pipeline.Dispatch(p.ctx, data.Symbol, func() error {
+			return p.consumer.Handle(topic, data, handle)
+		})
 	}
+}
 
-	// 关闭存储
-	if p.storage != nil {
-		p.storage.Close()
+// track 记录成交消息所在的分区，分区收回时保存其中交易对的聚合状态
+func (p *Processor) track(handle consumer.MessageHandler) consumer.MessageHandler {
+	return func(data *models.MarketMessage) error {
+		p.partitions.add(data.Partition, data.Symbol)
+		return handle(data)
 	}
-
-	log.Println("Processor stopped")
 }
 
-// parseTickerFromMap 从 map 解析 Ticker
-func parseTickerFromMap(data map[string]interface{}, symbol string) *models.Ticker {
-	return &models.Ticker{
-		Symbol:    symbol,
-		LastPrice: getFloat(data, "last_price"),
-		BidPrice:  getFloat(data, "bid_price"),
-		AskPrice:  getFloat(data, "ask_price"),
-		High24h:   getFloat(data, "high_24h"),
-		Low24h:    getFloat(data, "low_24h"),
-		Volume24h: getFloat(data, "volume_24h"),
-		Timestamp: getInt64(data, "timestamp"),
+// removeSymbol 交易对被软删除后丢弃其聚合状态，经处理队列执行，排在已投递的该交易对消息之后
+func (p *Processor) removeSymbol(symbol string) {
+	err := p.pipeline.Dispatch(p.ctx, symbol, func() error {
+		p.releaseSymbol(symbol)
+		return nil
+	})
+	if err != nil {
+		log.Printf("[Registry] Failed to dispatch removal of %s: %v\n", symbol, err)
 	}
 }
 
-// parseDepthFromMap 从 map 解析深度
-func parseDepthFromMap(data map[string]interface{}, symbol string, timestamp int64) *models.OrderBook {
-	return &models.OrderBook{
-		Symbol:    symbol,
-		Bids:      parsePriceLevels(data["bids"]),
-		Asks:      parsePriceLevels(data["asks"]),
-		Timestamp: timestamp,
+// throttle 按交易对活跃度降频，返回 true 表示立即处理
+// 被降频的数据暂存为待处理任务，到期后重新投递到交易对所属的处理队列
+func (p *Processor) throttle(symbol, dataType string, task func() error) bool {
+	if p.tiering == nil {
+		return true
 	}
+	return p.tiering.Submit(symbol, dataType, task)
 }
 
-// parseTradeFromMap 从 map 解析交易
-func parseTradeFromMap(data map[string]interface{}, symbol string) *models.Trade {
-	return &models.Trade{
-		Symbol:    symbol,
-		TradeID:   getString(data, "trade_id"),
-		Price:     getFloat(data, "price"),
-		Amount:    getFloat(data, "amount"),
-		Side:      getString(data, "side"),
-		Timestamp: getInt64(data, "timestamp"),
+// handleTicker 处理 Ticker 消息
+func (p *Processor) handleTicker(data *models.MarketMessage) error {
+	t := &models.Ticker{}
+	if err := data.Decode(t); err != nil {
+		return fmt.Errorf("failed to decode ticker: %w", err)
+	}
+	t.Symbol = data.Symbol
+	fillTickerChange(t)
+
+	if !p.sanitizer.Ticker(data.Exchange, t) {
+		return nil
+	}
+
+	// 指数价格只使用各外部交易所自己的最新价，内部和融合后的 Ticker 不参与计算
+	if p.index != nil && data.Source != constants.SourceInternal && data.Source != constants.SourceMerged {
+		p.index.RecordTicker(data.Exchange, t)
+	}
+
+	sourced, err := tickerWithSource(data, t)
+	if err != nil {
+		return fmt.Errorf("failed to decode ticker sources: %w", err)
+	}
+
+	save := func() error {
+		if err := p.sink.SaveTicker(t); err != nil {
+			return err
+		}
+		if p.priceAlerts != nil {
+			p.priceAlerts.RecordTicker(t)
+		}
+		return p.storage.SaveTickerSource(sourced)
 	}
+	if !p.throttle(t.Symbol, constants.DataTypeTicker, save) {
+		return nil
+	}
+	return save()
 }
 
-// 辅助函数
-func getFloat(m map[string]interface{}, key string) float64 {
-	if v, ok := m[key]; ok {
-		if f, ok := v.(float64); ok {
-			return f
+// handleDepth 处理深度消息
+func (p *Processor) handleDepth(data *models.MarketMessage) error {
+	depth := &models.OrderBook{}
+	if err := data.Decode(depth); err != nil {
+		return fmt.Errorf("failed to decode depth: %w", err)
+	}
+	depth.Symbol = data.Symbol
+	depth.Timestamp = data.Timestamp
+	if depth.Bids == nil {
+		depth.Bids = []models.PriceLevel{}
+	}
+	if depth.Asks == nil {
+		depth.Asks = []models.PriceLevel{}
+	}
+	p.sanitizer.OrderBook(data.Exchange, depth)
+
+	// 融合深度同时保存各档位的来源，单一来源的深度不重复保存
+	var sourced *models.OrderBookWithSource
+	if data.Source == constants.SourceMerged {
+		sourced = &models.OrderBookWithSource{}
+		if err := data.Decode(sourced); err != nil {
+			return fmt.Errorf("failed to decode depth sources: %w", err)
+		}
+		sourced.Symbol = data.Symbol
+		sourced.Timestamp = data.Timestamp
+	}
+
+	handle := func() error {
+		if err := p.depthHandler.HandleDepth(depth); err != nil {
+			return err
+		}
+		if sourced == nil {
+			return nil
 		}
+		return p.storage.SaveDepthSource(sourced)
 	}
-	return 0
+	if !p.throttle(depth.Symbol, constants.DataTypeDepth, handle) {
+		return nil
+	}
+	return handle()
 }
 
-func getInt64(m map[string]interface{}, key string) int64 {
-	if v, ok := m[key]; ok {
-		if i, ok := v.(float64); ok {
-			return int64(i)
+// newTradeHandler 创建单条成交消息的处理函数
+// 成交只计入参考价格、滚动 Ticker 等统计和保存一次，失败重试时只重新生成K线，避免重复计入
+func (p *Processor) newTradeHandler() consumer.MessageHandler {
+	var (
+		trade    *models.Trade
+		recorded bool
+	)
+	return func(data *models.MarketMessage) error {
+		if !recorded {
+			t, err := p.decodeTrade(data)
+			if err != nil {
+				return err
+			}
+			// 先标记再记录，记录过程中 panic 时重试也不会重复计入
+			recorded = true
+			if t == nil {
+				return nil
+			}
+			trade = t
+			p.recordTrade(trade)
+		}
+		if trade == nil {
+			return nil
 		}
+
+		// 生成K线
+		return p.klineHandler.HandleTrade(trade)
+	}
+}
+
+// decodeTrade 解析并校验成交消息，被过滤的成交返回 nil
+func (p *Processor) decodeTrade(data *models.MarketMessage) (*models.Trade, error) {
+	trade := &models.Trade{}
+	if err := data.Decode(trade); err != nil {
+		return nil, fmt.Errorf("failed to decode trade: %w", err)
+	}
+	trade.Symbol = data.Symbol
+	trade.Source = data.Source
+	trade.Exchange = data.Exchange
+
+	if !p.sanitizer.Trade(data.Exchange, trade) {
+		return nil, nil
+	}
+	return trade, nil
+}
+
+// recordTrade 将成交计入各统计并保存
+func (p *Processor) recordTrade(trade *models.Trade) {
+	if p.tiering != nil {
+		p.tiering.RecordTrade(trade.Symbol)
+	}
+	if p.twap != nil {
+		p.twap.RecordTrade(trade)
+	}
+	if p.vwap != nil {
+		p.vwap.RecordTrade(trade)
+	}
+	if p.rolling != nil {
+		p.rolling.RecordTrade(trade)
+	}
+	if p.priceBands != nil {
+		p.priceBands.RecordTrade(trade)
+	}
+	if p.aggTrades != nil {
+		p.aggTrades.RecordTrade(trade)
+	}
+	if p.anomalies != nil {
+		p.anomalies.RecordTrade(trade)
+	}
+	if p.tradeTotals != nil {
+		p.tradeTotals.RecordTrade(trade)
+	}
+
+	// 保存交易数据
+	if err := p.sink.SaveTrade(trade); err != nil {
+		log.Printf("[Trade] Failed to save: %v\n", err)
 	}
-	return 0
 }
 
-func getString(m map[string]interface{}, key string) string {
-	if v, ok := m[key]; ok {
-		if s, ok := v.(string); ok {
-			return s
+// handleKline 处理交易所推送的K线
+func (p *Processor) handleKline(data *models.MarketMessage) error {
+	// 本服务发布的本地聚合K线，不需要处理
+	if data.Source == constants.KlineSourceLocal {
+		return nil
+	}
+
+	kline := &models.Kline{}
+	if err := data.Decode(kline); err != nil {
+		return fmt.Errorf("failed to decode kline: %w", err)
+	}
+	kline.Symbol = data.Symbol
+	kline.Source = data.Exchange
+	if !p.sanitizer.Kline(data.Exchange, kline) {
+		return nil
+	}
+
+	return p.klineHandler.HandleKline(kline)
+}
+
+// printStats 定期打印处理队列统计信息
+func (p *Processor) printStats() {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			p.reportStats()
+
+			stats := p.pipeline.Stats()
+			log.Println("=== Pipeline Stats ===")
+			for name, stat := range stats {
+				log.Printf("[%s] Queue: %d/%d, Processed: %d, Blocked: %d, Dropped: %d, Failed: %d, Panics: %d, Latency: %v (max %v)\n",
+					name, stat.Length, stat.Capacity, stat.Processed, stat.Blocked, stat.Dropped, stat.Failed, stat.Panics,
+					stat.LastLatency, stat.MaxLatency)
+			}
+
+			for source, stat := range p.sanitizer.Stats() {
+				log.Printf("[Sanitize][%s] Rejected: %d, Clamped: %d\n", source, stat.Rejected, stat.Clamped)
+			}
+			for source, stat := range p.storage.SanitizeStats() {
+				log.Printf("[Sanitize][storage:%s] Rejected: %d, Clamped: %d\n", source, stat.Rejected, stat.Clamped)
+			}
+
+			if p.tiering != nil {
+				stat := p.tiering.Stats()
+				log.Printf("[Tiering] Hot: %d, Cold: %d, Throttled: hot=%d cold=%d\n",
+					stat.Hot, stat.Cold, stat.Throttled[tiering.TierHot], stat.Throttled[tiering.TierCold])
+			}
+
+			if p.twap != nil {
+				log.Printf("[TWAP] Symbols: %d\n", p.twap.SymbolCount())
+			}
+
+			if p.vwap != nil {
+				log.Printf("[VWAP] Symbols: %d\n", p.vwap.SymbolCount())
+			}
+
+			if p.index != nil {
+				log.Printf("[Index] Symbols: %d\n", p.index.SymbolCount())
+			}
+
+			if p.indicators != nil {
+				log.Printf("[Indicator] Series: %d\n", p.indicators.SeriesCount())
+			}
+
+			if p.heikinAshi != nil {
+				log.Printf("[HeikinAshi] Series: %d\n", p.heikinAshi.SeriesCount())
+			}
+
+			if p.renko != nil {
+				log.Printf("[Renko] Series: %d\n", p.renko.SeriesCount())
+			}
+
+			if p.bookStats != nil {
+				log.Printf("[BookStats] Symbols: %d\n", p.bookStats.SymbolCount())
+			}
+
+			if p.rolling != nil {
+				log.Printf("[RollingTicker] Symbols: %d\n", p.rolling.SymbolCount())
+			}
+
+			if p.priceBands != nil {
+				log.Printf("[PriceBand] Symbols: %d\n", p.priceBands.SymbolCount())
+			}
+
+			if p.aggTrades != nil {
+				log.Printf("[AggTrade] Published: %d\n", p.aggTrades.Count())
+			}
+
+			if p.anomalies != nil {
+				log.Printf("[Anomaly] Symbols: %d, alerts: %d\n", p.anomalies.SymbolCount(), p.anomalies.Count())
+			}
+
+			if p.priceAlerts != nil {
+				log.Printf("[PriceAlert] Alerts: %d, triggered: %d\n", p.priceAlerts.AlertCount(), p.priceAlerts.Count())
+			}
+
+			log.Printf("[Registry] Deleted symbols: %d\n", p.symbols.DeletedCount())
+
+			if p.partitions != nil {
+				log.Printf("[Kafka Consumer] Assigned partitions: %v\n", p.consumer.AssignedPartitions())
+			}
+
+			poison := p.consumer.PoisonStats()
+			if poison.Retries > 0 || poison.DeadLettered > 0 || poison.Discarded > 0 {
+				log.Printf("[Kafka Consumer] Retries: %d, Dead-lettered: %d, Discarded: %d\n",
+					poison.Retries, poison.DeadLettered, poison.Discarded)
+			}
+
+			versions := p.consumer.VersionStats()
+			if len(versions.Mismatched) > 0 || versions.Unversioned > 0 || versions.UnknownSchema > 0 {
+				log.Printf("[Kafka Consumer] Version mismatched: %v, Unversioned: %d, Unknown schema: %d, Dead-lettered: %d\n",
+					versions.Mismatched, versions.Unversioned, versions.UnknownSchema, versions.DeadLettered)
+			}
+
+			if p.backfill != nil {
+				stat := p.backfill.Stats()
+				log.Printf("[Backfill] Runs: %d, Klines: %d, Errors: %d\n", stat.Runs, stat.Klines, stat.Errors)
+			}
+
+			if p.consistency != nil {
+				stat := p.consistency.Stats()
+				log.Printf("[Consistency] Runs: %d, Compared: %d, Mismatched: %d, Missing: %d, Repaired: %d\n",
+					stat.Runs, stat.Compared, stat.Mismatched, stat.Missing, stat.Repaired)
+			}
+
+			if p.tradeTotals != nil {
+				stat := p.tradeTotals.Stats()
+				log.Printf("[DailyTotals] Trades: %d, Runs: %d, Mismatched: %d, Errors: %d\n",
+					stat.Trades, stat.Runs, stat.Mismatched, stat.Errors)
+			}
+
+			reconcile := p.klineHandler.ReconcileStats()
+			log.Printf("[Kline] Reconcile: Matched: %d, Mismatched: %d, Filled: %d\n",
+				reconcile.Matched, reconcile.Mismatched, reconcile.Filled)
+
+			if p.influx != nil {
+				stat := p.influx.Stats()
+				log.Printf("[InfluxDB] Written: %d, Failed: %d, Dropped: %d, Pending: %d\n",
+					stat.Written, stat.Failed, stat.Dropped, stat.Pending)
+			}
+
+			if p.postgres != nil {
+				stat := p.postgres.Stats()
+				log.Printf("[Postgres] Trades: %d, Klines: %d, Failed: %d, Dropped: %d\n",
+					stat.Trades, stat.Klines, stat.Failed, stat.Dropped)
+			}
+
+			if p.archiver != nil {
+				stat := p.archiver.Stats()
+				log.Printf("[Archive] Files: %d, Rows: %d, Upload failures: %d, Pending uploads: %d\n",
+					stat.Files, stat.Rows, stat.UploadFailures, stat.PendingUploads)
+			}
+
+			if stat, ok := p.storage.TradeBufferStats(); ok {
+				log.Printf("[Redis] Trade buffer: Buffered: %d, Written: %d, Trimmed: %d, Batches: %d, Failed: %d, Pending: %d\n",
+					stat.Buffered, stat.Written, stat.Trimmed, stat.Batches, stat.Failed, stat.Pending)
+			}
+
+			for name, stat := range p.sink.Stats() {
+				if stat.Errors > 0 || stat.Panics > 0 {
+					log.Printf("[Storage][%s] Errors: %d, Panics: %d\n", name, stat.Errors, stat.Panics)
+				}
+			}
 		}
 	}
-	return ""
 }
 
-func parsePriceLevels(v interface{}) []models.PriceLevel {
-	if v == nil {
-		return []models.PriceLevel{}
+// reportStats 将运行统计（消息速率、Kafka 消费延迟）写入 Redis，供 API 汇总展示
+func (p *Processor) reportStats() {
+	lag := make(map[string]int64)
+	for topic, stat := range p.consumer.GetStats() {
+		lag[topic] = stat.Lag
 	}
 
-	arr, ok := v.([]interface{})
-	if !ok {
-		return []models.PriceLevel{}
+	stats := &models.ServiceStats{
+		Service:      constants.ServiceProcessor,
+		Timestamp:    utils.GetCurrentTimestamp(),
+		MessageRates: p.rates.Rates(),
+		KafkaLag:     lag,
+	}
+	if err := p.storage.SaveServiceStats(stats); err != nil {
+		log.Printf("[Stats] Failed to report stats: %v\n", err)
 	}
 
-	levels := make([]models.PriceLevel, 0, len(arr))
-	for _, item := range arr {
-		levelMap, ok := item.(map[string]interface{})
-		if !ok {
-			continue
+	// 价格提醒的 Webhook 推送统计，与 webhook 服务的统计一起通过管理接口查看
+	if p.priceAlerts != nil {
+		if err := p.storage.SavePriceAlertWebhookStats(p.priceAlerts.WebhookStats()); err != nil {
+			log.Printf("[Stats] Failed to report price alert webhook stats: %v\n", err)
 		}
+	}
+}
 
-		levels = append(levels, models.PriceLevel{
-			Price:  getFloat(levelMap, "price"),
-			Amount: getFloat(levelMap, "amount"),
-		})
+func (p *Processor) Stop() {
+	log.Println("Stopping processor...")
+
+	if p.health != nil {
+		p.health.Close()
 	}
 
-	return levels
+	// 取消上下文
+	p.cancel()
+
+	// 关闭消费者（按分区消费时等待各分区保存未收盘的K线）
+	if p.consumer != nil {
+		p.consumer.Close()
+	}
+
+	// 等待处理队列中的消息处理完成
+	if p.pipeline != nil {
+		p.pipeline.Stop()
+	}
+
+	// 保存未收盘的K线，重启后恢复
+	if p.klineHandler != nil {
+		p.klineHandler.Stop()
+	}
+
+	// 保存滚动 Ticker 的分桶状态，重启后恢复
+	if p.rolling != nil {
+		p.rolling.Stop()
+	}
+
+	if p.klineKafka != nil {
+		p.klineKafka.Close()
+	}
+
+	// 发布正在聚合的成交
+	if p.aggTrades != nil {
+		p.aggTrades.Stop()
+	}
+	if p.aggTradeKafka != nil {
+		p.aggTradeKafka.Close()
+	}
+	if p.alertKafka != nil {
+		p.alertKafka.Close()
+	}
+
+	// 写入累计的成交汇总
+	if p.tradeTotals != nil {
+		p.tradeTotals.Stop()
+	}
+
+	// 关闭存储（历史存储关闭前写入缓冲区中剩余的数据）
+	if p.sink != nil {
+		p.sink.Close()
+	}
+
+	log.Println("Processor stopped")
+}
+
+// fillTickerChange 交易所只提供开盘价时（如 OKX）按最新价计算涨跌额和涨跌幅
+func fillTickerChange(ticker *models.Ticker) {
+	if ticker.Open24h > 0 && ticker.PriceChange24h == 0 && ticker.PriceChangePercent24h == 0 {
+		ticker.PriceChange24h = ticker.LastPrice - ticker.Open24h
+		ticker.PriceChangePercent24h = utils.CalculateChange24h(ticker.LastPrice, ticker.Open24h)
+	}
+}
+
+// tickerWithSource 带来源的 Ticker：融合数据解析来源明细，单一来源的数据成交量全部计入该来源
+// 融合 Ticker 只有分来源的成交量，按总成交量补全 Ticker 的 24 小时成交量
+func tickerWithSource(data *models.MarketMessage, t *models.Ticker) (*models.TickerWithSource, error) {
+	if data.Source == constants.SourceMerged {
+		sourced := &models.TickerWithSource{}
+		if err := data.Decode(sourced); err != nil {
+			return nil, err
+		}
+		sourced.Symbol = t.Symbol
+		sourced.PriceChange24h = t.PriceChange24h
+		sourced.PriceChangePercent24h = t.PriceChangePercent24h
+		if t.Volume24h == 0 {
+			t.Volume24h = sourced.TotalVolume24h
+		}
+		return sourced, nil
+	}
+
+	sourced := &models.TickerWithSource{
+		Symbol:                t.Symbol,
+		LastPrice:             t.LastPrice,
+		LastPriceSource:       data.Source,
+		BidPrice:              t.BidPrice,
+		AskPrice:              t.AskPrice,
+		High24h:               t.High24h,
+		Low24h:                t.Low24h,
+		Open24h:               t.Open24h,
+		PriceChange24h:        t.PriceChange24h,
+		PriceChangePercent24h: t.PriceChangePercent24h,
+		TradeCount24h:         t.TradeCount24h,
+		TotalVolume24h:        t.Volume24h,
+		Timestamp:             t.Timestamp,
+	}
+	if data.Source == constants.SourceInternal {
+		sourced.InternalVolume24h = t.Volume24h
+	} else {
+		sourced.LastPriceSource = constants.SourceExternal
+		sourced.ExternalVolume24h = t.Volume24h
+	}
+	return sourced, nil
 }
 
 // loadConfig 加载配置文件
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"log"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"sync"
+)
+
+// partitionSymbols 按分区记录本实例处理过的交易对，分区收回时据此保存并丢弃聚合状态
+type partitionSymbols struct {
+	mu      sync.Mutex
+	symbols map[int]map[string]bool
+}
+
+func newPartitionSymbols() *partitionSymbols {
+	return &partitionSymbols{symbols: make(map[int]map[string]bool)}
+}
+
+// add 记录分区中的交易对
+func (ps *partitionSymbols) add(partition int, symbol string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	symbols, ok := ps.symbols[partition]
+	if !ok {
+		symbols = make(map[string]bool)
+		ps.symbols[partition] = symbols
+	}
+	symbols[symbol] = true
+}
+
+// take 取出并清空分区中的交易对
+func (ps *partitionSymbols) take(partition int) []string {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	symbols := make([]string, 0, len(ps.symbols[partition]))
+	for symbol := range ps.symbols[partition] {
+		symbols = append(symbols, symbol)
+	}
+	delete(ps.symbols, partition)
+	return symbols
+}
+
+// PartitionAssigned 成交分区分配到本实例时恢复上一个持有者保存的未收盘K线和滚动 Ticker 的分桶，返回保存时的消费位置
+// 已提交位置比保存的位置新时，说明保存的状态已过期（之后又有实例处理过该分区），丢弃不用
+func (p *Processor) PartitionAssigned(topic string, partition int, committed int64) int64 {
+	if topic != constants.TopicMarketTrade {
+		return -1
+	}
+
+	// 分桶状态与K线一起保存，读取后删除，是否使用以K线保存的消费位置为准
+	var rolling map[string]string
+	if p.rolling != nil {
+		states, err := p.storage.LoadPartitionRollingState(partition)
+		if err != nil {
+			log.Printf("[Rebalance] Failed to load rolling state of partition %d: %v\n", partition, err)
+		}
+		rolling = states
+	}
+
+	klines, next, err := p.storage.LoadPartitionKlines(partition)
+	if err != nil {
+		log.Printf("[Rebalance] Failed to load partition %d: %v\n", partition, err)
+		return -1
+	}
+	if next < 0 {
+		return -1
+	}
+	if next < committed {
+		log.Printf("[Rebalance] Discarded stale state of partition %d (saved at %d, committed %d)\n", partition, next, committed)
+		return -1
+	}
+
+	restored := p.klineHandler.Restore(klines)
+	for _, kline := range klines {
+		p.partitions.add(partition, kline.Symbol)
+	}
+	windows := 0
+	if p.rolling != nil {
+		windows = p.rolling.Restore(rolling)
+		for symbol := range rolling {
+			p.partitions.add(partition, symbol)
+		}
+	}
+	log.Printf("[Rebalance] Partition %d assigned: restored %d current klines, %d rolling windows, resuming at %d\n",
+		partition, restored, windows, next)
+	return next
+}
+
+// PartitionRevoked 成交分区收回时，在各交易对的处理队列中排在已投递的消息之后丢弃聚合状态，
+// 等待完成后保存未收盘的K线和消费位置，供新的持有者恢复
+// 回调在该分区的消费 goroutine 中执行，此时不会再投递该分区的成交，丢弃之后队列中不会再有该分区交易对的成交任务；
+// 队列已满时阻塞等待入队，聚合状态只在交易对的 worker 中丢弃，不与处理中的成交并发
+// 滚动 Ticker 的分钟分桶与K线一起保存；参考价格和价格带的状态不保存，由新的持有者从之后的成交重新计算
+func (p *Processor) PartitionRevoked(topic string, partition int, next int64) {
+	if topic != constants.TopicMarketTrade {
+		return
+	}
+
+	var (
+		mu      sync.Mutex
+		klines  []*models.Kline
+		rolling = make(map[string]string)
+		wg      sync.WaitGroup
+		failed  []string
+	)
+	for _, symbol := range p.partitions.take(partition) {
+		symbol := symbol
+		wg.Add(1)
+		// 停止时上下文已取消，使用独立的上下文等待入队；处理队列在消费者关闭后才停止
+		err := p.pipeline.Dispatch(context.Background(), symbol, func() error {
+			defer wg.Done()
+			var state string
+			if p.rolling != nil {
+				state = p.rolling.SymbolState(symbol)
+			}
+			released := p.releaseSymbol(symbol)
+			mu.Lock()
+			klines = append(klines, released...)
+			if state != "" {
+				rolling[symbol] = state
+			}
+			mu.Unlock()
+			return nil
+		})
+		if err != nil {
+			wg.Done()
+			failed = append(failed, symbol)
+		}
+	}
+	wg.Wait()
+
+	// 有交易对的状态未能丢弃时保存的状态不完整，不保存，新的持有者从已提交位置重新计算
+	if len(failed) > 0 {
+		log.Printf("[Rebalance] Partition %d revoked: failed to release %v, state not saved\n", partition, failed)
+		return
+	}
+
+	if p.rolling != nil {
+		if err := p.storage.SavePartitionRollingState(partition, rolling); err != nil {
+			log.Printf("[Rebalance] Failed to save rolling state of partition %d: %v\n", partition, err)
+		}
+	}
+	if err := p.storage.SavePartitionKlines(partition, next, klines); err != nil {
+		log.Printf("[Rebalance] Failed to save partition %d: %v\n", partition, err)
+		return
+	}
+	log.Printf("[Rebalance] Partition %d revoked: saved %d current klines, %d rolling windows at %d\n",
+		partition, len(klines), len(rolling), next)
+}
+
+// releaseSymbol 丢弃交易对的聚合状态，返回未收盘的K线
+func (p *Processor) releaseSymbol(symbol string) []*models.Kline {
+	klines := p.klineHandler.RemoveSymbol(symbol)
+	if p.twap != nil {
+		p.twap.RemoveSymbol(symbol)
+	}
+	if p.vwap != nil {
+		p.vwap.RemoveSymbol(symbol)
+	}
+	if p.index != nil {
+		p.index.RemoveSymbol(symbol)
+	}
+	if p.indicators != nil {
+		p.indicators.RemoveSymbol(symbol)
+	}
+	if p.heikinAshi != nil {
+		p.heikinAshi.RemoveSymbol(symbol)
+	}
+	if p.renko != nil {
+		p.renko.RemoveSymbol(symbol)
+	}
+	if p.bookStats != nil {
+		p.bookStats.RemoveSymbol(symbol)
+	}
+	if p.rolling != nil {
+		p.rolling.RemoveSymbol(symbol)
+	}
+	if p.priceBands != nil {
+		p.priceBands.RemoveSymbol(symbol)
+	}
+	if p.aggTrades != nil {
+		p.aggTrades.RemoveSymbol(symbol)
+	}
+	if p.anomalies != nil {
+		p.anomalies.RemoveSymbol(symbol)
+	}
+	if p.priceAlerts != nil {
+		p.priceAlerts.RemoveSymbol(symbol)
+	}
+	return klines
+}
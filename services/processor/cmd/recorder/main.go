@@ -0,0 +1,158 @@
+// recorder 将 Kafka 中的行情消息录制到本地分段文件，供 replay 命令回放（回测、问题复现）
+//
+// 以独立的消费组读取，不影响 Processor 的消费；消费位置在消息写入磁盘后提交，
+// 进程异常退出后重启可能重复录制少量消息。
+//
+// 用法:
+//
+//	recorder -config configs/processor.json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"market-system/common/config"
+	"market-system/services/processor/internal/recording"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// defaultGroup 默认消费组
+const defaultGroup = "market-recorder"
+
+// statsInterval 输出录制统计的间隔
+const statsInterval = time.Minute
+
+func main() {
+	configPath := flag.String("config", "configs/processor.json", "配置文件路径")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v\n", err)
+	}
+
+	topics := cfg.Recorder.Topics
+	if len(topics) == 0 {
+		for _, topic := range []string{cfg.Kafka.Topics.Ticker, cfg.Kafka.Topics.Depth, cfg.Kafka.Topics.Trade, cfg.Kafka.Topics.Kline} {
+			if topic != "" {
+				topics = append(topics, topic)
+			}
+		}
+	}
+	if len(topics) == 0 {
+		log.Fatalf("No topics to record\n")
+	}
+	group := cfg.Recorder.Group
+	if group == "" {
+		group = defaultGroup
+	}
+	flushInterval := time.Duration(cfg.Recorder.FlushIntervalMs) * time.Millisecond
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
+
+	writer, err := recording.NewWriter(cfg.Recorder)
+	if err != nil {
+		log.Fatalf("Failed to create writer: %v\n", err)
+	}
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     cfg.Kafka.Brokers,
+		GroupID:     group,
+		GroupTopics: topics,
+		MinBytes:    1,
+		MaxBytes:    10e6,
+		MaxWait:     500 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	messages := make(chan kafka.Message, 1024)
+	go func() {
+		defer close(messages)
+		for {
+			msg, err := reader.FetchMessage(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("[Recorder] Failed to fetch message: %v\n", err)
+				}
+				return
+			}
+			messages <- msg
+		}
+	}()
+	log.Printf("[Recorder] Recording %v to %s (group %s)\n", topics, writer.Dir(), group)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	flushTicker := time.NewTicker(flushInterval)
+	defer flushTicker.Stop()
+	statsTicker := time.NewTicker(statsInterval)
+	defer statsTicker.Stop()
+
+	var pending []kafka.Message
+	flush := func() {
+		if err := writer.Flush(); err != nil {
+			log.Printf("[Recorder] Failed to flush segment: %v\n", err)
+			return
+		}
+		if len(pending) == 0 {
+			return
+		}
+		if err := reader.CommitMessages(context.Background(), pending...); err != nil {
+			log.Printf("[Recorder] Failed to commit offsets: %v\n", err)
+		}
+		pending = pending[:0]
+	}
+
+loop:
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				break loop
+			}
+			if err := writer.Write(recording.FromMessage(msg)); err != nil {
+				log.Printf("[Recorder] Failed to write message %s/%d/%d: %v\n", msg.Topic, msg.Partition, msg.Offset, err)
+				break loop
+			}
+			pending = append(pending, msg)
+		case <-flushTicker.C:
+			flush()
+		case <-statsTicker.C:
+			stats := writer.Stats()
+			log.Printf("[Recorder] Records: %d, bytes: %d, segments: %d, removed: %d\n",
+				stats.Records, stats.Bytes, stats.Segments, stats.Removed)
+		case <-sigChan:
+			log.Println("Received shutdown signal")
+			break loop
+		}
+	}
+
+	cancel()
+	flush()
+	if err := writer.Close(); err != nil {
+		log.Printf("[Recorder] Failed to close segment: %v\n", err)
+	}
+	reader.Close()
+}
+
+func loadConfig(path string) (*config.ProcessorConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg config.ProcessorConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,102 @@
+// webhook 将行情事件推送到配置的 Webhook 地址，供无法保持 WebSocket 连接的下游系统使用
+//
+// 订阅 Redis market:* 频道，与 Processor 分开部署，只需运行一个实例（多个实例会重复推送）。
+//
+// 用法:
+//
+//	webhook -config configs/processor.json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/services/processor/internal/storage"
+	"market-system/services/processor/internal/webhook"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func main() {
+	configPath := flag.String("config", "configs/processor.json", "配置文件路径")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v\n", err)
+	}
+
+	dispatcher := webhook.NewDispatcher(cfg.Webhook)
+	if dispatcher.EndpointCount() == 0 {
+		log.Fatalf("No webhook endpoints configured\n")
+	}
+
+	redisStorage, err := storage.NewRedisStorage(cfg.Redis)
+	if err != nil {
+		log.Fatalf("Failed to connect to Redis: %v\n", err)
+	}
+	defer redisStorage.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go dispatcher.Run(ctx)
+	go reportStats(ctx, dispatcher, redisStorage, cfg.Webhook.StatsIntervalSec)
+
+	messages := redisStorage.SubscribePattern(ctx, constants.RedisChannelMarket+"*")
+	go func() {
+		for msg := range messages {
+			dispatcher.Dispatch(strings.TrimPrefix(msg.Channel, constants.RedisChannelMarket), []byte(msg.Payload))
+		}
+	}()
+	log.Printf("[Webhook] Dispatching market events to %d endpoints\n", dispatcher.EndpointCount())
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	<-sigChan
+	log.Println("Received shutdown signal")
+	cancel()
+}
+
+// reportStats 定期将推送统计写入 Redis，供管理接口查询
+func reportStats(ctx context.Context, dispatcher *webhook.Dispatcher, redisStorage *storage.RedisStorage, intervalSec int) {
+	if intervalSec <= 0 {
+		intervalSec = 10
+	}
+	ticker := time.NewTicker(time.Duration(intervalSec) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats := dispatcher.Stats()
+			if err := redisStorage.SaveWebhookStats(stats); err != nil {
+				log.Printf("[Webhook] Failed to report stats: %v\n", err)
+			}
+			for name, s := range stats.Endpoints {
+				log.Printf("[Webhook] %s: delivered %d, failed %d, retries %d, dropped %d, pending %d\n",
+					name, s.Delivered, s.Failed, s.Retries, s.Dropped, s.Pending)
+			}
+		}
+	}
+}
+
+func loadConfig(path string) (*config.ProcessorConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg config.ProcessorConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,199 @@
+package aggtrade
+
+import (
+	"log"
+	"market-system/common/config"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"math"
+	"sync"
+	"time"
+)
+
+// Publisher 聚合成交发布接口
+type Publisher interface {
+	SaveAggTrade(agg *models.AggTrade) error
+}
+
+// Publishers 同时发布到多个目标（Redis、Kafka 等），返回第一个错误
+type Publishers []Publisher
+
+// SaveAggTrade 发布聚合成交
+func (ps Publishers) SaveAggTrade(agg *models.AggTrade) error {
+	var firstErr error
+	for _, p := range ps {
+		if err := p.SaveAggTrade(agg); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Aggregator 由成交流生成聚合成交（对应 Binance aggTrade）
+// 同一价格、同一方向、同一来源的连续成交，从第一笔起聚合窗口内的合并为一条；价格、方向或来源变化，
+// 或超出聚合窗口时，当前的聚合成交结束并发布。没有后续成交时由 Run 在窗口结束后发布。
+type Aggregator struct {
+	window    int64 // 聚合窗口（毫秒）
+	publisher Publisher
+
+	mu      sync.Mutex
+	symbols map[string]*state
+	count   int64 // 已发布的聚合成交数
+}
+
+// state 单个交易对正在聚合的成交
+type state struct {
+	pending *models.AggTrade // 为 nil 表示没有正在聚合的成交
+	lastID  int64            // 上一条聚合成交的 ID
+	updated int64            // 最近一次合并成交的本地时间（毫秒），用于判断窗口是否结束
+}
+
+// NewAggregator 创建聚合成交生成器
+func NewAggregator(cfg config.AggTradeConfig, publisher Publisher) *Aggregator {
+	if cfg.WindowMs <= 0 {
+		cfg.WindowMs = 100
+	}
+	return &Aggregator{
+		window:    int64(cfg.WindowMs),
+		publisher: publisher,
+		symbols:   make(map[string]*state),
+	}
+}
+
+// RecordTrade 合并成交，结束的聚合成交立即发布
+func (a *Aggregator) RecordTrade(trade *models.Trade) {
+	if done := a.record(trade, utils.GetCurrentTimestamp()); done != nil {
+		a.publish(done)
+	}
+}
+
+// record 将成交合并到正在聚合的成交中，无法合并时结束当前聚合并返回，否则返回 nil
+func (a *Aggregator) record(trade *models.Trade, now int64) *models.AggTrade {
+	if trade.Price <= 0 || trade.Amount <= 0 {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s, ok := a.symbols[trade.Symbol]
+	if !ok {
+		s = &state{}
+		a.symbols[trade.Symbol] = s
+	}
+	s.updated = now
+
+	if p := s.pending; p != nil && a.mergeable(p, trade) {
+		p.Amount += trade.Amount
+		p.LastTradeID = trade.TradeID
+		p.Count++
+		p.Timestamp = trade.Timestamp
+		return nil
+	}
+
+	done := s.pending
+	s.pending = &models.AggTrade{
+		Symbol:       trade.Symbol,
+		AggID:        s.nextID(trade.Timestamp),
+		Price:        trade.Price,
+		Amount:       trade.Amount,
+		Side:         trade.Side,
+		FirstTradeID: trade.TradeID,
+		LastTradeID:  trade.TradeID,
+		Count:        1,
+		FirstTime:    trade.Timestamp,
+		Timestamp:    trade.Timestamp,
+		Source:       trade.Source,
+		Exchange:     trade.Exchange,
+	}
+	return done
+}
+
+// mergeable 成交能否合并到正在聚合的成交中；迟到的成交不合并
+func (a *Aggregator) mergeable(p *models.AggTrade, trade *models.Trade) bool {
+	return trade.Price == p.Price &&
+		trade.Side == p.Side &&
+		trade.Source == p.Source &&
+		trade.Exchange == p.Exchange &&
+		trade.Timestamp >= p.Timestamp &&
+		trade.Timestamp-p.FirstTime < a.window
+}
+
+// nextID 分配聚合成交 ID：不小于第一笔成交时间 ×1000，重启后仍大于之前分配的 ID
+func (s *state) nextID(firstTime int64) int64 {
+	id := s.lastID + 1
+	if base := firstTime * 1000; base > id {
+		id = base
+	}
+	s.lastID = id
+	return id
+}
+
+// Run 按聚合窗口发布已结束的聚合成交，直到 stop 关闭
+func (a *Aggregator) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Duration(a.window) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			for _, agg := range a.Flush(utils.GetCurrentTimestamp()) {
+				a.publish(agg)
+			}
+		}
+	}
+}
+
+// Flush 结束超过聚合窗口没有新成交的聚合成交并返回
+func (a *Aggregator) Flush(now int64) []*models.AggTrade {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var done []*models.AggTrade
+	for _, s := range a.symbols {
+		if s.pending != nil && now-s.updated >= a.window {
+			done = append(done, s.pending)
+			s.pending = nil
+		}
+	}
+	return done
+}
+
+// Stop 发布全部正在聚合的成交（停机时在处理队列停止后调用）
+func (a *Aggregator) Stop() {
+	for _, agg := range a.Flush(math.MaxInt64) {
+		a.publish(agg)
+	}
+}
+
+// RemoveSymbol 发布交易对正在聚合的成交并丢弃其状态
+func (a *Aggregator) RemoveSymbol(symbol string) {
+	a.mu.Lock()
+	s := a.symbols[symbol]
+	delete(a.symbols, symbol)
+	a.mu.Unlock()
+
+	if s != nil && s.pending != nil {
+		a.publish(s.pending)
+	}
+}
+
+// Count 已发布的聚合成交数
+func (a *Aggregator) Count() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.count
+}
+
+// publish 发布聚合成交
+func (a *Aggregator) publish(agg *models.AggTrade) {
+	a.mu.Lock()
+	a.count++
+	a.mu.Unlock()
+
+	if err := a.publisher.SaveAggTrade(agg); err != nil {
+		log.Printf("[AggTrade] Failed to publish %s: %v\n", agg.Symbol, err)
+	}
+}
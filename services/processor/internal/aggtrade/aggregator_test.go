@@ -0,0 +1,57 @@
+package aggtrade
+
+import (
+	"market-system/common/config"
+	"market-system/common/models"
+	"testing"
+)
+
+func TestAggregatorCoalesce(t *testing.T) {
+	a := NewAggregator(config.AggTradeConfig{WindowMs: 100}, Publishers{})
+
+	const start = int64(1700000000000)
+	trades := []*models.Trade{
+		{Symbol: "BTCUSDT", TradeID: "1", Price: 100, Amount: 1, Side: "buy", Timestamp: start},
+		{Symbol: "BTCUSDT", TradeID: "2", Price: 100, Amount: 2, Side: "buy", Timestamp: start + 10},
+		{Symbol: "BTCUSDT", TradeID: "3", Price: 100, Amount: 0.5, Side: "buy", Timestamp: start + 99},
+		// 超出聚合窗口
+		{Symbol: "BTCUSDT", TradeID: "4", Price: 100, Amount: 1, Side: "buy", Timestamp: start + 100},
+		// 方向变化
+		{Symbol: "BTCUSDT", TradeID: "5", Price: 100, Amount: 1, Side: "sell", Timestamp: start + 101},
+		// 价格变化
+		{Symbol: "BTCUSDT", TradeID: "6", Price: 101, Amount: 1, Side: "sell", Timestamp: start + 102},
+	}
+
+	var done []*models.AggTrade
+	for _, trade := range trades {
+		if agg := a.record(trade, start); agg != nil {
+			done = append(done, agg)
+		}
+	}
+	if len(done) != 3 {
+		t.Fatalf("got %d agg trades, want 3: %+v", len(done), done)
+	}
+
+	first := done[0]
+	if first.Amount != 3.5 || first.Count != 3 || first.FirstTradeID != "1" || first.LastTradeID != "3" ||
+		first.FirstTime != start || first.Timestamp != start+99 {
+		t.Errorf("unexpected first agg trade: %+v", first)
+	}
+	if done[1].FirstTradeID != "4" || done[1].Count != 1 || done[2].Side != "sell" || done[2].Price != 100 {
+		t.Errorf("unexpected agg trades: %+v %+v", done[1], done[2])
+	}
+	for i := 1; i < len(done); i++ {
+		if done[i].AggID <= done[i-1].AggID {
+			t.Errorf("agg id not increasing: %d after %d", done[i].AggID, done[i-1].AggID)
+		}
+	}
+
+	// 窗口内没有新成交时结束
+	if flushed := a.Flush(start + 99); len(flushed) != 0 {
+		t.Errorf("flushed before window end: %+v", flushed)
+	}
+	flushed := a.Flush(start + 100)
+	if len(flushed) != 1 || flushed[0].LastTradeID != "6" || flushed[0].Price != 101 {
+		t.Errorf("unexpected flushed agg trades: %+v", flushed)
+	}
+}
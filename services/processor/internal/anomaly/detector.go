@@ -0,0 +1,246 @@
+package anomaly
+
+import (
+	"fmt"
+	"log"
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"math"
+	"sync"
+)
+
+// Publisher 告警发布接口
+type Publisher interface {
+	SaveAlert(alert *models.Alert) error
+}
+
+// Publishers 同时发布到多个目标（Redis、Kafka 等），返回第一个错误
+type Publishers []Publisher
+
+// SaveAlert 发布告警
+func (ps Publishers) SaveAlert(alert *models.Alert) error {
+	var firstErr error
+	for _, p := range ps {
+		if err := p.SaveAlert(alert); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Detector 成交价格和成交量异常检测
+// 价格：每笔成交与交易对最近 EMAPeriod 笔成交的 EMA 比较，偏离超过 PriceDeviationPercent% 时告警，
+// 成交数不足 EMAPeriod 时只更新 EMA；
+// 成交量：成交按 VolumeWindowSec 划分固定窗口，当前窗口的成交量超过之前 VolumeBaselineWindows 个窗口
+// 平均成交量的 VolumeMultiplier 倍时告警，每个窗口最多一次。
+// 同一交易对的同类告警在 CooldownMs 内只发布一次。这里只产生告警，不拦截成交。
+type Detector struct {
+	cfg       config.AnomalyConfig
+	symbols   map[string]bool // 为空表示检测全部交易对
+	publisher Publisher
+
+	mu     sync.Mutex
+	states map[string]*state
+	count  int64 // 已发布的告警数
+}
+
+// state 单个交易对的检测状态
+type state struct {
+	ema    float64
+	trades int // 已计入 EMA 的成交数
+
+	windowStart   int64     // 当前成交量窗口的开始时间（毫秒）
+	volume        float64   // 当前窗口的成交量
+	history       []float64 // 之前窗口的成交量，按时间升序，最多 VolumeBaselineWindows 个
+	volumeAlerted bool      // 当前窗口已告警
+
+	lastAlert map[string]int64 // 各类告警最近一次发布的成交时间（毫秒）
+}
+
+// NewDetector 创建异常检测
+func NewDetector(cfg config.AnomalyConfig, publisher Publisher) *Detector {
+	if cfg.PriceDeviationPercent <= 0 {
+		cfg.PriceDeviationPercent = 3
+	}
+	if cfg.EMAPeriod <= 0 {
+		cfg.EMAPeriod = 20
+	}
+	if cfg.VolumeWindowSec <= 0 {
+		cfg.VolumeWindowSec = 60
+	}
+	if cfg.VolumeBaselineWindows <= 0 {
+		cfg.VolumeBaselineWindows = 30
+	}
+	if cfg.VolumeMultiplier <= 0 {
+		cfg.VolumeMultiplier = 5
+	}
+	if cfg.CooldownMs <= 0 {
+		cfg.CooldownMs = 60000
+	}
+
+	d := &Detector{
+		cfg:       cfg,
+		symbols:   make(map[string]bool, len(cfg.Symbols)),
+		publisher: publisher,
+		states:    make(map[string]*state),
+	}
+	for _, symbol := range cfg.Symbols {
+		d.symbols[symbol] = true
+	}
+	return d
+}
+
+// RecordTrade 检测成交，发现异常时发布告警
+func (d *Detector) RecordTrade(trade *models.Trade) {
+	if len(d.symbols) > 0 && !d.symbols[trade.Symbol] {
+		return
+	}
+	if trade.Price <= 0 || trade.Amount <= 0 {
+		return
+	}
+
+	for _, alert := range d.detect(trade) {
+		if err := d.publisher.SaveAlert(alert); err != nil {
+			log.Printf("[Anomaly] Failed to publish %s alert for %s: %v\n", alert.Type, alert.Symbol, err)
+		}
+	}
+}
+
+// RemoveSymbol 丢弃交易对的检测状态
+func (d *Detector) RemoveSymbol(symbol string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.states, symbol)
+}
+
+// SymbolCount 正在检测的交易对数
+func (d *Detector) SymbolCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.states)
+}
+
+// Count 已发布的告警数
+func (d *Detector) Count() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.count
+}
+
+// detect 更新交易对的 EMA 和成交量窗口，返回需要发布的告警
+func (d *Detector) detect(trade *models.Trade) []*models.Alert {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s, ok := d.states[trade.Symbol]
+	if !ok {
+		s = &state{lastAlert: make(map[string]int64)}
+		d.states[trade.Symbol] = s
+	}
+
+	var alerts []*models.Alert
+	if alert := d.checkPrice(s, trade); alert != nil && d.allow(s, alert) {
+		alerts = append(alerts, alert)
+	}
+	if alert := d.checkVolume(s, trade); alert != nil && d.allow(s, alert) {
+		alerts = append(alerts, alert)
+	}
+	d.count += int64(len(alerts))
+	return alerts
+}
+
+// checkPrice 比较成交价与之前成交的 EMA，再将成交价计入 EMA
+func (d *Detector) checkPrice(s *state, trade *models.Trade) *models.Alert {
+	var alert *models.Alert
+	if s.trades >= d.cfg.EMAPeriod {
+		deviation := (trade.Price - s.ema) / s.ema * 100
+		if math.Abs(deviation) > d.cfg.PriceDeviationPercent {
+			alert = newAlert(constants.AlertTypePriceDeviation, trade)
+			alert.Value = deviation
+			alert.Reference = s.ema
+			alert.Threshold = d.cfg.PriceDeviationPercent
+			alert.Message = fmt.Sprintf("price %v deviates %.2f%% from EMA %v", trade.Price, deviation, s.ema)
+		}
+	}
+
+	// 前 EMAPeriod 笔成交取算术平均作为初始值
+	s.trades++
+	if s.trades <= d.cfg.EMAPeriod {
+		s.ema += (trade.Price - s.ema) / float64(s.trades)
+	} else {
+		alpha := 2 / float64(d.cfg.EMAPeriod+1)
+		s.ema += alpha * (trade.Price - s.ema)
+	}
+	return alert
+}
+
+// checkVolume 将成交量计入所在窗口，窗口切换时将之前的窗口移入基准，没有成交的窗口按 0 计
+// 迟到的成交计入当前窗口
+func (d *Detector) checkVolume(s *state, trade *models.Trade) *models.Alert {
+	windowMs := int64(d.cfg.VolumeWindowSec) * 1000
+	start := trade.Timestamp / windowMs * windowMs
+	if s.windowStart == 0 {
+		s.windowStart = start
+	}
+	if start > s.windowStart {
+		s.history = append(s.history, s.volume)
+		gaps := (start-s.windowStart)/windowMs - 1
+		if gaps > int64(d.cfg.VolumeBaselineWindows) {
+			gaps = int64(d.cfg.VolumeBaselineWindows)
+		}
+		for ; gaps > 0; gaps-- {
+			s.history = append(s.history, 0)
+		}
+		if n := len(s.history); n > d.cfg.VolumeBaselineWindows {
+			s.history = append(s.history[:0], s.history[n-d.cfg.VolumeBaselineWindows:]...)
+		}
+		s.windowStart = start
+		s.volume = 0
+		s.volumeAlerted = false
+	}
+	s.volume += trade.Amount
+
+	// 基准窗口不足时不检测
+	if s.volumeAlerted || len(s.history) < d.cfg.VolumeBaselineWindows {
+		return nil
+	}
+	var total float64
+	for _, v := range s.history {
+		total += v
+	}
+	baseline := total / float64(len(s.history))
+	if baseline <= 0 || s.volume <= baseline*d.cfg.VolumeMultiplier {
+		return nil
+	}
+
+	s.volumeAlerted = true
+	alert := newAlert(constants.AlertTypeVolumeSpike, trade)
+	alert.Value = s.volume
+	alert.Reference = baseline
+	alert.Threshold = d.cfg.VolumeMultiplier
+	alert.Message = fmt.Sprintf("volume %v in %ds window is %.1fx the baseline %v", s.volume, d.cfg.VolumeWindowSec, s.volume/baseline, baseline)
+	return alert
+}
+
+// allow 同类告警在冷却时间内只发布一次
+func (d *Detector) allow(s *state, alert *models.Alert) bool {
+	if last, ok := s.lastAlert[alert.Type]; ok && alert.Timestamp-last < int64(d.cfg.CooldownMs) {
+		return false
+	}
+	s.lastAlert[alert.Type] = alert.Timestamp
+	return true
+}
+
+// newAlert 按触发告警的成交创建告警
+func newAlert(alertType string, trade *models.Trade) *models.Alert {
+	return &models.Alert{
+		Symbol:    trade.Symbol,
+		Type:      alertType,
+		Exchange:  trade.Exchange,
+		Source:    trade.Source,
+		Price:     trade.Price,
+		TradeID:   trade.TradeID,
+		Timestamp: trade.Timestamp,
+	}
+}
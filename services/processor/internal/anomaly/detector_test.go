@@ -0,0 +1,90 @@
+package anomaly
+
+import (
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"testing"
+)
+
+type memoryPublisher struct {
+	alerts []*models.Alert
+}
+
+func (p *memoryPublisher) SaveAlert(alert *models.Alert) error {
+	p.alerts = append(p.alerts, alert)
+	return nil
+}
+
+func trade(price, amount float64, timestamp int64) *models.Trade {
+	return &models.Trade{Symbol: "BTCUSDT", Price: price, Amount: amount, Timestamp: timestamp}
+}
+
+func TestPriceDeviation(t *testing.T) {
+	publisher := &memoryPublisher{}
+	d := NewDetector(config.AnomalyConfig{EMAPeriod: 3, PriceDeviationPercent: 5, CooldownMs: 10000}, publisher)
+
+	// EMA 成交数不足时不检测
+	d.RecordTrade(trade(100, 1, 1000))
+	d.RecordTrade(trade(200, 1, 2000))
+	d.RecordTrade(trade(0, 1, 3000))
+	if len(publisher.alerts) != 0 {
+		t.Fatalf("alerts during warmup = %+v", publisher.alerts)
+	}
+
+	// 初始 EMA 为 (100 + 200 + 150) / 3 = 150
+	d.RecordTrade(trade(150, 1, 3000))
+	d.RecordTrade(trade(156, 1, 4000))
+	if len(publisher.alerts) != 0 {
+		t.Fatalf("unexpected alerts = %+v", publisher.alerts)
+	}
+
+	// EMA = 153，下跌 10% 触发告警
+	d.RecordTrade(trade(137.7, 1, 5000))
+	if len(publisher.alerts) != 1 {
+		t.Fatalf("alerts = %d", len(publisher.alerts))
+	}
+	alert := publisher.alerts[0]
+	if alert.Type != constants.AlertTypePriceDeviation || alert.Reference != 153 || alert.Value > -9.99 || alert.Value < -10.01 {
+		t.Errorf("alert = %+v", alert)
+	}
+
+	// 冷却时间内不重复告警
+	d.RecordTrade(trade(100, 1, 6000))
+	if len(publisher.alerts) != 1 {
+		t.Errorf("alerts in cooldown = %d", len(publisher.alerts))
+	}
+}
+
+func TestVolumeSpike(t *testing.T) {
+	publisher := &memoryPublisher{}
+	d := NewDetector(config.AnomalyConfig{
+		EMAPeriod:             1000,
+		VolumeWindowSec:       1,
+		VolumeBaselineWindows: 3,
+		VolumeMultiplier:      4,
+	}, publisher)
+
+	// 基准窗口成交量 2、没有成交、4，平均 2
+	d.RecordTrade(trade(100, 2, 10000))
+	d.RecordTrade(trade(100, 4, 12000))
+	d.RecordTrade(trade(100, 8, 13000))
+	if len(publisher.alerts) != 0 {
+		t.Fatalf("unexpected alerts = %+v", publisher.alerts)
+	}
+
+	// 当前窗口累计超过 8 时告警，同一窗口只告警一次
+	d.RecordTrade(trade(100, 1, 13500))
+	d.RecordTrade(trade(100, 5, 13900))
+	if len(publisher.alerts) != 1 {
+		t.Fatalf("alerts = %d", len(publisher.alerts))
+	}
+	alert := publisher.alerts[0]
+	if alert.Type != constants.AlertTypeVolumeSpike || alert.Value != 9 || alert.Reference != 2 {
+		t.Errorf("alert = %+v", alert)
+	}
+
+	if d.SymbolCount() != 1 || d.Count() != 1 {
+		t.Errorf("symbols = %d, count = %d", d.SymbolCount(), d.Count())
+	}
+}
@@ -0,0 +1,389 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"market-system/common/config"
+	"market-system/common/models"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	datasetTrades = "trades"
+	datasetKlines = "klines"
+)
+
+// Archiver 历史数据归档
+// 成交和收盘K线按小时（数据时间）分桶缓存，小时结束并超过延迟容忍时间后写成 Parquet 文件，
+// 先落盘到本地目录再上传到 S3 兼容存储，上传成功后删除本地文件；上传失败的文件保留在本地，下次归档时重试。
+// 单个小时的数据超过 MaxRowsPerFile 时提前写出分片文件。
+// 对象路径: {prefix}/{trades|klines}/dt=YYYY-MM-DD/hour=HH/part-{纳秒时间戳}.parquet
+type Archiver struct {
+	cfg config.ArchiveConfig
+	s3  *s3Client
+
+	mu      sync.Mutex
+	buckets map[int64]*hourBucket // key: 小时起始时间（毫秒）
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	stats Stats
+}
+
+// hourBucket 一个小时内的待归档数据
+type hourBucket struct {
+	trades []models.Trade
+	klines []models.Kline
+}
+
+// Stats 归档统计
+type Stats struct {
+	Files          int64 // 已上传的文件数
+	Rows           int64 // 已写入文件的记录数
+	UploadFailures int64 // 上传失败次数
+	PendingUploads int   // 本地待上传的文件数
+}
+
+// NewArchiver 创建归档器
+func NewArchiver(cfg config.ArchiveConfig) (*Archiver, error) {
+	if cfg.LocalDir == "" {
+		cfg.LocalDir = "data/archive"
+	}
+	if cfg.MaxRowsPerFile <= 0 {
+		cfg.MaxRowsPerFile = 500000
+	}
+	if cfg.LatenessSec <= 0 {
+		cfg.LatenessSec = 300
+	}
+	if cfg.S3.Endpoint == "" || cfg.S3.Bucket == "" {
+		return nil, fmt.Errorf("archive s3 endpoint and bucket are required")
+	}
+	if err := os.MkdirAll(cfg.LocalDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive dir: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &Archiver{
+		cfg:     cfg,
+		s3:      newS3Client(cfg.S3),
+		buckets: make(map[int64]*hourBucket),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	a.wg.Add(1)
+	go a.run()
+
+	log.Printf("[Archive] Archiving to %s/%s (local dir: %s)\n", cfg.S3.Endpoint, cfg.S3.Bucket, cfg.LocalDir)
+	return a, nil
+}
+
+// SaveTrade 缓存成交
+func (a *Archiver) SaveTrade(trade *models.Trade) error {
+	hour := hourStart(trade.Timestamp)
+
+	a.mu.Lock()
+	bucket := a.bucket(hour)
+	bucket.trades = append(bucket.trades, *trade)
+	full := len(bucket.trades) >= a.cfg.MaxRowsPerFile
+	var trades []models.Trade
+	if full {
+		trades = bucket.trades
+		bucket.trades = nil
+	}
+	a.mu.Unlock()
+
+	if full {
+		a.writeTrades(hour, trades)
+	}
+	return nil
+}
+
+// SaveKline 缓存收盘K线（聚合器只在K线收盘时保存）
+func (a *Archiver) SaveKline(kline *models.Kline) error {
+	hour := hourStart(kline.OpenTime)
+
+	a.mu.Lock()
+	bucket := a.bucket(hour)
+	bucket.klines = append(bucket.klines, *kline)
+	full := len(bucket.klines) >= a.cfg.MaxRowsPerFile
+	var klines []models.Kline
+	if full {
+		klines = bucket.klines
+		bucket.klines = nil
+	}
+	a.mu.Unlock()
+
+	if full {
+		a.writeKlines(hour, klines)
+	}
+	return nil
+}
+
+// SaveTicker Ticker 不归档
+func (a *Archiver) SaveTicker(ticker *models.Ticker) error {
+	return nil
+}
+
+// SaveDepth 深度不归档
+func (a *Archiver) SaveDepth(depth *models.OrderBook) error {
+	return nil
+}
+
+// Stats 获取归档统计
+func (a *Archiver) Stats() Stats {
+	a.mu.Lock()
+	stats := a.stats
+	a.mu.Unlock()
+
+	stats.PendingUploads = len(a.localFiles())
+	return stats
+}
+
+// Close 写出所有缓存数据并尝试上传
+func (a *Archiver) Close() error {
+	a.cancel()
+	a.wg.Wait()
+	return nil
+}
+
+// run 每分钟归档已结束的小时，并重试上传本地文件
+func (a *Archiver) run() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			a.roll(true)
+			a.upload()
+			return
+		case <-ticker.C:
+			a.roll(false)
+			a.upload()
+		}
+	}
+}
+
+// roll 写出已结束（超过延迟容忍时间）的小时，all 为 true 时写出全部
+func (a *Archiver) roll(all bool) {
+	cutoff := time.Now().Add(-time.Duration(a.cfg.LatenessSec) * time.Second).UnixMilli()
+
+	a.mu.Lock()
+	ready := make(map[int64]*hourBucket)
+	for hour, bucket := range a.buckets {
+		if all || hour+time.Hour.Milliseconds() <= cutoff {
+			ready[hour] = bucket
+			delete(a.buckets, hour)
+		}
+	}
+	a.mu.Unlock()
+
+	for hour, bucket := range ready {
+		a.writeTrades(hour, bucket.trades)
+		a.writeKlines(hour, bucket.klines)
+	}
+}
+
+// writeTrades 将成交写成 Parquet 文件
+func (a *Archiver) writeTrades(hour int64, trades []models.Trade) {
+	if len(trades) == 0 {
+		return
+	}
+
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].Timestamp < trades[j].Timestamp })
+
+	var (
+		timestamps = make([]int64, len(trades))
+		symbols    = make([]string, len(trades))
+		tradeIDs   = make([]string, len(trades))
+		prices     = make([]float64, len(trades))
+		amounts    = make([]float64, len(trades))
+		sides      = make([]string, len(trades))
+	)
+	for i, t := range trades {
+		timestamps[i] = t.Timestamp
+		symbols[i] = t.Symbol
+		tradeIDs[i] = t.TradeID
+		prices[i] = t.Price
+		amounts[i] = t.Amount
+		sides[i] = t.Side
+	}
+
+	w := newParquetWriter(len(trades))
+	w.int64Column("timestamp", timestamps, true)
+	w.stringColumn("symbol", symbols)
+	w.stringColumn("trade_id", tradeIDs)
+	w.doubleColumn("price", prices)
+	w.doubleColumn("amount", amounts)
+	w.stringColumn("side", sides)
+
+	a.writeFile(datasetTrades, hour, w)
+}
+
+// writeKlines 将K线写成 Parquet 文件
+func (a *Archiver) writeKlines(hour int64, klines []models.Kline) {
+	if len(klines) == 0 {
+		return
+	}
+
+	sort.SliceStable(klines, func(i, j int) bool { return klines[i].OpenTime < klines[j].OpenTime })
+
+	var (
+		openTimes  = make([]int64, len(klines))
+		closeTimes = make([]int64, len(klines))
+		symbols    = make([]string, len(klines))
+		intervals  = make([]string, len(klines))
+		opens      = make([]float64, len(klines))
+		highs      = make([]float64, len(klines))
+		lows       = make([]float64, len(klines))
+		closes     = make([]float64, len(klines))
+		volumes    = make([]float64, len(klines))
+		quoteVols  = make([]float64, len(klines))
+		tradeNums  = make([]int64, len(klines))
+		revisions  = make([]int64, len(klines))
+	)
+	for i, k := range klines {
+		openTimes[i] = k.OpenTime
+		closeTimes[i] = k.CloseTime
+		symbols[i] = k.Symbol
+		intervals[i] = k.Interval
+		opens[i] = k.Open
+		highs[i] = k.High
+		lows[i] = k.Low
+		closes[i] = k.Close
+		volumes[i] = k.Volume
+		quoteVols[i] = k.QuoteVol
+		tradeNums[i] = k.TradeNum
+		revisions[i] = k.Revision
+	}
+
+	w := newParquetWriter(len(klines))
+	w.int64Column("open_time", openTimes, true)
+	w.int64Column("close_time", closeTimes, true)
+	w.stringColumn("symbol", symbols)
+	w.stringColumn("interval", intervals)
+	w.doubleColumn("open", opens)
+	w.doubleColumn("high", highs)
+	w.doubleColumn("low", lows)
+	w.doubleColumn("close", closes)
+	w.doubleColumn("volume", volumes)
+	w.doubleColumn("quote_vol", quoteVols)
+	w.int64Column("trade_num", tradeNums, false)
+	w.int64Column("revision", revisions, false)
+
+	a.writeFile(datasetKlines, hour, w)
+}
+
+// writeFile 生成 Parquet 文件并写入本地目录（相对路径即对象 key）
+func (a *Archiver) writeFile(dataset string, hour int64, w *parquetWriter) {
+	data, err := w.bytes()
+	if err != nil {
+		log.Printf("[Archive] Failed to encode %s: %v\n", dataset, err)
+		return
+	}
+
+	t := time.UnixMilli(hour).UTC()
+	key := fmt.Sprintf("%s/dt=%s/hour=%02d/part-%d.parquet", dataset, t.Format("2006-01-02"), t.Hour(), time.Now().UnixNano())
+	path := filepath.Join(a.cfg.LocalDir, filepath.FromSlash(key))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		log.Printf("[Archive] Failed to create dir for %s: %v\n", key, err)
+		return
+	}
+	// 先写临时文件再重命名，避免上传不完整的文件
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		log.Printf("[Archive] Failed to write %s: %v\n", key, err)
+		return
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		log.Printf("[Archive] Failed to write %s: %v\n", key, err)
+		return
+	}
+
+	a.mu.Lock()
+	a.stats.Rows += int64(w.numRows)
+	a.mu.Unlock()
+
+	log.Printf("[Archive] Wrote %s (%d rows, %d bytes)\n", key, w.numRows, len(data))
+}
+
+// upload 上传本地目录中的 Parquet 文件，成功后删除
+func (a *Archiver) upload() {
+	for _, path := range a.localFiles() {
+		rel, err := filepath.Rel(a.cfg.LocalDir, path)
+		if err != nil {
+			continue
+		}
+		key := filepath.ToSlash(rel)
+		if a.cfg.S3.Prefix != "" {
+			key = strings.Trim(a.cfg.S3.Prefix, "/") + "/" + key
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("[Archive] Failed to read %s: %v\n", path, err)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		err = a.s3.putObject(ctx, key, data, "application/vnd.apache.parquet")
+		cancel()
+		if err != nil {
+			a.mu.Lock()
+			a.stats.UploadFailures++
+			a.mu.Unlock()
+			log.Printf("[Archive] Failed to upload %s: %v\n", key, err)
+			return // 存储不可用时不继续尝试剩余文件，下次归档时重试
+		}
+
+		if err := os.Remove(path); err != nil {
+			log.Printf("[Archive] Failed to remove %s: %v\n", path, err)
+		}
+
+		a.mu.Lock()
+		a.stats.Files++
+		a.mu.Unlock()
+
+		log.Printf("[Archive] Uploaded %s\n", key)
+	}
+}
+
+// localFiles 本地待上传的 Parquet 文件
+func (a *Archiver) localFiles() []string {
+	var files []string
+	filepath.Walk(a.cfg.LocalDir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && strings.HasSuffix(path, ".parquet") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files
+}
+
+// bucket 获取小时分桶（调用方需持有锁）
+func (a *Archiver) bucket(hour int64) *hourBucket {
+	bucket, ok := a.buckets[hour]
+	if !ok {
+		bucket = &hourBucket{}
+		a.buckets[hour] = bucket
+	}
+	return bucket
+}
+
+// hourStart 时间戳所在小时的起始时间（毫秒）
+func hourStart(timestamp int64) int64 {
+	hour := time.Hour.Milliseconds()
+	return timestamp - timestamp%hour
+}
@@ -0,0 +1,306 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// 最小 Parquet 写入实现：单个 row group，所有列为 REQUIRED，PLAIN 编码，GZIP 压缩，
+// 每列一个 data page。足以被 Spark / DuckDB / Athena / pandas 等读取，无需引入额外依赖。
+// 格式参考 https://github.com/apache/parquet-format
+
+// Parquet 物理类型
+const (
+	parquetInt64     int32 = 2
+	parquetDouble    int32 = 5
+	parquetByteArray int32 = 6
+)
+
+// Parquet 逻辑类型（ConvertedType）
+const (
+	convertedNone            int32 = -1
+	convertedUTF8            int32 = 0
+	convertedTimestampMillis int32 = 9
+)
+
+const (
+	encodingPlain int32 = 0
+	encodingRLE   int32 = 3
+	codecGzip     int32 = 2
+	pageTypeData  int32 = 0
+	repRequired   int32 = 0
+)
+
+var parquetMagic = []byte("PAR1")
+
+// parquetColumn 列数据
+type parquetColumn struct {
+	name          string
+	physicalType  int32
+	convertedType int32
+	numValues     int
+	data          bytes.Buffer // PLAIN 编码后的数据
+}
+
+// parquetWriter 按列构造 Parquet 文件
+type parquetWriter struct {
+	columns []*parquetColumn
+	numRows int
+}
+
+func newParquetWriter(numRows int) *parquetWriter {
+	return &parquetWriter{numRows: numRows}
+}
+
+// int64Column 添加 INT64 列，timestamp 为 true 时标记为毫秒时间戳
+func (w *parquetWriter) int64Column(name string, values []int64, timestamp bool) {
+	col := &parquetColumn{name: name, physicalType: parquetInt64, convertedType: convertedNone, numValues: len(values)}
+	if timestamp {
+		col.convertedType = convertedTimestampMillis
+	}
+	var buf [8]byte
+	for _, v := range values {
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		col.data.Write(buf[:])
+	}
+	w.columns = append(w.columns, col)
+}
+
+// doubleColumn 添加 DOUBLE 列
+func (w *parquetWriter) doubleColumn(name string, values []float64) {
+	col := &parquetColumn{name: name, physicalType: parquetDouble, convertedType: convertedNone, numValues: len(values)}
+	var buf [8]byte
+	for _, v := range values {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+		col.data.Write(buf[:])
+	}
+	w.columns = append(w.columns, col)
+}
+
+// stringColumn 添加 UTF8 字符串列
+func (w *parquetWriter) stringColumn(name string, values []string) {
+	col := &parquetColumn{name: name, physicalType: parquetByteArray, convertedType: convertedUTF8, numValues: len(values)}
+	var buf [4]byte
+	for _, v := range values {
+		binary.LittleEndian.PutUint32(buf[:], uint32(len(v)))
+		col.data.Write(buf[:])
+		col.data.WriteString(v)
+	}
+	w.columns = append(w.columns, col)
+}
+
+// bytes 生成完整的 Parquet 文件
+func (w *parquetWriter) bytes() ([]byte, error) {
+	var out bytes.Buffer
+	out.Write(parquetMagic)
+
+	type chunkMeta struct {
+		offset           int64
+		uncompressedSize int64
+		compressedSize   int64
+	}
+	chunks := make([]chunkMeta, len(w.columns))
+	var totalSize int64
+
+	for i, col := range w.columns {
+		if col.numValues != w.numRows {
+			return nil, fmt.Errorf("column %s has %d values, expected %d", col.name, col.numValues, w.numRows)
+		}
+
+		compressed, err := gzipBytes(col.data.Bytes())
+		if err != nil {
+			return nil, err
+		}
+
+		// PageHeader
+		header := newThriftWriter()
+		header.i32Field(1, pageTypeData)
+		header.i32Field(2, int32(col.data.Len()))
+		header.i32Field(3, int32(len(compressed)))
+		header.structBegin(5) // DataPageHeader
+		header.i32Field(1, int32(col.numValues))
+		header.i32Field(2, encodingPlain)
+		header.i32Field(3, encodingRLE)
+		header.i32Field(4, encodingRLE)
+		header.structEnd()
+		header.stop()
+
+		offset := int64(out.Len())
+		out.Write(header.buf.Bytes())
+		out.Write(compressed)
+
+		chunks[i] = chunkMeta{
+			offset:           offset,
+			uncompressedSize: int64(header.buf.Len() + col.data.Len()),
+			compressedSize:   int64(header.buf.Len() + len(compressed)),
+		}
+		totalSize += chunks[i].uncompressedSize
+	}
+
+	// FileMetaData
+	meta := newThriftWriter()
+	meta.i32Field(1, 1) // version
+	meta.listBegin(2, thriftStruct, len(w.columns)+1)
+	// 根节点
+	meta.listStructBegin()
+	meta.stringField(4, "schema")
+	meta.i32Field(5, int32(len(w.columns)))
+	meta.structEnd()
+	for _, col := range w.columns {
+		meta.listStructBegin()
+		meta.i32Field(1, col.physicalType)
+		meta.i32Field(3, repRequired)
+		meta.stringField(4, col.name)
+		if col.convertedType != convertedNone {
+			meta.i32Field(6, col.convertedType)
+		}
+		meta.structEnd()
+	}
+	meta.i64Field(3, int64(w.numRows))
+
+	meta.listBegin(4, thriftStruct, 1) // row_groups
+	meta.listStructBegin()
+	meta.listBegin(1, thriftStruct, len(w.columns)) // columns
+	for i, col := range w.columns {
+		meta.listStructBegin()
+		meta.i64Field(2, chunks[i].offset) // file_offset
+		meta.structBegin(3)                // ColumnMetaData
+		meta.i32Field(1, col.physicalType)
+		meta.listBegin(2, thriftI32, 2)
+		meta.varint(zigzag32(encodingPlain))
+		meta.varint(zigzag32(encodingRLE))
+		meta.listBegin(3, thriftBinary, 1)
+		meta.binary(col.name)
+		meta.i32Field(4, codecGzip)
+		meta.i64Field(5, int64(col.numValues))
+		meta.i64Field(6, chunks[i].uncompressedSize)
+		meta.i64Field(7, chunks[i].compressedSize)
+		meta.i64Field(9, chunks[i].offset) // data_page_offset
+		meta.structEnd()
+		meta.structEnd()
+	}
+	meta.i64Field(2, totalSize)
+	meta.i64Field(3, int64(w.numRows))
+	meta.structEnd()
+	meta.stringField(6, "market-system archiver")
+	meta.stop()
+
+	out.Write(meta.buf.Bytes())
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(meta.buf.Len()))
+	out.Write(size[:])
+	out.Write(parquetMagic)
+
+	return out.Bytes(), nil
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Thrift Compact Protocol 类型
+const (
+	thriftI32    byte = 5
+	thriftI64    byte = 6
+	thriftBinary byte = 8
+	thriftList   byte = 9
+	thriftStruct byte = 12
+)
+
+// thriftWriter Thrift Compact Protocol 编码（仅实现 Parquet 元数据需要的部分）
+type thriftWriter struct {
+	buf       bytes.Buffer
+	lastField []int16 // 嵌套结构体的上一个字段 ID
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{lastField: []int16{0}}
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	last := &t.lastField[len(t.lastField)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(zigzag32(int32(id)))
+	}
+	*last = id
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	t.buf.Write(buf[:n])
+}
+
+func (t *thriftWriter) binary(s string) {
+	t.varint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+func (t *thriftWriter) i32Field(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.varint(zigzag32(v))
+}
+
+func (t *thriftWriter) i64Field(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.varint(zigzag64(v))
+}
+
+func (t *thriftWriter) stringField(id int16, s string) {
+	t.fieldHeader(id, thriftBinary)
+	t.binary(s)
+}
+
+// listBegin 写入列表字段头，元素由调用方随后写入
+func (t *thriftWriter) listBegin(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		t.buf.WriteByte(0xF0 | elemType)
+		t.varint(uint64(size))
+	}
+}
+
+// structBegin 开始结构体字段
+func (t *thriftWriter) structBegin(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.lastField = append(t.lastField, 0)
+}
+
+// listStructBegin 开始列表中的结构体元素
+func (t *thriftWriter) listStructBegin() {
+	t.lastField = append(t.lastField, 0)
+}
+
+// structEnd 结束结构体
+func (t *thriftWriter) structEnd() {
+	t.stop()
+	t.lastField = t.lastField[:len(t.lastField)-1]
+}
+
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+}
+
+func zigzag32(v int32) uint64 {
+	return uint64(uint32((v << 1) ^ (v >> 31)))
+}
+
+func zigzag64(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
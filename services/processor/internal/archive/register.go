@@ -0,0 +1,12 @@
+package archive
+
+import (
+	"market-system/common/config"
+	"market-system/services/processor/internal/storage"
+)
+
+func init() {
+	storage.Register(storage.BackendArchive, func(cfg *config.ProcessorConfig) (storage.Backend, error) {
+		return NewArchiver(cfg.Archive)
+	})
+}
@@ -0,0 +1,133 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"market-system/common/config"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// s3Client S3 兼容存储客户端（AWS S3 / MinIO / OSS 等），只实现 PutObject，使用 Signature V4 签名
+type s3Client struct {
+	cfg    config.S3Config
+	client *http.Client
+}
+
+func newS3Client(cfg config.S3Config) *s3Client {
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	return &s3Client{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// putObject 上传对象
+func (c *s3Client) putObject(ctx context.Context, key string, body []byte, contentType string) error {
+	endpoint, err := url.Parse(c.cfg.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid s3 endpoint: %w", err)
+	}
+
+	// path-style: {endpoint}/{bucket}/{key}，virtual-hosted-style: {bucket}.{host}/{key}
+	host := endpoint.Host
+	path := "/" + c.cfg.Bucket + "/" + key
+	if !c.cfg.PathStyle {
+		host = c.cfg.Bucket + "." + endpoint.Host
+		path = "/" + key
+	}
+	canonicalURI := encodePath(path)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint.Scheme+"://"+host+canonicalURI, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Type", contentType)
+	c.sign(req, host, canonicalURI, body, time.Now().UTC())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// sign 按 AWS Signature V4 签名请求
+func (c *s3Client) sign(req *http.Request, host, canonicalURI string, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("Host", host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "content-type;host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "content-type:" + req.Header.Get("Content-Type") + "\n" +
+		"host:" + host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		"", // query string
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + c.cfg.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+c.cfg.SecretKey), date)
+	key = hmacSHA256(key, c.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.cfg.AccessKey, scope, signedHeaders, signature))
+}
+
+// encodePath 按 Signature V4 规则对路径编码：除 A-Z a-z 0-9 - _ . ~ 和路径分隔符 / 外全部百分号编码
+func encodePath(path string) string {
+	var sb strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,211 @@
+package backfill
+
+import (
+	"fmt"
+	"log"
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/exchange"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"sync"
+	"time"
+)
+
+// Store K线写入接口（经 FanoutStorage 写入 Redis、InfluxDB 等全部后端）
+type Store interface {
+	SaveKline(kline *models.Kline) error
+}
+
+// History 已保存的K线查询接口，结果按开盘时间倒序
+type History interface {
+	GetKlines(symbol, interval string, startTime, endTime, limit int64) ([]*models.Kline, error)
+}
+
+// Live 本地K线聚合，回补不写入本地正在聚合的K线
+type Live interface {
+	CurrentOpenTime(symbol, interval string) int64
+	Intervals(symbol string) []string
+}
+
+// pageSizes 各交易所单次请求的最大K线数
+var pageSizes = map[string]int{
+	constants.ExchangeBinance: 1000,
+	constants.ExchangeOKX:     100,
+}
+
+// Backfiller 从交易所 REST 接口回补历史K线
+// 只回补最新已保存K线之后、本地正在聚合的K线之前的已收盘K线，按时间升序写入，不打乱 Redis 中从新到旧的K线列表：
+// 首次启动时回补最近 Bars 根，重启后补齐停机期间缺失的K线。需要整体重建时使用管理接口的缓存重建。
+type Backfiller struct {
+	cfg     config.BackfillConfig
+	client  exchange.RESTClient
+	store   Store
+	history History
+	live    Live
+	symbols func() ([]string, error) // 未配置交易对时获取注册表中启用的交易对
+
+	running sync.Mutex // 同一时间只执行一次回补
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// Stats 回补统计
+type Stats struct {
+	Runs   int64 // 回补次数
+	Klines int64 // 写入的K线数
+	Errors int64 // 失败的交易对周期数
+}
+
+// NewBackfiller 创建历史K线回补
+func NewBackfiller(cfg config.BackfillConfig, store Store, history History, live Live, symbols func() ([]string, error)) (*Backfiller, error) {
+	if cfg.Bars <= 0 {
+		cfg.Bars = 1000
+	}
+
+	client := exchange.NewRESTClient(cfg.Source)
+	if client == nil {
+		return nil, fmt.Errorf("unsupported backfill source: %s", cfg.Source)
+	}
+
+	return &Backfiller{
+		cfg:     cfg,
+		client:  client,
+		store:   store,
+		history: history,
+		live:    live,
+		symbols: symbols,
+	}, nil
+}
+
+// Run 回补请求中的交易对和周期（为空时使用配置），stop 关闭时中断
+func (b *Backfiller) Run(req *models.BackfillRequest, stop <-chan struct{}) {
+	b.running.Lock()
+	defer b.running.Unlock()
+
+	symbols := req.Symbols
+	if len(symbols) == 0 {
+		symbols = b.cfg.Symbols
+	}
+	if len(symbols) == 0 {
+		var err error
+		if symbols, err = b.symbols(); err != nil {
+			log.Printf("[Backfill] Failed to get symbols: %v\n", err)
+			return
+		}
+	}
+	start := time.Now()
+	var total, failed int64
+	for _, symbol := range symbols {
+		// 未指定周期时回补交易对聚合的全部周期
+		intervals := req.Intervals
+		if len(intervals) == 0 {
+			intervals = b.cfg.Intervals
+		}
+		if len(intervals) == 0 {
+			intervals = b.live.Intervals(symbol)
+		}
+
+		for _, interval := range intervals {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			n, err := b.backfill(symbol, interval, stop)
+			total += int64(n)
+			if err != nil {
+				failed++
+				log.Printf("[Backfill] Failed to backfill %s %s: %v\n", symbol, interval, err)
+			}
+		}
+	}
+
+	b.mu.Lock()
+	b.stats.Runs++
+	b.stats.Klines += total
+	b.stats.Errors += failed
+	b.mu.Unlock()
+
+	log.Printf("[Backfill] Backfilled %d klines for %d symbols from %s in %v (errors: %d)\n",
+		total, len(symbols), b.client.Name(), time.Since(start).Round(time.Millisecond), failed)
+}
+
+// Listen 处理管理接口触发的回补请求，直到 requests 关闭
+func (b *Backfiller) Listen(requests <-chan string, stop <-chan struct{}) {
+	for payload := range requests {
+		var req models.BackfillRequest
+		if err := utils.FromJSON(payload, &req); err != nil {
+			log.Printf("[Backfill] Invalid backfill request: %v\n", err)
+			continue
+		}
+		b.Run(&req, stop)
+	}
+}
+
+// Stats 获取回补统计
+func (b *Backfiller) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
+
+// backfill 回补单个交易对单个周期，返回写入的K线数
+func (b *Backfiller) backfill(symbol, interval string, stop <-chan struct{}) (int, error) {
+	end := utils.GetKlineOpenTime(utils.GetCurrentTimestamp(), interval)
+	if open := b.live.CurrentOpenTime(symbol, interval); open > 0 && open < end {
+		end = open
+	}
+	period := utils.GetKlineCloseTime(end, interval) - end + 1
+	if period <= 1 {
+		return 0, fmt.Errorf("unsupported interval: %s", interval)
+	}
+
+	start := end - int64(b.cfg.Bars)*period
+	latest, err := b.history.GetKlines(symbol, interval, 0, 0, 1)
+	if err != nil {
+		return 0, err
+	}
+	if len(latest) > 0 && latest[0].CloseTime+1 > start {
+		start = latest[0].CloseTime + 1
+	}
+
+	pageSize := pageSizes[b.client.Name()]
+	saved := 0
+	for start < end {
+		select {
+		case <-stop:
+			return saved, nil
+		default:
+		}
+
+		pageEnd := start + int64(pageSize)*period
+		if pageEnd > end {
+			pageEnd = end
+		}
+
+		klines, err := b.client.GetKlines(symbol, interval, start, pageEnd-1, pageSize)
+		if err != nil {
+			return saved, err
+		}
+		for _, k := range klines {
+			if k.OpenTime < start || k.OpenTime >= pageEnd {
+				continue
+			}
+			// OKX 不返回收盘时间，单根K线时无法推算
+			if k.CloseTime <= k.OpenTime {
+				k.CloseTime = utils.GetKlineCloseTime(k.OpenTime, interval)
+			}
+			k.Source = b.client.Name()
+			k.IsFinal = true
+			if err := b.store.SaveKline(k); err != nil {
+				return saved, err
+			}
+			saved++
+		}
+		start = pageEnd
+	}
+	return saved, nil
+}
@@ -0,0 +1,152 @@
+package bookstats
+
+import (
+	"log"
+	"market-system/common/config"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"sync"
+)
+
+// Store 订单簿统计写入接口
+type Store interface {
+	SaveBookStats(stats *models.BookStats, historySize int) error
+}
+
+// Analyzer 订单簿统计
+// 作为深度处理的后续阶段接收排序后的完整深度，每个交易对按采样间隔计算一次并写入，
+// 两次写入之间的深度更新不计算
+type Analyzer struct {
+	cfg     config.BookStatsConfig
+	symbols map[string]bool // 为空表示计算全部交易对
+	store   Store
+
+	mu         sync.Mutex
+	lastSample map[string]int64 // 交易对最近一次写入的深度时间（毫秒）
+}
+
+// NewAnalyzer 创建订单簿统计
+func NewAnalyzer(cfg config.BookStatsConfig, store Store) *Analyzer {
+	if len(cfg.Bands) == 0 {
+		cfg.Bands = []float64{0.1, 0.5, 1, 2}
+	}
+	bands := make([]float64, 0, len(cfg.Bands))
+	for _, band := range cfg.Bands {
+		if band <= 0 {
+			log.Printf("[BookStats] Ignored invalid band: %v\n", band)
+			continue
+		}
+		bands = append(bands, band)
+	}
+	cfg.Bands = bands
+	if cfg.ImbalanceLevels <= 0 {
+		cfg.ImbalanceLevels = 10
+	}
+	if cfg.SampleIntervalMs <= 0 {
+		cfg.SampleIntervalMs = 1000
+	}
+	if cfg.HistorySize <= 0 {
+		cfg.HistorySize = 3600
+	}
+
+	a := &Analyzer{
+		cfg:        cfg,
+		symbols:    make(map[string]bool, len(cfg.Symbols)),
+		store:      store,
+		lastSample: make(map[string]int64),
+	}
+	for _, symbol := range cfg.Symbols {
+		a.symbols[symbol] = true
+	}
+	return a
+}
+
+// RecordDepth 记录深度更新，bids 按价格从高到低、asks 按价格从低到高排列
+func (a *Analyzer) RecordDepth(symbol string, bids, asks []models.PriceLevel, timestamp int64) {
+	if len(a.symbols) > 0 && !a.symbols[symbol] {
+		return
+	}
+	if len(bids) == 0 || len(asks) == 0 {
+		return
+	}
+	if timestamp <= 0 {
+		timestamp = utils.GetCurrentTimestamp()
+	}
+
+	a.mu.Lock()
+	if timestamp-a.lastSample[symbol] < int64(a.cfg.SampleIntervalMs) {
+		a.mu.Unlock()
+		return
+	}
+	a.lastSample[symbol] = timestamp
+	a.mu.Unlock()
+
+	stats := a.compute(symbol, bids, asks, timestamp)
+	if err := a.store.SaveBookStats(stats, a.cfg.HistorySize); err != nil {
+		log.Printf("[BookStats] Failed to save %s: %v\n", symbol, err)
+	}
+}
+
+// RemoveSymbol 丢弃交易对的采样状态
+func (a *Analyzer) RemoveSymbol(symbol string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.lastSample, symbol)
+}
+
+// SymbolCount 正在计算统计的交易对数
+func (a *Analyzer) SymbolCount() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.lastSample)
+}
+
+// compute 计算价差、不平衡度和各范围内的累计挂单量
+func (a *Analyzer) compute(symbol string, bids, asks []models.PriceLevel, timestamp int64) *models.BookStats {
+	bid, ask := bids[0].Price, asks[0].Price
+	mid := (bid + ask) / 2
+	stats := &models.BookStats{
+		Symbol:    symbol,
+		BidPrice:  bid,
+		AskPrice:  ask,
+		MidPrice:  mid,
+		Spread:    ask - bid,
+		Liquidity: make([]models.LiquidityBand, 0, len(a.cfg.Bands)),
+		Timestamp: timestamp,
+	}
+	if mid > 0 {
+		stats.SpreadBps = (ask - bid) / mid * 10000
+	}
+
+	var bidQty, askQty float64
+	for i := 0; i < a.cfg.ImbalanceLevels && i < len(bids); i++ {
+		bidQty += bids[i].Amount
+	}
+	for i := 0; i < a.cfg.ImbalanceLevels && i < len(asks); i++ {
+		askQty += asks[i].Amount
+	}
+	if bidQty+askQty > 0 {
+		stats.Imbalance = (bidQty - askQty) / (bidQty + askQty)
+	}
+
+	for _, percent := range a.cfg.Bands {
+		band := models.LiquidityBand{Percent: percent}
+		low, high := mid*(1-percent/100), mid*(1+percent/100)
+		for _, level := range bids {
+			if level.Price < low {
+				break
+			}
+			band.BidAmount += level.Amount
+			band.BidNotional += level.Price * level.Amount
+		}
+		for _, level := range asks {
+			if level.Price > high {
+				break
+			}
+			band.AskAmount += level.Amount
+			band.AskNotional += level.Price * level.Amount
+		}
+		stats.Liquidity = append(stats.Liquidity, band)
+	}
+	return stats
+}
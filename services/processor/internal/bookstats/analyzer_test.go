@@ -0,0 +1,58 @@
+package bookstats
+
+import (
+	"market-system/common/config"
+	"market-system/common/models"
+	"math"
+	"testing"
+)
+
+type memoryStore struct {
+	saved []*models.BookStats
+}
+
+func (s *memoryStore) SaveBookStats(stats *models.BookStats, historySize int) error {
+	s.saved = append(s.saved, stats)
+	return nil
+}
+
+func TestBookStats(t *testing.T) {
+	store := &memoryStore{}
+	a := NewAnalyzer(config.BookStatsConfig{Bands: []float64{0.5, 2}, ImbalanceLevels: 2}, store)
+
+	bids := []models.PriceLevel{{Price: 99.9, Amount: 3}, {Price: 99.6, Amount: 1}, {Price: 98.5, Amount: 10}}
+	asks := []models.PriceLevel{{Price: 100.1, Amount: 1}, {Price: 100.4, Amount: 1}, {Price: 103, Amount: 10}}
+	a.RecordDepth("BTCUSDT", bids, asks, 10000)
+	// 采样间隔内的更新不计算
+	a.RecordDepth("BTCUSDT", bids, asks, 10500)
+
+	if len(store.saved) != 1 {
+		t.Fatalf("saved = %d", len(store.saved))
+	}
+	stats := store.saved[0]
+	if stats.MidPrice != 100 || math.Abs(stats.Spread-0.2) > 1e-9 || math.Abs(stats.SpreadBps-20) > 1e-9 {
+		t.Errorf("spread = %+v", stats)
+	}
+	// 前 2 档买量 4、卖量 2
+	if math.Abs(stats.Imbalance-1.0/3) > 1e-9 {
+		t.Errorf("imbalance = %v", stats.Imbalance)
+	}
+
+	if len(stats.Liquidity) != 2 {
+		t.Fatalf("liquidity = %+v", stats.Liquidity)
+	}
+	// ±0.5%：买盘 99.5 以上（99.9、99.6），卖盘 100.5 以下
+	if band := stats.Liquidity[0]; band.BidAmount != 4 || band.AskAmount != 2 || math.Abs(band.AskNotional-200.5) > 1e-9 {
+		t.Errorf("0.5%% band = %+v", band)
+	}
+	// ±2%：买盘 98 以上（含 98.5），卖盘 102 以下
+	if band := stats.Liquidity[1]; band.BidAmount != 14 || band.AskAmount != 2 {
+		t.Errorf("2%% band = %+v", band)
+	}
+
+	// 一侧没有挂单时不计算
+	a.RecordDepth("ETHUSDT", nil, asks, 20000)
+	if len(store.saved) != 1 {
+		t.Errorf("saved = %d", len(store.saved))
+	}
+}
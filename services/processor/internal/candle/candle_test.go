@@ -0,0 +1,133 @@
+package candle
+
+import (
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"testing"
+)
+
+type memoryStore struct {
+	saved     map[string][]*models.Kline // key 为数据类型，只记录收盘的
+	published map[string][]*models.KlineUpdate
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		saved:     make(map[string][]*models.Kline),
+		published: make(map[string][]*models.KlineUpdate),
+	}
+}
+
+func (s *memoryStore) SaveDerivedKline(dataType string, update *models.KlineUpdate) error {
+	if update.Closed {
+		k := update.Kline
+		s.saved[dataType] = append(s.saved[dataType], &k)
+	}
+	s.published[dataType] = append(s.published[dataType], update)
+	return nil
+}
+
+func (s *memoryStore) LatestDerivedKline(dataType, symbol, interval string) (*models.Kline, error) {
+	saved := s.saved[dataType]
+	if len(saved) == 0 {
+		return nil, nil
+	}
+	return saved[len(saved)-1], nil
+}
+
+func kline(openTime int64, open, high, low, close float64) *models.Kline {
+	return &models.Kline{
+		Symbol:    "BTCUSDT",
+		Interval:  "1m",
+		OpenTime:  openTime,
+		CloseTime: openTime + 59999,
+		Open:      open,
+		High:      high,
+		Low:       low,
+		Close:     close,
+		Volume:    1,
+		TradeNum:  1,
+	}
+}
+
+func TestHeikinAshi(t *testing.T) {
+	store := newMemoryStore()
+	ha := NewHeikinAshi(config.HeikinAshiConfig{Intervals: []string{"1m"}}, store)
+
+	ha.PublishKline(models.NewKlineUpdate(kline(0, 10, 14, 8, 12), true))
+	ha.PublishKline(models.NewKlineUpdate(kline(60000, 12, 13, 11, 12.5), false))
+	ha.PublishKline(models.NewKlineUpdate(kline(60000, 12, 16, 11, 15), true))
+	// 已收盘的K线被改写时不重新计算
+	ha.PublishKline(models.NewKlineUpdate(kline(0, 10, 14, 8, 13), true))
+	// 未计算的周期
+	ha.PublishKline(models.NewKlineUpdate(&models.Kline{Symbol: "BTCUSDT", Interval: "5m", Close: 1}, true))
+
+	saved := store.saved[constants.DataTypeHeikinAshi]
+	if len(saved) != 2 || len(store.published[constants.DataTypeHeikinAshi]) != 3 {
+		t.Fatalf("saved %d, published %d", len(saved), len(store.published[constants.DataTypeHeikinAshi]))
+	}
+	// 第一根：收 = (10+14+8+12)/4 = 11，开 = (10+12)/2 = 11
+	if k := saved[0]; k.Open != 11 || k.Close != 11 || k.High != 14 || k.Low != 8 || !k.IsFinal {
+		t.Errorf("first = %+v", k)
+	}
+	// 第二根：收 = (12+16+11+15)/4 = 13.5，开 = (11+11)/2 = 11，低取平均开盘价 11
+	if k := saved[1]; k.Open != 11 || k.Close != 13.5 || k.High != 16 || k.Low != 11 {
+		t.Errorf("second = %+v", k)
+	}
+	if live := store.published[constants.DataTypeHeikinAshi][1]; live.Closed || live.Close != 12.125 || live.Open != 11 {
+		t.Errorf("live = %+v", live)
+	}
+
+	// 重启后从存储加载上一根
+	restarted := NewHeikinAshi(config.HeikinAshiConfig{Intervals: []string{"1m"}}, store)
+	restarted.PublishKline(models.NewKlineUpdate(kline(120000, 15, 15, 15, 15), true))
+	if k := store.saved[constants.DataTypeHeikinAshi][2]; k.Open != 12.25 || k.Close != 15 {
+		t.Errorf("after restart = %+v", k)
+	}
+}
+
+func TestRenko(t *testing.T) {
+	store := newMemoryStore()
+	renko := NewRenko(config.RenkoConfig{BrickSize: map[string]float64{"BTCUSDT": 10}}, store)
+
+	closes := []float64{
+		103, // 起点 100
+		109, // 不足一块砖
+		131, // 110、120、130 三块上涨砖
+		115, // 反转需要低于 120
+		99,  // 120->110、110->100 两块下跌砖
+	}
+	for i, c := range closes {
+		update := models.NewKlineUpdate(kline(int64(i)*60000, c, c, c, c), true)
+		renko.PublishKline(update)
+		// 实时K线和重复的收盘K线不生成砖块
+		renko.PublishKline(models.NewKlineUpdate(kline(int64(i)*60000, c+50, c+50, c+50, c+50), false))
+		renko.PublishKline(update)
+	}
+
+	bricks := store.saved[constants.DataTypeRenko]
+	want := [][2]float64{{100, 110}, {110, 120}, {120, 130}, {120, 110}, {110, 100}}
+	if len(bricks) != len(want) {
+		t.Fatalf("bricks = %d, want %d", len(bricks), len(want))
+	}
+	for i, w := range want {
+		if bricks[i].Open != w[0] || bricks[i].Close != w[1] {
+			t.Errorf("brick %d = %v->%v, want %v->%v", i, bricks[i].Open, bricks[i].Close, w[0], w[1])
+		}
+	}
+	// 同一根K线的砖块开始时间依次加 1 毫秒，成交量计入第一块
+	if bricks[0].OpenTime != 120000 || bricks[1].OpenTime != 120001 || bricks[2].OpenTime != 120002 {
+		t.Errorf("open times = %d, %d, %d", bricks[0].OpenTime, bricks[1].OpenTime, bricks[2].OpenTime)
+	}
+	if bricks[0].Volume != 2 || bricks[1].Volume != 0 || bricks[3].Volume != 2 {
+		t.Errorf("volumes = %v, %v, %v", bricks[0].Volume, bricks[1].Volume, bricks[3].Volume)
+	}
+
+	// 重启后从最后一块砖继续
+	restarted := NewRenko(config.RenkoConfig{BrickPercent: 1}, store)
+	restarted.PublishKline(models.NewKlineUpdate(kline(300000, 89, 89, 89, 89), true))
+	if bricks := store.saved[constants.DataTypeRenko]; len(bricks) != 6 || bricks[5].Open != 100 || bricks[5].Close != 90 {
+		t.Errorf("after restart = %+v", bricks[len(bricks)-1])
+	}
+}
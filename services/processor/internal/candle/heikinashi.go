@@ -0,0 +1,137 @@
+package candle
+
+import (
+	"log"
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"math"
+	"sync"
+)
+
+// Store 平均K线、砖块的存储接口
+type Store interface {
+	SaveDerivedKline(dataType string, update *models.KlineUpdate) error
+	LatestDerivedKline(dataType, symbol, interval string) (*models.Kline, error)
+}
+
+// HeikinAshi 平均K线计算
+// 作为K线推送目标接收实时K线和收盘K线：收盘 = (开 + 高 + 低 + 收) / 4，开盘 = (上一根的开盘 + 收盘) / 2，
+// 最高、最低取原K线与平均K线开盘、收盘中的最大、最小值。
+// 收盘K线保存并作为下一根的基础，实时K线基于上一根临时计算后只推送；
+// 每个交易对、周期首次收到时从存储加载上一根平均K线，没有时第一根的开盘价为 (开 + 收) / 2。
+type HeikinAshi struct {
+	intervals map[string]bool
+	store     Store
+
+	mu   sync.Mutex
+	last map[string]*models.Kline // key 为 {symbol}:{interval}，最后一根收盘的平均K线，为 nil 表示还没有
+}
+
+// NewHeikinAshi 创建平均K线计算
+func NewHeikinAshi(cfg config.HeikinAshiConfig, store Store) *HeikinAshi {
+	return &HeikinAshi{
+		intervals: intervalSet("HeikinAshi", cfg.Intervals, []string{"1m", "5m", "15m", "1h", "4h", "1d"}),
+		store:     store,
+		last:      make(map[string]*models.Kline),
+	}
+}
+
+// PublishKline 接收K线更新，计算并推送平均K线
+func (h *HeikinAshi) PublishKline(update *models.KlineUpdate) error {
+	if !h.intervals[update.Interval] {
+		return nil
+	}
+
+	key := update.Symbol + ":" + update.Interval
+	h.mu.Lock()
+	_, ok := h.last[key]
+	h.mu.Unlock()
+	if !ok {
+		last, err := h.store.LatestDerivedKline(constants.DataTypeHeikinAshi, update.Symbol, update.Interval)
+		if err != nil {
+			return err
+		}
+		h.mu.Lock()
+		if _, ok := h.last[key]; !ok {
+			h.last[key] = last
+		}
+		h.mu.Unlock()
+	}
+
+	ha := h.update(key, update)
+	if ha == nil {
+		return nil
+	}
+	return h.store.SaveDerivedKline(constants.DataTypeHeikinAshi, ha)
+}
+
+// RemoveSymbol 丢弃交易对各周期的平均K线
+func (h *HeikinAshi) RemoveSymbol(symbol string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for interval := range h.intervals {
+		delete(h.last, symbol+":"+interval)
+	}
+}
+
+// SeriesCount 正在计算平均K线的交易对、周期数
+func (h *HeikinAshi) SeriesCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.last)
+}
+
+// update 计算平均K线，收盘K线推进序列；不早于最后一根已收盘平均K线的更新（补齐、改写）忽略
+func (h *HeikinAshi) update(key string, update *models.KlineUpdate) *models.KlineUpdate {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	prev := h.last[key]
+	if prev != nil && update.OpenTime <= prev.OpenTime {
+		return nil
+	}
+
+	ha := heikinAshi(prev, &update.Kline)
+	if update.Closed {
+		ha.IsFinal = true
+		h.last[key] = ha
+	}
+	return &models.KlineUpdate{
+		Kline:    *ha,
+		UpdateID: update.UpdateID,
+		Closed:   update.Closed,
+		Checksum: models.KlineChecksum(ha),
+	}
+}
+
+// heikinAshi 由上一根平均K线和当前K线计算平均K线，prev 为 nil 时为第一根
+func heikinAshi(prev, k *models.Kline) *models.Kline {
+	ha := *k
+	ha.Close = (k.Open + k.High + k.Low + k.Close) / 4
+	if prev == nil {
+		ha.Open = (k.Open + k.Close) / 2
+	} else {
+		ha.Open = (prev.Open + prev.Close) / 2
+	}
+	ha.High = math.Max(k.High, math.Max(ha.Open, ha.Close))
+	ha.Low = math.Min(k.Low, math.Min(ha.Open, ha.Close))
+	return &ha
+}
+
+// intervalSet 校验配置的周期，为空时使用默认周期
+func intervalSet(component string, intervals, defaults []string) map[string]bool {
+	if len(intervals) == 0 {
+		intervals = defaults
+	}
+	set := make(map[string]bool, len(intervals))
+	for _, interval := range intervals {
+		if !utils.ValidateInterval(interval) {
+			log.Printf("[%s] Invalid interval %q, skipped\n", component, interval)
+			continue
+		}
+		set[interval] = true
+	}
+	return set
+}
@@ -0,0 +1,194 @@
+package candle
+
+import (
+	"log"
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"math"
+	"sync"
+)
+
+// maxBricksPerKline 一根K线最多生成的砖块数，超过时视为砖块大小配置不当，从当前收盘价重新开始
+const maxBricksPerKline = 100
+
+// Renko 砖形图计算
+// 作为K线推送目标只处理收盘K线：收盘价高于上一块砖的顶部一个砖块大小时生成上涨砖，低于底部一个砖块大小时生成下跌砖，
+// 一根K线可生成多块砖（反转需要两个砖块大小）。砖块以K线格式保存，开高低收为砖块的边界，
+// 开始时间取生成砖块的K线开盘时间（同一根K线的后续砖块依次加 1 毫秒，保证唯一），成交量为上一块砖之后累计的成交量。
+// 每个交易对、周期首次收到时从存储加载最后一块砖，没有时以首个收盘价所在的砖块边界开始。
+type Renko struct {
+	cfg       config.RenkoConfig
+	intervals map[string]bool
+	store     Store
+
+	mu     sync.Mutex
+	series map[string]*bricks // key 为 {symbol}:{interval}
+}
+
+// bricks 单个交易对、周期的砖块状态
+type bricks struct {
+	size      float64 // 砖块大小
+	top       float64 // 最后一块砖的顶部
+	bottom    float64 // 最后一块砖的底部，还没有砖块时与顶部相同
+	lastOpen  int64   // 最后一块砖的开始时间
+	lastClose int64   // 最后处理的收盘K线的收盘时间
+
+	// 上一块砖之后累计的成交
+	volume   float64
+	quoteVol float64
+	tradeNum int64
+}
+
+// NewRenko 创建砖形图计算
+func NewRenko(cfg config.RenkoConfig, store Store) *Renko {
+	return &Renko{
+		cfg:       cfg,
+		intervals: intervalSet("Renko", cfg.Intervals, []string{"1m"}),
+		store:     store,
+		series:    make(map[string]*bricks),
+	}
+}
+
+// PublishKline 接收K线更新，收盘K线生成砖块后保存并推送
+func (r *Renko) PublishKline(update *models.KlineUpdate) error {
+	if !update.Closed || !r.intervals[update.Interval] || update.Close <= 0 {
+		return nil
+	}
+	size := r.cfg.BrickSize[update.Symbol]
+	if size <= 0 && r.cfg.BrickPercent <= 0 {
+		return nil
+	}
+
+	key := update.Symbol + ":" + update.Interval
+	r.mu.Lock()
+	_, ok := r.series[key]
+	r.mu.Unlock()
+	if !ok {
+		last, err := r.store.LatestDerivedKline(constants.DataTypeRenko, update.Symbol, update.Interval)
+		if err != nil {
+			return err
+		}
+		r.mu.Lock()
+		if _, ok := r.series[key]; !ok {
+			r.series[key] = r.restore(last, size)
+		}
+		r.mu.Unlock()
+	}
+
+	for _, brick := range r.update(key, &update.Kline, size) {
+		if err := r.store.SaveDerivedKline(constants.DataTypeRenko, models.NewKlineUpdate(brick, true)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveSymbol 丢弃交易对各周期的砖块状态
+func (r *Renko) RemoveSymbol(symbol string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for interval := range r.intervals {
+		delete(r.series, symbol+":"+interval)
+	}
+}
+
+// SeriesCount 正在生成砖块的交易对、周期数
+func (r *Renko) SeriesCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.series)
+}
+
+// restore 由最后一块砖恢复砖块状态，没有砖块时返回 nil；
+// 配置了砖块大小时使用配置的大小，否则沿用最后一块砖的大小
+func (r *Renko) restore(last *models.Kline, size float64) *bricks {
+	if last == nil {
+		return nil
+	}
+	if size <= 0 {
+		size = math.Abs(last.Close - last.Open)
+	}
+	return &bricks{
+		size:      size,
+		top:       math.Max(last.Open, last.Close),
+		bottom:    math.Min(last.Open, last.Close),
+		lastOpen:  last.OpenTime,
+		lastClose: last.CloseTime,
+	}
+}
+
+// update 并入收盘K线，返回新生成的砖块；已处理过的K线（补齐、改写）忽略
+func (r *Renko) update(key string, k *models.Kline, size float64) []*models.Kline {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b := r.series[key]
+	if b == nil {
+		if size <= 0 {
+			size = k.Close * r.cfg.BrickPercent / 100
+		}
+		anchor := math.Floor(k.Close/size) * size
+		r.series[key] = &bricks{size: size, top: anchor, bottom: anchor, lastClose: k.CloseTime}
+		return nil
+	}
+	if k.CloseTime <= b.lastClose {
+		return nil
+	}
+	return b.add(k)
+}
+
+// add 累计K线的成交并生成砖块
+func (b *bricks) add(k *models.Kline) []*models.Kline {
+	b.lastClose = k.CloseTime
+	b.volume += k.Volume
+	b.quoteVol += k.QuoteVol
+	b.tradeNum += k.TradeNum
+
+	if n := math.Max((k.Close-b.top)/b.size, (b.bottom-k.Close)/b.size); n > maxBricksPerKline {
+		log.Printf("[Renko] %s %s moved %.0f bricks in one kline, restarting from %v\n", k.Symbol, k.Interval, n, k.Close)
+		b.top = math.Floor(k.Close/b.size) * b.size
+		b.bottom = b.top
+		return nil
+	}
+
+	var result []*models.Kline
+	for k.Close >= b.top+b.size {
+		result = append(result, b.brick(k, b.top, b.top+b.size))
+		b.bottom = b.top
+		b.top += b.size
+	}
+	for k.Close <= b.bottom-b.size {
+		result = append(result, b.brick(k, b.bottom, b.bottom-b.size))
+		b.top = b.bottom
+		b.bottom -= b.size
+	}
+	return result
+}
+
+// brick 生成一块砖，第一块砖带上累计的成交
+func (b *bricks) brick(k *models.Kline, open, close float64) *models.Kline {
+	openTime := k.OpenTime
+	if openTime <= b.lastOpen {
+		openTime = b.lastOpen + 1
+	}
+	b.lastOpen = openTime
+
+	brick := &models.Kline{
+		Symbol:    k.Symbol,
+		Interval:  k.Interval,
+		OpenTime:  openTime,
+		CloseTime: k.CloseTime,
+		Open:      open,
+		High:      math.Max(open, close),
+		Low:       math.Min(open, close),
+		Close:     close,
+		Volume:    b.volume,
+		QuoteVol:  b.quoteVol,
+		TradeNum:  b.tradeNum,
+		Source:    k.Source,
+		IsFinal:   true,
+	}
+	b.volume, b.quoteVol, b.tradeNum = 0, 0, 0
+	return brick
+}
@@ -0,0 +1,342 @@
+package consistency
+
+import (
+	"fmt"
+	"log"
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/exchange"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store 交易对注册表读取接口
+type Store interface {
+	ExternalOnlySymbols() (map[string]string, error)
+}
+
+// History 已保存的K线查询接口，返回开盘时间在 [startTime, endTime] 内的K线，按开盘时间倒序
+type History interface {
+	GetKlines(symbol, interval string, startTime, endTime, limit int64) ([]*models.Kline, error)
+}
+
+// Live 本地K线聚合，不检查本地正在聚合的K线
+type Live interface {
+	CurrentOpenTime(symbol, interval string) int64
+}
+
+// Repairer 修复K线的写入接口（经 FanoutStorage 写入全部后端）
+type Repairer interface {
+	SaveKline(kline *models.Kline) error
+}
+
+// Publisher 修复后的K线推送接口
+type Publisher interface {
+	PublishKline(update *models.KlineUpdate) error
+}
+
+// Reporter 检查报告写入接口
+type Reporter interface {
+	SaveConsistencyReport(report *models.KlineConsistencyReport) error
+}
+
+// maxLookback 单次请求交易所的最大K线数（OKX 最多 100 根）
+const maxLookback = 100
+
+// maxDiscrepancies 报告中保留的偏差条数
+const maxDiscrepancies = 200
+
+// Checker 定期比较本地聚合的 1m K线与交易所 REST K线
+// 只检查仅使用外部数据的交易对（混合模式的交易对包含内部成交，与交易所K线本来就不同），
+// 检查范围为本地正在聚合的K线之前最近 Lookback 根已收盘的K线。偏差超过容差或本地缺失的K线记入报告，
+// 开启修复时以交易所K线覆盖本地K线（Redis 中的K线修订号随之递增）并推送。
+type Checker struct {
+	cfg       config.ConsistencyConfig
+	store     Store
+	history   History
+	live      Live
+	repairer  Repairer
+	publisher Publisher // 为 nil 表示修复后不推送
+	reporter  Reporter
+
+	clients map[string]exchange.RESTClient // 按交易所缓存的 REST 客户端
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// Stats 一致性检查统计（累计）
+type Stats struct {
+	Runs       int64
+	Compared   int64
+	Mismatched int64
+	Missing    int64
+	Repaired   int64
+}
+
+// NewChecker 创建K线一致性检查
+func NewChecker(cfg config.ConsistencyConfig, store Store, history History, live Live, repairer Repairer, publisher Publisher, reporter Reporter) *Checker {
+	if cfg.IntervalSec <= 0 {
+		cfg.IntervalSec = 300
+	}
+	if cfg.Lookback <= 0 {
+		cfg.Lookback = 60
+	}
+	if cfg.Lookback > maxLookback {
+		cfg.Lookback = maxLookback
+	}
+	if cfg.PriceTolerance <= 0 {
+		cfg.PriceTolerance = 0.1
+	}
+	if cfg.VolumeTolerance <= 0 {
+		cfg.VolumeTolerance = 1
+	}
+	if cfg.Source == "" {
+		cfg.Source = constants.ExchangeBinance
+	}
+	return &Checker{
+		cfg:       cfg,
+		store:     store,
+		history:   history,
+		live:      live,
+		repairer:  repairer,
+		publisher: publisher,
+		reporter:  reporter,
+		clients:   make(map[string]exchange.RESTClient),
+	}
+}
+
+// Run 按检查间隔执行检查，直到 stop 关闭
+func (c *Checker) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Duration(c.cfg.IntervalSec) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			report := c.Check(utils.GetCurrentTimestamp(), stop)
+			if err := c.reporter.SaveConsistencyReport(report); err != nil {
+				log.Printf("[Consistency] Failed to save report: %v\n", err)
+			}
+		}
+	}
+}
+
+// Check 检查全部交易对截至 now 的已收盘K线，返回检查报告
+func (c *Checker) Check(now int64, stop <-chan struct{}) *models.KlineConsistencyReport {
+	report := &models.KlineConsistencyReport{
+		Interval:      constants.Interval1m,
+		Discrepancies: []models.KlineDiscrepancy{},
+		EndTime:       utils.GetKlineOpenTime(now, constants.Interval1m),
+		Timestamp:     now,
+	}
+	report.StartTime = report.EndTime - int64(c.cfg.Lookback)*constants.Minute
+
+	symbols, err := c.symbols()
+	if err != nil {
+		log.Printf("[Consistency] Failed to get symbols: %v\n", err)
+		report.Errors = append(report.Errors, err.Error())
+		return report
+	}
+
+	names := make([]string, 0, len(symbols))
+	for symbol := range symbols {
+		names = append(names, symbol)
+	}
+	sort.Strings(names)
+
+	for _, symbol := range names {
+		select {
+		case <-stop:
+			return report
+		default:
+		}
+
+		if err := c.checkSymbol(symbol, symbols[symbol], report); err != nil {
+			log.Printf("[Consistency] Failed to check %s: %v\n", symbol, err)
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", symbol, err))
+			continue
+		}
+		report.Symbols++
+	}
+
+	c.mu.Lock()
+	c.stats.Runs++
+	c.stats.Compared += int64(report.Compared)
+	c.stats.Mismatched += int64(report.Mismatched)
+	c.stats.Missing += int64(report.Missing)
+	c.stats.Repaired += int64(report.Repaired)
+	c.mu.Unlock()
+
+	if report.Mismatched > 0 || report.Missing > 0 {
+		log.Printf("[Consistency] Checked %d symbols: compared %d, mismatched %d, missing %d, repaired %d\n",
+			report.Symbols, report.Compared, report.Mismatched, report.Missing, report.Repaired)
+	}
+	return report
+}
+
+// Stats 获取累计统计
+func (c *Checker) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// symbols 需要检查的交易对及其外部数据源交易所
+func (c *Checker) symbols() (map[string]string, error) {
+	symbols, err := c.store.ExternalOnlySymbols()
+	if err != nil {
+		return nil, err
+	}
+	for _, symbol := range c.cfg.Symbols {
+		if _, ok := symbols[symbol]; !ok {
+			symbols[symbol] = ""
+		}
+	}
+	for symbol, source := range symbols {
+		if source == "" {
+			symbols[symbol] = c.cfg.Source
+		}
+	}
+	return symbols, nil
+}
+
+// client 获取交易所的 REST 客户端，不支持的交易所返回 nil
+func (c *Checker) client(name string) exchange.RESTClient {
+	client, ok := c.clients[name]
+	if !ok {
+		client = exchange.NewRESTClient(name)
+		c.clients[name] = client
+	}
+	return client
+}
+
+// checkSymbol 检查单个交易对，偏差计入报告
+func (c *Checker) checkSymbol(symbol, source string, report *models.KlineConsistencyReport) error {
+	client := c.client(source)
+	if client == nil {
+		return fmt.Errorf("unsupported source: %s", source)
+	}
+
+	// 不检查本地正在聚合的K线
+	start, end := report.StartTime, report.EndTime
+	if open := c.live.CurrentOpenTime(symbol, constants.Interval1m); open > 0 && open < end {
+		end = open
+	}
+	if start >= end {
+		return nil
+	}
+
+	remote, err := client.GetKlines(symbol, constants.Interval1m, start, end-1, c.cfg.Lookback)
+	if err != nil {
+		return err
+	}
+
+	local, err := c.history.GetKlines(symbol, constants.Interval1m, start, end-1, int64(c.cfg.Lookback))
+	if err != nil {
+		return err
+	}
+	byOpenTime := make(map[int64]*models.Kline, len(local))
+	for _, k := range local {
+		byOpenTime[k.OpenTime] = k
+	}
+
+	for _, r := range remote {
+		if r.OpenTime < start || r.OpenTime >= end {
+			continue
+		}
+		report.Compared++
+
+		d, ok := c.compare(byOpenTime[r.OpenTime], r)
+		if !ok {
+			continue
+		}
+		d.Symbol, d.Exchange, d.OpenTime = symbol, client.Name(), r.OpenTime
+		if d.Field == fieldMissing {
+			report.Missing++
+		} else {
+			report.Mismatched++
+		}
+
+		if c.cfg.Repair {
+			if err := c.repair(r, client.Name()); err != nil {
+				log.Printf("[Consistency] Failed to repair %s %d: %v\n", symbol, r.OpenTime, err)
+			} else {
+				d.Repaired = true
+				report.Repaired++
+			}
+		}
+		if len(report.Discrepancies) < maxDiscrepancies {
+			report.Discrepancies = append(report.Discrepancies, d)
+		}
+	}
+	return nil
+}
+
+// fieldMissing 本地缺失的K线
+const fieldMissing = "missing"
+
+// compare 比较本地K线与交易所K线，返回偏差最大的字段；都在容差内时返回 false
+func (c *Checker) compare(local, remote *models.Kline) (models.KlineDiscrepancy, bool) {
+	if local == nil {
+		return models.KlineDiscrepancy{Field: fieldMissing, Remote: remote.Close}, true
+	}
+
+	fields := []struct {
+		name          string
+		local, remote float64
+		tolerance     float64
+	}{
+		{"open", local.Open, remote.Open, c.cfg.PriceTolerance},
+		{"high", local.High, remote.High, c.cfg.PriceTolerance},
+		{"low", local.Low, remote.Low, c.cfg.PriceTolerance},
+		{"close", local.Close, remote.Close, c.cfg.PriceTolerance},
+		{"volume", local.Volume, remote.Volume, c.cfg.VolumeTolerance},
+	}
+
+	var worst models.KlineDiscrepancy
+	worstRatio := 1.0 // 偏差与容差之比，大于 1 即超过容差
+	for _, f := range fields {
+		diff := relativeDiff(f.local, f.remote) * 100
+		if ratio := diff / f.tolerance; ratio > worstRatio {
+			worstRatio = ratio
+			worst = models.KlineDiscrepancy{Field: f.name, Local: f.local, Remote: f.remote, Diff: diff}
+		}
+	}
+	return worst, worst.Field != ""
+}
+
+// repair 以交易所K线覆盖本地K线并推送
+func (c *Checker) repair(remote *models.Kline, source string) error {
+	kline := *remote
+	// OKX 不返回收盘时间，单根K线时无法推算
+	if kline.CloseTime <= kline.OpenTime {
+		kline.CloseTime = utils.GetKlineCloseTime(kline.OpenTime, kline.Interval)
+	}
+	kline.Source = source
+	kline.IsFinal = true
+
+	if err := c.repairer.SaveKline(&kline); err != nil {
+		return err
+	}
+	if c.publisher != nil {
+		if err := c.publisher.PublishKline(models.NewKlineUpdate(&kline, true)); err != nil {
+			log.Printf("[Consistency] Failed to publish repaired %s %d: %v\n", kline.Symbol, kline.OpenTime, err)
+		}
+	}
+	return nil
+}
+
+// relativeDiff 相对偏差
+func relativeDiff(a, b float64) float64 {
+	if a == b {
+		return 0
+	}
+	return math.Abs(a-b) / math.Max(math.Abs(a), math.Abs(b))
+}
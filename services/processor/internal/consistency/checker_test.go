@@ -0,0 +1,100 @@
+package consistency
+
+import (
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/exchange"
+	"market-system/common/models"
+	"testing"
+)
+
+type fakeStore map[string]string
+
+func (s fakeStore) ExternalOnlySymbols() (map[string]string, error) {
+	symbols := make(map[string]string, len(s))
+	for k, v := range s {
+		symbols[k] = v
+	}
+	return symbols, nil
+}
+
+// fakeHistory 按开盘时间倒序返回保存的K线
+type fakeHistory struct {
+	klines []*models.Kline
+	saved  []*models.Kline
+}
+
+func (h *fakeHistory) GetKlines(symbol, interval string, startTime, endTime, limit int64) ([]*models.Kline, error) {
+	return h.klines, nil
+}
+
+func (h *fakeHistory) SaveKline(kline *models.Kline) error {
+	h.saved = append(h.saved, kline)
+	return nil
+}
+
+type noLive struct{}
+
+func (noLive) CurrentOpenTime(symbol, interval string) int64 { return 0 }
+
+// fakeClient 交易所K线按开盘时间升序返回
+type fakeClient struct {
+	exchange.RESTClient
+	klines []*models.Kline
+}
+
+func (c *fakeClient) Name() string { return "fake" }
+
+func (c *fakeClient) GetKlines(symbol, interval string, startTime, endTime int64, limit int) ([]*models.Kline, error) {
+	return c.klines, nil
+}
+
+func kline(openTime int64, close, volume float64) *models.Kline {
+	return &models.Kline{
+		Symbol:    "BTCUSDT",
+		Interval:  constants.Interval1m,
+		OpenTime:  openTime,
+		CloseTime: openTime + constants.Minute - 1,
+		Open:      100,
+		High:      110,
+		Low:       90,
+		Close:     close,
+		Volume:    volume,
+	}
+}
+
+func TestCheckerReportAndRepair(t *testing.T) {
+	const now = int64(1700000040000) // 1700000040000 为整分钟
+	start := now - 3*constants.Minute
+
+	history := &fakeHistory{klines: []*models.Kline{
+		kline(start+2*constants.Minute, 100, 10.5), // 成交量偏差 5%
+		kline(start, 100, 10),
+	}}
+	client := &fakeClient{klines: []*models.Kline{
+		kline(start, 100.05, 10.05), // 在容差内
+		kline(start+constants.Minute, 101, 3),
+		kline(start+2*constants.Minute, 100, 10),
+	}}
+
+	c := NewChecker(config.ConsistencyConfig{Lookback: 3, Repair: true, Source: "fake"},
+		fakeStore{"BTCUSDT": ""}, history, noLive{}, history, nil, nil)
+	c.clients["fake"] = client
+
+	report := c.Check(now, nil)
+	if report.Symbols != 1 || report.Compared != 3 || report.Missing != 1 || report.Mismatched != 1 || report.Repaired != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if len(report.Discrepancies) != 2 {
+		t.Fatalf("got %d discrepancies, want 2", len(report.Discrepancies))
+	}
+	if d := report.Discrepancies[0]; d.Field != fieldMissing || d.OpenTime != start+constants.Minute || !d.Repaired {
+		t.Errorf("unexpected missing discrepancy: %+v", d)
+	}
+	if d := report.Discrepancies[1]; d.Field != "volume" || d.Local != 10.5 || d.Remote != 10 {
+		t.Errorf("unexpected volume discrepancy: %+v", d)
+	}
+	if len(history.saved) != 2 || history.saved[0].Source != "fake" || !history.saved[0].IsFinal {
+		t.Errorf("unexpected repaired klines: %+v", history.saved)
+	}
+}
@@ -0,0 +1,162 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// 死信消息头，原消息的消息头（包括版本消息头）原样保留
+const (
+	HeaderSourceTopic = "x-source-topic" // 原 topic，重新投递时写回该 topic
+	HeaderError       = "x-error"        // 最后一次处理的错误
+	HeaderAttempts    = "x-attempts"     // 处理次数，0 表示未处理（消息格式版本无法识别）
+	HeaderFailedAt    = "x-failed-at"    // 转入死信队列的时间（毫秒）
+)
+
+// dlqWriteTimeout 写入死信队列的超时
+const dlqWriteTimeout = 5 * time.Second
+
+// retryBackoff 每次重试前等待的时间，按重试次数递增
+const retryBackoff = 50 * time.Millisecond
+
+// PoisonStats 处理失败统计
+type PoisonStats struct {
+	Retries      int64 // 重试次数
+	DeadLettered int64 // 重试后仍失败、转入死信队列的消息数
+	Discarded    int64 // 重试后仍失败、未转入死信队列（未启用或写入失败）的消息数
+}
+
+// EnableDLQ 启用死信队列（需在 Start 前调用）
+// unknownSchema 为 true 时消息格式版本无法识别的消息转入死信队列，poison 为 true 时多次处理失败的消息转入死信队列
+func (c *KafkaConsumer) EnableDLQ(topic string, unknownSchema, poison bool) {
+	if !unknownSchema && !poison {
+		return
+	}
+
+	c.dlq = &kafka.Writer{
+		Addr:         kafka.TCP(c.brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+	}
+	c.unknownToDLQ = unknownSchema
+	c.poisonToDLQ = poison
+	log.Printf("[Kafka Consumer] DLQ: %s (unknown schema: %v, poison messages: %v)\n", topic, unknownSchema, poison)
+}
+
+// SetMaxRetries 设置处理失败后的重试次数（需在 Start 前调用）
+func (c *KafkaConsumer) SetMaxRetries(n int) {
+	if n < 0 {
+		n = 0
+	}
+	c.maxRetries = n
+}
+
+// Handle 处理消息，失败（包括 panic）时重试，重试后仍失败的消息转入死信队列
+// 重试在调用方的 goroutine 中同步执行，同一交易对的后续消息在重试结束后才处理，保证顺序
+func (c *KafkaConsumer) Handle(topic string, data *models.MarketMessage, handle MessageHandler) error {
+	var err error
+	attempts := 0
+	for attempts <= c.maxRetries {
+		if attempts > 0 {
+			c.mu.Lock()
+			c.poison.Retries++
+			c.mu.Unlock()
+			time.Sleep(time.Duration(attempts) * retryBackoff)
+		}
+		attempts++
+
+		if err = safeHandle(handle, data); err == nil {
+			return nil
+		}
+	}
+
+	c.deadLetter(context.Background(), topic, originalMessage(data), err, attempts)
+	return fmt.Errorf("failed after %d attempts: %w", attempts, err)
+}
+
+// originalMessage 还原消费到的 Kafka 消息（原文和消息头），不是从 Kafka 消费的消息按当前格式重新编码
+func originalMessage(data *models.MarketMessage) kafka.Message {
+	msg := kafka.Message{Key: []byte(data.Symbol), Value: data.Raw}
+	if msg.Value == nil {
+		value, err := json.Marshal(data)
+		if err != nil {
+			value = data.Data
+		}
+		msg.Value = value
+	}
+	msg.Headers = make([]kafka.Header, len(data.Headers))
+	for i, h := range data.Headers {
+		msg.Headers[i] = kafka.Header(h)
+	}
+	return msg
+}
+
+// PoisonStats 获取处理失败统计
+func (c *KafkaConsumer) PoisonStats() PoisonStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.poison
+}
+
+// safeHandle 执行处理函数，panic 转换为错误
+func safeHandle(handle MessageHandler, data *models.MarketMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handle(data)
+}
+
+// deadLetter 将处理失败的消息转入死信队列，未启用时只计数
+func (c *KafkaConsumer) deadLetter(ctx context.Context, topic string, msg kafka.Message, reason error, attempts int) {
+	if c.poisonToDLQ {
+		err := c.writeDLQ(ctx, topic, msg, reason, attempts)
+		if err == nil {
+			c.mu.Lock()
+			c.poison.DeadLettered++
+			c.mu.Unlock()
+			return
+		}
+		log.Printf("[Kafka Consumer] Failed to write to DLQ: %v\n", err)
+	}
+
+	c.mu.Lock()
+	c.poison.Discarded++
+	c.mu.Unlock()
+	log.Printf("[Kafka Consumer] Discarded message from %s (key %s) after %d attempts: %v\n", topic, msg.Key, attempts, reason)
+}
+
+// writeDLQ 写入死信队列，附加原 topic 和错误信息
+func (c *KafkaConsumer) writeDLQ(ctx context.Context, topic string, msg kafka.Message, reason error, attempts int) error {
+	headers := make([]kafka.Header, 0, len(msg.Headers)+4)
+	headers = append(headers, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: HeaderSourceTopic, Value: []byte(topic)},
+		kafka.Header{Key: HeaderError, Value: []byte(reason.Error())},
+		kafka.Header{Key: HeaderAttempts, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: HeaderFailedAt, Value: []byte(strconv.FormatInt(utils.GetCurrentTimestamp(), 10))},
+	)
+
+	ctx, cancel := context.WithTimeout(ctx, dlqWriteTimeout)
+	defer cancel()
+	return c.dlq.WriteMessages(ctx, kafka.Message{Key: msg.Key, Value: msg.Value, Headers: headers})
+}
+
+// IsDeadLetterHeader 是否为转入死信队列时附加的消息头，重新投递时去除
+func IsDeadLetterHeader(key string) bool {
+	switch key {
+	case HeaderSourceTopic, HeaderError, HeaderAttempts, HeaderFailedAt:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// 按分区消费
+// 多个处理服务实例组成同一个消费组时，消息按交易对作为 key 写入，同一交易对的消息总在同一分区，
+// 由持有该分区的实例按顺序处理。分区在实例之间转移（扩缩容、实例重启）时：
+//  1. 原实例停止读取该分区，回调 PartitionRevoked，由处理方保存该分区的内存状态和对应的消费位置，然后提交位置
+//  2. 新实例分配到该分区后回调 PartitionAssigned，由处理方加载保存的状态，并返回状态对应的消费位置
+//  3. 从已提交位置和状态位置中较新的一个继续读取，保证状态中已计入的消息不会重复处理
+//
+// 同一订阅中的多个 topic 在同一个消费组成员中消费，分区数相同的 topic 的同号分区分配给同一实例，
+// 因此同一交易对的成交和K线由同一实例处理。
+
+// commitInterval 按分区消费时提交消费位置的间隔
+const commitInterval = time.Second
+
+// RebalanceListener 分区分配变化回调，在分区的消费协程中调用，回调期间该分区不读取消息
+type RebalanceListener interface {
+	// PartitionAssigned 分区分配到本实例，committed 为已提交的位置（没有时为 -1），
+	// 返回保存的状态对应的下一条消息位置，没有保存的状态时返回 -1
+	PartitionAssigned(topic string, partition int, committed int64) int64
+	// PartitionRevoked 分区即将从本实例收回，next 为下一条未处理消息的位置
+	PartitionRevoked(topic string, partition int, next int64)
+}
+
+// partitionedSubscription 按分区消费的订阅
+type partitionedSubscription struct {
+	handlers map[string]MessageHandler
+	listener RebalanceListener
+}
+
+// SubscribePartitioned 以消费组成员的身份订阅多个 Topic，分区分配变化时回调 listener
+func (c *KafkaConsumer) SubscribePartitioned(handlers map[string]MessageHandler, listener RebalanceListener) error {
+	if len(handlers) == 0 {
+		return errors.New("no topics to subscribe")
+	}
+
+	c.partitioned = append(c.partitioned, &partitionedSubscription{handlers: handlers, listener: listener})
+	for topic := range handlers {
+		log.Printf("[Kafka Consumer] Subscribed to topic: %s (partition aware)\n", topic)
+	}
+	return nil
+}
+
+// consumePartitioned 加入消费组，每次重新分配后按分区启动消费协程，直到 ctx 取消或消费组关闭
+func (c *KafkaConsumer) consumePartitioned(ctx context.Context, sub *partitionedSubscription) {
+	topics := make([]string, 0, len(sub.handlers))
+	for topic := range sub.handlers {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	group, err := kafka.NewConsumerGroup(kafka.ConsumerGroupConfig{
+		ID:          c.groupID,
+		Brokers:     c.brokers,
+		Topics:      topics,
+		StartOffset: kafka.LastOffset,
+	})
+	if err != nil {
+		log.Printf("[Kafka Consumer] Failed to join consumer group %s: %v\n", c.groupID, err)
+		return
+	}
+	c.mu.Lock()
+	c.groups = append(c.groups, group)
+	c.mu.Unlock()
+
+	log.Printf("[Kafka Consumer] Started consuming topics %v in group %s\n", topics, c.groupID)
+
+	for {
+		gen, err := group.Next(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, kafka.ErrGroupClosed) {
+				return
+			}
+			log.Printf("[Kafka Consumer] Failed to join generation: %v\n", err)
+			continue
+		}
+
+		for topic, assignments := range gen.Assignments {
+			for _, assignment := range assignments {
+				topic, assignment := topic, assignment
+				gen.Start(func(ctx context.Context) {
+					c.consumePartition(ctx, gen, topic, assignment, sub)
+				})
+			}
+		}
+		log.Printf("[Kafka Consumer] Generation %d: assigned %d topic(s)\n", gen.ID, len(gen.Assignments))
+	}
+}
+
+// consumePartition 消费一个分区直到本代结束，结束时先回调 PartitionRevoked 再提交位置
+func (c *KafkaConsumer) consumePartition(ctx context.Context, gen *kafka.Generation, topic string, assignment kafka.PartitionAssignment, sub *partitionedSubscription) {
+	handler := sub.handlers[topic]
+
+	committed := int64(-1)
+	if assignment.Offset >= 0 {
+		committed = assignment.Offset
+	}
+	next := committed
+	if sub.listener != nil {
+		if resume := sub.listener.PartitionAssigned(topic, assignment.ID, committed); resume > next {
+			next = resume
+		}
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   c.brokers,
+		Topic:     topic,
+		Partition: assignment.ID,
+		MinBytes:  1e3,  // 1KB
+		MaxBytes:  10e6, // 10MB
+	})
+	defer reader.Close()
+
+	offset := next
+	if offset < 0 {
+		offset = kafka.LastOffset
+	}
+	if err := reader.SetOffset(offset); err != nil {
+		log.Printf("[Kafka Consumer] Failed to seek %s[%d] to %d: %v\n", topic, assignment.ID, offset, err)
+	}
+
+	c.trackPartition(topic, assignment.ID, true)
+	defer c.trackPartition(topic, assignment.ID, false)
+
+	commit := func() {
+		if next < 0 || next == committed {
+			return
+		}
+		if err := gen.CommitOffsets(map[string]map[int]int64{topic: {assignment.ID: next}}); err != nil {
+			log.Printf("[Kafka Consumer] Failed to commit %s[%d]: %v\n", topic, assignment.ID, err)
+			return
+		}
+		committed = next
+	}
+
+	lastCommit := time.Now()
+	for {
+		msg, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			log.Printf("[Kafka Consumer] Error fetching %s[%d]: %v\n", topic, assignment.ID, err)
+			continue
+		}
+
+		// 停止消费时未能投递的消息不计入消费位置，由下一个持有者重新消费
+		if err := c.process(ctx, topic, msg, handler); err != nil && ctx.Err() != nil {
+			break
+		}
+		next = msg.Offset + 1
+
+		if time.Since(lastCommit) >= commitInterval {
+			commit()
+			lastCommit = time.Now()
+		}
+	}
+
+	if sub.listener != nil {
+		sub.listener.PartitionRevoked(topic, assignment.ID, next)
+	}
+	commit()
+}
+
+// trackPartition 记录本实例当前持有的分区
+func (c *KafkaConsumer) trackPartition(topic string, partition int, assigned bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if assigned {
+		c.assigned[topic] = append(c.assigned[topic], partition)
+		return
+	}
+	partitions := c.assigned[topic]
+	for i, p := range partitions {
+		if p == partition {
+			c.assigned[topic] = append(partitions[:i], partitions[i+1:]...)
+			break
+		}
+	}
+	if len(c.assigned[topic]) == 0 {
+		delete(c.assigned, topic)
+	}
+}
+
+// AssignedPartitions 获取按分区消费时本实例当前持有的分区
+func (c *KafkaConsumer) AssignedPartitions() map[string][]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	assigned := make(map[string][]int, len(c.assigned))
+	for topic, partitions := range c.assigned {
+		assigned[topic] = append([]int(nil), partitions...)
+		sort.Ints(assigned[topic])
+	}
+	return assigned
+}
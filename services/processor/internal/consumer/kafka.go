@@ -6,12 +6,14 @@ import (
 	"log"
 	"market-system/common/models"
 	"market-system/common/utils"
+	"market-system/common/version"
+	"sync"
 
 	"github.com/segmentio/kafka-go"
 )
 
 // MessageHandler 消息处理器
-type MessageHandler func(data *models.MarketData) error
+type MessageHandler func(data *models.MarketMessage) error
 
 // KafkaConsumer Kafka 消费者
 type KafkaConsumer struct {
@@ -19,6 +21,28 @@ type KafkaConsumer struct {
 	handlers map[string]MessageHandler
 	brokers  []string
 	groupID  string
+	dlq      *kafka.Writer // 死信队列，为 nil 表示未启用
+
+	partitioned []*partitionedSubscription // 按分区消费的订阅
+	groups      []*kafka.ConsumerGroup
+
+	unknownToDLQ bool // 消息格式版本无法识别时转入死信队列，否则按当前格式尽力解析
+	poisonToDLQ  bool // 多次处理失败的消息转入死信队列，否则只记录日志
+	maxRetries   int  // 处理失败后的重试次数
+
+	mu       sync.Mutex
+	versions VersionStats
+	poison   PoisonStats
+	logged   map[string]bool  // 已记录日志的版本，每个版本只记录一次
+	assigned map[string][]int // 按分区消费时本实例持有的分区
+}
+
+// VersionStats 消息版本统计，滚动升级期间观察新旧版本混合的情况
+type VersionStats struct {
+	Mismatched    map[string]int64 // 生产者版本与本服务不一致的消息数，key 为 {producer}@{version}
+	Unversioned   int64            // 没有版本消息头的消息数（升级前的生产者）
+	UnknownSchema int64            // 消息格式版本无法识别的消息数
+	DeadLettered  int64            // 转入死信队列的消息数
 }
 
 // NewKafkaConsumer 创建 Kafka 消费者
@@ -28,6 +52,9 @@ func NewKafkaConsumer(brokers []string, groupID string) *KafkaConsumer {
 		handlers: make(map[string]MessageHandler),
 		brokers:  brokers,
 		groupID:  groupID,
+		versions: VersionStats{Mismatched: make(map[string]int64)},
+		logged:   make(map[string]bool),
+		assigned: make(map[string][]int),
 	}
 }
 
@@ -56,6 +83,9 @@ func (c *KafkaConsumer) Start(ctx context.Context) error {
 		handler := c.handlers[topic]
 		go c.consume(ctx, topic, reader, handler)
 	}
+	for _, sub := range c.partitioned {
+		go c.consumePartitioned(ctx, sub)
+	}
 	return nil
 }
 
@@ -78,17 +108,9 @@ func (c *KafkaConsumer) consume(ctx context.Context, topic string, reader *kafka
 				continue
 			}
 
-			// 解析消息
-			var data models.MarketData
-			if err := utils.FromJSONBytes(msg.Value, &data); err != nil {
-				log.Printf("[Kafka Consumer] Failed to parse message: %v\n", err)
-				reader.CommitMessages(ctx, msg)
-				continue
-			}
-
-			// 处理消息
-			if err := handler(&data); err != nil {
-				log.Printf("[Kafka Consumer] Failed to handle message: %v\n", err)
+			// 停止消费时未能投递的消息不提交，重启后重新消费
+			if err := c.process(ctx, topic, msg, handler); err != nil && ctx.Err() != nil {
+				return
 			}
 
 			// 提交消息
@@ -99,8 +121,102 @@ func (c *KafkaConsumer) consume(ctx context.Context, topic string, reader *kafka
 	}
 }
 
-// Close 关闭所有 Reader
+// process 检查版本、解析并处理单条消息，返回处理方的错误（已转入死信队列的消息不算错误）
+func (c *KafkaConsumer) process(ctx context.Context, topic string, msg kafka.Message, handler MessageHandler) error {
+	// 检查消息版本，无法识别的消息格式已转入死信队列时不再处理
+	if c.checkVersion(ctx, topic, msg) {
+		return nil
+	}
+
+	// 解析消息外层，Data 由处理方按数据类型解析；无法解析的消息不会因重试而成功，直接转入死信队列
+	var data models.MarketMessage
+	if err := utils.FromJSONBytes(msg.Value, &data); err != nil {
+		log.Printf("[Kafka Consumer] Failed to parse message: %v\n", err)
+		c.deadLetter(ctx, topic, msg, err, 1)
+		return nil
+	}
+	data.Partition = msg.Partition
+	data.Raw = msg.Value
+	data.Headers = make([]models.Header, len(msg.Headers))
+	for i, h := range msg.Headers {
+		data.Headers[i] = models.Header(h)
+	}
+
+	// 处理消息
+	err := handler(&data)
+	if err != nil {
+		log.Printf("[Kafka Consumer] Failed to handle message: %v\n", err)
+	}
+	return err
+}
+
+// checkVersion 统计消息版本，返回 true 表示消息已转入死信队列
+// 生产者版本与本服务不一致只记录统计；消息格式版本无法识别时，启用死信队列则转入，否则按当前格式尽力解析
+func (c *KafkaConsumer) checkVersion(ctx context.Context, topic string, msg kafka.Message) bool {
+	info := version.FromHeaders(msg.Headers)
+	unknown := info.SchemaVersion != "" && info.SchemaVersion != version.SchemaVersion
+
+	c.mu.Lock()
+	switch {
+	case info.SchemaVersion == "":
+		c.versions.Unversioned++
+	case info.ServiceVersion != version.Version:
+		key := info.Producer + "@" + info.ServiceVersion
+		if c.versions.Mismatched[key] == 0 {
+			log.Printf("[Kafka Consumer] Topic %s: producer %s version %s differs from %s\n",
+				topic, info.Producer, info.ServiceVersion, version.Version)
+		}
+		c.versions.Mismatched[key]++
+	}
+	if unknown {
+		c.versions.UnknownSchema++
+		if !c.logged[info.SchemaVersion] {
+			c.logged[info.SchemaVersion] = true
+			log.Printf("[Kafka Consumer] Topic %s: unknown schema version %s from %s@%s (supported: %s)\n",
+				topic, info.SchemaVersion, info.Producer, info.ServiceVersion, version.SchemaVersion)
+		}
+	}
+	c.mu.Unlock()
+
+	if !unknown || !c.unknownToDLQ {
+		return false
+	}
+
+	reason := fmt.Errorf("unknown schema version %s", info.SchemaVersion)
+	if err := c.writeDLQ(ctx, topic, msg, reason, 0); err != nil {
+		log.Printf("[Kafka Consumer] Failed to write to DLQ, processing as current schema: %v\n", err)
+		return false
+	}
+
+	c.mu.Lock()
+	c.versions.DeadLettered++
+	c.mu.Unlock()
+	return true
+}
+
+// VersionStats 获取消息版本统计
+func (c *KafkaConsumer) VersionStats() VersionStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.versions
+	stats.Mismatched = make(map[string]int64, len(c.versions.Mismatched))
+	for k, v := range c.versions.Mismatched {
+		stats.Mismatched[k] = v
+	}
+	return stats
+}
+
+// Close 关闭所有 Reader，消费组关闭时等待各分区回调 PartitionRevoked 完成
 func (c *KafkaConsumer) Close() error {
+	c.mu.Lock()
+	groups := c.groups
+	c.mu.Unlock()
+	for _, group := range groups {
+		if err := group.Close(); err != nil {
+			log.Printf("[Kafka Consumer] Failed to close consumer group: %v\n", err)
+		}
+	}
 	for topic, reader := range c.readers {
 		if err := reader.Close(); err != nil {
 			log.Printf("[Kafka Consumer] Failed to close reader for topic %s: %v\n", topic, err)
@@ -108,6 +224,11 @@ func (c *KafkaConsumer) Close() error {
 			log.Printf("[Kafka Consumer] Closed reader for topic: %s\n", topic)
 		}
 	}
+	if c.dlq != nil {
+		if err := c.dlq.Close(); err != nil {
+			log.Printf("[Kafka Consumer] Failed to close DLQ writer: %v\n", err)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,108 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"market-system/common/models"
+	"market-system/common/version"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestCheckVersion(t *testing.T) {
+	c := NewKafkaConsumer(nil, "test")
+
+	headers := func(producer, serviceVersion, schemaVersion string) []kafka.Header {
+		return []kafka.Header{
+			{Key: version.HeaderProducer, Value: []byte(producer)},
+			{Key: version.HeaderServiceVersion, Value: []byte(serviceVersion)},
+			{Key: version.HeaderSchemaVersion, Value: []byte(schemaVersion)},
+		}
+	}
+	messages := []kafka.Message{
+		{Headers: version.Headers("collector")},
+		{},
+		{Headers: headers("collector", "old", version.SchemaVersion)},
+		{Headers: headers("collector", "old", version.SchemaVersion)},
+		{Headers: headers("collector", "next", "99")},
+	}
+	for _, msg := range messages {
+		// 未启用死信队列时全部按当前格式处理
+		if c.checkVersion(context.Background(), "market.trade", msg) {
+			t.Fatalf("message %+v dead-lettered without DLQ", msg.Headers)
+		}
+	}
+
+	stats := c.VersionStats()
+	if stats.Unversioned != 1 || stats.UnknownSchema != 1 || stats.DeadLettered != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if stats.Mismatched["collector@old"] != 2 || stats.Mismatched["collector@next"] != 1 || len(stats.Mismatched) != 2 {
+		t.Errorf("unexpected mismatched versions: %v", stats.Mismatched)
+	}
+}
+
+func TestHandleRetries(t *testing.T) {
+	c := NewKafkaConsumer(nil, "test")
+	c.SetMaxRetries(2)
+
+	// 第二次处理成功
+	calls := 0
+	err := c.Handle("market.trade", &models.MarketMessage{Symbol: "BTCUSDT"}, func(*models.MarketMessage) error {
+		calls++
+		if calls == 1 {
+			return errors.New("temporary")
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Fatalf("err = %v, calls = %d", err, calls)
+	}
+
+	// panic 视为处理失败，重试后仍失败的消息未启用死信队列时丢弃
+	calls = 0
+	err = c.Handle("market.trade", &models.MarketMessage{Symbol: "BTCUSDT"}, func(*models.MarketMessage) error {
+		calls++
+		panic("bad message")
+	})
+	if err == nil || calls != 3 {
+		t.Fatalf("err = %v, calls = %d", err, calls)
+	}
+
+	if stats := c.PoisonStats(); stats.Retries != 3 || stats.Discarded != 1 || stats.DeadLettered != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestDeadLetterKeepsOriginalMessage(t *testing.T) {
+	c := NewKafkaConsumer(nil, "test")
+
+	raw := []byte(`{"exchange":"binance","symbol":"BTCUSDT","type":"trade","data":{"price":"1.0"}}`)
+	msg := kafka.Message{Partition: 3, Value: raw, Headers: version.Headers("collector")}
+
+	var got *models.MarketMessage
+	if err := c.process(context.Background(), "market.trade", msg, func(data *models.MarketMessage) error {
+		got = data
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 转入死信队列的消息为消费到的原文和消息头，而不是按当前格式重新编码
+	dead := originalMessage(got)
+	if string(dead.Value) != string(raw) || string(dead.Key) != "BTCUSDT" {
+		t.Errorf("value = %s, key = %s", dead.Value, dead.Key)
+	}
+	if len(dead.Headers) != len(msg.Headers) {
+		t.Fatalf("headers = %v, want %v", dead.Headers, msg.Headers)
+	}
+	for i, h := range msg.Headers {
+		if dead.Headers[i].Key != h.Key || string(dead.Headers[i].Value) != string(h.Value) {
+			t.Errorf("header %d = %s:%s, want %s:%s", i, dead.Headers[i].Key, dead.Headers[i].Value, h.Key, h.Value)
+		}
+	}
+	if info := version.FromHeaders(dead.Headers); info.Producer != "collector" || info.SchemaVersion != version.SchemaVersion {
+		t.Errorf("version headers lost: %+v", info)
+	}
+}
@@ -0,0 +1,69 @@
+package dailytotals
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"market-system/common/models"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HTTPEngine 从撮合引擎接口拉取单日成交汇总
+// 请求 GET {url}?date=YYYY-MM-DD，响应为 EngineDailyTotals JSON
+type HTTPEngine struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPEngine 创建撮合引擎汇总接口客户端
+func NewHTTPEngine(engineURL string, timeout time.Duration) *HTTPEngine {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &HTTPEngine{
+		url:    engineURL,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// DailyTotals 获取指定日期的成交汇总，key 为交易对
+func (e *HTTPEngine) DailyTotals(date string) (map[string]*models.DailyTradeTotals, error) {
+	u, err := url.Parse(e.url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid engine url: %w", err)
+	}
+	query := u.Query()
+	query.Set("date", date)
+	u.RawQuery = query.Encode()
+
+	resp, err := e.client.Get(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result models.EngineDailyTotals
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if result.Date != "" && result.Date != date {
+		return nil, fmt.Errorf("engine returned totals of %s, expected %s", result.Date, date)
+	}
+
+	totals := make(map[string]*models.DailyTradeTotals, len(result.Symbols))
+	for i := range result.Symbols {
+		t := result.Symbols[i]
+		totals[t.Symbol] = &t
+	}
+	return totals, nil
+}
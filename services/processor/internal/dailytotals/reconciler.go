@@ -0,0 +1,363 @@
+package dailytotals
+
+import (
+	"fmt"
+	"log"
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store 成交汇总和对账报告的读写接口
+type Store interface {
+	AddMarketTotals(date string, totals []*models.DailyTradeTotals) error
+	MarketTotals(date string) (map[string]*models.DailyTradeTotals, error)
+	EngineTotals(date string) (map[string]*models.DailyTradeTotals, error)
+	SaveTradeReconcileReport(report *models.TradeReconcileReport) error
+	TradeReconciled(date string) (bool, error)
+}
+
+// Engine 撮合引擎单日成交汇总查询接口
+type Engine interface {
+	DailyTotals(date string) (map[string]*models.DailyTradeTotals, error)
+}
+
+// 引擎汇总的来源
+const (
+	SourceAPI  = "api"  // 从撮合引擎接口拉取
+	SourcePush = "push" // 撮合引擎经内部适配器推送
+)
+
+// flushInterval 累计的成交汇总写入 Redis 的间隔
+const flushInterval = 10 * time.Second
+
+// checkInterval 检查是否需要对账的间隔，引擎汇总不可用时按此间隔重试
+const checkInterval = 5 * time.Minute
+
+// dateLayout 汇总日期格式（UTC）
+const dateLayout = "2006-01-02"
+
+// Reconciler 撮合引擎与行情系统的每日成交对账
+// 行情系统按成交时间的 UTC 日累计内部成交的笔数、成交量和成交额，定期以增量写入 Redis（多个实例共同累加）。
+// 每日零点后等待迟到的成交，再将前一日的汇总与撮合引擎的汇总逐个交易对比较，
+// 笔数差值或成交量、成交额的相对偏差超过容差，以及只有一方有成交的交易对记入报告并输出告警。
+type Reconciler struct {
+	cfg    config.TradeReconcileConfig
+	store  Store
+	engine Engine // 为 nil 表示只使用引擎推送的汇总
+
+	mu      sync.Mutex
+	pending map[string]map[string]*models.DailyTradeTotals // 尚未写入的增量，日期 -> 交易对 -> 汇总
+	done    string                                         // 最近一次完成对账的日期
+	stats   Stats
+}
+
+// Stats 成交对账统计（累计）
+type Stats struct {
+	Trades     int64 // 累计的内部成交数
+	Runs       int64 // 完成的对账次数
+	Mismatched int64 // 偏差超过容差的交易对数
+	Errors     int64 // 写入汇总或对账失败次数
+}
+
+// NewReconciler 创建每日成交对账
+func NewReconciler(cfg config.TradeReconcileConfig, store Store, engine Engine) *Reconciler {
+	if cfg.RunDelayMin <= 0 {
+		cfg.RunDelayMin = 30
+	}
+	if cfg.VolumeTolerance <= 0 {
+		cfg.VolumeTolerance = 0.01
+	}
+	return &Reconciler{
+		cfg:     cfg,
+		store:   store,
+		engine:  engine,
+		pending: make(map[string]map[string]*models.DailyTradeTotals),
+	}
+}
+
+// RecordTrade 累计内部成交
+func (r *Reconciler) RecordTrade(trade *models.Trade) {
+	if trade.Source != constants.SourceInternal {
+		return
+	}
+
+	date := formatDate(trade.Timestamp)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	symbols, ok := r.pending[date]
+	if !ok {
+		symbols = make(map[string]*models.DailyTradeTotals)
+		r.pending[date] = symbols
+	}
+	t, ok := symbols[trade.Symbol]
+	if !ok {
+		t = &models.DailyTradeTotals{Symbol: trade.Symbol}
+		symbols[trade.Symbol] = t
+	}
+	t.TradeCount++
+	t.Volume += trade.Amount
+	t.QuoteVolume += trade.Price * trade.Amount
+	r.stats.Trades++
+}
+
+// Run 定期写入累计的汇总并在每日对账时间对账，直到 stop 关闭
+func (r *Reconciler) Run(stop <-chan struct{}) {
+	flush := time.NewTicker(flushInterval)
+	defer flush.Stop()
+	check := time.NewTicker(checkInterval)
+	defer check.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-flush.C:
+			r.Flush()
+		case <-check.C:
+			r.Check(utils.GetCurrentTimestamp())
+		}
+	}
+}
+
+// Flush 将累计的增量写入 Redis，写入失败的增量保留到下一次
+func (r *Reconciler) Flush() {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = make(map[string]map[string]*models.DailyTradeTotals)
+	r.mu.Unlock()
+
+	for date, symbols := range pending {
+		totals := make([]*models.DailyTradeTotals, 0, len(symbols))
+		for _, t := range symbols {
+			totals = append(totals, t)
+		}
+		if err := r.store.AddMarketTotals(date, totals); err != nil {
+			log.Printf("[DailyTotals] Failed to flush %s: %v\n", date, err)
+			r.restore(date, symbols)
+		}
+	}
+}
+
+// restore 将写入失败的增量合并回待写入的增量
+func (r *Reconciler) restore(date string, symbols map[string]*models.DailyTradeTotals) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stats.Errors++
+	current, ok := r.pending[date]
+	if !ok {
+		r.pending[date] = symbols
+		return
+	}
+	for symbol, t := range symbols {
+		if c, ok := current[symbol]; ok {
+			c.TradeCount += t.TradeCount
+			c.Volume += t.Volume
+			c.QuoteVolume += t.QuoteVolume
+		} else {
+			current[symbol] = t
+		}
+	}
+}
+
+// Stop 写入累计的汇总（停机时在处理队列停止后调用）
+func (r *Reconciler) Stop() {
+	r.Flush()
+}
+
+// Check 对账时间已到且前一日尚未对账时执行对账，返回报告；不需要对账时返回 nil
+func (r *Reconciler) Check(now int64) *models.TradeReconcileReport {
+	// 零点后 RunDelayMin 分钟起对账前一日，此前仍为前两日（已完成时跳过）
+	date := formatDate(now - int64(r.cfg.RunDelayMin)*constants.Minute - constants.Day)
+	if r.done == date {
+		return nil
+	}
+
+	// 其他实例或重启前已完成对账
+	reconciled, err := r.store.TradeReconciled(date)
+	if err != nil {
+		log.Printf("[DailyTotals] Failed to check report of %s: %v\n", date, err)
+		return nil
+	}
+	if reconciled {
+		r.done = date
+		return nil
+	}
+
+	// 先写入本实例累计的汇总
+	r.Flush()
+
+	report := r.Reconcile(date, now)
+	if err := r.store.SaveTradeReconcileReport(report); err != nil {
+		log.Printf("[DailyTotals] Failed to save report of %s: %v\n", date, err)
+	}
+	if report.Source != "" {
+		r.done = date
+	}
+	return report
+}
+
+// Reconcile 对账指定日期，取不到引擎汇总时报告的 Source 为空
+func (r *Reconciler) Reconcile(date string, now int64) *models.TradeReconcileReport {
+	report := &models.TradeReconcileReport{
+		Date:          date,
+		Discrepancies: []models.TradeTotalsDiscrepancy{},
+		Timestamp:     now,
+	}
+
+	engine, source, err := r.engineTotals(date)
+	if err != nil {
+		log.Printf("[DailyTotals] Engine totals of %s unavailable: %v\n", date, err)
+		report.Errors = append(report.Errors, err.Error())
+		r.addError()
+		return report
+	}
+	report.Source = source
+
+	market, err := r.store.MarketTotals(date)
+	if err != nil {
+		log.Printf("[DailyTotals] Failed to get market totals of %s: %v\n", date, err)
+		report.Source = ""
+		report.Errors = append(report.Errors, err.Error())
+		r.addError()
+		return report
+	}
+
+	symbols := make(map[string]bool, len(engine)+len(market))
+	for symbol := range engine {
+		symbols[symbol] = true
+	}
+	for symbol := range market {
+		symbols[symbol] = true
+	}
+	names := make([]string, 0, len(symbols))
+	for symbol := range symbols {
+		names = append(names, symbol)
+	}
+	sort.Strings(names)
+
+	for _, symbol := range names {
+		report.Symbols++
+		d, ok := r.compare(symbol, engine[symbol], market[symbol])
+		if !ok {
+			report.Matched++
+			continue
+		}
+		report.Mismatched++
+		report.Discrepancies = append(report.Discrepancies, d)
+	}
+
+	r.mu.Lock()
+	r.stats.Runs++
+	r.stats.Mismatched += int64(report.Mismatched)
+	r.mu.Unlock()
+
+	if report.Mismatched > 0 {
+		log.Printf("[ALERT] Trade totals of %s diverge from matching engine: %d of %d symbols mismatched\n",
+			date, report.Mismatched, report.Symbols)
+		for _, d := range report.Discrepancies {
+			log.Printf("[DailyTotals] %s %s %s: engine count %d volume %g quote %g, market count %d volume %g quote %g\n",
+				date, d.Symbol, d.Field, d.EngineCount, d.EngineVolume, d.EngineQuoteVolume,
+				d.MarketCount, d.MarketVolume, d.MarketQuoteVolume)
+		}
+	} else {
+		log.Printf("[DailyTotals] Trade totals of %s match matching engine (%d symbols)\n", date, report.Symbols)
+	}
+	return report
+}
+
+// Stats 获取累计统计
+func (r *Reconciler) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
+
+// addError 记录对账失败
+func (r *Reconciler) addError() {
+	r.mu.Lock()
+	r.stats.Errors++
+	r.mu.Unlock()
+}
+
+// engineTotals 获取引擎汇总，优先从引擎接口拉取，拉取失败或未配置时使用引擎推送的汇总
+func (r *Reconciler) engineTotals(date string) (map[string]*models.DailyTradeTotals, string, error) {
+	var apiErr error
+	if r.engine != nil {
+		totals, err := r.engine.DailyTotals(date)
+		if err == nil {
+			return totals, SourceAPI, nil
+		}
+		apiErr = err
+	}
+
+	totals, err := r.store.EngineTotals(date)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(totals) == 0 {
+		if apiErr != nil {
+			return nil, "", apiErr
+		}
+		return nil, "", fmt.Errorf("no engine totals for %s", date)
+	}
+	return totals, SourcePush, nil
+}
+
+// compare 比较单个交易对的引擎汇总与行情系统汇总，在容差内时返回 false
+func (r *Reconciler) compare(symbol string, engine, market *models.DailyTradeTotals) (models.TradeTotalsDiscrepancy, bool) {
+	d := models.TradeTotalsDiscrepancy{Symbol: symbol}
+	if engine != nil {
+		d.EngineCount, d.EngineVolume, d.EngineQuoteVolume = engine.TradeCount, engine.Volume, engine.QuoteVolume
+	}
+	if market != nil {
+		d.MarketCount, d.MarketVolume, d.MarketQuoteVolume = market.TradeCount, market.Volume, market.QuoteVolume
+	}
+
+	switch {
+	case engine == nil || engine.TradeCount == 0:
+		if d.MarketCount == 0 {
+			return d, false
+		}
+		d.Field = "engine_missing"
+	case market == nil || market.TradeCount == 0:
+		d.Field = "market_missing"
+	case abs(d.EngineCount-d.MarketCount) > r.cfg.CountTolerance:
+		d.Field = "trade_count"
+	case relativeDiff(d.EngineVolume, d.MarketVolume)*100 > r.cfg.VolumeTolerance:
+		d.Field = "volume"
+	case relativeDiff(d.EngineQuoteVolume, d.MarketQuoteVolume)*100 > r.cfg.VolumeTolerance:
+		d.Field = "quote_volume"
+	default:
+		return d, false
+	}
+	return d, true
+}
+
+// formatDate 时间戳（毫秒）所在的 UTC 日期
+func formatDate(ts int64) string {
+	return time.UnixMilli(ts).UTC().Format(dateLayout)
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// relativeDiff 相对偏差
+func relativeDiff(a, b float64) float64 {
+	if a == b {
+		return 0
+	}
+	return math.Abs(a-b) / math.Max(math.Abs(a), math.Abs(b))
+}
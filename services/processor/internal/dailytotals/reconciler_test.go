@@ -0,0 +1,150 @@
+package dailytotals
+
+import (
+	"errors"
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"testing"
+	"time"
+)
+
+// fakeStore 内存中的汇总和报告
+type fakeStore struct {
+	market  map[string]map[string]*models.DailyTradeTotals
+	engine  map[string]map[string]*models.DailyTradeTotals
+	reports map[string]*models.TradeReconcileReport
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		market:  make(map[string]map[string]*models.DailyTradeTotals),
+		engine:  make(map[string]map[string]*models.DailyTradeTotals),
+		reports: make(map[string]*models.TradeReconcileReport),
+	}
+}
+
+func (s *fakeStore) AddMarketTotals(date string, totals []*models.DailyTradeTotals) error {
+	if s.market[date] == nil {
+		s.market[date] = make(map[string]*models.DailyTradeTotals)
+	}
+	for _, t := range totals {
+		c, ok := s.market[date][t.Symbol]
+		if !ok {
+			c = &models.DailyTradeTotals{Symbol: t.Symbol}
+			s.market[date][t.Symbol] = c
+		}
+		c.TradeCount += t.TradeCount
+		c.Volume += t.Volume
+		c.QuoteVolume += t.QuoteVolume
+	}
+	return nil
+}
+
+func (s *fakeStore) MarketTotals(date string) (map[string]*models.DailyTradeTotals, error) {
+	return s.market[date], nil
+}
+
+func (s *fakeStore) EngineTotals(date string) (map[string]*models.DailyTradeTotals, error) {
+	return s.engine[date], nil
+}
+
+func (s *fakeStore) SaveTradeReconcileReport(report *models.TradeReconcileReport) error {
+	s.reports[report.Date] = report
+	return nil
+}
+
+func (s *fakeStore) TradeReconciled(date string) (bool, error) {
+	report, ok := s.reports[date]
+	return ok && report.Source != "", nil
+}
+
+type failingEngine struct{}
+
+func (failingEngine) DailyTotals(date string) (map[string]*models.DailyTradeTotals, error) {
+	return nil, errors.New("engine unavailable")
+}
+
+func trade(symbol, source string, price, amount float64, ts int64) *models.Trade {
+	return &models.Trade{Symbol: symbol, Source: source, Price: price, Amount: amount, Timestamp: ts}
+}
+
+func TestReconcile(t *testing.T) {
+	day := time.Date(2025, 11, 11, 0, 0, 0, 0, time.UTC).UnixMilli()
+	store := newFakeStore()
+	r := NewReconciler(config.TradeReconcileConfig{}, store, nil)
+
+	r.RecordTrade(trade("BTCUSDT", constants.SourceInternal, 100, 1, day+1000))
+	r.RecordTrade(trade("BTCUSDT", constants.SourceInternal, 100, 2, day+2000))
+	r.RecordTrade(trade("BTCUSDT", constants.SourceExternal, 100, 5, day+3000)) // 外部成交不计入
+	r.RecordTrade(trade("ETHUSDT", constants.SourceInternal, 10, 1, day+4000))
+	r.RecordTrade(trade("SOLUSDT", constants.SourceInternal, 5, 1, day+5000))
+	r.RecordTrade(trade("BTCUSDT", constants.SourceInternal, 100, 1, day+constants.Day)) // 次日
+
+	// 对账时间之前不对账当日
+	if report := r.Check(day + constants.Day + 10*constants.Minute); report != nil && report.Date == "2025-11-11" {
+		t.Fatalf("reconciled before run delay: %+v", report)
+	}
+
+	// 引擎汇总尚未推送时报告错误，之后重试
+	now := day + constants.Day + 31*constants.Minute
+	report := r.Check(now)
+	if report == nil || report.Source != "" || len(report.Errors) == 0 {
+		t.Fatalf("expected error report, got %+v", report)
+	}
+
+	store.engine["2025-11-11"] = map[string]*models.DailyTradeTotals{
+		"BTCUSDT": {Symbol: "BTCUSDT", TradeCount: 2, Volume: 3, QuoteVolume: 300},
+		"ETHUSDT": {Symbol: "ETHUSDT", TradeCount: 2, Volume: 2, QuoteVolume: 20},
+		"XRPUSDT": {Symbol: "XRPUSDT", TradeCount: 1, Volume: 1, QuoteVolume: 1},
+	}
+	report = r.Check(now + checkInterval.Milliseconds())
+	if report == nil || report.Source != SourcePush {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if report.Symbols != 4 || report.Matched != 1 || report.Mismatched != 3 {
+		t.Fatalf("symbols %d, matched %d, mismatched %d", report.Symbols, report.Matched, report.Mismatched)
+	}
+	want := map[string]string{"ETHUSDT": "trade_count", "SOLUSDT": "engine_missing", "XRPUSDT": "market_missing"}
+	for _, d := range report.Discrepancies {
+		if want[d.Symbol] != d.Field {
+			t.Errorf("%s: field %s, want %s", d.Symbol, d.Field, want[d.Symbol])
+		}
+	}
+
+	// 已完成的日期不再对账
+	if report := r.Check(now + 2*checkInterval.Milliseconds()); report != nil {
+		t.Errorf("reconciled twice: %+v", report)
+	}
+
+	// 次日的成交已累计到次日
+	if got := store.market["2025-11-12"]["BTCUSDT"]; got == nil || got.TradeCount != 1 {
+		t.Errorf("next day totals = %+v", got)
+	}
+}
+
+func TestReconcileVolumeTolerance(t *testing.T) {
+	r := NewReconciler(config.TradeReconcileConfig{VolumeTolerance: 1}, newFakeStore(), nil)
+
+	engine := &models.DailyTradeTotals{TradeCount: 10, Volume: 100, QuoteVolume: 1000}
+	if _, ok := r.compare("BTCUSDT", engine, &models.DailyTradeTotals{TradeCount: 10, Volume: 100.5, QuoteVolume: 1000}); ok {
+		t.Error("volume within tolerance reported")
+	}
+	d, ok := r.compare("BTCUSDT", engine, &models.DailyTradeTotals{TradeCount: 10, Volume: 100, QuoteVolume: 1020})
+	if !ok || d.Field != "quote_volume" {
+		t.Errorf("quote volume discrepancy = %+v, %v", d, ok)
+	}
+}
+
+func TestEngineFallbackToPush(t *testing.T) {
+	store := newFakeStore()
+	store.engine["2025-11-11"] = map[string]*models.DailyTradeTotals{"BTCUSDT": {Symbol: "BTCUSDT", TradeCount: 1}}
+	r := NewReconciler(config.TradeReconcileConfig{}, store, failingEngine{})
+
+	if _, source, err := r.engineTotals("2025-11-11"); err != nil || source != SourcePush {
+		t.Errorf("source %s, err %v", source, err)
+	}
+	if _, _, err := r.engineTotals("2025-11-12"); err == nil || err.Error() != "engine unavailable" {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -2,8 +2,12 @@ package handler
 
 import (
 	"log"
+	"market-system/common/config"
 	"market-system/common/models"
+	"market-system/common/utils"
+	"math"
 	"sort"
+	"strconv"
 	"sync"
 )
 
@@ -12,6 +16,35 @@ type DepthHandler struct {
 	managers map[string]*DepthManager // key: symbol
 	storage  StorageInterface
 	mu       sync.RWMutex
+
+	// 按价格精度聚合深度，aggregated 为 nil 时不聚合
+	aggregated       AggregatedDepthStore
+	precisions       []precision            // 默认精度
+	symbolPrecisions map[string][]precision // 按交易对覆盖的精度
+
+	bookTickers BookTickerStore   // 为 nil 表示不发布最优买卖价
+	bookStats   BookStatsRecorder // 为 nil 表示不计算订单簿统计
+}
+
+// BookTickerStore 最优买卖价写入接口
+type BookTickerStore interface {
+	SaveBookTicker(ticker *models.BookTicker) error
+}
+
+// BookStatsRecorder 订单簿统计接口，接收排序后的完整深度
+type BookStatsRecorder interface {
+	RecordDepth(symbol string, bids, asks []models.PriceLevel, timestamp int64)
+}
+
+// AggregatedDepthStore 按价格精度聚合的深度写入接口
+type AggregatedDepthStore interface {
+	SaveAggregatedDepth(depth *models.OrderBook, precision string) error
+}
+
+// precision 深度聚合精度
+type precision struct {
+	name string  // 配置中的精度，作为存储键的后缀
+	step float64 // 价格步长
 }
 
 // NewDepthHandler 创建深度处理器
@@ -32,7 +65,143 @@ func (h *DepthHandler) HandleDepth(depth *models.OrderBook) error {
 	}
 	h.mu.Unlock()
 
-	return manager.UpdateDepth(depth)
+	if err := manager.UpdateDepth(depth); err != nil {
+		return err
+	}
+
+	h.aggregate(manager, depth.Timestamp)
+	h.publishBookTicker(manager, depth.Timestamp)
+	if h.bookStats != nil {
+		bids, asks := manager.levels()
+		h.bookStats.RecordDepth(manager.symbol, bids, asks, depth.Timestamp)
+	}
+	return nil
+}
+
+// SetBookTicker 设置最优买卖价的写入（需在处理数据前调用）
+func (h *DepthHandler) SetBookTicker(store BookTickerStore) {
+	h.bookTickers = store
+}
+
+// SetBookStats 设置订单簿统计（需在处理数据前调用）
+func (h *DepthHandler) SetBookStats(recorder BookStatsRecorder) {
+	h.bookStats = recorder
+}
+
+// publishBookTicker 盘口第一档的价格或数量变化时写入最优买卖价
+func (h *DepthHandler) publishBookTicker(manager *DepthManager, timestamp int64) {
+	if h.bookTickers == nil {
+		return
+	}
+
+	ticker, changed := manager.updateTop(timestamp)
+	if !changed {
+		return
+	}
+	if err := h.bookTickers.SaveBookTicker(ticker); err != nil {
+		log.Printf("[Depth] Failed to save book ticker for %s: %v\n", manager.symbol, err)
+	}
+}
+
+// SetAggregation 设置按价格精度聚合深度（需在处理数据前调用），未启用时不聚合
+func (h *DepthHandler) SetAggregation(cfg config.DepthAggregationConfig, store AggregatedDepthStore) {
+	if !cfg.Enable {
+		return
+	}
+
+	h.aggregated = store
+	h.precisions = parsePrecisions(cfg.Precisions)
+	h.symbolPrecisions = make(map[string][]precision, len(cfg.Symbols))
+	for symbol, precisions := range cfg.Symbols {
+		h.symbolPrecisions[symbol] = parsePrecisions(precisions)
+	}
+}
+
+// parsePrecisions 校验配置的精度，忽略无法解析或不为正数的精度
+func parsePrecisions(names []string) []precision {
+	precisions := make([]precision, 0, len(names))
+	for _, name := range names {
+		step, err := strconv.ParseFloat(name, 64)
+		if err != nil || step <= 0 || math.IsInf(step, 0) {
+			log.Printf("[Depth] Ignored invalid aggregation precision: %s\n", name)
+			continue
+		}
+		precisions = append(precisions, precision{name: name, step: step})
+	}
+	return precisions
+}
+
+// aggregate 按交易对的各个精度聚合当前深度并写入存储
+func (h *DepthHandler) aggregate(manager *DepthManager, timestamp int64) {
+	if h.aggregated == nil {
+		return
+	}
+
+	precisions, ok := h.symbolPrecisions[manager.symbol]
+	if !ok {
+		precisions = h.precisions
+	}
+	if len(precisions) == 0 {
+		return
+	}
+
+	bids, asks := manager.levels()
+	for _, p := range precisions {
+		book := &models.OrderBook{
+			Symbol:    manager.symbol,
+			Bids:      aggregateLevels(bids, p.step, true),
+			Asks:      aggregateLevels(asks, p.step, false),
+			Timestamp: timestamp,
+		}
+		if err := h.aggregated.SaveAggregatedDepth(book, p.name); err != nil {
+			log.Printf("[Depth] Failed to save %s depth for %s: %v\n", p.name, manager.symbol, err)
+		}
+	}
+}
+
+// aggregateLevels 将已排序的档位按步长合并
+// 买盘向下对齐、卖盘向上对齐，避免聚合后买卖盘交叉；对齐不改变档位顺序，只需合并相邻档位
+func aggregateLevels(levels []models.PriceLevel, step float64, isBid bool) []models.PriceLevel {
+	result := make([]models.PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		price := alignPrice(level.Price, step, isBid)
+		if n := len(result); n > 0 && result[n-1].Price == price {
+			result[n-1].Amount += level.Amount
+			continue
+		}
+		result = append(result, models.PriceLevel{Price: price, Amount: level.Amount})
+	}
+	return result
+}
+
+// alignPrice 将价格对齐到步长
+func alignPrice(price, step float64, isBid bool) float64 {
+	// 先按步长数量取整，再消除浮点误差，避免 0.1+0.2 之类的价格落入不同档位
+	steps := utils.RoundFloat(price/step, 8)
+	if isBid {
+		steps = math.Floor(steps)
+	} else {
+		steps = math.Ceil(steps)
+	}
+	return utils.RoundFloat(steps*step, 10)
+}
+
+// BestPrices 获取交易对当前的最优买卖价，没有深度数据时为 0
+func (h *DepthHandler) BestPrices(symbol string) (bid, ask float64) {
+	h.mu.RLock()
+	manager, ok := h.managers[symbol]
+	h.mu.RUnlock()
+	if !ok {
+		return 0, 0
+	}
+
+	if level := manager.GetBestBid(); level != nil {
+		bid = level.Price
+	}
+	if level := manager.GetBestAsk(); level != nil {
+		ask = level.Price
+	}
+	return bid, ask
 }
 
 // DepthManager 深度管理器
@@ -42,6 +211,8 @@ type DepthManager struct {
 	asks    []models.PriceLevel // 卖盘，价格从低到高
 	storage StorageInterface
 	mu      sync.RWMutex
+
+	top models.BookTicker // 上次发布的最优买卖价
 }
 
 // NewDepthManager 创建深度管理器
@@ -82,6 +253,36 @@ func (m *DepthManager) UpdateDepth(depth *models.OrderBook) error {
 	return nil
 }
 
+// levels 获取当前的买卖盘（更新深度时整体替换，返回的切片不会再被修改）
+func (m *DepthManager) levels() (bids, asks []models.PriceLevel) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.bids, m.asks
+}
+
+// updateTop 比较当前盘口第一档与上次发布的最优买卖价，变化时记录并返回新的最优买卖价
+// 某一侧没有挂单时价格和数量为 0
+func (m *DepthManager) updateTop(timestamp int64) (*models.BookTicker, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	top := models.BookTicker{Symbol: m.symbol, Timestamp: timestamp}
+	if len(m.bids) > 0 {
+		top.BidPrice, top.BidQty = m.bids[0].Price, m.bids[0].Amount
+	}
+	if len(m.asks) > 0 {
+		top.AskPrice, top.AskQty = m.asks[0].Price, m.asks[0].Amount
+	}
+
+	last := m.top
+	last.Timestamp = timestamp
+	if last == top {
+		return nil, false
+	}
+	m.top = top
+	return &top, true
+}
+
 // sortDepth 排序深度
 func (m *DepthManager) sortDepth() {
 	// 买盘按价格从高到低排序
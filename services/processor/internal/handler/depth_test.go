@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"market-system/common/config"
+	"market-system/common/models"
+	"reflect"
+	"testing"
+)
+
+// memoryAggregatedStore 记录按精度保存的聚合深度
+type memoryAggregatedStore map[string]*models.OrderBook
+
+func (s memoryAggregatedStore) SaveAggregatedDepth(depth *models.OrderBook, precision string) error {
+	s[precision] = depth
+	return nil
+}
+
+func TestDepthAggregation(t *testing.T) {
+	store := memoryAggregatedStore{}
+	h := NewDepthHandler(&memoryStorage{})
+	h.SetAggregation(config.DepthAggregationConfig{
+		Enable:     true,
+		Precisions: []string{"0.1", "1", "bad"},
+		Symbols:    map[string][]string{"ETHUSDT": {"10"}},
+	}, store)
+
+	err := h.HandleDepth(&models.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids: []models.PriceLevel{
+			{Price: 99.95, Amount: 1},
+			{Price: 100.3, Amount: 2},
+			{Price: 100.25, Amount: 1},
+		},
+		Asks: []models.PriceLevel{
+			{Price: 100.35, Amount: 1},
+			{Price: 100.4, Amount: 3},
+			{Price: 101.2, Amount: 2},
+		},
+		Timestamp: 1700000000000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(store) != 2 {
+		t.Fatalf("saved precisions %v, want 0.1 and 1", store)
+	}
+
+	// 买盘向下对齐、卖盘向上对齐
+	tests := []struct {
+		precision string
+		bids      []models.PriceLevel
+		asks      []models.PriceLevel
+	}{
+		{"0.1", []models.PriceLevel{{Price: 100.3, Amount: 2}, {Price: 100.2, Amount: 1}, {Price: 99.9, Amount: 1}},
+			[]models.PriceLevel{{Price: 100.4, Amount: 4}, {Price: 101.2, Amount: 2}}},
+		{"1", []models.PriceLevel{{Price: 100, Amount: 3}, {Price: 99, Amount: 1}},
+			[]models.PriceLevel{{Price: 101, Amount: 4}, {Price: 102, Amount: 2}}},
+	}
+	for _, tc := range tests {
+		book := store[tc.precision]
+		if book == nil || book.Symbol != "BTCUSDT" || book.Timestamp != 1700000000000 {
+			t.Fatalf("%s: unexpected book %+v", tc.precision, book)
+		}
+		if !reflect.DeepEqual(book.Bids, tc.bids) || !reflect.DeepEqual(book.Asks, tc.asks) {
+			t.Errorf("%s: bids %v asks %v, want %v %v", tc.precision, book.Bids, book.Asks, tc.bids, tc.asks)
+		}
+	}
+
+	// 按交易对覆盖的精度
+	h.HandleDepth(&models.OrderBook{Symbol: "ETHUSDT", Bids: []models.PriceLevel{{Price: 2015, Amount: 1}}})
+	if book := store["10"]; book == nil || !reflect.DeepEqual(book.Bids, []models.PriceLevel{{Price: 2010, Amount: 1}}) {
+		t.Errorf("unexpected ETHUSDT book: %+v", book)
+	}
+}
+
+// memoryBookTickerStore 记录保存的最优买卖价
+type memoryBookTickerStore []models.BookTicker
+
+func (s *memoryBookTickerStore) SaveBookTicker(ticker *models.BookTicker) error {
+	*s = append(*s, *ticker)
+	return nil
+}
+
+func TestDepthBookTicker(t *testing.T) {
+	var store memoryBookTickerStore
+	h := NewDepthHandler(&memoryStorage{})
+	h.SetBookTicker(&store)
+
+	book := func(ts int64, bidQty float64, deeper float64) *models.OrderBook {
+		return &models.OrderBook{
+			Symbol:    "BTCUSDT",
+			Bids:      []models.PriceLevel{{Price: 99, Amount: deeper}, {Price: 100, Amount: bidQty}},
+			Asks:      []models.PriceLevel{{Price: 101, Amount: 2}},
+			Timestamp: ts,
+		}
+	}
+
+	h.HandleDepth(book(1, 1, 5))
+	// 只有非第一档变化，不推送
+	h.HandleDepth(book(2, 1, 6))
+	// 第一档数量变化
+	h.HandleDepth(book(3, 1.5, 6))
+	// 卖盘清空
+	h.HandleDepth(&models.OrderBook{Symbol: "BTCUSDT", Bids: []models.PriceLevel{{Price: 100, Amount: 1.5}}, Timestamp: 4})
+
+	want := memoryBookTickerStore{
+		{Symbol: "BTCUSDT", BidPrice: 100, BidQty: 1, AskPrice: 101, AskQty: 2, Timestamp: 1},
+		{Symbol: "BTCUSDT", BidPrice: 100, BidQty: 1.5, AskPrice: 101, AskQty: 2, Timestamp: 3},
+		{Symbol: "BTCUSDT", BidPrice: 100, BidQty: 1.5, Timestamp: 4},
+	}
+	if !reflect.DeepEqual(store, want) {
+		t.Errorf("book tickers = %+v, want %+v", store, want)
+	}
+}
@@ -2,18 +2,28 @@ package handler
 
 import (
 	"log"
+	"market-system/common/config"
 	"market-system/common/constants"
 	"market-system/common/models"
 	"market-system/common/utils"
+	"math"
 	"sync"
+	"time"
 )
 
 // KlineHandler K线处理器
 type KlineHandler struct {
 	aggregators map[string]*KlineAggregator // key: symbol:interval
 	storage     StorageInterface
+	publisher   KlinePublisher   // 为 nil 时不推送K线更新
+	sourceStore KlineSourceStore // 为 nil 时不单独保存交易所K线
+	state       KlineStateStore  // 为 nil 时重启不保留未收盘的K线
+	history     KlineHistory     // 为 nil 时最多补齐 defaultMaxGapKlines 根空K线
 	mu          sync.RWMutex
-	intervals   []string
+
+	// 聚合的周期：1m 和秒级周期由成交聚合，其余周期由 1m 收盘K线合成
+	intervals       []string            // 默认周期
+	symbolIntervals map[string][]string // 按交易对覆盖的周期
 }
 
 // StorageInterface 存储接口
@@ -24,52 +34,399 @@ type StorageInterface interface {
 	SaveTrade(trade *models.Trade) error
 }
 
-// NewKlineHandler 创建K线处理器
-func NewKlineHandler(storage StorageInterface) *KlineHandler {
-	return &KlineHandler{
-		aggregators: make(map[string]*KlineAggregator),
-		storage:     storage,
-		intervals: []string{
-			constants.Interval1m,
-			constants.Interval5m,
-			constants.Interval15m,
-			constants.Interval1h,
-			constants.Interval4h,
-			constants.Interval1d,
-		},
+// KlinePublisher K线更新推送接口
+type KlinePublisher interface {
+	PublishKline(update *models.KlineUpdate) error
+}
+
+// KlinePublishers 同时推送到多个目标（Redis Pub/Sub、Kafka 等），返回第一个错误
+type KlinePublishers []KlinePublisher
+
+// PublishKline 推送K线更新
+func (ps KlinePublishers) PublishKline(update *models.KlineUpdate) error {
+	var firstErr error
+	for _, p := range ps {
+		if err := p.PublishKline(update); err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
+	return firstErr
 }
 
-// HandleTrade 处理交易数据生成K线
-func (h *KlineHandler) HandleTrade(trade *models.Trade) error {
+// KlineSourceStore 交易所K线存储接口（与本地聚合K线分开保存，按来源区分）
+type KlineSourceStore interface {
+	SaveSourceKline(kline *models.Kline) error
+}
+
+// KlineBatchStorage 批量保存K线的存储接口（可选），补齐的空K线一次写入，未实现时逐根保存
+type KlineBatchStorage interface {
+	SaveKlines(klines []*models.Kline) error
+}
+
+// KlineHistory 各交易对和周期保留的K线数，补齐空K线时最多补齐该数量，更早的空K线不会被保留，不再生成
+type KlineHistory interface {
+	KlineHistorySize(symbol, interval string) int64
+}
+
+// KlineStateStore 未收盘K线的存储接口，停机时保存、启动时恢复，重启不丢失正在形成的K线
+type KlineStateStore interface {
+	SaveCurrentKlines(klines []*models.Kline) error
+	LoadCurrentKlines() ([]*models.Kline, error)
+}
+
+// ReconcileStats 交易所K线与本地聚合K线的核对统计
+type ReconcileStats struct {
+	Matched    int64 // 一致的K线数
+	Mismatched int64 // 偏差超过容忍度的K线数
+	Filled     int64 // 本地缺失、使用交易所K线补齐的K线数
+}
+
+// 核对容忍度（相对偏差）：本地聚合只包含采集到的成交，允许少量差异
+const (
+	reconcilePriceTolerance  = 0.001 // 收盘价
+	reconcileVolumeTolerance = 0.01  // 成交量
+)
+
+// 每个聚合器保留的最近收盘K线数，用于核对晚到的交易所K线
+const reconcileHistorySize = 10
+
+// defaultMaxGapKlines 未设置 KlineHistory 时最多补齐的空K线数（与 Redis 默认保留的K线数相同）
+const defaultMaxGapKlines = 1000
+
+// defaultIntervals 未配置时聚合的周期
+var defaultIntervals = []string{
+	constants.Interval1m,
+	constants.Interval5m,
+	constants.Interval15m,
+	constants.Interval1h,
+	constants.Interval4h,
+	constants.Interval1d,
+	constants.Interval1w,
+	constants.Interval1M,
+}
+
+// NewKlineHandler 创建K线处理器，state 不为 nil 时恢复上次停机时未收盘的K线
+func NewKlineHandler(storage StorageInterface, state KlineStateStore, cfg config.KlineConfig) *KlineHandler {
+	h := &KlineHandler{
+		aggregators:     make(map[string]*KlineAggregator),
+		storage:         storage,
+		state:           state,
+		intervals:       normalizeIntervals(cfg.Intervals),
+		symbolIntervals: make(map[string][]string, len(cfg.Symbols)),
+	}
+	for symbol, intervals := range cfg.Symbols {
+		h.symbolIntervals[symbol] = normalizeIntervals(intervals)
+	}
+	h.restore()
+	return h
+}
+
+// normalizeIntervals 校验配置的周期，为空时使用默认周期；1m 是合成更高周期的基础，始终聚合
+func normalizeIntervals(intervals []string) []string {
+	if len(intervals) == 0 {
+		return defaultIntervals
+	}
+
+	result := []string{rollupBaseInterval}
+	seen := map[string]bool{rollupBaseInterval: true}
+	for _, interval := range intervals {
+		if seen[interval] {
+			continue
+		}
+		if !utils.ValidateInterval(interval) {
+			log.Printf("[Kline] Ignored unsupported interval: %s\n", interval)
+			continue
+		}
+		seen[interval] = true
+		result = append(result, interval)
+	}
+	return result
+}
+
+// SetPublisher 设置K线更新推送（需在处理数据前调用）
+func (h *KlineHandler) SetPublisher(publisher KlinePublisher) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	h.publisher = publisher
 
-	// 为每个周期生成K线
-	for _, interval := range h.intervals {
-		key := trade.Symbol + ":" + interval
-		aggregator, ok := h.aggregators[key]
-		if !ok {
-			aggregator = NewKlineAggregator(trade.Symbol, interval, h.storage)
-			h.aggregators[key] = aggregator
+	// 恢复的聚合器在设置推送前已创建
+	for _, aggregator := range h.aggregators {
+		aggregator.mu.Lock()
+		aggregator.publisher = publisher
+		aggregator.mu.Unlock()
+	}
+}
+
+// SetHistory 设置各周期保留的K线数，用于限制补齐的空K线数（需在处理数据前调用）
+func (h *KlineHandler) SetHistory(history KlineHistory) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.history = history
+
+	for _, aggregator := range h.aggregators {
+		aggregator.mu.Lock()
+		aggregator.maxGap = h.maxGap(aggregator.symbol, aggregator.interval)
+		aggregator.mu.Unlock()
+	}
+}
+
+// maxGap 交易对和周期最多补齐的空K线数
+func (h *KlineHandler) maxGap(symbol, interval string) int {
+	if h.history != nil {
+		if size := h.history.KlineHistorySize(symbol, interval); size > 0 {
+			return int(size)
 		}
+	}
+	return defaultMaxGapKlines
+}
+
+// Stop 保存所有聚合器未收盘的K线，需在停止处理数据后调用
+func (h *KlineHandler) Stop() {
+	if h.state == nil {
+		return
+	}
 
-		// 添加交易数据
-		if err := aggregator.AddTrade(trade); err != nil {
-			log.Printf("[Kline] Failed to add trade for %s: %v\n", key, err)
+	h.mu.RLock()
+	klines := make([]*models.Kline, 0, len(h.aggregators))
+	for _, aggregator := range h.aggregators {
+		if kline := aggregator.GetCurrentKline(); kline != nil {
+			klines = append(klines, kline)
 		}
 	}
+	h.mu.RUnlock()
+
+	if err := h.state.SaveCurrentKlines(klines); err != nil {
+		log.Printf("[Kline] Failed to save current klines: %v\n", err)
+		return
+	}
+	log.Printf("[Kline] Saved %d current klines\n", len(klines))
+}
+
+// restore 恢复停机时保存的未收盘K线，已过收盘时间的K线由 Run 按周期边界收盘
+func (h *KlineHandler) restore() {
+	if h.state == nil {
+		return
+	}
 
+	klines, err := h.state.LoadCurrentKlines()
+	if err != nil {
+		log.Printf("[Kline] Failed to load current klines: %v\n", err)
+		return
+	}
+
+	if restored := h.Restore(klines); restored > 0 {
+		log.Printf("[Kline] Restored %d current klines\n", restored)
+	}
+}
+
+// Restore 恢复保存的未收盘K线，返回恢复的K线数；未聚合的周期和不完整的K线忽略
+func (h *KlineHandler) Restore(klines []*models.Kline) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	restored := 0
+	for _, kline := range klines {
+		if !h.aggregates(kline.Symbol, kline.Interval) || kline.CloseTime != utils.GetKlineCloseTime(kline.OpenTime, kline.Interval) {
+			continue
+		}
+		kline.Source = constants.KlineSourceLocal
+		aggregator := h.aggregator(kline.Symbol, kline.Interval)
+		aggregator.mu.Lock()
+		aggregator.currentKline = kline
+		aggregator.mu.Unlock()
+		restored++
+	}
+	return restored
+}
+
+// CurrentOpenTime 获取正在聚合的K线开盘时间，没有时返回 0
+func (h *KlineHandler) CurrentOpenTime(symbol, interval string) int64 {
+	h.mu.RLock()
+	aggregator, ok := h.aggregators[symbol+":"+interval]
+	h.mu.RUnlock()
+	if !ok {
+		return 0
+	}
+
+	if kline := aggregator.GetCurrentKline(); kline != nil {
+		return kline.OpenTime
+	}
+	return 0
+}
+
+// RemoveSymbol 丢弃交易对的全部聚合器，返回未收盘的K线（不保存，由调用方决定是否保留），之后收到成交时重新开始聚合
+func (h *KlineHandler) RemoveSymbol(symbol string) []*models.Kline {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var klines []*models.Kline
+	for key, aggregator := range h.aggregators {
+		if aggregator.symbol == symbol {
+			if kline := aggregator.GetCurrentKline(); kline != nil {
+				klines = append(klines, kline)
+			}
+			delete(h.aggregators, key)
+		}
+	}
+	return klines
+}
+
+// Intervals 交易对聚合的周期
+func (h *KlineHandler) Intervals(symbol string) []string {
+	if intervals, ok := h.symbolIntervals[symbol]; ok {
+		return intervals
+	}
+	return h.intervals
+}
+
+// aggregates 交易对是否本地聚合该周期
+func (h *KlineHandler) aggregates(symbol, interval string) bool {
+	for _, iv := range h.Intervals(symbol) {
+		if iv == interval {
+			return true
+		}
+	}
+	return false
+}
+
+// SetSourceStore 设置交易所K线存储（需在处理数据前调用）
+func (h *KlineHandler) SetSourceStore(store KlineSourceStore) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sourceStore = store
+}
+
+// HandleTrade 处理交易数据生成K线，成交只更新 1m 和秒级K线，更高周期在 1m K线收盘后合成
+func (h *KlineHandler) HandleTrade(trade *models.Trade) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, interval := range h.Intervals(trade.Symbol) {
+		if !tradeAggregated(interval) {
+			continue
+		}
+		if err := h.aggregator(trade.Symbol, interval).AddTrade(trade); err != nil {
+			log.Printf("[Kline] Failed to add trade for %s:%s: %v\n", trade.Symbol, interval, err)
+		}
+	}
+
+	return nil
+}
+
+// HandleKline 处理交易所推送的K线（Source 为交易所名称）
+// 同一根K线会多次推送，收到下一根K线时上一根视为收盘：单独保存，并与本地聚合K线核对，
+// 本地缺失的K线（采集中断、未订阅成交等）使用交易所K线补齐
+func (h *KlineHandler) HandleKline(kline *models.Kline) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var final *models.Kline
+	if h.aggregates(kline.Symbol, kline.Interval) {
+		final = h.aggregator(kline.Symbol, kline.Interval).AddSourceKline(kline)
+	} else {
+		// 本地不聚合的周期只保存，不核对
+		final = h.aggregator(kline.Symbol, kline.Interval).trackSource(kline)
+	}
+
+	if final != nil && h.sourceStore != nil {
+		if err := h.sourceStore.SaveSourceKline(final); err != nil {
+			log.Printf("[Kline] Failed to save %s kline %s %s: %v\n", final.Source, final.Symbol, final.Interval, err)
+		}
+	}
 	return nil
 }
 
+// Run 按周期边界收盘K线，直到 stop 关闭
+// 聚合器只在收到下一周期的成交时收盘，不活跃的交易对会一直没有收盘K线；
+// 这里每秒检查一次，超过收盘时间加迟到成交等待时间后，没有新成交也保存并推送收盘K线
+func (h *KlineHandler) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			h.closeDue(utils.GetCurrentTimestamp() - constants.KlineCloseDelay)
+		}
+	}
+}
+
+// closeDue 收盘所有由成交聚合的聚合器（1m 和秒级）中到期的K线，更高周期随最后一根 1m K线并入后收盘
+func (h *KlineHandler) closeDue(timestamp int64) {
+	h.mu.RLock()
+	aggregators := make([]*KlineAggregator, 0, len(h.aggregators))
+	for _, aggregator := range h.aggregators {
+		if tradeAggregated(aggregator.interval) {
+			aggregators = append(aggregators, aggregator)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, aggregator := range aggregators {
+		aggregator.CloseDue(timestamp)
+	}
+}
+
+// ReconcileStats 获取核对统计
+func (h *KlineHandler) ReconcileStats() ReconcileStats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var stats ReconcileStats
+	for _, aggregator := range h.aggregators {
+		stat := aggregator.ReconcileStats()
+		stats.Matched += stat.Matched
+		stats.Mismatched += stat.Mismatched
+		stats.Filled += stat.Filled
+	}
+	return stats
+}
+
+// aggregator 获取或创建聚合器（调用方需持有锁）
+func (h *KlineHandler) aggregator(symbol, interval string) *KlineAggregator {
+	key := symbol + ":" + interval
+	aggregator, ok := h.aggregators[key]
+	if !ok {
+		aggregator = NewKlineAggregator(symbol, interval, h.storage)
+		aggregator.publisher = h.publisher
+		aggregator.maxGap = h.maxGap(symbol, interval)
+		h.aggregators[key] = aggregator
+
+		if interval == rollupBaseInterval {
+			intervals := h.Intervals(symbol)
+			targets := make([]*KlineAggregator, 0, len(intervals))
+			for _, iv := range intervals {
+				if !tradeAggregated(iv) {
+					targets = append(targets, h.aggregator(symbol, iv))
+				}
+			}
+			aggregator.rollup = NewRollup(targets)
+		}
+	}
+	return aggregator
+}
+
 // KlineAggregator K线聚合器
 type KlineAggregator struct {
 	symbol       string
 	interval     string
 	currentKline *models.Kline
 	storage      StorageInterface
-	mu           sync.RWMutex
+	publisher    KlinePublisher
+	lastPush     int64   // 上次推送实时K线的时间（毫秒）
+	lateTrades   int64   // 所属K线已收盘的迟到成交数
+	rollup       *Rollup // 1m 聚合器收盘后合成更高周期K线，其他周期为 nil
+	maxGap       int     // 最多补齐的空K线数
+
+	closed     []*models.Kline          // 最近收盘的本地K线，按开盘时间升序
+	sources    map[string]*models.Kline // 各交易所推送的当前K线，key: 来源
+	pending    []*models.Kline          // 已收盘、等待本地K线收盘后核对的交易所K线
+	lastFilled int64                    // 最近一次补齐的K线开盘时间，避免多个交易所重复补齐
+	reconcile  ReconcileStats
+
+	mu sync.RWMutex
 }
 
 // NewKlineAggregator 创建K线聚合器
@@ -78,6 +435,8 @@ func NewKlineAggregator(symbol, interval string, storage StorageInterface) *Klin
 		symbol:   symbol,
 		interval: interval,
 		storage:  storage,
+		sources:  make(map[string]*models.Kline),
+		maxGap:   defaultMaxGapKlines,
 	}
 }
 
@@ -88,15 +447,20 @@ func (a *KlineAggregator) AddTrade(trade *models.Trade) error {
 
 	openTime := utils.GetKlineOpenTime(trade.Timestamp, a.interval)
 
-	// 检查是否需要生成新K线
-	if a.currentKline == nil || a.currentKline.OpenTime != openTime {
-		// 保存旧K线
-		if a.currentKline != nil {
-			if err := a.saveKline(); err != nil {
-				log.Printf("[Kline] Failed to save kline: %v\n", err)
-			}
+	// 所属K线已收盘（超过迟到成交等待时间），不再更新
+	if a.currentKline != nil && openTime < a.currentKline.OpenTime {
+		a.lateTrades++
+		if a.lateTrades%100 == 1 {
+			log.Printf("[Kline] Dropped late trade for %s %s (total %d)\n", a.symbol, a.interval, a.lateTrades)
 		}
+		return nil
+	}
+
+	// 收盘当前K线，中间没有成交的周期生成空K线
+	a.closeUntil(openTime)
 
+	// 检查是否需要生成新K线
+	if a.currentKline == nil {
 		// 创建新K线
 		a.currentKline = &models.Kline{
 			Symbol:    a.symbol,
@@ -110,15 +474,181 @@ func (a *KlineAggregator) AddTrade(trade *models.Trade) error {
 			Volume:    0,
 			QuoteVol:  0,
 			TradeNum:  0,
+			Source:    constants.KlineSourceLocal,
 		}
+	} else if a.currentKline.TradeNum == 0 {
+		// 空K线的第一笔成交决定开盘价
+		a.currentKline.Open = trade.Price
+		a.currentKline.High = trade.Price
+		a.currentKline.Low = trade.Price
+		a.currentKline.Close = trade.Price
 	}
 
 	// 更新K线数据
 	a.updateKline(trade)
 
+	// 实时K线按最小间隔推送
+	now := utils.GetCurrentTimestamp()
+	if now-a.lastPush >= constants.KlineLivePushInterval {
+		a.lastPush = now
+		a.publishLive()
+	}
+
 	return nil
 }
 
+// CloseDue 收盘开盘时间早于 timestamp 所在周期的K线，没有新成交时生成沿用收盘价的空K线
+func (a *KlineAggregator) CloseDue(timestamp int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	current := a.currentKline
+	if current == nil {
+		return
+	}
+	a.closeUntil(utils.GetKlineOpenTime(timestamp, a.interval))
+
+	// 推送新开始的空K线，图表无需等待成交即可显示正在形成的K线
+	if a.currentKline != current {
+		a.lastPush = utils.GetCurrentTimestamp()
+		a.publishLive()
+	}
+}
+
+// AddKline 并入收盘的 1m K线，合成本周期K线（由 Rollup 调用）
+func (a *KlineAggregator) AddKline(kline *models.Kline) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	openTime := utils.GetKlineOpenTime(kline.OpenTime, a.interval)
+	if a.currentKline != nil && openTime < a.currentKline.OpenTime {
+		return // 所属K线已收盘
+	}
+
+	a.closeUntil(openTime)
+	if a.currentKline == nil {
+		a.currentKline = rollupKline(kline, a.interval)
+	} else {
+		mergeKline(a.currentKline, kline)
+	}
+
+	// 周期内最后一根 1m K线并入后收盘
+	if kline.CloseTime >= a.currentKline.CloseTime {
+		a.closeUntil(a.currentKline.CloseTime + 1)
+	}
+}
+
+// closeUntil 依次收盘开盘时间早于 openTime 的K线（调用方需持有锁）
+// 中间没有成交的周期生成空K线，开高低收沿用上一根的收盘价；空K线最多补齐 maxGap 根（更早的不会被保留），
+// 批量写入并只推送最后一根，长时间没有成交、恢复较早的K线或成交时间异常时不会长时间占用锁
+func (a *KlineAggregator) closeUntil(openTime int64) {
+	prev := a.currentKline
+	if prev == nil || prev.OpenTime >= openTime {
+		return
+	}
+	if prev.CloseTime <= prev.OpenTime {
+		return // 不支持的周期
+	}
+
+	// 保存旧K线
+	if err := a.saveKline(); err != nil {
+		log.Printf("[Kline] Failed to save kline: %v\n", err)
+	}
+
+	gap := a.emptyKlines(prev, openTime)
+	if len(gap) > 0 {
+		a.saveEmpty(gap)
+	}
+
+	for _, closed := range append([]*models.Kline{prev}, gap...) {
+		a.remember(closed)
+		if a.rollup != nil {
+			a.rollup.AddClosed(closed)
+		}
+
+		// 下一根K线先以空K线开始
+		a.currentKline = a.emptyKline(closed.CloseTime+1, prev.Close)
+
+		// 本地K线收盘后核对等待中的交易所K线
+		a.resolvePending()
+	}
+}
+
+// emptyKlines 上一根K线之后、openTime 之前没有成交的周期，最多 maxGap 根（取最近的）
+func (a *KlineAggregator) emptyKlines(prev *models.Kline, openTime int64) []*models.Kline {
+	next := prev.CloseTime + 1
+	if next >= openTime {
+		return nil
+	}
+
+	// 跳过超出数量的周期，月线的周期长度不固定，按上一根的长度估算后再截取
+	maxGap := a.maxGap
+	if maxGap <= 0 {
+		maxGap = defaultMaxGapKlines
+	}
+	span := prev.CloseTime + 1 - prev.OpenTime
+	if from := utils.GetKlineOpenTime(openTime-int64(maxGap)*span, a.interval); from > next {
+		next = from
+	}
+
+	var klines []*models.Kline
+	for next < openTime {
+		kline := a.emptyKline(next, prev.Close)
+		if kline.CloseTime < kline.OpenTime {
+			break // 不支持的周期
+		}
+		kline.IsFinal = true
+		klines = append(klines, kline)
+		next = kline.CloseTime + 1
+	}
+	if len(klines) > maxGap {
+		klines = klines[len(klines)-maxGap:]
+	}
+	return klines
+}
+
+// emptyKline 没有成交的K线，开高低收为 price
+func (a *KlineAggregator) emptyKline(openTime int64, price float64) *models.Kline {
+	return &models.Kline{
+		Symbol:    a.symbol,
+		Interval:  a.interval,
+		OpenTime:  openTime,
+		CloseTime: utils.GetKlineCloseTime(openTime, a.interval),
+		Open:      price,
+		High:      price,
+		Low:       price,
+		Close:     price,
+		Source:    constants.KlineSourceLocal,
+	}
+}
+
+// saveEmpty 批量保存补齐的空K线，只推送最后一根（调用方需持有锁）
+func (a *KlineAggregator) saveEmpty(klines []*models.Kline) {
+	if len(klines) > 1 {
+		log.Printf("[Kline] Filling %d empty %s %s klines\n", len(klines), a.symbol, a.interval)
+	}
+
+	var err error
+	if batch, ok := a.storage.(KlineBatchStorage); ok {
+		err = batch.SaveKlines(klines)
+	} else {
+		for _, kline := range klines {
+			if err = a.storage.SaveKline(kline); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		log.Printf("[Kline] Failed to save empty klines: %v\n", err)
+	}
+
+	if a.publisher != nil {
+		if err := a.publisher.PublishKline(models.NewKlineUpdate(klines[len(klines)-1], true)); err != nil {
+			log.Printf("[Kline] Failed to publish %s %s: %v\n", a.symbol, a.interval, err)
+		}
+	}
+}
+
 // updateKline 更新K线数据
 func (a *KlineAggregator) updateKline(trade *models.Trade) {
 	k := a.currentKline
@@ -154,12 +684,196 @@ func (a *KlineAggregator) saveKline() error {
 		a.currentKline.Low, a.currentKline.Close,
 		a.currentKline.Volume)
 
-	return a.storage.SaveKline(a.currentKline)
+	// 先保存再推送，推送的收盘K线带有存储分配的修订号；收盘K线立即推送，不受实时推送间隔限制
+	a.currentKline.IsFinal = true
+	err := a.storage.SaveKline(a.currentKline)
+	a.publish(true)
+
+	return err
+}
+
+// publish 推送K线更新
+func (a *KlineAggregator) publish(closed bool) {
+	if a.publisher == nil {
+		return
+	}
+	if err := a.publisher.PublishKline(models.NewKlineUpdate(a.currentKline, closed)); err != nil {
+		log.Printf("[Kline] Failed to publish %s %s: %v\n", a.symbol, a.interval, err)
+	}
+}
+
+// publishLive 推送实时K线，1m 聚合器同时推送合成的更高周期实时K线（调用方需持有锁）
+func (a *KlineAggregator) publishLive() {
+	a.publish(false)
+	if a.rollup != nil {
+		a.rollup.PublishLive(a.currentKline)
+	}
+}
+
+// publishPreview 推送叠加了正在形成的 1m K线的实时K线，不修改当前K线
+func (a *KlineAggregator) publishPreview(current *models.Kline) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.publisher == nil {
+		return
+	}
+
+	var preview *models.Kline
+	openTime := utils.GetKlineOpenTime(current.OpenTime, a.interval)
+	switch {
+	case a.currentKline == nil || a.currentKline.OpenTime < openTime:
+		preview = rollupKline(current, a.interval)
+	case a.currentKline.OpenTime == openTime:
+		k := *a.currentKline
+		mergeKline(&k, current)
+		preview = &k
+	default:
+		return
+	}
+
+	if err := a.publisher.PublishKline(models.NewKlineUpdate(preview, false)); err != nil {
+		log.Printf("[Kline] Failed to publish %s %s: %v\n", a.symbol, a.interval, err)
+	}
+}
+
+// AddSourceKline 添加交易所推送的K线，返回已收盘的交易所K线（没有则返回 nil）
+func (a *KlineAggregator) AddSourceKline(kline *models.Kline) *models.Kline {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	final := a.trackSourceLocked(kline)
+	if final != nil {
+		a.pending = append(a.pending, final)
+		if len(a.pending) > reconcileHistorySize {
+			a.pending = a.pending[len(a.pending)-reconcileHistorySize:]
+		}
+		a.resolvePending()
+	}
+	return final
+}
+
+// trackSource 记录交易所推送的K线，返回已收盘的交易所K线（没有则返回 nil）
+func (a *KlineAggregator) trackSource(kline *models.Kline) *models.Kline {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.trackSourceLocked(kline)
+}
+
+// trackSourceLocked 同一来源收到开盘时间更晚的K线时，上一根视为收盘（调用方需持有锁）
+func (a *KlineAggregator) trackSourceLocked(kline *models.Kline) *models.Kline {
+	k := *kline
+	prev, ok := a.sources[k.Source]
+	if ok && k.OpenTime < prev.OpenTime {
+		return nil // 乱序的旧K线
+	}
+	a.sources[k.Source] = &k
+	if ok && k.OpenTime > prev.OpenTime {
+		prev.IsFinal = true
+		return prev
+	}
+	return nil
+}
+
+// ReconcileStats 获取核对统计
+func (a *KlineAggregator) ReconcileStats() ReconcileStats {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.reconcile
+}
+
+// remember 记录收盘的本地K线（调用方需持有锁）
+func (a *KlineAggregator) remember(kline *models.Kline) {
+	a.closed = append(a.closed, kline)
+	if len(a.closed) > reconcileHistorySize {
+		a.closed = a.closed[len(a.closed)-reconcileHistorySize:]
+	}
 }
 
-// GetCurrentKline 获取当前K线
+// resolvePending 核对等待中的交易所K线（调用方需持有锁）
+// 本地已收盘的K线直接比较；本地当前K线已越过该开盘时间但没有对应K线时补齐；其余继续等待
+func (a *KlineAggregator) resolvePending() {
+	remaining := a.pending[:0]
+	for _, k := range a.pending {
+		if local := a.closedKline(k.OpenTime); local != nil {
+			a.compare(local, k)
+			continue
+		}
+		if a.currentKline == nil || a.currentKline.OpenTime > k.OpenTime {
+			a.fill(k)
+			continue
+		}
+		remaining = append(remaining, k)
+	}
+	a.pending = remaining
+}
+
+// closedKline 按开盘时间查找收盘的本地K线（调用方需持有锁）
+func (a *KlineAggregator) closedKline(openTime int64) *models.Kline {
+	for i := len(a.closed) - 1; i >= 0; i-- {
+		if a.closed[i].OpenTime == openTime {
+			return a.closed[i]
+		}
+	}
+	return nil
+}
+
+// compare 比较本地K线与交易所K线（调用方需持有锁）
+func (a *KlineAggregator) compare(local, source *models.Kline) {
+	if relativeDiff(local.Close, source.Close) <= reconcilePriceTolerance &&
+		relativeDiff(local.Volume, source.Volume) <= reconcileVolumeTolerance {
+		a.reconcile.Matched++
+		return
+	}
+
+	a.reconcile.Mismatched++
+	if a.reconcile.Mismatched%100 == 1 {
+		log.Printf("[Kline] Reconcile mismatch %s %s %d: local C:%.2f V:%.2f, %s C:%.2f V:%.2f (total %d)\n",
+			a.symbol, a.interval, local.OpenTime, local.Close, local.Volume,
+			source.Source, source.Close, source.Volume, a.reconcile.Mismatched)
+	}
+}
+
+// fill 使用交易所K线补齐本地缺失的K线（调用方需持有锁）
+func (a *KlineAggregator) fill(source *models.Kline) {
+	// 本地有成交时，早于核对窗口（包括启动后第一根本地K线之前）的K线无法判断本地是否存在，不补齐
+	if a.currentKline != nil && (len(a.closed) == 0 || source.OpenTime < a.closed[0].OpenTime) {
+		return
+	}
+	if source.OpenTime <= a.lastFilled {
+		return
+	}
+	a.lastFilled = source.OpenTime
+
+	log.Printf("[Kline] Filling %s %s %d from %s\n", a.symbol, a.interval, source.OpenTime, source.Source)
+
+	if err := a.storage.SaveKline(source); err != nil {
+		log.Printf("[Kline] Failed to save filled kline: %v\n", err)
+		return
+	}
+	if a.publisher != nil {
+		if err := a.publisher.PublishKline(models.NewKlineUpdate(source, true)); err != nil {
+			log.Printf("[Kline] Failed to publish %s %s: %v\n", a.symbol, a.interval, err)
+		}
+	}
+	a.reconcile.Filled++
+}
+
+// relativeDiff 相对偏差
+func relativeDiff(a, b float64) float64 {
+	if a == b {
+		return 0
+	}
+	return math.Abs(a-b) / math.Max(math.Abs(a), math.Abs(b))
+}
+
+// GetCurrentKline 获取当前K线（副本）
 func (a *KlineAggregator) GetCurrentKline() *models.Kline {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
-	return a.currentKline
+	if a.currentKline == nil {
+		return nil
+	}
+	kline := *a.currentKline
+	return &kline
 }
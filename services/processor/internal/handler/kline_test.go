@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"testing"
+)
+
+func TestKlineIntervalsPerSymbol(t *testing.T) {
+	storage := &memoryStorage{}
+	h := NewKlineHandler(storage, nil, config.KlineConfig{
+		Symbols: map[string][]string{
+			"ETHUSDT": {constants.Interval1s, constants.Interval1h, "7m"},
+		},
+	})
+
+	// 未单独配置的交易对使用默认周期，1m 始终聚合，不支持的周期被忽略
+	if got := h.Intervals("BTCUSDT"); len(got) != len(defaultIntervals) {
+		t.Errorf("default intervals = %v, want %v", got, defaultIntervals)
+	}
+	want := []string{constants.Interval1m, constants.Interval1s, constants.Interval1h}
+	if got := h.Intervals("ETHUSDT"); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("ETHUSDT intervals = %v, want %v", got, want)
+	}
+
+	h.HandleTrade(&models.Trade{Symbol: "ETHUSDT", Price: 100, Amount: 1, Timestamp: minute(0, 1)})
+	h.HandleTrade(&models.Trade{Symbol: "ETHUSDT", Price: 101, Amount: 2, Timestamp: minute(0, 3)})
+	h.closeDue(minute(1, 0))
+
+	// 秒级K线由成交直接聚合，中间没有成交的秒生成空K线
+	seconds := storage.saved(constants.Interval1s)
+	if len(seconds) != 59 {
+		t.Fatalf("saved %d 1s klines, want 59", len(seconds))
+	}
+	if k := seconds[0]; k.OpenTime != minute(0, 1) || k.CloseTime != minute(0, 2)-1 || k.Volume != 1 {
+		t.Errorf("unexpected first 1s kline: %+v", k)
+	}
+	if k := seconds[2]; k.OpenTime != minute(0, 3) || k.Close != 101 || k.Volume != 2 {
+		t.Errorf("unexpected 1s kline: %+v", k)
+	}
+
+	// 1m 收盘后只合成配置的更高周期
+	if got := storage.saved(constants.Interval1m); len(got) != 1 || got[0].Volume != 3 {
+		t.Errorf("unexpected 1m klines: %+v", got)
+	}
+	if h.CurrentOpenTime("ETHUSDT", constants.Interval1h) != minute(0, 0) {
+		t.Error("1h kline not aggregated for ETHUSDT")
+	}
+	if h.CurrentOpenTime("ETHUSDT", constants.Interval5m) != 0 {
+		t.Error("5m kline aggregated for ETHUSDT")
+	}
+}
+
+// revisingStorage 模拟存储为改写的K线分配修订号
+type revisingStorage struct {
+	memoryStorage
+}
+
+func (s *revisingStorage) SaveKline(kline *models.Kline) error {
+	kline.Revision = 2
+	return s.memoryStorage.SaveKline(kline)
+}
+
+func TestKlineFinalRevision(t *testing.T) {
+	storage := &revisingStorage{}
+	publisher := &memoryPublisher{}
+	a := NewKlineAggregator("BTCUSDT", constants.Interval1m, storage)
+	a.publisher = publisher
+
+	a.AddTrade(&models.Trade{Symbol: "BTCUSDT", Price: 100, Amount: 1, Timestamp: minute(0, 1)})
+	a.CloseDue(minute(1, 0))
+
+	if len(publisher.updates) < 2 {
+		t.Fatalf("published %d updates, want live and closed", len(publisher.updates))
+	}
+	if live := publisher.updates[0]; live.Closed || live.IsFinal {
+		t.Errorf("live kline marked final: %+v", live)
+	}
+	// 收盘K线先保存再推送，推送带有存储分配的修订号
+	closed := publisher.updates[1]
+	if !closed.Closed || !closed.IsFinal || closed.Revision != 2 {
+		t.Errorf("unexpected closed update: %+v", closed)
+	}
+	if saved := storage.saved(constants.Interval1m); len(saved) != 1 || !saved[0].IsFinal {
+		t.Errorf("unexpected saved klines: %+v", saved)
+	}
+	// 新开始的K线不继承收盘标记和修订号
+	if k := a.GetCurrentKline(); k.IsFinal || k.Revision != 0 {
+		t.Errorf("unexpected current kline: %+v", k)
+	}
+}
+
+func TestKlineReleaseRestore(t *testing.T) {
+	from := NewKlineHandler(&memoryStorage{}, nil, config.KlineConfig{})
+	from.HandleTrade(&models.Trade{Symbol: "BTCUSDT", Price: 100, Amount: 1, Timestamp: minute(0, 1)})
+	from.HandleTrade(&models.Trade{Symbol: "BTCUSDT", Price: 105, Amount: 2, Timestamp: minute(1, 30)})
+
+	// 分区收回：丢弃交易对的聚合器，返回各周期未收盘的K线
+	klines := from.RemoveSymbol("BTCUSDT")
+	released := make(map[string]*models.Kline)
+	for _, k := range klines {
+		released[k.Interval] = k
+	}
+	if k := released[constants.Interval1m]; k == nil || k.OpenTime != minute(1, 0) || k.Volume != 2 {
+		t.Fatalf("unexpected released 1m kline: %+v", k)
+	}
+	if k := released[constants.Interval5m]; k == nil || k.Volume != 1 {
+		t.Fatalf("unexpected released 5m kline: %+v", k)
+	}
+	if from.CurrentOpenTime("BTCUSDT", constants.Interval1m) != 0 {
+		t.Error("aggregator kept after release")
+	}
+
+	// 新实例恢复后继续聚合同一根K线
+	storage := &memoryStorage{}
+	to := NewKlineHandler(storage, nil, config.KlineConfig{})
+	if restored := to.Restore(klines); restored != len(klines) {
+		t.Fatalf("restored %d klines, want %d", restored, len(klines))
+	}
+	to.HandleTrade(&models.Trade{Symbol: "BTCUSDT", Price: 99, Amount: 3, Timestamp: minute(1, 45)})
+	to.closeDue(minute(5, 0))
+
+	minutes := storage.saved(constants.Interval1m)
+	if len(minutes) == 0 || minutes[0].Open != 105 || minutes[0].Low != 99 || minutes[0].Volume != 5 {
+		t.Fatalf("unexpected 1m klines after restore: %+v", minutes)
+	}
+	if got := storage.saved(constants.Interval5m); len(got) != 1 || got[0].Open != 100 || got[0].High != 105 || got[0].Volume != 6 {
+		t.Errorf("unexpected 5m klines after restore: %+v", got)
+	}
+}
+
+// batchStorage 记录批量保存的次数
+type batchStorage struct {
+	memoryStorage
+	batches int
+}
+
+func (s *batchStorage) SaveKlines(klines []*models.Kline) error {
+	s.batches++
+	for _, kline := range klines {
+		s.memoryStorage.SaveKline(kline)
+	}
+	return nil
+}
+
+// fixedHistory 所有周期保留相同数量的K线
+type fixedHistory int64
+
+func (h fixedHistory) KlineHistorySize(symbol, interval string) int64 { return int64(h) }
+
+func TestKlineGapFillLimit(t *testing.T) {
+	storage := &batchStorage{}
+	publisher := &memoryPublisher{}
+	h := NewKlineHandler(storage, nil, config.KlineConfig{
+		Symbols: map[string][]string{"BTCUSDT": {constants.Interval1m}},
+	})
+	h.SetPublisher(publisher)
+	h.SetHistory(fixedHistory(5))
+
+	h.HandleTrade(&models.Trade{Symbol: "BTCUSDT", Price: 100, Amount: 1, Timestamp: minute(0, 1)})
+	h.HandleTrade(&models.Trade{Symbol: "BTCUSDT", Price: 101, Amount: 1, Timestamp: minute(30, 1)})
+
+	// 中断 29 分钟只补齐保留数量内最近的 5 根空K线，并一次写入
+	saved := storage.saved(constants.Interval1m)
+	if len(saved) != 6 || storage.batches != 1 {
+		t.Fatalf("saved %d klines in %d batches, want 6 in 1", len(saved), storage.batches)
+	}
+	if saved[0].OpenTime != minute(0, 0) || saved[0].Volume != 1 {
+		t.Errorf("unexpected closed kline: %+v", saved[0])
+	}
+	for i, k := range saved[1:] {
+		if k.OpenTime != minute(25+i, 0) || k.Close != 100 || k.Volume != 0 || !k.IsFinal {
+			t.Errorf("unexpected empty kline %d: %+v", i, k)
+		}
+	}
+
+	// 空K线只推送最后一根
+	var closed []int64
+	for _, update := range publisher.updates {
+		if update.Closed {
+			closed = append(closed, update.OpenTime)
+		}
+	}
+	if len(closed) != 2 || closed[0] != minute(0, 0) || closed[1] != minute(29, 0) {
+		t.Errorf("closed updates = %v", closed)
+	}
+	if h.CurrentOpenTime("BTCUSDT", constants.Interval1m) != minute(30, 0) {
+		t.Error("current kline not at the trade's minute")
+	}
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+)
+
+// rollupBaseInterval 由成交直接聚合的周期，更高周期由该周期的收盘K线合成
+const rollupBaseInterval = constants.Interval1m
+
+// tradeAggregated 是否由成交直接聚合：基础周期和秒级周期（秒级周期短于基础周期，不参与合成）
+func tradeAggregated(interval string) bool {
+	return interval == rollupBaseInterval || interval == constants.Interval1s
+}
+
+// Rollup 由基础周期（1m）收盘K线合成更高周期K线
+// 每笔成交只更新 1m 聚合器；1m K线收盘后并入各更高周期聚合器的当前K线，周期内最后一根 1m K线并入后收盘，
+// 结果与使用回补的 1m K线合成一致。实时推送时将正在形成的 1m K线叠加到已合成的部分上，不修改聚合器状态。
+type Rollup struct {
+	targets []*KlineAggregator // 更高周期的聚合器
+}
+
+// NewRollup 创建K线合成器
+func NewRollup(targets []*KlineAggregator) *Rollup {
+	return &Rollup{targets: targets}
+}
+
+// AddClosed 并入收盘的基础周期K线
+func (r *Rollup) AddClosed(kline *models.Kline) {
+	for _, target := range r.targets {
+		target.AddKline(kline)
+	}
+}
+
+// PublishLive 推送叠加了正在形成的基础周期K线的更高周期实时K线
+func (r *Rollup) PublishLive(current *models.Kline) {
+	for _, target := range r.targets {
+		target.publishPreview(current)
+	}
+}
+
+// rollupKline 以低周期K线开始一根新的高周期K线
+func rollupKline(src *models.Kline, interval string) *models.Kline {
+	openTime := utils.GetKlineOpenTime(src.OpenTime, interval)
+	return &models.Kline{
+		Symbol:    src.Symbol,
+		Interval:  interval,
+		OpenTime:  openTime,
+		CloseTime: utils.GetKlineCloseTime(openTime, interval),
+		Open:      src.Open,
+		High:      src.High,
+		Low:       src.Low,
+		Close:     src.Close,
+		Volume:    src.Volume,
+		QuoteVol:  src.QuoteVol,
+		TradeNum:  src.TradeNum,
+		Source:    constants.KlineSourceLocal,
+	}
+}
+
+// mergeKline 将低周期K线并入高周期K线
+// 没有成交的空K线只沿用收盘价，不参与开高低；高周期K线仍为空时由第一根有成交的低周期K线决定开盘价
+func mergeKline(dst, src *models.Kline) {
+	if src.TradeNum == 0 {
+		return
+	}
+
+	if dst.TradeNum == 0 {
+		dst.Open = src.Open
+		dst.High = src.High
+		dst.Low = src.Low
+	} else {
+		dst.High = utils.MaxFloat(dst.High, src.High)
+		dst.Low = utils.MinFloat(dst.Low, src.Low)
+	}
+	dst.Close = src.Close
+	dst.Volume += src.Volume
+	dst.QuoteVol += src.QuoteVol
+	dst.TradeNum += src.TradeNum
+}
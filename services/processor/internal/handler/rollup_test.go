@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"math"
+	"testing"
+	"time"
+)
+
+// memoryStorage 记录保存的K线
+type memoryStorage struct {
+	klines []models.Kline
+}
+
+func (s *memoryStorage) SaveKline(kline *models.Kline) error {
+	s.klines = append(s.klines, *kline)
+	return nil
+}
+
+func (s *memoryStorage) SaveTicker(ticker *models.Ticker) error  { return nil }
+func (s *memoryStorage) SaveDepth(depth *models.OrderBook) error { return nil }
+func (s *memoryStorage) SaveTrade(trade *models.Trade) error     { return nil }
+
+// saved 按周期筛选保存的K线
+func (s *memoryStorage) saved(interval string) []models.Kline {
+	var klines []models.Kline
+	for _, k := range s.klines {
+		if k.Interval == interval {
+			klines = append(klines, k)
+		}
+	}
+	return klines
+}
+
+// memoryPublisher 记录推送的K线更新
+type memoryPublisher struct {
+	updates []models.KlineUpdate
+}
+
+func (p *memoryPublisher) PublishKline(update *models.KlineUpdate) error {
+	p.updates = append(p.updates, *update)
+	return nil
+}
+
+// rollupStart 测试起始时间，按小时对齐（本地时区）
+var rollupStart = utils.GetKlineOpenTime(1700000000000, constants.Interval1h)
+
+func minute(n int, second int) int64 {
+	return rollupStart + int64(n)*time.Minute.Milliseconds() + int64(second)*time.Second.Milliseconds()
+}
+
+// aggregateTrades 直接由成交聚合K线，作为合成结果的对照
+// 没有成交的周期沿用上一根的收盘价
+func aggregateTrades(trades []*models.Trade, interval string, openTime int64, prevClose float64) models.Kline {
+	k := models.Kline{
+		Symbol:    "BTCUSDT",
+		Interval:  interval,
+		OpenTime:  openTime,
+		CloseTime: utils.GetKlineCloseTime(openTime, interval),
+		Open:      prevClose,
+		High:      prevClose,
+		Low:       prevClose,
+		Close:     prevClose,
+		Source:    constants.KlineSourceLocal,
+	}
+	for _, t := range trades {
+		if t.Timestamp < k.OpenTime || t.Timestamp > k.CloseTime {
+			continue
+		}
+		if k.TradeNum == 0 {
+			k.Open, k.High, k.Low = t.Price, t.Price, t.Price
+		}
+		k.High = math.Max(k.High, t.Price)
+		k.Low = math.Min(k.Low, t.Price)
+		k.Close = t.Price
+		k.Volume += t.Amount
+		k.QuoteVol += t.Price * t.Amount
+		k.TradeNum++
+	}
+	return k
+}
+
+func TestRollupMatchesTradeAggregation(t *testing.T) {
+	// 第二个 5m 周期没有成交，第三个 5m 周期前两分钟没有成交
+	trades := []*models.Trade{
+		{Symbol: "BTCUSDT", Price: 100, Amount: 1, Timestamp: minute(0, 5)},
+		{Symbol: "BTCUSDT", Price: 103, Amount: 2, Timestamp: minute(0, 40)},
+		{Symbol: "BTCUSDT", Price: 98, Amount: 0.5, Timestamp: minute(2, 10)},
+		{Symbol: "BTCUSDT", Price: 101, Amount: 1.5, Timestamp: minute(4, 59)},
+		{Symbol: "BTCUSDT", Price: 105, Amount: 1, Timestamp: minute(12, 0)},
+		{Symbol: "BTCUSDT", Price: 99, Amount: 3, Timestamp: minute(13, 30)},
+		{Symbol: "BTCUSDT", Price: 102, Amount: 1, Timestamp: minute(14, 1)},
+	}
+
+	storage := &memoryStorage{}
+	h := NewKlineHandler(storage, nil, config.KlineConfig{})
+	for _, trade := range trades {
+		if err := h.HandleTrade(trade); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 成交只更新 1m 聚合器，5m K线只随收盘的 1m K线推进（最后一根 1m K线尚未收盘）
+	if k := h.aggregator("BTCUSDT", constants.Interval5m).GetCurrentKline(); k == nil || k.OpenTime != minute(10, 0) {
+		t.Fatalf("5m kline should only advance on closed 1m klines, got %+v", k)
+	}
+
+	h.closeDue(minute(15, 0))
+
+	tests := []struct {
+		interval string
+		periods  int
+	}{
+		{constants.Interval5m, 3},
+		{constants.Interval15m, 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.interval, func(t *testing.T) {
+			got := storage.saved(tc.interval)
+			if len(got) != tc.periods {
+				t.Fatalf("saved %d %s klines, want %d", len(got), tc.interval, tc.periods)
+			}
+
+			prevClose := trades[0].Price
+			for i, k := range got {
+				want := aggregateTrades(trades, tc.interval, got[0].OpenTime+int64(i)*(got[0].CloseTime-got[0].OpenTime+1), prevClose)
+				if !klinesEqual(k, want) {
+					t.Errorf("period %d:\ngot  %+v\nwant %+v", i, k, want)
+				}
+				prevClose = want.Close
+			}
+		})
+	}
+}
+
+func TestRollupClosesWithLastMinute(t *testing.T) {
+	storage := &memoryStorage{}
+	h := NewKlineHandler(storage, nil, config.KlineConfig{})
+	h.HandleTrade(&models.Trade{Symbol: "BTCUSDT", Price: 100, Amount: 1, Timestamp: minute(3, 0)})
+
+	// 第 4 分钟收盘前 5m K线保持未收盘
+	h.closeDue(minute(4, 30))
+	if got := storage.saved(constants.Interval5m); len(got) != 0 {
+		t.Fatalf("5m kline closed before its last minute: %+v", got)
+	}
+
+	h.closeDue(minute(5, 0))
+	got := storage.saved(constants.Interval5m)
+	if len(got) != 1 || got[0].OpenTime != minute(0, 0) || got[0].Volume != 1 {
+		t.Fatalf("unexpected 5m klines: %+v", got)
+	}
+}
+
+func TestRollupLivePreview(t *testing.T) {
+	publisher := &memoryPublisher{}
+	h := NewKlineHandler(&memoryStorage{}, nil, config.KlineConfig{})
+	h.SetPublisher(publisher)
+
+	h.HandleTrade(&models.Trade{Symbol: "BTCUSDT", Price: 100, Amount: 1, Timestamp: minute(0, 10)})
+	h.closeDue(minute(1, 0))
+
+	// 绕过实时推送间隔
+	base := h.aggregator("BTCUSDT", constants.Interval1m)
+	base.lastPush = 0
+	publisher.updates = nil
+	h.HandleTrade(&models.Trade{Symbol: "BTCUSDT", Price: 110, Amount: 2, Timestamp: minute(1, 20)})
+
+	var preview *models.KlineUpdate
+	for i := range publisher.updates {
+		if publisher.updates[i].Interval == constants.Interval5m {
+			preview = &publisher.updates[i]
+		}
+	}
+	if preview == nil {
+		t.Fatal("no live 5m update published")
+	}
+	if preview.Closed || preview.Open != 100 || preview.High != 110 || preview.Close != 110 ||
+		preview.Volume != 3 || preview.TradeNum != 2 {
+		t.Errorf("unexpected 5m preview: %+v", preview.Kline)
+	}
+
+	// 预览不修改 5m 聚合器的状态
+	if k := h.aggregator("BTCUSDT", constants.Interval5m).GetCurrentKline(); k.Volume != 1 || k.Close != 100 {
+		t.Errorf("preview modified 5m kline: %+v", k)
+	}
+}
+
+func klinesEqual(a, b models.Kline) bool {
+	const eps = 1e-9
+	return a.Symbol == b.Symbol && a.Interval == b.Interval &&
+		a.OpenTime == b.OpenTime && a.CloseTime == b.CloseTime &&
+		math.Abs(a.Open-b.Open) < eps && math.Abs(a.High-b.High) < eps &&
+		math.Abs(a.Low-b.Low) < eps && math.Abs(a.Close-b.Close) < eps &&
+		math.Abs(a.Volume-b.Volume) < eps && math.Abs(a.QuoteVol-b.QuoteVol) < eps &&
+		a.TradeNum == b.TradeNum && a.Source == b.Source
+}
@@ -0,0 +1,260 @@
+package index
+
+import (
+	"log"
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Publisher 指数价格发布接口
+type Publisher interface {
+	SaveIndexPrice(price *models.IndexPrice) error
+}
+
+// Calculator 指数价格计算器
+// 记录每个外部交易所推送的最新价，每个发布间隔计算一次：先以全部价格的加权中位数为基准，
+// 剔除偏离超过阈值的交易所，再对剩余价格取加权中位数或加权均值。
+// 单个交易所价格异常或断线时不会带偏指数价格，断线交易所的价格过期后自动不参与计算。
+type Calculator struct {
+	cfg       config.IndexConfig
+	publisher Publisher
+
+	mu      sync.Mutex
+	symbols map[string]map[string]*quote // 交易对 -> 交易所 -> 最新价
+}
+
+// quote 单个交易所的最新价
+type quote struct {
+	price     float64
+	timestamp int64 // 接收时间（毫秒），用于判断是否过期
+}
+
+// NewCalculator 创建指数价格计算器
+func NewCalculator(cfg config.IndexConfig, publisher Publisher) *Calculator {
+	switch cfg.Method {
+	case constants.IndexMethodWeightedMedian, constants.IndexMethodWeightedMean:
+	case "":
+		cfg.Method = constants.IndexMethodWeightedMedian
+	default:
+		log.Printf("[Index] Unknown method %q, using %s\n", cfg.Method, constants.IndexMethodWeightedMedian)
+		cfg.Method = constants.IndexMethodWeightedMedian
+	}
+	if cfg.MaxDeviationPercent == 0 {
+		cfg.MaxDeviationPercent = 2
+	}
+	if cfg.MinSources <= 0 {
+		cfg.MinSources = 1
+	}
+	if cfg.StaleMs <= 0 {
+		cfg.StaleMs = 10000
+	}
+	if cfg.PublishIntervalMs <= 0 {
+		cfg.PublishIntervalMs = 1000
+	}
+
+	return &Calculator{
+		cfg:       cfg,
+		publisher: publisher,
+		symbols:   make(map[string]map[string]*quote),
+	}
+}
+
+// RecordTicker 记录交易所推送的最新价，未配置权重的交易所忽略
+func (c *Calculator) RecordTicker(exchange string, ticker *models.Ticker) {
+	if ticker.LastPrice <= 0 || c.weight(exchange) <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	quotes, ok := c.symbols[ticker.Symbol]
+	if !ok {
+		quotes = make(map[string]*quote)
+		c.symbols[ticker.Symbol] = quotes
+	}
+	quotes[exchange] = &quote{price: ticker.LastPrice, timestamp: utils.GetCurrentTimestamp()}
+}
+
+// Run 按发布间隔计算并发布指数价格，直到 stop 关闭
+func (c *Calculator) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Duration(c.cfg.PublishIntervalMs) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			for _, price := range c.calculate(utils.GetCurrentTimestamp()) {
+				if err := c.publisher.SaveIndexPrice(price); err != nil {
+					log.Printf("[Index] Failed to publish %s: %v\n", price.Symbol, err)
+				}
+			}
+		}
+	}
+}
+
+// RemoveSymbol 停止计算交易对的指数价格，丢弃已记录的价格
+func (c *Calculator) RemoveSymbol(symbol string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.symbols, symbol)
+}
+
+// SymbolCount 正在计算指数价格的交易对数
+func (c *Calculator) SymbolCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.symbols)
+}
+
+// weight 交易所的权重，没有配置权重时所有交易所均为 1
+func (c *Calculator) weight(exchange string) float64 {
+	if len(c.cfg.Weights) == 0 {
+		return 1
+	}
+	return c.cfg.Weights[exchange]
+}
+
+// calculate 丢弃过期的价格，返回各交易对最新的指数价格
+// 剔除异常价格后交易所数不足 MinSources 的交易对本次不发布，Redis 中保留上一次的指数价格
+func (c *Calculator) calculate(now int64) []*models.IndexPrice {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stale := int64(c.cfg.StaleMs)
+	prices := make([]*models.IndexPrice, 0, len(c.symbols))
+	for symbol, quotes := range c.symbols {
+		for exchange, q := range quotes {
+			if now-q.timestamp > stale {
+				delete(quotes, exchange)
+			}
+		}
+		if len(quotes) == 0 {
+			delete(c.symbols, symbol)
+			continue
+		}
+
+		if price := c.compute(symbol, quotes, now); price != nil {
+			prices = append(prices, price)
+		}
+	}
+	return prices
+}
+
+// compute 计算单个交易对的指数价格（调用方需持有锁）
+func (c *Calculator) compute(symbol string, quotes map[string]*quote, now int64) *models.IndexPrice {
+	components := make([]models.IndexComponent, 0, len(quotes))
+	for exchange, q := range quotes {
+		components = append(components, models.IndexComponent{
+			Exchange:  exchange,
+			Price:     q.price,
+			Weight:    c.weight(exchange),
+			Timestamp: q.timestamp,
+		})
+	}
+	sort.Slice(components, func(i, j int) bool {
+		if components[i].Price != components[j].Price {
+			return components[i].Price < components[j].Price
+		}
+		return components[i].Exchange < components[j].Exchange
+	})
+
+	// 以全部价格的加权中位数为基准剔除异常价格，基准价格本身总会保留
+	if c.cfg.MaxDeviationPercent > 0 {
+		base := weightedMedian(components)
+		for i := range components {
+			if math.Abs(components[i].Price-base)/base*100 > c.cfg.MaxDeviationPercent {
+				components[i].Excluded = true
+			}
+		}
+	}
+
+	var total float64
+	var n int
+	for _, comp := range components {
+		if !comp.Excluded {
+			total += comp.Weight
+			n++
+		}
+	}
+	if n < c.cfg.MinSources || total <= 0 {
+		return nil
+	}
+	for i := range components {
+		if components[i].Excluded {
+			components[i].Weight = 0
+		} else {
+			components[i].Weight /= total
+		}
+	}
+
+	price := &models.IndexPrice{
+		Symbol:     symbol,
+		Method:     c.cfg.Method,
+		Components: components,
+		Timestamp:  now,
+	}
+	if c.cfg.Method == constants.IndexMethodWeightedMean {
+		price.Price = weightedMean(components)
+	} else {
+		price.Price = weightedMedian(components)
+	}
+	return price
+}
+
+// weightedMedian 加权中位数（components 已按价格升序），跳过被剔除的价格
+// 累计权重恰好为一半时取与下一个价格的平均值，权重相同时与普通中位数一致
+func weightedMedian(components []models.IndexComponent) float64 {
+	var total float64
+	for _, comp := range components {
+		if !comp.Excluded {
+			total += comp.Weight
+		}
+	}
+	half := total / 2
+	tolerance := total * 1e-9
+
+	var cum float64
+	for i, comp := range components {
+		if comp.Excluded {
+			continue
+		}
+		cum += comp.Weight
+		if cum < half-tolerance {
+			continue
+		}
+		if cum <= half+tolerance {
+			for _, next := range components[i+1:] {
+				if !next.Excluded {
+					return (comp.Price + next.Price) / 2
+				}
+			}
+		}
+		return comp.Price
+	}
+	return 0
+}
+
+// weightedMean 加权均值，跳过被剔除的价格
+func weightedMean(components []models.IndexComponent) float64 {
+	var sum, total float64
+	for _, comp := range components {
+		if comp.Excluded {
+			continue
+		}
+		sum += comp.Price * comp.Weight
+		total += comp.Weight
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
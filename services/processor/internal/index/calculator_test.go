@@ -0,0 +1,92 @@
+package index
+
+import (
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"math"
+	"testing"
+)
+
+func record(c *Calculator, symbol string, prices map[string]float64) {
+	for exchange, price := range prices {
+		c.RecordTicker(exchange, &models.Ticker{Symbol: symbol, LastPrice: price})
+	}
+}
+
+func TestWeightedMedianExcludesOutlier(t *testing.T) {
+	c := NewCalculator(config.IndexConfig{
+		Weights: map[string]float64{"binance": 3, "okx": 1, "bybit": 1, "gate": 1},
+	}, nil)
+	record(c, "BTCUSDT", map[string]float64{
+		"binance": 100,
+		"okx":     101,
+		"bybit":   99,
+		"gate":    150, // 偏离中位数超过 2%，不参与计算
+		"kraken":  100, // 未配置权重
+	})
+
+	prices := c.calculate(utils.GetCurrentTimestamp())
+	if len(prices) != 1 {
+		t.Fatalf("prices = %+v", prices)
+	}
+	price := prices[0]
+	if price.Price != 100 || price.Method != constants.IndexMethodWeightedMedian {
+		t.Errorf("index = %+v", price)
+	}
+	if len(price.Components) != 4 {
+		t.Fatalf("components = %+v", price.Components)
+	}
+	for _, comp := range price.Components {
+		switch comp.Exchange {
+		case "gate":
+			if !comp.Excluded || comp.Weight != 0 {
+				t.Errorf("gate = %+v", comp)
+			}
+		case "binance":
+			if comp.Excluded || comp.Weight != 0.6 {
+				t.Errorf("binance = %+v", comp)
+			}
+		}
+	}
+}
+
+func TestWeightedMean(t *testing.T) {
+	c := NewCalculator(config.IndexConfig{
+		Method:  constants.IndexMethodWeightedMean,
+		Weights: map[string]float64{"binance": 3, "okx": 1},
+	}, nil)
+	record(c, "ETHUSDT", map[string]float64{"binance": 2000, "okx": 2010})
+
+	prices := c.calculate(utils.GetCurrentTimestamp())
+	if len(prices) != 1 || math.Abs(prices[0].Price-2002.5) > 1e-9 {
+		t.Fatalf("prices = %+v", prices)
+	}
+}
+
+func TestEvenMedianAndMinSources(t *testing.T) {
+	c := NewCalculator(config.IndexConfig{MinSources: 3}, nil)
+	record(c, "BTCUSDT", map[string]float64{"binance": 100, "okx": 101})
+	if prices := c.calculate(utils.GetCurrentTimestamp()); len(prices) != 0 {
+		t.Fatalf("expected no index with 2 sources, got %+v", prices)
+	}
+
+	record(c, "BTCUSDT", map[string]float64{"bybit": 102, "gate": 103})
+	prices := c.calculate(utils.GetCurrentTimestamp())
+	if len(prices) != 1 || prices[0].Price != 101.5 {
+		t.Fatalf("prices = %+v", prices)
+	}
+}
+
+func TestStalePricesDropped(t *testing.T) {
+	c := NewCalculator(config.IndexConfig{StaleMs: 1000}, nil)
+	record(c, "BTCUSDT", map[string]float64{"binance": 100})
+
+	if prices := c.calculate(utils.GetCurrentTimestamp() + 2000); len(prices) != 0 {
+		t.Fatalf("expected stale price dropped, got %+v", prices)
+	}
+	if c.SymbolCount() != 0 {
+		t.Errorf("symbols = %d", c.SymbolCount())
+	}
+}
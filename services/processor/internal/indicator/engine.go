@@ -0,0 +1,215 @@
+package indicator
+
+import (
+	"log"
+	"market-system/common/config"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"strconv"
+	"sync"
+)
+
+// Publisher 技术指标发布接口
+type Publisher interface {
+	SaveIndicators(ind *models.Indicators) error
+}
+
+// History 已保存K线的读取接口，首次计算交易对的某个周期时加载最近的收盘价
+type History interface {
+	GetKlines(symbol, interval string, startTime, endTime, limit int64) ([]*models.Kline, error)
+}
+
+// Engine 技术指标计算
+// 作为K线推送目标接收实时K线和收盘K线：收盘K线追加到收盘价序列，实时K线以当前价格作为最后一根临时计算。
+// 每个交易对、周期只保留最近 Bars 个收盘价，首次收到时从存储加载。
+type Engine struct {
+	cfg       config.IndicatorConfig
+	intervals map[string]bool
+	history   History
+	publisher Publisher
+
+	mu     sync.Mutex
+	series map[string]*series // key 为 {symbol}:{interval}
+}
+
+// series 单个交易对、周期的收盘价序列
+type series struct {
+	closes      []float64 // 已收盘K线的收盘价，按时间升序
+	lastOpen    int64     // 最后一根已收盘K线的开盘时间
+	lastPublish int64     // 最近一次推送实时K线指标的时间（毫秒）
+}
+
+// NewEngine 创建技术指标计算
+func NewEngine(cfg config.IndicatorConfig, history History, publisher Publisher) *Engine {
+	if len(cfg.Intervals) == 0 {
+		cfg.Intervals = []string{"1m", "5m", "15m", "1h", "4h", "1d"}
+	}
+	if len(cfg.MA) == 0 {
+		cfg.MA = []int{7, 25, 99}
+	}
+	if len(cfg.RSI) == 0 {
+		cfg.RSI = []int{6, 12, 24}
+	}
+	if len(cfg.MACD) != 3 {
+		cfg.MACD = []int{12, 26, 9}
+	}
+	if cfg.BollPeriod <= 0 {
+		cfg.BollPeriod = 20
+	}
+	if cfg.BollWidth <= 0 {
+		cfg.BollWidth = 2
+	}
+	if cfg.Bars <= 0 {
+		cfg.Bars = 500
+	}
+	if cfg.PublishIntervalMs <= 0 {
+		cfg.PublishIntervalMs = 1000
+	}
+
+	e := &Engine{
+		cfg:       cfg,
+		intervals: make(map[string]bool, len(cfg.Intervals)),
+		history:   history,
+		publisher: publisher,
+		series:    make(map[string]*series),
+	}
+	for _, interval := range cfg.Intervals {
+		if !utils.ValidateInterval(interval) {
+			log.Printf("[Indicator] Invalid interval %q, skipped\n", interval)
+			continue
+		}
+		e.intervals[interval] = true
+	}
+	return e
+}
+
+// PublishKline 接收K线更新，计算并发布技术指标
+func (e *Engine) PublishKline(update *models.KlineUpdate) error {
+	if !e.intervals[update.Interval] || update.Close <= 0 {
+		return nil
+	}
+
+	key := update.Symbol + ":" + update.Interval
+	e.mu.Lock()
+	_, ok := e.series[key]
+	e.mu.Unlock()
+	if !ok {
+		loaded, err := e.load(update.Symbol, update.Interval, update.OpenTime)
+		if err != nil {
+			return err
+		}
+		e.mu.Lock()
+		if _, ok := e.series[key]; !ok {
+			e.series[key] = loaded
+		}
+		e.mu.Unlock()
+	}
+
+	ind := e.update(key, update, utils.GetCurrentTimestamp())
+	if ind == nil {
+		return nil
+	}
+	return e.publisher.SaveIndicators(ind)
+}
+
+// RemoveSymbol 丢弃交易对各周期的收盘价序列
+func (e *Engine) RemoveSymbol(symbol string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for interval := range e.intervals {
+		delete(e.series, symbol+":"+interval)
+	}
+}
+
+// SeriesCount 正在计算指标的交易对、周期数
+func (e *Engine) SeriesCount() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.series)
+}
+
+// load 加载开盘时间早于 before 的最近 Bars 根K线的收盘价
+func (e *Engine) load(symbol, interval string, before int64) (*series, error) {
+	klines, err := e.history.GetKlines(symbol, interval, 0, before-1, int64(e.cfg.Bars))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &series{closes: make([]float64, 0, len(klines)+1)}
+	for i := len(klines) - 1; i >= 0; i-- {
+		s.closes = append(s.closes, klines[i].Close)
+		s.lastOpen = klines[i].OpenTime
+	}
+	return s, nil
+}
+
+// update 更新收盘价序列并计算指标，不需要推送时返回 nil
+// 实时K线按 PublishIntervalMs 限频，收盘K线总是推送；
+// 补齐或改写已收盘的K线时丢弃序列，下次更新时从存储重新加载
+func (e *Engine) update(key string, update *models.KlineUpdate, now int64) *models.Indicators {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s, ok := e.series[key]
+	if !ok {
+		return nil
+	}
+
+	var closes []float64
+	if update.Closed {
+		if update.OpenTime <= s.lastOpen {
+			delete(e.series, key)
+			return nil
+		}
+		s.closes = append(s.closes, update.Close)
+		if n := len(s.closes); n > e.cfg.Bars {
+			s.closes = append(s.closes[:0], s.closes[n-e.cfg.Bars:]...)
+		}
+		s.lastOpen = update.OpenTime
+		closes = s.closes
+	} else {
+		if update.OpenTime <= s.lastOpen || now-s.lastPublish < int64(e.cfg.PublishIntervalMs) {
+			return nil
+		}
+		closes = append(s.closes[:len(s.closes):len(s.closes)], update.Close)
+	}
+	s.lastPublish = now
+
+	ind := e.compute(closes)
+	ind.Symbol = update.Symbol
+	ind.Interval = update.Interval
+	ind.OpenTime = update.OpenTime
+	ind.Closed = update.Closed
+	ind.Close = update.Close
+	ind.Timestamp = now
+	return ind
+}
+
+// compute 按收盘价计算全部指标
+func (e *Engine) compute(closes []float64) *models.Indicators {
+	ind := &models.Indicators{
+		SMA: make(map[string]float64, len(e.cfg.MA)),
+		EMA: make(map[string]float64, len(e.cfg.MA)),
+		RSI: make(map[string]float64, len(e.cfg.RSI)),
+	}
+	for _, period := range e.cfg.MA {
+		if v, ok := SMA(closes, period); ok {
+			ind.SMA[strconv.Itoa(period)] = v
+		}
+		if v, ok := EMA(closes, period); ok {
+			ind.EMA[strconv.Itoa(period)] = v
+		}
+	}
+	for _, period := range e.cfg.RSI {
+		if v, ok := RSI(closes, period); ok {
+			ind.RSI[strconv.Itoa(period)] = v
+		}
+	}
+	if dif, dea, ok := MACD(closes, e.cfg.MACD[0], e.cfg.MACD[1], e.cfg.MACD[2]); ok {
+		ind.MACD = &models.MACD{DIF: dif, DEA: dea, Histogram: dif - dea}
+	}
+	if upper, middle, lower, ok := BOLL(closes, e.cfg.BollPeriod, e.cfg.BollWidth); ok {
+		ind.BOLL = &models.BOLL{Upper: upper, Middle: middle, Lower: lower}
+	}
+	return ind
+}
@@ -0,0 +1,119 @@
+package indicator
+
+import "math"
+
+// 以下计算均基于按时间升序的收盘价，数据不足周期时 ok 为 false
+
+// SMA 最近 period 个收盘价的简单移动平均
+func SMA(closes []float64, period int) (float64, bool) {
+	if period <= 0 || len(closes) < period {
+		return 0, false
+	}
+	var sum float64
+	for _, c := range closes[len(closes)-period:] {
+		sum += c
+	}
+	return sum / float64(period), true
+}
+
+// EMA 指数移动平均，以前 period 个收盘价的 SMA 为初始值递推
+func EMA(closes []float64, period int) (float64, bool) {
+	series := emaSeries(closes, period)
+	if len(series) == 0 {
+		return 0, false
+	}
+	return series[len(series)-1], true
+}
+
+// emaSeries EMA 序列，第 i 个值对应 closes[period-1+i]
+func emaSeries(values []float64, period int) []float64 {
+	if period <= 0 || len(values) < period {
+		return nil
+	}
+	var sum float64
+	for _, v := range values[:period] {
+		sum += v
+	}
+
+	alpha := 2 / float64(period+1)
+	series := make([]float64, 0, len(values)-period+1)
+	ema := sum / float64(period)
+	series = append(series, ema)
+	for _, v := range values[period:] {
+		ema = alpha*v + (1-alpha)*ema
+		series = append(series, ema)
+	}
+	return series
+}
+
+// RSI 相对强弱指标（Wilder 平滑），需要 period+1 个收盘价
+// 区间内没有下跌时为 100，没有任何涨跌时为 50
+func RSI(closes []float64, period int) (float64, bool) {
+	if period <= 0 || len(closes) < period+1 {
+		return 0, false
+	}
+
+	var gain, loss float64
+	for i := 1; i <= period; i++ {
+		if d := closes[i] - closes[i-1]; d > 0 {
+			gain += d
+		} else {
+			loss -= d
+		}
+	}
+	gain /= float64(period)
+	loss /= float64(period)
+
+	for i := period + 1; i < len(closes); i++ {
+		d := closes[i] - closes[i-1]
+		up, down := 0.0, 0.0
+		if d > 0 {
+			up = d
+		} else {
+			down = -d
+		}
+		gain = (gain*float64(period-1) + up) / float64(period)
+		loss = (loss*float64(period-1) + down) / float64(period)
+	}
+
+	if loss == 0 {
+		if gain == 0 {
+			return 50, true
+		}
+		return 100, true
+	}
+	return 100 - 100/(1+gain/loss), true
+}
+
+// MACD 指数平滑异同移动平均线，需要 slow+signal-1 个收盘价
+func MACD(closes []float64, fast, slow, signal int) (dif, dea float64, ok bool) {
+	if fast <= 0 || fast >= slow || signal <= 0 || len(closes) < slow+signal-1 {
+		return 0, 0, false
+	}
+
+	fastSeries := emaSeries(closes, fast)
+	slowSeries := emaSeries(closes, slow)
+	// 两个序列按收盘价对齐：慢线第 i 个值对应快线第 slow-fast+i 个值
+	difs := make([]float64, len(slowSeries))
+	for i, s := range slowSeries {
+		difs[i] = fastSeries[slow-fast+i] - s
+	}
+
+	deas := emaSeries(difs, signal)
+	return difs[len(difs)-1], deas[len(deas)-1], true
+}
+
+// BOLL 布林带：中轨为 period 个收盘价的 SMA，上下轨为中轨加减 width 倍总体标准差
+func BOLL(closes []float64, period int, width float64) (upper, middle, lower float64, ok bool) {
+	middle, ok = SMA(closes, period)
+	if !ok {
+		return 0, 0, 0, false
+	}
+
+	var variance float64
+	for _, c := range closes[len(closes)-period:] {
+		variance += (c - middle) * (c - middle)
+	}
+	std := math.Sqrt(variance / float64(period))
+	return middle + width*std, middle, middle - width*std, true
+}
@@ -0,0 +1,148 @@
+package indicator
+
+import (
+	"market-system/common/config"
+	"market-system/common/models"
+	"math"
+	"testing"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestMovingAverages(t *testing.T) {
+	closes := []float64{1, 2, 3, 4, 5, 6}
+
+	if v, ok := SMA(closes, 3); !ok || v != 5 {
+		t.Errorf("SMA = %v, %v", v, ok)
+	}
+	if _, ok := SMA(closes, 7); ok {
+		t.Error("SMA with insufficient data should not be computed")
+	}
+
+	// 初始值为前 3 个的均值 2，之后 alpha = 0.5：3, 4, 5, 6
+	if v, ok := EMA(closes, 3); !ok || v != 5 {
+		t.Errorf("EMA = %v, %v", v, ok)
+	}
+}
+
+func TestRSI(t *testing.T) {
+	if v, ok := RSI([]float64{1, 2, 3, 4}, 3); !ok || v != 100 {
+		t.Errorf("RSI all gains = %v, %v", v, ok)
+	}
+	if v, ok := RSI([]float64{5, 5, 5, 5}, 3); !ok || v != 50 {
+		t.Errorf("RSI flat = %v, %v", v, ok)
+	}
+
+	// 涨跌幅度相同时为 50
+	if v, ok := RSI([]float64{10, 11, 10, 11, 10}, 4); !ok || !almostEqual(v, 50) {
+		t.Errorf("RSI balanced = %v, %v", v, ok)
+	}
+
+	// Wilder 平滑：初始平均涨幅 2/3、跌幅 1/3，再下跌 1 后涨幅 4/9、跌幅 5/9
+	if v, ok := RSI([]float64{10, 11, 12, 11, 10}, 3); !ok || !almostEqual(v, 100-100/(1+0.8)) {
+		t.Errorf("RSI = %v, %v", v, ok)
+	}
+}
+
+func TestMACDAndBOLL(t *testing.T) {
+	// 收盘价不变时 DIF、DEA 为 0，布林带收敛到中轨
+	flat := make([]float64, 40)
+	for i := range flat {
+		flat[i] = 100
+	}
+	dif, dea, ok := MACD(flat, 12, 26, 9)
+	if !ok || !almostEqual(dif, 0) || !almostEqual(dea, 0) {
+		t.Errorf("MACD flat = %v, %v, %v", dif, dea, ok)
+	}
+	if _, _, ok := MACD(flat[:33], 12, 26, 9); ok {
+		t.Error("MACD with insufficient data should not be computed")
+	}
+
+	// 线性上涨时快线高于慢线
+	rising := make([]float64, 40)
+	for i := range rising {
+		rising[i] = float64(100 + i)
+	}
+	if dif, _, ok := MACD(rising, 12, 26, 9); !ok || dif <= 0 {
+		t.Errorf("MACD rising dif = %v, %v", dif, ok)
+	}
+
+	upper, middle, lower, ok := BOLL([]float64{2, 4, 4, 4, 5, 5, 7, 9}, 8, 2)
+	if !ok || middle != 5 || upper != 9 || lower != 1 {
+		t.Errorf("BOLL = %v, %v, %v, %v", upper, middle, lower, ok)
+	}
+}
+
+type memoryHistory []*models.Kline // 按开盘时间倒序
+
+func (h memoryHistory) GetKlines(symbol, interval string, startTime, endTime, limit int64) ([]*models.Kline, error) {
+	var klines []*models.Kline
+	for _, k := range h {
+		if endTime > 0 && k.OpenTime > endTime {
+			continue
+		}
+		if int64(len(klines)) == limit {
+			break
+		}
+		klines = append(klines, k)
+	}
+	return klines, nil
+}
+
+type memoryPublisher struct {
+	published []*models.Indicators
+}
+
+func (p *memoryPublisher) SaveIndicators(ind *models.Indicators) error {
+	p.published = append(p.published, ind)
+	return nil
+}
+
+func kline(openTime int64, close float64, closed bool) *models.KlineUpdate {
+	return models.NewKlineUpdate(&models.Kline{Symbol: "BTCUSDT", Interval: "1m", OpenTime: openTime, Close: close}, closed)
+}
+
+func TestEngine(t *testing.T) {
+	history := memoryHistory{
+		{OpenTime: 120000, Close: 3},
+		{OpenTime: 60000, Close: 2},
+		{OpenTime: 0, Close: 1},
+	}
+	publisher := &memoryPublisher{}
+	e := NewEngine(config.IndicatorConfig{MA: []int{3}, RSI: []int{2}, PublishIntervalMs: 60000}, history, publisher)
+
+	// 不计算的周期
+	if err := e.PublishKline(models.NewKlineUpdate(&models.Kline{Symbol: "BTCUSDT", Interval: "3m", OpenTime: 180000, Close: 4}, true)); err != nil {
+		t.Fatal(err)
+	}
+	if len(publisher.published) != 0 {
+		t.Fatalf("unexpected publish: %+v", publisher.published)
+	}
+
+	// 实时K线以当前价格作为最后一根计算，限频期间不再推送
+	e.PublishKline(kline(180000, 6, false))
+	e.PublishKline(kline(180000, 7, false))
+	if len(publisher.published) != 1 {
+		t.Fatalf("published = %d", len(publisher.published))
+	}
+	if got := publisher.published[0]; got.Closed || got.SMA["3"] != (2+3+6)/3.0 {
+		t.Errorf("live indicators = %+v", got)
+	}
+
+	// 收盘K线总是推送并追加到序列
+	e.PublishKline(kline(180000, 4, true))
+	if len(publisher.published) != 2 {
+		t.Fatalf("published = %d", len(publisher.published))
+	}
+	if got := publisher.published[1]; !got.Closed || got.SMA["3"] != 3 || got.RSI["2"] != 100 {
+		t.Errorf("closed indicators = %+v", got)
+	}
+
+	// 补齐已收盘的K线时丢弃序列
+	e.PublishKline(kline(60000, 2.5, true))
+	if e.SeriesCount() != 0 {
+		t.Errorf("series = %d", e.SeriesCount())
+	}
+}
@@ -0,0 +1,242 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrQueueFull 队列已满，等待入队期间上下文已取消
+var ErrQueueFull = errors.New("pipeline queue full")
+
+// ErrStopped 调度器已停止
+var ErrStopped = errors.New("pipeline stopped")
+
+// Task 处理任务
+type Task func() error
+
+// Dispatcher 按交易对（或交易对分片）隔离的处理调度器
+// 每个队列有独立的有界缓冲和 worker，单个交易对的异常数据或慢处理不会阻塞其他交易对
+type Dispatcher struct {
+	queueSize int
+	shards    int // 0 表示每个交易对独立队列，>0 表示按哈希分片
+	queues    map[string]*Queue
+	mu        sync.RWMutex
+	wg        sync.WaitGroup
+	sending   sync.WaitGroup // 正在投递的任务，停止时等待其入队或放弃后再关闭队列
+	done      chan struct{}  // 停止时关闭，唤醒等待入队的投递
+	stopped   bool
+}
+
+// Queue 单个处理队列
+type Queue struct {
+	name  string
+	tasks chan Task
+
+	enqueued  int64
+	processed int64
+	blocked   int64 // 队列已满、需要等待才能入队的次数
+	dropped   int64
+	failed    int64
+	panics    int64
+	lastNanos int64 // 最近一次处理耗时
+	maxNanos  int64 // 最大处理耗时
+}
+
+// QueueStats 队列统计信息
+type QueueStats struct {
+	Name        string        `json:"name"`
+	Length      int           `json:"length"`
+	Capacity    int           `json:"capacity"`
+	Enqueued    int64         `json:"enqueued"`
+	Processed   int64         `json:"processed"`
+	Blocked     int64         `json:"blocked"`
+	Dropped     int64         `json:"dropped"`
+	Failed      int64         `json:"failed"`
+	Panics      int64         `json:"panics"`
+	LastLatency time.Duration `json:"last_latency"`
+	MaxLatency  time.Duration `json:"max_latency"`
+}
+
+// NewDispatcher 创建调度器
+func NewDispatcher(queueSize, shards int) *Dispatcher {
+	if queueSize <= 0 {
+		queueSize = 1024
+	}
+	return &Dispatcher{
+		queueSize: queueSize,
+		shards:    shards,
+		queues:    make(map[string]*Queue),
+		done:      make(chan struct{}),
+	}
+}
+
+// Dispatch 将任务投递到交易对所属的队列，队列已满时阻塞等待，使上游（Kafka 消费）同步减速而不丢弃消息
+// 等待期间 ctx 取消时放弃投递并返回 ErrQueueFull，调度器停止时返回 ErrStopped
+func (d *Dispatcher) Dispatch(ctx context.Context, symbol string, task Task) error {
+	queue, err := d.acquire(d.queueName(symbol))
+	if err != nil {
+		return err
+	}
+	defer d.sending.Done()
+
+	select {
+	case queue.tasks <- task:
+		atomic.AddInt64(&queue.enqueued, 1)
+		return nil
+	default:
+	}
+
+	blocked := atomic.AddInt64(&queue.blocked, 1)
+	if blocked%100 == 1 {
+		log.Printf("[Pipeline] Queue %s full, waiting to enqueue (blocked: %d)\n", queue.name, blocked)
+	}
+
+	select {
+	case queue.tasks <- task:
+		atomic.AddInt64(&queue.enqueued, 1)
+		return nil
+	case <-d.done:
+		atomic.AddInt64(&queue.dropped, 1)
+		return ErrStopped
+	case <-ctx.Done():
+		atomic.AddInt64(&queue.dropped, 1)
+		return fmt.Errorf("%w: %s: %v", ErrQueueFull, queue.name, ctx.Err())
+	}
+}
+
+// Stop 停止所有队列，等待正在投递的任务入队或放弃后关闭队列，再等待已入队任务处理完成
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		return
+	}
+	d.stopped = true
+	close(d.done)
+	d.mu.Unlock()
+
+	// 停止后不会再有新的投递，已在投递中的任务入队或因 done 放弃后才能关闭队列
+	d.sending.Wait()
+	for _, queue := range d.queues {
+		close(queue.tasks)
+	}
+
+	d.wg.Wait()
+	log.Println("[Pipeline] All queues stopped")
+}
+
+// Stats 获取所有队列的统计信息
+func (d *Dispatcher) Stats() map[string]QueueStats {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	stats := make(map[string]QueueStats, len(d.queues))
+	for name, queue := range d.queues {
+		stats[name] = QueueStats{
+			Name:        name,
+			Length:      len(queue.tasks),
+			Capacity:    cap(queue.tasks),
+			Enqueued:    atomic.LoadInt64(&queue.enqueued),
+			Processed:   atomic.LoadInt64(&queue.processed),
+			Blocked:     atomic.LoadInt64(&queue.blocked),
+			Dropped:     atomic.LoadInt64(&queue.dropped),
+			Failed:      atomic.LoadInt64(&queue.failed),
+			Panics:      atomic.LoadInt64(&queue.panics),
+			LastLatency: time.Duration(atomic.LoadInt64(&queue.lastNanos)),
+			MaxLatency:  time.Duration(atomic.LoadInt64(&queue.maxNanos)),
+		}
+	}
+	return stats
+}
+
+// queueName 获取交易对对应的队列名称
+func (d *Dispatcher) queueName(symbol string) string {
+	if d.shards <= 0 {
+		return symbol
+	}
+	h := fnv.New32a()
+	h.Write([]byte(symbol))
+	return fmt.Sprintf("shard-%d", h.Sum32()%uint32(d.shards))
+}
+
+// acquire 获取或创建队列，并登记一次正在进行的投递（调用方投递结束后调用 d.sending.Done）
+// 登记与停止检查在同一把锁内完成，Stop 之后不会再登记新的投递
+func (d *Dispatcher) acquire(name string) (*Queue, error) {
+	d.mu.RLock()
+	queue, ok := d.queues[name]
+	if d.stopped {
+		d.mu.RUnlock()
+		return nil, ErrStopped
+	}
+	if ok {
+		d.sending.Add(1)
+		d.mu.RUnlock()
+		return queue, nil
+	}
+	d.mu.RUnlock()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopped {
+		return nil, ErrStopped
+	}
+	if queue, ok := d.queues[name]; ok {
+		d.sending.Add(1)
+		return queue, nil
+	}
+
+	queue = &Queue{
+		name:  name,
+		tasks: make(chan Task, d.queueSize),
+	}
+	d.queues[name] = queue
+
+	d.wg.Add(1)
+	go d.work(queue)
+
+	log.Printf("[Pipeline] Created queue: %s (size: %d)\n", name, d.queueSize)
+	d.sending.Add(1)
+	return queue, nil
+}
+
+// work 队列 worker，顺序处理任务，保证同一交易对内的消息有序
+func (d *Dispatcher) work(queue *Queue) {
+	defer d.wg.Done()
+
+	for task := range queue.tasks {
+		queue.run(task)
+	}
+}
+
+// run 执行单个任务，捕获 panic 避免影响 worker
+func (q *Queue) run(task Task) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			atomic.AddInt64(&q.panics, 1)
+			log.Printf("[Pipeline] Queue %s task panic: %v\n", q.name, r)
+		}
+
+		elapsed := int64(time.Since(start))
+		atomic.StoreInt64(&q.lastNanos, elapsed)
+		for {
+			cur := atomic.LoadInt64(&q.maxNanos)
+			if elapsed <= cur || atomic.CompareAndSwapInt64(&q.maxNanos, cur, elapsed) {
+				break
+			}
+		}
+		atomic.AddInt64(&q.processed, 1)
+	}()
+
+	if err := task(); err != nil {
+		atomic.AddInt64(&q.failed, 1)
+		log.Printf("[Pipeline] Queue %s task failed: %v\n", q.name, err)
+	}
+}
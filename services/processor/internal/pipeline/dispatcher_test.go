@@ -0,0 +1,115 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatchBlocksWhenFull(t *testing.T) {
+	d := NewDispatcher(1, 0)
+	defer d.Stop()
+
+	// 第一个任务占住 worker，第二个任务占满队列
+	release := make(chan struct{})
+	started := make(chan struct{})
+	if err := d.Dispatch(context.Background(), "BTCUSDT", func() error {
+		close(started)
+		<-release
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	var processed int64
+	count := func() error {
+		atomic.AddInt64(&processed, 1)
+		return nil
+	}
+	if err := d.Dispatch(context.Background(), "BTCUSDT", count); err != nil {
+		t.Fatal(err)
+	}
+
+	// 队列已满时等待入队，不丢弃
+	done := make(chan error, 1)
+	go func() { done <- d.Dispatch(context.Background(), "BTCUSDT", count) }()
+	select {
+	case err := <-done:
+		t.Fatalf("dispatch returned while queue full: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("dispatch after drain: %v", err)
+	}
+
+	// 等待期间上下文取消时放弃投递
+	block := make(chan struct{})
+	d.Dispatch(context.Background(), "ETHUSDT", func() error { <-block; return nil })
+	d.Dispatch(context.Background(), "ETHUSDT", count)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := d.Dispatch(ctx, "ETHUSDT", count); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("err = %v, want ErrQueueFull", err)
+	}
+	close(block)
+
+	d.Stop()
+	if n := atomic.LoadInt64(&processed); n != 3 {
+		t.Errorf("processed = %d, want 3", n)
+	}
+	stats := d.Stats()
+	if stats["BTCUSDT"].Blocked != 1 || stats["BTCUSDT"].Dropped != 0 {
+		t.Errorf("BTCUSDT stats = %+v", stats["BTCUSDT"])
+	}
+	if stats["ETHUSDT"].Dropped != 1 {
+		t.Errorf("ETHUSDT stats = %+v", stats["ETHUSDT"])
+	}
+}
+
+func TestStopWhileDispatching(t *testing.T) {
+	d := NewDispatcher(1, 2)
+
+	var (
+		wg        sync.WaitGroup
+		processed int64
+		accepted  int64
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			symbol := []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}[i%3]
+			for j := 0; j < 200; j++ {
+				err := d.Dispatch(context.Background(), symbol, func() error {
+					atomic.AddInt64(&processed, 1)
+					return nil
+				})
+				if errors.Is(err, ErrStopped) {
+					return
+				}
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				atomic.AddInt64(&accepted, 1)
+			}
+		}(i)
+	}
+
+	// 投递过程中停止：不会向已关闭的队列发送（panic），已入队的任务全部处理完成
+	time.Sleep(time.Millisecond)
+	d.Stop()
+	wg.Wait()
+
+	if p, a := atomic.LoadInt64(&processed), atomic.LoadInt64(&accepted); p != a {
+		t.Errorf("processed = %d, accepted = %d", p, a)
+	}
+	if err := d.Dispatch(context.Background(), "BTCUSDT", func() error { return nil }); !errors.Is(err, ErrStopped) {
+		t.Errorf("dispatch after stop: %v", err)
+	}
+}
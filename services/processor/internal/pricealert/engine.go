@@ -0,0 +1,219 @@
+package pricealert
+
+import (
+	"context"
+	"errors"
+	"log"
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/services/processor/internal/webhook"
+	"sync"
+)
+
+// WebhookTarget 价格提醒 Webhook 在推送统计中的名称
+const WebhookTarget = "price_alert"
+
+// Store 价格提醒读写接口
+type Store interface {
+	PriceAlerts() ([]*models.PriceAlert, error)
+	DeletePriceAlert(id string) error
+}
+
+// Publisher 触发通知发布接口
+type Publisher interface {
+	SavePriceAlertEvent(event *models.PriceAlertEvent) error
+}
+
+// Engine 用户价格提醒
+// 提醒由 API 写入 price_alert Hash，变更后通过 price_alert:update 频道通知，这里收到通知后重新加载。
+// 每个保存的 Ticker 按交易对的提醒判断条件：穿越价格以上一个 Ticker 的最新价为起点，
+// 涨跌幅条件由不满足变为满足时触发。触发后推送到 price_alert:{id} 频道，配置了 Webhook 时同时经 webhook 包的推送发送
+// （签名、失败重试并计入推送统计）；非重复的提醒触发后删除。
+type Engine struct {
+	store     Store
+	publisher Publisher
+	webhook   *webhook.Dispatcher
+
+	mu        sync.Mutex
+	bySymbol  map[string]map[string]*rule // 交易对 -> 提醒 ID -> 提醒
+	lastPrice map[string]float64          // 交易对上一个 Ticker 的最新价
+	count     int64                       // 已触发的提醒数
+}
+
+// rule 提醒及其判断状态
+type rule struct {
+	alert *models.PriceAlert
+	met   bool // 涨跌幅条件上次判断时是否满足
+}
+
+// NewEngine 创建价格提醒，Webhook 必须签名，未配置签名密钥时返回错误
+func NewEngine(cfg config.PriceAlertConfig, store Store, publisher Publisher) (*Engine, error) {
+	if cfg.WebhookSecret == "" {
+		return nil, errors.New("price_alert.webhook_secret is required")
+	}
+	if cfg.WebhookWorkers <= 0 {
+		cfg.WebhookWorkers = 4
+	}
+
+	dispatcher := webhook.NewDispatcher(config.WebhookConfig{
+		TimeoutMs:  cfg.WebhookTimeoutMs,
+		MaxRetries: cfg.WebhookMaxRetries,
+		QueueSize:  cfg.WebhookQueueSize,
+	})
+	dispatcher.AddTarget(WebhookTarget, cfg.WebhookSecret, cfg.WebhookWorkers, cfg.WebhookHosts)
+
+	return &Engine{
+		store:     store,
+		publisher: publisher,
+		webhook:   dispatcher,
+		bySymbol:  make(map[string]map[string]*rule),
+		lastPrice: make(map[string]float64),
+	}, nil
+}
+
+// Load 加载全部提醒，未变化的提醒保留判断状态
+func (e *Engine) Load() error {
+	alerts, err := e.store.PriceAlerts()
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	bySymbol := make(map[string]map[string]*rule)
+	for _, alert := range alerts {
+		r := &rule{alert: alert}
+		if old, ok := e.bySymbol[alert.Symbol][alert.ID]; ok && *old.alert == *alert {
+			r.met = old.met
+		}
+		if bySymbol[alert.Symbol] == nil {
+			bySymbol[alert.Symbol] = make(map[string]*rule)
+		}
+		bySymbol[alert.Symbol][alert.ID] = r
+	}
+	e.bySymbol = bySymbol
+	return nil
+}
+
+// Run 发送 Webhook，收到变更通知后重新加载提醒，直到 stop 关闭
+func (e *Engine) Run(updates <-chan string, stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go e.webhook.Run(ctx)
+
+	for {
+		select {
+		case <-stop:
+			return
+		case _, ok := <-updates:
+			if !ok {
+				updates = nil
+				continue
+			}
+			if err := e.Load(); err != nil {
+				log.Printf("[PriceAlert] Failed to reload alerts: %v\n", err)
+			}
+		}
+	}
+}
+
+// RecordTicker 按 Ticker 判断交易对的提醒，发布触发通知
+func (e *Engine) RecordTicker(ticker *models.Ticker) {
+	if ticker.LastPrice <= 0 {
+		return
+	}
+
+	for _, alert := range e.evaluate(ticker) {
+		event := &models.PriceAlertEvent{
+			AlertID:       alert.ID,
+			Symbol:        alert.Symbol,
+			Condition:     alert.Condition,
+			Value:         alert.Value,
+			Price:         ticker.LastPrice,
+			ChangePercent: ticker.PriceChangePercent24h,
+			Timestamp:     ticker.Timestamp,
+		}
+		if err := e.publisher.SavePriceAlertEvent(event); err != nil {
+			log.Printf("[PriceAlert] Failed to publish alert %s: %v\n", alert.ID, err)
+		}
+		if alert.Webhook != "" {
+			e.webhook.SendTo(WebhookTarget, alert.Webhook, constants.DataTypePriceAlert+":"+alert.ID, event)
+		}
+		if !alert.Repeat {
+			if err := e.store.DeletePriceAlert(alert.ID); err != nil {
+				log.Printf("[PriceAlert] Failed to delete triggered alert %s: %v\n", alert.ID, err)
+			}
+		}
+	}
+}
+
+// RemoveSymbol 丢弃交易对的最新价，提醒保留
+func (e *Engine) RemoveSymbol(symbol string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.lastPrice, symbol)
+}
+
+// AlertCount 已加载的提醒数
+func (e *Engine) AlertCount() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	n := 0
+	for _, rules := range e.bySymbol {
+		n += len(rules)
+	}
+	return n
+}
+
+// WebhookStats Webhook 推送统计
+func (e *Engine) WebhookStats() *models.WebhookStats {
+	return e.webhook.Stats()
+}
+
+// Count 已触发的提醒数
+func (e *Engine) Count() int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.count
+}
+
+// evaluate 更新交易对的最新价，返回触发的提醒；非重复的提醒同时从本地移除
+func (e *Engine) evaluate(ticker *models.Ticker) []*models.PriceAlert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	price := ticker.LastPrice
+	prev, hasPrev := e.lastPrice[ticker.Symbol]
+	e.lastPrice[ticker.Symbol] = price
+
+	var triggered []*models.PriceAlert
+	for id, r := range e.bySymbol[ticker.Symbol] {
+		v := r.alert.Value
+		fire := false
+		switch r.alert.Condition {
+		case constants.PriceAlertCrossUp:
+			fire = hasPrev && prev < v && price >= v
+		case constants.PriceAlertCrossDown:
+			fire = hasPrev && prev > v && price <= v
+		case constants.PriceAlertChangeAbove, constants.PriceAlertChangeBelow:
+			met := ticker.PriceChangePercent24h >= v
+			if r.alert.Condition == constants.PriceAlertChangeBelow {
+				met = ticker.PriceChangePercent24h <= v
+			}
+			fire = met && !r.met
+			r.met = met
+		}
+		if !fire {
+			continue
+		}
+
+		triggered = append(triggered, r.alert)
+		if !r.alert.Repeat {
+			delete(e.bySymbol[ticker.Symbol], id)
+		}
+	}
+	e.count += int64(len(triggered))
+	return triggered
+}
@@ -0,0 +1,97 @@
+package pricealert
+
+import (
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"testing"
+)
+
+type memoryStore struct {
+	alerts  []*models.PriceAlert
+	deleted []string
+}
+
+func (s *memoryStore) PriceAlerts() ([]*models.PriceAlert, error) {
+	return s.alerts, nil
+}
+
+func (s *memoryStore) DeletePriceAlert(id string) error {
+	s.deleted = append(s.deleted, id)
+	return nil
+}
+
+type memoryPublisher struct {
+	events []*models.PriceAlertEvent
+}
+
+func (p *memoryPublisher) SavePriceAlertEvent(event *models.PriceAlertEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func ticker(price, change float64) *models.Ticker {
+	return &models.Ticker{Symbol: "BTCUSDT", LastPrice: price, PriceChangePercent24h: change}
+}
+
+func TestPriceAlerts(t *testing.T) {
+	store := &memoryStore{alerts: []*models.PriceAlert{
+		{ID: "up", Symbol: "BTCUSDT", Condition: constants.PriceAlertCrossUp, Value: 100},
+		{ID: "down", Symbol: "BTCUSDT", Condition: constants.PriceAlertCrossDown, Value: 90, Repeat: true},
+		{ID: "change", Symbol: "BTCUSDT", Condition: constants.PriceAlertChangeAbove, Value: 5, Repeat: true},
+	}}
+	publisher := &memoryPublisher{}
+	if _, err := NewEngine(config.PriceAlertConfig{}, store, publisher); err == nil {
+		t.Error("engine created without webhook secret")
+	}
+	e, err := NewEngine(config.PriceAlertConfig{WebhookSecret: "secret"}, store, publisher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 第一个 Ticker 只作为穿越的起点
+	e.RecordTicker(ticker(105, 0))
+	if len(publisher.events) != 0 {
+		t.Fatalf("unexpected events = %+v", publisher.events)
+	}
+
+	e.RecordTicker(ticker(95, 0))
+	e.RecordTicker(ticker(100, 0))
+	if len(publisher.events) != 1 || publisher.events[0].AlertID != "up" || publisher.events[0].Price != 100 {
+		t.Fatalf("events = %+v", publisher.events)
+	}
+	// 非重复的提醒触发后删除
+	if len(store.deleted) != 1 || store.deleted[0] != "up" || e.AlertCount() != 2 {
+		t.Errorf("deleted = %v, alerts = %d", store.deleted, e.AlertCount())
+	}
+
+	// 重复的提醒每次穿越都触发
+	e.RecordTicker(ticker(89, 0))
+	e.RecordTicker(ticker(91, 0))
+	e.RecordTicker(ticker(90, 0))
+	if len(publisher.events) != 3 {
+		t.Fatalf("events = %d", len(publisher.events))
+	}
+
+	// 涨跌幅条件由不满足变为满足时触发，重新加载后保留状态
+	e.RecordTicker(ticker(95, 6))
+	if len(publisher.events) != 4 || publisher.events[3].AlertID != "change" {
+		t.Fatalf("events = %+v", publisher.events)
+	}
+	store.alerts = store.alerts[1:]
+	if err := e.Load(); err != nil {
+		t.Fatal(err)
+	}
+	e.RecordTicker(ticker(95, 7))
+	if len(publisher.events) != 4 {
+		t.Fatalf("events while still met = %d", len(publisher.events))
+	}
+	e.RecordTicker(ticker(95, 4))
+	e.RecordTicker(ticker(95, 5))
+	if len(publisher.events) != 5 || e.Count() != 5 {
+		t.Errorf("events = %d, count = %d", len(publisher.events), e.Count())
+	}
+}
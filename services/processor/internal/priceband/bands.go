@@ -0,0 +1,221 @@
+package priceband
+
+import (
+	"log"
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"sync"
+	"time"
+)
+
+// Store 交易对注册表读取接口
+type Store interface {
+	InternalOnlySymbols() (map[string]bool, error)
+}
+
+// Publisher 价格带发布接口
+type Publisher interface {
+	SavePriceBand(band *models.PriceBand) error
+}
+
+// Bands 内部市场的动态价格带（涨跌停）
+// 交易对的第一笔成交作为初始参考价格，之后每个参考周期将参考价格更新为最新成交价，价格带随之移动。
+// 成交价触及上限或下限时进入涨停 / 跌停状态，状态变化和参考价格更新都推送到价格带频道，
+// 撮合引擎和前端据此展示涨跌停状态。这里只产生信号，不拦截成交。
+type Bands struct {
+	cfg       config.PriceBandConfig
+	store     Store
+	publisher Publisher
+
+	mu      sync.Mutex
+	include map[string]bool // 计算的交易对
+	symbols map[string]*band
+}
+
+// band 单个交易对的价格带状态
+type band struct {
+	reference float64
+	lastPrice float64
+	lastTrade int64 // 最近成交时间（毫秒）
+	state     string
+}
+
+// NewBands 创建价格带计算
+func NewBands(cfg config.PriceBandConfig, store Store, publisher Publisher) *Bands {
+	if cfg.Percent <= 0 {
+		cfg.Percent = 10
+	}
+	if cfg.ReferenceIntervalSec <= 0 {
+		cfg.ReferenceIntervalSec = 300
+	}
+	return &Bands{
+		cfg:       cfg,
+		store:     store,
+		publisher: publisher,
+		include:   make(map[string]bool),
+		symbols:   make(map[string]*band),
+	}
+}
+
+// Load 加载需要计算的交易对：INTERNAL_ONLY 交易对和配置中额外指定的交易对
+// 不再需要计算的交易对丢弃已有状态
+func (b *Bands) Load() error {
+	include, err := b.store.InternalOnlySymbols()
+	if err != nil {
+		return err
+	}
+	for _, symbol := range b.cfg.Symbols {
+		include[symbol] = true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.include = include
+	for symbol := range b.symbols {
+		if !include[symbol] {
+			delete(b.symbols, symbol)
+		}
+	}
+	return nil
+}
+
+// RecordTrade 记录成交，价格带状态变化时推送事件；迟到的成交不参与计算
+func (b *Bands) RecordTrade(trade *models.Trade) {
+	if event := b.record(trade); event != nil {
+		b.publish(event)
+	}
+}
+
+// record 更新交易对的最新成交价，返回需要推送的事件，没有变化时返回 nil
+func (b *Bands) record(trade *models.Trade) *models.PriceBand {
+	if trade.Price <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.include[trade.Symbol] {
+		return nil
+	}
+
+	s, ok := b.symbols[trade.Symbol]
+	if !ok {
+		s = &band{reference: trade.Price, state: constants.BandStateNormal}
+		b.symbols[trade.Symbol] = s
+		s.lastPrice, s.lastTrade = trade.Price, trade.Timestamp
+		return b.event(trade.Symbol, s, constants.BandEventUpdate, trade.Timestamp)
+	}
+	if trade.Timestamp < s.lastTrade {
+		return nil
+	}
+
+	s.lastPrice, s.lastTrade = trade.Price, trade.Timestamp
+	state := b.state(s)
+	if state == s.state {
+		return nil
+	}
+	s.state = state
+	return b.event(trade.Symbol, s, state, trade.Timestamp)
+}
+
+// Run 按参考周期更新参考价格并推送价格带，收到注册表变更通知后重新加载交易对，直到 stop 关闭
+func (b *Bands) Run(updates <-chan string, stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Duration(b.cfg.ReferenceIntervalSec) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case _, ok := <-updates:
+			if !ok {
+				updates = nil
+				continue
+			}
+			if err := b.Load(); err != nil {
+				log.Printf("[PriceBand] Failed to reload symbols: %v\n", err)
+			}
+		case <-ticker.C:
+			for _, event := range b.Rebase(utils.GetCurrentTimestamp()) {
+				b.publish(event)
+			}
+		}
+	}
+}
+
+// Rebase 将各交易对的参考价格更新为最新成交价，返回新的价格带
+func (b *Bands) Rebase(now int64) []*models.PriceBand {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events := make([]*models.PriceBand, 0, len(b.symbols))
+	for symbol, s := range b.symbols {
+		s.reference = s.lastPrice
+		s.state = b.state(s)
+		events = append(events, b.event(symbol, s, constants.BandEventUpdate, now))
+	}
+	return events
+}
+
+// RemoveSymbol 丢弃交易对的价格带，重新收到成交后以新的成交价作为参考价格
+func (b *Bands) RemoveSymbol(symbol string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.symbols, symbol)
+}
+
+// SymbolCount 正在计算价格带的交易对数
+func (b *Bands) SymbolCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.symbols)
+}
+
+// limits 价格带上下限，消除浮点误差，避免恰好等于上下限的成交价被判断为未触及
+func (b *Bands) limits(s *band) (upper, lower float64) {
+	upper = utils.RoundFloat(s.reference*(1+b.cfg.Percent/100), 10)
+	lower = utils.RoundFloat(s.reference*(1-b.cfg.Percent/100), 10)
+	return upper, lower
+}
+
+// state 由最新成交价判断价格带状态，触及上下限即进入涨停 / 跌停
+func (b *Bands) state(s *band) string {
+	upper, lower := b.limits(s)
+	switch {
+	case s.lastPrice >= upper:
+		return constants.BandStateLimitUp
+	case s.lastPrice <= lower:
+		return constants.BandStateLimitDown
+	default:
+		return constants.BandStateNormal
+	}
+}
+
+// event 生成价格带事件（调用方需持有锁）
+func (b *Bands) event(symbol string, s *band, event string, timestamp int64) *models.PriceBand {
+	upper, lower := b.limits(s)
+	return &models.PriceBand{
+		Symbol:    symbol,
+		Event:     event,
+		State:     s.state,
+		Reference: s.reference,
+		Upper:     upper,
+		Lower:     lower,
+		LastPrice: s.lastPrice,
+		Timestamp: timestamp,
+	}
+}
+
+// publish 推送价格带事件
+func (b *Bands) publish(event *models.PriceBand) {
+	if event.Event == constants.BandStateLimitUp || event.Event == constants.BandStateLimitDown {
+		log.Printf("[PriceBand] %s %s at %v (reference %v)\n", event.Symbol, event.Event, event.LastPrice, event.Reference)
+	}
+	if err := b.publisher.SavePriceBand(event); err != nil {
+		log.Printf("[PriceBand] Failed to publish %s: %v\n", event.Symbol, err)
+	}
+}
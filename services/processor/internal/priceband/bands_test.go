@@ -0,0 +1,75 @@
+package priceband
+
+import (
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"testing"
+)
+
+type memoryStore map[string]bool
+
+func (s memoryStore) InternalOnlySymbols() (map[string]bool, error) {
+	symbols := make(map[string]bool, len(s))
+	for k, v := range s {
+		symbols[k] = v
+	}
+	return symbols, nil
+}
+
+type memoryPublisher struct {
+	events []models.PriceBand
+}
+
+func (p *memoryPublisher) SavePriceBand(band *models.PriceBand) error {
+	p.events = append(p.events, *band)
+	return nil
+}
+
+func TestBandBreaches(t *testing.T) {
+	publisher := &memoryPublisher{}
+	b := NewBands(config.PriceBandConfig{Percent: 10}, memoryStore{"ABCUSDT": true}, publisher)
+	if err := b.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	trades := []*models.Trade{
+		{Symbol: "ABCUSDT", Price: 100, Timestamp: 1000},
+		{Symbol: "ABCUSDT", Price: 105, Timestamp: 2000},
+		{Symbol: "ABCUSDT", Price: 110, Timestamp: 3000},
+		{Symbol: "ABCUSDT", Price: 112, Timestamp: 4000},
+		// 迟到的成交不参与计算
+		{Symbol: "ABCUSDT", Price: 80, Timestamp: 3500},
+		{Symbol: "ABCUSDT", Price: 104, Timestamp: 5000},
+		{Symbol: "ABCUSDT", Price: 90, Timestamp: 6000},
+		// 不计算的交易对
+		{Symbol: "BTCUSDT", Price: 50000, Timestamp: 1000},
+	}
+	for _, trade := range trades {
+		b.RecordTrade(trade)
+	}
+
+	want := []string{constants.BandEventUpdate, constants.BandStateLimitUp, constants.BandStateNormal, constants.BandStateLimitDown}
+	if len(publisher.events) != len(want) {
+		t.Fatalf("events = %+v, want %v", publisher.events, want)
+	}
+	for i, event := range publisher.events {
+		if event.Event != want[i] || event.Reference != 100 {
+			t.Errorf("event %d = %+v, want %s", i, event, want[i])
+		}
+	}
+	if first := publisher.events[0]; first.Upper != 110 || first.Lower != 90 {
+		t.Errorf("unexpected band: %+v", first)
+	}
+
+	// 参考价格更新为最新成交价，价格带随之移动
+	bands := b.Rebase(7000)
+	if len(bands) != 1 || bands[0].Reference != 90 || bands[0].State != constants.BandStateNormal {
+		t.Fatalf("unexpected rebase: %+v", bands)
+	}
+	publisher.events = nil
+	b.RecordTrade(&models.Trade{Symbol: "ABCUSDT", Price: 99, Timestamp: 8000})
+	if len(publisher.events) != 1 || publisher.events[0].State != constants.BandStateLimitUp {
+		t.Errorf("unexpected events after rebase: %+v", publisher.events)
+	}
+}
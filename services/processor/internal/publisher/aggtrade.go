@@ -0,0 +1,66 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"market-system/common/version"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// AggTradePublisher 将聚合成交发布到 agg_trade topic
+// 消息为 MarketData，Type 为 agg_trade，Source / Exchange 与合并的成交相同，Data 为 AggTrade
+type AggTradePublisher struct {
+	writer *kafka.Writer
+}
+
+// NewAggTradePublisher 创建聚合成交发布者
+func NewAggTradePublisher(brokers []string) *AggTradePublisher {
+	log.Printf("[Kafka] Initialized writer for topic: %s\n", constants.TopicMarketAggTrade)
+	return &AggTradePublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        constants.TopicMarketAggTrade,
+			Balancer:     &kafka.Hash{}, // 同一交易对的聚合成交按顺序写入同一分区
+			BatchSize:    100,
+			BatchTimeout: 10, // 10ms
+			Async:        true,
+			RequiredAcks: kafka.RequireOne,
+		},
+	}
+}
+
+// SaveAggTrade 发布聚合成交
+func (p *AggTradePublisher) SaveAggTrade(agg *models.AggTrade) error {
+	value, err := utils.ToJSONBytes(&models.MarketData{
+		Exchange:  agg.Exchange,
+		Symbol:    agg.Symbol,
+		Type:      constants.DataTypeAggTrade,
+		Source:    agg.Source,
+		Timestamp: utils.GetCurrentTimestamp(),
+		Data:      agg,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal agg trade: %w", err)
+	}
+
+	// 异步写入
+	msg := kafka.Message{
+		Key:     []byte(agg.Symbol),
+		Value:   value,
+		Headers: version.Headers(constants.ServiceProcessor),
+	}
+	if err := p.writer.WriteMessages(context.Background(), msg); err != nil {
+		return fmt.Errorf("failed to write agg trade: %w", err)
+	}
+	return nil
+}
+
+// Close 关闭 Writer
+func (p *AggTradePublisher) Close() error {
+	return p.writer.Close()
+}
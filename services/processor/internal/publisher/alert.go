@@ -0,0 +1,66 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"market-system/common/version"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// AlertPublisher 将异常检测告警发布到 alert topic
+// 消息为 MarketData，Type 为 alerts，Source / Exchange 与触发告警的成交相同，Data 为 Alert
+type AlertPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewAlertPublisher 创建告警发布者
+func NewAlertPublisher(brokers []string) *AlertPublisher {
+	log.Printf("[Kafka] Initialized writer for topic: %s\n", constants.TopicMarketAlert)
+	return &AlertPublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        constants.TopicMarketAlert,
+			Balancer:     &kafka.Hash{}, // 同一交易对的告警按顺序写入同一分区
+			BatchSize:    100,
+			BatchTimeout: 10, // 10ms
+			Async:        true,
+			RequiredAcks: kafka.RequireOne,
+		},
+	}
+}
+
+// SaveAlert 发布告警
+func (p *AlertPublisher) SaveAlert(alert *models.Alert) error {
+	value, err := utils.ToJSONBytes(&models.MarketData{
+		Exchange:  alert.Exchange,
+		Symbol:    alert.Symbol,
+		Type:      constants.DataTypeAlerts,
+		Source:    alert.Source,
+		Timestamp: utils.GetCurrentTimestamp(),
+		Data:      alert,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	// 异步写入
+	msg := kafka.Message{
+		Key:     []byte(alert.Symbol),
+		Value:   value,
+		Headers: version.Headers(constants.ServiceProcessor),
+	}
+	if err := p.writer.WriteMessages(context.Background(), msg); err != nil {
+		return fmt.Errorf("failed to write alert: %w", err)
+	}
+	return nil
+}
+
+// Close 关闭 Writer
+func (p *AlertPublisher) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,71 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"market-system/common/version"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KlinePublisher 将本地聚合的K线更新（实时K线与收盘K线）发布到 kline topic
+// 消息为 MarketData，Source 为 local，Data 为 KlineUpdate（closed 标记是否收盘），
+// 与交易所推送的K线共用 topic，消费方按 Source 区分
+type KlinePublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKlinePublisher 创建K线发布者
+func NewKlinePublisher(brokers []string) *KlinePublisher {
+	log.Printf("[Kafka] Initialized writer for topic: %s\n", constants.TopicMarketKline)
+	return &KlinePublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        constants.TopicMarketKline,
+			Balancer:     &kafka.Hash{}, // 同一交易对的K线按顺序写入同一分区
+			BatchSize:    100,
+			BatchTimeout: 10, // 10ms
+			Async:        true,
+			RequiredAcks: kafka.RequireOne,
+		},
+	}
+}
+
+// PublishKline 发布K线更新
+func (p *KlinePublisher) PublishKline(update *models.KlineUpdate) error {
+	value, err := utils.ToJSONBytes(&models.MarketData{
+		Symbol:    update.Symbol,
+		Type:      constants.DataTypeKline,
+		Source:    constants.KlineSourceLocal,
+		Timestamp: utils.GetCurrentTimestamp(),
+		Data:      update,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal kline: %w", err)
+	}
+
+	// 异步写入
+	msg := kafka.Message{
+		Key:     []byte(update.Symbol),
+		Value:   value,
+		Headers: version.Headers(constants.ServiceProcessor),
+	}
+	if err := p.writer.WriteMessages(context.Background(), msg); err != nil {
+		return fmt.Errorf("failed to write kline: %w", err)
+	}
+	return nil
+}
+
+// Stats 获取写入统计
+func (p *KlinePublisher) Stats() kafka.WriterStats {
+	return p.writer.Stats()
+}
+
+// Close 关闭 Writer
+func (p *KlinePublisher) Close() error {
+	return p.writer.Close()
+}
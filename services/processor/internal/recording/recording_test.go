@@ -0,0 +1,168 @@
+package recording
+
+import (
+	"context"
+	"encoding/json"
+	"market-system/common/config"
+	"market-system/common/models"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type memorySink struct {
+	sent []*Record
+}
+
+func (s *memorySink) Send(ctx context.Context, rec *Record) error {
+	s.sent = append(s.sent, rec)
+	return nil
+}
+
+func (s *memorySink) Close() error { return nil }
+
+func record(topic, symbol string, t int64) *Record {
+	return &Record{Topic: topic, Key: []byte(symbol), Value: []byte(`{"symbol":"` + symbol + `"}`), Time: t}
+}
+
+func TestWriterSegments(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(config.RecorderConfig{Dir: dir, SegmentMaxMinutes: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 每分钟切换一个分段
+	for _, ts := range []int64{1000, 30000, 61000, 90000, 125000} {
+		if err := w.Write(record("market.trade", "BTCUSDT", ts)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// 不完整的最后一行被忽略
+	f, _ := os.OpenFile(filepath.Join(dir, "0000000125000.rec"), os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString(`{"topic":"market.tr`)
+	f.Close()
+
+	segments, err := ListSegments(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var starts []int64
+	for _, s := range segments {
+		starts = append(starts, s.Start)
+	}
+	if !reflect.DeepEqual(starts, []int64{1000, 61000, 125000}) {
+		t.Fatalf("segments = %v", starts)
+	}
+
+	var selected []int64
+	for _, s := range SelectSegments(segments, 70000, 100000) {
+		selected = append(selected, s.Start)
+	}
+	if !reflect.DeepEqual(selected, []int64{61000}) {
+		t.Errorf("selected = %v", selected)
+	}
+
+	var times []int64
+	for _, s := range segments {
+		if err := ReadSegment(s.Path, func(rec *Record) error {
+			times = append(times, rec.Time)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(times, []int64{1000, 30000, 61000, 90000, 125000}) {
+		t.Errorf("times = %v", times)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	dir := t.TempDir()
+	w, _ := NewWriter(config.RecorderConfig{Dir: dir})
+	for _, rec := range []*Record{
+		record("market.trade", "BTCUSDT", 1000),
+		record("market.trade", "BTCUSDT", 2000),
+		record("market.ticker", "BTCUSDT", 3000),
+		record("market.trade", "ETHUSDT", 4000),
+		record("market.trade", "BTCUSDT", 3500), // 分区间乱序
+		record("market.trade", "BTCUSDT", 6000),
+		record("market.trade", "BTCUSDT", 9000),
+	} {
+		w.Write(rec)
+	}
+	w.Close()
+
+	sink := &memorySink{}
+	replayer, err := NewReplayer(dir, 10, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := time.Unix(0, 0)
+	var waits []time.Duration
+	replayer.now = func() time.Time { return clock }
+	replayer.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		clock = clock.Add(d)
+		return nil
+	}
+
+	stats, err := replayer.Replay(context.Background(), 2000, 6000, Filter{
+		Topics:  map[string]bool{"market.trade": true},
+		Symbols: map[string]bool{"BTCUSDT": true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var times []int64
+	for _, rec := range sink.sent {
+		times = append(times, rec.Time)
+	}
+	if !reflect.DeepEqual(times, []int64{2000, 3500, 6000}) {
+		t.Errorf("replayed = %v", times)
+	}
+	// 10 倍速：相对第一条消息 1.5 秒、4 秒，分别在 150ms、400ms 发送
+	if want := []time.Duration{150 * time.Millisecond, 250 * time.Millisecond}; !reflect.DeepEqual(waits, want) {
+		t.Errorf("waits = %v, want %v", waits, want)
+	}
+	if stats.Replayed != 3 || stats.First != 2000 || stats.Last != 6000 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	if _, err := NewReplayer(dir, 200, sink); err == nil {
+		t.Error("expected error for speed 200")
+	}
+}
+
+func TestRedisMessage(t *testing.T) {
+	kline := &models.Kline{Symbol: "BTCUSDT", Interval: "1m", OpenTime: 60000, Close: 100, IsFinal: true}
+	value, _ := json.Marshal(models.MarketData{Symbol: "BTCUSDT", Type: "kline", Data: kline})
+	channel, payload, err := redisMessage(&Record{Value: value})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var update models.KlineUpdate
+	json.Unmarshal(payload, &update)
+	if channel != "market:kline:BTCUSDT:1m" || !update.Closed || update.Close != 100 {
+		t.Errorf("kline: channel = %s, update = %+v", channel, update)
+	}
+
+	value, _ = json.Marshal(models.MarketData{Symbol: "ETHUSDT", Type: "trade", Data: &models.Trade{Symbol: "ETHUSDT", Price: 3000}})
+	channel, payload, err = redisMessage(&Record{Value: value})
+	if err != nil || channel != "market:trade:ETHUSDT" {
+		t.Fatalf("trade: channel = %s, err = %v", channel, err)
+	}
+	var trade models.Trade
+	if json.Unmarshal(payload, &trade); trade.Price != 3000 {
+		t.Errorf("trade payload = %s", payload)
+	}
+
+	if _, _, err := redisMessage(&Record{Value: []byte("not json")}); err == nil {
+		t.Error("expected error for invalid message")
+	}
+}
@@ -0,0 +1,298 @@
+// Package recording 将 Kafka 消息录制到本地分段文件，并按时间范围回放
+//
+// 分段文件每行一条 JSON 编码的 Record，按接收顺序追加；文件名为段内第一条消息的时间（毫秒），
+// 因此按文件名排序即为时间顺序，回放时只需读取与时间范围相交的分段。
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"market-system/common/config"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// segmentExt 分段文件扩展名
+const segmentExt = ".rec"
+
+// maxRecordSize 单条记录的最大长度（深度快照可能较大）
+const maxRecordSize = 16 << 20
+
+// 默认配置
+const (
+	defaultDir               = "data/recording"
+	defaultSegmentMaxMB      = 256
+	defaultSegmentMaxMinutes = 60
+)
+
+// Record 录制的 Kafka 消息，Key、Value 原样保存（JSON 中为 base64）
+type Record struct {
+	Topic     string   `json:"topic"`
+	Partition int      `json:"partition"`
+	Offset    int64    `json:"offset"`
+	Time      int64    `json:"time"` // Kafka 消息时间（毫秒），回放按该时间控制节奏
+	Key       []byte   `json:"key,omitempty"`
+	Value     []byte   `json:"value"`
+	Headers   []Header `json:"headers,omitempty"`
+}
+
+// Header Kafka 消息头
+type Header struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// FromMessage 由 Kafka 消息创建记录
+func FromMessage(msg kafka.Message) *Record {
+	rec := &Record{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Time:      msg.Time.UnixMilli(),
+		Key:       msg.Key,
+		Value:     msg.Value,
+	}
+	for _, h := range msg.Headers {
+		rec.Headers = append(rec.Headers, Header{Key: h.Key, Value: h.Value})
+	}
+	return rec
+}
+
+// Message 转换为写入 topic 的 Kafka 消息（不含分区和位置）
+func (r *Record) Message(topic string) kafka.Message {
+	msg := kafka.Message{Topic: topic, Key: r.Key, Value: r.Value}
+	for _, h := range r.Headers {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: h.Key, Value: h.Value})
+	}
+	return msg
+}
+
+// Segment 分段文件
+type Segment struct {
+	Path  string
+	Start int64 // 段内第一条消息的时间（毫秒）
+}
+
+// ListSegments 列出目录中的分段，按开始时间排序
+func ListSegments(dir string) ([]Segment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []Segment
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		start, err := strconv.ParseInt(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, Segment{Path: filepath.Join(dir, name), Start: start})
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].Start < segments[j].Start })
+	return segments, nil
+}
+
+// SelectSegments 选出可能包含 [from, to] 内消息的分段
+// 分段的时间范围为其开始时间到下一个分段的开始时间
+func SelectSegments(segments []Segment, from, to int64) []Segment {
+	var selected []Segment
+	for i, s := range segments {
+		if s.Start > to {
+			break
+		}
+		if i+1 < len(segments) && segments[i+1].Start < from {
+			continue
+		}
+		selected = append(selected, s)
+	}
+	return selected
+}
+
+// ReadSegment 按顺序读取分段中的记录，fn 返回错误时停止
+// 最后一行不完整（录制进程异常退出）时忽略
+func ReadSegment(path string, fn func(*Record) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordSize)
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		if err := fn(&rec); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return nil
+}
+
+// WriterStats 录制统计
+type WriterStats struct {
+	Records  int64 // 写入的记录数
+	Bytes    int64 // 写入的字节数
+	Segments int64 // 创建的分段数
+	Removed  int64 // 超过保留时间删除的分段数
+}
+
+// Writer 分段写入，达到大小或时间跨度上限时切换到新的分段
+// 写入先进入缓冲区，Flush 后才保证落盘；非并发安全
+type Writer struct {
+	dir       string
+	maxBytes  int64
+	maxAge    int64 // 毫秒
+	retention time.Duration
+
+	file  *os.File
+	buf   *bufio.Writer
+	start int64
+	size  int64
+
+	stats WriterStats
+}
+
+// NewWriter 创建分段写入
+func NewWriter(cfg config.RecorderConfig) (*Writer, error) {
+	if cfg.Dir == "" {
+		cfg.Dir = defaultDir
+	}
+	if cfg.SegmentMaxMB <= 0 {
+		cfg.SegmentMaxMB = defaultSegmentMaxMB
+	}
+	if cfg.SegmentMaxMinutes <= 0 {
+		cfg.SegmentMaxMinutes = defaultSegmentMaxMinutes
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create recording dir: %w", err)
+	}
+
+	return &Writer{
+		dir:       cfg.Dir,
+		maxBytes:  int64(cfg.SegmentMaxMB) << 20,
+		maxAge:    int64(cfg.SegmentMaxMinutes) * time.Minute.Milliseconds(),
+		retention: time.Duration(cfg.RetentionHours) * time.Hour,
+	}, nil
+}
+
+// Dir 分段文件目录
+func (w *Writer) Dir() string {
+	return w.dir
+}
+
+// Write 写入记录
+func (w *Writer) Write(rec *Record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if w.file != nil && (w.size+int64(len(line)) > w.maxBytes || rec.Time-w.start >= w.maxAge) {
+		if err := w.closeSegment(); err != nil {
+			return err
+		}
+		w.removeExpired()
+	}
+	if w.file == nil {
+		if err := w.openSegment(rec.Time); err != nil {
+			return err
+		}
+	}
+
+	if _, err := w.buf.Write(line); err != nil {
+		return err
+	}
+	w.size += int64(len(line))
+	w.stats.Records++
+	w.stats.Bytes += int64(len(line))
+	return nil
+}
+
+// Flush 将缓冲区写入磁盘
+func (w *Writer) Flush() error {
+	if w.file == nil {
+		return nil
+	}
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	return w.file.Sync()
+}
+
+// Stats 获取录制统计
+func (w *Writer) Stats() WriterStats {
+	return w.stats
+}
+
+// Close 写出缓冲区并关闭当前分段
+func (w *Writer) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	return w.closeSegment()
+}
+
+// openSegment 以第一条消息的时间创建分段，同名文件已存在时（重启后）追加
+func (w *Writer) openSegment(start int64) error {
+	path := filepath.Join(w.dir, fmt.Sprintf("%013d%s", start, segmentExt))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open segment: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	w.file = f
+	w.buf = bufio.NewWriterSize(f, 256*1024)
+	w.start = start
+	w.size = info.Size()
+	w.stats.Segments++
+	return nil
+}
+
+func (w *Writer) closeSegment() error {
+	err := w.Flush()
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	w.file, w.buf = nil, nil
+	return err
+}
+
+// removeExpired 删除超过保留时间的分段（下一个分段的开始时间已超过保留时间，即段内全部消息都已过期）
+func (w *Writer) removeExpired() {
+	if w.retention <= 0 {
+		return
+	}
+	segments, err := ListSegments(w.dir)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-w.retention).UnixMilli()
+	for i := 0; i+1 < len(segments) && segments[i+1].Start < cutoff; i++ {
+		if err := os.Remove(segments[i].Path); err == nil {
+			w.stats.Removed++
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package recording
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaSink 将消息重新写入 Kafka，topic 为 prefix + 原 topic，key 和消息头（含版本消息头）原样保留
+// 异步批量写入，写入失败只计数和记录日志
+type KafkaSink struct {
+	writer *kafka.Writer
+	prefix string
+	failed atomic.Int64
+}
+
+// NewKafkaSink 创建 Kafka 回放目标
+func NewKafkaSink(brokers []string, topicPrefix string) *KafkaSink {
+	s := &KafkaSink{prefix: topicPrefix}
+	s.writer = &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+		BatchTimeout: 10 * time.Millisecond,
+		Async:        true,
+		Completion: func(messages []kafka.Message, err error) {
+			if err != nil {
+				s.failed.Add(int64(len(messages)))
+				log.Printf("[Replay] Failed to write %d messages: %v\n", len(messages), err)
+			}
+		},
+	}
+	return s
+}
+
+// Send 写入消息
+func (s *KafkaSink) Send(ctx context.Context, rec *Record) error {
+	return s.writer.WriteMessages(ctx, rec.Message(s.prefix+rec.Topic))
+}
+
+// Failed 异步写入失败的消息数
+func (s *KafkaSink) Failed() int64 {
+	return s.failed.Load()
+}
+
+// Close 写出未发送的消息
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
+
+// RedisSink 将消息直接发布到 Redis Pub/Sub，不经过 Processor
+// 频道与 WebSocket 广播订阅的格式相同：market:{type}:{symbol}，K线为 market:kline:{symbol}:{interval}（以 KlineUpdate 发布）
+type RedisSink struct {
+	client *redis.Client
+}
+
+// NewRedisSink 创建 Redis 回放目标
+func NewRedisSink(client *redis.Client) *RedisSink {
+	return &RedisSink{client: client}
+}
+
+// Send 发布消息，不是行情消息（如 Processor 发布的K线以外的 topic）时返回错误
+func (s *RedisSink) Send(ctx context.Context, rec *Record) error {
+	channel, payload, err := redisMessage(rec)
+	if err != nil {
+		return err
+	}
+	return s.client.Publish(ctx, channel, payload).Err()
+}
+
+// Close 关闭连接
+func (s *RedisSink) Close() error {
+	return s.client.Close()
+}
+
+// redisMessage 由 Kafka 中的行情消息得到发布的频道和内容
+func redisMessage(rec *Record) (string, []byte, error) {
+	var msg models.MarketMessage
+	if err := json.Unmarshal(rec.Value, &msg); err != nil {
+		return "", nil, fmt.Errorf("invalid market message: %w", err)
+	}
+	if msg.Symbol == "" || len(msg.Data) == 0 {
+		return "", nil, fmt.Errorf("invalid market message: missing symbol or data")
+	}
+
+	switch msg.Type {
+	case constants.DataTypeKline:
+		var kline models.Kline
+		if err := msg.Decode(&kline); err != nil {
+			return "", nil, fmt.Errorf("invalid kline: %w", err)
+		}
+		payload, err := json.Marshal(models.NewKlineUpdate(&kline, kline.IsFinal))
+		if err != nil {
+			return "", nil, err
+		}
+		return constants.RedisChannelMarket + utils.IntervalChannel(constants.DataTypeKline, msg.Symbol, kline.Interval), payload, nil
+	case constants.DataTypeTicker, constants.DataTypeDepth, constants.DataTypeTrade:
+		return constants.RedisChannelMarket + utils.MarketChannel(msg.Type, msg.Symbol), msg.Data, nil
+	}
+	return "", nil, fmt.Errorf("unsupported message type: %s", msg.Type)
+}
@@ -0,0 +1,195 @@
+package reference
+
+import (
+	"log"
+	"market-system/common/config"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Publisher 参考价格发布接口
+type Publisher interface {
+	SaveReferencePrice(price *models.ReferencePrice) error
+}
+
+// TWAP 按时间加权的平均价格（参考价格）
+// 每个采样间隔记录一次交易对的最新成交价，窗口内的采样取平均即为 TWAP。
+// 同一采样间隔内的大量成交只计一次，短时间内的异常成交对结果影响有限，适合作为强平等场景的参考价格。
+type TWAP struct {
+	cfg       config.TWAPConfig
+	windows   []window // 按长度升序
+	maxWindow time.Duration
+	publisher Publisher
+
+	mu      sync.Mutex
+	symbols map[string]*series
+}
+
+// series 单个交易对的采样序列
+type series struct {
+	lastPrice float64
+	lastTrade int64    // 最近成交时间（毫秒）
+	samples   []sample // 按时间升序
+}
+
+// window 计算窗口
+type window struct {
+	name     string // 配置中的名称，例如 5m、30m、1h
+	duration time.Duration
+}
+
+// sample 采样点
+type sample struct {
+	timestamp int64
+	price     float64
+}
+
+// NewTWAP 创建 TWAP 计算器
+func NewTWAP(cfg config.TWAPConfig, publisher Publisher) *TWAP {
+	if len(cfg.Windows) == 0 {
+		cfg.Windows = []string{"5m", "30m", "1h"}
+	}
+	if cfg.SampleIntervalMs <= 0 {
+		cfg.SampleIntervalMs = 1000
+	}
+
+	t := &TWAP{
+		cfg:       cfg,
+		publisher: publisher,
+		symbols:   make(map[string]*series),
+	}
+	for _, w := range cfg.Windows {
+		d, err := time.ParseDuration(w)
+		if err != nil || d <= 0 {
+			log.Printf("[TWAP] Invalid window %q, skipped\n", w)
+			continue
+		}
+		t.windows = append(t.windows, window{name: w, duration: d})
+		if d > t.maxWindow {
+			t.maxWindow = d
+		}
+	}
+	sort.Slice(t.windows, func(i, j int) bool { return t.windows[i].duration < t.windows[j].duration })
+	return t
+}
+
+// RecordTrade 记录成交价格
+func (t *TWAP) RecordTrade(trade *models.Trade) {
+	if trade.Price <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.symbols[trade.Symbol]
+	if !ok {
+		s = &series{}
+		t.symbols[trade.Symbol] = s
+	}
+	if trade.Timestamp >= s.lastTrade {
+		s.lastPrice = trade.Price
+		s.lastTrade = trade.Timestamp
+	}
+}
+
+// Run 按采样间隔采样并发布参考价格，直到 stop 关闭
+func (t *TWAP) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Duration(t.cfg.SampleIntervalMs) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			for _, price := range t.sample(utils.GetCurrentTimestamp()) {
+				if err := t.publisher.SaveReferencePrice(price); err != nil {
+					log.Printf("[TWAP] Failed to publish %s: %v\n", price.Symbol, err)
+				}
+			}
+		}
+	}
+}
+
+// RemoveSymbol 停止计算交易对的参考价格，丢弃已有采样
+func (t *TWAP) RemoveSymbol(symbol string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.symbols, symbol)
+}
+
+// SymbolCount 正在计算参考价格的交易对数
+func (t *TWAP) SymbolCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.symbols)
+}
+
+// sample 为每个交易对记录一个采样点，返回最新的参考价格
+// 超过最长窗口没有成交的交易对不再计算
+func (t *TWAP) sample(now int64) []*models.ReferencePrice {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	maxWindow := t.maxWindow.Milliseconds()
+	prices := make([]*models.ReferencePrice, 0, len(t.symbols))
+	for symbol, s := range t.symbols {
+		if now-s.lastTrade > maxWindow {
+			delete(t.symbols, symbol)
+			continue
+		}
+
+		s.samples = append(s.samples, sample{timestamp: now, price: s.lastPrice})
+
+		// 丢弃超出最长窗口的采样
+		cut := 0
+		for cut < len(s.samples) && s.samples[cut].timestamp <= now-maxWindow {
+			cut++
+		}
+		if cut > 0 {
+			s.samples = append(s.samples[:0], s.samples[cut:]...)
+		}
+
+		prices = append(prices, t.compute(symbol, s, now))
+	}
+	return prices
+}
+
+// compute 计算各窗口的 TWAP（调用方需持有锁）
+// 启动后数据不足一个窗口时按已有采样计算，Coverage 给出已覆盖的比例
+func (t *TWAP) compute(symbol string, s *series, now int64) *models.ReferencePrice {
+	price := &models.ReferencePrice{
+		Symbol:    symbol,
+		LastPrice: s.lastPrice,
+		TWAP:      make(map[string]float64, len(t.windows)),
+		Coverage:  make(map[string]float64, len(t.windows)),
+		Timestamp: now,
+	}
+
+	interval := int64(t.cfg.SampleIntervalMs)
+	for _, w := range t.windows {
+		start := now - w.duration.Milliseconds()
+		var sum float64
+		var n int64
+		for j := len(s.samples) - 1; j >= 0 && s.samples[j].timestamp > start; j-- {
+			sum += s.samples[j].price
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+
+		price.TWAP[w.name] = sum / float64(n)
+
+		coverage := float64(n*interval) / float64(w.duration.Milliseconds())
+		if coverage > 1 {
+			coverage = 1
+		}
+		price.Coverage[w.name] = coverage
+	}
+	return price
+}
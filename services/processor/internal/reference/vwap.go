@@ -0,0 +1,170 @@
+package reference
+
+import (
+	"log"
+	"market-system/common/config"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"sort"
+	"sync"
+	"time"
+)
+
+// VWAPPublisher 成交量加权平均价格发布接口
+type VWAPPublisher interface {
+	SaveVWAP(price *models.VWAPPrice) error
+}
+
+// VWAP 滚动的成交量加权平均价格
+// 成交按秒汇总成交额和成交量，每个发布间隔对各窗口内的汇总求和，VWAP = 成交额 / 成交量。
+// 与 TWAP 不同，每笔成交按成交量计入，反映窗口内的实际平均成交成本。
+type VWAP struct {
+	cfg       config.VWAPConfig
+	windows   []window // 按长度升序
+	maxWindow time.Duration
+	publisher VWAPPublisher
+
+	mu      sync.Mutex
+	symbols map[string][]bucket // 按秒升序
+}
+
+// bucket 一秒内的成交汇总
+type bucket struct {
+	second   int64 // 成交时间（秒）
+	notional float64
+	volume   float64
+}
+
+// NewVWAP 创建 VWAP 计算器
+func NewVWAP(cfg config.VWAPConfig, publisher VWAPPublisher) *VWAP {
+	if len(cfg.Windows) == 0 {
+		cfg.Windows = []string{"1m", "5m", "1h"}
+	}
+	if cfg.PublishIntervalMs <= 0 {
+		cfg.PublishIntervalMs = 1000
+	}
+
+	v := &VWAP{
+		cfg:       cfg,
+		publisher: publisher,
+		symbols:   make(map[string][]bucket),
+	}
+	for _, w := range cfg.Windows {
+		d, err := time.ParseDuration(w)
+		if err != nil || d < time.Second {
+			log.Printf("[VWAP] Invalid window %q, skipped\n", w)
+			continue
+		}
+		v.windows = append(v.windows, window{name: w, duration: d})
+		if d > v.maxWindow {
+			v.maxWindow = d
+		}
+	}
+	sort.Slice(v.windows, func(i, j int) bool { return v.windows[i].duration < v.windows[j].duration })
+	return v
+}
+
+// RecordTrade 记录成交，迟到的成交计入对应的秒
+func (v *VWAP) RecordTrade(trade *models.Trade) {
+	if trade.Price <= 0 || trade.Amount <= 0 {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	second := trade.Timestamp / 1000
+	buckets := v.symbols[trade.Symbol]
+	i := len(buckets)
+	for i > 0 && buckets[i-1].second > second {
+		i--
+	}
+	if i > 0 && buckets[i-1].second == second {
+		buckets[i-1].notional += trade.Price * trade.Amount
+		buckets[i-1].volume += trade.Amount
+		return
+	}
+
+	buckets = append(buckets, bucket{})
+	copy(buckets[i+1:], buckets[i:])
+	buckets[i] = bucket{second: second, notional: trade.Price * trade.Amount, volume: trade.Amount}
+	v.symbols[trade.Symbol] = buckets
+}
+
+// Run 按发布间隔计算并发布 VWAP，直到 stop 关闭
+func (v *VWAP) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Duration(v.cfg.PublishIntervalMs) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			for _, price := range v.calculate(utils.GetCurrentTimestamp()) {
+				if err := v.publisher.SaveVWAP(price); err != nil {
+					log.Printf("[VWAP] Failed to publish %s: %v\n", price.Symbol, err)
+				}
+			}
+		}
+	}
+}
+
+// RemoveSymbol 停止计算交易对的 VWAP，丢弃已有汇总
+func (v *VWAP) RemoveSymbol(symbol string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.symbols, symbol)
+}
+
+// SymbolCount 正在计算 VWAP 的交易对数
+func (v *VWAP) SymbolCount() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return len(v.symbols)
+}
+
+// calculate 丢弃超出最长窗口的汇总，返回各交易对最新的 VWAP
+// 最长窗口内没有成交的交易对不再计算，窗口内没有成交的窗口不返回
+func (v *VWAP) calculate(now int64) []*models.VWAPPrice {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	oldest := (now - v.maxWindow.Milliseconds()) / 1000
+	prices := make([]*models.VWAPPrice, 0, len(v.symbols))
+	for symbol, buckets := range v.symbols {
+		cut := 0
+		for cut < len(buckets) && buckets[cut].second <= oldest {
+			cut++
+		}
+		if cut == len(buckets) {
+			delete(v.symbols, symbol)
+			continue
+		}
+		if cut > 0 {
+			buckets = append(buckets[:0], buckets[cut:]...)
+			v.symbols[symbol] = buckets
+		}
+
+		price := &models.VWAPPrice{
+			Symbol:    symbol,
+			VWAP:      make(map[string]float64, len(v.windows)),
+			Volume:    make(map[string]float64, len(v.windows)),
+			Timestamp: now,
+		}
+		for _, w := range v.windows {
+			start := (now - w.duration.Milliseconds()) / 1000
+			var notional, volume float64
+			for j := len(buckets) - 1; j >= 0 && buckets[j].second > start; j-- {
+				notional += buckets[j].notional
+				volume += buckets[j].volume
+			}
+			if volume > 0 {
+				price.VWAP[w.name] = notional / volume
+				price.Volume[w.name] = volume
+			}
+		}
+		prices = append(prices, price)
+	}
+	return prices
+}
@@ -0,0 +1,48 @@
+package reference
+
+import (
+	"market-system/common/config"
+	"market-system/common/models"
+	"math"
+	"testing"
+)
+
+func TestVWAP(t *testing.T) {
+	v := NewVWAP(config.VWAPConfig{Windows: []string{"1m", "5m", "bad"}}, nil)
+	if len(v.windows) != 2 {
+		t.Fatalf("windows = %+v", v.windows)
+	}
+
+	trade := func(price, amount float64, timestamp int64) {
+		v.RecordTrade(&models.Trade{Symbol: "BTCUSDT", Price: price, Amount: amount, Timestamp: timestamp})
+	}
+	now := int64(600000)
+	trade(100, 1, now-200000) // 只在 5m 窗口内
+	trade(110, 1, now-30000)
+	trade(120, 3, now-10000)
+	// 迟到的成交计入对应的秒
+	trade(100, 2, now-30500)
+
+	prices := v.calculate(now)
+	if len(prices) != 1 {
+		t.Fatalf("prices = %d", len(prices))
+	}
+	price := prices[0]
+	if got := price.VWAP["1m"]; math.Abs(got-(110+360+200)/6.0) > 1e-9 || price.Volume["1m"] != 6 {
+		t.Errorf("1m = %v, volume %v", got, price.Volume["1m"])
+	}
+	if got := price.VWAP["5m"]; math.Abs(got-(100+110+360+200)/7.0) > 1e-9 || price.Volume["5m"] != 7 {
+		t.Errorf("5m = %v, volume %v", got, price.Volume["5m"])
+	}
+
+	// 1m 窗口内没有成交时不返回该窗口
+	prices = v.calculate(now + 120000)
+	if _, ok := prices[0].VWAP["1m"]; ok || prices[0].Volume["5m"] != 6 {
+		t.Errorf("after 2m = %+v", prices[0])
+	}
+
+	// 最长窗口内没有成交时不再计算
+	if prices = v.calculate(now + 600000); len(prices) != 0 || v.SymbolCount() != 0 {
+		t.Errorf("after 10m = %+v, symbols %d", prices, v.SymbolCount())
+	}
+}
@@ -0,0 +1,80 @@
+package registry
+
+import (
+	"log"
+	"sync"
+)
+
+// Store 交易对注册表读取接口
+type Store interface {
+	DeletedSymbols() (map[string]bool, error)
+}
+
+// Registry 交易对注册表中已软删除的交易对
+// 软删除的交易对不再处理（不写入存储、不推送），已有数据保留；恢复后收到新数据时重新开始处理。
+// 注册表由 API 服务的管理接口维护，变更后通过 symbol_config:update 频道通知，这里收到通知后重新加载。
+type Registry struct {
+	store     Store
+	onDeleted func(symbol string) // 交易对被软删除时清理其聚合状态
+
+	mu      sync.RWMutex
+	deleted map[string]bool
+}
+
+// New 创建交易对注册表
+func New(store Store, onDeleted func(symbol string)) *Registry {
+	return &Registry{
+		store:     store,
+		onDeleted: onDeleted,
+		deleted:   make(map[string]bool),
+	}
+}
+
+// Load 加载已软删除的交易对
+func (r *Registry) Load() error {
+	deleted, err := r.store.DeletedSymbols()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	previous := r.deleted
+	r.deleted = deleted
+	r.mu.Unlock()
+
+	for symbol := range deleted {
+		if !previous[symbol] {
+			log.Printf("[Registry] Symbol %s deleted, processing stopped\n", symbol)
+			r.onDeleted(symbol)
+		}
+	}
+	for symbol := range previous {
+		if !deleted[symbol] {
+			log.Printf("[Registry] Symbol %s restored\n", symbol)
+		}
+	}
+	return nil
+}
+
+// Run 收到注册表变更通知后重新加载，直到 updates 关闭
+func (r *Registry) Run(updates <-chan string) {
+	for range updates {
+		if err := r.Load(); err != nil {
+			log.Printf("[Registry] Failed to reload: %v\n", err)
+		}
+	}
+}
+
+// IsDeleted 交易对是否已软删除
+func (r *Registry) IsDeleted(symbol string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.deleted[symbol]
+}
+
+// DeletedCount 已软删除的交易对数
+func (r *Registry) DeletedCount() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.deleted)
+}
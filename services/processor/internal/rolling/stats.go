@@ -0,0 +1,517 @@
+package rolling
+
+import (
+	"log"
+	"market-system/common/config"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store 交易对注册表读取接口
+type Store interface {
+	InternalOnlySymbols() (map[string]bool, error)
+}
+
+// Quotes 最优买卖价读取接口
+type Quotes interface {
+	BestPrices(symbol string) (bid, ask float64)
+}
+
+// Sink Ticker 写入接口
+type Sink interface {
+	SaveTicker(ticker *models.Ticker) error
+}
+
+// StatsSink 24 小时统计写入接口
+type StatsSink interface {
+	SaveMarketStats(stats []*models.MarketStats) error
+}
+
+// StateStore 滚动窗口状态存储接口，key 为交易对，value 为分桶状态 JSON
+type StateStore interface {
+	SaveRollingState(states map[string]string) error
+	LoadRollingState() (map[string]string, error)
+}
+
+// windowMs 滚动窗口长度
+const windowMs = 24 * int64(time.Hour/time.Millisecond)
+
+// bucketMs 分桶长度，窗口按分钟滑动
+const bucketMs = int64(time.Minute / time.Millisecond)
+
+// Stats 由成交流计算的 24 小时滚动行情统计
+// 仅使用内部数据的交易对没有交易所 Ticker，这里按分钟分桶累计成交，定期汇总最近 24 小时的
+// 开盘价、最高/最低价、成交量和涨跌幅，合成 Ticker 写入存储。窗口按分钟滑动，最早一个分桶整体移出窗口。
+type Stats struct {
+	cfg    config.RollingTickerConfig
+	store  Store
+	quotes Quotes
+	sink   Sink
+	stats  StatsSink  // 为 nil 表示只写入合成的 Ticker
+	state  StateStore // 为 nil 表示不保存窗口状态，重启后从头计算
+
+	mu       sync.Mutex
+	include  map[string]bool // 计算的交易对
+	symbols  map[string]*series
+	restored bool // 已恢复停机时保存的窗口状态
+}
+
+// series 单个交易对的成交分桶
+type series struct {
+	lastPrice float64
+	lastTrade int64    // 最近成交时间（毫秒）
+	buckets   []bucket // 按时间升序
+}
+
+// bucket 一分钟内的成交汇总
+type bucket struct {
+	start     int64
+	open      float64
+	openTime  int64
+	high      float64
+	low       float64
+	close     float64
+	closeTime int64
+	volume    float64
+	quoteVol  float64
+	count     int64
+}
+
+// state 交易对滚动窗口的保存格式
+type state struct {
+	LastPrice float64       `json:"last_price"`
+	LastTrade int64         `json:"last_trade"`
+	Buckets   []bucketState `json:"buckets"`
+}
+
+// bucketState 分桶的保存格式，窗口最多 1440 个分桶，字段名从简
+type bucketState struct {
+	Start     int64   `json:"t"`
+	Open      float64 `json:"o"`
+	OpenTime  int64   `json:"ot"`
+	High      float64 `json:"h"`
+	Low       float64 `json:"l"`
+	Close     float64 `json:"c"`
+	CloseTime int64   `json:"ct"`
+	Volume    float64 `json:"v"`
+	QuoteVol  float64 `json:"q"`
+	Count     int64   `json:"n"`
+}
+
+// Window 24 小时滚动窗口统计
+type Window struct {
+	Symbol        string
+	LastPrice     float64
+	Open          float64 // 窗口内第一笔成交价，窗口内没有成交时为最新价
+	High          float64
+	Low           float64
+	Volume        float64
+	QuoteVolume   float64
+	Change        float64
+	ChangePercent float64
+	Count         int64
+}
+
+// NewStats 创建滚动行情统计
+func NewStats(cfg config.RollingTickerConfig, store Store, quotes Quotes, sink Sink) *Stats {
+	if cfg.PublishIntervalMs <= 0 {
+		cfg.PublishIntervalMs = 1000
+	}
+	return &Stats{
+		cfg:     cfg,
+		store:   store,
+		quotes:  quotes,
+		sink:    sink,
+		include: make(map[string]bool),
+		symbols: make(map[string]*series),
+	}
+}
+
+// SetStatsSink 同时写入包含成交额的 24 小时统计（启动前调用）
+func (s *Stats) SetStatsSink(sink StatsSink) {
+	s.stats = sink
+}
+
+// SetStateStore 停机时保存窗口状态，首次加载交易对时恢复（启动前调用）
+func (s *Stats) SetStateStore(store StateStore) {
+	s.state = store
+}
+
+// Load 加载需要计算的交易对：INTERNAL_ONLY 交易对和配置中额外指定的交易对
+// 不再需要计算的交易对丢弃已有统计；首次加载时恢复停机时保存的窗口状态
+func (s *Stats) Load() error {
+	include, err := s.store.InternalOnlySymbols()
+	if err != nil {
+		return err
+	}
+	for _, symbol := range s.cfg.Symbols {
+		include[symbol] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.include = include
+	for symbol := range s.symbols {
+		if !include[symbol] {
+			delete(s.symbols, symbol)
+		}
+	}
+	if !s.restored {
+		s.restored = true
+		s.restoreLocked()
+	}
+	return nil
+}
+
+// Stop 保存所有交易对的窗口状态，需在停止处理数据后调用
+func (s *Stats) Stop() {
+	if s.state == nil {
+		return
+	}
+
+	s.mu.Lock()
+	states := make(map[string]string, len(s.symbols))
+	for symbol, sr := range s.symbols {
+		if data, err := sr.encode(); err == nil {
+			states[symbol] = data
+		}
+	}
+	s.mu.Unlock()
+
+	if err := s.state.SaveRollingState(states); err != nil {
+		log.Printf("[RollingTicker] Failed to save window state: %v\n", err)
+		return
+	}
+	log.Printf("[RollingTicker] Saved window state of %d symbols\n", len(states))
+}
+
+// restoreLocked 恢复停机时保存的窗口状态
+func (s *Stats) restoreLocked() {
+	if s.state == nil {
+		return
+	}
+
+	states, err := s.state.LoadRollingState()
+	if err != nil {
+		log.Printf("[RollingTicker] Failed to load window state: %v\n", err)
+		return
+	}
+	if restored := s.restore(states); restored > 0 {
+		log.Printf("[RollingTicker] Restored window state of %d symbols\n", restored)
+	}
+}
+
+// SymbolState 交易对的窗口状态 JSON，没有统计时返回空字符串
+func (s *Stats) SymbolState(symbol string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sr, ok := s.symbols[symbol]
+	if !ok {
+		return ""
+	}
+	data, err := sr.encode()
+	if err != nil {
+		log.Printf("[RollingTicker] Failed to encode window state of %s: %v\n", symbol, err)
+		return ""
+	}
+	return data
+}
+
+// Restore 恢复保存的窗口状态，返回恢复的交易对数；不计算的交易对和已有统计的交易对忽略
+func (s *Stats) Restore(states map[string]string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.restore(states)
+}
+
+// restore 恢复窗口状态，需持有锁
+func (s *Stats) restore(states map[string]string) int {
+	now := utils.GetCurrentTimestamp()
+	restored := 0
+	for symbol, data := range states {
+		if !s.include[symbol] {
+			continue
+		}
+		if _, ok := s.symbols[symbol]; ok {
+			continue
+		}
+		sr, err := decodeSeries(data)
+		if err != nil {
+			log.Printf("[RollingTicker] Invalid window state of %s: %v\n", symbol, err)
+			continue
+		}
+		sr.expire(now)
+		s.symbols[symbol] = sr
+		restored++
+	}
+	return restored
+}
+
+// RecordTrade 记录成交
+func (s *Stats) RecordTrade(trade *models.Trade) {
+	if trade.Price <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.include[trade.Symbol] {
+		return
+	}
+
+	sr, ok := s.symbols[trade.Symbol]
+	if !ok {
+		sr = &series{}
+		s.symbols[trade.Symbol] = sr
+	}
+	sr.add(trade)
+}
+
+// Run 按发布间隔写入合成的 Ticker，收到注册表变更通知后重新加载交易对，直到 stop 关闭
+func (s *Stats) Run(updates <-chan string, stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Duration(s.cfg.PublishIntervalMs) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case _, ok := <-updates:
+			if !ok {
+				updates = nil
+				continue
+			}
+			if err := s.Load(); err != nil {
+				log.Printf("[RollingTicker] Failed to reload symbols: %v\n", err)
+			}
+		case <-ticker.C:
+			now := utils.GetCurrentTimestamp()
+			windows := s.Snapshot(now)
+			for _, w := range windows {
+				if err := s.sink.SaveTicker(s.ticker(w, now)); err != nil {
+					log.Printf("[RollingTicker] Failed to save %s: %v\n", w.Symbol, err)
+				}
+			}
+			if s.stats != nil && len(windows) > 0 {
+				stats := make([]*models.MarketStats, len(windows))
+				for i, w := range windows {
+					stats[i] = w.MarketStats(now)
+				}
+				if err := s.stats.SaveMarketStats(stats); err != nil {
+					log.Printf("[RollingTicker] Failed to save 24h stats: %v\n", err)
+				}
+			}
+		}
+	}
+}
+
+// RemoveSymbol 丢弃交易对的统计，重新收到成交后从头计算
+func (s *Stats) RemoveSymbol(symbol string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.symbols, symbol)
+}
+
+// SymbolCount 正在计算的交易对数
+func (s *Stats) SymbolCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.symbols)
+}
+
+// Snapshot 计算各交易对截至 now 的滚动窗口统计，并丢弃移出窗口的分桶
+func (s *Stats) Snapshot(now int64) []*Window {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	windows := make([]*Window, 0, len(s.symbols))
+	for symbol, sr := range s.symbols {
+		sr.expire(now)
+		windows = append(windows, sr.window(symbol))
+	}
+	return windows
+}
+
+// ticker 由滚动窗口统计合成 Ticker，买卖价取当前深度的最优价
+func (s *Stats) ticker(w *Window, now int64) *models.Ticker {
+	t := &models.Ticker{
+		Symbol:                w.Symbol,
+		LastPrice:             w.LastPrice,
+		High24h:               w.High,
+		Low24h:                w.Low,
+		Volume24h:             w.Volume,
+		Open24h:               w.Open,
+		PriceChange24h:        w.Change,
+		PriceChangePercent24h: w.ChangePercent,
+		TradeCount24h:         w.Count,
+		Timestamp:             now,
+	}
+	if s.quotes != nil {
+		t.BidPrice, t.AskPrice = s.quotes.BestPrices(w.Symbol)
+	}
+	return t
+}
+
+// MarketStats 转换为 24 小时统计
+func (w *Window) MarketStats(now int64) *models.MarketStats {
+	return &models.MarketStats{
+		Symbol:             w.Symbol,
+		Open:               w.Open,
+		High:               w.High,
+		Low:                w.Low,
+		LastPrice:          w.LastPrice,
+		Volume:             w.Volume,
+		QuoteVolume:        w.QuoteVolume,
+		PriceChange:        w.Change,
+		PriceChangePercent: w.ChangePercent,
+		TradeCount:         w.Count,
+		Timestamp:          now,
+	}
+}
+
+// add 将成交计入所在分钟的分桶，迟到的成交计入已有分桶
+func (sr *series) add(trade *models.Trade) {
+	if trade.Timestamp >= sr.lastTrade {
+		sr.lastPrice = trade.Price
+		sr.lastTrade = trade.Timestamp
+	}
+
+	start := trade.Timestamp - trade.Timestamp%bucketMs
+	i := sort.Search(len(sr.buckets), func(i int) bool { return sr.buckets[i].start >= start })
+	if i == len(sr.buckets) || sr.buckets[i].start != start {
+		sr.buckets = append(sr.buckets, bucket{})
+		copy(sr.buckets[i+1:], sr.buckets[i:])
+		sr.buckets[i] = bucket{
+			start:     start,
+			open:      trade.Price,
+			openTime:  trade.Timestamp,
+			high:      trade.Price,
+			low:       trade.Price,
+			close:     trade.Price,
+			closeTime: trade.Timestamp,
+		}
+	}
+
+	b := &sr.buckets[i]
+	if trade.Timestamp < b.openTime {
+		b.open, b.openTime = trade.Price, trade.Timestamp
+	}
+	if trade.Timestamp >= b.closeTime {
+		b.close, b.closeTime = trade.Price, trade.Timestamp
+	}
+	if trade.Price > b.high {
+		b.high = trade.Price
+	}
+	if trade.Price < b.low {
+		b.low = trade.Price
+	}
+	b.volume += trade.Amount
+	b.quoteVol += trade.Price * trade.Amount
+	b.count++
+}
+
+// encode 序列化分桶状态
+func (sr *series) encode() (string, error) {
+	st := state{
+		LastPrice: sr.lastPrice,
+		LastTrade: sr.lastTrade,
+		Buckets:   make([]bucketState, len(sr.buckets)),
+	}
+	for i, b := range sr.buckets {
+		st.Buckets[i] = bucketState{
+			Start:     b.start,
+			Open:      b.open,
+			OpenTime:  b.openTime,
+			High:      b.high,
+			Low:       b.low,
+			Close:     b.close,
+			CloseTime: b.closeTime,
+			Volume:    b.volume,
+			QuoteVol:  b.quoteVol,
+			Count:     b.count,
+		}
+	}
+	return utils.ToJSON(st)
+}
+
+// decodeSeries 解析分桶状态，分桶按时间排序
+func decodeSeries(data string) (*series, error) {
+	var st state
+	if err := utils.FromJSON(data, &st); err != nil {
+		return nil, err
+	}
+
+	sr := &series{
+		lastPrice: st.LastPrice,
+		lastTrade: st.LastTrade,
+		buckets:   make([]bucket, len(st.Buckets)),
+	}
+	for i, b := range st.Buckets {
+		sr.buckets[i] = bucket{
+			start:     b.Start,
+			open:      b.Open,
+			openTime:  b.OpenTime,
+			high:      b.High,
+			low:       b.Low,
+			close:     b.Close,
+			closeTime: b.CloseTime,
+			volume:    b.Volume,
+			quoteVol:  b.QuoteVol,
+			count:     b.Count,
+		}
+	}
+	sort.Slice(sr.buckets, func(i, j int) bool { return sr.buckets[i].start < sr.buckets[j].start })
+	return sr, nil
+}
+
+// expire 丢弃整体移出窗口的分桶
+func (sr *series) expire(now int64) {
+	cut := 0
+	for cut < len(sr.buckets) && sr.buckets[cut].start+bucketMs <= now-windowMs {
+		cut++
+	}
+	if cut > 0 {
+		sr.buckets = append(sr.buckets[:0], sr.buckets[cut:]...)
+	}
+}
+
+// window 汇总窗口内的分桶；窗口内没有成交时价格均为最新价，成交量为 0
+func (sr *series) window(symbol string) *Window {
+	w := &Window{
+		Symbol:    symbol,
+		LastPrice: sr.lastPrice,
+		Open:      sr.lastPrice,
+		High:      sr.lastPrice,
+		Low:       sr.lastPrice,
+	}
+	if len(sr.buckets) == 0 {
+		return w
+	}
+
+	w.Open = sr.buckets[0].open
+	w.High = sr.buckets[0].high
+	w.Low = sr.buckets[0].low
+	for _, b := range sr.buckets {
+		if b.high > w.High {
+			w.High = b.high
+		}
+		if b.low < w.Low {
+			w.Low = b.low
+		}
+		w.Volume += b.volume
+		w.QuoteVolume += b.quoteVol
+		w.Count += b.count
+	}
+
+	w.Change = w.LastPrice - w.Open
+	if w.Open > 0 {
+		w.ChangePercent = w.Change / w.Open * 100
+	}
+	return w
+}
@@ -0,0 +1,167 @@
+package rolling
+
+import (
+	"market-system/common/config"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"math"
+	"testing"
+)
+
+type memoryStore map[string]bool
+
+func (s memoryStore) InternalOnlySymbols() (map[string]bool, error) {
+	symbols := make(map[string]bool, len(s))
+	for k, v := range s {
+		symbols[k] = v
+	}
+	return symbols, nil
+}
+
+const hour = 60 * bucketMs
+
+func TestStatsRollingWindow(t *testing.T) {
+	s := NewStats(config.RollingTickerConfig{}, memoryStore{"ABCUSDT": true}, nil, nil)
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	start := int64(1700000000000) - int64(1700000000000)%bucketMs
+	trades := []*models.Trade{
+		{Symbol: "ABCUSDT", Price: 100, Amount: 1, Timestamp: start},
+		{Symbol: "ABCUSDT", Price: 120, Amount: 2, Timestamp: start + 2*hour},
+		{Symbol: "ABCUSDT", Price: 90, Amount: 1, Timestamp: start + 10*hour},
+		// 迟到的成交计入已有分桶
+		{Symbol: "ABCUSDT", Price: 95, Amount: 1, Timestamp: start + 2*hour - 1000},
+		{Symbol: "ABCUSDT", Price: 110, Amount: 4, Timestamp: start + 24*hour},
+		// 不计算的交易对
+		{Symbol: "BTCUSDT", Price: 50000, Amount: 1, Timestamp: start},
+	}
+	for _, trade := range trades {
+		s.RecordTrade(trade)
+	}
+
+	// 第一笔成交所在分桶仍在窗口内
+	w := snapshot(t, s, start+24*hour)
+	if w.Open != 100 || w.High != 120 || w.Low != 90 || w.LastPrice != 110 || w.Volume != 9 || w.Count != 5 {
+		t.Fatalf("unexpected window: %+v", w)
+	}
+	if st := w.MarketStats(start + 24*hour); st.QuoteVolume != 965 || st.TradeCount != 5 || st.LastPrice != 110 {
+		t.Errorf("unexpected stats: %+v", st)
+	}
+
+	// 第一笔成交所在分桶移出窗口
+	w = snapshot(t, s, start+24*hour+bucketMs)
+	if w.Open != 95 || w.High != 120 || w.Low != 90 || w.Volume != 8 || w.Count != 4 {
+		t.Fatalf("unexpected window after expiry: %+v", w)
+	}
+	if math.Abs(w.Change-15) > 1e-9 || math.Abs(w.ChangePercent-15.0/95*100) > 1e-9 {
+		t.Errorf("unexpected change: %v (%v%%)", w.Change, w.ChangePercent)
+	}
+
+	// 窗口内没有成交时保留最新价
+	w = snapshot(t, s, start+72*hour)
+	if w.Open != 110 || w.High != 110 || w.Low != 110 || w.Volume != 0 || w.ChangePercent != 0 {
+		t.Errorf("unexpected empty window: %+v", w)
+	}
+}
+
+type memoryState map[string]string
+
+func (m memoryState) SaveRollingState(states map[string]string) error {
+	for k := range m {
+		delete(m, k)
+	}
+	for k, v := range states {
+		m[k] = v
+	}
+	return nil
+}
+
+func (m memoryState) LoadRollingState() (map[string]string, error) {
+	states := make(map[string]string, len(m))
+	for k, v := range m {
+		states[k] = v
+		delete(m, k)
+	}
+	return states, nil
+}
+
+func TestStatsRestore(t *testing.T) {
+	// 使用当前时间，恢复时丢弃的是相对当前时间已移出窗口的分桶
+	now := utils.GetCurrentTimestamp()
+	start := now - now%bucketMs - 23*hour
+	trades := []*models.Trade{
+		{Symbol: "ABCUSDT", Price: 100, Amount: 1, Timestamp: start},
+		{Symbol: "ABCUSDT", Price: 120, Amount: 2, Timestamp: start + 2*hour},
+		{Symbol: "ABCUSDT", Price: 90, Amount: 1, Timestamp: start + 10*hour},
+		{Symbol: "XYZUSDT", Price: 5, Amount: 10, Timestamp: start + hour},
+	}
+
+	store := memoryState{}
+	s := NewStats(config.RollingTickerConfig{}, memoryStore{"ABCUSDT": true, "XYZUSDT": true}, nil, nil)
+	s.SetStateStore(store)
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	for _, trade := range trades {
+		s.RecordTrade(trade)
+	}
+	want := snapshot(t, filtered(s, "ABCUSDT"), now)
+
+	// 停机时保存，重启后首次加载交易对时恢复；不再计算的交易对不恢复
+	s.Stop()
+	if len(store) != 2 {
+		t.Fatalf("saved states: %v", store)
+	}
+	restarted := NewStats(config.RollingTickerConfig{}, memoryStore{"ABCUSDT": true}, nil, nil)
+	restarted.SetStateStore(store)
+	if err := restarted.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if restarted.SymbolCount() != 1 {
+		t.Fatalf("restored symbols: %d", restarted.SymbolCount())
+	}
+	if got := snapshot(t, restarted, now); *got != *want {
+		t.Errorf("restored window = %+v, want %+v", got, want)
+	}
+
+	// 恢复后继续计入成交，窗口连续
+	restarted.RecordTrade(&models.Trade{Symbol: "ABCUSDT", Price: 130, Amount: 1, Timestamp: now})
+	if w := snapshot(t, restarted, now); w.Open != 100 || w.High != 130 || w.Volume != 5 || w.Count != 4 {
+		t.Errorf("unexpected window after restore: %+v", w)
+	}
+
+	// 分区收回时按交易对保存，分配时恢复；已有统计的交易对不覆盖
+	state := restarted.SymbolState("ABCUSDT")
+	restarted.RemoveSymbol("ABCUSDT")
+	if restarted.SymbolState("ABCUSDT") != "" {
+		t.Error("removed symbol still has state")
+	}
+	if n := restarted.Restore(map[string]string{"ABCUSDT": state, "XYZUSDT": state}); n != 1 {
+		t.Errorf("restored %d symbols, want 1", n)
+	}
+	if n := restarted.Restore(map[string]string{"ABCUSDT": state}); n != 0 {
+		t.Errorf("restored %d symbols over existing state", n)
+	}
+	if w := snapshot(t, restarted, now); w.High != 130 || w.Count != 4 {
+		t.Errorf("unexpected window after partition restore: %+v", w)
+	}
+}
+
+// filtered 只保留一个交易对的统计，便于用 snapshot 比较
+func filtered(s *Stats, symbol string) *Stats {
+	c := NewStats(s.cfg, s.store, nil, nil)
+	c.include = map[string]bool{symbol: true}
+	c.symbols[symbol] = s.symbols[symbol]
+	return c
+}
+
+func snapshot(t *testing.T, s *Stats, now int64) *Window {
+	t.Helper()
+	windows := s.Snapshot(now)
+	if len(windows) != 1 || windows[0].Symbol != "ABCUSDT" {
+		t.Fatalf("unexpected windows: %+v", windows)
+	}
+	return windows[0]
+}
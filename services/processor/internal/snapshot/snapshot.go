@@ -0,0 +1,265 @@
+package snapshot
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"market-system/common/constants"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Version 快照文件格式版本
+const Version = 1
+
+// Snapshot 行情状态快照
+type Snapshot struct {
+	Version   int      `json:"version"`
+	CreatedAt int64    `json:"created_at"`
+	Symbols   []string `json:"symbols"`
+	Keys      []Record `json:"keys"`
+}
+
+// Record 单个 Redis 键，值按类型保存在对应字段中（[]byte 在 JSON 中为 base64，兼容 MessagePack 编码的值）
+type Record struct {
+	Symbol string            `json:"symbol"`
+	Key    string            `json:"key"`
+	Type   string            `json:"type"`
+	TTLMs  int64             `json:"ttl_ms"` // 导出时的剩余过期时间，0 表示不过期
+	Value  []byte            `json:"value,omitempty"`
+	Hash   map[string]string `json:"hash,omitempty"`
+	List   [][]byte          `json:"list,omitempty"` // 从头部到尾部
+	ZSet   []ZMember         `json:"zset,omitempty"`
+}
+
+// ZMember 有序集合成员
+type ZMember struct {
+	Score  float64 `json:"score"`
+	Member []byte  `json:"member"`
+}
+
+// symbolKeys 交易对的行情键：Ticker（含来源明细）、深度、最优买卖价、K线、成交、聚合成交
+// 带后缀的聚合深度（depth:{symbol}:{precision}）和 K线（kline:{symbol}:{interval}[:{source}]）通过 SCAN 查找
+func symbolKeys(ctx context.Context, client *redis.Client, symbol string) ([]string, error) {
+	keys := []string{
+		constants.RedisKeyTicker + symbol,
+		constants.RedisKeyTickerSource + symbol,
+		constants.RedisKeyDepth + symbol,
+		constants.RedisKeyDepthSource + symbol,
+		constants.RedisKeyTrade + symbol,
+		constants.RedisKeyAggTrade + symbol,
+		constants.RedisKeyBookTicker + symbol,
+	}
+	for _, prefix := range []string{constants.RedisKeyDepth, constants.RedisKeyKline} {
+		var scanned []string
+		iter := client.Scan(ctx, 0, prefix+symbol+":*", 500).Iterator()
+		for iter.Next(ctx) {
+			scanned = append(scanned, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return nil, err
+		}
+		sort.Strings(scanned)
+		keys = append(keys, scanned...)
+	}
+	return keys, nil
+}
+
+// Export 导出交易对的行情键，不存在的键跳过
+func Export(ctx context.Context, client *redis.Client, symbols []string) (*Snapshot, error) {
+	snap := &Snapshot{
+		Version:   Version,
+		CreatedAt: time.Now().UnixMilli(),
+		Symbols:   symbols,
+	}
+	for _, symbol := range symbols {
+		keys, err := symbolKeys(ctx, client, symbol)
+		if err != nil {
+			return nil, fmt.Errorf("scan %s: %w", symbol, err)
+		}
+		for _, key := range keys {
+			rec, err := exportKey(ctx, client, key)
+			if err != nil {
+				return nil, fmt.Errorf("export %s: %w", key, err)
+			}
+			if rec == nil {
+				continue
+			}
+			rec.Symbol = symbol
+			snap.Keys = append(snap.Keys, *rec)
+		}
+	}
+	return snap, nil
+}
+
+// exportKey 读取单个键，键不存在时返回 nil
+func exportKey(ctx context.Context, client *redis.Client, key string) (*Record, error) {
+	typ, err := client.Type(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	rec := &Record{Key: key, Type: typ}
+
+	switch typ {
+	case "none":
+		return nil, nil
+	case "string":
+		rec.Value, err = client.Get(ctx, key).Bytes()
+	case "hash":
+		rec.Hash, err = client.HGetAll(ctx, key).Result()
+	case "list":
+		var items []string
+		items, err = client.LRange(ctx, key, 0, -1).Result()
+		for _, item := range items {
+			rec.List = append(rec.List, []byte(item))
+		}
+	case "zset":
+		var members []redis.Z
+		members, err = client.ZRangeWithScores(ctx, key, 0, -1).Result()
+		for _, m := range members {
+			rec.ZSet = append(rec.ZSet, ZMember{Score: m.Score, Member: []byte(m.Member.(string))})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type %s", typ)
+	}
+	if err == redis.Nil {
+		// 读取前已过期
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ttl, err := client.PTTL(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	if ttl > 0 {
+		rec.TTLMs = ttl.Milliseconds()
+	}
+	return rec, nil
+}
+
+// Restore 写入快照中的键，覆盖目标环境中的同名键
+// symbols 为空时写入全部交易对；persist 为 true 时不设置过期时间
+func Restore(ctx context.Context, client *redis.Client, snap *Snapshot, symbols []string, persist bool) (int, error) {
+	var selected map[string]bool
+	if len(symbols) > 0 {
+		selected = make(map[string]bool, len(symbols))
+		for _, symbol := range symbols {
+			selected[symbol] = true
+		}
+	}
+
+	restored := 0
+	for _, rec := range snap.Keys {
+		if selected != nil && !selected[rec.Symbol] {
+			continue
+		}
+		if err := restoreKey(ctx, client, rec, persist); err != nil {
+			return restored, fmt.Errorf("restore %s: %w", rec.Key, err)
+		}
+		restored++
+	}
+	return restored, nil
+}
+
+// restoreKey 在一个事务中删除并写入单个键
+func restoreKey(ctx context.Context, client *redis.Client, rec Record, persist bool) error {
+	_, err := client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, rec.Key)
+		switch rec.Type {
+		case "string":
+			pipe.Set(ctx, rec.Key, rec.Value, 0)
+		case "hash":
+			if len(rec.Hash) > 0 {
+				pipe.HSet(ctx, rec.Key, rec.Hash)
+			}
+		case "list":
+			if len(rec.List) > 0 {
+				values := make([]interface{}, len(rec.List))
+				for i, item := range rec.List {
+					values[i] = item
+				}
+				pipe.RPush(ctx, rec.Key, values...)
+			}
+		case "zset":
+			if len(rec.ZSet) > 0 {
+				members := make([]redis.Z, len(rec.ZSet))
+				for i, m := range rec.ZSet {
+					members[i] = redis.Z{Score: m.Score, Member: m.Member}
+				}
+				pipe.ZAdd(ctx, rec.Key, members...)
+			}
+		default:
+			return fmt.Errorf("unsupported type %s", rec.Type)
+		}
+		if !persist && rec.TTLMs > 0 {
+			pipe.PExpire(ctx, rec.Key, time.Duration(rec.TTLMs)*time.Millisecond)
+		}
+		return nil
+	})
+	return err
+}
+
+// Write 将快照写入文件，文件名以 .gz 结尾时使用 gzip 压缩
+func Write(path string, snap *Snapshot) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := encode(f, snap, strings.HasSuffix(path, ".gz")); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Read 读取快照文件，文件名以 .gz 结尾时按 gzip 解压
+func Read(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return decode(f, strings.HasSuffix(path, ".gz"))
+}
+
+func encode(w io.Writer, snap *Snapshot, compress bool) error {
+	if !compress {
+		return json.NewEncoder(w).Encode(snap)
+	}
+	gz := gzip.NewWriter(w)
+	if err := json.NewEncoder(gz).Encode(snap); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func decode(r io.Reader, compress bool) (*Snapshot, error) {
+	if compress {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var snap Snapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, err
+	}
+	if snap.Version != Version {
+		return nil, fmt.Errorf("unsupported snapshot version %d", snap.Version)
+	}
+	return &snap, nil
+}
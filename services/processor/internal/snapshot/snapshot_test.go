@@ -0,0 +1,39 @@
+package snapshot
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	snap := &Snapshot{
+		Version:   Version,
+		CreatedAt: 1700000000000,
+		Symbols:   []string{"BTCUSDT"},
+		Keys: []Record{
+			{Symbol: "BTCUSDT", Key: "depth:BTCUSDT", Type: "string", TTLMs: 5000, Value: []byte{0x84, 0xa6, 0xff}},
+			{Symbol: "BTCUSDT", Key: "ticker:BTCUSDT", Type: "hash", Hash: map[string]string{"data": `{"s":"BTCUSDT"}`}},
+			{Symbol: "BTCUSDT", Key: "trade:BTCUSDT", Type: "list", List: [][]byte{[]byte("b"), []byte("a")}},
+			{Symbol: "BTCUSDT", Key: "kline:BTCUSDT:1m", Type: "zset", ZSet: []ZMember{{Score: 1700000000000, Member: []byte("k")}}},
+		},
+	}
+
+	for _, compress := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := encode(&buf, snap, compress); err != nil {
+			t.Fatal(err)
+		}
+		got, err := decode(&buf, compress)
+		if err != nil {
+			t.Fatalf("compress=%v: %v", compress, err)
+		}
+		if !reflect.DeepEqual(got, snap) {
+			t.Errorf("compress=%v: got %+v", compress, got)
+		}
+	}
+
+	if _, err := decode(bytes.NewBufferString(`{"version":2}`), false); err == nil {
+		t.Error("expected version error")
+	}
+}
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"fmt"
+	"log"
+	"market-system/common/config"
+	"sort"
+	"sync"
+)
+
+// 内置存储后端名称
+const (
+	BackendRedis    = "redis"
+	BackendInfluxDB = "influxdb"
+	BackendPostgres = "postgres"
+	BackendArchive  = "archive"
+)
+
+// Backend 可插拔的存储后端
+type Backend interface {
+	Storage
+	Close() error
+}
+
+// Factory 根据配置创建存储后端
+type Factory func(cfg *config.ProcessorConfig) (Backend, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+func init() {
+	Register(BackendRedis, func(cfg *config.ProcessorConfig) (Backend, error) {
+		s, err := NewRedisStorage(cfg.Redis)
+		if err != nil {
+			return nil, err
+		}
+		s.SetRetention(cfg.Kline.History, cfg.Retention)
+		s.SetTickerBatch(cfg.TickerBatch)
+		s.SetTradeBuffer(cfg.TradeBuffer)
+		if migrated, err := s.MigrateKlineLists(); err != nil {
+			log.Printf("[Redis] Failed to migrate kline lists: %v\n", err)
+		} else if migrated > 0 {
+			log.Printf("[Redis] Migrated %d kline lists to sorted sets\n", migrated)
+		}
+		return s, nil
+	})
+	Register(BackendInfluxDB, func(cfg *config.ProcessorConfig) (Backend, error) {
+		return NewInfluxStorage(cfg.InfluxDB)
+	})
+	Register(BackendPostgres, func(cfg *config.ProcessorConfig) (Backend, error) {
+		return NewPostgresStorage(cfg.Postgres)
+	})
+}
+
+// Register 注册存储后端，重复注册时覆盖
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[name] = factory
+}
+
+// Registered 已注册的后端名称
+func Registered() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Backends 配置启用的后端名称，第一个为主存储
+// 未配置 storage.backends 时使用 Redis 作为主存储，并按各存储自身的 enable 开关追加历史存储
+func Backends(cfg *config.ProcessorConfig) []string {
+	if len(cfg.Storage.Backends) > 0 {
+		return cfg.Storage.Backends
+	}
+
+	names := []string{BackendRedis}
+	if cfg.InfluxDB.Enable {
+		names = append(names, BackendInfluxDB)
+	}
+	if cfg.Postgres.Enable {
+		names = append(names, BackendPostgres)
+	}
+	if cfg.Archive.Enable {
+		names = append(names, BackendArchive)
+	}
+	return names
+}
+
+// Open 按配置创建所有存储后端，任一后端创建失败时关闭已创建的后端并返回错误
+func Open(cfg *config.ProcessorConfig) (*FanoutStorage, error) {
+	names := Backends(cfg)
+	backends := make([]Backend, 0, len(names))
+
+	closeAll := func() {
+		for i := len(backends) - 1; i >= 0; i-- {
+			backends[i].Close()
+		}
+	}
+
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			closeAll()
+			return nil, fmt.Errorf("storage backend %s configured more than once", name)
+		}
+		seen[name] = true
+
+		factoriesMu.RLock()
+		factory, ok := factories[name]
+		factoriesMu.RUnlock()
+		if !ok {
+			closeAll()
+			return nil, fmt.Errorf("unknown storage backend %s (available: %v)", name, Registered())
+		}
+
+		backend, err := factory(cfg)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("failed to open storage backend %s: %w", name, err)
+		}
+		backends = append(backends, backend)
+	}
+
+	return newFanoutStorage(names, backends), nil
+}
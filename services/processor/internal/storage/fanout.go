@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"fmt"
+	"log"
+	"market-system/common/models"
+	"sync"
+)
+
+// Storage 行情数据存储
+type Storage interface {
+	SaveKline(kline *models.Kline) error
+	SaveTicker(ticker *models.Ticker) error
+	SaveDepth(depth *models.OrderBook) error
+	SaveTrade(trade *models.Trade) error
+}
+
+// FanoutStorage 同时写入多个存储后端
+// 第一个后端为主存储，其错误返回给调用方；其余后端的错误（包括 panic）只记录日志和统计，不影响主流程和其他后端
+type FanoutStorage struct {
+	backends []*fanoutBackend
+
+	mu    sync.Mutex
+	stats map[string]*BackendStats
+}
+
+// fanoutBackend 带名称的存储后端
+type fanoutBackend struct {
+	name    string
+	backend Backend
+}
+
+// BackendStats 单个后端的写入错误统计
+type BackendStats struct {
+	Errors int64 // 返回错误的写入次数
+	Panics int64 // 发生 panic 的写入次数
+}
+
+// newFanoutStorage 创建多路写入存储，names 与 backends 一一对应
+func newFanoutStorage(names []string, backends []Backend) *FanoutStorage {
+	s := &FanoutStorage{stats: make(map[string]*BackendStats)}
+	for i, backend := range backends {
+		s.backends = append(s.backends, &fanoutBackend{name: names[i], backend: backend})
+		s.stats[names[i]] = &BackendStats{}
+	}
+	return s
+}
+
+// Backend 按名称获取后端，未启用时返回 nil
+func (s *FanoutStorage) Backend(name string) Backend {
+	for _, b := range s.backends {
+		if b.name == name {
+			return b.backend
+		}
+	}
+	return nil
+}
+
+// Names 已启用的后端名称，第一个为主存储
+func (s *FanoutStorage) Names() []string {
+	names := make([]string, 0, len(s.backends))
+	for _, b := range s.backends {
+		names = append(names, b.name)
+	}
+	return names
+}
+
+// SaveKline 保存K线数据
+func (s *FanoutStorage) SaveKline(kline *models.Kline) error {
+	return s.each("kline", func(b Backend) error { return b.SaveKline(kline) })
+}
+
+// klineBatchStorage 支持批量保存K线的后端
+type klineBatchStorage interface {
+	SaveKlines(klines []*models.Kline) error
+}
+
+// SaveKlines 批量保存K线数据，后端不支持批量写入时逐根保存
+func (s *FanoutStorage) SaveKlines(klines []*models.Kline) error {
+	return s.each("kline", func(b Backend) error {
+		if batch, ok := b.(klineBatchStorage); ok {
+			return batch.SaveKlines(klines)
+		}
+		for _, kline := range klines {
+			if err := b.SaveKline(kline); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SaveTicker 保存Ticker数据
+func (s *FanoutStorage) SaveTicker(ticker *models.Ticker) error {
+	return s.each("ticker", func(b Backend) error { return b.SaveTicker(ticker) })
+}
+
+// SaveDepth 保存深度数据
+func (s *FanoutStorage) SaveDepth(depth *models.OrderBook) error {
+	return s.each("depth", func(b Backend) error { return b.SaveDepth(depth) })
+}
+
+// SaveTrade 保存交易数据
+func (s *FanoutStorage) SaveTrade(trade *models.Trade) error {
+	return s.each("trade", func(b Backend) error { return b.SaveTrade(trade) })
+}
+
+// Stats 获取各后端的错误统计
+func (s *FanoutStorage) Stats() map[string]BackendStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make(map[string]BackendStats, len(s.stats))
+	for name, stat := range s.stats {
+		stats[name] = *stat
+	}
+	return stats
+}
+
+// Close 按创建的逆序关闭所有后端（辅助存储关闭前写入缓冲区中剩余的数据，主存储最后关闭）
+func (s *FanoutStorage) Close() error {
+	var firstErr error
+	for i := len(s.backends) - 1; i >= 0; i-- {
+		b := s.backends[i]
+		if err := b.backend.Close(); err != nil {
+			log.Printf("[Storage] Failed to close %s: %v\n", b.name, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// each 依次写入所有后端，返回主存储的错误
+func (s *FanoutStorage) each(dataType string, save func(Backend) error) error {
+	var primaryErr error
+	for i, b := range s.backends {
+		err := s.call(b, save)
+		if err == nil {
+			continue
+		}
+		if i == 0 {
+			primaryErr = err
+			continue
+		}
+		log.Printf("[Storage] Failed to save %s to %s: %v\n", dataType, b.name, err)
+	}
+	return primaryErr
+}
+
+// call 写入单个后端，panic 转换为错误，避免一个后端的故障影响其他后端
+func (s *FanoutStorage) call(b *fanoutBackend, save func(Backend) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic in %s backend: %v", b.name, r)
+			s.mu.Lock()
+			s.stats[b.name].Panics++
+			s.mu.Unlock()
+		}
+	}()
+
+	if err = save(b.backend); err != nil {
+		s.mu.Lock()
+		s.stats[b.name].Errors++
+		s.mu.Unlock()
+	}
+	return err
+}
@@ -0,0 +1,474 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"market-system/common/config"
+	"market-system/common/models"
+	"market-system/common/sanitize"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InfluxDB measurement
+const (
+	measurementKline  = "kline"
+	measurementTicker = "ticker"
+	measurementTrade  = "trade"
+)
+
+// InfluxStorage InfluxDB 存储（InfluxDB 2.x HTTP 写入接口）
+// K线、成交、Ticker 以行协议写入，交易对/周期作为 tag。
+// 写入先进入缓冲区，达到批量大小或刷新间隔时批量提交；缓冲区满时丢弃新数据，不阻塞处理流程。
+type InfluxStorage struct {
+	cfg       config.InfluxDBConfig
+	client    *http.Client
+	sanitizer *sanitize.Sanitizer
+
+	mu      sync.Mutex
+	buffer  []string // 待写入的行协议数据
+	flushCh chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	stats InfluxStats
+}
+
+// InfluxStats 写入统计
+type InfluxStats struct {
+	Written int64 // 写入成功的点数
+	Failed  int64 // 写入失败的点数
+	Dropped int64 // 缓冲区满丢弃的点数
+	Pending int   // 缓冲区中待写入的点数
+}
+
+// NewInfluxStorage 创建 InfluxDB 存储，并按配置设置 bucket 的保留策略
+func NewInfluxStorage(cfg config.InfluxDBConfig) (*InfluxStorage, error) {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1000
+	}
+	if cfg.FlushIntervalMs <= 0 {
+		cfg.FlushIntervalMs = 1000
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &InfluxStorage{
+		cfg:       cfg,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		sanitizer: sanitize.New(sanitize.StageSerialize),
+		buffer:    make([]string, 0, cfg.BatchSize),
+		flushCh:   make(chan struct{}, 1),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	if err := s.ensureBucket(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	s.wg.Add(1)
+	go s.flushLoop()
+
+	log.Printf("[InfluxDB] Connected to %s (bucket: %s, retention: %dd)\n", cfg.URL, cfg.Bucket, cfg.RetentionDays)
+	return s, nil
+}
+
+// SaveKline 写入K线，tag: symbol, interval
+func (s *InfluxStorage) SaveKline(kline *models.Kline) error {
+	if !s.sanitizer.Kline(kline.Symbol, kline) {
+		return nil
+	}
+
+	line := newLine(measurementKline).
+		tag("symbol", kline.Symbol).
+		tag("interval", kline.Interval).
+		floatField("open", kline.Open).
+		floatField("high", kline.High).
+		floatField("low", kline.Low).
+		floatField("close", kline.Close).
+		floatField("volume", kline.Volume).
+		floatField("quote_vol", kline.QuoteVol).
+		intField("trade_num", kline.TradeNum).
+		intField("revision", kline.Revision).
+		build(kline.OpenTime)
+	return s.write(line)
+}
+
+// SaveTicker 写入 Ticker，tag: symbol
+func (s *InfluxStorage) SaveTicker(ticker *models.Ticker) error {
+	if !s.sanitizer.Ticker(ticker.Symbol, ticker) {
+		return nil
+	}
+
+	line := newLine(measurementTicker).
+		tag("symbol", ticker.Symbol).
+		floatField("last_price", ticker.LastPrice).
+		floatField("bid_price", ticker.BidPrice).
+		floatField("ask_price", ticker.AskPrice).
+		floatField("high_24h", ticker.High24h).
+		floatField("low_24h", ticker.Low24h).
+		floatField("volume_24h", ticker.Volume24h).
+		floatField("open_24h", ticker.Open24h).
+		floatField("price_change_24h", ticker.PriceChange24h).
+		floatField("price_change_percent_24h", ticker.PriceChangePercent24h).
+		intField("trade_count_24h", ticker.TradeCount24h).
+		build(ticker.Timestamp)
+	return s.write(line)
+}
+
+// SaveTrade 写入成交，tag: symbol, side
+func (s *InfluxStorage) SaveTrade(trade *models.Trade) error {
+	if !s.sanitizer.Trade(trade.Symbol, trade) {
+		return nil
+	}
+
+	line := newLine(measurementTrade).
+		tag("symbol", trade.Symbol).
+		tag("side", trade.Side).
+		floatField("price", trade.Price).
+		floatField("amount", trade.Amount).
+		stringField("trade_id", trade.TradeID).
+		build(trade.Timestamp)
+	return s.write(line)
+}
+
+// SaveDepth 深度数据变化频繁且只关心最新状态，不写入 InfluxDB
+func (s *InfluxStorage) SaveDepth(depth *models.OrderBook) error {
+	return nil
+}
+
+// Stats 获取写入统计
+func (s *InfluxStorage) Stats() InfluxStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.stats
+	stats.Pending = len(s.buffer)
+	return stats
+}
+
+// SanitizeStats 获取序列化前的清洗统计
+func (s *InfluxStorage) SanitizeStats() map[string]sanitize.Stats {
+	return s.sanitizer.Stats()
+}
+
+// Close 写入缓冲区中剩余的数据并停止
+func (s *InfluxStorage) Close() error {
+	s.cancel()
+	s.wg.Wait()
+	return nil
+}
+
+// write 写入缓冲区，达到批量大小时触发提交
+func (s *InfluxStorage) write(line string) error {
+	s.mu.Lock()
+	// 缓冲区上限为批量大小的 10 倍，InfluxDB 不可用时避免内存无限增长
+	if len(s.buffer) >= s.cfg.BatchSize*10 {
+		s.stats.Dropped++
+		dropped := s.stats.Dropped
+		s.mu.Unlock()
+		if dropped%100 == 1 {
+			log.Printf("[InfluxDB] Buffer full, dropped %d points\n", dropped)
+		}
+		return nil
+	}
+	s.buffer = append(s.buffer, line)
+	full := len(s.buffer) >= s.cfg.BatchSize
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.flushCh <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// flushLoop 按刷新间隔或批量大小提交缓冲区，退出前提交剩余数据
+func (s *InfluxStorage) flushLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(time.Duration(s.cfg.FlushIntervalMs) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			s.flush()
+			return
+		case <-ticker.C:
+			s.flush()
+		case <-s.flushCh:
+			s.flush()
+		}
+	}
+}
+
+// flush 分批提交缓冲区中的数据
+func (s *InfluxStorage) flush() {
+	s.mu.Lock()
+	lines := s.buffer
+	s.buffer = make([]string, 0, s.cfg.BatchSize)
+	s.mu.Unlock()
+
+	for start := 0; start < len(lines); start += s.cfg.BatchSize {
+		end := start + s.cfg.BatchSize
+		if end > len(lines) {
+			end = len(lines)
+		}
+		batch := lines[start:end]
+
+		err := s.send(batch)
+
+		s.mu.Lock()
+		if err != nil {
+			s.stats.Failed += int64(len(batch))
+		} else {
+			s.stats.Written += int64(len(batch))
+		}
+		s.mu.Unlock()
+
+		if err != nil {
+			log.Printf("[InfluxDB] Failed to write %d points: %v\n", len(batch), err)
+		}
+	}
+}
+
+// send 提交一批行协议数据（时间精度：毫秒）
+func (s *InfluxStorage) send(lines []string) error {
+	query := url.Values{}
+	query.Set("org", s.cfg.Org)
+	query.Set("bucket", s.cfg.Bucket)
+	query.Set("precision", "ms")
+
+	body := strings.Join(lines, "\n")
+	status, respBody, err := s.request(context.Background(), http.MethodPost, "/api/v2/write?"+query.Encode(), "text/plain; charset=utf-8", []byte(body))
+	if err != nil {
+		return err
+	}
+	if status != http.StatusNoContent {
+		return fmt.Errorf("unexpected status %d: %s", status, respBody)
+	}
+	return nil
+}
+
+// ensureBucket 确保 bucket 存在，并将保留策略更新为配置值
+func (s *InfluxStorage) ensureBucket() error {
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
+	defer cancel()
+
+	query := url.Values{}
+	query.Set("org", s.cfg.Org)
+	query.Set("name", s.cfg.Bucket)
+
+	status, body, err := s.request(ctx, http.MethodGet, "/api/v2/buckets?"+query.Encode(), "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to influxdb: %w", err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("failed to get bucket: status %d: %s", status, body)
+	}
+
+	var result struct {
+		Buckets []influxBucket `json:"buckets"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("failed to parse buckets: %w", err)
+	}
+
+	rules := s.retentionRules()
+
+	// bucket 不存在时创建
+	if len(result.Buckets) == 0 {
+		orgID, err := s.orgID(ctx)
+		if err != nil {
+			return err
+		}
+		payload, _ := json.Marshal(map[string]interface{}{
+			"orgID":          orgID,
+			"name":           s.cfg.Bucket,
+			"retentionRules": rules,
+		})
+		status, body, err := s.request(ctx, http.MethodPost, "/api/v2/buckets", "application/json", payload)
+		if err != nil {
+			return fmt.Errorf("failed to create bucket: %w", err)
+		}
+		if status != http.StatusCreated {
+			return fmt.Errorf("failed to create bucket: status %d: %s", status, body)
+		}
+		log.Printf("[InfluxDB] Created bucket %s\n", s.cfg.Bucket)
+		return nil
+	}
+
+	// 保留策略与配置不一致时更新
+	bucket := result.Buckets[0]
+	if bucket.retentionSeconds() == s.retentionSeconds() {
+		return nil
+	}
+	payload, _ := json.Marshal(map[string]interface{}{
+		"retentionRules": rules,
+	})
+	status, body, err = s.request(ctx, http.MethodPatch, "/api/v2/buckets/"+bucket.ID, "application/json", payload)
+	if err != nil {
+		return fmt.Errorf("failed to update retention: %w", err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("failed to update retention: status %d: %s", status, body)
+	}
+	log.Printf("[InfluxDB] Updated retention of bucket %s to %dd\n", s.cfg.Bucket, s.cfg.RetentionDays)
+	return nil
+}
+
+// orgID 查询组织 ID
+func (s *InfluxStorage) orgID(ctx context.Context) (string, error) {
+	query := url.Values{}
+	query.Set("org", s.cfg.Org)
+
+	status, body, err := s.request(ctx, http.MethodGet, "/api/v2/orgs?"+query.Encode(), "", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get org: %w", err)
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("failed to get org: status %d: %s", status, body)
+	}
+
+	var result struct {
+		Orgs []struct {
+			ID string `json:"id"`
+		} `json:"orgs"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to parse orgs: %w", err)
+	}
+	if len(result.Orgs) == 0 {
+		return "", fmt.Errorf("org %s not found", s.cfg.Org)
+	}
+	return result.Orgs[0].ID, nil
+}
+
+// retentionSeconds 配置的保留时长（秒），0 表示永久保留
+func (s *InfluxStorage) retentionSeconds() int64 {
+	if s.cfg.RetentionDays <= 0 {
+		return 0
+	}
+	return int64(s.cfg.RetentionDays) * 24 * 3600
+}
+
+// retentionRules 保留策略，永久保留时为空
+func (s *InfluxStorage) retentionRules() []influxRetentionRule {
+	seconds := s.retentionSeconds()
+	if seconds == 0 {
+		return []influxRetentionRule{}
+	}
+	return []influxRetentionRule{{Type: "expire", EverySeconds: seconds}}
+}
+
+// request 发送 InfluxDB API 请求
+func (s *InfluxStorage) request(ctx context.Context, method, path, contentType string, body []byte) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(s.cfg.URL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", "Token "+s.cfg.Token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, respBody, nil
+}
+
+// influxBucket bucket 信息
+type influxBucket struct {
+	ID             string                `json:"id"`
+	RetentionRules []influxRetentionRule `json:"retentionRules"`
+}
+
+// retentionSeconds bucket 当前的保留时长（秒）
+func (b influxBucket) retentionSeconds() int64 {
+	for _, rule := range b.RetentionRules {
+		if rule.Type == "expire" {
+			return rule.EverySeconds
+		}
+	}
+	return 0
+}
+
+// influxRetentionRule 保留策略
+type influxRetentionRule struct {
+	Type         string `json:"type"`
+	EverySeconds int64  `json:"everySeconds"`
+}
+
+// lineBuilder 行协议构造器
+type lineBuilder struct {
+	measurement string
+	tags        []string
+	fields      []string
+}
+
+func newLine(measurement string) *lineBuilder {
+	return &lineBuilder{measurement: measurement}
+}
+
+// tag 添加 tag，空值不写入
+func (b *lineBuilder) tag(key, value string) *lineBuilder {
+	if value != "" {
+		b.tags = append(b.tags, key+"="+tagEscaper.Replace(value))
+	}
+	return b
+}
+
+func (b *lineBuilder) floatField(key string, value float64) *lineBuilder {
+	b.fields = append(b.fields, key+"="+strconv.FormatFloat(value, 'f', -1, 64))
+	return b
+}
+
+func (b *lineBuilder) intField(key string, value int64) *lineBuilder {
+	b.fields = append(b.fields, key+"="+strconv.FormatInt(value, 10)+"i")
+	return b
+}
+
+func (b *lineBuilder) stringField(key, value string) *lineBuilder {
+	b.fields = append(b.fields, key+"=\""+fieldEscaper.Replace(value)+"\"")
+	return b
+}
+
+// build 生成行协议，timestamp 为毫秒时间戳
+func (b *lineBuilder) build(timestamp int64) string {
+	var sb strings.Builder
+	sb.WriteString(b.measurement)
+	for _, tag := range b.tags {
+		sb.WriteByte(',')
+		sb.WriteString(tag)
+	}
+	sb.WriteByte(' ')
+	sb.WriteString(strings.Join(b.fields, ","))
+	sb.WriteByte(' ')
+	sb.WriteString(strconv.FormatInt(timestamp, 10))
+	return sb.String()
+}
+
+var (
+	tagEscaper   = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+	fieldEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`)
+)
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"log"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MigrateKlineLists 将升级前以列表存储的K线（kline:*）转换为以开盘时间为分数的有序集合，返回转换的键数
+// 启动时执行一次；多个实例同时执行时由 WATCH 保证每个键只转换一次。
+// 启动后仍为列表的键（例如旧版本实例又写入了列表）在下一次写入时转换
+func (s *RedisStorage) MigrateKlineLists() (int, error) {
+	migrated := 0
+	iter := s.client.Scan(s.ctx, 0, constants.RedisKeyKline+"*", 100).Iterator()
+	for iter.Next(s.ctx) {
+		key := iter.Val()
+		typ, err := s.client.Type(s.ctx, key).Result()
+		if err != nil {
+			return migrated, err
+		}
+		if typ != "list" {
+			continue
+		}
+		if err := s.migrateKlineKey(key); err != nil {
+			log.Printf("[Redis] Failed to migrate kline list %s: %v\n", key, err)
+			continue
+		}
+		migrated++
+	}
+	return migrated, iter.Err()
+}
+
+// migrateKlineKey 将单个K线列表转换为有序集合，保留原过期时间；键已不是列表时不处理
+func (s *RedisStorage) migrateKlineKey(key string) error {
+	return s.client.Watch(s.ctx, func(tx *redis.Tx) error {
+		typ, err := tx.Type(s.ctx, key).Result()
+		if err != nil || typ != "list" {
+			return err
+		}
+		items, err := tx.LRange(s.ctx, key, 0, -1).Result()
+		if err != nil {
+			return err
+		}
+		ttl, err := tx.PTTL(s.ctx, key).Result()
+		if err != nil {
+			return err
+		}
+
+		members := klineMembers(items)
+		_, err = tx.TxPipelined(s.ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(s.ctx, key)
+			if len(members) > 0 {
+				pipe.ZAdd(s.ctx, key, members...)
+				if ttl > 0 {
+					pipe.PExpire(s.ctx, key, ttl)
+				}
+			}
+			return nil
+		})
+		return err
+	}, key)
+}
+
+// klineMembers 将列表中的K线转换为有序集合成员，无法解析的K线丢弃
+// 旧版本可能写入同一开盘时间的多根K线，保留修订号最大的一根，修订号相同时保留靠近列表头部（后写入）的一根
+func klineMembers(items []string) []redis.Z {
+	type member struct {
+		data     string
+		revision int64
+	}
+	byOpenTime := make(map[int64]member, len(items))
+	order := make([]int64, 0, len(items))
+	for _, item := range items {
+		var k models.Kline
+		if err := utils.FromJSON(item, &k); err != nil || k.OpenTime <= 0 {
+			continue
+		}
+		m, ok := byOpenTime[k.OpenTime]
+		if !ok {
+			order = append(order, k.OpenTime)
+		} else if k.Revision <= m.revision {
+			continue
+		}
+		byOpenTime[k.OpenTime] = member{data: item, revision: k.Revision}
+	}
+
+	members := make([]redis.Z, 0, len(order))
+	for _, openTime := range order {
+		members = append(members, redis.Z{Score: float64(openTime), Member: byOpenTime[openTime].data})
+	}
+	return members
+}
+
+// isWrongType 键的类型与命令不符（K线键仍为升级前的列表）
+func isWrongType(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "WRONGTYPE")
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"market-system/common/models"
+	"market-system/common/utils"
+	"testing"
+)
+
+func TestKlineMembers(t *testing.T) {
+	item := func(openTime, revision int64, close float64) string {
+		data, _ := utils.ToJSON(&models.Kline{OpenTime: openTime, Revision: revision, Close: close})
+		return data
+	}
+
+	// 列表头部为最新写入
+	members := klineMembers([]string{
+		item(3000, 0, 3),
+		item(2000, 0, 2.5), // 重复写入，后写入的保留
+		item(2000, 0, 2),
+		item(1000, 0, 1),
+		item(1000, 1, 1.1), // 修订号更大的保留
+		"not json",
+	})
+
+	if len(members) != 3 {
+		t.Fatalf("members = %d, want 3", len(members))
+	}
+	want := map[float64]float64{3000: 3, 2000: 2.5, 1000: 1.1}
+	for _, m := range members {
+		var k models.Kline
+		if err := utils.FromJSON(m.Member.(string), &k); err != nil {
+			t.Fatal(err)
+		}
+		if float64(k.OpenTime) != m.Score || k.Close != want[m.Score] {
+			t.Errorf("score %v: kline %d close %v, want close %v", m.Score, k.OpenTime, k.Close, want[m.Score])
+		}
+	}
+}
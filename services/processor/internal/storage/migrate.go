@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+)
+
+// migrations PostgreSQL 迁移文件，按文件名顺序执行
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrate 执行尚未执行的迁移，已执行的版本记录在 schema_migrations 表中
+func Migrate(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	files, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		version := file[len("migrations/"):]
+
+		var exists bool
+		if err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, version).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check migration %s: %w", version, err)
+		}
+		if exists {
+			continue
+		}
+
+		script, err := migrations.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		if err := applyMigration(db, version, string(script)); err != nil {
+			return fmt.Errorf("failed to apply migration %s: %w", version, err)
+		}
+		log.Printf("[Postgres] Applied migration %s\n", version)
+	}
+
+	return nil
+}
+
+// applyMigration 在事务中执行单个迁移
+func applyMigration(db *sql.DB, version, script string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(script); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
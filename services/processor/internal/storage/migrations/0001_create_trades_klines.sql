@@ -0,0 +1,39 @@
+-- 成交与K线历史数据
+-- 安装了 TimescaleDB 扩展时转换为 hypertable，否则作为普通 PostgreSQL 表使用
+
+CREATE TABLE IF NOT EXISTS trades (
+    time      TIMESTAMPTZ      NOT NULL,
+    symbol    TEXT             NOT NULL,
+    trade_id  TEXT             NOT NULL,
+    price     DOUBLE PRECISION NOT NULL,
+    amount    DOUBLE PRECISION NOT NULL,
+    side      TEXT             NOT NULL DEFAULT '',
+    PRIMARY KEY (symbol, trade_id, time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_trades_symbol_time ON trades (symbol, time DESC);
+
+CREATE TABLE IF NOT EXISTS klines (
+    open_time  TIMESTAMPTZ      NOT NULL,
+    close_time TIMESTAMPTZ      NOT NULL,
+    symbol     TEXT             NOT NULL,
+    interval   TEXT             NOT NULL,
+    open       DOUBLE PRECISION NOT NULL,
+    high       DOUBLE PRECISION NOT NULL,
+    low        DOUBLE PRECISION NOT NULL,
+    close      DOUBLE PRECISION NOT NULL,
+    volume     DOUBLE PRECISION NOT NULL DEFAULT 0,
+    quote_vol  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    trade_num  BIGINT           NOT NULL DEFAULT 0,
+    PRIMARY KEY (symbol, interval, open_time)
+);
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'timescaledb') THEN
+        CREATE EXTENSION IF NOT EXISTS timescaledb;
+        PERFORM create_hypertable('trades', 'time', chunk_time_interval => INTERVAL '1 day', if_not_exists => TRUE);
+        PERFORM create_hypertable('klines', 'open_time', chunk_time_interval => INTERVAL '7 days', if_not_exists => TRUE);
+    END IF;
+END
+$$;
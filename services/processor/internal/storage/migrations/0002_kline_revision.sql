@@ -0,0 +1,5 @@
+-- K线修订号与收盘标记
+-- 回补、补齐等改写已保存的K线时 revision 递增；已有的K线均为收盘K线
+
+ALTER TABLE klines ADD COLUMN IF NOT EXISTS revision BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE klines ADD COLUMN IF NOT EXISTS is_final BOOLEAN NOT NULL DEFAULT TRUE;
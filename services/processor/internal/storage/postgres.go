@@ -0,0 +1,313 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"market-system/common/config"
+	"market-system/common/models"
+	"market-system/common/sanitize"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// PostgresStorage PostgreSQL / TimescaleDB 存储
+// 成交和K线写入 trades、klines 表（结构见 migrations 目录），供 SQL 查询历史数据。
+// 写入先进入缓冲区，按批量大小或刷新间隔批量提交；K线按 (symbol, interval, open_time) 覆盖写入。
+type PostgresStorage struct {
+	cfg       config.PostgresConfig
+	db        *sql.DB
+	sanitizer *sanitize.Sanitizer
+
+	mu      sync.Mutex
+	trades  []*models.Trade
+	klines  map[string]*models.Kline // key: symbol|interval|open_time，同一根K线只写入最新状态
+	flushCh chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	stats PostgresStats
+}
+
+// PostgresStats 写入统计
+type PostgresStats struct {
+	Trades  int64 // 写入成功的成交数
+	Klines  int64 // 写入成功的K线数
+	Failed  int64 // 写入失败的记录数
+	Dropped int64 // 缓冲区满丢弃的记录数
+}
+
+// NewPostgresStorage 创建 PostgreSQL 存储，AutoMigrate 为 true 时执行迁移
+func NewPostgresStorage(cfg config.PostgresConfig) (*PostgresStorage, error) {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	if cfg.FlushIntervalMs <= 0 {
+		cfg.FlushIntervalMs = 1000
+	}
+	if cfg.MaxConns <= 0 {
+		cfg.MaxConns = 10
+	}
+
+	db, err := sql.Open("postgres", cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open postgres: %w", err)
+	}
+	db.SetMaxOpenConns(cfg.MaxConns)
+	db.SetMaxIdleConns(cfg.MaxConns)
+	db.SetConnMaxLifetime(30 * time.Minute)
+
+	pingCtx, pingCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer pingCancel()
+	if err := db.PingContext(pingCtx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+
+	if cfg.AutoMigrate {
+		if err := Migrate(db); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &PostgresStorage{
+		cfg:       cfg,
+		db:        db,
+		sanitizer: sanitize.New(sanitize.StageSerialize),
+		klines:    make(map[string]*models.Kline),
+		flushCh:   make(chan struct{}, 1),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	s.wg.Add(1)
+	go s.flushLoop()
+
+	log.Println("[Postgres] Connected successfully")
+	return s, nil
+}
+
+// SaveKline 保存K线
+func (s *PostgresStorage) SaveKline(kline *models.Kline) error {
+	if !s.sanitizer.Kline(kline.Symbol, kline) {
+		return nil
+	}
+
+	// 复制一份，聚合器会继续修改当前K线
+	k := *kline
+	key := fmt.Sprintf("%s|%s|%d", k.Symbol, k.Interval, k.OpenTime)
+
+	s.mu.Lock()
+	if _, ok := s.klines[key]; !ok && s.pendingLocked() >= s.cfg.BatchSize*10 {
+		s.dropLocked()
+		return nil
+	}
+	s.klines[key] = &k
+	full := s.pendingLocked() >= s.cfg.BatchSize
+	s.mu.Unlock()
+
+	s.notify(full)
+	return nil
+}
+
+// SaveTrade 保存成交
+func (s *PostgresStorage) SaveTrade(trade *models.Trade) error {
+	if !s.sanitizer.Trade(trade.Symbol, trade) {
+		return nil
+	}
+
+	t := *trade
+
+	s.mu.Lock()
+	if s.pendingLocked() >= s.cfg.BatchSize*10 {
+		s.dropLocked()
+		return nil
+	}
+	s.trades = append(s.trades, &t)
+	full := s.pendingLocked() >= s.cfg.BatchSize
+	s.mu.Unlock()
+
+	s.notify(full)
+	return nil
+}
+
+// SaveTicker Ticker 只保留最新状态，不写入 PostgreSQL
+func (s *PostgresStorage) SaveTicker(ticker *models.Ticker) error {
+	return nil
+}
+
+// SaveDepth 深度只保留最新状态，不写入 PostgreSQL
+func (s *PostgresStorage) SaveDepth(depth *models.OrderBook) error {
+	return nil
+}
+
+// Stats 获取写入统计
+func (s *PostgresStorage) Stats() PostgresStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// Close 写入缓冲区中剩余的数据并关闭连接
+func (s *PostgresStorage) Close() error {
+	s.cancel()
+	s.wg.Wait()
+	return s.db.Close()
+}
+
+// pendingLocked 缓冲区中待写入的记录数（调用方需持有锁）
+func (s *PostgresStorage) pendingLocked() int {
+	return len(s.trades) + len(s.klines)
+}
+
+// dropLocked 缓冲区满时丢弃记录，释放锁
+func (s *PostgresStorage) dropLocked() {
+	s.stats.Dropped++
+	dropped := s.stats.Dropped
+	s.mu.Unlock()
+	if dropped%100 == 1 {
+		log.Printf("[Postgres] Buffer full, dropped %d records\n", dropped)
+	}
+}
+
+// notify 缓冲区达到批量大小时触发提交
+func (s *PostgresStorage) notify(full bool) {
+	if !full {
+		return
+	}
+	select {
+	case s.flushCh <- struct{}{}:
+	default:
+	}
+}
+
+// flushLoop 按刷新间隔或批量大小提交缓冲区，退出前提交剩余数据
+func (s *PostgresStorage) flushLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(time.Duration(s.cfg.FlushIntervalMs) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			s.flush()
+			return
+		case <-ticker.C:
+			s.flush()
+		case <-s.flushCh:
+			s.flush()
+		}
+	}
+}
+
+// flush 分批提交缓冲区中的成交和K线
+func (s *PostgresStorage) flush() {
+	s.mu.Lock()
+	trades := s.trades
+	klines := make([]*models.Kline, 0, len(s.klines))
+	for _, k := range s.klines {
+		klines = append(klines, k)
+	}
+	s.trades = nil
+	s.klines = make(map[string]*models.Kline)
+	s.mu.Unlock()
+
+	for start := 0; start < len(trades); start += s.cfg.BatchSize {
+		end := start + s.cfg.BatchSize
+		if end > len(trades) {
+			end = len(trades)
+		}
+		s.record(len(trades[start:end]), &s.stats.Trades, "trades", s.insertTrades(trades[start:end]))
+	}
+
+	for start := 0; start < len(klines); start += s.cfg.BatchSize {
+		end := start + s.cfg.BatchSize
+		if end > len(klines) {
+			end = len(klines)
+		}
+		s.record(len(klines[start:end]), &s.stats.Klines, "klines", s.upsertKlines(klines[start:end]))
+	}
+}
+
+// record 记录批量写入结果
+func (s *PostgresStorage) record(n int, written *int64, table string, err error) {
+	s.mu.Lock()
+	if err != nil {
+		s.stats.Failed += int64(n)
+	} else {
+		*written += int64(n)
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		log.Printf("[Postgres] Failed to write %d %s: %v\n", n, table, err)
+	}
+}
+
+// insertTrades 批量写入成交，重复成交忽略
+func (s *PostgresStorage) insertTrades(trades []*models.Trade) error {
+	const columns = 6
+	values := make([]string, 0, len(trades))
+	args := make([]interface{}, 0, len(trades)*columns)
+	for i, t := range trades {
+		values = append(values, placeholders(i*columns, columns))
+		args = append(args, time.UnixMilli(t.Timestamp).UTC(), t.Symbol, t.TradeID, t.Price, t.Amount, t.Side)
+	}
+
+	query := `INSERT INTO trades (time, symbol, trade_id, price, amount, side) VALUES ` +
+		strings.Join(values, ",") +
+		` ON CONFLICT (symbol, trade_id, time) DO NOTHING`
+	_, err := s.db.ExecContext(context.Background(), query, args...)
+	return err
+}
+
+// upsertKlines 批量写入K线，同一根K线（symbol, interval, open_time）覆盖更新并递增修订号
+func (s *PostgresStorage) upsertKlines(klines []*models.Kline) error {
+	const columns = 13
+	values := make([]string, 0, len(klines))
+	args := make([]interface{}, 0, len(klines)*columns)
+	for i, k := range klines {
+		values = append(values, placeholders(i*columns, columns))
+		args = append(args,
+			time.UnixMilli(k.OpenTime).UTC(), time.UnixMilli(k.CloseTime).UTC(), k.Symbol, k.Interval,
+			k.Open, k.High, k.Low, k.Close, k.Volume, k.QuoteVol, k.TradeNum, k.Revision, k.IsFinal)
+	}
+
+	query := `INSERT INTO klines (open_time, close_time, symbol, interval, open, high, low, close, volume, quote_vol, trade_num, revision, is_final) VALUES ` +
+		strings.Join(values, ",") +
+		` ON CONFLICT (symbol, interval, open_time) DO UPDATE SET
+			close_time = EXCLUDED.close_time,
+			open = EXCLUDED.open,
+			high = EXCLUDED.high,
+			low = EXCLUDED.low,
+			close = EXCLUDED.close,
+			volume = EXCLUDED.volume,
+			quote_vol = EXCLUDED.quote_vol,
+			trade_num = EXCLUDED.trade_num,
+			revision = GREATEST(klines.revision + 1, EXCLUDED.revision),
+			is_final = EXCLUDED.is_final`
+	_, err := s.db.ExecContext(context.Background(), query, args...)
+	return err
+}
+
+// placeholders 生成一行参数占位符，例如 ($1,$2,$3)
+func placeholders(offset, n int) string {
+	var sb strings.Builder
+	sb.WriteByte('(')
+	for i := 1; i <= n; i++ {
+		if i > 1 {
+			sb.WriteByte(',')
+		}
+		fmt.Fprintf(&sb, "$%d", offset+i)
+	}
+	sb.WriteByte(')')
+	return sb.String()
+}
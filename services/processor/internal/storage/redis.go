@@ -4,9 +4,15 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"market-system/common/codec"
+	"market-system/common/config"
 	"market-system/common/constants"
 	"market-system/common/models"
+	"market-system/common/retention"
+	"market-system/common/sanitize"
 	"market-system/common/utils"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -14,22 +20,24 @@ import (
 
 // RedisStorage Redis 存储
 type RedisStorage struct {
-	client *redis.Client
-	ctx    context.Context
+	client    *redis.Client
+	ctx       context.Context
+	sanitizer *sanitize.Sanitizer  // 序列化前的 NaN/Inf 清洗，按交易对统计
+	retention *retention.Retention // 各类数据的保留数量和过期时间
+	codec     codec.Codec          // 行情数据和推送消息的编码
+
+	tickerBatch *tickerBatch // 为 nil 表示不合并写入 Ticker
+	tradeBuffer *tradeBuffer // 为 nil 表示成交直接写入
 }
 
 // NewRedisStorage 创建 Redis 存储
-func NewRedisStorage(host string, port int, password string, db int) (*RedisStorage, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:         fmt.Sprintf("%s:%d", host, port),
-		Password:     password,
-		DB:           db,
-		DialTimeout:  5 * time.Second,
-		ReadTimeout:  3 * time.Second,
-		WriteTimeout: 3 * time.Second,
-		PoolSize:     100,
-		MinIdleConns: 10,
-	})
+func NewRedisStorage(cfg config.RedisConfig) (*RedisStorage, error) {
+	valueCodec, err := codec.New(cfg.Codec)
+	if err != nil {
+		return nil, err
+	}
+
+	client := NewRedisClient(cfg)
 
 	ctx := context.Background()
 
@@ -38,124 +46,842 @@ func NewRedisStorage(host string, port int, password string, db int) (*RedisStor
 		return nil, fmt.Errorf("failed to connect to redis: %w", err)
 	}
 
-	log.Println("[Redis] Connected successfully")
+	if cfg.Sentinel.MasterName != "" {
+		log.Printf("[Redis] Connected successfully (sentinel master: %s)\n", cfg.Sentinel.MasterName)
+	} else {
+		log.Println("[Redis] Connected successfully")
+	}
 
 	return &RedisStorage{
-		client: client,
-		ctx:    ctx,
+		client:    client,
+		ctx:       ctx,
+		sanitizer: sanitize.New(sanitize.StageSerialize),
+		retention: retention.New(nil, config.RetentionConfig{}),
+		codec:     valueCodec,
 	}, nil
 }
 
+// NewRedisClient 创建 Redis 客户端，配置了 Sentinel 时经 Sentinel 获取主节点地址，
+// 主节点故障切换后连接（包括订阅）自动重连到新的主节点
+func NewRedisClient(cfg config.RedisConfig) *redis.Client {
+	poolSize := cfg.PoolSize
+	if poolSize <= 0 {
+		poolSize = 100
+	}
+
+	if cfg.Sentinel.MasterName != "" {
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       cfg.Sentinel.MasterName,
+			SentinelAddrs:    cfg.Sentinel.Addrs,
+			SentinelPassword: cfg.Sentinel.Password,
+			Password:         cfg.Password,
+			DB:               cfg.DB,
+			DialTimeout:      5 * time.Second,
+			ReadTimeout:      3 * time.Second,
+			WriteTimeout:     3 * time.Second,
+			PoolSize:         poolSize,
+			MinIdleConns:     10,
+		})
+	}
+
+	return redis.NewClient(&redis.Options{
+		Addr:         fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		DialTimeout:  5 * time.Second,
+		ReadTimeout:  3 * time.Second,
+		WriteTimeout: 3 * time.Second,
+		PoolSize:     poolSize,
+		MinIdleConns: 10,
+	})
+}
+
+// SetRetention 设置保留策略，klineHistory 为 kline.history 配置
+func (s *RedisStorage) SetRetention(klineHistory map[string]int, cfg config.RetentionConfig) {
+	s.retention = retention.New(klineHistory, cfg)
+}
+
 // SaveKline 保存K线数据
+// K线存储在以开盘时间为分数的有序集合中，同一开盘时间只保留一根K线：
+// 已有同一根K线（回补、补齐等改写）时替换，并将 kline.Revision 设为原修订号加 1，
+// 之后写入的其他存储和推送使用同一修订号；超出保留数量时删除最早的K线
 func (s *RedisStorage) SaveKline(kline *models.Kline) error {
+	if !s.sanitizer.Kline(kline.Symbol, kline) {
+		return nil
+	}
+
 	key := fmt.Sprintf("%s%s:%s", constants.RedisKeyKline, kline.Symbol, kline.Interval)
 
-	// 将K线转换为JSON
-	data, err := utils.ToJSON(kline)
+	err := s.saveKline(key, kline)
+	if isWrongType(err) {
+		// 升级前的列表尚未迁移
+		if err := s.migrateKlineKey(key); err != nil {
+			return fmt.Errorf("failed to migrate kline list %s: %w", key, err)
+		}
+		err = s.saveKline(key, kline)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save kline to redis: %w", err)
+	}
+
+	return nil
+}
+
+// SaveKlines 批量保存已收盘的K线（补齐的空K线），在一个管道中写入
+// 已有同一开盘时间的K线时保留原K线，不替换，超出保留数量时删除最早的K线
+func (s *RedisStorage) SaveKlines(klines []*models.Kline) error {
+	var valid []*models.Kline
+	for _, kline := range klines {
+		if s.sanitizer.Kline(kline.Symbol, kline) {
+			valid = append(valid, kline)
+		}
+	}
+	if len(valid) == 0 {
+		return nil
+	}
+
+	err := s.saveKlines(valid)
+	if isWrongType(err) {
+		// 升级前的列表尚未迁移
+		for _, kline := range valid {
+			key := fmt.Sprintf("%s%s:%s", constants.RedisKeyKline, kline.Symbol, kline.Interval)
+			if err := s.migrateKlineKey(key); err != nil {
+				return fmt.Errorf("failed to migrate kline list %s: %w", key, err)
+			}
+		}
+		err = s.saveKlines(valid)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save klines to redis: %w", err)
+	}
+
+	return nil
+}
+
+// saveKlines 先查询已有的开盘时间，再写入其余K线，每个键只裁剪和设置过期时间一次
+func (s *RedisStorage) saveKlines(klines []*models.Kline) error {
+	keys := make([]string, len(klines))
+	counts := make([]*redis.IntCmd, len(klines))
+	_, err := s.client.Pipelined(s.ctx, func(pipe redis.Pipeliner) error {
+		for i, kline := range klines {
+			keys[i] = fmt.Sprintf("%s%s:%s", constants.RedisKeyKline, kline.Symbol, kline.Interval)
+			score := strconv.FormatInt(kline.OpenTime, 10)
+			counts[i] = pipe.ZCount(s.ctx, keys[i], score, score)
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	// 使用 List 存储，保留最近1000根K线
-	pipe := s.client.Pipeline()
-	pipe.LPush(s.ctx, key, data)
-	pipe.LTrim(s.ctx, key, 0, 999) // 只保留最近1000根
-	pipe.Expire(s.ctx, key, 7*24*time.Hour) // 7天过期
+	_, err = s.client.Pipelined(s.ctx, func(pipe redis.Pipeliner) error {
+		trimmed := make(map[string]bool)
+		for i, kline := range klines {
+			if counts[i].Val() > 0 {
+				continue
+			}
+			data, err := s.codec.Marshal(kline)
+			if err != nil {
+				return err
+			}
+			pipe.ZAdd(s.ctx, keys[i], redis.Z{Score: float64(kline.OpenTime), Member: data})
+		}
+		for i, kline := range klines {
+			if trimmed[keys[i]] {
+				continue
+			}
+			trimmed[keys[i]] = true
+			policy := s.retention.Policy(kline.Symbol)
+			pipe.ZRemRangeByRank(s.ctx, keys[i], 0, -policy.KlineListSize(kline.Interval)-1)
+			pipe.Expire(s.ctx, keys[i], policy.KlineExpiration(kline.Interval))
+		}
+		return nil
+	})
+	return err
+}
+
+// KlineHistorySize 交易对和周期保留的K线数
+func (s *RedisStorage) KlineHistorySize(symbol, interval string) int64 {
+	return s.retention.Policy(symbol).KlineListSize(interval)
+}
+
+// saveKline 按开盘时间写入K线，修订号在已有K线的基础上递增
+func (s *RedisStorage) saveKline(key string, kline *models.Kline) error {
+	policy := s.retention.Policy(kline.Symbol)
+	score := strconv.FormatInt(kline.OpenTime, 10)
+
+	return s.client.Watch(s.ctx, func(tx *redis.Tx) error {
+		items, err := tx.ZRangeByScore(s.ctx, key, &redis.ZRangeBy{Min: score, Max: score}).Result()
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			var existing models.Kline
+			if err := codec.Unmarshal([]byte(item), &existing); err != nil {
+				continue
+			}
+			if kline.Revision <= existing.Revision {
+				kline.Revision = existing.Revision + 1
+			}
+		}
 
-	_, err = pipe.Exec(s.ctx)
+		// 将K线转换为JSON
+		data, err := s.codec.Marshal(kline)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(s.ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZRemRangeByScore(s.ctx, key, score, score)
+			pipe.ZAdd(s.ctx, key, redis.Z{Score: float64(kline.OpenTime), Member: data})
+			pipe.ZRemRangeByRank(s.ctx, key, 0, -policy.KlineListSize(kline.Interval)-1)
+			pipe.Expire(s.ctx, key, policy.KlineExpiration(kline.Interval))
+			return nil
+		})
+		return err
+	}, key)
+}
+
+// SaveSourceKline 保存交易所推送的已收盘K线，按来源单独存储，不影响本地聚合的K线序列
+func (s *RedisStorage) SaveSourceKline(kline *models.Kline) error {
+	if !s.sanitizer.Kline(kline.Symbol, kline) {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s%s:%s:%s", constants.RedisKeyKline, kline.Symbol, kline.Interval, kline.Source)
+
+	data, err := s.codec.Marshal(kline)
 	if err != nil {
-		return fmt.Errorf("failed to save kline to redis: %w", err)
+		return err
+	}
+
+	policy := s.retention.Policy(kline.Symbol)
+	score := strconv.FormatInt(kline.OpenTime, 10)
+	save := func() error {
+		_, err := s.client.TxPipelined(s.ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZRemRangeByScore(s.ctx, key, score, score)
+			pipe.ZAdd(s.ctx, key, redis.Z{Score: float64(kline.OpenTime), Member: data})
+			pipe.ZRemRangeByRank(s.ctx, key, 0, -policy.KlineListSize(kline.Interval)-1)
+			pipe.Expire(s.ctx, key, policy.KlineExpiration(kline.Interval))
+			return nil
+		})
+		return err
+	}
+
+	err = save()
+	if isWrongType(err) {
+		if err := s.migrateKlineKey(key); err != nil {
+			return fmt.Errorf("failed to migrate kline list %s: %w", key, err)
+		}
+		err = save()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save source kline to redis: %w", err)
 	}
 
 	return nil
 }
 
+// SaveCurrentKlines 保存未收盘的K线（停机时调用），覆盖上一次保存的内容
+func (s *RedisStorage) SaveCurrentKlines(klines []*models.Kline) error {
+	pipe := s.client.TxPipeline()
+	pipe.Del(s.ctx, constants.RedisKeyKlineState)
+	for _, kline := range klines {
+		data, err := utils.ToJSON(kline)
+		if err != nil {
+			return err
+		}
+		pipe.HSet(s.ctx, constants.RedisKeyKlineState, kline.Symbol+":"+kline.Interval, data)
+	}
+	pipe.Expire(s.ctx, constants.RedisKeyKlineState, 24*time.Hour)
+
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return fmt.Errorf("failed to save current klines to redis: %w", err)
+	}
+	return nil
+}
+
+// LoadCurrentKlines 读取停机时保存的未收盘K线，读取后删除，避免异常退出后再次恢复过期的K线
+func (s *RedisStorage) LoadCurrentKlines() ([]*models.Kline, error) {
+	pipe := s.client.TxPipeline()
+	get := pipe.HGetAll(s.ctx, constants.RedisKeyKlineState)
+	pipe.Del(s.ctx, constants.RedisKeyKlineState)
+
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return nil, fmt.Errorf("failed to load current klines from redis: %w", err)
+	}
+
+	klines := make([]*models.Kline, 0, len(get.Val()))
+	for field, data := range get.Val() {
+		var kline models.Kline
+		if err := utils.FromJSON(data, &kline); err != nil {
+			log.Printf("[Redis] Invalid saved kline %s: %v\n", field, err)
+			continue
+		}
+		klines = append(klines, &kline)
+	}
+	return klines, nil
+}
+
+// SaveRollingState 保存滚动 Ticker 的分桶状态（停机时调用），覆盖上一次保存的内容
+func (s *RedisStorage) SaveRollingState(states map[string]string) error {
+	return s.saveHash(constants.RedisKeyRollingState, states)
+}
+
+// LoadRollingState 读取停机时保存的滚动 Ticker 分桶状态，读取后删除
+func (s *RedisStorage) LoadRollingState() (map[string]string, error) {
+	return s.takeHash(constants.RedisKeyRollingState)
+}
+
+// SavePartitionRollingState 保存分区中交易对的滚动 Ticker 分桶状态（分区被收回时调用），覆盖上一次保存的内容
+func (s *RedisStorage) SavePartitionRollingState(partition int, states map[string]string) error {
+	return s.saveHash(constants.RedisKeyPartitionRollingState+strconv.Itoa(partition), states)
+}
+
+// LoadPartitionRollingState 读取分区保存的滚动 Ticker 分桶状态，读取后删除
+func (s *RedisStorage) LoadPartitionRollingState(partition int) (map[string]string, error) {
+	return s.takeHash(constants.RedisKeyPartitionRollingState + strconv.Itoa(partition))
+}
+
+// saveHash 以 fields 覆盖 Hash，24 小时后过期
+func (s *RedisStorage) saveHash(key string, fields map[string]string) error {
+	pipe := s.client.TxPipeline()
+	pipe.Del(s.ctx, key)
+	for field, value := range fields {
+		pipe.HSet(s.ctx, key, field, value)
+	}
+	pipe.Expire(s.ctx, key, 24*time.Hour)
+
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return fmt.Errorf("failed to save %s to redis: %w", key, err)
+	}
+	return nil
+}
+
+// takeHash 读取并删除 Hash
+func (s *RedisStorage) takeHash(key string) (map[string]string, error) {
+	pipe := s.client.TxPipeline()
+	get := pipe.HGetAll(s.ctx, key)
+	pipe.Del(s.ctx, key)
+
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return nil, fmt.Errorf("failed to load %s from redis: %w", key, err)
+	}
+	return get.Val(), nil
+}
+
+// partitionOffsetField 分区状态中记录消费位置的字段
+const partitionOffsetField = "offset"
+
+// SavePartitionKlines 保存分区中交易对的未收盘K线及对应的消费位置（分区被收回时调用），覆盖上一次保存的内容
+func (s *RedisStorage) SavePartitionKlines(partition int, offset int64, klines []*models.Kline) error {
+	key := constants.RedisKeyPartitionState + strconv.Itoa(partition)
+
+	pipe := s.client.TxPipeline()
+	pipe.Del(s.ctx, key)
+	pipe.HSet(s.ctx, key, partitionOffsetField, offset)
+	for _, kline := range klines {
+		data, err := utils.ToJSON(kline)
+		if err != nil {
+			return err
+		}
+		pipe.HSet(s.ctx, key, kline.Symbol+":"+kline.Interval, data)
+	}
+	pipe.Expire(s.ctx, key, 24*time.Hour)
+
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return fmt.Errorf("failed to save partition %d klines to redis: %w", partition, err)
+	}
+	return nil
+}
+
+// LoadPartitionKlines 读取分区保存的未收盘K线和消费位置，读取后删除；没有保存的状态时位置为 -1
+func (s *RedisStorage) LoadPartitionKlines(partition int) ([]*models.Kline, int64, error) {
+	key := constants.RedisKeyPartitionState + strconv.Itoa(partition)
+
+	pipe := s.client.TxPipeline()
+	get := pipe.HGetAll(s.ctx, key)
+	pipe.Del(s.ctx, key)
+
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return nil, -1, fmt.Errorf("failed to load partition %d klines from redis: %w", partition, err)
+	}
+
+	offset := int64(-1)
+	klines := make([]*models.Kline, 0, len(get.Val()))
+	for field, data := range get.Val() {
+		if field == partitionOffsetField {
+			if v, err := strconv.ParseInt(data, 10, 64); err == nil {
+				offset = v
+			}
+			continue
+		}
+
+		var kline models.Kline
+		if err := utils.FromJSON(data, &kline); err != nil {
+			log.Printf("[Redis] Invalid saved kline %s in partition %d: %v\n", field, partition, err)
+			continue
+		}
+		klines = append(klines, &kline)
+	}
+	return klines, offset, nil
+}
+
+// PublishKline 推送K线更新（实时K线与收盘K线）
+func (s *RedisStorage) PublishKline(update *models.KlineUpdate) error {
+	data, err := s.codec.Marshal(update)
+	if err != nil {
+		return err
+	}
+
+	// 每个周期（含合成的更高周期和秒级周期）单独推送，与 WebSocket 频道 kline:{symbol}:{interval} 对应
+	channel := constants.RedisChannelMarket + utils.IntervalChannel(constants.DataTypeKline, update.Symbol, update.Interval)
+	if err := s.client.Publish(s.ctx, channel, data).Err(); err != nil {
+		return fmt.Errorf("failed to publish kline: %w", err)
+	}
+	return nil
+}
+
+// SaveDerivedKline 推送由K线计算的平均K线、砖块，已收盘的同时保存到 {dataType}:{symbol}:{interval}
+// dataType 为 kline_ha 或 kline_renko，推送频道为 market:{dataType}:{symbol}:{interval}
+func (s *RedisStorage) SaveDerivedKline(dataType string, update *models.KlineUpdate) error {
+	data, err := s.codec.Marshal(update)
+	if err != nil {
+		return err
+	}
+
+	if update.Closed {
+		key := fmt.Sprintf("%s:%s:%s", dataType, update.Symbol, update.Interval)
+		kline, err := s.codec.Marshal(&update.Kline)
+		if err != nil {
+			return err
+		}
+		policy := s.retention.Policy(update.Symbol)
+		score := strconv.FormatInt(update.OpenTime, 10)
+		_, err = s.client.TxPipelined(s.ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZRemRangeByScore(s.ctx, key, score, score)
+			pipe.ZAdd(s.ctx, key, redis.Z{Score: float64(update.OpenTime), Member: kline})
+			pipe.ZRemRangeByRank(s.ctx, key, 0, -policy.KlineListSize(update.Interval)-1)
+			pipe.Expire(s.ctx, key, policy.KlineExpiration(update.Interval))
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save %s to redis: %w", dataType, err)
+		}
+	}
+
+	channel := constants.RedisChannelMarket + utils.IntervalChannel(dataType, update.Symbol, update.Interval)
+	if err := s.client.Publish(s.ctx, channel, data).Err(); err != nil {
+		return fmt.Errorf("failed to publish %s: %w", dataType, err)
+	}
+	return nil
+}
+
+// LatestDerivedKline 获取最近保存的平均K线或砖块，没有时返回 nil
+func (s *RedisStorage) LatestDerivedKline(dataType, symbol, interval string) (*models.Kline, error) {
+	key := fmt.Sprintf("%s:%s:%s", dataType, symbol, interval)
+	items, err := s.client.ZRevRange(s.ctx, key, 0, 0).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s from redis: %w", dataType, err)
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	var kline models.Kline
+	if err := codec.Unmarshal([]byte(items[0]), &kline); err != nil {
+		return nil, err
+	}
+	return &kline, nil
+}
+
+// SaveReferencePrice 保存参考价格并推送
+func (s *RedisStorage) SaveReferencePrice(price *models.ReferencePrice) error {
+	data, err := s.codec.Marshal(price)
+	if err != nil {
+		return err
+	}
+
+	key := constants.RedisKeyTWAP + price.Symbol
+	if err := s.client.Set(s.ctx, key, data, 5*time.Minute).Err(); err != nil {
+		return fmt.Errorf("failed to save reference price to redis: %w", err)
+	}
+
+	// 推送到 WebSocket twap:{symbol} 频道
+	channel := constants.RedisChannelMarket + constants.DataTypeTWAP + ":" + price.Symbol
+	s.client.Publish(s.ctx, channel, data)
+
+	return nil
+}
+
+// SaveVWAP 保存成交量加权平均价格并推送
+func (s *RedisStorage) SaveVWAP(price *models.VWAPPrice) error {
+	data, err := s.codec.Marshal(price)
+	if err != nil {
+		return err
+	}
+
+	key := constants.RedisKeyVWAP + price.Symbol
+	if err := s.client.Set(s.ctx, key, data, 5*time.Minute).Err(); err != nil {
+		return fmt.Errorf("failed to save vwap to redis: %w", err)
+	}
+
+	// 推送到 WebSocket vwap:{symbol} 频道
+	channel := constants.RedisChannelMarket + constants.DataTypeVWAP + ":" + price.Symbol
+	s.client.Publish(s.ctx, channel, data)
+
+	return nil
+}
+
+// SaveIndexPrice 保存指数价格并推送
+func (s *RedisStorage) SaveIndexPrice(price *models.IndexPrice) error {
+	data, err := s.codec.Marshal(price)
+	if err != nil {
+		return err
+	}
+
+	key := constants.RedisKeyIndex + price.Symbol
+	if err := s.client.Set(s.ctx, key, data, 5*time.Minute).Err(); err != nil {
+		return fmt.Errorf("failed to save index price to redis: %w", err)
+	}
+
+	// 推送到 WebSocket index:{symbol} 频道
+	channel := constants.RedisChannelMarket + constants.DataTypeIndex + ":" + price.Symbol
+	s.client.Publish(s.ctx, channel, data)
+
+	return nil
+}
+
+// SaveIndicators 保存技术指标并推送
+func (s *RedisStorage) SaveIndicators(ind *models.Indicators) error {
+	data, err := s.codec.Marshal(ind)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("%s%s:%s", constants.RedisKeyIndicator, ind.Symbol, ind.Interval)
+	if err := s.client.Set(s.ctx, key, data, utils.KlineTTL(ind.Interval)).Err(); err != nil {
+		return fmt.Errorf("failed to save indicators to redis: %w", err)
+	}
+
+	// 推送到 WebSocket indicator:{symbol}:{interval} 频道
+	channel := constants.RedisChannelMarket + utils.IntervalChannel(constants.DataTypeIndicator, ind.Symbol, ind.Interval)
+	s.client.Publish(s.ctx, channel, data)
+
+	return nil
+}
+
+// SavePriceBand 保存价格带并推送（参考价格更新和涨跌停状态变化）
+func (s *RedisStorage) SavePriceBand(band *models.PriceBand) error {
+	data, err := s.codec.Marshal(band)
+	if err != nil {
+		return err
+	}
+
+	key := constants.RedisKeyBand + band.Symbol
+	if err := s.client.Set(s.ctx, key, data, 1*time.Hour).Err(); err != nil {
+		return fmt.Errorf("failed to save price band to redis: %w", err)
+	}
+
+	// 推送到 WebSocket band:{symbol} 频道
+	channel := constants.RedisChannelMarket + constants.DataTypeBand + ":" + band.Symbol
+	s.client.Publish(s.ctx, channel, data)
+
+	return nil
+}
+
+// SaveAlert 推送异常检测告警，告警不保存
+func (s *RedisStorage) SaveAlert(alert *models.Alert) error {
+	data, err := s.codec.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	// 推送到 WebSocket alerts 频道，由 API 同时转发到 alerts:{symbol}
+	channel := constants.RedisChannelMarket + constants.DataTypeAlerts
+	if err := s.client.Publish(s.ctx, channel, data).Err(); err != nil {
+		return fmt.Errorf("failed to publish alert: %w", err)
+	}
+	return nil
+}
+
+// SavePriceAlertEvent 推送用户价格提醒的触发通知
+func (s *RedisStorage) SavePriceAlertEvent(event *models.PriceAlertEvent) error {
+	data, err := s.codec.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	// 推送到 WebSocket price_alert:{id} 频道
+	channel := constants.RedisChannelMarket + constants.DataTypePriceAlert + ":" + event.AlertID
+	if err := s.client.Publish(s.ctx, channel, data).Err(); err != nil {
+		return fmt.Errorf("failed to publish price alert: %w", err)
+	}
+	return nil
+}
+
+// SaveAggTrade 保存聚合成交并推送
+func (s *RedisStorage) SaveAggTrade(agg *models.AggTrade) error {
+	data, err := s.codec.Marshal(agg)
+	if err != nil {
+		return err
+	}
+
+	key := constants.RedisKeyAggTrade + agg.Symbol
+	policy := s.retention.Policy(agg.Symbol)
+	pipe := s.client.Pipeline()
+	pipe.LPush(s.ctx, key, data)
+	pipe.LTrim(s.ctx, key, 0, policy.AggTradeSize-1) // 只保留最近的聚合成交
+	pipe.Expire(s.ctx, key, policy.AggTradeTTL)
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return fmt.Errorf("failed to save agg trade to redis: %w", err)
+	}
+
+	// 推送到 WebSocket agg_trade:{symbol} 频道
+	channel := constants.RedisChannelMarket + constants.DataTypeAggTrade + ":" + agg.Symbol
+	s.client.Publish(s.ctx, channel, data)
+
+	return nil
+}
+
 // SaveTicker 保存Ticker数据
+// 保存、设置过期时间和推送在同一个管道中发送；开启合并写入的交易对加入批量写入，在合并窗口结束时写入最新的 Ticker
 func (s *RedisStorage) SaveTicker(ticker *models.Ticker) error {
-	key := constants.RedisKeyTicker + ticker.Symbol
+	if !s.sanitizer.Ticker(ticker.Symbol, ticker) {
+		return nil
+	}
 
-	// 使用 Hash 存储
-	data := map[string]interface{}{
-		"last_price": ticker.LastPrice,
-		"bid_price":  ticker.BidPrice,
-		"ask_price":  ticker.AskPrice,
-		"high_24h":   ticker.High24h,
-		"low_24h":    ticker.Low24h,
-		"volume_24h": ticker.Volume24h,
-		"timestamp":  ticker.Timestamp,
+	if s.tickerBatch != nil && s.tickerBatch.add(ticker) {
+		return nil
 	}
 
-	if err := s.client.HSet(s.ctx, key, data).Err(); err != nil {
+	pipe := s.client.Pipeline()
+	if err := s.writeTicker(pipe, ticker); err != nil {
+		return err
+	}
+	if _, err := pipe.Exec(s.ctx); err != nil {
 		return fmt.Errorf("failed to save ticker to redis: %w", err)
 	}
 
+	return nil
+}
+
+// SaveTickerSource 保存带来源明细的 Ticker（内部/外部成交量、最新价来源），过期时间与 Ticker 相同，不推送
+func (s *RedisStorage) SaveTickerSource(ticker *models.TickerWithSource) error {
+	data, err := s.codec.Marshal(ticker)
+	if err != nil {
+		return err
+	}
+
+	key := constants.RedisKeyTickerSource + ticker.Symbol
+	if err := s.client.Set(s.ctx, key, data, s.retention.Policy(ticker.Symbol).TickerTTL).Err(); err != nil {
+		return fmt.Errorf("failed to save ticker source to redis: %w", err)
+	}
+	return nil
+}
+
+// SaveDepthSource 保存按档位标注来源的融合深度，过期时间与深度相同，不推送
+func (s *RedisStorage) SaveDepthSource(depth *models.OrderBookWithSource) error {
+	data, err := s.codec.Marshal(depth)
+	if err != nil {
+		return err
+	}
+
+	key := constants.RedisKeyDepthSource + depth.Symbol
+	if err := s.client.Set(s.ctx, key, data, s.retention.Policy(depth.Symbol).DepthTTL).Err(); err != nil {
+		return fmt.Errorf("failed to save depth source to redis: %w", err)
+	}
+	return nil
+}
+
+// SaveBookTicker 保存最优买卖价并推送，过期时间与深度相同
+func (s *RedisStorage) SaveBookTicker(ticker *models.BookTicker) error {
+	data, err := s.codec.Marshal(ticker)
+	if err != nil {
+		return err
+	}
+
+	key := constants.RedisKeyBookTicker + ticker.Symbol
+	if err := s.client.Set(s.ctx, key, data, s.retention.Policy(ticker.Symbol).DepthTTL).Err(); err != nil {
+		return fmt.Errorf("failed to save book ticker to redis: %w", err)
+	}
+
+	// 推送到 WebSocket book_ticker:{symbol} 频道
+	channel := constants.RedisChannelMarket + constants.DataTypeBookTicker + ":" + ticker.Symbol
+	s.client.Publish(s.ctx, channel, data)
+
+	return nil
+}
+
+// SaveBookStats 保存订单簿统计并推送，历史只保留最近 historySize 条，过期时间与深度相同
+func (s *RedisStorage) SaveBookStats(stats *models.BookStats, historySize int) error {
+	data, err := s.codec.Marshal(stats)
+	if err != nil {
+		return err
+	}
+
+	key := constants.RedisKeyBookStats + stats.Symbol
+	pipe := s.client.Pipeline()
+	pipe.LPush(s.ctx, key, data)
+	pipe.LTrim(s.ctx, key, 0, int64(historySize)-1)
+	pipe.Expire(s.ctx, key, s.retention.Policy(stats.Symbol).DepthTTL)
+
+	// 推送到 WebSocket bookstats:{symbol} 频道
+	channel := constants.RedisChannelMarket + constants.DataTypeBookStats + ":" + stats.Symbol
+	pipe.Publish(s.ctx, channel, data)
+
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return fmt.Errorf("failed to save book stats to redis: %w", err)
+	}
+	return nil
+}
+
+// SaveMarketStats 在同一个管道中保存各交易对的 24 小时统计，过期时间与 Ticker 相同
+func (s *RedisStorage) SaveMarketStats(stats []*models.MarketStats) error {
+	pipe := s.client.Pipeline()
+	for _, st := range stats {
+		data, err := s.codec.Marshal(st)
+		if err != nil {
+			return err
+		}
+		key := constants.RedisKeyMarketStats + st.Symbol
+		pipe.Set(s.ctx, key, data, s.retention.Policy(st.Symbol).TickerTTL)
+	}
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return fmt.Errorf("failed to save 24h stats to redis: %w", err)
+	}
+	return nil
+}
+
+// writeTicker 将保存、设置过期时间和推送 Ticker 的命令加入管道
+func (s *RedisStorage) writeTicker(pipe redis.Pipeliner, ticker *models.Ticker) error {
+	payload, err := s.codec.Marshal(ticker)
+	if err != nil {
+		return err
+	}
+
+	key := constants.RedisKeyTicker + ticker.Symbol
+
+	// 使用 Hash 存储
+	data := map[string]interface{}{
+		"last_price":               ticker.LastPrice,
+		"bid_price":                ticker.BidPrice,
+		"ask_price":                ticker.AskPrice,
+		"high_24h":                 ticker.High24h,
+		"low_24h":                  ticker.Low24h,
+		"volume_24h":               ticker.Volume24h,
+		"open_24h":                 ticker.Open24h,
+		"price_change_24h":         ticker.PriceChange24h,
+		"price_change_percent_24h": ticker.PriceChangePercent24h,
+		"trade_count_24h":          ticker.TradeCount24h,
+		"timestamp":                ticker.Timestamp,
+	}
+	pipe.HSet(s.ctx, key, data)
+
 	// 设置过期时间
-	s.client.Expire(s.ctx, key, 1*time.Hour)
+	pipe.Expire(s.ctx, key, s.retention.Policy(ticker.Symbol).TickerTTL)
 
-	// 发布到 Redis Pub/Sub
-	channel := fmt.Sprintf("%s%s:%s", constants.RedisChannelMarket, ticker.Symbol, constants.DataTypeTicker)
-	jsonData, _ := utils.ToJSON(ticker)
-	s.client.Publish(s.ctx, channel, jsonData)
+	// 更新行情排行，Ticker 过期的交易对由 API 查询时移除
+	pipe.ZAdd(s.ctx, constants.RedisKeyScreener+constants.ScreenerSortChange, redis.Z{Score: ticker.PriceChangePercent24h, Member: ticker.Symbol})
+	pipe.ZAdd(s.ctx, constants.RedisKeyScreener+constants.ScreenerSortVolume, redis.Z{Score: ticker.Volume24h, Member: ticker.Symbol})
+	pipe.ZAdd(s.ctx, constants.RedisKeyScreener+constants.ScreenerSortTradeCount, redis.Z{Score: float64(ticker.TradeCount24h), Member: ticker.Symbol})
+
+	// 发布到 Redis Pub/Sub，频道 market:ticker:{symbol}
+	channel := constants.RedisChannelMarket + utils.MarketChannel(constants.DataTypeTicker, ticker.Symbol)
+	pipe.Publish(s.ctx, channel, payload)
 
 	return nil
 }
 
 // SaveDepth 保存深度数据
 func (s *RedisStorage) SaveDepth(depth *models.OrderBook) error {
+	s.sanitizer.OrderBook(depth.Symbol, depth)
+
 	key := constants.RedisKeyDepth + depth.Symbol
 
 	// 将深度数据转换为JSON
-	data, err := utils.ToJSON(depth)
+	data, err := s.codec.Marshal(depth)
 	if err != nil {
 		return err
 	}
 
 	// 使用 String 存储
-	if err := s.client.Set(s.ctx, key, data, 1*time.Hour).Err(); err != nil {
+	if err := s.client.Set(s.ctx, key, data, s.retention.Policy(depth.Symbol).DepthTTL).Err(); err != nil {
 		return fmt.Errorf("failed to save depth to redis: %w", err)
 	}
 
-	// 发布到 Redis Pub/Sub
-	channel := fmt.Sprintf("%s%s:%s", constants.RedisChannelMarket, depth.Symbol, constants.DataTypeDepth)
+	// 发布到 Redis Pub/Sub，频道 market:depth:{symbol}
+	channel := constants.RedisChannelMarket + utils.MarketChannel(constants.DataTypeDepth, depth.Symbol)
 	s.client.Publish(s.ctx, channel, data)
 
 	return nil
 }
 
+// SaveAggregatedDepth 保存按价格精度聚合的深度
+func (s *RedisStorage) SaveAggregatedDepth(depth *models.OrderBook, precision string) error {
+	key := constants.RedisKeyDepth + depth.Symbol + ":" + precision
+
+	data, err := s.codec.Marshal(depth)
+	if err != nil {
+		return err
+	}
+
+	if err := s.client.Set(s.ctx, key, data, s.retention.Policy(depth.Symbol).DepthTTL).Err(); err != nil {
+		return fmt.Errorf("failed to save aggregated depth to redis: %w", err)
+	}
+
+	return nil
+}
+
 // SaveTrade 保存交易数据
 func (s *RedisStorage) SaveTrade(trade *models.Trade) error {
+	if !s.sanitizer.Trade(trade.Symbol, trade) {
+		return nil
+	}
+
 	key := constants.RedisKeyTrade + trade.Symbol
 
 	// 将交易转换为JSON
-	data, err := utils.ToJSON(trade)
+	data, err := s.codec.Marshal(trade)
 	if err != nil {
 		return err
 	}
 
-	// 使用 List 存储最近的交易记录
-	pipe := s.client.Pipeline()
-	pipe.LPush(s.ctx, key, data)
-	pipe.LTrim(s.ctx, key, 0, 99) // 只保留最近100条
-	pipe.Expire(s.ctx, key, 1*time.Hour)
+	// 使用 List 存储最近的交易记录，开启延迟批量写入时加入缓冲
+	policy := s.retention.Policy(trade.Symbol)
+	if s.tradeBuffer != nil {
+		s.tradeBuffer.add(trade.Symbol, data, policy.TradeSize)
+	} else {
+		pipe := s.client.Pipeline()
+		pipe.LPush(s.ctx, key, data)
+		pipe.LTrim(s.ctx, key, 0, policy.TradeSize-1) // 只保留最近的成交
+		pipe.Expire(s.ctx, key, policy.TradeTTL)
 
-	_, err = pipe.Exec(s.ctx)
-	if err != nil {
-		return fmt.Errorf("failed to save trade to redis: %w", err)
+		if _, err := pipe.Exec(s.ctx); err != nil {
+			return fmt.Errorf("failed to save trade to redis: %w", err)
+		}
 	}
 
-	// 发布到 Redis Pub/Sub
-	channel := fmt.Sprintf("%s%s:%s", constants.RedisChannelMarket, trade.Symbol, constants.DataTypeTrade)
+	// 发布到 Redis Pub/Sub，频道 market:trade:{symbol}
+	channel := constants.RedisChannelMarket + utils.MarketChannel(constants.DataTypeTrade, trade.Symbol)
 	s.client.Publish(s.ctx, channel, data)
 
 	return nil
 }
 
-// GetKlines 获取K线数据
-func (s *RedisStorage) GetKlines(symbol, interval string, limit int64) ([]*models.Kline, error) {
+// GetKlines 获取开盘时间在 [startTime, endTime] 内最近的 limit 根K线，按开盘时间倒序
+// startTime、endTime 为 0 表示不限制
+func (s *RedisStorage) GetKlines(symbol, interval string, startTime, endTime, limit int64) ([]*models.Kline, error) {
 	key := fmt.Sprintf("%s%s:%s", constants.RedisKeyKline, symbol, interval)
 
-	// 从 List 中获取数据
-	results, err := s.client.LRange(s.ctx, key, 0, limit-1).Result()
+	opt := &redis.ZRangeBy{Min: "-inf", Max: "+inf", Count: limit}
+	if startTime > 0 {
+		opt.Min = strconv.FormatInt(startTime, 10)
+	}
+	if endTime > 0 {
+		opt.Max = strconv.FormatInt(endTime, 10)
+	}
+	results, err := s.client.ZRevRangeByScore(s.ctx, key, opt).Result()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get klines from redis: %w", err)
 	}
@@ -163,7 +889,7 @@ func (s *RedisStorage) GetKlines(symbol, interval string, limit int64) ([]*model
 	klines := make([]*models.Kline, 0, len(results))
 	for _, data := range results {
 		var kline models.Kline
-		if err := utils.FromJSON(data, &kline); err != nil {
+		if err := codec.Unmarshal([]byte(data), &kline); err != nil {
 			continue
 		}
 		klines = append(klines, &kline)
@@ -208,6 +934,18 @@ func (s *RedisStorage) GetTicker(symbol string) (*models.Ticker, error) {
 	if val, ok := data["volume_24h"]; ok {
 		fmt.Sscanf(val, "%f", &ticker.Volume24h)
 	}
+	if val, ok := data["open_24h"]; ok {
+		fmt.Sscanf(val, "%f", &ticker.Open24h)
+	}
+	if val, ok := data["price_change_24h"]; ok {
+		fmt.Sscanf(val, "%f", &ticker.PriceChange24h)
+	}
+	if val, ok := data["price_change_percent_24h"]; ok {
+		fmt.Sscanf(val, "%f", &ticker.PriceChangePercent24h)
+	}
+	if val, ok := data["trade_count_24h"]; ok {
+		fmt.Sscanf(val, "%d", &ticker.TradeCount24h)
+	}
 	if val, ok := data["timestamp"]; ok {
 		fmt.Sscanf(val, "%d", &ticker.Timestamp)
 	}
@@ -225,14 +963,352 @@ func (s *RedisStorage) GetDepth(symbol string) (*models.OrderBook, error) {
 	}
 
 	var depth models.OrderBook
-	if err := utils.FromJSON(data, &depth); err != nil {
+	if err := codec.Unmarshal([]byte(data), &depth); err != nil {
 		return nil, err
 	}
 
 	return &depth, nil
 }
 
+// EnabledSymbols 获取交易对注册表中启用且未软删除的交易对
+func (s *RedisStorage) EnabledSymbols() ([]string, error) {
+	configs, err := s.symbolConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]string, 0, len(configs))
+	for _, cfg := range configs {
+		if cfg.Enable && !cfg.Deleted {
+			symbols = append(symbols, cfg.Symbol)
+		}
+	}
+	return symbols, nil
+}
+
+// DeletedSymbols 获取交易对注册表中已软删除的交易对
+func (s *RedisStorage) DeletedSymbols() (map[string]bool, error) {
+	configs, err := s.symbolConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := make(map[string]bool)
+	for _, cfg := range configs {
+		if cfg.Deleted {
+			deleted[cfg.Symbol] = true
+		}
+	}
+	return deleted, nil
+}
+
+// InternalOnlySymbols 获取仅使用内部数据（INTERNAL_ONLY）的交易对
+func (s *RedisStorage) InternalOnlySymbols() (map[string]bool, error) {
+	configs, err := s.symbolConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make(map[string]bool)
+	for _, cfg := range configs {
+		if cfg.Mode == constants.ModeInternalOnly && !cfg.Deleted {
+			symbols[cfg.Symbol] = true
+		}
+	}
+	return symbols, nil
+}
+
+// ExternalOnlySymbols 获取交易对注册表中启用的仅使用外部数据的交易对，value 为外部数据源交易所（可能为空）
+func (s *RedisStorage) ExternalOnlySymbols() (map[string]string, error) {
+	configs, err := s.symbolConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make(map[string]string)
+	for _, cfg := range configs {
+		if cfg.Mode == constants.ModeExternalOnly && cfg.Enable && !cfg.Deleted {
+			symbols[cfg.Symbol] = cfg.ExternalSource
+		}
+	}
+	return symbols, nil
+}
+
+// SaveConsistencyReport 保存K线一致性检查报告（覆盖上一次的报告）
+func (s *RedisStorage) SaveConsistencyReport(report *models.KlineConsistencyReport) error {
+	data, err := utils.ToJSON(report)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Set(s.ctx, constants.RedisKeyConsistencyReport, data, 24*time.Hour).Err(); err != nil {
+		return fmt.Errorf("failed to save consistency report to redis: %w", err)
+	}
+	return nil
+}
+
+// AddMarketTotals 累加行情系统单日的内部成交汇总（增量），多个实例可同时累加
+func (s *RedisStorage) AddMarketTotals(date string, totals []*models.DailyTradeTotals) error {
+	key := constants.RedisKeyMarketTotals + date
+	pipe := s.client.Pipeline()
+	for _, t := range totals {
+		pipe.HIncrBy(s.ctx, key, t.Symbol+":trade_count", t.TradeCount)
+		pipe.HIncrByFloat(s.ctx, key, t.Symbol+":volume", t.Volume)
+		pipe.HIncrByFloat(s.ctx, key, t.Symbol+":quote_volume", t.QuoteVolume)
+	}
+	pipe.Expire(s.ctx, key, constants.DailyTotalsTTL*time.Millisecond)
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return fmt.Errorf("failed to add market totals to redis: %w", err)
+	}
+	return nil
+}
+
+// MarketTotals 获取行情系统单日的内部成交汇总，key 为交易对
+func (s *RedisStorage) MarketTotals(date string) (map[string]*models.DailyTradeTotals, error) {
+	data, err := s.client.HGetAll(s.ctx, constants.RedisKeyMarketTotals+date).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get market totals from redis: %w", err)
+	}
+
+	totals := make(map[string]*models.DailyTradeTotals)
+	for field, value := range data {
+		idx := strings.LastIndex(field, ":")
+		if idx <= 0 {
+			continue
+		}
+		symbol := field[:idx]
+		t, ok := totals[symbol]
+		if !ok {
+			t = &models.DailyTradeTotals{Symbol: symbol}
+			totals[symbol] = t
+		}
+		switch field[idx+1:] {
+		case "trade_count":
+			t.TradeCount, _ = strconv.ParseInt(value, 10, 64)
+		case "volume":
+			t.Volume, _ = strconv.ParseFloat(value, 64)
+		case "quote_volume":
+			t.QuoteVolume, _ = strconv.ParseFloat(value, 64)
+		}
+	}
+	return totals, nil
+}
+
+// EngineTotals 获取撮合引擎推送的单日成交汇总，key 为交易对，没有推送时为空
+func (s *RedisStorage) EngineTotals(date string) (map[string]*models.DailyTradeTotals, error) {
+	data, err := s.client.HGetAll(s.ctx, constants.RedisKeyEngineTotals+date).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get engine totals from redis: %w", err)
+	}
+
+	totals := make(map[string]*models.DailyTradeTotals, len(data))
+	for symbol, raw := range data {
+		var t models.DailyTradeTotals
+		if err := utils.FromJSON(raw, &t); err != nil {
+			continue
+		}
+		t.Symbol = symbol
+		totals[symbol] = &t
+	}
+	return totals, nil
+}
+
+// SaveTradeReconcileReport 保存成交对账报告（同一日期覆盖）
+func (s *RedisStorage) SaveTradeReconcileReport(report *models.TradeReconcileReport) error {
+	data, err := utils.ToJSON(report)
+	if err != nil {
+		return err
+	}
+	pipe := s.client.Pipeline()
+	pipe.HSet(s.ctx, constants.RedisKeyTradeReconcileReport, report.Date, data)
+	pipe.Expire(s.ctx, constants.RedisKeyTradeReconcileReport, constants.DailyTotalsTTL*time.Millisecond)
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return fmt.Errorf("failed to save trade reconcile report to redis: %w", err)
+	}
+	return nil
+}
+
+// TradeReconciled 指定日期是否已完成成交对账（报告中取得了撮合引擎的汇总）
+func (s *RedisStorage) TradeReconciled(date string) (bool, error) {
+	raw, err := s.client.HGet(s.ctx, constants.RedisKeyTradeReconcileReport, date).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get trade reconcile report from redis: %w", err)
+	}
+
+	var report models.TradeReconcileReport
+	if err := utils.FromJSON(raw, &report); err != nil {
+		return false, nil
+	}
+	return report.Source != "", nil
+}
+
+// symbolConfigs 读取交易对注册表，忽略无法解析的配置
+func (s *RedisStorage) symbolConfigs() ([]*models.SymbolConfig, error) {
+	data, err := s.client.HGetAll(s.ctx, constants.RedisKeySymbolConfig).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbol configs from redis: %w", err)
+	}
+
+	configs := make([]*models.SymbolConfig, 0, len(data))
+	for symbol, raw := range data {
+		var cfg models.SymbolConfig
+		if err := utils.FromJSON(raw, &cfg); err != nil {
+			continue
+		}
+		cfg.Symbol = symbol
+		configs = append(configs, &cfg)
+	}
+	return configs, nil
+}
+
+// PriceAlerts 获取全部用户价格提醒
+func (s *RedisStorage) PriceAlerts() ([]*models.PriceAlert, error) {
+	data, err := s.client.HGetAll(s.ctx, constants.RedisKeyPriceAlert).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price alerts from redis: %w", err)
+	}
+
+	alerts := make([]*models.PriceAlert, 0, len(data))
+	for id, raw := range data {
+		var alert models.PriceAlert
+		if err := utils.FromJSON(raw, &alert); err != nil {
+			continue
+		}
+		alert.ID = id
+		alerts = append(alerts, &alert)
+	}
+	return alerts, nil
+}
+
+// DeletePriceAlert 删除已触发的价格提醒
+func (s *RedisStorage) DeletePriceAlert(id string) error {
+	if err := s.client.HDel(s.ctx, constants.RedisKeyPriceAlert, id).Err(); err != nil {
+		return fmt.Errorf("failed to delete price alert: %w", err)
+	}
+	return nil
+}
+
+// Subscribe 订阅频道，ctx 取消后关闭返回的通道
+func (s *RedisStorage) Subscribe(ctx context.Context, channel string) <-chan string {
+	pubsub := s.client.Subscribe(ctx, channel)
+	messages := make(chan string)
+
+	go func() {
+		defer close(messages)
+		defer pubsub.Close()
+
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case messages <- msg.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return messages
+}
+
+// SubscribePattern 按模式订阅频道，ctx 取消后关闭返回的通道
+func (s *RedisStorage) SubscribePattern(ctx context.Context, pattern string) <-chan *redis.Message {
+	pubsub := s.client.PSubscribe(ctx, pattern)
+	messages := make(chan *redis.Message)
+
+	go func() {
+		defer close(messages)
+		defer pubsub.Close()
+
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case messages <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return messages
+}
+
+// SaveWebhookStats 保存 Webhook 推送统计
+func (s *RedisStorage) SaveWebhookStats(stats *models.WebhookStats) error {
+	data, err := utils.ToJSON(stats)
+	if err != nil {
+		return err
+	}
+
+	key := constants.RedisKeyServiceStats + constants.ServiceWebhook
+	if err := s.client.Set(s.ctx, key, data, constants.ServiceStatsTTL*time.Millisecond).Err(); err != nil {
+		return fmt.Errorf("failed to save webhook stats to redis: %w", err)
+	}
+	return nil
+}
+
+// SavePriceAlertWebhookStats 保存价格提醒的 Webhook 推送统计
+func (s *RedisStorage) SavePriceAlertWebhookStats(stats *models.WebhookStats) error {
+	data, err := utils.ToJSON(stats)
+	if err != nil {
+		return err
+	}
+
+	key := constants.RedisKeyPriceAlertWebhookStats
+	if err := s.client.Set(s.ctx, key, data, constants.ServiceStatsTTL*time.Millisecond).Err(); err != nil {
+		return fmt.Errorf("failed to save price alert webhook stats to redis: %w", err)
+	}
+	return nil
+}
+
+// SaveServiceStats 保存服务运行统计
+func (s *RedisStorage) SaveServiceStats(stats *models.ServiceStats) error {
+	data, err := utils.ToJSON(stats)
+	if err != nil {
+		return err
+	}
+
+	key := constants.RedisKeyServiceStats + stats.Service
+	if err := s.client.Set(s.ctx, key, data, constants.ServiceStatsTTL*time.Millisecond).Err(); err != nil {
+		return fmt.Errorf("failed to save service stats to redis: %w", err)
+	}
+	return nil
+}
+
+// Ping 检查 Redis 连接
+func (s *RedisStorage) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+// SanitizeStats 获取序列化前清洗统计（按交易对）
+func (s *RedisStorage) SanitizeStats() map[string]sanitize.Stats {
+	return s.sanitizer.Stats()
+}
+
 // Close 关闭连接
 func (s *RedisStorage) Close() error {
+	if s.tickerBatch != nil {
+		s.tickerBatch.stop()
+	}
+	if s.tradeBuffer != nil {
+		s.tradeBuffer.stop()
+	}
 	return s.client.Close()
 }
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"log"
+	"market-system/common/config"
+	"market-system/common/models"
+	"sync"
+	"time"
+)
+
+// tickerBatch Ticker 合并写入
+// 合并窗口内同一交易对只保留最新的 Ticker（之前的 Ticker 已被取代，不再写入和推送），
+// 窗口结束时全部交易对在同一个管道中写入，将每条消息 3 个命令的往返合并为每个窗口一次。
+// 进程异常退出时最多丢失一个窗口内的 Ticker，正常停止时写入剩余的 Ticker
+type tickerBatch struct {
+	storage *RedisStorage
+	window  time.Duration
+	symbols map[string]bool // 为 nil 表示全部交易对
+
+	mu      sync.Mutex
+	pending map[string]*models.Ticker
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// SetTickerBatch 按配置开启 Ticker 合并写入（创建后调用一次）
+func (s *RedisStorage) SetTickerBatch(cfg config.TickerBatchConfig) {
+	if !cfg.Enable {
+		return
+	}
+	s.tickerBatch = newTickerBatch(s, cfg)
+	go s.tickerBatch.run()
+	log.Printf("[Redis] Ticker batching enabled, window %v\n", s.tickerBatch.window)
+}
+
+// newTickerBatch 创建 Ticker 合并写入
+func newTickerBatch(s *RedisStorage, cfg config.TickerBatchConfig) *tickerBatch {
+	if cfg.WindowMs <= 0 {
+		cfg.WindowMs = 5
+	}
+	b := &tickerBatch{
+		storage: s,
+		window:  time.Duration(cfg.WindowMs) * time.Millisecond,
+		pending: make(map[string]*models.Ticker),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	if len(cfg.Symbols) > 0 {
+		b.symbols = make(map[string]bool, len(cfg.Symbols))
+		for _, symbol := range cfg.Symbols {
+			b.symbols[symbol] = true
+		}
+	}
+	return b
+}
+
+// add 加入合并写入，交易对未开启合并写入时返回 false
+func (b *tickerBatch) add(ticker *models.Ticker) bool {
+	if b.symbols != nil && !b.symbols[ticker.Symbol] {
+		return false
+	}
+
+	t := *ticker
+	b.mu.Lock()
+	b.pending[t.Symbol] = &t
+	b.mu.Unlock()
+	return true
+}
+
+// run 每个合并窗口写入一次，直到 stop
+func (b *tickerBatch) run() {
+	defer close(b.stopped)
+
+	ticker := time.NewTicker(b.window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			b.flush()
+			return
+		case <-ticker.C:
+			b.flush()
+		}
+	}
+}
+
+// take 取出待写入的 Ticker
+func (b *tickerBatch) take() map[string]*models.Ticker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.pending) == 0 {
+		return nil
+	}
+	pending := b.pending
+	b.pending = make(map[string]*models.Ticker, len(pending))
+	return pending
+}
+
+// flush 在同一个管道中写入全部待写入的 Ticker
+func (b *tickerBatch) flush() {
+	pending := b.take()
+	if len(pending) == 0 {
+		return
+	}
+
+	s := b.storage
+	pipe := s.client.Pipeline()
+	for _, t := range pending {
+		if err := s.writeTicker(pipe, t); err != nil {
+			log.Printf("[Redis] Failed to encode ticker %s: %v\n", t.Symbol, err)
+		}
+	}
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		log.Printf("[Redis] Failed to flush %d tickers: %v\n", len(pending), err)
+	}
+}
+
+// stop 停止合并写入并写入剩余的 Ticker
+func (b *tickerBatch) stop() {
+	close(b.done)
+	<-b.stopped
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"market-system/common/config"
+	"market-system/common/models"
+	"testing"
+)
+
+func TestTickerBatchCoalesce(t *testing.T) {
+	b := newTickerBatch(nil, config.TickerBatchConfig{Enable: true, Symbols: []string{"BTCUSDT", "ETHUSDT"}})
+
+	ticker := &models.Ticker{Symbol: "BTCUSDT", LastPrice: 100}
+	if !b.add(ticker) {
+		t.Fatal("BTCUSDT not batched")
+	}
+	ticker.LastPrice = 999 // 加入后修改不影响待写入的 Ticker
+	b.add(&models.Ticker{Symbol: "BTCUSDT", LastPrice: 101})
+	b.add(&models.Ticker{Symbol: "ETHUSDT", LastPrice: 10})
+	if b.add(&models.Ticker{Symbol: "DOGEUSDT", LastPrice: 1}) {
+		t.Error("DOGEUSDT should not be batched")
+	}
+
+	pending := b.take()
+	if len(pending) != 2 || pending["BTCUSDT"].LastPrice != 101 || pending["ETHUSDT"].LastPrice != 10 {
+		t.Errorf("pending = %+v", pending)
+	}
+	if pending := b.take(); pending != nil {
+		t.Errorf("pending after take = %+v", pending)
+	}
+
+	all := newTickerBatch(nil, config.TickerBatchConfig{Enable: true})
+	if !all.add(&models.Ticker{Symbol: "DOGEUSDT"}) {
+		t.Error("all symbols should be batched when symbols is empty")
+	}
+	if all.window.Milliseconds() != 5 {
+		t.Errorf("default window = %v", all.window)
+	}
+}
@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"log"
+	"market-system/common/config"
+	"market-system/common/constants"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tradeBuffer 最近成交列表的延迟批量写入（write-behind）
+// 成交按交易对累积，达到 MaxBatch 条或每隔 FlushIntervalMs 在同一个管道中写入，推送不经过缓冲。
+// 每个交易对最多缓冲最近成交列表保留的条数（更早的成交写入后也会被裁剪），
+// 写入失败的成交在下一次写入时重试（部分命令已执行时可能重复写入）。
+// 进程异常退出时丢失尚未写入的成交：最近 FlushIntervalMs 内、最多 MaxBatch 条（Redis 写入失败重试期间除外）；
+// 正常停止时写入全部缓冲的成交
+type tradeBuffer struct {
+	storage  *RedisStorage
+	interval time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	pending map[string][][]byte // 交易对 -> 编码后的成交，按时间升序
+	count   int
+	stats   TradeBufferStats
+
+	trigger chan struct{} // 达到 maxBatch 时通知立即写入
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// TradeBufferStats 成交缓冲统计（累计）
+type TradeBufferStats struct {
+	Buffered int64 // 加入缓冲的成交数
+	Written  int64 // 写入 Redis 的成交数
+	Trimmed  int64 // 超过列表保留条数、无需写入的成交数
+	Batches  int64 // 写入次数
+	Failed   int64 // 写入失败次数
+	Pending  int   // 当前缓冲的成交数
+}
+
+// SetTradeBuffer 按配置开启成交延迟批量写入（创建后调用一次）
+func (s *RedisStorage) SetTradeBuffer(cfg config.TradeBufferConfig) {
+	if !cfg.Enable {
+		return
+	}
+	s.tradeBuffer = newTradeBuffer(s, cfg)
+	go s.tradeBuffer.run()
+	log.Printf("[Redis] Trade write-behind enabled, flush every %v or %d trades\n",
+		s.tradeBuffer.interval, s.tradeBuffer.maxBatch)
+}
+
+// TradeBufferStats 获取成交缓冲统计，未开启时返回 false
+func (s *RedisStorage) TradeBufferStats() (TradeBufferStats, bool) {
+	if s.tradeBuffer == nil {
+		return TradeBufferStats{}, false
+	}
+	return s.tradeBuffer.Stats(), true
+}
+
+// newTradeBuffer 创建成交缓冲
+func newTradeBuffer(s *RedisStorage, cfg config.TradeBufferConfig) *tradeBuffer {
+	if cfg.FlushIntervalMs <= 0 {
+		cfg.FlushIntervalMs = 100
+	}
+	if cfg.MaxBatch <= 0 {
+		cfg.MaxBatch = 1000
+	}
+	return &tradeBuffer{
+		storage:  s,
+		interval: time.Duration(cfg.FlushIntervalMs) * time.Millisecond,
+		maxBatch: cfg.MaxBatch,
+		pending:  make(map[string][][]byte),
+		trigger:  make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// add 加入缓冲，limit 为交易对最近成交列表保留的条数
+func (b *tradeBuffer) add(symbol string, data []byte, limit int64) {
+	b.mu.Lock()
+	items := append(b.pending[symbol], data)
+	b.count++
+	if trimmed := int64(len(items)) - limit; trimmed > 0 {
+		items = items[trimmed:]
+		b.count -= int(trimmed)
+		b.stats.Trimmed += trimmed
+	}
+	b.pending[symbol] = items
+	b.stats.Buffered++
+	full := b.count >= b.maxBatch
+	b.mu.Unlock()
+
+	if full {
+		select {
+		case b.trigger <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run 按间隔或缓冲达到 maxBatch 时写入，直到 stop
+func (b *tradeBuffer) run() {
+	defer close(b.stopped)
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			b.flush()
+			return
+		case <-ticker.C:
+			b.flush()
+		case <-b.trigger:
+			b.flush()
+		}
+	}
+}
+
+// take 取出缓冲的成交
+func (b *tradeBuffer) take() map[string][][]byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.count == 0 {
+		return nil
+	}
+	pending := b.pending
+	b.pending = make(map[string][][]byte, len(pending))
+	b.count = 0
+	return pending
+}
+
+// restore 将写入失败的成交放回缓冲（早于之后加入的成交），仍按列表保留条数裁剪
+func (b *tradeBuffer) restore(failed map[string][][]byte) {
+	s := b.storage
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stats.Failed++
+	for symbol, items := range failed {
+		items = append(items, b.pending[symbol]...)
+		if trimmed := int64(len(items)) - s.retention.Policy(symbol).TradeSize; trimmed > 0 {
+			items = items[trimmed:]
+			b.stats.Trimmed += trimmed
+		}
+		b.count += len(items) - len(b.pending[symbol])
+		b.pending[symbol] = items
+	}
+}
+
+// flush 在同一个管道中写入全部缓冲的成交
+func (b *tradeBuffer) flush() {
+	pending := b.take()
+	if len(pending) == 0 {
+		return
+	}
+
+	s := b.storage
+	written := 0
+	_, err := s.client.Pipelined(s.ctx, func(pipe redis.Pipeliner) error {
+		for symbol, items := range pending {
+			key := constants.RedisKeyTrade + symbol
+			policy := s.retention.Policy(symbol)
+
+			values := make([]interface{}, len(items))
+			for i, item := range items {
+				values[i] = item
+			}
+			// 按时间升序 LPUSH，最新的成交位于列表头部
+			pipe.LPush(s.ctx, key, values...)
+			pipe.LTrim(s.ctx, key, 0, policy.TradeSize-1)
+			pipe.Expire(s.ctx, key, policy.TradeTTL)
+			written += len(items)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("[Redis] Failed to flush %d trades: %v\n", written, err)
+		b.restore(pending)
+		return
+	}
+
+	b.mu.Lock()
+	b.stats.Written += int64(written)
+	b.stats.Batches++
+	b.mu.Unlock()
+}
+
+// Stats 获取统计
+func (b *tradeBuffer) Stats() TradeBufferStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := b.stats
+	stats.Pending = b.count
+	return stats
+}
+
+// stop 停止缓冲并写入剩余的成交
+func (b *tradeBuffer) stop() {
+	close(b.done)
+	<-b.stopped
+}
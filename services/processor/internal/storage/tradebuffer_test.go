@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"market-system/common/config"
+	"market-system/common/retention"
+	"testing"
+)
+
+func TestTradeBuffer(t *testing.T) {
+	s := &RedisStorage{retention: retention.New(nil, config.RetentionConfig{
+		Symbols: map[string]config.RetentionPolicy{"ETHUSDT": {TradeListSize: 2}},
+	})}
+	b := newTradeBuffer(s, config.TradeBufferConfig{Enable: true, MaxBatch: 3})
+
+	b.add("BTCUSDT", []byte("b1"), 100)
+	b.add("ETHUSDT", []byte("e1"), 2)
+	b.add("ETHUSDT", []byte("e2"), 2)
+	b.add("ETHUSDT", []byte("e3"), 2) // 超过保留条数，e1 不再写入
+
+	select {
+	case <-b.trigger:
+	default:
+		t.Error("flush not triggered at max batch")
+	}
+	if stats := b.Stats(); stats.Pending != 3 || stats.Trimmed != 1 || stats.Buffered != 4 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	failed := b.take()
+	if got := failed["ETHUSDT"]; len(got) != 2 || string(got[0]) != "e2" || string(got[1]) != "e3" {
+		t.Fatalf("ETHUSDT pending = %q", got)
+	}
+
+	// 写入失败期间加入的成交排在失败的成交之后
+	b.add("ETHUSDT", []byte("e4"), 2)
+	b.restore(failed)
+	pending := b.take()
+	if got := pending["ETHUSDT"]; len(got) != 2 || string(got[0]) != "e3" || string(got[1]) != "e4" {
+		t.Errorf("ETHUSDT after restore = %q", got)
+	}
+	if got := pending["BTCUSDT"]; len(got) != 1 {
+		t.Errorf("BTCUSDT after restore = %q", got)
+	}
+	if stats := b.Stats(); stats.Pending != 0 || stats.Failed != 1 || stats.Trimmed != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
@@ -0,0 +1,255 @@
+package tiering
+
+import (
+	"log"
+	"market-system/common/config"
+	"market-system/common/constants"
+	"sync"
+	"time"
+)
+
+// Tier 交易对活跃度分级
+type Tier string
+
+const (
+	TierHot  Tier = "hot"  // 活跃交易对，深度全速处理
+	TierCold Tier = "cold" // 冷门交易对，深度和 Ticker 降频处理
+)
+
+// Task 待处理任务
+type Task func() error
+
+// Pending 到期的待处理任务
+type Pending struct {
+	Symbol string
+	Task   Task
+}
+
+// Manager 按成交活跃度（笔/分钟）对交易对分级，并按级别限制深度、Ticker 的处理频率
+// 降频期间只保留最新一条数据，到期后补发，保证最终状态不丢失。
+// 成交不降频（K线聚合依赖全部成交）。
+type Manager struct {
+	cfg config.TieringConfig
+
+	mu         sync.Mutex
+	trades     map[string]int64 // 本统计周期内的成交笔数
+	tiers      map[string]Tier  // 当前级别，未分级的交易对视为 hot
+	slots      map[string]*slot // key: symbol|dataType
+	throttled  map[Tier]int64   // 降频跳过的数据条数
+	lastRollAt time.Time
+}
+
+// slot 单个交易对、单个数据类型的降频状态
+type slot struct {
+	symbol  string
+	last    time.Time // 上次处理时间
+	pending Task      // 降频期间最新的待处理任务
+}
+
+// Stats 分级统计
+type Stats struct {
+	Hot       int            `json:"hot"`
+	Cold      int            `json:"cold"`
+	Throttled map[Tier]int64 `json:"throttled"`
+}
+
+// NewManager 创建分级管理器
+func NewManager(cfg config.TieringConfig) *Manager {
+	if cfg.HotTradesPerMin <= 0 {
+		cfg.HotTradesPerMin = 60
+	}
+	if cfg.ClassifyIntervalSec <= 0 {
+		cfg.ClassifyIntervalSec = 60
+	}
+	if cfg.HotTickerIntervalMs <= 0 {
+		cfg.HotTickerIntervalMs = 1000
+	}
+	if cfg.ColdTickerIntervalMs <= 0 {
+		cfg.ColdTickerIntervalMs = 5000
+	}
+	if cfg.ColdDepthIntervalMs <= 0 {
+		cfg.ColdDepthIntervalMs = 2000
+	}
+
+	return &Manager{
+		cfg:        cfg,
+		trades:     make(map[string]int64),
+		tiers:      make(map[string]Tier),
+		slots:      make(map[string]*slot),
+		throttled:  make(map[Tier]int64),
+		lastRollAt: time.Now(),
+	}
+}
+
+// RecordTrade 记录成交，用于计算活跃度
+func (m *Manager) RecordTrade(symbol string) {
+	m.mu.Lock()
+	m.trades[symbol]++
+	m.mu.Unlock()
+}
+
+// Tier 获取交易对当前级别
+func (m *Manager) Tier(symbol string) Tier {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.tierLocked(symbol)
+}
+
+// Submit 按交易对级别决定是否立即处理
+// 返回 true 时由调用方立即执行任务；返回 false 时任务被暂存，到期后由 Due 取出补发
+func (m *Manager) Submit(symbol, dataType string, task Task) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tier := m.tierLocked(symbol)
+	interval := m.interval(tier, dataType)
+
+	key := symbol + "|" + dataType
+	s, ok := m.slots[key]
+	if !ok {
+		s = &slot{symbol: symbol}
+		m.slots[key] = s
+	}
+
+	now := time.Now()
+	if interval == 0 || now.Sub(s.last) >= interval {
+		s.last = now
+		s.pending = nil // 更新的数据直接处理，旧的待处理任务作废
+		return true
+	}
+
+	s.pending = task
+	m.throttled[tier]++
+	return false
+}
+
+// Due 取出已到期的待处理任务
+func (m *Manager) Due() []Pending {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var due []Pending
+	for key, s := range m.slots {
+		if s.pending == nil {
+			continue
+		}
+		dataType := key[len(s.symbol)+1:]
+		if now.Sub(s.last) < m.interval(m.tierLocked(s.symbol), dataType) {
+			continue
+		}
+		due = append(due, Pending{Symbol: s.symbol, Task: s.pending})
+		s.pending = nil
+		s.last = now
+	}
+	return due
+}
+
+// Classify 根据本统计周期内的成交笔数重新分级
+// 降级阈值为升级阈值的一半，避免活跃度在阈值附近波动时级别频繁切换
+func (m *Manager) Classify() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	minutes := now.Sub(m.lastRollAt).Minutes()
+	m.lastRollAt = now
+	if minutes <= 0 {
+		return
+	}
+
+	symbols := make(map[string]bool, len(m.tiers)+len(m.trades))
+	for symbol := range m.tiers {
+		symbols[symbol] = true
+	}
+	for symbol := range m.trades {
+		symbols[symbol] = true
+	}
+
+	for symbol := range symbols {
+		rate := float64(m.trades[symbol]) / minutes
+		current := m.tierLocked(symbol)
+
+		next := current
+		switch {
+		case rate >= m.cfg.HotTradesPerMin:
+			next = TierHot
+		case rate < m.cfg.HotTradesPerMin/2:
+			next = TierCold
+		}
+
+		if next != current {
+			log.Printf("[Tiering] %s: %s -> %s (%.1f trades/min)\n", symbol, current, next, rate)
+		}
+		m.tiers[symbol] = next
+	}
+
+	m.trades = make(map[string]int64)
+}
+
+// Run 定期分级并补发到期的待处理任务，直到 stop 关闭
+func (m *Manager) Run(stop <-chan struct{}, dispatch func(symbol string, task Task)) {
+	classifyTicker := time.NewTicker(time.Duration(m.cfg.ClassifyIntervalSec) * time.Second)
+	defer classifyTicker.Stop()
+
+	flushTicker := time.NewTicker(200 * time.Millisecond)
+	defer flushTicker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-classifyTicker.C:
+			m.Classify()
+		case <-flushTicker.C:
+			for _, p := range m.Due() {
+				dispatch(p.Symbol, p.Task)
+			}
+		}
+	}
+}
+
+// Stats 获取分级统计
+func (m *Manager) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := Stats{Throttled: make(map[Tier]int64, len(m.throttled))}
+	for _, tier := range m.tiers {
+		if tier == TierHot {
+			stats.Hot++
+		} else {
+			stats.Cold++
+		}
+	}
+	for tier, count := range m.throttled {
+		stats.Throttled[tier] = count
+	}
+	return stats
+}
+
+// tierLocked 获取交易对级别（调用方需持有锁）
+func (m *Manager) tierLocked(symbol string) Tier {
+	if tier, ok := m.tiers[symbol]; ok {
+		return tier
+	}
+	return TierHot
+}
+
+// interval 各级别、数据类型的最小处理间隔，0 表示不限制
+func (m *Manager) interval(tier Tier, dataType string) time.Duration {
+	var ms int
+	switch dataType {
+	case constants.DataTypeTicker:
+		if tier == TierHot {
+			ms = m.cfg.HotTickerIntervalMs
+		} else {
+			ms = m.cfg.ColdTickerIntervalMs
+		}
+	case constants.DataTypeDepth:
+		if tier == TierCold {
+			ms = m.cfg.ColdDepthIntervalMs
+		}
+	}
+	return time.Duration(ms) * time.Millisecond
+}
@@ -0,0 +1,254 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"market-system/common/codec"
+	"market-system/common/config"
+	"market-system/common/constants"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dispatcher 行情事件 Webhook 推送
+// 接收 Redis market:* 频道的推送消息，按各地址配置的频道过滤后加入该地址的发送队列。
+// 消息体与 WebSocket 推送相同: {"channel": ..., "data": ...}，MessagePack 编码的消息转为 JSON。
+// 每个地址一个发送协程，同一地址的事件按顺序发送，失败时按指数退避重试。
+// AddTarget 添加的地址由事件自带（如用户价格提醒的 Webhook），同样签名、重试并计入统计。
+type Dispatcher struct {
+	cfg          config.WebhookConfig
+	client       *http.Client
+	targetClient *http.Client // 发送到用户提供的地址，连接时拒绝回环、链路本地和私有地址，不跟随重定向
+	endpoints    []*endpoint
+}
+
+// NewDispatcher 创建 Webhook 推送
+func NewDispatcher(cfg config.WebhookConfig) *Dispatcher {
+	if cfg.TimeoutMs <= 0 {
+		cfg.TimeoutMs = 5000
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.InitialBackoffMs <= 0 {
+		cfg.InitialBackoffMs = 500
+	}
+	if cfg.MaxBackoffMs <= 0 {
+		cfg.MaxBackoffMs = 30000
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+
+	timeout := time.Duration(cfg.TimeoutMs) * time.Millisecond
+	dialer := &net.Dialer{Timeout: timeout, Control: utils.WebhookDialControl}
+	d := &Dispatcher{
+		cfg:    cfg,
+		client: &http.Client{Timeout: timeout},
+		targetClient: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{DialContext: dialer.DialContext},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+	for _, e := range cfg.Endpoints {
+		if e.URL == "" || len(e.Channels) == 0 {
+			log.Printf("[Webhook] Endpoint %q has no url or channels, skipped\n", e.Name)
+			continue
+		}
+		if e.Name == "" {
+			e.Name = e.URL
+		}
+		d.endpoints = append(d.endpoints, &endpoint{
+			cfg:   e,
+			queue: make(chan *event, cfg.QueueSize),
+		})
+	}
+	return d
+}
+
+// AddTarget 添加地址由事件自带的推送（需在 Run 之前调用），name 为统计中的名称，secret 为签名密钥，
+// workers 个协程并发发送（不保证顺序）；地址由用户提供，发送前检查主机是否在 hosts 中（为空时允许全部公网地址）
+func (d *Dispatcher) AddTarget(name, secret string, workers int, hosts []string) {
+	if workers <= 0 {
+		workers = 1
+	}
+	d.endpoints = append(d.endpoints, &endpoint{
+		cfg:     config.WebhookEndpoint{Name: name, Secret: secret},
+		queue:   make(chan *event, d.cfg.QueueSize),
+		workers: workers,
+		hosts:   hosts,
+		target:  true,
+	})
+}
+
+// EndpointCount 有效的 Webhook 地址数
+func (d *Dispatcher) EndpointCount() int {
+	return len(d.endpoints)
+}
+
+// Run 启动各地址的发送协程，ctx 取消后停止（队列中未发送的事件丢弃）
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, e := range d.endpoints {
+		for i := 0; i < e.workerCount(); i++ {
+			wg.Add(1)
+			go func(e *endpoint) {
+				defer wg.Done()
+				d.send(ctx, e)
+			}(e)
+		}
+	}
+	wg.Wait()
+}
+
+// SendTo 将频道消息发送到 AddTarget 添加的推送，url 为事件的地址，消息体格式与 Dispatch 相同
+func (d *Dispatcher) SendTo(name, url, channel string, data interface{}) {
+	var target *endpoint
+	for _, e := range d.endpoints {
+		if e.target && e.cfg.Name == name {
+			target = e
+			break
+		}
+	}
+	if target == nil {
+		log.Printf("[Webhook] Unknown target %s\n", name)
+		return
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"channel": channel,
+		"data":    data,
+	})
+	if err != nil {
+		log.Printf("[Webhook] Failed to marshal message on %s: %v\n", channel, err)
+		return
+	}
+	target.enqueue(&event{channel: channel, body: body, url: url})
+}
+
+// Dispatch 推送频道消息，channel 为去掉 market: 前缀后的频道名，如 trade:BTCUSDT
+func (d *Dispatcher) Dispatch(channel string, payload []byte) {
+	var matched []*endpoint
+	for _, e := range d.endpoints {
+		if e.matches(channel) {
+			matched = append(matched, e)
+		}
+	}
+	if len(matched) == 0 {
+		return
+	}
+
+	var data interface{}
+	if err := codec.Unmarshal(payload, &data); err != nil {
+		log.Printf("[Webhook] Failed to parse message on %s: %v\n", channel, err)
+		return
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"channel": channel,
+		"data":    data,
+	})
+	if err != nil {
+		log.Printf("[Webhook] Failed to marshal message on %s: %v\n", channel, err)
+		return
+	}
+
+	closed := false
+	if update, ok := data.(map[string]interface{}); ok {
+		closed, _ = update["closed"].(bool)
+	}
+	for _, e := range matched {
+		if e.cfg.ClosedKlinesOnly && channelType(channel) == constants.DataTypeKline && !closed {
+			continue
+		}
+		e.enqueue(&event{channel: channel, body: body})
+	}
+}
+
+// Stats 各地址的推送统计
+func (d *Dispatcher) Stats() *models.WebhookStats {
+	stats := &models.WebhookStats{
+		Timestamp: utils.GetCurrentTimestamp(),
+		Endpoints: make(map[string]models.WebhookEndpointStats, len(d.endpoints)),
+	}
+	for _, e := range d.endpoints {
+		stats.Endpoints[e.cfg.Name] = e.snapshot()
+	}
+	return stats
+}
+
+// send 依次发送地址队列中的事件
+func (d *Dispatcher) send(ctx context.Context, e *endpoint) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-e.queue:
+			d.deliver(ctx, e, ev)
+		}
+	}
+}
+
+// deliver 发送单个事件，请求失败、429 或 5xx 时按指数退避重试，其余 4xx 不重试
+// 事件自带的地址发送前检查，不允许的地址不发送也不重试
+func (d *Dispatcher) deliver(ctx context.Context, e *endpoint, ev *event) {
+	if e.target {
+		if err := utils.ValidateWebhookURL(ctx, ev.url, e.hosts); err != nil {
+			e.recordAttempt(0, 0, err)
+			e.recordFailure()
+			log.Printf("[Webhook] Rejected %s to %s: %v\n", ev.channel, e.cfg.Name, err)
+			return
+		}
+	}
+
+	backoff := time.Duration(d.cfg.InitialBackoffMs) * time.Millisecond
+	maxBackoff := time.Duration(d.cfg.MaxBackoffMs) * time.Millisecond
+
+	for attempt := 0; ; attempt++ {
+		status, err := d.post(ctx, e, ev)
+		if err == nil {
+			return
+		}
+		retryable := status == 0 || status == http.StatusTooManyRequests || status >= 500
+		if !retryable || attempt >= d.cfg.MaxRetries {
+			e.recordFailure()
+			log.Printf("[Webhook] Failed to deliver %s to %s: %v\n", ev.channel, e.cfg.Name, err)
+			return
+		}
+
+		e.recordRetry()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// channelType 频道的数据类型，如 kline:BTCUSDT:1m -> kline
+func channelType(channel string) string {
+	if idx := strings.Index(channel, ":"); idx >= 0 {
+		return channel[:idx]
+	}
+	return channel
+}
+
+// matchChannel 频道是否匹配配置的频道，* 匹配任意不含 / 的字符
+func matchChannel(pattern, channel string) bool {
+	ok, err := path.Match(pattern, channel)
+	return err == nil && ok
+}
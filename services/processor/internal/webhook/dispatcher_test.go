@@ -0,0 +1,174 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"market-system/common/config"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type request struct {
+	channel   string
+	timestamp string
+	signature string
+	body      []byte
+}
+
+// recorder 记录收到的请求，前 failures 次返回 500
+type recorder struct {
+	mu       sync.Mutex
+	failures int
+	requests []request
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests = append(r.requests, request{
+		channel:   req.Header.Get(HeaderChannel),
+		timestamp: req.Header.Get(HeaderTimestamp),
+		signature: req.Header.Get(HeaderSignature),
+		body:      body,
+	})
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (r *recorder) received() []request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]request(nil), r.requests...)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	rec := &recorder{failures: 1}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	d := NewDispatcher(config.WebhookConfig{
+		InitialBackoffMs: 1,
+		Endpoints: []config.WebhookEndpoint{{
+			Name:             "test",
+			URL:              server.URL,
+			Secret:           "secret",
+			Channels:         []string{"trade:BTCUSDT", "kline:*:1m"},
+			ClosedKlinesOnly: true,
+		}},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	d.Dispatch("trade:ETHUSDT", []byte(`{"price":1}`))
+	d.Dispatch("kline:BTCUSDT:1m", []byte(`{"closed":false}`))
+	d.Dispatch("kline:BTCUSDT:5m", []byte(`{"closed":true}`))
+	d.Dispatch("trade:BTCUSDT", []byte(`{"price":2}`))
+	d.Dispatch("kline:BTCUSDT:1m", []byte(`{"closed":true}`))
+
+	// 第一次请求返回 500 后重试
+	waitFor(t, func() bool { return d.Stats().Endpoints["test"].Delivered == 2 })
+	requests := rec.received()
+	if len(requests) != 3 {
+		t.Fatalf("requests = %d, want 3", len(requests))
+	}
+	if requests[0].channel != "trade:BTCUSDT" || requests[1].channel != "trade:BTCUSDT" || requests[2].channel != "kline:BTCUSDT:1m" {
+		t.Fatalf("channels = %s, %s, %s", requests[0].channel, requests[1].channel, requests[2].channel)
+	}
+
+	for _, req := range requests {
+		if want := Sign("secret", req.timestamp, req.body); req.signature != want {
+			t.Errorf("signature = %s, want %s", req.signature, want)
+		}
+	}
+	var msg struct {
+		Channel string                 `json:"channel"`
+		Data    map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(requests[1].body, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Channel != "trade:BTCUSDT" || msg.Data["price"] != 2.0 {
+		t.Errorf("body = %s", requests[1].body)
+	}
+
+	stats := d.Stats().Endpoints["test"]
+	if stats.Retries != 1 || stats.Failed != 0 || stats.LastStatus != http.StatusNoContent {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestDeliverGivesUp(t *testing.T) {
+	rec := &recorder{failures: 10}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	d := NewDispatcher(config.WebhookConfig{
+		MaxRetries:       2,
+		InitialBackoffMs: 1,
+		Endpoints:        []config.WebhookEndpoint{{Name: "test", URL: server.URL, Channels: []string{"*"}}},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	d.Dispatch("alerts", []byte(`{}`))
+	waitFor(t, func() bool { return d.Stats().Endpoints["test"].Failed == 1 })
+
+	stats := d.Stats().Endpoints["test"]
+	if stats.Retries != 2 || stats.Delivered != 0 || stats.LastStatus != http.StatusInternalServerError {
+		t.Errorf("stats = %+v", stats)
+	}
+	if n := len(rec.received()); n != 3 {
+		t.Errorf("requests = %d, want 3", n)
+	}
+	if rec.received()[0].signature != "" {
+		t.Error("unexpected signature without secret")
+	}
+}
+
+func TestSendToTarget(t *testing.T) {
+	rec := &recorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	d := NewDispatcher(config.WebhookConfig{InitialBackoffMs: 1})
+	d.AddTarget("alerts", "secret", 2, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	// 事件自带的地址为回环地址，不发送也不重试
+	d.SendTo("alerts", server.URL, "price_alert:1", map[string]interface{}{"price": 1})
+	d.SendTo("unknown", server.URL, "price_alert:1", nil)
+	waitFor(t, func() bool { return d.Stats().Endpoints["alerts"].Failed == 1 })
+
+	stats := d.Stats().Endpoints["alerts"]
+	if stats.Retries != 0 || stats.Delivered != 0 || stats.LastError == "" {
+		t.Errorf("stats = %+v", stats)
+	}
+	if n := len(rec.received()); n != 0 {
+		t.Errorf("requests = %d, want 0", n)
+	}
+}
@@ -0,0 +1,157 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"market-system/common/config"
+	"market-system/common/models"
+	"market-system/common/utils"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 请求头
+const (
+	HeaderChannel   = "X-Market-Channel"
+	HeaderTimestamp = "X-Market-Timestamp" // 发送时间（毫秒），参与签名，接收方可据此拒绝过旧的请求
+	HeaderSignature = "X-Market-Signature" // sha256=hex(HMAC-SHA256(secret, timestamp + "." + body))
+)
+
+// endpoint 单个 Webhook 地址的发送队列和统计
+type endpoint struct {
+	cfg     config.WebhookEndpoint
+	queue   chan *event
+	workers int      // 发送协程数，0 表示 1 个（按顺序发送）
+	hosts   []string // 事件自带地址时允许的主机
+	target  bool     // 地址由事件自带，cfg.URL 为空
+
+	mu    sync.Mutex
+	stats models.WebhookEndpointStats
+}
+
+// event 待发送的频道消息
+type event struct {
+	channel string
+	body    []byte
+	url     string // 事件自带的地址（AddTarget 添加的推送）
+}
+
+// workerCount 发送协程数
+func (e *endpoint) workerCount() int {
+	if e.workers <= 0 {
+		return 1
+	}
+	return e.workers
+}
+
+// matches 频道是否在地址配置的频道中
+func (e *endpoint) matches(channel string) bool {
+	for _, pattern := range e.cfg.Channels {
+		if matchChannel(pattern, channel) {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueue 加入发送队列，队列满时丢弃
+func (e *endpoint) enqueue(ev *event) {
+	select {
+	case e.queue <- ev:
+	default:
+		e.mu.Lock()
+		e.stats.Dropped++
+		e.mu.Unlock()
+	}
+}
+
+// Sign 计算签名，接收方以相同方式计算并比较 X-Market-Signature
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// post 发送一次请求，返回 HTTP 状态码（请求失败时为 0），非 2xx 响应视为失败
+func (d *Dispatcher) post(ctx context.Context, e *endpoint, ev *event) (int, error) {
+	url, client := e.cfg.URL, d.client
+	if e.target {
+		url, client = ev.url, d.targetClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(ev.body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(utils.GetCurrentTimestamp(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderChannel, ev.channel)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if e.cfg.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(e.cfg.Secret, timestamp, ev.body))
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	latency := time.Since(start).Milliseconds()
+	if err != nil {
+		e.recordAttempt(0, latency, err)
+		return 0, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err = fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	e.recordAttempt(resp.StatusCode, latency, err)
+	return resp.StatusCode, err
+}
+
+// recordAttempt 记录一次请求的结果
+func (e *endpoint) recordAttempt(status int, latencyMs int64, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.stats.LastStatus = status
+	e.stats.LastLatencyMs = latencyMs
+	if err != nil {
+		e.stats.LastError = err.Error()
+		return
+	}
+	e.stats.Delivered++
+	e.stats.LastDeliveryAt = utils.GetCurrentTimestamp()
+}
+
+// recordRetry 记录一次重试
+func (e *endpoint) recordRetry() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stats.Retries++
+}
+
+// recordFailure 记录重试后仍失败的事件
+func (e *endpoint) recordFailure() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stats.Failed++
+}
+
+// snapshot 当前统计
+func (e *endpoint) snapshot() models.WebhookEndpointStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stats := e.stats
+	stats.URL = e.cfg.URL
+	stats.Pending = len(e.queue)
+	return stats
+}